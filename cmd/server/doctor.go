@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"flacidal/internal/app"
+	"flacidal/internal/settings"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+)
+
+// runDoctor implements `flacidal-server doctor`: it runs app.Doctor with
+// the server's config and prints the report, returning the exit status,
+// 1 when a check failed.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	track := fs.Int("track", app.DoctorTrackID, "Tidal track the test download fetches")
+	noDownload := fs.Bool("no-download", false, "skip the test download")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	core.InitTidalEndpoints()
+	config, err := core.LoadConfigWithEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load config: %v\n", err)
+		config = nil // reported by the Config check
+	}
+	var s settings.Settings
+	if store, err := settings.Open(core.GetDataDir()); err == nil {
+		s = store.Get()
+	}
+
+	downloader := core.NewTidalHifiService()
+	if config != nil {
+		// Validated by the Config check; the downloader just keeps its defaults
+		_, _ = app.ApplyConfig(app.ConfigTargets{Downloader: downloader}, nil, config)
+	}
+
+	env := app.DoctorEnv{Config: config, Settings: s, DataDir: core.GetDataDir(), Downloader: downloader}
+	if !*noDownload {
+		env.TrackID = *track
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report := app.Doctor(ctx, env)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
@@ -3,12 +3,23 @@ package main
 import (
 	"context"
 	"embed"
-	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // the slim Docker image has no zoneinfo; /api/history/heatmap takes tz names
 
+	"flacidal/internal/analysisstore"
 	"flacidal/internal/api"
+	"flacidal/internal/app"
+	"flacidal/internal/coverproxy"
+	"flacidal/internal/coverstore"
+	"flacidal/internal/credits"
+	"flacidal/internal/events"
+	"flacidal/internal/history"
+	"flacidal/internal/library"
+	"flacidal/internal/logging"
+	"flacidal/internal/lyricscache"
+	"flacidal/internal/settings"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
 )
@@ -18,7 +29,28 @@ import (
 var frontendFS embed.FS
 
 func main() {
-	log.Println("FLACidal Server starting...")
+	// `flacidal-server doctor` runs the self-check instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctor(os.Args[2:]))
+	}
+
+	// Per-component log levels, e.g. LOG_LEVEL="warn,http=error,ws=debug";
+	// adjustable at runtime through /api/logs/levels.
+	logLevels := &logging.Levels{}
+	logger := logging.NewText(logLevels, os.Stderr)
+	if err := logLevels.ParseSpec(os.Getenv("LOG_LEVEL")); err != nil {
+		logger.Warn("ignoring LOG_LEVEL", "err", err)
+	}
+	log := logging.Component(logger, logging.Server)
+
+	log.Info("FLACidal Server starting...")
+
+	// A guest token alone would leave full access open to everyone else
+	apiToken, guestToken := os.Getenv("API_TOKEN"), os.Getenv("GUEST_TOKEN")
+	if guestToken != "" && apiToken == "" {
+		log.Error("GUEST_TOKEN requires API_TOKEN to be set")
+		os.Exit(1)
+	}
 
 	// Refresh Tidal endpoints from gist in background before downloader init.
 	core.InitTidalEndpoints()
@@ -26,7 +58,7 @@ func main() {
 	// Load config (env vars override file config)
 	config, err := core.LoadConfigWithEnv()
 	if err != nil {
-		log.Printf("Warning: Could not load config: %v, using defaults", err)
+		log.Warn("could not load config, using defaults", "err", err)
 		config = core.GetDefaultConfig()
 	}
 
@@ -36,28 +68,33 @@ func main() {
 		downloadDir = core.GetDefaultDownloadFolder()
 	}
 	if err := os.MkdirAll(downloadDir, 0755); err != nil {
-		log.Printf("Warning: Could not create download directory: %v", err)
+		log.Warn("could not create download directory", "err", err)
 	}
 
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Stored times predating UTC timestamps are rewritten before the
+	// database is opened
+	if n, err := app.MigrateTimestamps(ctx, core.GetDataDir()); err != nil {
+		log.Warn("could not migrate stored timestamps", "err", err)
+	} else if n > 0 {
+		log.Info("migrated stored timestamps to UTC", "count", n)
+	}
+
 	// Initialize database
 	db, err := core.NewDatabase()
 	if err != nil {
-		log.Printf("Warning: Could not initialize database: %v", err)
+		log.Warn("could not initialize database", "err", err)
 	}
 
 	// Initialize FLAC downloader service
 	downloader := core.NewTidalHifiService()
 
-	// Initialize download manager
-	workers := config.ConcurrentDownloads
-	if workers <= 0 {
-		workers = 4
-	}
-	downloadManager := core.NewDownloadManager(downloader, workers)
+	// Initialize download manager with the largest pool; the server holds
+	// it to the configured number of downloads
+	downloadManager := core.NewDownloadManager(downloader, app.MaxDownloadWorkers)
 
 	// Initialize sources
 	tidalSource := core.NewTidalSource()
@@ -72,30 +109,84 @@ func main() {
 	sourceManager.RegisterSource(qobuzSource)
 	sourceManager.SetPreferredSource(config.PreferredSource)
 
-	// Initialize lyrics client
+	// Load app-local settings (options flacidal-core's Config doesn't cover)
+	appSettings, err := settings.Open(core.GetDataDir())
+	if err != nil {
+		log.Warn("could not load settings, using defaults", "err", err)
+	}
+	historyOrigins, err := history.Open(core.GetDataDir())
+	if err != nil {
+		log.Warn("could not load history origins", "err", err)
+	}
+	historyTimings, err := history.OpenTimings(core.GetDataDir())
+	if err != nil {
+		log.Warn("could not load download timings", "err", err)
+	}
+	covers, err := coverstore.Open(core.GetDataDir())
+	if err != nil {
+		log.Warn("could not open cover store", "err", err)
+	}
+	coverProxy, err := coverproxy.Open(core.GetDataDir())
+	if err != nil {
+		log.Warn("could not open cover proxy", "err", err)
+	}
+	libraryIndex, err := library.Open(core.GetDataDir())
+	if err != nil {
+		log.Warn("could not open library index", "err", err)
+	}
+	creditsStore, err := credits.Open(core.GetDataDir())
+	if err != nil {
+		log.Warn("could not open credits store", "err", err)
+	}
+	analyses, err := analysisstore.Open(core.GetDataDir())
+	if err != nil {
+		log.Warn("could not open analysis store", "err", err)
+	}
+
+	// Initialize lyrics client and its lookup cache
 	lyricsClient := core.NewLyricsClient()
+	lyricsCache, err := lyricscache.Open(core.GetDataDir())
+	if err != nil {
+		log.Warn("could not load lyrics cache", "err", err)
+	}
 
 	// Create and configure server
 	server := api.NewServer(api.ServerConfig{
 		Config:          config,
 		DB:              db,
+		Downloader:      downloader,
 		DownloadManager: downloadManager,
 		SourceManager:   sourceManager,
 		TidalSource:     tidalSource,
 		QobuzSource:     qobuzSource,
 		LyricsClient:    lyricsClient,
+		LyricsCache:     lyricsCache,
+		Settings:        appSettings,
+		HistoryOrigins:  historyOrigins,
+		HistoryTimings:  historyTimings,
+		Covers:          covers,
+		CoverProxy:      coverProxy,
+		Library:         libraryIndex,
+		Credits:         creditsStore,
+		Analyses:        analyses,
 		Context:         ctx,
 		FrontendFS:      frontendFS,
 		FrontendDir:     os.Getenv("FRONTEND_DIST_DIR"),
+		Pprof:           os.Getenv("PPROF_ENABLED") == "1",
+		PprofToken:      os.Getenv("PPROF_TOKEN"),
+		APIToken:        apiToken,
+		GuestToken:      guestToken,
+		LogLevels:       logLevels,
 	})
+	downloadLog := logging.Component(logger, logging.Downloads)
 
-	// Set download progress callback to broadcast via WebSocket
-	downloadManager.SetProgressCallback(func(trackID int, status string, result *core.DownloadResult) {
-		server.BroadcastDownloadEvent(core.DownloadEvent{
-			TrackID: trackID,
-			Status:  status,
-			Result:  result,
-		})
+	// Log download progress; NewServer already feeds the WebSocket clients
+	events.Listen(server.DownloadEvents(), 256, func(ev core.DownloadEvent) {
+		if ev.Status == "error" && ev.Result != nil {
+			downloadLog.Warn("download failed", "track", ev.TrackID, "err", ev.Result.Error)
+			return
+		}
+		downloadLog.Debug("download progress", "track", ev.TrackID, "status", ev.Status)
 	})
 
 	// Start download manager
@@ -107,13 +198,19 @@ func main() {
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
 
-		log.Println("Shutting down...")
+		log.Info("Shutting down...")
 		cancel()
+		server.ReleaseDownloads()
 		downloadManager.Stop()
 		if db != nil {
 			db.Close()
 		}
 		_ = server.Shutdown()
+		if libraryIndex != nil {
+			libraryIndex.Close()
+		}
+		creditsStore.Close()
+		analyses.Close()
 	}()
 
 	// Get port from env or default
@@ -122,8 +219,9 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Server listening on :%s", port)
+	log.Info("Server listening", "port", port)
 	if err := server.Listen(":" + port); err != nil {
-		log.Fatalf("Server error: %v", err) //nolint:gocritic // process is exiting; deferred cancel() has nothing left to clean up
+		log.Error("Server error", "err", err)
+		os.Exit(1) //nolint:gocritic // process is exiting; deferred cancel() has nothing left to clean up
 	}
 }
@@ -59,11 +59,40 @@ export function injectWailsMocks(page: Page, overrides: WailsOverrides = {}) {
 
     const config = opts.GetConfig ?? defaultConfig
 
+    // Analysis jobs finish at once, with AnalyzeMultiple's results as items
+    let lastAnalyzed: string[] = []
+    const analysisJob = (paths: string[]) => {
+      lastAnalyzed = paths
+      const results = opts.AnalyzeMultiple ?? []
+      return {
+        id: '1',
+        kind: 'analysis',
+        state: 'done',
+        total: paths.length,
+        processed: paths.length,
+        failed: 0,
+        items: paths.map((path, i) => ({ path, done: true, result: results[i] ?? { filePath: path, fileName: path, verdict: 'lossless' } })),
+        queued: new Date().toISOString(),
+      }
+    }
+
+    let lastConverted: string[] = []
+    const conversionJob = (paths: string[]) => ({
+      id: '1',
+      kind: 'conversion',
+      state: 'done',
+      total: paths.length,
+      processed: paths.length,
+      failed: 0,
+      items: paths.map((path) => ({ path, done: true, result: { sourcePath: path, outputPath: path, success: true } })),
+      queued: new Date().toISOString(),
+    })
+
     // ---------- App methods ---------- //
     const App = {
       // Config
       GetConfig: async () => config,
-      SaveConfig: async (_c: any) => {},
+      SaveConfig: async (_c: any) => [],
       ResetToDefaults: async () => defaultConfig,
 
       // App / version
@@ -144,6 +173,9 @@ export function injectWailsMocks(page: Page, overrides: WailsOverrides = {}) {
       // Search
       SearchTidal: async (_q: string) => [],
       SearchTidalAlbums: async (_q: string) => [],
+      GetAlbumEditions: async (_a: string, _t: string) => [],
+      GetEditionURL: async (_b: string) => '',
+      GetAlbumCredits: async (source: string, id: string, _r: boolean) => ({ source, id, title: '', artist: '', tracks: [], roles: [], fetchedAt: '' }),
       SearchTidalArtists: async (_q: string) => [],
 
       // Download / queue
@@ -169,6 +201,12 @@ export function injectWailsMocks(page: Page, overrides: WailsOverrides = {}) {
       DeleteHistoryRecord: async (_id: number) => {},
       ClearDownloadHistory: async () => {},
       RefetchFromHistory: async (_id: string) => ({ url: '' }),
+      GetHistorySnapshots: async () => ({}),
+      GetHistoryTimings: async () => ({}),
+      GetHistoryCover: async (_id: string) => { throw new Error('no archived cover') },
+      CheckHistoryCompleteness: async (_id: string) => ({ folder: '', url: '', source: 'tidal', type: 'album', title: '', tracks: [], missing: 0 }),
+      CheckAlbumCompleteness: async (dir: string, url: string) => ({ folder: dir, url, source: 'tidal', type: 'album', title: '', tracks: [], missing: 0 }),
+      QueueMissingTracks: async (_dir: string, _url: string) => 0,
 
       // Files
       ListDownloadedFiles: async () => opts.ListDownloadedFiles ?? [],
@@ -183,6 +221,23 @@ export function injectWailsMocks(page: Page, overrides: WailsOverrides = {}) {
           { fileName: 'test.flac', verdict: 'lossless', message: 'Authentic lossless' },
         ],
       QuickAnalyze: async (_p: string) => ({ verdict: 'lossless' }),
+      VerifyFiles: async (paths: string[]) => paths.map((path) => ({ path, ok: true, md5: '', samples: 0 })),
+      VerifyFolder: async (_folder: string) => [],
+      CheckAlbum: async (folder: string) => ({ folder, tracks: [], format: '', verdict: '', consistent: true, issues: [] }),
+      CompareFiles: async (a: string, b: string) => ({
+        a: { path: a, verdict: 'lossless' },
+        b: { path: b, verdict: 'lossless' },
+        sameAudio: true,
+        keep: '',
+        reason: 'identical audio; only the tags can differ',
+        differences: [],
+      }),
+      StartAnalysis: async (paths: string[], _force: boolean) => analysisJob(paths),
+      AnalyzeFolder: async (folder: string, _recursive: boolean, _force: boolean) => analysisJob([`${folder}/test.flac`]),
+      GetAnalysisJob: async (_id: string) => analysisJob(lastAnalyzed),
+      ListAnalysisJobs: async () => [],
+      ExportQualityReport: async (_format: string) => '',
+      CancelAnalysisJob: async (_id: string) => analysisJob(lastAnalyzed),
 
       // Conversion
       IsConverterAvailable: async () => opts.IsConverterAvailable ?? true,
@@ -197,18 +252,35 @@ export function injectWailsMocks(page: Page, overrides: WailsOverrides = {}) {
         ],
       ConvertFiles: async (..._a: any[]) => [],
       ConvertFolder: async (..._a: any[]) => [],
+      StartConversion: async (files: string[], ..._a: any[]) => conversionJob((lastConverted = files)),
+      GetConversionJob: async (_id: string) => conversionJob(lastConverted),
+      ListConversionJobs: async () => [],
+      CancelConversionJob: async (_id: string) => conversionJob(lastConverted),
       GetFFmpegInfo: async () => ({ version: '6.0', available: true }),
+      RunDoctor: async (_download: boolean) => ({ results: [{ name: 'Config', status: 'pass', detail: '', durationMs: 1 }], ok: true, startedAt: '' }),
       GetFFmpegInstallStatus: async () => ({ installed: true }),
       InstallFFmpeg: async () => {},
 
       // Lyrics / metadata
       FetchAndEmbedLyrics: async (_p: string) => ({ synced: false }),
       FetchAndEmbedLyricsMultiple: async (_p: string[]) => [],
+      PreviewLyricsEmbed: async (_p: string[]) => [],
       FetchLyrics: async (..._a: any[]) => ({ synced: false }),
       FetchLyricsForFile: async (_p: string) => ({ synced: false }),
       EmbedLyricsToFile: async (..._a: any[]) => {},
+      SaveLyricsSidecar: async (..._a: any[]) => '/mock/music/track.lrc',
       GetFileMetadata: async (_p: string) => ({}),
       GetFileCoverArt: async (_p: string) => ({}),
+      GetFileThumbnail: async (_p: string, _s: number) => ({}),
+
+      // Library index
+      GetLibraryStatus: async () => ({ tracks: 0, scanning: false }),
+      ScanLibrary: async () => ({ roots: ['/mock/music'], files: 0, added: 0, updated: 0, removed: 0, failed: 0, duration: 0 }),
+      BrowseLibrary: async (_q: any) => ({ total: 0, limit: 100, offset: 0, tracks: [] }),
+      GetLibraryArtists: async (_q: any) => [],
+      GetLibraryAlbums: async (_q: any) => [],
+      GetLibraryLabels: async (_q: any) => [],
+      GetLibraryDuplicates: async (_by: string) => [],
 
       // Logs
       GetLogs: async () => [],
@@ -0,0 +1,90 @@
+import { AnalyzeFolder, GetAnalysisJob, StartAnalysis } from './api';
+import type { AnalysisJob, AnalysisJobEvent, AnalysisJobItem, AnalysisResult } from './api';
+import { EventsOn } from './websocket';
+
+/**
+ * Queues a background analysis of paths and resolves with the finished (or
+ * cancelled) job, per-file results included. Unchanged files reuse their
+ * saved analysis unless `force` is set. `onStart` gets the queued job,
+ * whose id CancelAnalysisJob takes; `onProgress` gets each
+ * "analysis-progress" event of this job. The job is also polled, so a
+ * missed event (or a disconnected WebSocket) only delays the result.
+ */
+export function runAnalysis(
+  paths: string[],
+  force: boolean,
+  onProgress?: (ev: AnalysisJobEvent) => void,
+  onStart?: (job: AnalysisJob) => void,
+): Promise<AnalysisJob> {
+  return follow(() => StartAnalysis(paths, force), onProgress, onStart);
+}
+
+/**
+ * runAnalysis for the FLAC files in folder and, if recursive, its
+ * subfolders. The finished job's `summary` counts the files by verdict.
+ */
+export function runFolderAnalysis(
+  folder: string,
+  recursive: boolean,
+  force: boolean,
+  onProgress?: (ev: AnalysisJobEvent) => void,
+  onStart?: (job: AnalysisJob) => void,
+): Promise<AnalysisJob> {
+  return follow(() => AnalyzeFolder(folder, recursive, force), onProgress, onStart);
+}
+
+async function follow(
+  start: () => Promise<AnalysisJob>,
+  onProgress?: (ev: AnalysisJobEvent) => void,
+  onStart?: (job: AnalysisJob) => void,
+): Promise<AnalysisJob> {
+  let id = '';
+  let wake: (() => void) | null = null;
+  const unsubscribe = EventsOn('analysis-progress', (ev: AnalysisJobEvent) => {
+    if (ev.id !== id) return;
+    onProgress?.(ev);
+    if (finished(ev.state)) wake?.();
+  });
+  try {
+    const started = await start();
+    id = started.id;
+    onStart?.(started);
+    for (;;) {
+      const job = await GetAnalysisJob(id);
+      if (finished(job.state)) return job;
+      await new Promise<void>(resolve => {
+        wake = resolve;
+        setTimeout(resolve, 1000);
+      });
+    }
+  } finally {
+    unsubscribe();
+  }
+}
+
+function finished(state: string): boolean {
+  return state === 'done' || state === 'cancelled';
+}
+
+/**
+ * An item's analysis, or an "error" verdict for a file that couldn't be
+ * analyzed, as AnalyzeMultiple reports it. Undefined while it's pending.
+ */
+export function itemResult(item: AnalysisJobItem): AnalysisResult | undefined {
+  if (!item.done) return undefined;
+  if (item.result) return item.result;
+  const fileName = item.path.split(/[\\/]/).pop() || item.path;
+  return {
+    filePath: item.path,
+    fileName,
+    isTrueLossless: false,
+    confidence: 0,
+    spectrumCutoff: 0,
+    expectedCutoff: 0,
+    verdict: 'error',
+    verdictLabel: 'Error',
+    details: item.error ?? '',
+    sampleRate: 0,
+    bitDepth: 0,
+  };
+}
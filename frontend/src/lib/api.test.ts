@@ -135,7 +135,7 @@ describe('API routing — browser mode', () => {
     expect(await IsQueuePaused()).toBe(true)
   })
 
-  it('QueueDownloads POSTs {tracks,outputDir,contentName} and unwraps .queued', async () => {
+  it('QueueDownloads POSTs the batch with its content id/type and unwraps .queued', async () => {
     const fetchMock = mockFetchOnce({ queued: 3 })
     const tracks = [{ id: 1 }]
 
@@ -146,12 +146,26 @@ describe('API routing — browser mode', () => {
     const [url, init] = fetchMock.mock.calls[0]
     expect(url).toBe('/api/downloads/queue')
     expect(init.method).toBe('POST')
-    expect(JSON.parse(init.body)).toEqual({ tracks, outputDir: '/music', contentName: 'Discovery' })
+    expect(JSON.parse(init.body)).toEqual({ tracks, outputDir: '/music', contentName: 'Discovery', contentId: 'content-1', contentType: 'album' })
+  })
+
+  it('ImportURLs POSTs the raw URL list as text with outputDir in the query', async () => {
+    const report = [{ url: 'https://tidal.com/browse/album/1', queued: 10 }]
+    const fetchMock = mockFetchOnce(report)
+
+    const { ImportURLs } = await import('./api')
+    const results = await ImportURLs('https://tidal.com/browse/album/1\n', '/music')
+
+    expect(results).toEqual(report)
+    const [url, init] = fetchMock.mock.calls[0]
+    expect(url).toBe('/api/downloads/import?outputDir=%2Fmusic')
+    expect(init.headers).toEqual({ 'Content-Type': 'text/plain' })
+    expect(init.body).toBe('https://tidal.com/browse/album/1\n')
   })
 
   it('AnalyzeMultiple normalizes the REST shape to the AnalysisResult shape', async () => {
     mockFetchOnce([
-      { fileName: 'a.flac', isUpscaled: false, confidence: 90, spectralCutoff: 22000, verdict: 'pass', verdictLabel: 'Lossless', message: 'Authentic lossless', sampleRate: 44100, bitDepth: 16 },
+      { fileName: 'a.flac', isUpscaled: false, confidence: 90, spectralCutoff: 22000, verdict: 'pass', verdictLabel: 'Lossless', message: 'Authentic lossless', sampleRate: 44100, bitDepth: 16, effectiveBitDepth: 16, paddedBitDepth: false },
     ])
 
     const { AnalyzeMultiple } = await import('./api')
@@ -169,6 +183,8 @@ describe('API routing — browser mode', () => {
       details: 'Authentic lossless',
       sampleRate: 44100,
       bitDepth: 16,
+      effectiveBitDepth: 16,
+      paddedBitDepth: false,
     })
   })
 
@@ -16,6 +16,7 @@
 // to each function below (also summarized in the migration report).
 
 import * as Wails from '../../wailsjs/go/app/App.js'
+import type { JobTiming } from '../stores/queue'
 
 // ---------------------------------------------------------------------------
 // Runtime detection
@@ -38,8 +39,49 @@ export function isWailsRuntime(): boolean {
 
 const API_BASE = '/api'
 
+// The server's API token, when it requires one (API_TOKEN or GUEST_TOKEN,
+// see internal/api/auth.go), kept in localStorage. Opening the app with
+// ?token=... in its URL — a shared dashboard link — saves the token and
+// drops it from the address bar.
+const TOKEN_KEY = 'flacidal-api-token'
+
+function loadToken(): string {
+  if (typeof localStorage === 'undefined') return ''
+  const fromURL = new URLSearchParams(window.location.search).get('token')
+  if (fromURL) {
+    localStorage.setItem(TOKEN_KEY, fromURL)
+    const url = new URL(window.location.href)
+    url.searchParams.delete('token')
+    window.history.replaceState(null, '', url)
+  }
+  return localStorage.getItem(TOKEN_KEY) || ''
+}
+
+let apiToken: string | null = null
+
+/** Returns the API token requests carry, or '' when none is set. */
+export function APIToken(): string {
+  if (apiToken === null) apiToken = loadToken()
+  return apiToken
+}
+
+/** Saves the API token for this browser; '' forgets it. */
+export function SetAPIToken(token: string): void {
+  apiToken = token
+  if (typeof localStorage === 'undefined') return
+  if (token) localStorage.setItem(TOKEN_KEY, token)
+  else localStorage.removeItem(TOKEN_KEY)
+}
+
+/** Adds the API token to a fetch's headers, leaving init as is when there is none. */
+function withToken(init?: RequestInit): RequestInit | undefined {
+  const token = APIToken()
+  if (!token) return init
+  return { ...init, headers: { ...(init?.headers as Record<string, string>), Authorization: `Bearer ${token}` } }
+}
+
 async function apiFetch<T>(path: string, init?: RequestInit): Promise<T> {
-  const res = await fetch(`${API_BASE}${path}`, init)
+  const res = await fetch(`${API_BASE}${path}`, withToken(init))
   if (!res.ok) {
     let message = `${res.status} ${res.statusText}`
     try {
@@ -86,6 +128,19 @@ function qs(params: Record<string, string | number | boolean | undefined>): stri
 // fields components actually read (per the call-site audit).
 // ---------------------------------------------------------------------------
 
+/** Cutoffs (Hz) and confidences (%) of the analyzer's verdicts. */
+export interface AnalyzerThresholds {
+  losslessHz: number
+  likelyHz: number
+  upscaledHz: number
+  hiResHz: number // below it an 88.2 kHz+ file is upsampled from 44.1/48 kHz
+  losslessConfidence: number
+  likelyConfidence: number
+  upscaledConfidence: number
+  certainConfidence: number
+  upsampledConfidence: number
+}
+
 export interface AnalysisResult {
   filePath: string
   fileName: string
@@ -98,6 +153,31 @@ export interface AnalysisResult {
   details: string
   sampleRate: number
   bitDepth: number
+  effectiveBitDepth?: number // bits carrying audio; 0 for digital silence
+  paddedBitDepth?: boolean
+  bitDepthLabel?: string // e.g. "16-bit audio padded to 24-bit"
+  loudness?: { integrated: number; range: number; truePeak: number } // LUFS, LU, dBTP
+  thresholds?: AnalyzerThresholds // the ones the verdict was judged by
+  loudnessTagged?: boolean // ReplayGain tags written (Loudness Tags setting)
+  clippedSamples?: number // samples in runs of 3+ at full scale
+  clippedPercent?: number
+  interSamplePeaks?: number // samples between which the waveform exceeds 0 dBFS
+  clipping?: boolean
+  clippingLabel?: string // e.g. "0.05% of samples clipped"
+  trailingSilence?: number // seconds of digital silence at the end
+  truncated?: boolean // decoded shorter than STREAMINFO, or ending in long silence
+  missingSeconds?: number
+  truncatedLabel?: string // e.g. "12.3 s shorter than its header records"
+  mqa?: boolean // MQA sync word in the audio or MQA tags
+  mqaLabel?: string // e.g. "MQA Studio, 352.8 kHz original"
+  mqaOriginalSampleRate?: number
+  lossyCodec?: string // "MP3", "AAC" or "Vorbis": the likely source of an upscaled file
+  correlation?: number // between the channels of stereo audio, 1 to -1
+  fakeStereo?: boolean // mono padded to stereo
+  outOfPhase?: boolean // channels working against each other
+  stereoLabel?: string // e.g. "mono: both channels are identical"
+  cached?: boolean // reused from the saved analysis of the unchanged file
+  lossyCodecLabel?: string // e.g. "MP3, ~128 kbps (16.0 kHz lowpass with holes in 35% of the audio)"
 }
 
 export interface ConversionResult {
@@ -125,9 +205,22 @@ export interface DownloadedFileInfo {
   title: string
   artist: string
   album: string
+  sampleRate?: number
+  bitDepth?: number
+  tier?: 'LOSSLESS' | 'HI_RES' // from STREAMINFO; absent when unreadable
+  analysis?: AnalysisVerdict // saved analysis; absent when never analyzed or changed since
   [key: string]: any
 }
 
+// What listings show of a file's saved analysis (see internal/analysisstore).
+export interface AnalysisVerdict {
+  verdict: string
+  verdictLabel?: string
+  paddedBitDepth?: boolean
+  clipping?: boolean
+  analyzedAt: string
+}
+
 export interface RenamePreview {
   oldPath: string
   oldName: string
@@ -144,6 +237,105 @@ export interface RenameResult {
   error?: string
 }
 
+export interface TagChange {
+  field: string
+  old?: string[]
+  new?: string[]
+}
+
+export interface StripOptions {
+  fields?: string[]
+  all?: boolean
+  covers?: boolean
+  id3?: boolean // an ID3v2 tag in front of the FLAC
+}
+
+// Normalization rules for title, artist and album tags (see
+// internal/tagrules); each is toggled on its own.
+export interface TagRules {
+  titleCase?: boolean
+  featuring?: boolean
+  stripRemaster?: boolean
+  stripExplicit?: boolean
+}
+
+// Batch file operations: jobs (see internal/jobs) queued one after another
+// with "batch-progress" events, and can be undone once finished.
+export type BatchOp = 'rename' | 'retag' | 'strip' | 'filename' | 'move' | 'convert' | 'musicbrainz' | 'acoustid' | 'normalize' | 'genres' | 'delete'
+export type BatchState = 'queued' | 'running' | 'done' | 'cancelled' | 'rolled-back' | 'undone'
+
+export interface BatchRequest {
+  op: BatchOp
+  files: string[]
+  atomic?: boolean
+  template?: string
+  tags?: Record<string, string>
+  mode?: 'merge' | 'replace'
+  strip?: StripOptions
+  pattern?: string
+  rules?: TagRules
+  dest?: string
+  format?: string
+  quality?: string
+  outputDir?: string
+}
+
+// Permission, read-only and disk space errors from file operations, with
+// the resolved path and the mount it lives on.
+export interface FileError {
+  code: 'permission-denied' | 'read-only' | 'quota-exceeded' | 'disk-full'
+  op: string
+  path: string
+  mount?: string
+  message: string
+}
+
+export interface BatchItem {
+  path: string
+  done: boolean // processed, successfully or not
+  result?: any // a convert batch's ConversionResult
+  output?: string
+  error?: string
+  detail?: FileError
+  undone?: boolean
+  undoError?: string
+}
+
+export interface Batch {
+  id: string
+  kind: BatchOp
+  state: BatchState
+  atomic?: boolean
+  total: number
+  processed: number
+  failed: number
+  undoable?: boolean
+  items?: BatchItem[] // left out by ListBatches
+  queued: string
+  started?: string
+  finished?: string
+}
+
+export interface BatchEvent {
+  id: string
+  kind: BatchOp
+  state: BatchState
+  total: number
+  processed: number
+  failed: number
+  item?: BatchItem
+}
+
+export interface TagEditResult {
+  path: string
+  changes: TagChange[]
+  pictures?: number
+  id3?: boolean
+  written: boolean
+  error?: string
+  detail?: FileError
+}
+
 export interface LogEntry {
   timestamp: string
   level: string
@@ -161,7 +353,7 @@ export async function AnalyzeMultiple(paths: string[]): Promise<AnalysisResult[]
 
   const raw = await apiPost<any[]>('/analyze/multiple', { paths })
   // The REST endpoint's response shape (isUpscaled/spectralCutoff/message)
-  // deliberately differs from core.AnalysisResult (isTrueLossless/
+  // deliberately differs from app.AnalysisResult (isTrueLossless/
   // spectrumCutoff/details) — normalize here so components see one
   // consistent shape either way.
   // Known gaps: the REST endpoint doesn't return filePath or expectedCutoff.
@@ -179,6 +371,31 @@ export async function AnalyzeMultiple(paths: string[]): Promise<AnalysisResult[]
     details: r.message,
     sampleRate: r.sampleRate,
     bitDepth: r.bitDepth,
+    effectiveBitDepth: r.effectiveBitDepth,
+    paddedBitDepth: r.paddedBitDepth,
+    bitDepthLabel: r.bitDepthLabel,
+    loudness: r.loudness,
+    loudnessTagged: r.loudnessTagged,
+    clippedSamples: r.clippedSamples,
+    clippedPercent: r.clippedPercent,
+    interSamplePeaks: r.interSamplePeaks,
+    clipping: r.clipping,
+    clippingLabel: r.clippingLabel,
+    mqa: r.mqa,
+    mqaLabel: r.mqaLabel,
+    mqaOriginalSampleRate: r.mqaOriginalSampleRate,
+    trailingSilence: r.trailingSilence,
+    truncated: r.truncated,
+    missingSeconds: r.missingSeconds,
+    truncatedLabel: r.truncatedLabel,
+    lossyCodec: r.lossyCodec,
+    lossyCodecLabel: r.lossyCodecLabel,
+    correlation: r.correlation,
+    fakeStereo: r.fakeStereo,
+    outOfPhase: r.outOfPhase,
+    stereoLabel: r.stereoLabel,
+    cached: r.cached,
+    thresholds: r.thresholds,
   }))
 }
 
@@ -222,6 +439,35 @@ export async function IsQueuePaused(): Promise<boolean> {
   return paused
 }
 
+// Progress of one queued playlist, album or single. Finished batches stay
+// listed until the next one is queued.
+export interface QueueBatch {
+  id: string
+  name: string
+  type: string
+  total: number
+  queued: number
+  active: number
+  done: number
+  failed: number
+}
+
+/** Per-batch progress of the download queue, in the order queued. */
+export async function GetQueueBatches(): Promise<QueueBatch[]> {
+  const status: any = isWailsRuntime()
+    ? await Wails.GetDownloadQueueStatus()
+    : await apiGet('/downloads/status')
+  return status?.batches ?? []
+}
+
+/** State history and timings of every download job seen this session. */
+export async function GetDownloadJobs(): Promise<any[]> {
+  if (isWailsRuntime()) {
+    return Wails.GetDownloadJobs()
+  }
+  return apiGet('/downloads/jobs')
+}
+
 export async function RetryAllFailed(): Promise<number> {
   if (isWailsRuntime()) {
     return Wails.RetryAllFailed()
@@ -243,7 +489,7 @@ export async function ExportFailedDownloads(format: 'txt' | 'csv'): Promise<stri
     return Wails.ExportFailedDownloads(format)
   }
 
-  const res = await fetch(`${API_BASE}/downloads/export?format=${encodeURIComponent(format)}`)
+  const res = await fetch(`${API_BASE}/downloads/export?format=${encodeURIComponent(format)}`, withToken())
   if (!res.ok) {
     const body = await res.json().catch(() => null)
     throw new Error(body?.error || `${res.status} ${res.statusText}`)
@@ -260,6 +506,147 @@ export async function ExportFailedDownloads(format: 'txt' | 'csv'): Promise<stri
   return ''
 }
 
+/**
+ * Saves a download manifest (track metadata plus stream URLs where they can
+ * be resolved) for url, to run the transfer with an external downloader.
+ * Returns the saved path on desktop; in the browser the file is downloaded.
+ */
+export async function ExportDownloadManifest(url: string, format: 'json' | 'aria2'): Promise<string> {
+  if (isWailsRuntime()) {
+    return Wails.ExportDownloadManifest(url, format)
+  }
+
+  const res = await fetch(`${API_BASE}/downloads/manifest?url=${encodeURIComponent(url)}&format=${encodeURIComponent(format)}`, withToken())
+  if (!res.ok) {
+    const body = await res.json().catch(() => null)
+    throw new Error(body?.error || `${res.status} ${res.statusText}`)
+  }
+  const name = /filename="([^"]+)"/.exec(res.headers.get('Content-Disposition') || '')?.[1]
+  const blob = await res.blob()
+  const href = URL.createObjectURL(blob)
+  const a = document.createElement('a')
+  a.href = href
+  a.download = name || `manifest.${format === 'json' ? 'json' : 'txt'}`
+  document.body.appendChild(a)
+  a.click()
+  a.remove()
+  URL.revokeObjectURL(href)
+  return ''
+}
+
+/**
+ * Saves the metadata of every FLAC under folder ('' for the download folder)
+ * as a CSV or JSON report. Returns the saved path on desktop ('' if
+ * cancelled); in the browser the file is downloaded and '' returned.
+ */
+export async function ExportLibrary(folder: string, format: 'csv' | 'json'): Promise<string> {
+  if (isWailsRuntime()) {
+    return Wails.ExportLibrary(folder, format)
+  }
+
+  const res = await fetch(`${API_BASE}/files/export${qs({ folder, format })}`, withToken())
+  if (!res.ok) {
+    const body = await res.json().catch(() => null)
+    throw new Error(body?.error || `${res.status} ${res.statusText}`)
+  }
+  const name = /filename="([^"]+)"/.exec(res.headers.get('Content-Disposition') || '')?.[1]
+  const blob = await res.blob()
+  const href = URL.createObjectURL(blob)
+  const a = document.createElement('a')
+  a.href = href
+  a.download = name || `library.metadata.${format}`
+  document.body.appendChild(a)
+  a.click()
+  a.remove()
+  URL.revokeObjectURL(href)
+  return ''
+}
+
+export interface TagImportResult {
+  file?: string
+  dest?: string
+  trackId?: string
+  track?: string
+  by?: 'order' | 'duration' | 'title'
+  warning?: string
+  error?: string
+  changes?: TagChange[] // dry runs only: the tags the import would change
+}
+
+export interface TagImportReport {
+  source: string
+  type: string
+  title: string
+  tagged: number
+  results: TagImportResult[]
+}
+
+/**
+ * Tags the FLAC files in `dir` (downloaded outside FLACidal) as the tracks
+ * of `url` and files them into the download folder. With `dryRun` only the
+ * file-to-track matches, and the tags each file would change, are
+ * returned. `dir` is a path on the machine running FLACidal (the server,
+ * in browser mode).
+ */
+export async function ImportAndTagFolder(dir: string, url: string, dryRun: boolean): Promise<TagImportReport> {
+  if (isWailsRuntime()) {
+    return Wails.ImportAndTagFolder(dir, url, dryRun) as Promise<TagImportReport>
+  }
+  return apiPost<TagImportReport>('/downloads/import/tag', { dir, url, dryRun })
+}
+
+export interface CompletenessTrack {
+  trackId: string
+  title: string
+  artist: string
+  trackNumber?: number
+  discNumber?: number
+  isrc?: string
+  file?: string // empty when the track is missing
+  by?: string // 'id', 'isrc', 'order', 'duration' or 'title'
+}
+
+export interface CompletenessReport {
+  folder: string
+  url: string
+  source: string
+  type: string
+  title: string
+  artist?: string
+  tracks: CompletenessTrack[]
+  missing: number
+  extra?: string[] // files no track matches
+}
+
+/**
+ * Compares the FLAC files in `dir` with the track list of `url`, or, when
+ * `url` is empty, of the album the files' provenance tags name. `dir` is a
+ * path on the machine running FLACidal (the server, in browser mode).
+ */
+export async function CheckAlbumCompleteness(dir: string, url: string): Promise<CompletenessReport> {
+  if (isWailsRuntime()) {
+    return Wails.CheckAlbumCompleteness(dir, url) as Promise<CompletenessReport>
+  }
+  return apiPost<CompletenessReport>('/downloads/completeness', { dir, url })
+}
+
+/** Checks the folder a history record's content was downloaded into. */
+export async function CheckHistoryCompleteness(contentId: string): Promise<CompletenessReport> {
+  if (isWailsRuntime()) {
+    return Wails.CheckHistoryCompleteness(contentId) as Promise<CompletenessReport>
+  }
+  return apiGet<CompletenessReport>(`/history/completeness/${encodeURIComponent(contentId)}`)
+}
+
+/** Queues the tracks of `url` no file in `dir` holds into `dir`. */
+export async function QueueMissingTracks(dir: string, url: string): Promise<number> {
+  if (isWailsRuntime()) {
+    return Wails.QueueMissingTracks(dir, url)
+  }
+  const { queued } = await apiPost<{ queued: number }>('/downloads/completeness/queue', { dir, url })
+  return queued
+}
+
 export async function QueueDownloads(
   tracks: any[],
   outputDir: string,
@@ -270,11 +657,13 @@ export async function QueueDownloads(
   if (isWailsRuntime()) {
     return Wails.QueueDownloads(tracks as any, outputDir, contentName, contentId, contentType)
   }
-  // Known gap: unlike the Wails path, the REST endpoint doesn't yet persist
-  // a content-level DownloadRecord for contentId/contentType, so
-  // playlist/album progress in History won't populate for downloads queued
-  // through the headless server. See migration report.
-  const { queued } = await apiPost<{ queued: number }>('/downloads/queue', { tracks, outputDir, contentName })
+  const { queued } = await apiPost<{ queued: number }>('/downloads/queue', {
+    tracks,
+    outputDir,
+    contentName,
+    contentId,
+    contentType,
+  })
   return queued
 }
 
@@ -290,6 +679,22 @@ export async function QueueSingleDownload(
   await apiPost('/downloads/single', { trackId, outputDir, title, artist })
 }
 
+/** User edits applied to one track's filename and tags; empty fields keep the source value. */
+export interface TrackOverrides {
+  title?: string
+  artist?: string
+  album?: string
+  trackNumber?: number
+}
+
+/** Sets (or, with an empty object, clears) overrides for a track about to be queued. */
+export async function SetTrackOverrides(trackId: number, overrides: TrackOverrides): Promise<void> {
+  if (isWailsRuntime()) {
+    return Wails.SetTrackOverrides(trackId, overrides as any)
+  }
+  await apiPost(`/downloads/overrides/${trackId}`, overrides)
+}
+
 export async function QueueArtistAlbum(albumId: string, artistName: string, outputDir: string): Promise<number> {
   if (isWailsRuntime()) {
     return Wails.QueueArtistAlbum(albumId, artistName, outputDir)
@@ -321,6 +726,66 @@ export async function GetDownloadHistoryFiltered(
   return apiGet(`/history/filtered${query}`)
 }
 
+/** Per-track download log (title, quality, cover…), newest first. */
+// Downloads per weekday and hour: counts[day][hour], Sunday first.
+export interface ActivityHeatmap {
+  days: number
+  counts: number[][]
+  byDay: number[]
+  byHour: number[]
+  total: number
+  max: number
+}
+// days is the period counted; 0 means all time.
+export async function GetActivityHeatmap(days = 30): Promise<ActivityHeatmap> {
+  if (isWailsRuntime()) {
+    return Wails.GetActivityHeatmap(days) as unknown as Promise<ActivityHeatmap>
+  }
+  return apiGet(`/history/heatmap${qs({ days, tz: Intl.DateTimeFormat().resolvedOptions().timeZone })}`)
+}
+// What a history record's content looked like when it was queued, by
+// content ID, so records of deleted playlists still show their cover and
+// description. `cover` is set once a playlist's cover is archived.
+export interface HistorySnapshot {
+  title?: string
+  creator?: string
+  description?: string
+  coverUrl?: string
+  cover?: string
+}
+export async function GetHistorySnapshots(): Promise<Record<string, HistorySnapshot>> {
+  if (isWailsRuntime()) {
+    return Wails.GetHistorySnapshots() as any
+  }
+  return apiGet('/history/snapshots')
+}
+// Per-track download timings kept with history, keyed by track ID.
+export async function GetHistoryTimings(): Promise<Record<string, JobTiming>> {
+  if (isWailsRuntime()) {
+    return Wails.GetHistoryTimings() as any
+  }
+  return apiGet('/history/timings')
+}
+export async function GetHistoryCover(contentId: string): Promise<{ data: string; mimeType: string }> {
+  if (isWailsRuntime()) {
+    return Wails.GetHistoryCover(contentId) as unknown as Promise<{ data: string; mimeType: string }>
+  }
+  return apiGet(`/history/cover${qs({ id: contentId })}`)
+}
+export async function GetHistoryFile(trackId: number): Promise<string> {
+  if (isWailsRuntime()) {
+    return Wails.GetHistoryFile(trackId)
+  }
+  const res = await apiGet<{ path: string }>(`/history/file${qs({ trackId })}`)
+  return res.path
+}
+export async function GetTrackHistory(limit = 50, offset = 0): Promise<{ entries: any[]; total: number }> {
+  if (isWailsRuntime()) {
+    return Wails.GetTrackHistory(limit, offset) as unknown as Promise<{ entries: any[]; total: number }>
+  }
+  return apiGet(`/track-history${qs({ limit, offset })}`)
+}
+
 export async function DeleteHistoryRecord(id: number): Promise<void> {
   if (isWailsRuntime()) {
     return Wails.DeleteHistoryRecord(id)
@@ -377,135 +842,1004 @@ export async function GetFileCoverArt(filePath: string): Promise<{ data: string;
   return apiGet(`/files/cover?path=${encodeURIComponent(filePath)}`)
 }
 
-export async function GetRenameTemplates(): Promise<Array<{ name: string; template: string }>> {
+// An image source for a small thumbnail of a FLAC's cover, for list views.
+// The server serves the image itself, so the browser caches it; the
+// desktop app gets it as base64.
+export async function FileThumbnailSrc(filePath: string, size = 0): Promise<string> {
   if (isWailsRuntime()) {
-    return Wails.GetRenameTemplates() as unknown as Promise<Array<{ name: string; template: string }>>
+    const t = await Wails.GetFileThumbnail(filePath, size)
+    return `data:${t.mimeType};base64,${t.data}`
   }
-  return apiGet('/files/templates')
+  const token = APIToken()
+  return `${API_BASE}/files/cover/thumbnail${qs({ path: filePath, size, token })}`
 }
 
-export async function PreviewRename(files: string[], template: string): Promise<RenamePreview[]> {
-  if (isWailsRuntime()) {
-    return Wails.PreviewRename(files, template)
-  }
-  return apiPost('/files/rename/preview', { files, template })
-}
+// Image hosts the server's cover proxy fetches from, and their subdomains
+// (coverproxy.Hosts).
+const coverHosts = ['resources.tidal.com', 'static.qobuz.com', 'dzcdn.net', 'coverartarchive.org', 'archive.org', 'scdn.co', 'mzstatic.com']
 
-export async function RenameFiles(files: string[], template: string): Promise<RenameResult[]> {
-  if (isWailsRuntime()) {
-    return Wails.RenameFiles(files, template)
+// An image source for a source's cover or artist picture. In a browser the
+// server fetches it (GET /api/proxy/cover), for networks that block the
+// image hosts; the desktop app loads it directly.
+export function CoverSrc(url: string | undefined): string {
+  if (!url || isWailsRuntime()) return url || ''
+  let host: string
+  try {
+    host = new URL(url).hostname.toLowerCase()
+  } catch {
+    return url
   }
-  return apiPost('/files/rename', { files, template })
+  if (!coverHosts.some(h => host === h || host.endsWith('.' + h))) return url
+  return `${API_BASE}/proxy/cover${qs({ url, token: APIToken() })}`
 }
 
-// ---------------------------------------------------------------------------
-// Conversion
-// ---------------------------------------------------------------------------
+// Every picture embedded in a FLAC file: front and back covers, artist
+// photos… `type` is the FLAC PICTURE type (3 = front cover, 4 = back cover,
+// 8 = artist); `index` identifies it for RemoveFilePicture.
+export interface PictureInfo {
+  index: number
+  type: number
+  typeName: string
+  mimeType: string
+  description: string
+  width: number
+  height: number
+  size: number
+  data: string
+}
 
-export async function ConvertFiles(
-  files: string[],
-  format: string,
-  quality: string,
-  outputDir: string,
-  deleteSource: boolean
-): Promise<ConversionResult[]> {
+export async function ListFilePictures(path: string): Promise<PictureInfo[]> {
   if (isWailsRuntime()) {
-    return Wails.ConvertFiles(files, format, quality, outputDir, deleteSource)
+    return Wails.ListFilePictures(path) as any
   }
-  return apiPost('/convert', { files, format, quality, outputDir, deleteSource })
+  return apiGet(`/files/pictures?path=${encodeURIComponent(path)}`)
 }
-
-export async function GetConversionFormats(): Promise<ConversionFormat[]> {
+export async function AddFilePicture(path: string, data: string, type: number, description = ''): Promise<void> {
   if (isWailsRuntime()) {
-    return Wails.GetConversionFormats()
+    return Wails.AddFilePicture(path, data, type, description)
   }
-  return apiGet('/convert/formats')
+  await apiPost('/files/pictures', { path, data, type, description })
 }
-
-export async function GetFFmpegInfo(): Promise<any> {
+export async function RemoveFilePicture(path: string, index: number): Promise<void> {
   if (isWailsRuntime()) {
-    return Wails.GetFFmpegInfo()
+    return Wails.RemoveFilePicture(path, index)
   }
-  return apiGet('/convert/ffmpeg')
+  await apiDelete(`/files/pictures?path=${encodeURIComponent(path)}&index=${index}`)
 }
 
-export async function IsConverterAvailable(): Promise<boolean> {
+// Writes a file's front cover to output, or beside it as cover.jpg /
+// cover.png when output is empty. Returns the path written.
+export async function SaveCoverArt(path: string, output = ''): Promise<string> {
   if (isWailsRuntime()) {
-    return Wails.IsConverterAvailable()
+    return Wails.SaveCoverArt(path, output)
   }
-  const { available } = await apiGet<{ available: boolean }>('/convert/available')
-  return available
+  const { path: written } = await apiPost<{ path: string }>('/files/cover/save', { path, output })
+  return written
 }
 
-// ---------------------------------------------------------------------------
-// Config / App info / Folder
-// ---------------------------------------------------------------------------
+// One folder's outcome of ExtractFolderCovers: the cover written, or the
+// one kept when `skipped`.
+export interface FolderCover {
+  folder: string
+  path?: string
+  skipped?: boolean
+  error?: string
+}
 
-export async function GetConfig(): Promise<any> {
+// Saves a cover.jpg / cover.png beside the FLACs in every folder under
+// folder, keeping existing cover files unless overwrite is set.
+export async function ExtractFolderCovers(folder: string, overwrite = false): Promise<FolderCover[]> {
   if (isWailsRuntime()) {
-    return Wails.GetConfig()
+    return Wails.ExtractFolderCovers(folder, overwrite) as any
   }
-  return apiGet('/config')
+  return apiPost('/files/covers/save', { folder, overwrite })
 }
 
-export async function GetAppVersion(): Promise<string> {
+// The distinct covers in the download folder, each stored once under its
+// hash however many tracks embed it. `embeddedBytes` counts every track's
+// copy; `uniqueBytes` each cover once.
+export interface LibraryCover {
+  hash: string
+  size: number
+  tracks: number
+  path: string
+}
+
+export interface LibraryCovers {
+  files: number
+  withCover: number
+  embeddedBytes: number
+  uniqueBytes: number
+  covers: LibraryCover[]
+}
+
+export async function GetLibraryCovers(): Promise<LibraryCovers> {
   if (isWailsRuntime()) {
-    return Wails.GetAppVersion()
+    return Wails.GetLibraryCovers() as any
   }
-  // Known gap: the REST server currently reports a hardcoded "1.0.0"
-  // instead of the real build version (see migration report).
-  const { version } = await apiGet<{ version: string }>('/version')
-  return version
+  return apiGet('/covers')
 }
-
-export async function GetDownloadFolder(): Promise<string> {
+export async function GetCoverThumbnail(hash: string, size = 0): Promise<{ data: string; mimeType: string }> {
   if (isWailsRuntime()) {
-    return Wails.GetDownloadFolder()
+    return Wails.GetCoverThumbnail(hash, size) as unknown as Promise<{ data: string; mimeType: string }>
   }
-  const { folder } = await apiGet<{ folder: string }>('/folder')
-  return folder
+  return apiGet(`/covers/${hash}/thumbnail?size=${size}`)
 }
 
-// ---------------------------------------------------------------------------
-// Logs
-//
-// Known gap: the headless server's /api/logs and /api/logs/clear are stubs
-// (no server-side log buffer wired up yet, unlike the Wails app's
-// logBuffer) — GetLogs() always resolves to [] in browser mode and
-// ClearLogs() is a no-op. Pre-existing backend limitation, out of scope for
-// this migration. See migration report.
-// ---------------------------------------------------------------------------
+// The library index: every FLAC under the download folder and the external
+// library paths, by its embedded tags, kept in library.db. Browsing queries
+// the index; ScanLibrary updates it, reading only files that changed.
+export interface LibraryTrack {
+  path: string
+  root: string
+  title: string
+  artist: string
+  albumArtist?: string
+  album: string
+  genre?: string
+  year?: string
+  label?: string
+  catalogNumber?: string
+  trackNumber?: number
+  discNumber?: number
+  isrc?: string
+  md5?: string // STREAMINFO's audio MD5, hex
+  quality?: string
+  sampleRate?: number
+  bitDepth?: number
+  channels?: number
+  duration: number
+  bitrate?: number // kbps
+  size: number
+  modTime: string
+  error?: string
+}
 
-export async function GetLogs(): Promise<LogEntry[]> {
+// What BrowseLibrary, GetLibraryArtists, GetLibraryAlbums and
+// GetLibraryLabels select. `sort` is artist, album, title, year, label,
+// modified, duration, size, quality or path; `limit` defaults to 100, at
+// most 1000.
+export interface LibraryQuery {
+  search?: string
+  artist?: string
+  album?: string
+  genre?: string
+  label?: string
+  root?: string
+  sort?: string
+  desc?: boolean
+  limit?: number
+  offset?: number
+}
+
+export interface LibraryPage {
+  total: number
+  limit: number
+  offset: number
+  tracks: LibraryTrack[]
+}
+
+export interface LibraryArtist {
+  name: string
+  albums: number
+  tracks: number
+}
+
+export interface LibraryAlbum {
+  title: string
+  artist: string
+  year?: string
+  genre?: string
+  label?: string
+  catalogNumber?: string
+  tracks: number
+  duration: number
+  size: number
+  path: string
+}
+
+export interface LibraryLabel {
+  name: string
+  albums: number
+  tracks: number
+}
+
+export interface LibraryScanReport {
+  roots: string[]
+  files: number
+  added: number
+  updated: number
+  removed: number
+  failed: number
+  pending?: number // still being written, left for the next check (Watch Library)
+  errors?: string[]
+  duration: number
+}
+
+export interface LibraryStatus {
+  tracks: number
+  scanning: boolean
+  lastScan?: LibraryScanReport
+  scanTime?: string
+}
+
+export async function GetLibraryStatus(): Promise<LibraryStatus> {
   if (isWailsRuntime()) {
-    return Wails.GetLogs()
+    return Wails.GetLibraryStatus() as any
   }
-  return apiGet('/logs')
+  return apiGet('/library')
 }
 
-export async function ClearLogs(): Promise<void> {
+// Updates the index; "library-scan-progress" events ({ files }) report the
+// files found so far.
+export async function ScanLibrary(): Promise<LibraryScanReport> {
   if (isWailsRuntime()) {
-    return Wails.ClearLogs()
+    return Wails.ScanLibrary() as any
   }
-  await apiPost('/logs/clear')
+  return apiPost('/library/scan')
 }
 
-// ---------------------------------------------------------------------------
-// Content / Search
-// ---------------------------------------------------------------------------
-
-export async function FetchContentFromURL(url: string): Promise<any> {
+export async function BrowseLibrary(q: LibraryQuery): Promise<LibraryPage> {
   if (isWailsRuntime()) {
-    return Wails.FetchContentFromURL(url)
+    return Wails.BrowseLibrary(q as any) as any
   }
-  return apiPost('/content/fetch', { url })
+  return apiGet(`/library/tracks${qs({ ...q })}`)
 }
 
-export async function SearchTidal(query: string): Promise<any[]> {
+export async function GetLibraryArtists(q: LibraryQuery = {}): Promise<LibraryArtist[]> {
   if (isWailsRuntime()) {
-    return Wails.SearchTidal(query)
+    return Wails.GetLibraryArtists(q as any) as any
   }
-  return apiGet(`/content/search${qs({ q: query })}`)
+  return apiGet(`/library/artists${qs({ ...q })}`)
+}
+
+export async function GetLibraryAlbums(q: LibraryQuery = {}): Promise<LibraryAlbum[]> {
+  if (isWailsRuntime()) {
+    return Wails.GetLibraryAlbums(q as any) as any
+  }
+  return apiGet(`/library/albums${qs({ ...q })}`)
+}
+
+// Albums filtered by a label come in catalog number order.
+export async function GetLibraryLabels(q: LibraryQuery = {}): Promise<LibraryLabel[]> {
+  if (isWailsRuntime()) {
+    return Wails.GetLibraryLabels(q as any) as any
+  }
+  return apiGet(`/library/labels${qs({ ...q })}`)
+}
+
+// Files holding the same track: sharing an ISRC or an audio MD5 (identical
+// when every file has the same MD5). Tracks come best copy first; deleting
+// the rest is a 'delete' batch.
+export interface LibraryDuplicateGroup {
+  isrc?: string
+  md5?: string
+  identical: boolean
+  tracks: LibraryTrack[]
+  wasted: number // bytes taken by all but the first
+}
+
+export async function GetLibraryDuplicates(by: '' | 'isrc' | 'md5' = ''): Promise<LibraryDuplicateGroup[]> {
+  if (isWailsRuntime()) {
+    return Wails.GetLibraryDuplicates(by) as any
+  }
+  return apiGet(`/library/duplicates${qs({ by })}`)
+}
+
+export async function GetRenameTemplates(): Promise<Array<{ name: string; template: string }>> {
+  if (isWailsRuntime()) {
+    return Wails.GetRenameTemplates() as unknown as Promise<Array<{ name: string; template: string }>>
+  }
+  return apiGet('/files/templates')
+}
+
+export async function PreviewRename(files: string[], template: string): Promise<RenamePreview[]> {
+  if (isWailsRuntime()) {
+    return Wails.PreviewRename(files, template)
+  }
+  return apiPost('/files/rename/preview', { files, template })
+}
+
+export async function RenameFiles(files: string[], template: string): Promise<RenameResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.RenameFiles(files, template)
+  }
+  return apiPost('/files/rename', { files, template })
+}
+// Batch tag editor. In 'merge' mode other tags are kept and an empty value
+// removes a tag; in 'replace' mode the files keep only the given tags.
+export async function PreviewSetTags(files: string[], tags: Record<string, string>, mode: 'merge' | 'replace' = 'merge'): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.PreviewSetTags(files, tags, mode)
+  }
+  return apiPost('/files/tags/preview', { files, tags, mode })
+}
+export async function SetTags(files: string[], tags: Record<string, string>, mode: 'merge' | 'replace' = 'merge'): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.SetTags(files, tags, mode)
+  }
+  return apiPost('/files/tags', { files, tags, mode })
+}
+// Removes the named tags, all tags and/or the embedded covers.
+export async function PreviewStripTags(files: string[], opts: StripOptions): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.PreviewStripTags(files, opts as any)
+  }
+  return apiPost('/files/tags/strip/preview', { files, ...opts })
+}
+export async function StripTags(files: string[], opts: StripOptions): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.StripTags(files, opts as any)
+  }
+  return apiPost('/files/tags/strip', { files, ...opts })
+}
+// Reads tags from file names with a pattern such as
+// '{track} - {artist} - {title}'; apply it with the 'filename' batch op.
+export async function PreviewTagsFromNames(files: string[], pattern: string): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.PreviewTagsFromNames(files, pattern)
+  }
+  return apiPost('/files/tags/filename/preview', { files, pattern })
+}
+// Applies normalization rules to title, artist and album tags; apply them
+// with the 'normalize' batch op.
+export async function PreviewNormalizeTags(files: string[], rules: TagRules): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.PreviewNormalizeTags(files, rules as any)
+  }
+  return apiPost('/files/tags/normalize/preview', { files, rules })
+}
+// Renames genres with the genreMap setting; apply it with the 'genres'
+// batch op.
+export async function PreviewRemapGenres(files: string[]): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.PreviewRemapGenres(files)
+  }
+  return apiPost('/files/tags/genres/preview', { files })
+}
+// MusicBrainz lookups are rate limited to about one file per second.
+export async function PreviewMusicBrainzTags(files: string[]): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.PreviewMusicBrainzTags(files)
+  }
+  return apiPost('/files/musicbrainz/preview', { files })
+}
+export async function MusicBrainzTags(files: string[]): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.MusicBrainzTags(files)
+  }
+  return apiPost('/files/musicbrainz', { files })
+}
+// AcoustID identifies files by their audio, for files with missing or
+// garbage tags. It needs fpcalc (Chromaprint) and an API key in Settings.
+export interface AcoustIDInfo {
+  available: boolean
+  path?: string
+  version?: string
+  keySet: boolean
+}
+export async function GetAcoustIDInfo(): Promise<AcoustIDInfo> {
+  if (isWailsRuntime()) {
+    return Wails.GetAcoustIDInfo()
+  }
+  return apiGet('/files/acoustid/status')
+}
+export async function PreviewAcoustIDTags(files: string[]): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.PreviewAcoustIDTags(files)
+  }
+  return apiPost('/files/acoustid/preview', { files })
+}
+export async function AcoustIDTags(files: string[]): Promise<TagEditResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.AcoustIDTags(files)
+  }
+  return apiPost('/files/acoustid', { files })
+}
+export async function StartBatch(req: BatchRequest): Promise<Batch> {
+  if (isWailsRuntime()) {
+    return Wails.StartBatch(req as any) as any
+  }
+  return apiPost('/batches', req)
+}
+export async function GetBatch(id: string): Promise<Batch> {
+  if (isWailsRuntime()) {
+    return Wails.GetBatch(id) as any
+  }
+  return apiGet(`/batches/${encodeURIComponent(id)}`)
+}
+export async function ListBatches(): Promise<Batch[]> {
+  if (isWailsRuntime()) {
+    return Wails.ListBatches() as any
+  }
+  return apiGet('/batches')
+}
+export async function UndoBatch(id: string): Promise<Batch> {
+  if (isWailsRuntime()) {
+    return Wails.UndoBatch(id) as any
+  }
+  return apiPost(`/batches/${encodeURIComponent(id)}/undo`, {})
+}
+
+export interface SplitPiece {
+  track: { number: number; title: string; performer?: string; isrc?: string; start: number }
+  path: string
+  startSample: number
+  endSample?: number
+  error?: string
+}
+
+export interface SplitReport {
+  file: string
+  album: string
+  split: number
+  pieces: SplitPiece[]
+}
+
+/**
+ * Splits a single-file album rip (FLAC or WAV) into one tagged FLAC per
+ * track beside it. `cueOrTracklist` is a .cue or tracklist file path,
+ * pasted tracklist text or an album URL; empty uses the .cue named like
+ * the rip. Needs FFmpeg.
+ */
+export async function SplitAlbum(file: string, cueOrTracklist: string): Promise<SplitReport> {
+  if (isWailsRuntime()) {
+    return Wails.SplitAlbum(file, cueOrTracklist) as Promise<SplitReport>
+  }
+  return apiPost<SplitReport>('/files/split', { file, cue: cueOrTracklist })
+}
+
+export interface SilenceReport {
+  path: string
+  duration: number
+  leading: number
+  trailing: number
+  regions: Array<{ start: number; end: number }>
+  trimmed: boolean
+}
+
+/**
+ * Trims the leading and trailing silence of a FLAC, using the silence
+ * thresholds in settings. With `dryRun` the silence is only detected, for
+ * a preview. Needs FFmpeg.
+ */
+export async function TrimSilence(file: string, dryRun: boolean): Promise<SilenceReport> {
+  if (isWailsRuntime()) {
+    return Wails.TrimSilence(file, dryRun) as Promise<SilenceReport>
+  }
+  return apiPost<SilenceReport>('/files/silence', { file, dryRun })
+}
+
+export interface IncompleteFile {
+  path: string
+  root: string
+  kind: 'partial' | 'empty'
+  size: number
+  modTime: string
+  trackId?: number
+  track?: string
+}
+
+/**
+ * Lists the partial files and zero-byte FLACs interrupted downloads left in
+ * the library folders. `trackId` is set when a failed download matches.
+ */
+export async function ListIncompleteFiles(): Promise<IncompleteFile[]> {
+  if (isWailsRuntime()) {
+    return Wails.ListIncompleteFiles() as unknown as Promise<IncompleteFile[]>
+  }
+  return apiGet<IncompleteFile[]>('/files/incomplete')
+}
+
+export async function DeleteIncompleteFile(path: string): Promise<void> {
+  if (isWailsRuntime()) {
+    return Wails.DeleteIncompleteFile(path)
+  }
+  await apiDelete(`/files/incomplete?path=${encodeURIComponent(path)}`)
+}
+
+/** Deletes an incomplete file and downloads its failed track again. */
+export async function RequeueIncompleteFile(path: string): Promise<void> {
+  if (isWailsRuntime()) {
+    return Wails.RequeueIncompleteFile(path)
+  }
+  await apiPost('/files/incomplete/requeue', { path })
+}
+
+/** Marks the errors of files refused by strict validation (see isBrokenFLACError). */
+const BROKEN_FLAC = 'broken FLAC'
+
+/** Reports whether an error came from a FLAC that failed validation. */
+export function isBrokenFLACError(message: string | undefined): boolean {
+  return !!message && message.includes(BROKEN_FLAC)
+}
+
+/**
+ * Checks the structure of a FLAC without decoding it. Returns why the file
+ * is broken, or '' for a sound file.
+ */
+export async function ValidateFLAC(path: string): Promise<string> {
+  if (isWailsRuntime()) {
+    return Wails.ValidateFLAC(path)
+  }
+  const res = await apiGet<{ broken: boolean; reason: string }>(`/files/validate?path=${encodeURIComponent(path)}`)
+  return res.reason
+}
+
+export interface Verification {
+  path: string
+  ok: boolean
+  md5: string // of the decoded audio
+  expectedMd5?: string // STREAMINFO's; absent when the encoder didn't record one
+  samples: number
+  expectedSamples?: number
+  problems?: string[]
+}
+
+/**
+ * Fully decodes each file and checks it against the MD5 signature in its
+ * STREAMINFO, like `flac -t`. Needs FFmpeg.
+ */
+export async function VerifyFiles(paths: string[]): Promise<Verification[]> {
+  if (isWailsRuntime()) {
+    return Wails.VerifyFiles(paths)
+  }
+  return apiPost<Verification[]>('/analyze/verify', { paths })
+}
+
+/** VerifyFiles for every FLAC file under folder. */
+export async function VerifyFolder(folder: string): Promise<Verification[]> {
+  if (isWailsRuntime()) {
+    return Wails.VerifyFolder(folder)
+  }
+  return apiPost<Verification[]>('/analyze/verify', { folder })
+}
+
+// What CompareFiles weighs of each copy (see internal/analysis).
+export interface ComparedVersion {
+  path: string
+  sampleRate: number
+  bitDepth: number
+  effectiveBitDepth: number
+  cutoff: number // spectral cutoff, Hz
+  verdict: string
+  verdictLabel: string
+  mqa: boolean
+  truncated: boolean
+  clipping: boolean
+  clippedPercent: number
+  loudness?: { integrated: number; range: number; truePeak: number }
+  audioMd5?: string
+}
+
+export interface Comparison {
+  a: ComparedVersion
+  b: ComparedVersion
+  sameAudio: boolean // identical decoded audio
+  keep: '' | 'a' | 'b' // '' when neither is better
+  reason: string
+  differences: string[] // e.g. "sample rate: 96 kHz vs 44.1 kHz"
+}
+
+/** Analyzes two copies of a track and tells which is the better keep. */
+export async function CompareFiles(a: string, b: string): Promise<Comparison> {
+  if (isWailsRuntime()) {
+    return Wails.CompareFiles(a, b) as any
+  }
+  return apiPost<Comparison>('/analyze/compare', { a, b })
+}
+
+// CheckAlbum's findings: the tracks of an album that stand out from the rest.
+export interface AlbumReport {
+  folder: string
+  tracks: ComparedVersion[]
+  format: string // most tracks', e.g. "96 kHz/24-bit"
+  verdict: string // most tracks'
+  consistent: boolean
+  issues: { path: string; problem: string }[]
+}
+
+/** Analyzes the album in folder and flags tracks unlike the rest. */
+export async function CheckAlbum(folder: string): Promise<AlbumReport> {
+  if (isWailsRuntime()) {
+    return Wails.CheckAlbum(folder) as any
+  }
+  return apiPost<AlbumReport>('/analyze/album', { folder })
+}
+
+// Background analysis jobs (see internal/jobs): queued, run on a worker
+// pool with "analysis-progress" events, and cancellable. Each done item's
+// result is an AnalysisResult in both modes.
+export type AnalysisJobState = 'queued' | 'running' | 'done' | 'cancelled'
+
+export interface AnalysisJobItem {
+  path: string
+  done: boolean
+  result?: AnalysisResult
+  error?: string
+}
+
+export interface AnalysisJob {
+  id: string
+  kind: string
+  state: AnalysisJobState
+  total: number
+  processed: number
+  failed: number
+  summary?: Record<string, number> // files by verdict, plus "error"
+  items?: AnalysisJobItem[] // left out by ListAnalysisJobs
+  queued: string
+  started?: string
+  finished?: string
+}
+
+export interface AnalysisJobEvent {
+  id: string
+  kind: string
+  state: AnalysisJobState
+  total: number
+  processed: number
+  failed: number
+  summary?: Record<string, number>
+  item?: AnalysisJobItem
+}
+
+/** Queues an analysis of paths; unchanged files reuse their saved analysis unless force. */
+export async function StartAnalysis(paths: string[], force = false): Promise<AnalysisJob> {
+  if (isWailsRuntime()) {
+    return Wails.StartAnalysis(paths, force) as any
+  }
+  return apiPost('/analyze/jobs', { paths, force })
+}
+/** Queues an analysis of the FLACs in folder (and, if recursive, below it). */
+export async function AnalyzeFolder(folder: string, recursive: boolean, force = false): Promise<AnalysisJob> {
+  if (isWailsRuntime()) {
+    return Wails.AnalyzeFolder(folder, recursive, force) as any
+  }
+  return apiPost('/analyze/folder', { folder, recursive, force })
+}
+export async function GetAnalysisJob(id: string): Promise<AnalysisJob> {
+  if (isWailsRuntime()) {
+    return Wails.GetAnalysisJob(id) as any
+  }
+  return apiGet(`/analyze/jobs/${encodeURIComponent(id)}`)
+}
+export async function ListAnalysisJobs(): Promise<AnalysisJob[]> {
+  if (isWailsRuntime()) {
+    return Wails.ListAnalysisJobs() as any
+  }
+  return apiGet('/analyze/jobs')
+}
+export async function CancelAnalysisJob(id: string): Promise<AnalysisJob> {
+  if (isWailsRuntime()) {
+    return Wails.CancelAnalysisJob(id) as any
+  }
+  return apiPost(`/analyze/jobs/${encodeURIComponent(id)}/cancel`)
+}
+
+/**
+ * Saves the library's quality report: every indexed track with its saved
+ * analysis verdict, as CSV or JSON. Returns the saved path on desktop (''
+ * if cancelled); in the browser the file is downloaded and '' returned.
+ */
+export async function ExportQualityReport(format: 'csv' | 'json'): Promise<string> {
+  if (isWailsRuntime()) {
+    return Wails.ExportQualityReport(format)
+  }
+
+  const res = await fetch(`${API_BASE}/analyze/report${qs({ format })}`, withToken())
+  if (!res.ok) {
+    const body = await res.json().catch(() => null)
+    throw new Error(body?.error || `${res.status} ${res.statusText}`)
+  }
+  const name = /filename="([^"]+)"/.exec(res.headers.get('Content-Disposition') || '')?.[1]
+  const blob = await res.blob()
+  const href = URL.createObjectURL(blob)
+  const a = document.createElement('a')
+  a.href = href
+  a.download = name || `quality-report.${format}`
+  document.body.appendChild(a)
+  a.click()
+  a.remove()
+  URL.revokeObjectURL(href)
+  return ''
+}
+
+/**
+ * Queues the Tidal track a broken FLAC was downloaded from, found by its
+ * ISRC or artist and title, and moves the broken file aside as `.broken`.
+ */
+export async function RedownloadBrokenFile(path: string): Promise<any> {
+  if (isWailsRuntime()) {
+    return Wails.RedownloadBrokenFile(path)
+  }
+  return apiPost('/files/redownload', { file: path })
+}
+
+// ---------------------------------------------------------------------------
+// Conversion
+// ---------------------------------------------------------------------------
+
+// Payload of the "conversion-progress" events ConvertFiles emits as ffmpeg
+// works through each file (see internal/convert). percent is of the current
+// file, batchPercent of them all. Conversion jobs fill in file and percent
+// only.
+export interface ConversionProgress {
+  file: string
+  index: number
+  total: number
+  percent: number
+  done: number
+  batchPercent: number
+}
+
+export async function ConvertFiles(
+  files: string[],
+  format: string,
+  quality: string,
+  outputDir: string,
+  deleteSource: boolean
+): Promise<ConversionResult[]> {
+  if (isWailsRuntime()) {
+    return Wails.ConvertFiles(files, format, quality, outputDir, deleteSource)
+  }
+  return apiPost('/convert', { files, format, quality, outputDir, deleteSource })
+}
+
+// Background conversion jobs (see internal/jobs), like the analysis jobs:
+// queued, run ConversionWorkers files at a time with
+// "conversion-job-progress" events, and cancellable. Each converted item's
+// result is a ConversionResult; "conversion-progress" events report each
+// running file's percent.
+export interface ConversionJobItem {
+  path: string
+  done: boolean
+  result?: ConversionResult
+  error?: string
+}
+
+export interface ConversionJob {
+  id: string
+  kind: string
+  state: AnalysisJobState
+  total: number
+  processed: number
+  failed: number
+  items?: ConversionJobItem[] // left out by ListConversionJobs
+  queued: string
+  started?: string
+  finished?: string
+}
+
+export interface ConversionJobEvent {
+  id: string
+  kind: string
+  state: AnalysisJobState
+  total: number
+  processed: number
+  failed: number
+  item?: ConversionJobItem
+}
+
+export async function StartConversion(
+  files: string[],
+  format: string,
+  quality: string,
+  outputDir: string,
+  deleteSource: boolean
+): Promise<ConversionJob> {
+  if (isWailsRuntime()) {
+    return Wails.StartConversion(files, format, quality, outputDir, deleteSource) as any
+  }
+  return apiPost('/convert/jobs', { files, format, quality, outputDir, deleteSource })
+}
+export async function GetConversionJob(id: string): Promise<ConversionJob> {
+  if (isWailsRuntime()) {
+    return Wails.GetConversionJob(id) as any
+  }
+  return apiGet(`/convert/jobs/${encodeURIComponent(id)}`)
+}
+export async function ListConversionJobs(): Promise<ConversionJob[]> {
+  if (isWailsRuntime()) {
+    return Wails.ListConversionJobs() as any
+  }
+  return apiGet('/convert/jobs')
+}
+export async function CancelConversionJob(id: string): Promise<ConversionJob> {
+  if (isWailsRuntime()) {
+    return Wails.CancelConversionJob(id) as any
+  }
+  return apiPost(`/convert/jobs/${encodeURIComponent(id)}/cancel`)
+}
+
+export async function GetConversionFormats(): Promise<ConversionFormat[]> {
+  if (isWailsRuntime()) {
+    return Wails.GetConversionFormats()
+  }
+  return apiGet('/convert/formats')
+}
+
+export async function GetFFmpegInfo(): Promise<any> {
+  if (isWailsRuntime()) {
+    return Wails.GetFFmpegInfo()
+  }
+  return apiGet('/convert/ffmpeg')
+}
+
+export interface DoctorResult {
+  name: string
+  status: 'pass' | 'warn' | 'fail' | 'skip'
+  detail: string
+  durationMs: number
+}
+
+export interface DoctorReport {
+  results: DoctorResult[]
+  ok: boolean // no check failed
+  startedAt: string
+}
+
+/** Runs the self-check; download adds a test download of a short track. */
+export async function RunDoctor(download = false): Promise<DoctorReport> {
+  if (isWailsRuntime()) {
+    return Wails.RunDoctor(download) as unknown as Promise<DoctorReport>
+  }
+  return apiPost('/doctor', { download })
+}
+
+export async function IsConverterAvailable(): Promise<boolean> {
+  if (isWailsRuntime()) {
+    return Wails.IsConverterAvailable()
+  }
+  const { available } = await apiGet<{ available: boolean }>('/convert/available')
+  return available
+}
+
+// ---------------------------------------------------------------------------
+// Config / App info / Folder
+// ---------------------------------------------------------------------------
+
+export async function GetConfig(): Promise<any> {
+  if (isWailsRuntime()) {
+    return Wails.GetConfig()
+  }
+  return apiGet('/config')
+}
+
+/** App-local settings (settings.json) — options implemented outside flacidal-core's Config. */
+export async function GetSettings(): Promise<any> {
+  if (isWailsRuntime()) {
+    return Wails.GetSettings()
+  }
+  return apiGet('/settings')
+}
+
+export async function SaveSettings(settings: any): Promise<ConfigChange[]> {
+  if (isWailsRuntime()) {
+    return Wails.SaveSettings(settings)
+  }
+  const res = await apiPost<{ changes?: ConfigChange[] }>('/settings', settings)
+  return res?.changes ?? []
+}
+
+/** Filename template tokens, for the settings UI's variable list. */
+export async function GetFilenameTokens(): Promise<any[]> {
+  if (isWailsRuntime()) {
+    return Wails.GetFilenameTokens()
+  }
+  return apiGet('/filename-tokens')
+}
+
+export async function GetAppVersion(): Promise<string> {
+  if (isWailsRuntime()) {
+    return Wails.GetAppVersion()
+  }
+  // Known gap: the REST server currently reports a hardcoded "1.0.0"
+  // instead of the real build version (see migration report).
+  const { version } = await apiGet<{ version: string }>('/version')
+  return version
+}
+
+/** Locale and time zone to format the API's UTC timestamps in. */
+export async function GetLocaleHint(): Promise<{ locale: string; timeZone: string }> {
+  if (isWailsRuntime()) {
+    return Wails.GetLocaleHint()
+  }
+  return apiGet('/locale')
+}
+
+/** What the server lets this browser do: see internal/api/auth.go. */
+export interface Access {
+  role: 'admin' | 'guest' | '' // '' when the server wants a token and the saved one (if any) is wrong
+  required: boolean // whether the server asks for a token at all
+}
+
+/**
+ * The caller's access to the server. The desktop app always has full access.
+ * Browser: GET /api/access, where a 401 means a (valid) token is needed.
+ */
+export async function GetAccess(): Promise<Access> {
+  if (isWailsRuntime()) {
+    return { role: 'admin', required: false }
+  }
+  const res = await fetch(`${API_BASE}/access`, withToken())
+  if (res.status === 401) {
+    return { role: '', required: true }
+  }
+  if (!res.ok) {
+    throw new Error(`${res.status} ${res.statusText}`)
+  }
+  return res.json()
+}
+
+export async function GetDownloadFolder(): Promise<string> {
+  if (isWailsRuntime()) {
+    return Wails.GetDownloadFolder()
+  }
+  const { folder } = await apiGet<{ folder: string }>('/folder')
+  return folder
+}
+
+// ---------------------------------------------------------------------------
+// Logs
+//
+// Known gap: the headless server's /api/logs and /api/logs/clear are stubs
+// (no server-side log buffer wired up yet, unlike the Wails app's
+// logBuffer) — GetLogs() always resolves to [] in browser mode and
+// ClearLogs() is a no-op. Pre-existing backend limitation, out of scope for
+// this migration. See migration report.
+// ---------------------------------------------------------------------------
+
+export async function GetLogs(): Promise<LogEntry[]> {
+  if (isWailsRuntime()) {
+    return Wails.GetLogs()
+  }
+  return apiGet('/logs')
+}
+
+export async function ClearLogs(): Promise<void> {
+  if (isWailsRuntime()) {
+    return Wails.ClearLogs()
+  }
+  await apiPost('/logs/clear')
+}
+
+export interface LogLevels {
+  /** Component name → level; "*" is the default for unlisted components. */
+  levels: Record<string, string>
+  components: string[]
+}
+
+export async function GetLogLevels(): Promise<LogLevels> {
+  if (isWailsRuntime()) {
+    return Wails.GetLogLevels() as Promise<LogLevels>
+  }
+  return apiGet('/logs/levels')
+}
+
+export async function SetLogLevel(component: string, level: string): Promise<void> {
+  if (isWailsRuntime()) {
+    return Wails.SetLogLevel(component, level)
+  }
+  await apiPost('/logs/levels', { component, level })
+}
+
+// ---------------------------------------------------------------------------
+// Content / Search
+// ---------------------------------------------------------------------------
+
+export async function FetchContentFromURL(url: string): Promise<any> {
+  if (isWailsRuntime()) {
+    return Wails.FetchContentFromURL(url)
+  }
+  return apiPost('/content/fetch', { url })
+}
+
+export async function SearchTidal(query: string): Promise<any[]> {
+  if (isWailsRuntime()) {
+    return Wails.SearchTidal(query)
+  }
+  return apiGet(`/content/search${qs({ q: query })}`)
 }
 
 export async function SearchTidalAlbums(query: string): Promise<any[]> {
@@ -529,6 +1863,68 @@ export async function SearchDeezer(query: string): Promise<any[]> {
   return apiGet(`/content/search/deezer${qs({ q: query })}`)
 }
 
+// One release of an album on MusicBrainz: a regional pressing, a deluxe or
+// clean version… `country` is an ISO 3166-1 code ("XW" = worldwide).
+export interface AlbumEdition {
+  releaseId: string
+  title: string
+  artist: string
+  country?: string
+  date?: string
+  barcode?: string
+  trackCount: number
+  status?: string
+  disambiguation?: string
+}
+
+/** The editions of an album, preferred countries (Settings) first. */
+export async function GetAlbumEditions(artist: string, album: string): Promise<AlbumEdition[]> {
+  if (isWailsRuntime()) {
+    return Wails.GetAlbumEditions(artist, album)
+  }
+  return apiGet(`/content/editions${qs({ artist, album })}`)
+}
+
+export interface AlbumCredit {
+  name: string
+  role: string
+}
+
+export interface AlbumCreditRole {
+  role: string
+  names: string[]
+}
+
+export interface AlbumCredits {
+  source: string
+  id: string
+  title: string
+  artist: string
+  tracks: { id: string; title: string; trackNumber?: number; discNumber?: number; credits: AlbumCredit[] }[]
+  roles: AlbumCreditRole[] // each role with everyone holding it on the album
+  fetchedAt: string
+}
+
+/**
+ * The credits (producers, engineers, musicians) of a Tidal or Qobuz album.
+ * They are fetched once and then kept; `refresh` fetches them again.
+ */
+export async function GetAlbumCredits(source: string, id: string, refresh = false): Promise<AlbumCredits> {
+  if (isWailsRuntime()) {
+    return Wails.GetAlbumCredits(source, id, refresh) as unknown as Promise<AlbumCredits>
+  }
+  return apiGet(`/content/credits${qs({ source, id, refresh: refresh ? 'true' : undefined })}`)
+}
+
+/** The Deezer URL of the edition with barcode, for FetchContentFromURL. */
+export async function GetEditionURL(barcode: string): Promise<string> {
+  if (isWailsRuntime()) {
+    return Wails.GetEditionURL(barcode)
+  }
+  const { url } = await apiGet<{ url: string }>(`/content/editions/url${qs({ barcode })}`)
+  return url
+}
+
 // ---------------------------------------------------------------------------
 // Lyrics
 // ---------------------------------------------------------------------------
@@ -540,6 +1936,48 @@ export async function FetchAndEmbedLyricsMultiple(filePaths: string[]): Promise<
   return apiPost('/lyrics/fetch-embed/multiple', { filePaths })
 }
 
+/** Lyrics found for a file, and whether they are sure enough to embed. */
+export interface LyricsMatch {
+  track: string
+  artist: string
+  album?: string
+  duration: number
+  fileDuration: number
+  durationDelta: number
+  hasPlain: boolean
+  hasSynced: boolean
+  instrumental: boolean
+  confident: boolean
+  reason?: string
+}
+
+export interface LyricsPreview {
+  filePath: string
+  instrumental?: boolean // tagged INSTRUMENTAL, so not looked up
+  match?: LyricsMatch
+  error?: string
+}
+
+/**
+ * Looks up lyrics for files without embedding them. FetchAndEmbedLyricsMultiple
+ * refuses the ones whose match isn't confident (length more than 3 s off).
+ */
+export async function PreviewLyricsEmbed(filePaths: string[]): Promise<LyricsPreview[]> {
+  if (isWailsRuntime()) {
+    return Wails.PreviewLyricsEmbed(filePaths)
+  }
+  return apiPost('/lyrics/fetch-embed/preview', { filePaths })
+}
+
+/** Writes lyrics to the .lrc file next to a FLAC and returns its path. */
+export async function SaveLyricsSidecar(filePath: string, plain: string, synced: string): Promise<string> {
+  if (isWailsRuntime()) {
+    return Wails.SaveLyricsSidecar(filePath, plain, synced)
+  }
+  const { path } = await apiPost<{ path: string }>('/lyrics/sidecar', { filePath, plain, synced })
+  return path
+}
+
 // ---------------------------------------------------------------------------
 // Native OS dialogs — no browser equivalent
 //
@@ -605,11 +2043,20 @@ export async function SetDownloadFolder(folder: string): Promise<void> {
  * Some settings may need a server restart to take effect in browser mode.
  * See migration report.
  */
-export async function SaveConfig(config: any): Promise<void> {
+// ConfigChange is one setting a SaveConfig or SaveSettings changed, and
+// whether it took effect right away (see internal/configdiff).
+export interface ConfigChange {
+  key: string
+  effect: 'applied-live' | 'needs-restart' | 'invalid'
+  error?: string
+}
+
+export async function SaveConfig(config: any): Promise<ConfigChange[]> {
   if (isWailsRuntime()) {
     return Wails.SaveConfig(config)
   }
-  await apiPost('/config', config)
+  const res = await apiPost<{ changes?: ConfigChange[] }>('/config', config)
+  return res?.changes ?? []
 }
 
 export async function GetDownloadOptions(): Promise<any> {
@@ -625,12 +2072,13 @@ export async function SetDownloadOptions(
   organizeFolders: boolean,
   embedCover: boolean,
   saveCoverFile: boolean,
-  autoAnalyze: boolean
+  autoAnalyze: boolean,
+  concurrentDownloads = 0 // 0 keeps the current value
 ): Promise<void> {
   if (isWailsRuntime()) {
-    return Wails.SetDownloadOptions(quality, fileNameFormat, organizeFolders, embedCover, saveCoverFile, autoAnalyze)
+    return Wails.SetDownloadOptions(quality, fileNameFormat, organizeFolders, embedCover, saveCoverFile, autoAnalyze, concurrentDownloads)
   }
-  await apiPost('/downloads/options', { quality, fileNameFormat, organizeFolders, embedCover, saveCoverFile, autoAnalyze })
+  await apiPost('/downloads/options', { quality, fileNameFormat, organizeFolders, embedCover, saveCoverFile, autoAnalyze, concurrentDownloads })
 }
 
 export async function ResetToDefaults(): Promise<any> {
@@ -716,11 +2164,47 @@ export async function ValidateTidalURL(url: string): Promise<any> {
   return result
 }
 
-export async function QueueQobuzDownloads(tracks: any[], outputDir: string, contentName: string): Promise<number> {
+export interface ImportResult {
+  url: string
+  source?: string
+  type?: string
+  title?: string
+  queued: number
+  error?: string
+}
+
+/**
+ * Resolves and queues every URL in `text` (one per line, "#" comments
+ * skipped). Browser mode posts the text as-is to /downloads/import.
+ */
+export async function ImportURLs(text: string, outputDir: string): Promise<ImportResult[]> {
   if (isWailsRuntime()) {
-    return Wails.QueueQobuzDownloads(tracks as any, outputDir, contentName)
+    return Wails.ImportURLs(text, outputDir) as Promise<ImportResult[]>
   }
-  const { queued } = await apiPost<{ queued: number }>('/downloads/queue/qobuz', { tracks, outputDir, contentName })
+  return apiFetch<ImportResult[]>(`/downloads/import${qs({ outputDir })}`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'text/plain' },
+    body: text,
+  })
+}
+
+export async function QueueQobuzDownloads(
+  tracks: any[],
+  outputDir: string,
+  contentName: string,
+  contentId: string,
+  contentType: string
+): Promise<number> {
+  if (isWailsRuntime()) {
+    return Wails.QueueQobuzDownloads(tracks as any, outputDir, contentName, contentId, contentType)
+  }
+  const { queued } = await apiPost<{ queued: number }>('/downloads/queue/qobuz', {
+    tracks,
+    outputDir,
+    contentName,
+    contentId,
+    contentType,
+  })
   return queued
 }
 
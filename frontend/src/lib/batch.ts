@@ -0,0 +1,37 @@
+import { GetBatch, StartBatch } from './api';
+import type { Batch, BatchEvent, BatchRequest } from './api';
+import { EventsOn } from './websocket';
+
+/**
+ * Starts a batch file operation and resolves with the finished batch,
+ * per-file results included. `onProgress` gets each "batch-progress" event
+ * of this batch. The batch is also polled, so a missed event (or a
+ * disconnected WebSocket) only delays the result.
+ */
+export async function runBatch(req: BatchRequest, onProgress?: (ev: BatchEvent) => void): Promise<Batch> {
+  let id = '';
+  let wake: (() => void) | null = null;
+  const unsubscribe = EventsOn('batch-progress', (ev: BatchEvent) => {
+    if (ev.id !== id) return;
+    onProgress?.(ev);
+    if (finished(ev.state)) wake?.();
+  });
+  try {
+    const started = await StartBatch(req);
+    id = started.id;
+    for (;;) {
+      const batch = await GetBatch(id);
+      if (finished(batch.state)) return batch;
+      await new Promise<void>(resolve => {
+        wake = resolve;
+        setTimeout(resolve, 1000);
+      });
+    }
+  } finally {
+    unsubscribe();
+  }
+}
+
+function finished(state: string): boolean {
+  return state !== 'queued' && state !== 'running';
+}
@@ -0,0 +1,52 @@
+import { GetConversionJob, StartConversion } from './api';
+import type { ConversionJob, ConversionJobEvent, ConversionProgress } from './api';
+import { EventsOn } from './websocket';
+
+/**
+ * Queues a background conversion of files and resolves with the finished
+ * (or cancelled) job, per-file results included. `onStart` gets the queued
+ * job, whose id CancelConversionJob takes; `onProgress` gets each
+ * "conversion-job-progress" event of this job and `onFile` each running
+ * file's percent. The job is also polled, like runAnalysis's.
+ */
+export async function runConversion(
+  files: string[],
+  format: string,
+  quality: string,
+  outputDir: string,
+  deleteSource: boolean,
+  onProgress?: (ev: ConversionJobEvent) => void,
+  onStart?: (job: ConversionJob) => void,
+  onFile?: (p: ConversionProgress) => void,
+): Promise<ConversionJob> {
+  let id = '';
+  let wake: (() => void) | null = null;
+  const unsubscribe = EventsOn('conversion-job-progress', (ev: ConversionJobEvent) => {
+    if (ev.id !== id) return;
+    onProgress?.(ev);
+    if (finished(ev.state)) wake?.();
+  });
+  const unsubscribeFiles = EventsOn('conversion-progress', (p: ConversionProgress) => {
+    if (id && files.includes(p.file)) onFile?.(p);
+  });
+  try {
+    const started = await StartConversion(files, format, quality, outputDir, deleteSource);
+    id = started.id;
+    onStart?.(started);
+    for (;;) {
+      const job = await GetConversionJob(id);
+      if (finished(job.state)) return job;
+      await new Promise<void>(resolve => {
+        wake = resolve;
+        setTimeout(resolve, 1000);
+      });
+    }
+  } finally {
+    unsubscribe();
+    unsubscribeFiles();
+  }
+}
+
+function finished(state: string): boolean {
+  return state === 'done' || state === 'cancelled';
+}
@@ -15,3 +15,55 @@ export function formatDuration(seconds: number): string {
     const secs = seconds % 60;
     return `${mins}:${secs.toString().padStart(2, '0')}`;
 }
+
+/** Formats an elapsed time in milliseconds, e.g. "850ms", "12.4s", "3m 05s". */
+export function formatElapsed(ms: number): string {
+    if (ms < 1000) return `${ms}ms`;
+    const seconds = ms / 1000;
+    if (seconds < 60) return `${seconds.toFixed(1)}s`;
+    const mins = Math.floor(seconds / 60);
+    const secs = Math.floor(seconds % 60);
+    return `${mins}m ${secs.toString().padStart(2, '0')}s`;
+}
+
+/** Formats a download speed, e.g. "4.2 MB/s". */
+export function formatSpeed(bytesPerSec: number): string {
+    return `${formatBytes(Math.max(0, Math.round(bytesPerSec)))}/s`;
+}
+
+/** Formats a remaining time in seconds, e.g. "~45 s", "~12 min", "~1 h 05 min". */
+export function formatETA(seconds: number): string {
+    if (seconds < 60) return `~${Math.max(1, Math.round(seconds))} s`;
+    const mins = Math.round(seconds / 60);
+    if (mins < 60) return `~${mins} min`;
+    return `~${Math.floor(mins / 60)} h ${(mins % 60).toString().padStart(2, '0')} min`;
+}
+
+// API timestamps are UTC RFC3339; they're shown in the browser's locale and
+// time zone unless the desktop app applies its system's (GetLocaleHint).
+let dateLocale: string | undefined;
+let dateTimeZone: string | undefined;
+
+/** Applies the backend's locale hint to every date formatted afterwards. */
+export function setLocaleHint(hint: { locale?: string; timeZone?: string }): void {
+    dateLocale = hint.locale || undefined;
+    dateTimeZone = hint.timeZone || undefined;
+}
+
+/**
+ * Formats an API timestamp for display, e.g. "Mar 1, 2026, 7:04 PM".
+ * Returns "--" for empty values and the input itself if it doesn't parse.
+ */
+export function formatDateTime(iso: string, options: Intl.DateTimeFormatOptions = {
+    month: 'short', day: 'numeric', year: 'numeric', hour: '2-digit', minute: '2-digit'
+}): string {
+    if (!iso) return '--';
+    const date = new Date(iso);
+    if (isNaN(date.getTime())) return iso;
+    try {
+        return date.toLocaleString(dateLocale, { ...options, timeZone: dateTimeZone });
+    } catch {
+        // unknown locale or zone in the hint — use the browser's
+        return date.toLocaleString(undefined, options);
+    }
+}
@@ -0,0 +1,26 @@
+import { isBrokenFLACError, RedownloadBrokenFile } from './api';
+import { toastStore } from '../stores/toast';
+
+/**
+ * Offers to re-download the files strict validation refused. `results`
+ * are an operation's per-file outcomes; the ones whose error marks a broken
+ * FLAC are listed in a confirmation and, if accepted, queued again.
+ */
+export async function offerRedownload(results: { path: string; error?: string }[]): Promise<void> {
+  const broken = results.filter(r => isBrokenFLACError(r.error)).map(r => r.path);
+  if (broken.length === 0) return;
+  const names = broken.map(p => p.split('/').pop()?.split('\\').pop() || p);
+  const many = broken.length !== 1;
+  if (!confirm(`${broken.length} file${many ? 's' : ''} failed FLAC validation and ${many ? 'were' : 'was'} left untouched:\n\n${names.join('\n')}\n\nRe-download ${many ? 'them' : 'it'}?`)) return;
+
+  let queued = 0;
+  for (const path of broken) {
+    try {
+      await RedownloadBrokenFile(path);
+      queued++;
+    } catch (err: any) {
+      toastStore.show(err?.message || `Could not re-download ${path}`, 'error');
+    }
+  }
+  if (queued > 0) toastStore.show(`Queued ${queued} re-download${queued !== 1 ? 's' : ''}`, 'success');
+}
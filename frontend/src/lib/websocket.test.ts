@@ -102,9 +102,22 @@ describe('websocket EventsOn/EventsOff — browser mode', () => {
     EventsOn('download-progress', cb)
 
     const socket = MockWebSocket.instances[0]
-    socket.emit({ type: 'download-progress', trackId: 42, status: 'completed', result: { filePath: '/music/a.flac' } })
+    const job = { state: 'completed', attempts: 1, transitions: [], queueTimeMs: 10, downloadTimeMs: 2000 }
+    socket.emit({ type: 'download-progress', trackId: 42, status: 'completed', result: { filePath: '/music/a.flac' }, job })
 
-    expect(cb).toHaveBeenCalledWith({ trackId: 42, status: 'completed', result: { filePath: '/music/a.flac' } })
+    expect(cb).toHaveBeenCalledWith({ trackId: 42, status: 'completed', result: { filePath: '/music/a.flac' }, job })
+  })
+
+  it('passes the track speed and queue throughput through', async () => {
+    const { EventsOn } = await import('./websocket')
+    const cb = vi.fn()
+    EventsOn('download-progress', cb)
+
+    const socket = MockWebSocket.instances[0]
+    const throughput = { bytesPerSec: 4404019, etaSeconds: 720 }
+    socket.emit({ type: 'download-progress', trackId: 42, status: 'completed', result: null, bytesPerSec: 3000000, throughput })
+
+    expect(cb).toHaveBeenCalledWith(expect.objectContaining({ bytesPerSec: 3000000, throughput }))
   })
 
   it('never fires listeners for event names the /ws hub does not broadcast', async () => {
@@ -6,12 +6,17 @@
 //
 // Browser mode: connects to the headless server's /ws WebSocket hub
 // (internal/api/server.go), which broadcasts download-progress events as
-// {"type":"download-progress","trackId":N,"status":"...","result":{...}}
-// (see cmd/server/main.go's DownloadManager.SetProgressCallback -> this is
-// the only event type the hub currently carries). Messages are unwrapped
-// and redispatched to 'download-progress' listeners with the exact same
-// payload shape Wails emits ({trackId, status, result}), so App.svelte's
-// handler works unchanged.
+// {"type":"download-progress","trackId":N,"status":"...","result":{...},"job":{...},
+//  "bytesPerSec":N,"throughput":{...}}
+// (see cmd/server/main.go's DownloadManager.SetProgressCallback). Messages
+// are unwrapped and redispatched to 'download-progress' listeners with the
+// exact same payload shape Wails emits ({trackId, status, result, job,
+// bytesPerSec, throughput}), so App.svelte's handler works unchanged.
+// {"type":"library-updated","report":{...}} likewise reaches
+// 'library-updated' listeners as the bare scan report, and
+// {"type":"analysis-progress",...}, {"type":"conversion-progress",...} and
+// {"type":"conversion-job-progress",...} reach their listeners as the event
+// without its "type".
 //
 // Known gap: 'queue-paused', 'endpoint-cooldown', 'log',
 // 'ffmpeg-install-progress' and 'sldl-install-progress' have no server-side
@@ -20,7 +25,7 @@
 // error) but the callback will simply never fire.
 
 import { EventsOn as WailsEventsOn, EventsOff as WailsEventsOff } from '../../wailsjs/runtime/runtime.js'
-import { isWailsRuntime } from './api'
+import { isWailsRuntime, APIToken } from './api'
 
 type EventCallback = (...data: any[]) => void
 
@@ -36,7 +41,8 @@ function backoffDelayMs(attempt: number): number {
 
 function socketURL(): string {
   const proto = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
-  return `${proto}//${window.location.host}/ws`
+  const token = APIToken()
+  return `${proto}//${window.location.host}/ws` + (token ? `?token=${encodeURIComponent(token)}` : '')
 }
 
 function dispatch(eventName: string, payload: any): void {
@@ -60,7 +66,19 @@ function handleMessage(event: MessageEvent): void {
   }
 
   if (msg?.type === 'download-progress') {
-    dispatch('download-progress', { trackId: msg.trackId, status: msg.status, result: msg.result })
+    dispatch('download-progress', {
+      trackId: msg.trackId,
+      status: msg.status,
+      result: msg.result,
+      job: msg.job,
+      bytesPerSec: msg.bytesPerSec,
+      throughput: msg.throughput
+    })
+  } else if (msg?.type === 'library-updated') {
+    dispatch('library-updated', msg.report)
+  } else if (['analysis-progress', 'conversion-progress', 'conversion-job-progress'].includes(msg?.type)) {
+    const { type, ...ev } = msg
+    dispatch(type, ev)
   }
 }
 
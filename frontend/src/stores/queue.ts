@@ -1,13 +1,22 @@
 import { writable, derived } from 'svelte/store';
 
 interface AnalysisResult {
-  verdict: string;          // "lossless" | "likely_upscaled" | "upscaled"
+  verdict: string;          // "lossless" | "upsampled" | "likely_upscaled" | "upscaled"
   verdictLabel: string;
   isTrueLossless: boolean;
   confidence: number;
   details?: string;
 }
 
+// Mirrors internal/downloads.Job: state transitions and derived timings.
+export interface JobTiming {
+  state: string;
+  attempts: number;
+  transitions: { state: string; at: string }[];
+  queueTimeMs: number;
+  downloadTimeMs: number;
+}
+
 export interface QueueItem {
   trackId: number;
   title: string;
@@ -21,6 +30,8 @@ export interface QueueItem {
   source?: string;
   attempts?: string[];      // cascade order: all sources tried (first → last)
   analysis?: AnalysisResult; // spectral analysis (auto for Soulseek/Bandcamp)
+  job?: JobTiming;
+  bytesPerSec?: number;     // measured when the download completed
 }
 
 export interface QueueStats {
@@ -145,6 +156,16 @@ export const downloadFolder = writable<string>('');
 // Queue paused state
 export const queuePaused = writable<boolean>(false);
 
+// Mirrors internal/downloads.Rate: aggregate speed over recent downloads and
+// the estimated time to finish the queue (0 = not known yet).
+export interface QueueThroughput {
+  bytesPerSec: number;
+  etaSeconds: number;
+}
+
+// Latest throughput, from download-progress events
+export const queueThroughput = writable<QueueThroughput>({ bytesPerSec: 0, etaSeconds: 0 });
+
 // Current content store (playlist/album/track being viewed)
 export interface TidalContent {
   type: 'playlist' | 'album' | 'track' | 'artist';
@@ -1,24 +1,63 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {tagedit} from '../models';
 import {core} from '../models';
 import {app} from '../models';
+import {acoustid} from '../models';
+import {history} from '../models';
+import {downloads} from '../models';
+import {naming} from '../models';
+import {coverstore} from '../models';
+import {timestamp} from '../models';
+import {jobs} from '../models';
+import {settings} from '../models';
+import {incomplete} from '../models';
+import {postprocess} from '../models';
+import {silence} from '../models';
+import {musicbrainz} from '../models';
+import {tagrules} from '../models';
+import {configdiff} from '../models';
+import {library} from '../models';
+import {credits} from '../models';
+import {doctor} from '../models';
+import {analysis} from '../models';
+
+export function AcoustIDTags(arg1:Array<string>):Promise<Array<tagedit.Result>>;
+
+export function AddFilePicture(arg1:string,arg2:string,arg3:number,arg4:string):Promise<void>;
 
 export function AddLog(arg1:string,arg2:string):Promise<void>;
 
-export function AnalyzeFile(arg1:string):Promise<core.AnalysisResult>;
+export function AnalyzeFile(arg1:string):Promise<app.AnalysisResult>;
 
-export function AnalyzeMultiple(arg1:Array<string>):Promise<Array<core.AnalysisResult>>;
+export function AnalyzeFolder(arg1:string,arg2:boolean,arg3:boolean):Promise<jobs.Job>;
+
+export function AnalyzeMultiple(arg1:Array<string>):Promise<Array<app.AnalysisResult>>;
+
+export function BrowseLibrary(arg1:library.Query):Promise<library.Page>;
+
+export function CancelAnalysisJob(arg1:string):Promise<jobs.Job>;
+
+export function CancelConversionJob(arg1:string):Promise<jobs.Job>;
 
 export function CancelDownload(arg1:number):Promise<void>;
 
 export function CheckAPIStatus():Promise<Array<app.EndpointStatus>>;
 
+export function CheckAlbum(arg1:string):Promise<analysis.AlbumReport>;
+
+export function CheckAlbumCompleteness(arg1:string,arg2:string):Promise<app.CompletenessReport>;
+
 export function CheckForUpdate():Promise<app.UpdateInfo>;
 
+export function CheckHistoryCompleteness(arg1:string):Promise<app.CompletenessReport>;
+
 export function ClearDownloadHistory():Promise<void>;
 
 export function ClearLogs():Promise<void>;
 
+export function CompareFiles(arg1:string,arg2:string):Promise<analysis.Comparison>;
+
 export function ConvertFiles(arg1:Array<string>,arg2:string,arg3:string,arg4:string,arg5:boolean):Promise<Array<core.ConversionResult>>;
 
 export function ConvertFolder(arg1:string,arg2:string,arg3:string,arg4:string,arg5:boolean):Promise<Array<core.ConversionResult>>;
@@ -27,6 +66,8 @@ export function DeleteFile(arg1:string):Promise<void>;
 
 export function DeleteHistoryRecord(arg1:number):Promise<void>;
 
+export function DeleteIncompleteFile(arg1:string):Promise<void>;
+
 export function DetectSourceFromURL(arg1:string):Promise<Record<string, any>>;
 
 export function DownloadArtistAssets(arg1:string,arg2:string,arg3:string):Promise<number>;
@@ -39,8 +80,16 @@ export function EmbedLyricsToFile(arg1:string,arg2:string,arg3:string):Promise<v
 
 export function ExpandDiscographyURL(arg1:string):Promise<Array<string>>;
 
+export function ExportDownloadManifest(arg1:string,arg2:string):Promise<string>;
+
 export function ExportFailedDownloads(arg1:string):Promise<string>;
 
+export function ExportLibrary(arg1:string,arg2:string):Promise<string>;
+
+export function ExportQualityReport(arg1:string):Promise<string>;
+
+export function ExtractFolderCovers(arg1:string,arg2:boolean):Promise<Array<app.FolderCover>>;
+
 export function FetchAndEmbedLyrics(arg1:string):Promise<core.Lyrics>;
 
 export function FetchAndEmbedLyricsMultiple(arg1:Array<string>):Promise<Array<Record<string, any>>>;
@@ -55,10 +104,22 @@ export function FetchTidalContent(arg1:string):Promise<Record<string, any>>;
 
 export function FetchTidalPlaylist(arg1:string):Promise<core.TidalPlaylist>;
 
+export function GetAcoustIDInfo():Promise<acoustid.Info>;
+
+export function GetActivityHeatmap(arg1:number):Promise<history.Heatmap>;
+
+export function GetAlbumCredits(arg1:string,arg2:string,arg3:boolean):Promise<credits.Album>;
+
+export function GetAlbumEditions(arg1:string,arg2:string):Promise<Array<musicbrainz.Edition>>;
+
+export function GetAnalysisJob(arg1:string):Promise<jobs.Job>;
+
 export function GetAppVersion():Promise<string>;
 
 export function GetAvailableSources():Promise<Array<core.SourceInfo>>;
 
+export function GetBatch(arg1:string):Promise<jobs.Job>;
+
 export function GetCacheStats():Promise<Record<string, any>>;
 
 export function GetConfig():Promise<core.Config>;
@@ -67,23 +128,59 @@ export function GetConnectionStatus():Promise<Record<string, any>>;
 
 export function GetConversionFormats():Promise<Array<core.ConversionFormat>>;
 
+export function GetConversionJob(arg1:string):Promise<jobs.Job>;
+
+export function GetCoverThumbnail(arg1:string,arg2:number):Promise<Record<string, string>>;
+
 export function GetDownloadFolder():Promise<string>;
 
 export function GetDownloadHistory():Promise<Array<core.DownloadRecord>>;
 
 export function GetDownloadHistoryFiltered(arg1:Record<string, any>):Promise<Record<string, any>>;
 
+export function GetDownloadJobs():Promise<Array<downloads.Job>>;
+
 export function GetDownloadOptions():Promise<Record<string, any>>;
 
 export function GetDownloadQueueStatus():Promise<Record<string, any>>;
 
+export function GetEditionURL(arg1:string):Promise<string>;
+
 export function GetFFmpegInfo():Promise<Record<string, any>>;
 
 export function GetFFmpegInstallStatus():Promise<Record<string, any>>;
 
 export function GetFileCoverArt(arg1:string):Promise<Record<string, string>>;
 
-export function GetFileMetadata(arg1:string):Promise<core.FLACMetadata>;
+export function GetFileMetadata(arg1:string):Promise<app.FileMetadata>;
+
+export function GetFileThumbnail(arg1:string,arg2:number):Promise<Record<string, string>>;
+
+export function GetFilenameTokens():Promise<Array<naming.Token>>;
+
+export function GetHistoryCover(arg1:string):Promise<Record<string, string>>;
+
+export function GetHistoryFile(arg1:number):Promise<string>;
+
+export function GetHistorySnapshots():Promise<Record<string, history.Snapshot>>;
+
+export function GetHistoryTimings():Promise<Record<string, downloads.Job>>;
+
+export function GetLibraryAlbums(arg1:library.Query):Promise<Array<library.Album>>;
+
+export function GetLibraryArtists(arg1:library.Query):Promise<Array<library.Artist>>;
+
+export function GetLibraryCovers():Promise<coverstore.Library>;
+
+export function GetLibraryDuplicates(arg1:string):Promise<Array<library.DuplicateGroup>>;
+
+export function GetLibraryLabels(arg1:library.Query):Promise<Array<library.Label>>;
+
+export function GetLibraryStatus():Promise<library.Status>;
+
+export function GetLocaleHint():Promise<timestamp.LocaleHint>;
+
+export function GetLogLevels():Promise<Record<string, any>>;
 
 export function GetLogs():Promise<Array<core.LogEntry>>;
 
@@ -95,6 +192,8 @@ export function GetRecentAlbums(arg1:number):Promise<Array<Record<string, any>>>
 
 export function GetRenameTemplates():Promise<Array<Record<string, string>>>;
 
+export function GetSettings():Promise<settings.Settings>;
+
 export function GetSldlStatus():Promise<Record<string, any>>;
 
 export function GetSourceAlbum(arg1:string,arg2:string):Promise<core.SourceAlbum>;
@@ -105,6 +204,12 @@ export function GetSourcePlaylist(arg1:string,arg2:string):Promise<core.SourcePl
 
 export function GetSourceTrack(arg1:string,arg2:string):Promise<core.SourceTrack>;
 
+export function GetTrackHistory(arg1:number,arg2:number):Promise<Record<string, any>>;
+
+export function ImportAndTagFolder(arg1:string,arg2:string,arg3:boolean):Promise<app.TagImportReport>;
+
+export function ImportURLs(arg1:string,arg2:string):Promise<Array<app.ImportResult>>;
+
 export function InstallFFmpeg():Promise<void>;
 
 export function InstallSldl():Promise<void>;
@@ -117,12 +222,26 @@ export function IsQobuzConfigured():Promise<boolean>;
 
 export function IsQueuePaused():Promise<boolean>;
 
-export function ListDownloadedFiles():Promise<Array<core.DownloadedFileInfo>>;
+export function ListAnalysisJobs():Promise<Array<jobs.Job>>;
+
+export function ListBatches():Promise<Array<jobs.Job>>;
+
+export function ListConversionJobs():Promise<Array<jobs.Job>>;
+
+export function ListDownloadedFiles():Promise<Array<app.FileInfo>>;
+
+export function ListFilePictures(arg1:string):Promise<Array<app.PictureInfo>>;
+
+export function ListIncompleteFiles():Promise<Array<incomplete.File>>;
 
 export function MatchPlaylistTracks(arg1:Array<core.TidalTrack>):Promise<Array<core.MatchResult>>;
 
 export function MatchSingleTrack(arg1:core.TidalTrack):Promise<core.MatchResult>;
 
+export function MusicBrainzTags(arg1:Array<string>):Promise<Array<tagedit.Result>>;
+
+export function NormalizeTags(arg1:Array<string>,arg2:tagrules.Rules):Promise<Array<tagedit.Result>>;
+
 export function OpenConfigFolder():Promise<void>;
 
 export function OpenDownloadFolder(arg1:string):Promise<void>;
@@ -131,26 +250,52 @@ export function OpenFLACFilesDialog():Promise<Array<string>>;
 
 export function PauseDownloads():Promise<boolean>;
 
+export function PreviewAcoustIDTags(arg1:Array<string>):Promise<Array<tagedit.Result>>;
+
+export function PreviewLyricsEmbed(arg1:Array<string>):Promise<Array<app.LyricsPreview>>;
+
+export function PreviewMusicBrainzTags(arg1:Array<string>):Promise<Array<tagedit.Result>>;
+
+export function PreviewNormalizeTags(arg1:Array<string>,arg2:tagrules.Rules):Promise<Array<tagedit.Result>>;
+
+export function PreviewRemapGenres(arg1:Array<string>):Promise<Array<tagedit.Result>>;
+
 export function PreviewRename(arg1:Array<string>,arg2:string):Promise<Array<core.RenamePreview>>;
 
+export function PreviewSetTags(arg1:Array<string>,arg2:Record<string, string>,arg3:string):Promise<Array<tagedit.Result>>;
+
+export function PreviewStripTags(arg1:Array<string>,arg2:tagedit.StripOptions):Promise<Array<tagedit.Result>>;
+
+export function PreviewTagsFromNames(arg1:Array<string>,arg2:string):Promise<Array<tagedit.Result>>;
+
 export function QueueArtistAlbum(arg1:string,arg2:string,arg3:string):Promise<number>;
 
 export function QueueDiscographyAlbums(arg1:Array<string>,arg2:string):Promise<number>;
 
 export function QueueDownloads(arg1:Array<core.TidalTrack>,arg2:string,arg3:string,arg4:string,arg5:string):Promise<number>;
 
-export function QueueQobuzDownloads(arg1:Array<core.SourceTrack>,arg2:string,arg3:string):Promise<number>;
+export function QueueMissingTracks(arg1:string,arg2:string):Promise<number>;
+
+export function QueueQobuzDownloads(arg1:Array<core.SourceTrack>,arg2:string,arg3:string,arg4:string,arg5:string):Promise<number>;
 
 export function QueueSingleDownload(arg1:number,arg2:string,arg3:string,arg4:string):Promise<void>;
 
 export function QuickAnalyze(arg1:string):Promise<core.AnalysisResult>;
 
+export function RedownloadBrokenFile(arg1:string):Promise<core.TidalTrack>;
+
 export function RefetchFromHistory(arg1:string):Promise<Record<string, any>>;
 
 export function RefreshTidalEndpoints():Promise<Array<string>>;
 
+export function RemapGenres(arg1:Array<string>):Promise<Array<tagedit.Result>>;
+
+export function RemoveFilePicture(arg1:string,arg2:number):Promise<void>;
+
 export function RenameFiles(arg1:Array<string>,arg2:string):Promise<Array<core.RenameResult>>;
 
+export function RequeueIncompleteFile(arg1:string):Promise<void>;
+
 export function ResetToDefaults():Promise<core.Config>;
 
 export function ResumeDownloads():Promise<boolean>;
@@ -159,7 +304,17 @@ export function RetryAllFailed():Promise<number>;
 
 export function RetryDownload(arg1:number):Promise<void>;
 
-export function SaveConfig(arg1:core.Config):Promise<void>;
+export function RunDoctor(arg1:boolean):Promise<doctor.Report>;
+
+export function SaveConfig(arg1:core.Config):Promise<Array<configdiff.Change>>;
+
+export function SaveCoverArt(arg1:string,arg2:string):Promise<string>;
+
+export function SaveLyricsSidecar(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function SaveSettings(arg1:settings.Settings):Promise<Array<configdiff.Change>>;
+
+export function ScanLibrary():Promise<library.ScanReport>;
 
 export function SearchDeezer(arg1:string):Promise<Array<Record<string, any>>>;
 
@@ -175,16 +330,44 @@ export function SelectFolderForConversion():Promise<Array<string>>;
 
 export function SetDownloadFolder(arg1:string):Promise<void>;
 
-export function SetDownloadOptions(arg1:string,arg2:string,arg3:boolean,arg4:boolean,arg5:boolean,arg6:boolean):Promise<void>;
+export function SetDownloadOptions(arg1:string,arg2:string,arg3:boolean,arg4:boolean,arg5:boolean,arg6:boolean,arg7:number):Promise<void>;
+
+export function SetLogLevel(arg1:string,arg2:string):Promise<void>;
 
 export function SetPreferredSource(arg1:string):Promise<void>;
 
 export function SetSourceOrder(arg1:Array<string>):Promise<void>;
 
+export function SetTags(arg1:Array<string>,arg2:Record<string, string>,arg3:string):Promise<Array<tagedit.Result>>;
+
 export function SetTidalCredentials(arg1:string,arg2:string):Promise<void>;
 
+export function SetTrackOverrides(arg1:number,arg2:postprocess.Overrides):Promise<void>;
+
+export function SplitAlbum(arg1:string,arg2:string):Promise<app.SplitReport>;
+
+export function StartAnalysis(arg1:Array<string>,arg2:boolean):Promise<jobs.Job>;
+
+export function StartBatch(arg1:app.BatchRequest):Promise<jobs.Job>;
+
+export function StartConversion(arg1:Array<string>,arg2:string,arg3:string,arg4:string,arg5:boolean):Promise<jobs.Job>;
+
+export function StripTags(arg1:Array<string>,arg2:tagedit.StripOptions):Promise<Array<tagedit.Result>>;
+
+export function TagsFromNames(arg1:Array<string>,arg2:string):Promise<Array<tagedit.Result>>;
+
 export function TestSoulseekConnection(arg1:string,arg2:string):Promise<Record<string, any>>;
 
+export function TrimSilence(arg1:string,arg2:boolean):Promise<silence.Report>;
+
+export function UndoBatch(arg1:string):Promise<jobs.Job>;
+
 export function UpdateQobuzCredentials(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function ValidateFLAC(arg1:string):Promise<string>;
+
 export function ValidateTidalURL(arg1:string):Promise<Record<string, any>>;
+
+export function VerifyFiles(arg1:Array<string>):Promise<Array<analysis.Verification>>;
+
+export function VerifyFolder(arg1:string):Promise<Array<analysis.Verification>>;
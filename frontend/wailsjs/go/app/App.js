@@ -2,6 +2,14 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AcoustIDTags(arg1) {
+  return window['go']['app']['App']['AcoustIDTags'](arg1);
+}
+
+export function AddFilePicture(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['AddFilePicture'](arg1, arg2, arg3, arg4);
+}
+
 export function AddLog(arg1, arg2) {
   return window['go']['app']['App']['AddLog'](arg1, arg2);
 }
@@ -10,10 +18,26 @@ export function AnalyzeFile(arg1) {
   return window['go']['app']['App']['AnalyzeFile'](arg1);
 }
 
+export function AnalyzeFolder(arg1, arg2, arg3) {
+  return window['go']['app']['App']['AnalyzeFolder'](arg1, arg2, arg3);
+}
+
 export function AnalyzeMultiple(arg1) {
   return window['go']['app']['App']['AnalyzeMultiple'](arg1);
 }
 
+export function BrowseLibrary(arg1) {
+  return window['go']['app']['App']['BrowseLibrary'](arg1);
+}
+
+export function CancelAnalysisJob(arg1) {
+  return window['go']['app']['App']['CancelAnalysisJob'](arg1);
+}
+
+export function CancelConversionJob(arg1) {
+  return window['go']['app']['App']['CancelConversionJob'](arg1);
+}
+
 export function CancelDownload(arg1) {
   return window['go']['app']['App']['CancelDownload'](arg1);
 }
@@ -22,10 +46,22 @@ export function CheckAPIStatus() {
   return window['go']['app']['App']['CheckAPIStatus']();
 }
 
+export function CheckAlbum(arg1) {
+  return window['go']['app']['App']['CheckAlbum'](arg1);
+}
+
+export function CheckAlbumCompleteness(arg1, arg2) {
+  return window['go']['app']['App']['CheckAlbumCompleteness'](arg1, arg2);
+}
+
 export function CheckForUpdate() {
   return window['go']['app']['App']['CheckForUpdate']();
 }
 
+export function CheckHistoryCompleteness(arg1) {
+  return window['go']['app']['App']['CheckHistoryCompleteness'](arg1);
+}
+
 export function ClearDownloadHistory() {
   return window['go']['app']['App']['ClearDownloadHistory']();
 }
@@ -34,6 +70,10 @@ export function ClearLogs() {
   return window['go']['app']['App']['ClearLogs']();
 }
 
+export function CompareFiles(arg1, arg2) {
+  return window['go']['app']['App']['CompareFiles'](arg1, arg2);
+}
+
 export function ConvertFiles(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['app']['App']['ConvertFiles'](arg1, arg2, arg3, arg4, arg5);
 }
@@ -50,6 +90,10 @@ export function DeleteHistoryRecord(arg1) {
   return window['go']['app']['App']['DeleteHistoryRecord'](arg1);
 }
 
+export function DeleteIncompleteFile(arg1) {
+  return window['go']['app']['App']['DeleteIncompleteFile'](arg1);
+}
+
 export function DetectSourceFromURL(arg1) {
   return window['go']['app']['App']['DetectSourceFromURL'](arg1);
 }
@@ -74,10 +118,26 @@ export function ExpandDiscographyURL(arg1) {
   return window['go']['app']['App']['ExpandDiscographyURL'](arg1);
 }
 
+export function ExportDownloadManifest(arg1, arg2) {
+  return window['go']['app']['App']['ExportDownloadManifest'](arg1, arg2);
+}
+
 export function ExportFailedDownloads(arg1) {
   return window['go']['app']['App']['ExportFailedDownloads'](arg1);
 }
 
+export function ExportLibrary(arg1, arg2) {
+  return window['go']['app']['App']['ExportLibrary'](arg1, arg2);
+}
+
+export function ExportQualityReport(arg1) {
+  return window['go']['app']['App']['ExportQualityReport'](arg1);
+}
+
+export function ExtractFolderCovers(arg1, arg2) {
+  return window['go']['app']['App']['ExtractFolderCovers'](arg1, arg2);
+}
+
 export function FetchAndEmbedLyrics(arg1) {
   return window['go']['app']['App']['FetchAndEmbedLyrics'](arg1);
 }
@@ -106,6 +166,26 @@ export function FetchTidalPlaylist(arg1) {
   return window['go']['app']['App']['FetchTidalPlaylist'](arg1);
 }
 
+export function GetAcoustIDInfo() {
+  return window['go']['app']['App']['GetAcoustIDInfo']();
+}
+
+export function GetActivityHeatmap(arg1) {
+  return window['go']['app']['App']['GetActivityHeatmap'](arg1);
+}
+
+export function GetAlbumCredits(arg1, arg2, arg3) {
+  return window['go']['app']['App']['GetAlbumCredits'](arg1, arg2, arg3);
+}
+
+export function GetAlbumEditions(arg1, arg2) {
+  return window['go']['app']['App']['GetAlbumEditions'](arg1, arg2);
+}
+
+export function GetAnalysisJob(arg1) {
+  return window['go']['app']['App']['GetAnalysisJob'](arg1);
+}
+
 export function GetAppVersion() {
   return window['go']['app']['App']['GetAppVersion']();
 }
@@ -114,6 +194,10 @@ export function GetAvailableSources() {
   return window['go']['app']['App']['GetAvailableSources']();
 }
 
+export function GetBatch(arg1) {
+  return window['go']['app']['App']['GetBatch'](arg1);
+}
+
 export function GetCacheStats() {
   return window['go']['app']['App']['GetCacheStats']();
 }
@@ -130,6 +214,14 @@ export function GetConversionFormats() {
   return window['go']['app']['App']['GetConversionFormats']();
 }
 
+export function GetConversionJob(arg1) {
+  return window['go']['app']['App']['GetConversionJob'](arg1);
+}
+
+export function GetCoverThumbnail(arg1, arg2) {
+  return window['go']['app']['App']['GetCoverThumbnail'](arg1, arg2);
+}
+
 export function GetDownloadFolder() {
   return window['go']['app']['App']['GetDownloadFolder']();
 }
@@ -142,6 +234,10 @@ export function GetDownloadHistoryFiltered(arg1) {
   return window['go']['app']['App']['GetDownloadHistoryFiltered'](arg1);
 }
 
+export function GetDownloadJobs() {
+  return window['go']['app']['App']['GetDownloadJobs']();
+}
+
 export function GetDownloadOptions() {
   return window['go']['app']['App']['GetDownloadOptions']();
 }
@@ -150,6 +246,10 @@ export function GetDownloadQueueStatus() {
   return window['go']['app']['App']['GetDownloadQueueStatus']();
 }
 
+export function GetEditionURL(arg1) {
+  return window['go']['app']['App']['GetEditionURL'](arg1);
+}
+
 export function GetFFmpegInfo() {
   return window['go']['app']['App']['GetFFmpegInfo']();
 }
@@ -166,6 +266,62 @@ export function GetFileMetadata(arg1) {
   return window['go']['app']['App']['GetFileMetadata'](arg1);
 }
 
+export function GetFileThumbnail(arg1, arg2) {
+  return window['go']['app']['App']['GetFileThumbnail'](arg1, arg2);
+}
+
+export function GetFilenameTokens() {
+  return window['go']['app']['App']['GetFilenameTokens']();
+}
+
+export function GetHistoryCover(arg1) {
+  return window['go']['app']['App']['GetHistoryCover'](arg1);
+}
+
+export function GetHistoryFile(arg1) {
+  return window['go']['app']['App']['GetHistoryFile'](arg1);
+}
+
+export function GetHistorySnapshots() {
+  return window['go']['app']['App']['GetHistorySnapshots']();
+}
+
+export function GetHistoryTimings() {
+  return window['go']['app']['App']['GetHistoryTimings']();
+}
+
+export function GetLibraryAlbums(arg1) {
+  return window['go']['app']['App']['GetLibraryAlbums'](arg1);
+}
+
+export function GetLibraryArtists(arg1) {
+  return window['go']['app']['App']['GetLibraryArtists'](arg1);
+}
+
+export function GetLibraryCovers() {
+  return window['go']['app']['App']['GetLibraryCovers']();
+}
+
+export function GetLibraryDuplicates(arg1) {
+  return window['go']['app']['App']['GetLibraryDuplicates'](arg1);
+}
+
+export function GetLibraryLabels(arg1) {
+  return window['go']['app']['App']['GetLibraryLabels'](arg1);
+}
+
+export function GetLibraryStatus() {
+  return window['go']['app']['App']['GetLibraryStatus']();
+}
+
+export function GetLocaleHint() {
+  return window['go']['app']['App']['GetLocaleHint']();
+}
+
+export function GetLogLevels() {
+  return window['go']['app']['App']['GetLogLevels']();
+}
+
 export function GetLogs() {
   return window['go']['app']['App']['GetLogs']();
 }
@@ -186,6 +342,10 @@ export function GetRenameTemplates() {
   return window['go']['app']['App']['GetRenameTemplates']();
 }
 
+export function GetSettings() {
+  return window['go']['app']['App']['GetSettings']();
+}
+
 export function GetSldlStatus() {
   return window['go']['app']['App']['GetSldlStatus']();
 }
@@ -206,6 +366,18 @@ export function GetSourceTrack(arg1, arg2) {
   return window['go']['app']['App']['GetSourceTrack'](arg1, arg2);
 }
 
+export function GetTrackHistory(arg1, arg2) {
+  return window['go']['app']['App']['GetTrackHistory'](arg1, arg2);
+}
+
+export function ImportAndTagFolder(arg1, arg2, arg3) {
+  return window['go']['app']['App']['ImportAndTagFolder'](arg1, arg2, arg3);
+}
+
+export function ImportURLs(arg1, arg2) {
+  return window['go']['app']['App']['ImportURLs'](arg1, arg2);
+}
+
 export function InstallFFmpeg() {
   return window['go']['app']['App']['InstallFFmpeg']();
 }
@@ -230,10 +402,30 @@ export function IsQueuePaused() {
   return window['go']['app']['App']['IsQueuePaused']();
 }
 
+export function ListAnalysisJobs() {
+  return window['go']['app']['App']['ListAnalysisJobs']();
+}
+
+export function ListBatches() {
+  return window['go']['app']['App']['ListBatches']();
+}
+
+export function ListConversionJobs() {
+  return window['go']['app']['App']['ListConversionJobs']();
+}
+
 export function ListDownloadedFiles() {
   return window['go']['app']['App']['ListDownloadedFiles']();
 }
 
+export function ListFilePictures(arg1) {
+  return window['go']['app']['App']['ListFilePictures'](arg1);
+}
+
+export function ListIncompleteFiles() {
+  return window['go']['app']['App']['ListIncompleteFiles']();
+}
+
 export function MatchPlaylistTracks(arg1) {
   return window['go']['app']['App']['MatchPlaylistTracks'](arg1);
 }
@@ -242,6 +434,14 @@ export function MatchSingleTrack(arg1) {
   return window['go']['app']['App']['MatchSingleTrack'](arg1);
 }
 
+export function MusicBrainzTags(arg1) {
+  return window['go']['app']['App']['MusicBrainzTags'](arg1);
+}
+
+export function NormalizeTags(arg1, arg2) {
+  return window['go']['app']['App']['NormalizeTags'](arg1, arg2);
+}
+
 export function OpenConfigFolder() {
   return window['go']['app']['App']['OpenConfigFolder']();
 }
@@ -258,10 +458,42 @@ export function PauseDownloads() {
   return window['go']['app']['App']['PauseDownloads']();
 }
 
+export function PreviewAcoustIDTags(arg1) {
+  return window['go']['app']['App']['PreviewAcoustIDTags'](arg1);
+}
+
+export function PreviewLyricsEmbed(arg1) {
+  return window['go']['app']['App']['PreviewLyricsEmbed'](arg1);
+}
+
+export function PreviewMusicBrainzTags(arg1) {
+  return window['go']['app']['App']['PreviewMusicBrainzTags'](arg1);
+}
+
+export function PreviewNormalizeTags(arg1, arg2) {
+  return window['go']['app']['App']['PreviewNormalizeTags'](arg1, arg2);
+}
+
+export function PreviewRemapGenres(arg1) {
+  return window['go']['app']['App']['PreviewRemapGenres'](arg1);
+}
+
 export function PreviewRename(arg1, arg2) {
   return window['go']['app']['App']['PreviewRename'](arg1, arg2);
 }
 
+export function PreviewSetTags(arg1, arg2, arg3) {
+  return window['go']['app']['App']['PreviewSetTags'](arg1, arg2, arg3);
+}
+
+export function PreviewStripTags(arg1, arg2) {
+  return window['go']['app']['App']['PreviewStripTags'](arg1, arg2);
+}
+
+export function PreviewTagsFromNames(arg1, arg2) {
+  return window['go']['app']['App']['PreviewTagsFromNames'](arg1, arg2);
+}
+
 export function QueueArtistAlbum(arg1, arg2, arg3) {
   return window['go']['app']['App']['QueueArtistAlbum'](arg1, arg2, arg3);
 }
@@ -274,8 +506,12 @@ export function QueueDownloads(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['app']['App']['QueueDownloads'](arg1, arg2, arg3, arg4, arg5);
 }
 
-export function QueueQobuzDownloads(arg1, arg2, arg3) {
-  return window['go']['app']['App']['QueueQobuzDownloads'](arg1, arg2, arg3);
+export function QueueMissingTracks(arg1, arg2) {
+  return window['go']['app']['App']['QueueMissingTracks'](arg1, arg2);
+}
+
+export function QueueQobuzDownloads(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['app']['App']['QueueQobuzDownloads'](arg1, arg2, arg3, arg4, arg5);
 }
 
 export function QueueSingleDownload(arg1, arg2, arg3, arg4) {
@@ -286,6 +522,10 @@ export function QuickAnalyze(arg1) {
   return window['go']['app']['App']['QuickAnalyze'](arg1);
 }
 
+export function RedownloadBrokenFile(arg1) {
+  return window['go']['app']['App']['RedownloadBrokenFile'](arg1);
+}
+
 export function RefetchFromHistory(arg1) {
   return window['go']['app']['App']['RefetchFromHistory'](arg1);
 }
@@ -294,10 +534,22 @@ export function RefreshTidalEndpoints() {
   return window['go']['app']['App']['RefreshTidalEndpoints']();
 }
 
+export function RemapGenres(arg1) {
+  return window['go']['app']['App']['RemapGenres'](arg1);
+}
+
+export function RemoveFilePicture(arg1, arg2) {
+  return window['go']['app']['App']['RemoveFilePicture'](arg1, arg2);
+}
+
 export function RenameFiles(arg1, arg2) {
   return window['go']['app']['App']['RenameFiles'](arg1, arg2);
 }
 
+export function RequeueIncompleteFile(arg1) {
+  return window['go']['app']['App']['RequeueIncompleteFile'](arg1);
+}
+
 export function ResetToDefaults() {
   return window['go']['app']['App']['ResetToDefaults']();
 }
@@ -314,10 +566,30 @@ export function RetryDownload(arg1) {
   return window['go']['app']['App']['RetryDownload'](arg1);
 }
 
+export function RunDoctor(arg1) {
+  return window['go']['app']['App']['RunDoctor'](arg1);
+}
+
 export function SaveConfig(arg1) {
   return window['go']['app']['App']['SaveConfig'](arg1);
 }
 
+export function SaveCoverArt(arg1, arg2) {
+  return window['go']['app']['App']['SaveCoverArt'](arg1, arg2);
+}
+
+export function SaveLyricsSidecar(arg1, arg2, arg3) {
+  return window['go']['app']['App']['SaveLyricsSidecar'](arg1, arg2, arg3);
+}
+
+export function SaveSettings(arg1) {
+  return window['go']['app']['App']['SaveSettings'](arg1);
+}
+
+export function ScanLibrary() {
+  return window['go']['app']['App']['ScanLibrary']();
+}
+
 export function SearchDeezer(arg1) {
   return window['go']['app']['App']['SearchDeezer'](arg1);
 }
@@ -346,8 +618,12 @@ export function SetDownloadFolder(arg1) {
   return window['go']['app']['App']['SetDownloadFolder'](arg1);
 }
 
-export function SetDownloadOptions(arg1, arg2, arg3, arg4, arg5, arg6) {
-  return window['go']['app']['App']['SetDownloadOptions'](arg1, arg2, arg3, arg4, arg5, arg6);
+export function SetDownloadOptions(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['app']['App']['SetDownloadOptions'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function SetLogLevel(arg1, arg2) {
+  return window['go']['app']['App']['SetLogLevel'](arg1, arg2);
 }
 
 export function SetPreferredSource(arg1) {
@@ -358,18 +634,70 @@ export function SetSourceOrder(arg1) {
   return window['go']['app']['App']['SetSourceOrder'](arg1);
 }
 
+export function SetTags(arg1, arg2, arg3) {
+  return window['go']['app']['App']['SetTags'](arg1, arg2, arg3);
+}
+
 export function SetTidalCredentials(arg1, arg2) {
   return window['go']['app']['App']['SetTidalCredentials'](arg1, arg2);
 }
 
+export function SetTrackOverrides(arg1, arg2) {
+  return window['go']['app']['App']['SetTrackOverrides'](arg1, arg2);
+}
+
+export function SplitAlbum(arg1, arg2) {
+  return window['go']['app']['App']['SplitAlbum'](arg1, arg2);
+}
+
+export function StartAnalysis(arg1, arg2) {
+  return window['go']['app']['App']['StartAnalysis'](arg1, arg2);
+}
+
+export function StartBatch(arg1) {
+  return window['go']['app']['App']['StartBatch'](arg1);
+}
+
+export function StartConversion(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['app']['App']['StartConversion'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function StripTags(arg1, arg2) {
+  return window['go']['app']['App']['StripTags'](arg1, arg2);
+}
+
+export function TagsFromNames(arg1, arg2) {
+  return window['go']['app']['App']['TagsFromNames'](arg1, arg2);
+}
+
 export function TestSoulseekConnection(arg1, arg2) {
   return window['go']['app']['App']['TestSoulseekConnection'](arg1, arg2);
 }
 
+export function TrimSilence(arg1, arg2) {
+  return window['go']['app']['App']['TrimSilence'](arg1, arg2);
+}
+
+export function UndoBatch(arg1) {
+  return window['go']['app']['App']['UndoBatch'](arg1);
+}
+
 export function UpdateQobuzCredentials(arg1, arg2, arg3) {
   return window['go']['app']['App']['UpdateQobuzCredentials'](arg1, arg2, arg3);
 }
 
+export function ValidateFLAC(arg1) {
+  return window['go']['app']['App']['ValidateFLAC'](arg1);
+}
+
 export function ValidateTidalURL(arg1) {
   return window['go']['app']['App']['ValidateTidalURL'](arg1);
 }
+
+export function VerifyFiles(arg1) {
+  return window['go']['app']['App']['VerifyFiles'](arg1);
+}
+
+export function VerifyFolder(arg1) {
+  return window['go']['app']['App']['VerifyFolder'](arg1);
+}
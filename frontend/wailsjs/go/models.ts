@@ -1,45 +1,292 @@
-export namespace app {
+export namespace acoustid {
 	
-	export class EndpointStatus {
-	    name: string;
-	    url: string;
-	    status: string;
-	    latencyMs: number;
+	export class Info {
+	    available: boolean;
+	    path?: string;
+	    version?: string;
+	    keySet: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new EndpointStatus(source);
+	        return new Info(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
-	        this.url = source["url"];
-	        this.status = source["status"];
-	        this.latencyMs = source["latencyMs"];
+	        this.available = source["available"];
+	        this.path = source["path"];
+	        this.version = source["version"];
+	        this.keySet = source["keySet"];
 	    }
 	}
-	export class UpdateInfo {
-	    hasUpdate: boolean;
-	    version: string;
-	    url: string;
-	    releaseUrl: string;
+
+}
+
+export namespace analysis {
+	
+	export class AlbumIssue {
+	    path: string;
+	    problem: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new UpdateInfo(source);
+	        return new AlbumIssue(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.hasUpdate = source["hasUpdate"];
-	        this.version = source["version"];
-	        this.url = source["url"];
-	        this.releaseUrl = source["releaseUrl"];
+	        this.path = source["path"];
+	        this.problem = source["problem"];
+	    }
+	}
+	export class AlbumReport {
+	    folder: string;
+	    tracks: Version[];
+	    format: string;
+	    verdict: string;
+	    consistent: boolean;
+	    issues: AlbumIssue[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AlbumReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.folder = source["folder"];
+	        this.tracks = this.convertValues(source["tracks"], Version);
+	        this.format = source["format"];
+	        this.verdict = source["verdict"];
+	        this.consistent = source["consistent"];
+	        this.issues = this.convertValues(source["issues"], AlbumIssue);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Comparison {
+	    a: Version;
+	    b: Version;
+	    sameAudio: boolean;
+	    keep: string;
+	    reason: string;
+	    differences: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Comparison(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.a = this.convertValues(source["a"], Version);
+	        this.b = this.convertValues(source["b"], Version);
+	        this.sameAudio = source["sameAudio"];
+	        this.keep = source["keep"];
+	        this.reason = source["reason"];
+	        this.differences = source["differences"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Loudness {
+	    integrated: number;
+	    range: number;
+	    truePeak: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Loudness(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.integrated = source["integrated"];
+	        this.range = source["range"];
+	        this.truePeak = source["truePeak"];
+	    }
+	}
+	export class Thresholds {
+	    losslessHz: number;
+	    likelyHz: number;
+	    upscaledHz: number;
+	    hiResHz: number;
+	    losslessConfidence: number;
+	    likelyConfidence: number;
+	    upscaledConfidence: number;
+	    certainConfidence: number;
+	    upsampledConfidence: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Thresholds(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.losslessHz = source["losslessHz"];
+	        this.likelyHz = source["likelyHz"];
+	        this.upscaledHz = source["upscaledHz"];
+	        this.hiResHz = source["hiResHz"];
+	        this.losslessConfidence = source["losslessConfidence"];
+	        this.likelyConfidence = source["likelyConfidence"];
+	        this.upscaledConfidence = source["upscaledConfidence"];
+	        this.certainConfidence = source["certainConfidence"];
+	        this.upsampledConfidence = source["upsampledConfidence"];
+	    }
+	}
+	export class Verification {
+	    path: string;
+	    ok: boolean;
+	    md5: string;
+	    expectedMd5?: string;
+	    samples: number;
+	    expectedSamples?: number;
+	    problems?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Verification(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.ok = source["ok"];
+	        this.md5 = source["md5"];
+	        this.expectedMd5 = source["expectedMd5"];
+	        this.samples = source["samples"];
+	        this.expectedSamples = source["expectedSamples"];
+	        this.problems = source["problems"];
+	    }
+	}
+	export class Version {
+	    path: string;
+	    sampleRate: number;
+	    bitDepth: number;
+	    effectiveBitDepth: number;
+	    cutoff: number;
+	    verdict: string;
+	    verdictLabel: string;
+	    mqa: boolean;
+	    truncated: boolean;
+	    clipping: boolean;
+	    clippedPercent: number;
+	    loudness?: Loudness;
+	    audioMd5?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Version(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.sampleRate = source["sampleRate"];
+	        this.bitDepth = source["bitDepth"];
+	        this.effectiveBitDepth = source["effectiveBitDepth"];
+	        this.cutoff = source["cutoff"];
+	        this.verdict = source["verdict"];
+	        this.verdictLabel = source["verdictLabel"];
+	        this.mqa = source["mqa"];
+	        this.truncated = source["truncated"];
+	        this.clipping = source["clipping"];
+	        this.clippedPercent = source["clippedPercent"];
+	        this.loudness = this.convertValues(source["loudness"], Loudness);
+	        this.audioMd5 = source["audioMd5"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
 
-export namespace core {
+export namespace analysisstore {
+	
+	export class Verdict {
+	    verdict: string;
+	    verdictLabel?: string;
+	    paddedBitDepth?: boolean;
+	    clipping?: boolean;
+	    // Go type: time
+	    analyzedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Verdict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.verdict = source["verdict"];
+	        this.verdictLabel = source["verdictLabel"];
+	        this.paddedBitDepth = source["paddedBitDepth"];
+	        this.clipping = source["clipping"];
+	        this.analyzedAt = this.convertValues(source["analyzedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace app {
 	
 	export class AnalysisResult {
 	    filePath: string;
@@ -53,6 +300,31 @@ export namespace core {
 	    details: string;
 	    sampleRate: number;
 	    bitDepth: number;
+	    effectiveBitDepth: number;
+	    paddedBitDepth: boolean;
+	    bitDepthLabel?: string;
+	    loudness?: analysis.Loudness;
+	    thresholds: analysis.Thresholds;
+	    loudnessTagged?: boolean;
+	    cached?: boolean;
+	    clippedSamples: number;
+	    clippedPercent: number;
+	    interSamplePeaks: number;
+	    clipping: boolean;
+	    clippingLabel?: string;
+	    trailingSilence: number;
+	    truncated: boolean;
+	    missingSeconds?: number;
+	    truncatedLabel?: string;
+	    mqa: boolean;
+	    mqaLabel?: string;
+	    mqaOriginalSampleRate?: number;
+	    correlation: number;
+	    fakeStereo: boolean;
+	    outOfPhase: boolean;
+	    stereoLabel?: string;
+	    lossyCodec?: string;
+	    lossyCodecLabel?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new AnalysisResult(source);
@@ -71,212 +343,1950 @@ export namespace core {
 	        this.details = source["details"];
 	        this.sampleRate = source["sampleRate"];
 	        this.bitDepth = source["bitDepth"];
-	    }
-	}
-	export class Config {
-	    tidalClientId?: string;
-	    tidalClientSecret?: string;
-	    downloadFolder?: string;
-	    downloadQuality?: string;
-	    fileNameFormat?: string;
-	    organizeFolders?: boolean;
-	    folderTemplate?: string;
-	    embedCover: boolean;
-	    saveCoverFile: boolean;
-	    saveFolderCover: boolean;
-	    concurrentDownloads?: number;
-	    theme: string;
-	    accentColor?: string;
-	    soundEffects: boolean;
-	    soundVolume: number;
-	    embedLyrics: boolean;
-	    preferSyncedLyrics: boolean;
-	    saveLyricsFile: boolean;
-	    autoAnalyze: boolean;
-	    autoQualityFallback: boolean;
-	    autoStopOnCooldown: boolean;
-	    tidalEnabled: boolean;
-	    qobuzEnabled: boolean;
-	    soulseekEnabled: boolean;
-	    soulseekUsername?: string;
-	    soulseekPassword?: string;
-	    soulseekBinaryPath?: string;
-	    qobuzAppId?: string;
-	    qobuzAppSecret?: string;
-	    qobuzAuthToken?: string;
-	    preferredSource?: string;
-	    tidalHifiEndpoints?: string[];
-	    qobuzEndpoints?: string[];
-	    tidalCustomEndpoint?: string;
-	    qobuzCustomEndpoint?: string;
-	    tidalPriorityEndpoints?: string[];
-	    qobuzPriorityEndpoints?: string[];
-	    amazonPriorityEndpoints?: string[];
-	    sourceOrder?: string[];
-	    qualityOrder?: string[];
-	    generateM3u8: boolean;
-	    skipUnavailableTracks: boolean;
-	    firstArtistOnly: boolean;
-	    artistSeparator?: string;
-	    artistTagMode?: string;
-	    playlistSubfolder: boolean;
-	    skipExisting: boolean;
-	    externalLibraryPaths?: string[];
-	    jellyfinEnabled: boolean;
-	    jellyfinUrl?: string;
-	    jellyfinApiKey?: string;
-	    separateSingles: boolean;
-	    countryCode?: string;
-	    fontFamily?: string;
-	    proxyUrl?: string;
-	    enableYouTubeFallback: boolean;
-	    autoSelectService: boolean;
-	    amazonEnabled: boolean;
-	    amazonProxyEndpoints?: string[];
-	    qobuzProxyEndpoints?: string[];
-	    qobuzProvidersDisabled?: string[];
-	    parallelEndpointRequests: boolean;
-	
-	    static createFrom(source: any = {}) {
-	        return new Config(source);
+	        this.effectiveBitDepth = source["effectiveBitDepth"];
+	        this.paddedBitDepth = source["paddedBitDepth"];
+	        this.bitDepthLabel = source["bitDepthLabel"];
+	        this.loudness = this.convertValues(source["loudness"], analysis.Loudness);
+	        this.thresholds = this.convertValues(source["thresholds"], analysis.Thresholds);
+	        this.loudnessTagged = source["loudnessTagged"];
+	        this.cached = source["cached"];
+	        this.clippedSamples = source["clippedSamples"];
+	        this.clippedPercent = source["clippedPercent"];
+	        this.interSamplePeaks = source["interSamplePeaks"];
+	        this.clipping = source["clipping"];
+	        this.clippingLabel = source["clippingLabel"];
+	        this.trailingSilence = source["trailingSilence"];
+	        this.truncated = source["truncated"];
+	        this.missingSeconds = source["missingSeconds"];
+	        this.truncatedLabel = source["truncatedLabel"];
+	        this.mqa = source["mqa"];
+	        this.mqaLabel = source["mqaLabel"];
+	        this.mqaOriginalSampleRate = source["mqaOriginalSampleRate"];
+	        this.correlation = source["correlation"];
+	        this.fakeStereo = source["fakeStereo"];
+	        this.outOfPhase = source["outOfPhase"];
+	        this.stereoLabel = source["stereoLabel"];
+	        this.lossyCodec = source["lossyCodec"];
+	        this.lossyCodecLabel = source["lossyCodecLabel"];
 	    }
 	
-	    constructor(source: any = {}) {
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BatchRequest {
+	    op: string;
+	    files: string[];
+	    atomic: boolean;
+	    template?: string;
+	    tags?: Record<string, string>;
+	    mode?: string;
+	    strip: tagedit.StripOptions;
+	    pattern?: string;
+	    rules: tagrules.Rules;
+	    dest?: string;
+	    format?: string;
+	    quality?: string;
+	    outputDir?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.op = source["op"];
+	        this.files = source["files"];
+	        this.atomic = source["atomic"];
+	        this.template = source["template"];
+	        this.tags = source["tags"];
+	        this.mode = source["mode"];
+	        this.strip = source["strip"];
+	        this.pattern = source["pattern"];
+	        this.rules = source["rules"];
+	        this.dest = source["dest"];
+	        this.format = source["format"];
+	        this.quality = source["quality"];
+	        this.outputDir = source["outputDir"];
+	    }
+	}
+	export class CompletenessReport {
+	    folder: string;
+	    url: string;
+	    source: string;
+	    type: string;
+	    title: string;
+	    artist?: string;
+	    tracks: CompletenessTrack[];
+	    missing: number;
+	    extra?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CompletenessReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.folder = source["folder"];
+	        this.url = source["url"];
+	        this.source = source["source"];
+	        this.type = source["type"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.tracks = this.convertValues(source["tracks"], CompletenessTrack);
+	        this.missing = source["missing"];
+	        this.extra = source["extra"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CompletenessTrack {
+	    trackId: string;
+	    title: string;
+	    artist: string;
+	    trackNumber?: number;
+	    discNumber?: number;
+	    isrc?: string;
+	    file?: string;
+	    by?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompletenessTrack(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.trackId = source["trackId"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.trackNumber = source["trackNumber"];
+	        this.discNumber = source["discNumber"];
+	        this.isrc = source["isrc"];
+	        this.file = source["file"];
+	        this.by = source["by"];
+	    }
+	}
+	export class EndpointStatus {
+	    name: string;
+	    url: string;
+	    status: string;
+	    latencyMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EndpointStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.url = source["url"];
+	        this.status = source["status"];
+	        this.latencyMs = source["latencyMs"];
+	    }
+	}
+	export class FileInfo {
+	    analysis?: analysisstore.Verdict;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.analysis = this.convertValues(source["analysis"], analysisstore.Verdict);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FileMetadata {
+	    path: string;
+	    title: string;
+	    artist: string;
+	    album: string;
+	    trackNumber: string;
+	    date: string;
+	    genre: string;
+	    isrc: string;
+	    albumArtist?: string;
+	    discNumber?: string;
+	    copyright?: string;
+	    label?: string;
+	    composer?: string;
+	    comment: string;
+	    size: number;
+	    duration: number;
+	    sampleRate: number;
+	    bitDepth: number;
+	    channels: number;
+	    bitrate: number;
+	    hasCover: boolean;
+	    coverMime?: string;
+	    coverSize?: number;
+	    totalSamples: number;
+	    lyrics?: string;
+	    syncedLyrics?: string;
+	    hasLyrics: boolean;
+	    upc?: string;
+	    popularity?: number;
+	    source?: string;
+	    sourceId?: string;
+	    downloadDate?: string;
+	    artists?: string[];
+	    genres?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FileMetadata(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.album = source["album"];
+	        this.trackNumber = source["trackNumber"];
+	        this.date = source["date"];
+	        this.genre = source["genre"];
+	        this.isrc = source["isrc"];
+	        this.albumArtist = source["albumArtist"];
+	        this.discNumber = source["discNumber"];
+	        this.copyright = source["copyright"];
+	        this.label = source["label"];
+	        this.composer = source["composer"];
+	        this.comment = source["comment"];
+	        this.size = source["size"];
+	        this.duration = source["duration"];
+	        this.sampleRate = source["sampleRate"];
+	        this.bitDepth = source["bitDepth"];
+	        this.channels = source["channels"];
+	        this.bitrate = source["bitrate"];
+	        this.hasCover = source["hasCover"];
+	        this.coverMime = source["coverMime"];
+	        this.coverSize = source["coverSize"];
+	        this.totalSamples = source["totalSamples"];
+	        this.lyrics = source["lyrics"];
+	        this.syncedLyrics = source["syncedLyrics"];
+	        this.hasLyrics = source["hasLyrics"];
+	        this.upc = source["upc"];
+	        this.popularity = source["popularity"];
+	        this.source = source["source"];
+	        this.sourceId = source["sourceId"];
+	        this.downloadDate = source["downloadDate"];
+	        this.artists = source["artists"];
+	        this.genres = source["genres"];
+	    }
+	}
+	export class FolderCover {
+	    folder: string;
+	    path?: string;
+	    skipped?: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderCover(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.folder = source["folder"];
+	        this.path = source["path"];
+	        this.skipped = source["skipped"];
+	        this.error = source["error"];
+	    }
+	}
+	export class ImportResult {
+	    url: string;
+	    source?: string;
+	    type?: string;
+	    title?: string;
+	    queued: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.source = source["source"];
+	        this.type = source["type"];
+	        this.title = source["title"];
+	        this.queued = source["queued"];
+	        this.error = source["error"];
+	    }
+	}
+	export class LyricsPreview {
+	    filePath: string;
+	    instrumental?: boolean;
+	    match?: lyricsmatch.Match;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LyricsPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.instrumental = source["instrumental"];
+	        this.match = this.convertValues(source["match"], lyricsmatch.Match);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PictureInfo {
+	    index: number;
+	    type: number;
+	    typeName: string;
+	    mimeType: string;
+	    description: string;
+	    width: number;
+	    height: number;
+	    size: number;
+	    data: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PictureInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.type = source["type"];
+	        this.typeName = source["typeName"];
+	        this.mimeType = source["mimeType"];
+	        this.description = source["description"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.size = source["size"];
+	        this.data = source["data"];
+	    }
+	}
+	export class SplitReport {
+	    file: string;
+	    album: string;
+	    split: number;
+	    pieces: split.Piece[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SplitReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.album = source["album"];
+	        this.split = source["split"];
+	        this.pieces = source["pieces"];
+	    }
+	}
+	export class TagImportReport {
+	    source: string;
+	    type: string;
+	    title: string;
+	    tagged: number;
+	    results: TagImportResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TagImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.type = source["type"];
+	        this.title = source["title"];
+	        this.tagged = source["tagged"];
+	        this.results = this.convertValues(source["results"], TagImportResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TagImportResult {
+	    file?: string;
+	    dest?: string;
+	    trackId?: string;
+	    track?: string;
+	    by?: string;
+	    warning?: string;
+	    error?: string;
+	    changes?: tagedit.Change[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TagImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.dest = source["dest"];
+	        this.trackId = source["trackId"];
+	        this.track = source["track"];
+	        this.by = source["by"];
+	        this.warning = source["warning"];
+	        this.error = source["error"];
+	        this.changes = this.convertValues(source["changes"], tagedit.Change);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UpdateInfo {
+	    hasUpdate: boolean;
+	    version: string;
+	    url: string;
+	    releaseUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hasUpdate = source["hasUpdate"];
+	        this.version = source["version"];
+	        this.url = source["url"];
+	        this.releaseUrl = source["releaseUrl"];
+	    }
+	}
+
+}
+
+export namespace configdiff {
+	
+	export class Change {
+	    key: string;
+	    effect: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Change(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.effect = source["effect"];
+	        this.error = source["error"];
+	    }
+	}
+
+}
+
+export namespace core {
+	
+	export class AnalysisResult {
+	    filePath: string;
+	    fileName: string;
+	    isTrueLossless: boolean;
+	    confidence: number;
+	    spectrumCutoff: number;
+	    expectedCutoff: number;
+	    verdict: string;
+	    verdictLabel: string;
+	    details: string;
+	    sampleRate: number;
+	    bitDepth: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnalysisResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.fileName = source["fileName"];
+	        this.isTrueLossless = source["isTrueLossless"];
+	        this.confidence = source["confidence"];
+	        this.spectrumCutoff = source["spectrumCutoff"];
+	        this.expectedCutoff = source["expectedCutoff"];
+	        this.verdict = source["verdict"];
+	        this.verdictLabel = source["verdictLabel"];
+	        this.details = source["details"];
+	        this.sampleRate = source["sampleRate"];
+	        this.bitDepth = source["bitDepth"];
+	    }
+	}
+	export class Config {
+	    tidalClientId?: string;
+	    tidalClientSecret?: string;
+	    downloadFolder?: string;
+	    downloadQuality?: string;
+	    fileNameFormat?: string;
+	    organizeFolders?: boolean;
+	    folderTemplate?: string;
+	    embedCover: boolean;
+	    saveCoverFile: boolean;
+	    saveFolderCover: boolean;
+	    concurrentDownloads?: number;
+	    theme: string;
+	    accentColor?: string;
+	    soundEffects: boolean;
+	    soundVolume: number;
+	    embedLyrics: boolean;
+	    preferSyncedLyrics: boolean;
+	    saveLyricsFile: boolean;
+	    autoAnalyze: boolean;
+	    autoQualityFallback: boolean;
+	    autoStopOnCooldown: boolean;
+	    tidalEnabled: boolean;
+	    qobuzEnabled: boolean;
+	    soulseekEnabled: boolean;
+	    soulseekUsername?: string;
+	    soulseekPassword?: string;
+	    soulseekBinaryPath?: string;
+	    qobuzAppId?: string;
+	    qobuzAppSecret?: string;
+	    qobuzAuthToken?: string;
+	    preferredSource?: string;
+	    tidalHifiEndpoints?: string[];
+	    qobuzEndpoints?: string[];
+	    tidalCustomEndpoint?: string;
+	    qobuzCustomEndpoint?: string;
+	    tidalPriorityEndpoints?: string[];
+	    qobuzPriorityEndpoints?: string[];
+	    amazonPriorityEndpoints?: string[];
+	    sourceOrder?: string[];
+	    qualityOrder?: string[];
+	    generateM3u8: boolean;
+	    skipUnavailableTracks: boolean;
+	    firstArtistOnly: boolean;
+	    artistSeparator?: string;
+	    artistTagMode?: string;
+	    playlistSubfolder: boolean;
+	    skipExisting: boolean;
+	    externalLibraryPaths?: string[];
+	    jellyfinEnabled: boolean;
+	    jellyfinUrl?: string;
+	    jellyfinApiKey?: string;
+	    separateSingles: boolean;
+	    countryCode?: string;
+	    fontFamily?: string;
+	    proxyUrl?: string;
+	    enableYouTubeFallback: boolean;
+	    autoSelectService: boolean;
+	    amazonEnabled: boolean;
+	    amazonProxyEndpoints?: string[];
+	    qobuzProxyEndpoints?: string[];
+	    qobuzProvidersDisabled?: string[];
+	    parallelEndpointRequests: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tidalClientId = source["tidalClientId"];
+	        this.tidalClientSecret = source["tidalClientSecret"];
+	        this.downloadFolder = source["downloadFolder"];
+	        this.downloadQuality = source["downloadQuality"];
+	        this.fileNameFormat = source["fileNameFormat"];
+	        this.organizeFolders = source["organizeFolders"];
+	        this.folderTemplate = source["folderTemplate"];
+	        this.embedCover = source["embedCover"];
+	        this.saveCoverFile = source["saveCoverFile"];
+	        this.saveFolderCover = source["saveFolderCover"];
+	        this.concurrentDownloads = source["concurrentDownloads"];
+	        this.theme = source["theme"];
+	        this.accentColor = source["accentColor"];
+	        this.soundEffects = source["soundEffects"];
+	        this.soundVolume = source["soundVolume"];
+	        this.embedLyrics = source["embedLyrics"];
+	        this.preferSyncedLyrics = source["preferSyncedLyrics"];
+	        this.saveLyricsFile = source["saveLyricsFile"];
+	        this.autoAnalyze = source["autoAnalyze"];
+	        this.autoQualityFallback = source["autoQualityFallback"];
+	        this.autoStopOnCooldown = source["autoStopOnCooldown"];
+	        this.tidalEnabled = source["tidalEnabled"];
+	        this.qobuzEnabled = source["qobuzEnabled"];
+	        this.soulseekEnabled = source["soulseekEnabled"];
+	        this.soulseekUsername = source["soulseekUsername"];
+	        this.soulseekPassword = source["soulseekPassword"];
+	        this.soulseekBinaryPath = source["soulseekBinaryPath"];
+	        this.qobuzAppId = source["qobuzAppId"];
+	        this.qobuzAppSecret = source["qobuzAppSecret"];
+	        this.qobuzAuthToken = source["qobuzAuthToken"];
+	        this.preferredSource = source["preferredSource"];
+	        this.tidalHifiEndpoints = source["tidalHifiEndpoints"];
+	        this.qobuzEndpoints = source["qobuzEndpoints"];
+	        this.tidalCustomEndpoint = source["tidalCustomEndpoint"];
+	        this.qobuzCustomEndpoint = source["qobuzCustomEndpoint"];
+	        this.tidalPriorityEndpoints = source["tidalPriorityEndpoints"];
+	        this.qobuzPriorityEndpoints = source["qobuzPriorityEndpoints"];
+	        this.amazonPriorityEndpoints = source["amazonPriorityEndpoints"];
+	        this.sourceOrder = source["sourceOrder"];
+	        this.qualityOrder = source["qualityOrder"];
+	        this.generateM3u8 = source["generateM3u8"];
+	        this.skipUnavailableTracks = source["skipUnavailableTracks"];
+	        this.firstArtistOnly = source["firstArtistOnly"];
+	        this.artistSeparator = source["artistSeparator"];
+	        this.artistTagMode = source["artistTagMode"];
+	        this.playlistSubfolder = source["playlistSubfolder"];
+	        this.skipExisting = source["skipExisting"];
+	        this.externalLibraryPaths = source["externalLibraryPaths"];
+	        this.jellyfinEnabled = source["jellyfinEnabled"];
+	        this.jellyfinUrl = source["jellyfinUrl"];
+	        this.jellyfinApiKey = source["jellyfinApiKey"];
+	        this.separateSingles = source["separateSingles"];
+	        this.countryCode = source["countryCode"];
+	        this.fontFamily = source["fontFamily"];
+	        this.proxyUrl = source["proxyUrl"];
+	        this.enableYouTubeFallback = source["enableYouTubeFallback"];
+	        this.autoSelectService = source["autoSelectService"];
+	        this.amazonEnabled = source["amazonEnabled"];
+	        this.amazonProxyEndpoints = source["amazonProxyEndpoints"];
+	        this.qobuzProxyEndpoints = source["qobuzProxyEndpoints"];
+	        this.qobuzProvidersDisabled = source["qobuzProvidersDisabled"];
+	        this.parallelEndpointRequests = source["parallelEndpointRequests"];
+	    }
+	}
+	export class ConversionFormat {
+	    id: string;
+	    name: string;
+	    extension: string;
+	    qualities: string[];
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConversionFormat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.extension = source["extension"];
+	        this.qualities = source["qualities"];
+	        this.description = source["description"];
+	    }
+	}
+	export class ConversionResult {
+	    sourcePath: string;
+	    outputPath: string;
+	    success: boolean;
+	    error?: string;
+	    outputSize?: number;
+	    sourceSize?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConversionResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sourcePath = source["sourcePath"];
+	        this.outputPath = source["outputPath"];
+	        this.success = source["success"];
+	        this.error = source["error"];
+	        this.outputSize = source["outputSize"];
+	        this.sourceSize = source["sourceSize"];
+	    }
+	}
+	export class DownloadRecord {
+	    id: number;
+	    tidalContentId: string;
+	    tidalContentName: string;
+	    contentType: string;
+	    // Go type: time
+	    lastDownloadAt: any;
+	    tracksTotal: number;
+	    tracksDownloaded: number;
+	    tracksFailed: number;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new DownloadRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.tidalContentId = source["tidalContentId"];
+	        this.tidalContentName = source["tidalContentName"];
+	        this.contentType = source["contentType"];
+	        this.lastDownloadAt = this.convertValues(source["lastDownloadAt"], null);
+	        this.tracksTotal = source["tracksTotal"];
+	        this.tracksDownloaded = source["tracksDownloaded"];
+	        this.tracksFailed = source["tracksFailed"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DownloadResult {
+	    trackId: number;
+	    title: string;
+	    artist: string;
+	    album: string;
+	    filePath: string;
+	    fileSize: number;
+	    quality: string;
+	    requestedQuality?: string;
+	    qualityMismatch?: boolean;
+	    coverUrl: string;
+	    success: boolean;
+	    error?: string;
+	    analysis?: AnalysisResult;
+	    source?: string;
+	    attempts?: string[];
+	    bytesDownloaded?: number;
+	    bytesTotal?: number;
+	    speed?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DownloadResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.trackId = source["trackId"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.album = source["album"];
+	        this.filePath = source["filePath"];
+	        this.fileSize = source["fileSize"];
+	        this.quality = source["quality"];
+	        this.requestedQuality = source["requestedQuality"];
+	        this.qualityMismatch = source["qualityMismatch"];
+	        this.coverUrl = source["coverUrl"];
+	        this.success = source["success"];
+	        this.error = source["error"];
+	        this.analysis = this.convertValues(source["analysis"], AnalysisResult);
+	        this.source = source["source"];
+	        this.attempts = source["attempts"];
+	        this.bytesDownloaded = source["bytesDownloaded"];
+	        this.bytesTotal = source["bytesTotal"];
+	        this.speed = source["speed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DownloadedFileInfo {
+	    path: string;
+	    name: string;
+	    size: number;
+	    modTime: string;
+	    title: string;
+	    artist: string;
+	    album: string;
+	    source: string;
+	    quality: string;
+	    format: string;
+	    discNumber: number;
+	    trackNumber: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DownloadedFileInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.modTime = source["modTime"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.album = source["album"];
+	        this.source = source["source"];
+	        this.quality = source["quality"];
+	        this.format = source["format"];
+	        this.discNumber = source["discNumber"];
+	        this.trackNumber = source["trackNumber"];
+	    }
+	}
+	export class EndpointStat {
+	    url: string;
+	    state: string;
+	    fails: number;
+	    revivals: number;
+	    latencyMs?: number;
+	    cooldownSecs?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EndpointStat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.state = source["state"];
+	        this.fails = source["fails"];
+	        this.revivals = source["revivals"];
+	        this.latencyMs = source["latencyMs"];
+	        this.cooldownSecs = source["cooldownSecs"];
+	    }
+	}
+	export class FLACMetadata {
+	    path: string;
+	    title: string;
+	    artist: string;
+	    album: string;
+	    trackNumber: string;
+	    date: string;
+	    genre: string;
+	    isrc: string;
+	    albumArtist?: string;
+	    discNumber?: string;
+	    copyright?: string;
+	    label?: string;
+	    composer?: string;
+	    comment: string;
+	    size: number;
+	    duration: number;
+	    sampleRate: number;
+	    bitDepth: number;
+	    channels: number;
+	    bitrate: number;
+	    hasCover: boolean;
+	    coverMime?: string;
+	    coverSize?: number;
+	    totalSamples: number;
+	    lyrics?: string;
+	    syncedLyrics?: string;
+	    hasLyrics: boolean;
+	    upc?: string;
+	    popularity?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FLACMetadata(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.album = source["album"];
+	        this.trackNumber = source["trackNumber"];
+	        this.date = source["date"];
+	        this.genre = source["genre"];
+	        this.isrc = source["isrc"];
+	        this.albumArtist = source["albumArtist"];
+	        this.discNumber = source["discNumber"];
+	        this.copyright = source["copyright"];
+	        this.label = source["label"];
+	        this.composer = source["composer"];
+	        this.comment = source["comment"];
+	        this.size = source["size"];
+	        this.duration = source["duration"];
+	        this.sampleRate = source["sampleRate"];
+	        this.bitDepth = source["bitDepth"];
+	        this.channels = source["channels"];
+	        this.bitrate = source["bitrate"];
+	        this.hasCover = source["hasCover"];
+	        this.coverMime = source["coverMime"];
+	        this.coverSize = source["coverSize"];
+	        this.totalSamples = source["totalSamples"];
+	        this.lyrics = source["lyrics"];
+	        this.syncedLyrics = source["syncedLyrics"];
+	        this.hasLyrics = source["hasLyrics"];
+	        this.upc = source["upc"];
+	        this.popularity = source["popularity"];
+	    }
+	}
+	export class LogEntry {
+	    timestamp: string;
+	    level: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = source["timestamp"];
+	        this.level = source["level"];
+	        this.message = source["message"];
+	    }
+	}
+	export class Lyrics {
+	    plain: string;
+	    synced: string;
+	    source: string;
+	    hasSynced: boolean;
+	    instrumental: boolean;
+	    trackName: string;
+	    artistName: string;
+	    albumName: string;
+	    duration: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Lyrics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.plain = source["plain"];
+	        this.synced = source["synced"];
+	        this.source = source["source"];
+	        this.hasSynced = source["hasSynced"];
+	        this.instrumental = source["instrumental"];
+	        this.trackName = source["trackName"];
+	        this.artistName = source["artistName"];
+	        this.albumName = source["albumName"];
+	        this.duration = source["duration"];
+	    }
+	}
+	export class MatchFailure {
+	    id: number;
+	    tidalTrackId: string;
+	    isrc: string;
+	    title: string;
+	    artist: string;
+	    album: string;
+	    reason: string;
+	    attempts: number;
+	    // Go type: time
+	    lastAttemptAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new MatchFailure(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.tidalTrackId = source["tidalTrackId"];
+	        this.isrc = source["isrc"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.album = source["album"];
+	        this.reason = source["reason"];
+	        this.attempts = source["attempts"];
+	        this.lastAttemptAt = this.convertValues(source["lastAttemptAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SpotifyTrack {
+	    id: string;
+	    name: string;
+	    artists: string;
+	    album: string;
+	    durationMs: number;
+	    uri: string;
+	    isrc?: string;
+	    preview_url?: string;
+	    popularity?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SpotifyTrack(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.artists = source["artists"];
+	        this.album = source["album"];
+	        this.durationMs = source["durationMs"];
+	        this.uri = source["uri"];
+	        this.isrc = source["isrc"];
+	        this.preview_url = source["preview_url"];
+	        this.popularity = source["popularity"];
+	    }
+	}
+	export class TidalTrack {
+	    id: number;
+	    title: string;
+	    version?: string;
+	    artist: string;
+	    artists: string;
+	    albumArtist?: string;
+	    album: string;
+	    albumId: number;
+	    isrc: string;
+	    duration: number;
+	    trackNumber: number;
+	    discNumber?: number;
+	    totalDiscs?: number;
+	    releaseDate?: string;
+	    coverUrl: string;
+	    explicit: boolean;
+	    tidalUrl: string;
+	    available: boolean;
+	    previewUrl?: string;
+	    copyright?: string;
+	    label?: string;
+	    popularity?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TidalTrack(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.version = source["version"];
+	        this.artist = source["artist"];
+	        this.artists = source["artists"];
+	        this.albumArtist = source["albumArtist"];
+	        this.album = source["album"];
+	        this.albumId = source["albumId"];
+	        this.isrc = source["isrc"];
+	        this.duration = source["duration"];
+	        this.trackNumber = source["trackNumber"];
+	        this.discNumber = source["discNumber"];
+	        this.totalDiscs = source["totalDiscs"];
+	        this.releaseDate = source["releaseDate"];
+	        this.coverUrl = source["coverUrl"];
+	        this.explicit = source["explicit"];
+	        this.tidalUrl = source["tidalUrl"];
+	        this.available = source["available"];
+	        this.previewUrl = source["previewUrl"];
+	        this.copyright = source["copyright"];
+	        this.label = source["label"];
+	        this.popularity = source["popularity"];
+	    }
+	}
+	export class MatchResult {
+	    tidalTrack: TidalTrack;
+	    spotifyTrack?: SpotifyTrack;
+	    matched: boolean;
+	    matchMethod: string;
+	    confidence: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MatchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tidalTrack = this.convertValues(source["tidalTrack"], TidalTrack);
+	        this.spotifyTrack = this.convertValues(source["spotifyTrack"], SpotifyTrack);
+	        this.matched = source["matched"];
+	        this.matchMethod = source["matchMethod"];
+	        this.confidence = source["confidence"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RenamePreview {
+	    oldPath: string;
+	    oldName: string;
+	    newName: string;
+	    newPath: string;
+	    hasError: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RenamePreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.oldPath = source["oldPath"];
+	        this.oldName = source["oldName"];
+	        this.newName = source["newName"];
+	        this.newPath = source["newPath"];
+	        this.hasError = source["hasError"];
+	        this.error = source["error"];
+	    }
+	}
+	export class RenameResult {
+	    oldPath: string;
+	    newPath: string;
+	    success: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RenameResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.oldPath = source["oldPath"];
+	        this.newPath = source["newPath"];
+	        this.success = source["success"];
+	        this.error = source["error"];
+	    }
+	}
+	export class SourceTrack {
+	    id: string;
+	    title: string;
+	    artist: string;
+	    artists: string[];
+	    album: string;
+	    albumId: string;
+	    isrc: string;
+	    duration: number;
+	    trackNumber: number;
+	    totalTracks: number;
+	    discNumber: number;
+	    year: string;
+	    genre: string;
+	    coverUrl: string;
+	    explicit: boolean;
+	    sourceUrl: string;
+	    source: string;
+	    quality: string;
+	    previewUrl?: string;
+	    upc?: string;
+	    popularity?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SourceTrack(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.artists = source["artists"];
+	        this.album = source["album"];
+	        this.albumId = source["albumId"];
+	        this.isrc = source["isrc"];
+	        this.duration = source["duration"];
+	        this.trackNumber = source["trackNumber"];
+	        this.totalTracks = source["totalTracks"];
+	        this.discNumber = source["discNumber"];
+	        this.year = source["year"];
+	        this.genre = source["genre"];
+	        this.coverUrl = source["coverUrl"];
+	        this.explicit = source["explicit"];
+	        this.sourceUrl = source["sourceUrl"];
+	        this.source = source["source"];
+	        this.quality = source["quality"];
+	        this.previewUrl = source["previewUrl"];
+	        this.upc = source["upc"];
+	        this.popularity = source["popularity"];
+	    }
+	}
+	export class SourceAlbum {
+	    id: string;
+	    title: string;
+	    artist: string;
+	    artists: string[];
+	    year: string;
+	    genre: string;
+	    coverUrl: string;
+	    trackCount: number;
+	    tracks: SourceTrack[];
+	    source: string;
+	    sourceUrl: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SourceAlbum(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.artists = source["artists"];
+	        this.year = source["year"];
+	        this.genre = source["genre"];
+	        this.coverUrl = source["coverUrl"];
+	        this.trackCount = source["trackCount"];
+	        this.tracks = this.convertValues(source["tracks"], SourceTrack);
+	        this.source = source["source"];
+	        this.sourceUrl = source["sourceUrl"];
+	        this.description = source["description"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SourceHealth {
+	    name: string;
+	    displayName: string;
+	    status: string;
+	    latencyMs: number;
+	    reason?: string;
+	    endpoints?: EndpointStat[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SourceHealth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.displayName = source["displayName"];
+	        this.status = source["status"];
+	        this.latencyMs = source["latencyMs"];
+	        this.reason = source["reason"];
+	        this.endpoints = this.convertValues(source["endpoints"], EndpointStat);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SourceInfo {
+	    name: string;
+	    displayName: string;
+	    available: boolean;
+	    urlPattern: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SourceInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.displayName = source["displayName"];
+	        this.available = source["available"];
+	        this.urlPattern = source["urlPattern"];
+	    }
+	}
+	export class SourcePlaylist {
+	    id: string;
+	    title: string;
+	    description: string;
+	    creator: string;
+	    coverUrl: string;
+	    trackCount: number;
+	    tracks: SourceTrack[];
+	    source: string;
+	    sourceUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SourcePlaylist(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.creator = source["creator"];
+	        this.coverUrl = source["coverUrl"];
+	        this.trackCount = source["trackCount"];
+	        this.tracks = this.convertValues(source["tracks"], SourceTrack);
+	        this.source = source["source"];
+	        this.sourceUrl = source["sourceUrl"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class TidalAlbum {
+	    id: number;
+	    title: string;
+	    artist: string;
+	    releaseDate: string;
+	    trackCount: number;
+	    coverUrl: string;
+	    albumType?: string;
+	    copyright?: string;
+	    label?: string;
+	    tracks: TidalTrack[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TidalAlbum(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.releaseDate = source["releaseDate"];
+	        this.trackCount = source["trackCount"];
+	        this.coverUrl = source["coverUrl"];
+	        this.albumType = source["albumType"];
+	        this.copyright = source["copyright"];
+	        this.label = source["label"];
+	        this.tracks = this.convertValues(source["tracks"], TidalTrack);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TidalArtist {
+	    id: number;
+	    name: string;
+	    pictureUrl?: string;
+	    albums: TidalAlbum[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TidalArtist(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.pictureUrl = source["pictureUrl"];
+	        this.albums = this.convertValues(source["albums"], TidalAlbum);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TidalPlaylist {
+	    uuid: string;
+	    title: string;
+	    description: string;
+	    creator: string;
+	    coverUrl: string;
+	    numberOfTracks: number;
+	    tracks: TidalTrack[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TidalPlaylist(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.uuid = source["uuid"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.creator = source["creator"];
+	        this.coverUrl = source["coverUrl"];
+	        this.numberOfTracks = source["numberOfTracks"];
+	        this.tracks = this.convertValues(source["tracks"], TidalTrack);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace coverstore {
+	
+	export class Cover {
+	    hash: string;
+	    size: number;
+	    tracks: number;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Cover(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hash = source["hash"];
+	        this.size = source["size"];
+	        this.tracks = source["tracks"];
+	        this.path = source["path"];
+	    }
+	}
+	export class Library {
+	    files: number;
+	    withCover: number;
+	    embeddedBytes: number;
+	    uniqueBytes: number;
+	    covers: Cover[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Library(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = source["files"];
+	        this.withCover = source["withCover"];
+	        this.embeddedBytes = source["embeddedBytes"];
+	        this.uniqueBytes = source["uniqueBytes"];
+	        this.covers = this.convertValues(source["covers"], Cover);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace credits {
+	
+	export class Album {
+	    source: string;
+	    id: string;
+	    title: string;
+	    artist: string;
+	    tracks: Track[];
+	    roles: Role[];
+	    fetchedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Album(source);
+	    }
+	
+	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.tidalClientId = source["tidalClientId"];
-	        this.tidalClientSecret = source["tidalClientSecret"];
-	        this.downloadFolder = source["downloadFolder"];
-	        this.downloadQuality = source["downloadQuality"];
-	        this.fileNameFormat = source["fileNameFormat"];
-	        this.organizeFolders = source["organizeFolders"];
-	        this.folderTemplate = source["folderTemplate"];
-	        this.embedCover = source["embedCover"];
-	        this.saveCoverFile = source["saveCoverFile"];
-	        this.saveFolderCover = source["saveFolderCover"];
-	        this.concurrentDownloads = source["concurrentDownloads"];
-	        this.theme = source["theme"];
-	        this.accentColor = source["accentColor"];
-	        this.soundEffects = source["soundEffects"];
-	        this.soundVolume = source["soundVolume"];
-	        this.embedLyrics = source["embedLyrics"];
-	        this.preferSyncedLyrics = source["preferSyncedLyrics"];
-	        this.saveLyricsFile = source["saveLyricsFile"];
-	        this.autoAnalyze = source["autoAnalyze"];
-	        this.autoQualityFallback = source["autoQualityFallback"];
-	        this.autoStopOnCooldown = source["autoStopOnCooldown"];
-	        this.tidalEnabled = source["tidalEnabled"];
-	        this.qobuzEnabled = source["qobuzEnabled"];
-	        this.soulseekEnabled = source["soulseekEnabled"];
-	        this.soulseekUsername = source["soulseekUsername"];
-	        this.soulseekPassword = source["soulseekPassword"];
-	        this.soulseekBinaryPath = source["soulseekBinaryPath"];
-	        this.qobuzAppId = source["qobuzAppId"];
-	        this.qobuzAppSecret = source["qobuzAppSecret"];
-	        this.qobuzAuthToken = source["qobuzAuthToken"];
-	        this.preferredSource = source["preferredSource"];
-	        this.tidalHifiEndpoints = source["tidalHifiEndpoints"];
-	        this.qobuzEndpoints = source["qobuzEndpoints"];
-	        this.tidalCustomEndpoint = source["tidalCustomEndpoint"];
-	        this.qobuzCustomEndpoint = source["qobuzCustomEndpoint"];
-	        this.tidalPriorityEndpoints = source["tidalPriorityEndpoints"];
-	        this.qobuzPriorityEndpoints = source["qobuzPriorityEndpoints"];
-	        this.amazonPriorityEndpoints = source["amazonPriorityEndpoints"];
-	        this.sourceOrder = source["sourceOrder"];
-	        this.qualityOrder = source["qualityOrder"];
-	        this.generateM3u8 = source["generateM3u8"];
-	        this.skipUnavailableTracks = source["skipUnavailableTracks"];
-	        this.firstArtistOnly = source["firstArtistOnly"];
-	        this.artistSeparator = source["artistSeparator"];
-	        this.artistTagMode = source["artistTagMode"];
-	        this.playlistSubfolder = source["playlistSubfolder"];
-	        this.skipExisting = source["skipExisting"];
-	        this.externalLibraryPaths = source["externalLibraryPaths"];
-	        this.jellyfinEnabled = source["jellyfinEnabled"];
-	        this.jellyfinUrl = source["jellyfinUrl"];
-	        this.jellyfinApiKey = source["jellyfinApiKey"];
-	        this.separateSingles = source["separateSingles"];
-	        this.countryCode = source["countryCode"];
-	        this.fontFamily = source["fontFamily"];
-	        this.proxyUrl = source["proxyUrl"];
-	        this.enableYouTubeFallback = source["enableYouTubeFallback"];
-	        this.autoSelectService = source["autoSelectService"];
-	        this.amazonEnabled = source["amazonEnabled"];
-	        this.amazonProxyEndpoints = source["amazonProxyEndpoints"];
-	        this.qobuzProxyEndpoints = source["qobuzProxyEndpoints"];
-	        this.qobuzProvidersDisabled = source["qobuzProvidersDisabled"];
-	        this.parallelEndpointRequests = source["parallelEndpointRequests"];
+	        this.source = source["source"];
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.tracks = this.convertValues(source["tracks"], Track);
+	        this.roles = this.convertValues(source["roles"], Role);
+	        this.fetchedAt = this.convertValues(source["fetchedAt"], null);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class ConversionFormat {
+	export class Credit {
+	    name: string;
+	    role: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Credit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.role = source["role"];
+	    }
+	}
+	export class Role {
+	    role: string;
+	    names: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Role(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.role = source["role"];
+	        this.names = source["names"];
+	    }
+	}
+	export class Track {
 	    id: string;
+	    title: string;
+	    trackNumber?: number;
+	    discNumber?: number;
+	    credits: Credit[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Track(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.trackNumber = source["trackNumber"];
+	        this.discNumber = source["discNumber"];
+	        this.credits = this.convertValues(source["credits"], Credit);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace doctor {
+	
+	export class Result {
 	    name: string;
-	    extension: string;
-	    qualities: string[];
-	    description: string;
+	    status: string;
+	    detail: string;
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Result(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.detail = source["detail"];
+	        this.durationMs = source["durationMs"];
+	    }
+	}
+	export class Report {
+	    results: Result[];
+	    ok: boolean;
+	    startedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.results = this.convertValues(source["results"], Result);
+	        this.ok = source["ok"];
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace downloads {
+	
+	export class Job {
+	    id: number;
+	    state: string;
+	    attempts: number;
+	    transitions: Transition[];
+	    queueTimeMs: number;
+	    downloadTimeMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Job(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.state = source["state"];
+	        this.attempts = source["attempts"];
+	        this.transitions = this.convertValues(source["transitions"], Transition);
+	        this.queueTimeMs = source["queueTimeMs"];
+	        this.downloadTimeMs = source["downloadTimeMs"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Transition {
+	    state: string;
+	    // Go type: time
+	    at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Transition(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.state = source["state"];
+	        this.at = this.convertValues(source["at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace history {
+	
+	export class Heatmap {
+	    days: number;
+	    counts: Array<number>[];
+	    byDay: number[];
+	    byHour: number[];
+	    total: number;
+	    max: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ConversionFormat(source);
+	        return new Heatmap(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.extension = source["extension"];
-	        this.qualities = source["qualities"];
-	        this.description = source["description"];
+	        this.days = source["days"];
+	        this.counts = source["counts"];
+	        this.byDay = source["byDay"];
+	        this.byHour = source["byHour"];
+	        this.total = source["total"];
+	        this.max = source["max"];
 	    }
 	}
-	export class ConversionResult {
-	    sourcePath: string;
-	    outputPath: string;
-	    success: boolean;
-	    error?: string;
-	    outputSize?: number;
-	    sourceSize?: number;
+
+	export class Snapshot {
+	    title?: string;
+	    creator?: string;
+	    description?: string;
+	    coverUrl?: string;
+	    cover?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ConversionResult(source);
+	        return new Snapshot(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.sourcePath = source["sourcePath"];
-	        this.outputPath = source["outputPath"];
-	        this.success = source["success"];
-	        this.error = source["error"];
-	        this.outputSize = source["outputSize"];
-	        this.sourceSize = source["sourceSize"];
+	        this.title = source["title"];
+	        this.creator = source["creator"];
+	        this.description = source["description"];
+	        this.coverUrl = source["coverUrl"];
+	        this.cover = source["cover"];
 	    }
 	}
-	export class DownloadRecord {
-	    id: number;
-	    tidalContentId: string;
-	    tidalContentName: string;
-	    contentType: string;
-	    // Go type: time
-	    lastDownloadAt: any;
-	    tracksTotal: number;
-	    tracksDownloaded: number;
-	    tracksFailed: number;
+}
+
+export namespace incomplete {
+	
+	export class File {
+	    path: string;
+	    root: string;
+	    kind: string;
+	    size: number;
 	    // Go type: time
-	    createdAt: any;
+	    modTime: any;
+	    trackId?: number;
+	    track?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new DownloadRecord(source);
+	        return new File(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.tidalContentId = source["tidalContentId"];
-	        this.tidalContentName = source["tidalContentName"];
-	        this.contentType = source["contentType"];
-	        this.lastDownloadAt = this.convertValues(source["lastDownloadAt"], null);
-	        this.tracksTotal = source["tracksTotal"];
-	        this.tracksDownloaded = source["tracksDownloaded"];
-	        this.tracksFailed = source["tracksFailed"];
-	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.path = source["path"];
+	        this.root = source["root"];
+	        this.kind = source["kind"];
+	        this.size = source["size"];
+	        this.modTime = this.convertValues(source["modTime"], null);
+	        this.trackId = source["trackId"];
+	        this.track = source["track"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -297,50 +2307,48 @@ export namespace core {
 		    return a;
 		}
 	}
-	export class DownloadResult {
-	    trackId: number;
-	    title: string;
-	    artist: string;
-	    album: string;
-	    filePath: string;
-	    fileSize: number;
-	    quality: string;
-	    requestedQuality?: string;
-	    qualityMismatch?: boolean;
-	    coverUrl: string;
-	    success: boolean;
-	    error?: string;
-	    analysis?: AnalysisResult;
-	    source?: string;
-	    attempts?: string[];
-	    bytesDownloaded?: number;
-	    bytesTotal?: number;
-	    speed?: number;
+
+}
+
+export namespace jobs {
+	
+	export class Job {
+	    id: string;
+	    kind: string;
+	    state: string;
+	    total: number;
+	    processed: number;
+	    failed: number;
+	    atomic?: boolean;
+	    undoable?: boolean;
+	    summary?: Record<string, number>;
+	    items?: Item[];
+	    // Go type: time
+	    queued: any;
+	    // Go type: time
+	    started: any;
+	    // Go type: time
+	    finished: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new DownloadResult(source);
+	        return new Job(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.trackId = source["trackId"];
-	        this.title = source["title"];
-	        this.artist = source["artist"];
-	        this.album = source["album"];
-	        this.filePath = source["filePath"];
-	        this.fileSize = source["fileSize"];
-	        this.quality = source["quality"];
-	        this.requestedQuality = source["requestedQuality"];
-	        this.qualityMismatch = source["qualityMismatch"];
-	        this.coverUrl = source["coverUrl"];
-	        this.success = source["success"];
-	        this.error = source["error"];
-	        this.analysis = this.convertValues(source["analysis"], AnalysisResult);
-	        this.source = source["source"];
-	        this.attempts = source["attempts"];
-	        this.bytesDownloaded = source["bytesDownloaded"];
-	        this.bytesTotal = source["bytesTotal"];
-	        this.speed = source["speed"];
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.state = source["state"];
+	        this.total = source["total"];
+	        this.processed = source["processed"];
+	        this.failed = source["failed"];
+	        this.atomic = source["atomic"];
+	        this.undoable = source["undoable"];
+	        this.summary = source["summary"];
+	        this.items = this.convertValues(source["items"], Item);
+	        this.queued = this.convertValues(source["queued"], null);
+	        this.started = this.convertValues(source["started"], null);
+	        this.finished = this.convertValues(source["finished"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -361,201 +2369,101 @@ export namespace core {
 		    return a;
 		}
 	}
-	export class DownloadedFileInfo {
+	export class Item {
 	    path: string;
-	    name: string;
-	    size: number;
-	    modTime: string;
-	    title: string;
-	    artist: string;
-	    album: string;
-	    source: string;
-	    quality: string;
-	    format: string;
-	    discNumber: number;
-	    trackNumber: number;
+	    done: boolean;
+	    result?: any;
+	    output?: string;
+	    error?: string;
+	    detail?: fileerr.Error;
+	    undone?: boolean;
+	    undoError?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new DownloadedFileInfo(source);
+	        return new Item(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.path = source["path"];
-	        this.name = source["name"];
-	        this.size = source["size"];
-	        this.modTime = source["modTime"];
-	        this.title = source["title"];
-	        this.artist = source["artist"];
-	        this.album = source["album"];
-	        this.source = source["source"];
-	        this.quality = source["quality"];
-	        this.format = source["format"];
-	        this.discNumber = source["discNumber"];
-	        this.trackNumber = source["trackNumber"];
+	        this.done = source["done"];
+	        this.result = source["result"];
+	        this.output = source["output"];
+	        this.error = source["error"];
+	        this.detail = source["detail"];
+	        this.undone = source["undone"];
+	        this.undoError = source["undoError"];
 	    }
 	}
-	export class EndpointStat {
-	    url: string;
-	    state: string;
-	    fails: number;
-	    revivals: number;
-	    latencyMs?: number;
-	    cooldownSecs?: number;
-	
-	    static createFrom(source: any = {}) {
-	        return new EndpointStat(source);
-	    }
+
+}
+
+export namespace library {
 	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.url = source["url"];
-	        this.state = source["state"];
-	        this.fails = source["fails"];
-	        this.revivals = source["revivals"];
-	        this.latencyMs = source["latencyMs"];
-	        this.cooldownSecs = source["cooldownSecs"];
-	    }
-	}
-	export class FLACMetadata {
-	    path: string;
+	export class Album {
 	    title: string;
 	    artist: string;
-	    album: string;
-	    trackNumber: string;
-	    date: string;
-	    genre: string;
-	    isrc: string;
-	    albumArtist?: string;
-	    discNumber?: string;
-	    copyright?: string;
+	    year?: string;
+	    genre?: string;
 	    label?: string;
-	    composer?: string;
-	    comment: string;
-	    size: number;
+	    catalogNumber?: string;
+	    tracks: number;
 	    duration: number;
-	    sampleRate: number;
-	    bitDepth: number;
-	    channels: number;
-	    bitrate: number;
-	    hasCover: boolean;
-	    coverMime?: string;
-	    coverSize?: number;
-	    totalSamples: number;
-	    lyrics?: string;
-	    syncedLyrics?: string;
-	    hasLyrics: boolean;
-	    upc?: string;
-	    popularity?: number;
+	    size: number;
+	    path: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new FLACMetadata(source);
+	        return new Album(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.path = source["path"];
 	        this.title = source["title"];
 	        this.artist = source["artist"];
-	        this.album = source["album"];
-	        this.trackNumber = source["trackNumber"];
-	        this.date = source["date"];
+	        this.year = source["year"];
 	        this.genre = source["genre"];
-	        this.isrc = source["isrc"];
-	        this.albumArtist = source["albumArtist"];
-	        this.discNumber = source["discNumber"];
-	        this.copyright = source["copyright"];
 	        this.label = source["label"];
-	        this.composer = source["composer"];
-	        this.comment = source["comment"];
-	        this.size = source["size"];
+	        this.catalogNumber = source["catalogNumber"];
+	        this.tracks = source["tracks"];
 	        this.duration = source["duration"];
-	        this.sampleRate = source["sampleRate"];
-	        this.bitDepth = source["bitDepth"];
-	        this.channels = source["channels"];
-	        this.bitrate = source["bitrate"];
-	        this.hasCover = source["hasCover"];
-	        this.coverMime = source["coverMime"];
-	        this.coverSize = source["coverSize"];
-	        this.totalSamples = source["totalSamples"];
-	        this.lyrics = source["lyrics"];
-	        this.syncedLyrics = source["syncedLyrics"];
-	        this.hasLyrics = source["hasLyrics"];
-	        this.upc = source["upc"];
-	        this.popularity = source["popularity"];
-	    }
-	}
-	export class LogEntry {
-	    timestamp: string;
-	    level: string;
-	    message: string;
-	
-	    static createFrom(source: any = {}) {
-	        return new LogEntry(source);
-	    }
-	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.timestamp = source["timestamp"];
-	        this.level = source["level"];
-	        this.message = source["message"];
+	        this.size = source["size"];
+	        this.path = source["path"];
 	    }
 	}
-	export class Lyrics {
-	    plain: string;
-	    synced: string;
-	    source: string;
-	    hasSynced: boolean;
-	    instrumental: boolean;
-	    trackName: string;
-	    artistName: string;
-	    albumName: string;
-	    duration: number;
-	
-	    static createFrom(source: any = {}) {
-	        return new Lyrics(source);
-	    }
-	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.plain = source["plain"];
-	        this.synced = source["synced"];
-	        this.source = source["source"];
-	        this.hasSynced = source["hasSynced"];
-	        this.instrumental = source["instrumental"];
-	        this.trackName = source["trackName"];
-	        this.artistName = source["artistName"];
-	        this.albumName = source["albumName"];
-	        this.duration = source["duration"];
+	export class Artist {
+	    name: string;
+	    albums: number;
+	    tracks: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Artist(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.albums = source["albums"];
+	        this.tracks = source["tracks"];
 	    }
 	}
-	export class MatchFailure {
-	    id: number;
-	    tidalTrackId: string;
-	    isrc: string;
-	    title: string;
-	    artist: string;
-	    album: string;
-	    reason: string;
-	    attempts: number;
-	    // Go type: time
-	    lastAttemptAt: any;
+	export class DuplicateGroup {
+	    isrc?: string;
+	    md5?: string;
+	    identical: boolean;
+	    tracks: Track[];
+	    wasted: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new MatchFailure(source);
+	        return new DuplicateGroup(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.tidalTrackId = source["tidalTrackId"];
 	        this.isrc = source["isrc"];
-	        this.title = source["title"];
-	        this.artist = source["artist"];
-	        this.album = source["album"];
-	        this.reason = source["reason"];
-	        this.attempts = source["attempts"];
-	        this.lastAttemptAt = this.convertValues(source["lastAttemptAt"], null);
+	        this.md5 = source["md5"];
+	        this.identical = source["identical"];
+	        this.tracks = this.convertValues(source["tracks"], Track);
+	        this.wasted = source["wasted"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -576,106 +2484,22 @@ export namespace core {
 		    return a;
 		}
 	}
-	export class SpotifyTrack {
-	    id: string;
-	    name: string;
-	    artists: string;
-	    album: string;
-	    durationMs: number;
-	    uri: string;
-	    isrc?: string;
-	    preview_url?: string;
-	    popularity?: number;
-	
-	    static createFrom(source: any = {}) {
-	        return new SpotifyTrack(source);
-	    }
-	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.artists = source["artists"];
-	        this.album = source["album"];
-	        this.durationMs = source["durationMs"];
-	        this.uri = source["uri"];
-	        this.isrc = source["isrc"];
-	        this.preview_url = source["preview_url"];
-	        this.popularity = source["popularity"];
-	    }
-	}
-	export class TidalTrack {
-	    id: number;
-	    title: string;
-	    artist: string;
-	    artists: string;
-	    albumArtist?: string;
-	    album: string;
-	    albumId: number;
-	    isrc: string;
-	    duration: number;
-	    trackNumber: number;
-	    discNumber?: number;
-	    totalDiscs?: number;
-	    releaseDate?: string;
-	    coverUrl: string;
-	    explicit: boolean;
-	    tidalUrl: string;
-	    available: boolean;
-	    previewUrl?: string;
-	    copyright?: string;
-	    label?: string;
-	    popularity?: number;
-	
-	    static createFrom(source: any = {}) {
-	        return new TidalTrack(source);
-	    }
-	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.title = source["title"];
-	        this.artist = source["artist"];
-	        this.artists = source["artists"];
-	        this.albumArtist = source["albumArtist"];
-	        this.album = source["album"];
-	        this.albumId = source["albumId"];
-	        this.isrc = source["isrc"];
-	        this.duration = source["duration"];
-	        this.trackNumber = source["trackNumber"];
-	        this.discNumber = source["discNumber"];
-	        this.totalDiscs = source["totalDiscs"];
-	        this.releaseDate = source["releaseDate"];
-	        this.coverUrl = source["coverUrl"];
-	        this.explicit = source["explicit"];
-	        this.tidalUrl = source["tidalUrl"];
-	        this.available = source["available"];
-	        this.previewUrl = source["previewUrl"];
-	        this.copyright = source["copyright"];
-	        this.label = source["label"];
-	        this.popularity = source["popularity"];
-	    }
-	}
-	export class MatchResult {
-	    tidalTrack: TidalTrack;
-	    spotifyTrack?: SpotifyTrack;
-	    matched: boolean;
-	    matchMethod: string;
-	    confidence: number;
-	    error?: string;
+	export class Page {
+	    total: number;
+	    limit: number;
+	    offset: number;
+	    tracks: Track[];
 	
 	    static createFrom(source: any = {}) {
-	        return new MatchResult(source);
+	        return new Page(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.tidalTrack = this.convertValues(source["tidalTrack"], TidalTrack);
-	        this.spotifyTrack = this.convertValues(source["spotifyTrack"], SpotifyTrack);
-	        this.matched = source["matched"];
-	        this.matchMethod = source["matchMethod"];
-	        this.confidence = source["confidence"];
-	        this.error = source["error"];
+	        this.total = source["total"];
+	        this.limit = source["limit"];
+	        this.offset = source["offset"];
+	        this.tracks = this.convertValues(source["tracks"], Track);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -696,130 +2520,96 @@ export namespace core {
 		    return a;
 		}
 	}
-	export class RenamePreview {
-	    oldPath: string;
-	    oldName: string;
-	    newName: string;
-	    newPath: string;
-	    hasError: boolean;
-	    error?: string;
+	export class Label {
+	    name: string;
+	    albums: number;
+	    tracks: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new RenamePreview(source);
+	        return new Label(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.oldPath = source["oldPath"];
-	        this.oldName = source["oldName"];
-	        this.newName = source["newName"];
-	        this.newPath = source["newPath"];
-	        this.hasError = source["hasError"];
-	        this.error = source["error"];
+	        this.name = source["name"];
+	        this.albums = source["albums"];
+	        this.tracks = source["tracks"];
 	    }
 	}
-	export class RenameResult {
-	    oldPath: string;
-	    newPath: string;
-	    success: boolean;
-	    error?: string;
+	export class Query {
+	    search?: string;
+	    artist?: string;
+	    album?: string;
+	    genre?: string;
+	    label?: string;
+	    root?: string;
+	    sort?: string;
+	    desc?: boolean;
+	    limit?: number;
+	    offset?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new RenameResult(source);
+	        return new Query(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.oldPath = source["oldPath"];
-	        this.newPath = source["newPath"];
-	        this.success = source["success"];
-	        this.error = source["error"];
+	        this.search = source["search"];
+	        this.artist = source["artist"];
+	        this.album = source["album"];
+	        this.genre = source["genre"];
+	        this.label = source["label"];
+	        this.root = source["root"];
+	        this.sort = source["sort"];
+	        this.desc = source["desc"];
+	        this.limit = source["limit"];
+	        this.offset = source["offset"];
 	    }
 	}
-	export class SourceTrack {
-	    id: string;
-	    title: string;
-	    artist: string;
-	    artists: string[];
-	    album: string;
-	    albumId: string;
-	    isrc: string;
+	export class ScanReport {
+	    roots: string[];
+	    files: number;
+	    added: number;
+	    updated: number;
+	    removed: number;
+	    failed: number;
+	    pending?: number;
+	    errors?: string[];
 	    duration: number;
-	    trackNumber: number;
-	    totalTracks: number;
-	    discNumber: number;
-	    year: string;
-	    genre: string;
-	    coverUrl: string;
-	    explicit: boolean;
-	    sourceUrl: string;
-	    source: string;
-	    quality: string;
-	    previewUrl?: string;
-	    upc?: string;
-	    popularity?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new SourceTrack(source);
+	        return new ScanReport(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.title = source["title"];
-	        this.artist = source["artist"];
-	        this.artists = source["artists"];
-	        this.album = source["album"];
-	        this.albumId = source["albumId"];
-	        this.isrc = source["isrc"];
+	        this.roots = source["roots"];
+	        this.files = source["files"];
+	        this.added = source["added"];
+	        this.updated = source["updated"];
+	        this.removed = source["removed"];
+	        this.failed = source["failed"];
+	        this.pending = source["pending"];
+	        this.errors = source["errors"];
 	        this.duration = source["duration"];
-	        this.trackNumber = source["trackNumber"];
-	        this.totalTracks = source["totalTracks"];
-	        this.discNumber = source["discNumber"];
-	        this.year = source["year"];
-	        this.genre = source["genre"];
-	        this.coverUrl = source["coverUrl"];
-	        this.explicit = source["explicit"];
-	        this.sourceUrl = source["sourceUrl"];
-	        this.source = source["source"];
-	        this.quality = source["quality"];
-	        this.previewUrl = source["previewUrl"];
-	        this.upc = source["upc"];
-	        this.popularity = source["popularity"];
 	    }
 	}
-	export class SourceAlbum {
-	    id: string;
-	    title: string;
-	    artist: string;
-	    artists: string[];
-	    year: string;
-	    genre: string;
-	    coverUrl: string;
-	    trackCount: number;
-	    tracks: SourceTrack[];
-	    source: string;
-	    sourceUrl: string;
-	    description: string;
+	export class Status {
+	    tracks: number;
+	    scanning: boolean;
+	    lastScan?: ScanReport;
+	    scanTime?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new SourceAlbum(source);
+	        return new Status(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.title = source["title"];
-	        this.artist = source["artist"];
-	        this.artists = source["artists"];
-	        this.year = source["year"];
-	        this.genre = source["genre"];
-	        this.coverUrl = source["coverUrl"];
-	        this.trackCount = source["trackCount"];
-	        this.tracks = this.convertValues(source["tracks"], SourceTrack);
-	        this.source = source["source"];
-	        this.sourceUrl = source["sourceUrl"];
-	        this.description = source["description"];
+	        this.tracks = source["tracks"];
+	        this.scanning = source["scanning"];
+	        this.lastScan = this.convertValues(source["lastScan"], ScanReport);
+	        this.scanTime = source["scanTime"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -840,26 +2630,60 @@ export namespace core {
 		    return a;
 		}
 	}
-	export class SourceHealth {
-	    name: string;
-	    displayName: string;
-	    status: string;
-	    latencyMs: number;
-	    reason?: string;
-	    endpoints?: EndpointStat[];
+	export class Track {
+	    path: string;
+	    root: string;
+	    title: string;
+	    artist: string;
+	    albumArtist?: string;
+	    album: string;
+	    genre?: string;
+	    year?: string;
+	    label?: string;
+	    catalogNumber?: string;
+	    trackNumber?: number;
+	    discNumber?: number;
+	    isrc?: string;
+	    md5?: string;
+	    quality?: string;
+	    sampleRate?: number;
+	    bitDepth?: number;
+	    channels?: number;
+	    duration: number;
+	    bitrate?: number;
+	    size: number;
+	    modTime: any;
+	    error?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new SourceHealth(source);
+	        return new Track(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
-	        this.displayName = source["displayName"];
-	        this.status = source["status"];
-	        this.latencyMs = source["latencyMs"];
-	        this.reason = source["reason"];
-	        this.endpoints = this.convertValues(source["endpoints"], EndpointStat);
+	        this.path = source["path"];
+	        this.root = source["root"];
+	        this.title = source["title"];
+	        this.artist = source["artist"];
+	        this.albumArtist = source["albumArtist"];
+	        this.album = source["album"];
+	        this.genre = source["genre"];
+	        this.year = source["year"];
+	        this.label = source["label"];
+	        this.catalogNumber = source["catalogNumber"];
+	        this.trackNumber = source["trackNumber"];
+	        this.discNumber = source["discNumber"];
+	        this.isrc = source["isrc"];
+	        this.md5 = source["md5"];
+	        this.quality = source["quality"];
+	        this.sampleRate = source["sampleRate"];
+	        this.bitDepth = source["bitDepth"];
+	        this.channels = source["channels"];
+	        this.duration = source["duration"];
+	        this.bitrate = source["bitrate"];
+	        this.size = source["size"];
+	        this.modTime = this.convertValues(source["modTime"], null);
+	        this.error = source["error"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -880,100 +2704,265 @@ export namespace core {
 		    return a;
 		}
 	}
-	export class SourceInfo {
-	    name: string;
-	    displayName: string;
-	    available: boolean;
-	    urlPattern: string;
+
+}
+
+export namespace lyricsmatch {
+	
+	export class Match {
+	    track: string;
+	    artist: string;
+	    album?: string;
+	    duration: number;
+	    fileDuration: number;
+	    durationDelta: number;
+	    hasPlain: boolean;
+	    hasSynced: boolean;
+	    instrumental: boolean;
+	    confident: boolean;
+	    reason?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new SourceInfo(source);
+	        return new Match(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
-	        this.displayName = source["displayName"];
-	        this.available = source["available"];
-	        this.urlPattern = source["urlPattern"];
+	        this.track = source["track"];
+	        this.artist = source["artist"];
+	        this.album = source["album"];
+	        this.duration = source["duration"];
+	        this.fileDuration = source["fileDuration"];
+	        this.durationDelta = source["durationDelta"];
+	        this.hasPlain = source["hasPlain"];
+	        this.hasSynced = source["hasSynced"];
+	        this.instrumental = source["instrumental"];
+	        this.confident = source["confident"];
+	        this.reason = source["reason"];
 	    }
 	}
-	export class SourcePlaylist {
-	    id: string;
+
+}
+
+export namespace metacache {
+	
+	export class Info {
+	    sampleRate: number;
+	    bitDepth: number;
+	    tier?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Info(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sampleRate = source["sampleRate"];
+	        this.bitDepth = source["bitDepth"];
+	        this.tier = source["tier"];
+	    }
+	}
+
+}
+
+export namespace musicbrainz {
+	
+	export class Edition {
+	    releaseId: string;
 	    title: string;
-	    description: string;
-	    creator: string;
-	    coverUrl: string;
+	    artist: string;
+	    country?: string;
+	    date?: string;
+	    barcode?: string;
 	    trackCount: number;
-	    tracks: SourceTrack[];
-	    source: string;
-	    sourceUrl: string;
+	    status?: string;
+	    disambiguation?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new SourcePlaylist(source);
+	        return new Edition(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
+	        this.releaseId = source["releaseId"];
 	        this.title = source["title"];
-	        this.description = source["description"];
-	        this.creator = source["creator"];
-	        this.coverUrl = source["coverUrl"];
+	        this.artist = source["artist"];
+	        this.country = source["country"];
+	        this.date = source["date"];
+	        this.barcode = source["barcode"];
 	        this.trackCount = source["trackCount"];
-	        this.tracks = this.convertValues(source["tracks"], SourceTrack);
-	        this.source = source["source"];
-	        this.sourceUrl = source["sourceUrl"];
+	        this.status = source["status"];
+	        this.disambiguation = source["disambiguation"];
 	    }
-	
-		convertValues(a: any, classs: any, asMap: boolean = false): any {
-		    if (!a) {
-		        return a;
-		    }
-		    if (a.slice && a.map) {
-		        return (a as any[]).map(elem => this.convertValues(elem, classs));
-		    } else if ("object" === typeof a) {
-		        if (asMap) {
-		            for (const key of Object.keys(a)) {
-		                a[key] = new classs(a[key]);
-		            }
-		            return a;
-		        }
-		        return new classs(a);
-		    }
-		    return a;
-		}
 	}
+
+}
+
+export namespace naming {
+	
+	export class Token {
+	    name: string;
+	    description: string;
+	    example: string;
+	    numeric: boolean;
 	
+	    static createFrom(source: any = {}) {
+	        return new Token(source);
+	    }
 	
-	export class TidalAlbum {
-	    id: number;
-	    title: string;
-	    artist: string;
-	    releaseDate: string;
-	    trackCount: number;
-	    coverUrl: string;
-	    albumType?: string;
-	    copyright?: string;
-	    label?: string;
-	    tracks: TidalTrack[];
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.example = source["example"];
+	        this.numeric = source["numeric"];
+	    }
+	}
+
+}
+
+export namespace postprocess {
+	
+	export class Overrides {
+	    title?: string;
+	    artist?: string;
+	    album?: string;
+	    trackNumber?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new TidalAlbum(source);
+	        return new Overrides(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
 	        this.title = source["title"];
 	        this.artist = source["artist"];
-	        this.releaseDate = source["releaseDate"];
-	        this.trackCount = source["trackCount"];
-	        this.coverUrl = source["coverUrl"];
-	        this.albumType = source["albumType"];
-	        this.copyright = source["copyright"];
-	        this.label = source["label"];
-	        this.tracks = this.convertValues(source["tracks"], TidalTrack);
+	        this.album = source["album"];
+	        this.trackNumber = source["trackNumber"];
+	    }
+	}
+
+}
+
+export namespace settings {
+	
+	export class Settings {
+	    discSubfolders: boolean;
+	    usePlaylistOrder: boolean;
+	    maxPathLength: number;
+	    filenameUnicode: string;
+	    titleLanguage: string;
+	    tagRules: tagrules.Rules;
+	    genreMap: Record<string, string>;
+	    fileConflict: string;
+	    coverMaxSize: number;
+	    coverQuality: number;
+	    keepFullCover: boolean;
+	    musicBrainzTagging: boolean;
+	    performerTags: boolean;
+	    acoustIdKey: string;
+	    watchClipboard: boolean;
+	    watchFolder: string;
+	    watchLibrary: boolean;
+	    analyzeNewFiles: boolean;
+	    analysisWorkers: number;
+	    conversionWorkers: number;
+	    loudnessTags: boolean;
+	    analyzerThresholds: analysis.Thresholds;
+	    startOnLogin: boolean;
+	    trimSilence: boolean;
+	    silenceThreshold: number;
+	    silenceMinSeconds: number;
+	    incompleteCleanupDays: number;
+	    strictValidation: boolean;
+	    verifyDownloads: boolean;
+	    matchNormalization: string;
+	    eventVerbosity: string;
+	    editionCountries: string[];
+	    lyricsOutput: string;
+	    coverUserAgents: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.discSubfolders = source["discSubfolders"];
+	        this.usePlaylistOrder = source["usePlaylistOrder"];
+	        this.maxPathLength = source["maxPathLength"];
+	        this.filenameUnicode = source["filenameUnicode"];
+	        this.titleLanguage = source["titleLanguage"];
+	        this.tagRules = source["tagRules"];
+	        this.genreMap = source["genreMap"];
+	        this.fileConflict = source["fileConflict"];
+	        this.coverMaxSize = source["coverMaxSize"];
+	        this.coverQuality = source["coverQuality"];
+	        this.keepFullCover = source["keepFullCover"];
+	        this.musicBrainzTagging = source["musicBrainzTagging"];
+	        this.performerTags = source["performerTags"];
+	        this.acoustIdKey = source["acoustIdKey"];
+	        this.watchClipboard = source["watchClipboard"];
+	        this.watchFolder = source["watchFolder"];
+	        this.watchLibrary = source["watchLibrary"];
+	        this.analyzeNewFiles = source["analyzeNewFiles"];
+	        this.analysisWorkers = source["analysisWorkers"];
+	        this.conversionWorkers = source["conversionWorkers"];
+	        this.loudnessTags = source["loudnessTags"];
+	        this.analyzerThresholds = source["analyzerThresholds"];
+	        this.startOnLogin = source["startOnLogin"];
+	        this.trimSilence = source["trimSilence"];
+	        this.silenceThreshold = source["silenceThreshold"];
+	        this.silenceMinSeconds = source["silenceMinSeconds"];
+	        this.incompleteCleanupDays = source["incompleteCleanupDays"];
+	        this.strictValidation = source["strictValidation"];
+	        this.verifyDownloads = source["verifyDownloads"];
+	        this.matchNormalization = source["matchNormalization"];
+	        this.eventVerbosity = source["eventVerbosity"];
+	        this.editionCountries = source["editionCountries"];
+	        this.lyricsOutput = source["lyricsOutput"];
+	        this.coverUserAgents = source["coverUserAgents"];
+	    }
+	}
+
+}
+
+export namespace silence {
+	
+	export class Region {
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Region(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class Report {
+	    path: string;
+	    duration: number;
+	    leading: number;
+	    trailing: number;
+	    regions: Region[];
+	    trimmed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.duration = source["duration"];
+	        this.leading = source["leading"];
+	        this.trailing = source["trailing"];
+	        this.regions = this.convertValues(source["regions"], Region);
+	        this.trimmed = source["trimmed"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -994,22 +2983,29 @@ export namespace core {
 		    return a;
 		}
 	}
-	export class TidalArtist {
-	    id: number;
-	    name: string;
-	    pictureUrl?: string;
-	    albums: TidalAlbum[];
+
+}
+
+export namespace split {
+	
+	export class Piece {
+	    track: Track;
+	    path: string;
+	    startSample: number;
+	    endSample?: number;
+	    error?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new TidalArtist(source);
+	        return new Piece(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.pictureUrl = source["pictureUrl"];
-	        this.albums = this.convertValues(source["albums"], TidalAlbum);
+	        this.track = this.convertValues(source["track"], Track);
+	        this.path = source["path"];
+	        this.startSample = source["startSample"];
+	        this.endSample = source["endSample"];
+	        this.error = source["error"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1030,28 +3026,69 @@ export namespace core {
 		    return a;
 		}
 	}
-	export class TidalPlaylist {
-	    uuid: string;
+	export class Track {
+	    number: number;
 	    title: string;
-	    description: string;
-	    creator: string;
-	    coverUrl: string;
-	    numberOfTracks: number;
-	    tracks: TidalTrack[];
+	    performer?: string;
+	    isrc?: string;
+	    start: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new TidalPlaylist(source);
+	        return new Track(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.uuid = source["uuid"];
+	        this.number = source["number"];
 	        this.title = source["title"];
-	        this.description = source["description"];
-	        this.creator = source["creator"];
-	        this.coverUrl = source["coverUrl"];
-	        this.numberOfTracks = source["numberOfTracks"];
-	        this.tracks = this.convertValues(source["tracks"], TidalTrack);
+	        this.performer = source["performer"];
+	        this.isrc = source["isrc"];
+	        this.start = source["start"];
+	    }
+	}
+
+}
+
+export namespace tagedit {
+	
+	export class Change {
+	    field: string;
+	    old?: string[];
+	    new?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Change(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+	export class Result {
+	    path: string;
+	    changes: Change[];
+	    pictures?: number;
+	    id3?: boolean;
+	    written: boolean;
+	    error?: string;
+	    detail?: fileerr.Error;
+	
+	    static createFrom(source: any = {}) {
+	        return new Result(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.changes = this.convertValues(source["changes"], Change);
+	        this.pictures = source["pictures"];
+	        this.id3 = source["id3"];
+	        this.written = source["written"];
+	        this.error = source["error"];
+	        this.detail = source["detail"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1072,6 +3109,65 @@ export namespace core {
 		    return a;
 		}
 	}
+	export class StripOptions {
+	    fields: string[];
+	    all: boolean;
+	    covers: boolean;
+	    id3: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new StripOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fields = source["fields"];
+	        this.all = source["all"];
+	        this.covers = source["covers"];
+	        this.id3 = source["id3"];
+	    }
+	}
+
+}
+
+export namespace tagrules {
+	
+	export class Rules {
+	    titleCase: boolean;
+	    featuring: boolean;
+	    stripRemaster: boolean;
+	    stripExplicit: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Rules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.titleCase = source["titleCase"];
+	        this.featuring = source["featuring"];
+	        this.stripRemaster = source["stripRemaster"];
+	        this.stripExplicit = source["stripExplicit"];
+	    }
+	}
 
 }
 
+export namespace timestamp {
+	
+	export class LocaleHint {
+	    locale: string;
+	    timeZone: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LocaleHint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.locale = source["locale"];
+	        this.timeZone = source["timeZone"];
+	    }
+	}
+
+}
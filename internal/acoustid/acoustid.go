@@ -0,0 +1,307 @@
+// Package acoustid identifies tracks by their sound rather than their tags:
+// Chromaprint's fpcalc fingerprints the audio and AcoustID (acoustid.org)
+// maps the fingerprint to MusicBrainz recordings. It is meant for files
+// whose tags are missing or garbage, which internal/musicbrainz can't look
+// up.
+package acoustid
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the AcoustID web service a Client uses when its BaseURL
+// is empty.
+const DefaultBaseURL = "https://api.acoustid.org/v2"
+
+// MinInterval is the least time between two lookups of a Client: AcoustID
+// allows three requests per second.
+const MinInterval = time.Second / 3
+
+// MinScore is the fingerprint match score (0–1) a result needs.
+const MinScore = 0.8
+
+// DefaultClient is the HTTP client a Client uses when its HTTP is nil.
+var DefaultClient = &http.Client{Timeout: 20 * time.Second}
+
+// Default is the client shared by the desktop app and the server, so their
+// lookups share one rate limit.
+var Default = &Client{}
+
+var (
+	// ErrNoMatch is returned for recordings AcoustID doesn't know.
+	ErrNoMatch = errors.New("no AcoustID match")
+
+	// ErrNoKey is returned when no AcoustID API key is configured.
+	ErrNoKey = errors.New("AcoustID API key not set (register an application at acoustid.org)")
+
+	// ErrFpcalcMissing is returned when fpcalc can't be found.
+	ErrFpcalcMissing = errors.New("fpcalc (Chromaprint) not available")
+)
+
+// Runner runs an external command and returns its standard output; tests
+// replace it.
+type Runner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// ExecRunner runs the command with os/exec, adding its standard error to
+// the error when it fails.
+func ExecRunner(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg := strings.TrimSpace(string(exitErr.Stderr)); msg != "" {
+			return out, fmt.Errorf("%w: %s", err, msg)
+		}
+	}
+	return out, err
+}
+
+// Client fingerprints files and looks them up on AcoustID. Its zero value
+// is ready to use; lookups are spaced at least Interval apart.
+type Client struct {
+	BaseURL  string        // DefaultBaseURL when empty
+	HTTP     *http.Client  // DefaultClient when nil
+	Interval time.Duration // MinInterval when 0
+	Fpcalc   string        // found on the PATH when empty
+	Run      Runner        // ExecRunner when nil
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Info describes whether fingerprinting is available.
+type Info struct {
+	Available bool   `json:"available"`
+	Path      string `json:"path,omitempty"`
+	Version   string `json:"version,omitempty"`
+	KeySet    bool   `json:"keySet"` // an API key is configured
+}
+
+// Fingerprint is fpcalc's fingerprint of a file.
+type Fingerprint struct {
+	Duration    float64 `json:"duration"` // seconds
+	Fingerprint string  `json:"fingerprint"`
+}
+
+// Match is the recording a fingerprint was identified as.
+type Match struct {
+	ID          string   `json:"id"` // the AcoustID track ID
+	Score       float64  `json:"score"`
+	RecordingID string   `json:"recordingId"`
+	ArtistIDs   []string `json:"artistIds"`
+	Title       string   `json:"title"`
+	Artist      string   `json:"artist"`
+	Album       string   `json:"album,omitempty"`
+}
+
+// FpcalcPath returns the fpcalc c runs, or ErrFpcalcMissing.
+func (c *Client) FpcalcPath() (string, error) {
+	if c.Fpcalc != "" {
+		return c.Fpcalc, nil
+	}
+	path, err := exec.LookPath("fpcalc")
+	if err != nil {
+		return "", ErrFpcalcMissing
+	}
+	return path, nil
+}
+
+// Info reports whether fpcalc is available, and its version.
+func (c *Client) Info(ctx context.Context) Info {
+	path, err := c.FpcalcPath()
+	if err != nil {
+		return Info{}
+	}
+	info := Info{Available: true, Path: path}
+	if out, err := c.run(ctx, path, "-version"); err == nil {
+		// "fpcalc version 1.5.1"
+		info.Version = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(out)), "fpcalc version"))
+	}
+	return info
+}
+
+// Ready reports why c can't identify files with key, or nil.
+func (c *Client) Ready(key string) error {
+	if strings.TrimSpace(key) == "" {
+		return ErrNoKey
+	}
+	_, err := c.FpcalcPath()
+	return err
+}
+
+// Fingerprint runs fpcalc on the file at path.
+func (c *Client) Fingerprint(ctx context.Context, path string) (Fingerprint, error) {
+	fpcalc, err := c.FpcalcPath()
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	out, err := c.run(ctx, fpcalc, "-json", path)
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("fpcalc: %w", err)
+	}
+	var fp Fingerprint
+	if err := json.Unmarshal(out, &fp); err != nil {
+		return Fingerprint{}, fmt.Errorf("fpcalc: %w", err)
+	}
+	if fp.Fingerprint == "" {
+		return Fingerprint{}, errors.New("fpcalc: empty fingerprint")
+	}
+	return fp, nil
+}
+
+func (c *Client) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if c.Run != nil {
+		return c.Run(ctx, name, args...)
+	}
+	return ExecRunner(ctx, name, args...)
+}
+
+// lookupResponse, result, recording and releaseGroup are the parts of AcoustID's JSON
+// used here.
+type lookupResponse struct {
+	Status string `json:"status"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Results []result `json:"results"`
+}
+
+type result struct {
+	ID         string      `json:"id"`
+	Score      float64     `json:"score"`
+	Recordings []recording `json:"recordings"`
+}
+
+type recording struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Artists []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		JoinPhrase string `json:"joinphrase"`
+	} `json:"artists"`
+	ReleaseGroups []releaseGroup `json:"releasegroups"`
+}
+
+type releaseGroup struct {
+	Title string `json:"title"`
+	Type  string `json:"type"` // "Album", "Single", ...
+}
+
+// Lookup identifies fp with the application API key key. The best scoring
+// result of at least MinScore that links to a titled recording wins;
+// albums are preferred over singles and compilations for Album.
+func (c *Client) Lookup(ctx context.Context, key string, fp Fingerprint) (Match, error) {
+	if strings.TrimSpace(key) == "" {
+		return Match{}, ErrNoKey
+	}
+	form := url.Values{
+		"client":      {key},
+		"format":      {"json"},
+		"meta":        {"recordings releasegroups compress"},
+		"duration":    {strconv.Itoa(int(math.Round(fp.Duration)))},
+		"fingerprint": {fp.Fingerprint},
+	}
+	var resp lookupResponse
+	if err := c.post(ctx, "/lookup", form, &resp); err != nil {
+		return Match{}, err
+	}
+	if resp.Status != "ok" {
+		if resp.Error != nil {
+			return Match{}, fmt.Errorf("acoustid: %s", resp.Error.Message)
+		}
+		return Match{}, fmt.Errorf("acoustid: status %q", resp.Status)
+	}
+
+	slices.SortStableFunc(resp.Results, func(a, b result) int { return cmp.Compare(b.Score, a.Score) })
+	for _, r := range resp.Results {
+		if r.Score < MinScore {
+			break
+		}
+		for _, rec := range r.Recordings {
+			if rec.Title == "" {
+				continue
+			}
+			m := Match{ID: r.ID, Score: r.Score, RecordingID: rec.ID, Title: rec.Title}
+			for i, a := range rec.Artists {
+				m.Artist += a.Name
+				if a.JoinPhrase != "" {
+					m.Artist += a.JoinPhrase
+				} else if i < len(rec.Artists)-1 {
+					m.Artist += " & "
+				}
+				if a.ID != "" {
+					m.ArtistIDs = append(m.ArtistIDs, a.ID)
+				}
+			}
+			if i := slices.IndexFunc(rec.ReleaseGroups, func(rg releaseGroup) bool { return rg.Type == "Album" }); i >= 0 {
+				m.Album = rec.ReleaseGroups[i].Title
+			} else if len(rec.ReleaseGroups) > 0 {
+				m.Album = rec.ReleaseGroups[0].Title
+			}
+			return m, nil
+		}
+	}
+	return Match{}, ErrNoMatch
+}
+
+// post sends form to path and decodes the JSON answer into v, after
+// waiting out the rate limit. AcoustID reports errors in the body, with a
+// 4xx or 5xx status, so those bodies are decoded too.
+func (c *Client) post(ctx context.Context, path string, form url.Values, v any) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	u := strings.TrimRight(cmp.Or(c.BaseURL, DefaultBaseURL), "/") + path
+	req, err := http.NewRequestWithContext(ctx, "POST", u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	client := c.HTTP
+	if client == nil {
+		client = DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("acoustid: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("acoustid: HTTP %d", resp.StatusCode)
+		}
+		return fmt.Errorf("acoustid: %w", err)
+	}
+	return nil
+}
+
+// wait blocks until c.Interval has passed since c's last request.
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d := time.Until(c.last.Add(cmp.Or(c.Interval, MinInterval))); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	c.last = time.Now()
+	return nil
+}
@@ -0,0 +1,153 @@
+package acoustid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/flacmeta/flactest"
+	"flacidal/internal/musicbrainz"
+)
+
+const lookupJSON = `{"status": "ok", "results": [
+	{"id": "weak", "score": 0.5, "recordings": [{"id": "rec-weak", "title": "Other"}]},
+	{"id": "aid-1", "score": 0.96, "recordings": [
+		{"id": "rec-untitled"},
+		{"id": "rec-1", "title": "Song",
+			"artists": [{"id": "art-a", "name": "A", "joinphrase": " feat. "}, {"id": "art-b", "name": "B"}],
+			"releasegroups": [{"title": "Song (Single)", "type": "Single"}, {"title": "Album", "type": "Album"}]}
+	]}
+]}`
+
+// fakeAcoustID answers the fingerprint "KNOWN" for the key "key", and
+// fakes fpcalc.
+func fakeAcoustID(t *testing.T) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/lookup" {
+			http.NotFound(w, r)
+			return
+		}
+		switch {
+		case r.FormValue("client") != "key":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status": "error", "error": {"code": 4, "message": "invalid API key"}}`))
+		case r.FormValue("fingerprint") != "KNOWN" || r.FormValue("duration") != "181":
+			w.Write([]byte(`{"status": "ok", "results": []}`))
+		default:
+			w.Write([]byte(lookupJSON))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &Client{BaseURL: srv.URL, Interval: time.Millisecond, Fpcalc: "fpcalc", Run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		if slices.Equal(args, []string{"-version"}) {
+			return []byte("fpcalc version 1.5.1\n"), nil
+		}
+		if filepath.Base(args[len(args)-1]) == "unknown.flac" {
+			return []byte(`{"duration": 181.2, "fingerprint": "UNKNOWN"}`), nil
+		}
+		return []byte(`{"duration": 180.7, "fingerprint": "KNOWN"}`), nil
+	}}
+}
+
+func writeFLAC(t *testing.T, name string, tags map[string]string) string {
+	t.Helper()
+	var fields []flacmeta.Field
+	for k, v := range tags {
+		fields = append(fields, flacmeta.Field{Name: k, Value: v})
+	}
+	path := filepath.Join(t.TempDir(), name)
+	flactest.Write(t, path, flactest.CD, flactest.Tags(fields...))
+	return path
+}
+
+func TestLookup(t *testing.T) {
+	c := fakeAcoustID(t)
+	m, err := c.Lookup(context.Background(), "key", Fingerprint{Duration: 180.7, Fingerprint: "KNOWN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Match{ID: "aid-1", Score: 0.96, RecordingID: "rec-1", ArtistIDs: []string{"art-a", "art-b"}, Title: "Song", Artist: "A feat. B", Album: "Album"}
+	if m.ID != want.ID || m.RecordingID != want.RecordingID || !slices.Equal(m.ArtistIDs, want.ArtistIDs) ||
+		m.Title != want.Title || m.Artist != want.Artist || m.Album != want.Album {
+		t.Errorf("Lookup = %+v, want %+v", m, want)
+	}
+
+	if _, err := c.Lookup(context.Background(), "key", Fingerprint{Duration: 181, Fingerprint: "UNKNOWN"}); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("unknown fingerprint: err = %v, want ErrNoMatch", err)
+	}
+	if _, err := c.Lookup(context.Background(), "", Fingerprint{}); !errors.Is(err, ErrNoKey) {
+		t.Errorf("no key: err = %v, want ErrNoKey", err)
+	}
+	if _, err := c.Lookup(context.Background(), "bad", Fingerprint{Fingerprint: "KNOWN"}); err == nil || err.Error() != "acoustid: invalid API key" {
+		t.Errorf("bad key: err = %v", err)
+	}
+}
+
+func TestInfoAndReady(t *testing.T) {
+	c := fakeAcoustID(t)
+	if info := c.Info(context.Background()); !info.Available || info.Version != "1.5.1" {
+		t.Errorf("Info = %+v", info)
+	}
+	if err := c.Ready(" "); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Ready without a key = %v", err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	missing := &Client{}
+	if info := missing.Info(context.Background()); info.Available {
+		t.Errorf("Info without fpcalc = %+v", info)
+	}
+	if err := missing.Ready("key"); !errors.Is(err, ErrFpcalcMissing) {
+		t.Errorf("Ready without fpcalc = %v", err)
+	}
+}
+
+func TestGarbage(t *testing.T) {
+	for _, v := range []string{"", "  ", "Unknown Artist", "track 03", "AudioTrack 01", "Untitled", "07", "01 - Song.flac", "Bj�rk", "???"} {
+		if !Garbage(v) {
+			t.Errorf("Garbage(%q) = false", v)
+		}
+	}
+	for _, v := range []string{"Björk", "Track Star", "1999", "Song", "Unknown Pleasures"} {
+		if Garbage(v) {
+			t.Errorf("Garbage(%q) = true", v)
+		}
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	c := fakeAcoustID(t)
+	path := writeFLAC(t, "track01.flac", map[string]string{"TITLE": "Track 01", "ARTIST": "Real Artist"})
+
+	preview, err := c.Identify(context.Background(), "key", path, true)
+	if err != nil || preview.Written || len(preview.Changes) != 4 { // IDs, TITLE, ALBUM
+		t.Fatalf("preview = %+v, %v", preview, err)
+	}
+	result, err := c.Identify(context.Background(), "key", path, false)
+	if err != nil || !result.Written {
+		t.Fatalf("Identify = %+v, %v", result, err)
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, _ := f.Comments()
+	if tags.Get(TagAcoustID) != "aid-1" || tags.Get(musicbrainz.TagTrackID) != "rec-1" {
+		t.Errorf("IDs = %v", tags.Fields)
+	}
+	if tags.Get("TITLE") != "Song" || tags.Get("ALBUM") != "Album" || tags.Get("ARTIST") != "Real Artist" {
+		t.Errorf("TITLE/ALBUM/ARTIST = %q/%q/%q; want the real artist kept", tags.Get("TITLE"), tags.Get("ALBUM"), tags.Get("ARTIST"))
+	}
+
+	unknown := writeFLAC(t, "unknown.flac", nil)
+	if r, err := c.Identify(context.Background(), "key", unknown, true); !errors.Is(err, ErrNoMatch) || r.Error == "" {
+		t.Errorf("unknown recording: %+v, %v", r, err)
+	}
+}
@@ -0,0 +1,108 @@
+package acoustid
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/musicbrainz"
+	"flacidal/internal/tagedit"
+)
+
+// TagAcoustID is the AcoustID track ID tag, named as Picard writes it.
+const TagAcoustID = "ACOUSTID_ID"
+
+// placeholder matches the stand-ins rippers and players write instead of
+// real tags: "Unknown Artist", "Track 01", "Untitled", bare numbers.
+var placeholder = regexp.MustCompile(`(?i)^(unknown( artist| album| title)?|untitled|no title|(audio ?)?track ?\d*|artist|album|title|\d{1,2}|[-_?.\s]+)$`)
+
+// Garbage reports whether a tag value is missing or junk: empty, a
+// placeholder, a file name, or mangled by a wrong character set.
+func Garbage(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || placeholder.MatchString(value) {
+		return true
+	}
+	if !utf8.ValidString(value) || strings.ContainsRune(value, utf8.RuneError) {
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(value)) {
+	case ".flac", ".wav", ".mp3", ".m4a":
+		return true
+	}
+	return false
+}
+
+// Changes lists what tagging a file tagged c with m changes: the AcoustID
+// and MusicBrainz recording IDs are set, and TITLE, ARTIST and ALBUM are
+// replaced only when they are garbage (see Garbage), so identifying a
+// well-tagged file never overwrites its tags.
+func (m Match) Changes(c *flacmeta.Comments) []tagedit.Change {
+	var changes []tagedit.Change
+	set := func(field string, values ...string) {
+		values = slices.DeleteFunc(values, func(v string) bool { return v == "" })
+		if old := c.GetAll(field); len(values) > 0 && !slices.Equal(old, values) {
+			changes = append(changes, tagedit.Change{Field: field, Old: old, New: values})
+		}
+	}
+	fix := func(field, value string) {
+		if Garbage(c.Get(field)) {
+			set(field, value)
+		}
+	}
+	set(TagAcoustID, m.ID)
+	set(musicbrainz.TagTrackID, m.RecordingID)
+	fix("TITLE", m.Title)
+	fix("ARTIST", m.Artist)
+	fix("ALBUM", m.Album)
+	if Garbage(c.Get("ARTIST")) {
+		set(musicbrainz.TagArtistID, m.ArtistIDs...)
+	}
+	return changes
+}
+
+// Identify fingerprints the FLAC at path, looks it up with the API key key
+// and tags it with the match (see Match.Changes), or with dryRun only works
+// out the changes. The error, ErrNoMatch when AcoustID doesn't know the
+// recording, is returned as well as recorded in the result.
+func (c *Client) Identify(ctx context.Context, key, path string, dryRun bool) (tagedit.Result, error) {
+	result := tagedit.Result{Path: path, Changes: []tagedit.Change{}}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		result.Fail(err)
+		return result, err
+	}
+	comments, err := f.Comments()
+	if err != nil {
+		result.Fail(err)
+		return result, err
+	}
+	fp, err := c.Fingerprint(ctx, path)
+	if err != nil {
+		result.Fail(err)
+		return result, err
+	}
+	m, err := c.Lookup(ctx, key, fp)
+	if err != nil {
+		result.Fail(err)
+		return result, err
+	}
+	if changes := m.Changes(comments); changes != nil {
+		result.Changes = changes
+	}
+	if dryRun || len(result.Changes) == 0 {
+		return result, nil
+	}
+	tagedit.Apply(comments, result.Changes)
+	f.SetComments(comments)
+	if err := f.Save(); err != nil {
+		result.Fail(err)
+		return result, err
+	}
+	result.Written = true
+	return result, nil
+}
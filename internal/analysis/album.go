@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AlbumReport is the outcome of CheckAlbum.
+type AlbumReport struct {
+	Folder string    `json:"folder"`
+	Tracks []Version `json:"tracks"`
+
+	// Format and Verdict are what most tracks are, e.g. "96 kHz/24-bit"
+	// (by effective bit depth) and "lossless".
+	Format  string `json:"format"`
+	Verdict string `json:"verdict"`
+
+	// Issues are the tracks that stand out from the rest; an album
+	// without any is Consistent.
+	Consistent bool         `json:"consistent"`
+	Issues     []AlbumIssue `json:"issues"`
+}
+
+// AlbumIssue is one way a track stands out from the rest of its album.
+type AlbumIssue struct {
+	Path    string `json:"path"`
+	Problem string `json:"problem"` // "44.1 kHz/16-bit while the rest are 96 kHz/24-bit"
+}
+
+// CheckAlbum compares the tracks of the album in folder with each other
+// and flags those that stand out: a different format than most, a worse
+// spectral verdict, MQA among plain FLACs, truncation or a failed
+// analysis. Single bad tracks are easy to miss going through an album's
+// analyses one by one.
+func CheckAlbum(folder string, tracks []Version) AlbumReport {
+	r := AlbumReport{Folder: folder, Tracks: tracks, Issues: []AlbumIssue{}}
+	var analyzed []Version
+	for _, t := range tracks {
+		if t.Verdict == "error" {
+			r.flag(t, "could not be analyzed")
+			continue
+		}
+		analyzed = append(analyzed, t)
+	}
+	r.Format = majority(analyzed, format, func(a, b Version) int {
+		return cmp.Or(cmp.Compare(effectiveBits(a), effectiveBits(b)), cmp.Compare(a.SampleRate, b.SampleRate))
+	})
+	r.Verdict = majority(analyzed, func(t Version) string { return t.Verdict }, func(a, b Version) int {
+		return cmp.Compare(verdictRank(a.Verdict), verdictRank(b.Verdict))
+	})
+	mqa := 0
+	for _, t := range analyzed {
+		if t.MQA {
+			mqa++
+		}
+	}
+	for _, t := range analyzed {
+		if t.Truncated {
+			r.flag(t, "truncated")
+		}
+		if f := format(t); f != r.Format {
+			r.flag(t, fmt.Sprintf("%s while the rest are %s", f, r.Format))
+		}
+		if verdictRank(t.Verdict) < verdictRank(r.Verdict) {
+			r.flag(t, fmt.Sprintf("%s while the rest are %s", verdictName(t.Verdict), verdictName(r.Verdict)))
+		}
+		if t.MQA && mqa < len(analyzed) {
+			r.flag(t, "MQA while the rest aren't")
+		}
+	}
+	r.Consistent = len(r.Issues) == 0
+	return r
+}
+
+// flag notes a problem with track t.
+func (r *AlbumReport) flag(t Version, problem string) {
+	r.Issues = append(r.Issues, AlbumIssue{Path: t.Path, Problem: problem})
+}
+
+// format is t's audio format as CheckAlbum compares it.
+func format(t Version) string {
+	return fmt.Sprintf("%g kHz/%d-bit", float64(t.SampleRate)/1000, effectiveBits(t))
+}
+
+// verdictName is a spectral verdict as Issues word it.
+func verdictName(verdict string) string {
+	return strings.ReplaceAll(verdict, "_", " ")
+}
+
+// majority is the key most tracks share; a tie goes to the best track's,
+// by better.
+func majority(tracks []Version, key func(Version) string, better func(a, b Version) int) string {
+	if len(tracks) == 0 {
+		return ""
+	}
+	counts := map[string]int{}
+	for _, t := range tracks {
+		counts[key(t)]++
+	}
+	return key(slices.MaxFunc(tracks, func(a, b Version) int {
+		return cmp.Or(cmp.Compare(counts[key(a)], counts[key(b)]), better(a, b))
+	}))
+}
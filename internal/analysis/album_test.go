@@ -0,0 +1,47 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckAlbum(t *testing.T) {
+	track := func(path string, rate, bits int, verdict string) Version {
+		return Version{Path: path, SampleRate: rate, BitDepth: bits, EffectiveBitDepth: bits, Verdict: verdict}
+	}
+	clean := []Version{
+		track("1.flac", 96000, 24, "lossless"),
+		track("2.flac", 96000, 24, "lossless"),
+		track("3.flac", 96000, 24, "lossless"),
+	}
+	if r := CheckAlbum("album", clean); !r.Consistent || r.Format != "96 kHz/24-bit" || r.Verdict != "lossless" {
+		t.Errorf("clean album = %+v", r)
+	}
+
+	mixed := append(clean[:2:2],
+		track("3.flac", 44100, 16, "lossless"),
+		track("4.flac", 96000, 24, "upscaled"),
+		Version{Path: "5.flac", Verdict: "error"},
+	)
+	padded := track("6.flac", 96000, 24, "lossless")
+	padded.EffectiveBitDepth, padded.MQA = 16, true
+	mixed = append(mixed, padded)
+
+	r := CheckAlbum("album", mixed)
+	want := []AlbumIssue{
+		{"5.flac", "could not be analyzed"},
+		{"3.flac", "44.1 kHz/16-bit while the rest are 96 kHz/24-bit"},
+		{"4.flac", "upscaled while the rest are lossless"},
+		{"6.flac", "96 kHz/16-bit while the rest are 96 kHz/24-bit"},
+		{"6.flac", "MQA while the rest aren't"},
+	}
+	if r.Consistent || !reflect.DeepEqual(r.Issues, want) {
+		t.Errorf("issues = %+v, want %+v", r.Issues, want)
+	}
+
+	// A tie goes to the better format, flagging the worse
+	r = CheckAlbum("album", []Version{track("1.flac", 44100, 16, "lossless"), track("2.flac", 96000, 24, "lossless")})
+	if r.Format != "96 kHz/24-bit" || len(r.Issues) != 1 || r.Issues[0].Path != "1.flac" {
+		t.Errorf("tie = %+v", r)
+	}
+}
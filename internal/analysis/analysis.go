@@ -0,0 +1,241 @@
+// Package analysis measures FLAC audio from its decoded samples, adding to
+// flacidal-core's spectral verdict what the spectrum can't show: how many
+// of the declared bits carry audio, how loud the audio is, whether it
+// clips, whether it was cut short, whether it is MQA-encoded, whether its
+// stereo is real and which lossy codec an upscaled file likely came from.
+// ffmpeg decodes the file to 32-bit little-endian PCM, whatever its bit
+// depth, and Measure reads that stream, so the measurements themselves
+// need no ffmpeg and tests feed them samples directly.
+package analysis
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os/exec"
+	"strings"
+
+	"flacidal/internal/flacmeta"
+)
+
+// Result is what Measure found.
+type Result struct {
+	// EffectiveBitDepth is how many bits of each sample carry audio: the
+	// declared bit depth less the low bits that are zero in every sample.
+	// 0 when the audio is all digital silence.
+	EffectiveBitDepth int `json:"effectiveBitDepth"`
+
+	// PaddedBitDepth is set when EffectiveBitDepth is below the declared
+	// bit depth, as with 16-bit audio padded to 24 bits and sold as
+	// hi-res.
+	PaddedBitDepth bool   `json:"paddedBitDepth"`
+	BitDepthLabel  string `json:"bitDepthLabel,omitempty"` // "16-bit audio padded to 24-bit"; empty unless padded
+
+	// Loudness is nil when the audio is too short or too quiet to measure.
+	Loudness *Loudness `json:"loudness,omitempty"`
+
+	// ClippedSamples counts the samples in runs of at least three at
+	// digital full scale, the flat tops of a clipped waveform, and
+	// ClippedPercent is their share of all samples.
+	ClippedSamples int64   `json:"clippedSamples"`
+	ClippedPercent float64 `json:"clippedPercent"`
+
+	// InterSamplePeaks counts the samples between which the reconstructed
+	// signal goes above 0 dBFS, clipping in players and lossy encoders.
+	InterSamplePeaks int64 `json:"interSamplePeaks"`
+
+	// Clipping is set when either is frequent enough that the track was
+	// likely damaged by its mastering or by the chain it went through.
+	Clipping      bool   `json:"clipping"`
+	ClippingLabel string `json:"clippingLabel,omitempty"` // "0.05% of samples clipped"; empty unless Clipping
+
+	// TrailingSilence is how many seconds of digital silence the audio
+	// ends in. Truncated is set when the decode is meaningfully shorter
+	// than STREAMINFO records (MissingSeconds short) or the silence is
+	// long, as with a download cut off or zero-filled.
+	TrailingSilence float64 `json:"trailingSilence"`
+	Truncated       bool    `json:"truncated"`
+	MissingSeconds  float64 `json:"missingSeconds,omitempty"`
+	TruncatedLabel  string  `json:"truncatedLabel,omitempty"` // "12.3 s shorter than its header records"; empty unless Truncated
+
+	// MQA is set when the audio carries MQA's sync word or the file MQA's
+	// tags (see MQATags): lossy-folded audio sold as hi-res, worth
+	// replacing with a true lossless copy.
+	MQA                   bool   `json:"mqa"`
+	MQALabel              string `json:"mqaLabel,omitempty"`              // "MQA Studio, 352.8 kHz original"; empty unless MQA
+	MQAOriginalSampleRate int    `json:"mqaOriginalSampleRate,omitempty"` // from the ORIGINALSAMPLERATE tag
+
+	// Correlation is how alike the two channels of stereo audio are, from
+	// 1 (the same signal) through 0 (unrelated) to -1 (one the other
+	// inverted); 0 for other channel counts and silence. FakeStereo is
+	// set for mono audio padded to stereo, which takes twice the space
+	// for nothing, and OutOfPhase for channels working against each
+	// other, which cancel out when played in mono.
+	Correlation float64 `json:"correlation"`
+	FakeStereo  bool    `json:"fakeStereo"`
+	OutOfPhase  bool    `json:"outOfPhase"`
+	StereoLabel string  `json:"stereoLabel,omitempty"` // "mono: both channels are identical"; empty unless either is set
+
+	// LossyCodec is the lossy codec, "MP3", "AAC" or "Vorbis", whose
+	// lowpass the spectrum shows: MP3 by the holes it leaves above 16 kHz,
+	// AAC by a sharp cutoff, Vorbis by a gradual one. A best guess, only
+	// meaningful for files judged upscaled; empty when the spectrum has
+	// no lowpass shelf.
+	LossyCodec      string `json:"lossyCodec,omitempty"`
+	LossyCodecLabel string `json:"lossyCodecLabel,omitempty"` // "MP3, ~128 kbps (16.0 kHz lowpass with holes in 35% of the audio)"
+}
+
+// meter is one measurement taken over the decoded samples.
+type meter interface {
+	// add takes the next frames, channels samples each, interleaved and
+	// left-aligned in 32 bits.
+	add(samples []int32)
+	// finish writes the measurement to r.
+	finish(r *Result)
+}
+
+// chunkFrames is how many frames Measure hands the meters at a time.
+const chunkFrames = 4096
+
+// Measure reads the decoded audio of a file with the format si from r,
+// 32-bit little-endian samples with si.Channels interleaved.
+func Measure(r io.Reader, si flacmeta.StreamInfo) (*Result, error) {
+	if si.Channels <= 0 || si.BitDepth <= 0 {
+		return nil, fmt.Errorf("unknown audio format")
+	}
+	peaks := newPeakMeter(si.Channels, si.BitDepth)
+	meters := []meter{&bitDepthMeter{declared: si.BitDepth}, peaks}
+	if si.Channels == 2 {
+		meters = append(meters, &stereoMeter{identical: true})
+	}
+	if si.Channels == 2 && si.BitDepth >= 16 {
+		meters = append(meters, &mqaMeter{channels: si.Channels})
+	}
+	if si.SampleRate > 0 {
+		meters = append(meters, &truncationMeter{channels: si.Channels, sampleRate: si.SampleRate, declared: si.Samples})
+	}
+	if si.SampleRate >= 2*maxShelfHz {
+		meters = append(meters, newSpectrumMeter(si.SampleRate, si.Channels))
+	}
+	if si.SampleRate >= 10 {
+		meters = append(meters, newLoudnessMeter(si.SampleRate, si.Channels, peaks))
+	}
+	if err := feed(r, si.Channels, meters...); err != nil {
+		return nil, err
+	}
+	res := &Result{}
+	for _, m := range meters {
+		m.finish(res)
+	}
+	return res, nil
+}
+
+// feed reads 32-bit little-endian samples with channels interleaved from
+// r and hands them to meters, chunkFrames frames at a time.
+func feed(r io.Reader, channels int, meters ...meter) error {
+	buf := make([]byte, chunkFrames*channels*4)
+	samples := make([]int32, chunkFrames*channels)
+	br := bufio.NewReaderSize(r, len(buf))
+	for {
+		n, err := io.ReadFull(br, buf)
+		n -= n % (channels * 4) // a partial frame at the end is dropped
+		if n > 0 {
+			s := samples[:n/4]
+			for i := range s {
+				s[i] = int32(binary.LittleEndian.Uint32(buf[i*4:]))
+			}
+			for _, m := range meters {
+				m.add(s)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// DecodeArgs returns the ffmpeg arguments that write the first audio
+// stream of path to stdout as 32-bit little-endian PCM.
+func DecodeArgs(path string) []string {
+	return []string{"-hide_banner", "-nostdin", "-v", "error", "-i", path, "-map", "0:a:0", "-f", "s32le", "-acodec", "pcm_s32le", "-"}
+}
+
+// Analyze decodes the FLAC file at path with the ffmpeg binary at ffmpeg
+// and measures it.
+func Analyze(ctx context.Context, ffmpeg, path string) (*Result, error) {
+	si, err := flacmeta.ReadStreamInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	var res *Result
+	_, err = decode(ctx, ffmpeg, DecodeArgs(path), func(out io.Reader) (err error) {
+		res, err = Measure(out, si)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// decode runs ffmpeg with args and hands its output to read, returning
+// what ffmpeg wrote to stderr. ffmpeg exiting unsuccessfully is an
+// *exec.ExitError carrying that message.
+func decode(ctx context.Context, ffmpeg string, args []string, read func(io.Reader) error) (string, error) {
+	cmd := exec.CommandContext(ctx, ffmpeg, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	err = read(out)
+	if err != nil {
+		cmd.Process.Kill() //nolint:errcheck // reported by Wait, which follows
+	}
+	// Wait first: stderr is copied on its own goroutine until then
+	werr := cmd.Wait()
+	msg := strings.TrimSpace(stderr.String())
+	if werr != nil {
+		if msg != "" {
+			werr = fmt.Errorf("%w: %s", werr, msg)
+		}
+		return msg, errors.Join(err, werr)
+	}
+	return msg, err
+}
+
+// bitDepthMeter finds the low bits that are zero in every sample.
+type bitDepthMeter struct {
+	declared int
+	used     uint32 // every sample ORed together
+}
+
+func (m *bitDepthMeter) add(samples []int32) {
+	for _, s := range samples {
+		m.used |= uint32(s)
+	}
+}
+
+func (m *bitDepthMeter) finish(r *Result) {
+	if m.used == 0 {
+		return
+	}
+	// Negative samples set the high bits, so only the trailing zeros tell
+	effective := min(32-bits.TrailingZeros32(m.used), m.declared)
+	r.EffectiveBitDepth = effective
+	if effective < m.declared {
+		r.PaddedBitDepth = true
+		r.BitDepthLabel = fmt.Sprintf("%d-bit audio padded to %d-bit", effective, m.declared)
+	}
+}
@@ -0,0 +1,331 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+)
+
+// pcm encodes samples, given at bitDepth, as Measure reads them: 32-bit
+// little-endian, left-aligned.
+func pcm(bitDepth int, samples ...int32) *bytes.Buffer {
+	var buf bytes.Buffer
+	for _, s := range samples {
+		binary.Write(&buf, binary.LittleEndian, s<<(32-bitDepth))
+	}
+	return &buf
+}
+
+// sine returns n samples of a full-scale sine at bitDepth, interleaved
+// across channels.
+func sine(bitDepth, channels, n int) []int32 {
+	peak := float64(int32(1)<<(bitDepth-1) - 1)
+	samples := make([]int32, 0, n*channels)
+	for i := range n {
+		v := int32(math.Round(peak * math.Sin(float64(i)/7)))
+		for range channels {
+			samples = append(samples, v)
+		}
+	}
+	return samples
+}
+
+func TestMeasureBitDepth(t *testing.T) {
+	si := flacmeta.StreamInfo{SampleRate: 96000, Channels: 2, BitDepth: 24}
+
+	res, err := Measure(pcm(24, sine(24, 2, 10000)...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.EffectiveBitDepth != 24 || res.PaddedBitDepth {
+		t.Errorf("true 24-bit: %+v", res)
+	}
+
+	// 16-bit audio padded to 24 bits: the low 8 bits are always zero
+	padded := sine(16, 2, 10000)
+	for i := range padded {
+		padded[i] <<= 8
+	}
+	res, err = Measure(pcm(24, padded...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.EffectiveBitDepth != 16 || !res.PaddedBitDepth || res.BitDepthLabel != "16-bit audio padded to 24-bit" {
+		t.Errorf("padded 24-bit: %+v", res)
+	}
+
+	res, err = Measure(pcm(24, make([]int32, 100)...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.EffectiveBitDepth != 0 || res.PaddedBitDepth {
+		t.Errorf("silence: %+v", res)
+	}
+}
+
+func TestMeasureDropsPartialFrame(t *testing.T) {
+	si := flacmeta.StreamInfo{SampleRate: 44100, Channels: 2, BitDepth: 16}
+	data := append(pcm(16, 1, 1).Bytes(), 0xff, 0xff) // half a sample more
+	res, err := Measure(bytes.NewReader(data), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.EffectiveBitDepth != 16 {
+		t.Errorf("%+v", res)
+	}
+}
+
+// tone returns seconds of a sine at freq Hz and dBFS level, the same in
+// every channel, at 24 bits.
+func tone(sampleRate, channels int, seconds, freq, level, phase float64) []int32 {
+	amp := math.Pow(10, level/20) * (1<<23 - 1)
+	n := int(seconds * float64(sampleRate))
+	samples := make([]int32, 0, n*channels)
+	for i := range n {
+		v := int32(math.Round(amp * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)+phase)))
+		for range channels {
+			samples = append(samples, v)
+		}
+	}
+	return samples
+}
+
+func TestMeasureLoudness(t *testing.T) {
+	si := flacmeta.StreamInfo{SampleRate: 48000, Channels: 2, BitDepth: 24}
+
+	// A 1 kHz sine at -20 dBFS in both channels reads -20 LUFS
+	res, err := Measure(pcm(24, tone(48000, 2, 5, 1000, -20, 0)...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := res.Loudness
+	if l == nil || math.Abs(l.Integrated+20) > 0.1 || l.Range > 0.1 || math.Abs(l.TruePeak+20) > 0.1 {
+		t.Errorf("-20 dBFS sine: %+v", l)
+	}
+	tags := (&Loudness{Integrated: -20, TruePeak: -20}).Tags()
+	if tags["REPLAYGAIN_TRACK_GAIN"] != "2.00 dB" || tags["REPLAYGAIN_TRACK_PEAK"] != "0.100000" {
+		t.Errorf("tags = %v", tags)
+	}
+
+	// EBU Tech 3342 case 1: 20 s at -20 dBFS then 20 s at -30 dBFS has a
+	// loudness range of 10 LU
+	si.Channels = 1
+	steps := append(tone(48000, 1, 20, 1000, -20, 0), tone(48000, 1, 20, 1000, -30, 0)...)
+	res, err = Measure(pcm(24, steps...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := res.Loudness; l == nil || math.Abs(l.Range-10) > 1 {
+		t.Errorf("-20/-30 dBFS steps: %+v", l)
+	}
+
+	res, err = Measure(pcm(24, make([]int32, 48000)...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Loudness != nil {
+		t.Errorf("silence: %+v", res.Loudness)
+	}
+}
+
+func TestMeasureTruePeak(t *testing.T) {
+	// A sine at a quarter of the sample rate, sampled 45° off its
+	// crests, peaks 3 dB above its samples.
+	si := flacmeta.StreamInfo{SampleRate: 44100, Channels: 1, BitDepth: 24}
+	res, err := Measure(pcm(24, tone(44100, 1, 1, 44100/4, -1, math.Pi/4)...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := res.Loudness; l == nil || math.Abs(l.TruePeak+1) > 0.3 {
+		t.Errorf("true peak: %+v", l)
+	}
+}
+
+func TestMeasureClipping(t *testing.T) {
+	si := flacmeta.StreamInfo{SampleRate: 44100, Channels: 2, BitDepth: 16}
+
+	res, err := Measure(pcm(16, sine(16, 2, 44100)...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ClippedSamples != 0 || res.Clipping {
+		t.Errorf("clean sine: %+v", res)
+	}
+
+	// The same sine driven 6 dB into the rails
+	clipped := sine(16, 2, 44100)
+	for i, s := range clipped {
+		clipped[i] = max(min(2*s, math.MaxInt16), math.MinInt16)
+	}
+	res, err = Measure(pcm(16, clipped...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ClippedPercent < 50 || res.InterSamplePeaks == 0 || !res.Clipping || res.ClippingLabel == "" {
+		t.Errorf("clipped sine: %+v", res)
+	}
+}
+
+func TestMeasureMQA(t *testing.T) {
+	si := flacmeta.StreamInfo{SampleRate: 96000, Channels: 2, BitDepth: 24}
+
+	res, err := Measure(pcm(24, sine(24, 2, 4800)...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MQA {
+		t.Errorf("plain sine: %+v", res)
+	}
+
+	// The sync word in the 16th bit of the right channel, after some audio
+	samples := sine(24, 2, 4800)
+	for i := range 36 {
+		bit := int32(uint64(mqaSync) >> (35 - i) & 1)
+		f := 2 * (1000 + i)
+		samples[f+1] = samples[f] ^ bit<<8
+	}
+	res, err = Measure(pcm(24, samples...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.MQA || res.MQALabel != "MQA" {
+		t.Errorf("MQA sine: %+v", res)
+	}
+}
+
+func TestMQATags(t *testing.T) {
+	var r Result
+	MQATags(&r, &flacmeta.Comments{Fields: []flacmeta.Field{{Name: "ORIGINALSAMPLERATE", Value: "352800"}}})
+	if r.MQA {
+		t.Errorf("sample rate alone: %+v", r)
+	}
+	MQATags(&r, &flacmeta.Comments{Fields: []flacmeta.Field{
+		{Name: "MQAENCODER", Value: "MQAEncode v1.1"},
+		{Name: "MQASTUDIO", Value: "1"},
+		{Name: "ORIGINALSAMPLERATE", Value: "352800"},
+	}})
+	if !r.MQA || r.MQAOriginalSampleRate != 352800 || r.MQALabel != "MQA Studio, 352.8 kHz original" {
+		t.Errorf("tagged: %+v", r)
+	}
+}
+
+func TestMeasureTruncation(t *testing.T) {
+	si := flacmeta.StreamInfo{SampleRate: 1000, Channels: 2, BitDepth: 16, Samples: 3000}
+
+	whole := sine(16, 2, 3000)
+	res, err := Measure(pcm(16, whole...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Truncated || res.TrailingSilence != 0 {
+		t.Errorf("whole track: %+v", res)
+	}
+
+	res, err = Measure(pcm(16, whole[:2*2000]...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Truncated || res.MissingSeconds != 1 || res.TruncatedLabel != "1.0 s shorter than its header records" {
+		t.Errorf("short decode: %+v", res)
+	}
+
+	// A second of audio and 12 of zero fill
+	si.Samples = 13000
+	filled := append(sine(16, 2, 1000), make([]int32, 2*12000)...)
+	res, err = Measure(pcm(16, filled...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Truncated || res.MissingSeconds != 0 || math.Abs(res.TrailingSilence-12) > 0.01 {
+		t.Errorf("zero-filled: %+v", res)
+	}
+}
+
+// band returns seconds of noise made of sines every 100 Hz from 100 Hz up
+// to cutoff Hz, at 44.1 kHz in 2 channels and 24 bits. Sines above
+// holeFrom Hz are silent in every other chunk when holeFrom > 0, as MP3
+// drops the top of the spectrum.
+func band(seconds float64, cutoff, holeFrom int) []int32 {
+	const rate = 44100
+	var freqs []float64
+	for f := 100; f <= cutoff; f += 100 {
+		freqs = append(freqs, float64(f))
+	}
+	amp := float64(1<<23-1) / float64(len(freqs)) / 2
+	n := int(seconds * rate)
+	samples := make([]int32, 0, 2*n)
+	for i := range n {
+		hole := holeFrom > 0 && (i/chunkFrames/spectrumStride)%2 == 1
+		var v float64
+		for k, f := range freqs {
+			if hole && f > float64(holeFrom) {
+				break
+			}
+			v += math.Sin(2*math.Pi*f*float64(i)/rate + float64(k*k))
+		}
+		s := int32(math.Round(amp*v)) << 8
+		samples = append(samples, s, s)
+	}
+	return samples
+}
+
+func TestMeasureLossyCodec(t *testing.T) {
+	si := flacmeta.StreamInfo{SampleRate: 44100, Channels: 2, BitDepth: 24}
+	for _, tt := range []struct {
+		name       string
+		samples    []int32
+		codec      string
+		labelStart string
+	}{
+		{"full band", band(2, 21900, 0), "", ""},
+		{"sharp 16 kHz", band(2, 16000, 0), "AAC", "AAC, ~128 kbps (sharp 16.0 kHz lowpass"},
+		{"holes above 14 kHz", band(2, 16000, 14000), "MP3", "MP3, ~128 kbps (16.0 kHz lowpass with holes"},
+	} {
+		res, err := Measure(pcm(32, tt.samples...), si)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.LossyCodec != tt.codec || !strings.HasPrefix(res.LossyCodecLabel, tt.labelStart) {
+			t.Errorf("%s: %q, %q", tt.name, res.LossyCodec, res.LossyCodecLabel)
+		}
+	}
+}
+
+func TestMeasureStereo(t *testing.T) {
+	si := flacmeta.StreamInfo{SampleRate: 44100, Channels: 2, BitDepth: 24}
+	left := tone(44100, 1, 1, 440, -10, 0)
+	right := tone(44100, 1, 1, 660, -10, 0)
+	stereo := func(r func(i int) int32) []int32 {
+		samples := make([]int32, 0, 2*len(left))
+		for i, l := range left {
+			samples = append(samples, l, r(i))
+		}
+		return samples
+	}
+	for _, tt := range []struct {
+		name              string
+		samples           []int32
+		fake, outOfPhase  bool
+		correlationAround float64
+	}{
+		{"real stereo", stereo(func(i int) int32 { return right[i] }), false, false, 0},
+		{"identical channels", stereo(func(i int) int32 { return left[i] }), true, false, 1},
+		{"mono with dither", stereo(func(i int) int32 { return left[i] + int32(i%3) - 1 }), true, false, 1},
+		{"inverted channel", stereo(func(i int) int32 { return -left[i] }), false, true, -1},
+	} {
+		res, err := Measure(pcm(24, tt.samples...), si)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.FakeStereo != tt.fake || res.OutOfPhase != tt.outOfPhase || math.Abs(res.Correlation-tt.correlationAround) > 0.01 {
+			t.Errorf("%s: %+v", tt.name, res)
+		}
+		if (tt.fake || tt.outOfPhase) != (res.StereoLabel != "") {
+			t.Errorf("%s: label %q", tt.name, res.StereoLabel)
+		}
+	}
+}
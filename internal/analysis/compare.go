@@ -0,0 +1,167 @@
+package analysis
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// Version is what Compare weighs of one copy of a track, from its
+// analysis.
+type Version struct {
+	Path              string    `json:"path"`
+	SampleRate        int       `json:"sampleRate"`
+	BitDepth          int       `json:"bitDepth"`
+	EffectiveBitDepth int       `json:"effectiveBitDepth"` // 0 when not measured
+	Cutoff            int       `json:"cutoff"`            // spectral cutoff, Hz
+	Verdict           string    `json:"verdict"`           // "lossless", "upsampled", "likely_upscaled", "upscaled"…
+	VerdictLabel      string    `json:"verdictLabel"`
+	MQA               bool      `json:"mqa"`
+	Truncated         bool      `json:"truncated"`
+	Clipping          bool      `json:"clipping"`
+	ClippedPercent    float64   `json:"clippedPercent"`
+	Loudness          *Loudness `json:"loudness,omitempty"`
+	AudioMD5          string    `json:"audioMd5,omitempty"` // of the decoded audio; empty when unknown
+}
+
+// Comparison is the outcome of Compare.
+type Comparison struct {
+	A Version `json:"a"`
+	B Version `json:"b"`
+
+	// SameAudio is set when both decode to identical audio, so only their
+	// tags can differ.
+	SameAudio bool `json:"sameAudio"`
+
+	// Keep is the better copy, "a" or "b", or "" when neither is, and
+	// Reason says why.
+	Keep   string `json:"keep"`
+	Reason string `json:"reason"`
+
+	// Differences lists the criteria the copies differ in, most important
+	// first, e.g. "sample rate: 96 kHz vs 44.1 kHz".
+	Differences []string `json:"differences"`
+}
+
+// criterion is one way a copy can be better than another.
+type criterion struct {
+	name    string
+	compare func(a, b Version) int // > 0 when a is better
+	show    func(v Version) string
+}
+
+// criteria are what Compare weighs, most important first: damage and
+// lossy history outweigh resolution, which outweighs the mastering.
+var criteria = []criterion{
+	{"completeness", func(a, b Version) int { return cmp.Compare(rank(!a.Truncated), rank(!b.Truncated)) },
+		func(v Version) string { return pick(v.Truncated, "truncated", "complete") }},
+	{"spectral verdict", func(a, b Version) int { return cmp.Compare(verdictRank(a.Verdict), verdictRank(b.Verdict)) },
+		func(v Version) string { return cmp.Or(v.VerdictLabel, v.Verdict) }},
+	{"MQA", func(a, b Version) int { return cmp.Compare(rank(!a.MQA), rank(!b.MQA)) },
+		func(v Version) string { return pick(v.MQA, "MQA", "not MQA") }},
+	{"bit depth", func(a, b Version) int { return cmp.Compare(effectiveBits(a), effectiveBits(b)) },
+		func(v Version) string { return fmt.Sprintf("%d-bit", effectiveBits(v)) }},
+	{"sample rate", func(a, b Version) int { return cmp.Compare(a.SampleRate, b.SampleRate) },
+		func(v Version) string { return fmt.Sprintf("%g kHz", float64(v.SampleRate)/1000) }},
+	{"spectral cutoff", func(a, b Version) int { return beyond(a.Cutoff-b.Cutoff, 1000) },
+		func(v Version) string { return fmt.Sprintf("%d Hz", v.Cutoff) }},
+	{"clipping", func(a, b Version) int { return cmp.Compare(rank(!a.Clipping), rank(!b.Clipping)) },
+		func(v Version) string {
+			return pick(v.Clipping, fmt.Sprintf("%.3f%% clipped", v.ClippedPercent), "no clipping")
+		}},
+	{"loudness range", func(a, b Version) int { return beyond(rangeOf(a)-rangeOf(b), 1) },
+		func(v Version) string {
+			if v.Loudness == nil {
+				return "unmeasured"
+			}
+			return fmt.Sprintf("%.1f LU", v.Loudness.Range)
+		}},
+}
+
+// Compare weighs two copies of the same track, such as downloads of it
+// from two services, and tells which is the better keep: the first
+// criterion they differ in decides.
+func Compare(a, b Version) Comparison {
+	c := Comparison{A: a, B: b, Differences: []string{}}
+	c.SameAudio = a.AudioMD5 != "" && a.AudioMD5 == b.AudioMD5
+	for _, cr := range criteria {
+		d := cr.compare(a, b)
+		if d == 0 {
+			continue
+		}
+		sa, sb := cr.show(a), cr.show(b)
+		c.Differences = append(c.Differences, fmt.Sprintf("%s: %s vs %s", cr.name, sa, sb))
+		switch {
+		case c.Keep != "":
+		case d > 0:
+			c.Keep, c.Reason = "a", fmt.Sprintf("better %s (%s vs %s)", cr.name, sa, sb)
+		default:
+			c.Keep, c.Reason = "b", fmt.Sprintf("better %s (%s vs %s)", cr.name, sb, sa)
+		}
+	}
+	switch {
+	case c.SameAudio && c.Keep == "":
+		c.Reason = "identical audio; only the tags can differ"
+	case c.Keep == "":
+		c.Reason = "no meaningful difference"
+	}
+	return c
+}
+
+// rank orders a criterion met above one that isn't.
+func rank(good bool) int {
+	if good {
+		return 1
+	}
+	return 0
+}
+
+// pick is yes if cond, else no.
+func pick(cond bool, yes, no string) string {
+	if cond {
+		return yes
+	}
+	return no
+}
+
+// verdictRank orders spectral verdicts from worst to best.
+func verdictRank(verdict string) int {
+	switch verdict {
+	case "lossless":
+		return 4
+	case "upsampled":
+		return 3
+	case "likely_upscaled":
+		return 2
+	case "upscaled":
+		return 1
+	}
+	return 0
+}
+
+// effectiveBits is the bits of v's samples that carry audio, as far as
+// measured.
+func effectiveBits(v Version) int {
+	if v.EffectiveBitDepth > 0 {
+		return v.EffectiveBitDepth
+	}
+	return v.BitDepth
+}
+
+// rangeOf is v's loudness range, 0 when unmeasured.
+func rangeOf(v Version) float64 {
+	if v.Loudness == nil {
+		return 0
+	}
+	return v.Loudness.Range
+}
+
+// beyond is the sign of d, or 0 when it is within ±tolerance.
+func beyond[T int | float64](d, tolerance T) int {
+	switch {
+	case d >= tolerance:
+		return 1
+	case d <= -tolerance:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+package analysis
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	cd := Version{Path: "a.flac", SampleRate: 44100, BitDepth: 16, EffectiveBitDepth: 16, Cutoff: 22000, Verdict: "lossless", AudioMD5: "x", Loudness: &Loudness{Range: 8}}
+	hires := Version{Path: "b.flac", SampleRate: 96000, BitDepth: 24, EffectiveBitDepth: 24, Cutoff: 22000, Verdict: "lossless", AudioMD5: "y", Loudness: &Loudness{Range: 8.3}}
+
+	c := Compare(cd, hires)
+	if c.Keep != "b" || c.Reason != "better bit depth (24-bit vs 16-bit)" || len(c.Differences) != 2 || c.SameAudio {
+		t.Errorf("CD vs hi-res = %+v", c)
+	}
+
+	// A padded, MQA hi-res copy loses to the plain CD one
+	mqa := hires
+	mqa.EffectiveBitDepth, mqa.MQA = 16, true
+	if c := Compare(cd, mqa); c.Keep != "a" || c.Differences[0] != "MQA: not MQA vs MQA" {
+		t.Errorf("CD vs MQA = %+v", c)
+	}
+
+	// Damage outweighs everything
+	truncated := hires
+	truncated.Truncated = true
+	if c := Compare(truncated, cd); c.Keep != "b" || c.Reason != "better completeness (complete vs truncated)" {
+		t.Errorf("truncated vs CD = %+v", c)
+	}
+
+	same := cd
+	same.Path = "c.flac"
+	if c := Compare(cd, same); c.Keep != "" || !c.SameAudio || len(c.Differences) != 0 {
+		t.Errorf("same audio = %+v", c)
+	}
+}
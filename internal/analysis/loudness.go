@@ -0,0 +1,249 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Loudness is a file's loudness per EBU R128: ITU-R BS.1770-4 integrated
+// loudness and true peak, and the EBU Tech 3342 loudness range.
+type Loudness struct {
+	Integrated float64 `json:"integrated"` // LUFS
+	Range      float64 `json:"range"`      // LU
+	TruePeak   float64 `json:"truePeak"`   // dBTP
+}
+
+// ReplayGainReference is the loudness ReplayGain 2.0 adjusts tracks to.
+const ReplayGainReference = -18.0 // LUFS
+
+// Tags returns l as the ReplayGain tags players read: the gain that
+// brings the track to ReplayGainReference and its true peak as a linear
+// amplitude.
+func (l *Loudness) Tags() map[string]string {
+	return map[string]string{
+		"REPLAYGAIN_TRACK_GAIN": fmt.Sprintf("%.2f dB", ReplayGainReference-l.Integrated),
+		"REPLAYGAIN_TRACK_PEAK": fmt.Sprintf("%.6f", math.Pow(10, l.TruePeak/20)),
+	}
+}
+
+const (
+	absoluteGate   = -70.0 // LUFS; blocks below are silence
+	relativeGate   = -10.0 // LU below the absolute-gated loudness (BS.1770)
+	rangeGate      = -20.0 // LU below it, for the loudness range (Tech 3342)
+	stepsPerBlock  = 4     // 400 ms momentary blocks…
+	stepsPerWindow = 30    // …and 3 s short-term windows, of 100 ms steps
+)
+
+// loudnessMeter measures Loudness. It keeps the K-weighted mean square
+// of each 100 ms step, from which the overlapping gating blocks and
+// short-term windows are built at the end.
+type loudnessMeter struct {
+	channels int
+	weights  []float64  // per channel; 0 for LFE
+	filters  []kWeight  // per channel
+	peaks    *peakMeter // measures the true peak alongside
+	stepLen  int        // frames per step
+	frames   int        // frames in the current step
+	sum      float64    // weighted square sum of the current step
+	steps    []float64  // mean square of each finished step
+}
+
+func newLoudnessMeter(sampleRate, channels int, peaks *peakMeter) *loudnessMeter {
+	m := &loudnessMeter{
+		channels: channels,
+		weights:  channelWeights(channels),
+		filters:  make([]kWeight, channels),
+		peaks:    peaks,
+		stepLen:  sampleRate / 10,
+	}
+	for c := range m.filters {
+		m.filters[c] = newKWeight(float64(sampleRate))
+	}
+	return m
+}
+
+// channelWeights returns the BS.1770 channel weights for ffmpeg's default
+// layout of channels: surround channels count 1.41, LFE not at all.
+func channelWeights(channels int) []float64 {
+	w := make([]float64, channels)
+	for i := range w {
+		w[i] = 1
+	}
+	switch channels {
+	case 5: // FL FR FC BL BR
+		w[3], w[4] = 1.41, 1.41
+	case 6: // FL FR FC LFE BL BR
+		w[3], w[4], w[5] = 0, 1.41, 1.41
+	}
+	return w
+}
+
+func (m *loudnessMeter) add(samples []int32) {
+	for i := 0; i+m.channels <= len(samples); i += m.channels {
+		for c := range m.channels {
+			if m.weights[c] != 0 {
+				x := float64(samples[i+c]) / (1 << 31)
+				y := m.filters[c].process(x)
+				m.sum += m.weights[c] * y * y
+			}
+		}
+		if m.frames++; m.frames == m.stepLen {
+			m.steps = append(m.steps, m.sum/float64(m.stepLen))
+			m.frames, m.sum = 0, 0
+		}
+	}
+}
+
+func (m *loudnessMeter) finish(r *Result) {
+	blocks := windows(m.steps, stepsPerBlock)
+	integrated, ok := gatedLoudness(blocks, relativeGate)
+	if !ok {
+		return // too short or too quiet to measure
+	}
+	r.Loudness = &Loudness{
+		Integrated: round2(integrated),
+		Range:      round2(loudnessRange(windows(m.steps, stepsPerWindow))),
+		TruePeak:   round2(20 * math.Log10(m.peaks.truePeak())),
+	}
+}
+
+// windows returns the mean square of every run of n consecutive steps.
+func windows(steps []float64, n int) []float64 {
+	if len(steps) < n {
+		return nil
+	}
+	out := make([]float64, 0, len(steps)-n+1)
+	sum := 0.0
+	for i, s := range steps {
+		sum += s
+		if i >= n {
+			sum -= steps[i-n]
+		}
+		if i >= n-1 {
+			out = append(out, sum/float64(n))
+		}
+	}
+	return out
+}
+
+// lufs converts a mean square to loudness.
+func lufs(ms float64) float64 {
+	return -0.691 + 10*math.Log10(ms)
+}
+
+// gatedLoudness is the loudness of the blocks above the absolute gate and
+// then above gate LU below their own loudness; false when none pass.
+func gatedLoudness(blocks []float64, gate float64) (float64, bool) {
+	passed := aboveAbsoluteGate(blocks)
+	if len(passed) == 0 {
+		return 0, false
+	}
+	threshold := lufs(mean(passed)) + gate
+	var kept []float64
+	for _, b := range passed {
+		if lufs(b) > threshold {
+			kept = append(kept, b)
+		}
+	}
+	if len(kept) == 0 {
+		return 0, false
+	}
+	return lufs(mean(kept)), true
+}
+
+// loudnessRange is the spread between the 10th and 95th percentile
+// loudness of the gated short-term windows (EBU Tech 3342).
+func loudnessRange(windows []float64) float64 {
+	passed := aboveAbsoluteGate(windows)
+	if len(passed) == 0 {
+		return 0
+	}
+	threshold := lufs(mean(passed)) + rangeGate
+	var levels []float64
+	for _, w := range passed {
+		if l := lufs(w); l > threshold {
+			levels = append(levels, l)
+		}
+	}
+	if len(levels) == 0 {
+		return 0
+	}
+	slices.Sort(levels)
+	percentile := func(p float64) float64 {
+		return levels[int(math.Round(p*float64(len(levels)-1)))]
+	}
+	return percentile(0.95) - percentile(0.10)
+}
+
+func aboveAbsoluteGate(blocks []float64) []float64 {
+	var passed []float64
+	for _, b := range blocks {
+		if b > 0 && lufs(b) > absoluteGate {
+			passed = append(passed, b)
+		}
+	}
+	return passed
+}
+
+func mean(v []float64) float64 {
+	sum := 0.0
+	for _, x := range v {
+		sum += x
+	}
+	return sum / float64(len(v))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// kWeight is the BS.1770 K-weighting filter of one channel: a high-shelf
+// modelling the head followed by a high-pass, as biquads designed for the
+// file's sample rate.
+type kWeight struct {
+	shelf, highPass biquad
+}
+
+func newKWeight(fs float64) kWeight {
+	// Shelf: +4 dB above about 1.7 kHz
+	f0, g, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, g/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	// High-pass at about 38 Hz
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1, b1: -2, b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return kWeight{shelf, highPass}
+}
+
+func (k *kWeight) process(x float64) float64 {
+	return k.highPass.process(k.shelf.process(x))
+}
+
+// biquad is a second-order IIR filter in direct form II.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	w := x - f.a1*f.z1 - f.a2*f.z2
+	y := f.b0*w + f.b1*f.z1 + f.b2*f.z2
+	f.z2, f.z1 = f.z1, w
+	return y
+}
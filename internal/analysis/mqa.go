@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"fmt"
+	"strconv"
+
+	"flacidal/internal/flacmeta"
+)
+
+// mqaSync is the 36-bit word MQA encoders hide, one bit per stereo frame,
+// in the XOR of the channels' 16th most significant bits, where a decoder
+// finds the folded high-resolution stream.
+const mqaSync = 0xbe0498c88
+
+// mqaMeter looks for mqaSync in the decoded audio.
+type mqaMeter struct {
+	channels int
+	buf      uint64 // the bits of the frames so far, the last in bit 0
+	found    bool
+}
+
+func (m *mqaMeter) add(samples []int32) {
+	if m.found {
+		return
+	}
+	for i := 0; i+1 < len(samples); i += m.channels {
+		// Samples are left-aligned, so the 16th bit is bit 16 at any depth
+		bit := uint64((uint32(samples[i])^uint32(samples[i+1]))>>16) & 1
+		m.buf = (m.buf<<1 | bit) & (1<<36 - 1)
+		if m.buf == mqaSync {
+			m.found = true
+			return
+		}
+	}
+}
+
+func (m *mqaMeter) finish(r *Result) {
+	if m.found {
+		r.MQA = true
+		r.MQALabel = "MQA"
+	}
+}
+
+// MQATags marks r as MQA when comments carry the tags MQA encoders and
+// the stores selling MQA write (MQAENCODER, MQASTUDIO and
+// ORIGINALSAMPLERATE), whether or not its audio was measured, and adds
+// the original sample rate and studio authentication they record to its
+// label.
+func MQATags(r *Result, c *flacmeta.Comments) {
+	encoder := c.Get("MQAENCODER")
+	studio := c.Get("MQASTUDIO")
+	rate, _ := strconv.Atoi(c.Get("ORIGINALSAMPLERATE"))
+	if encoder == "" && studio == "" && !r.MQA {
+		return
+	}
+	r.MQA = true
+	r.MQALabel = "MQA"
+	if studio != "" && studio != "0" {
+		r.MQALabel = "MQA Studio"
+	}
+	if rate > 0 {
+		r.MQAOriginalSampleRate = rate
+		r.MQALabel += fmt.Sprintf(", %g kHz original", float64(rate)/1000)
+	}
+}
@@ -0,0 +1,163 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// clipRun is how many consecutive full-scale samples make a clip, as
+	// in Audacity's Find Clipping; lone full-scale samples are often
+	// legitimate peaks.
+	clipRun = 3
+
+	// damagedPercent is the share of clipped samples, or of inter-sample
+	// peaks above 0 dBFS, from which a track is flagged as damaged.
+	damagedPercent = 0.01
+)
+
+// peakMeter finds each channel's true peak and counts clipped samples and
+// inter-sample overs.
+type peakMeter struct {
+	channels  int
+	fullScale int32      // the declared bit depth's largest sample, left-aligned
+	peaks     []truePeak // per channel
+	runs      []int      // per channel: consecutive full-scale samples so far
+	last      []int32    // per channel: the previous sample
+	samples   int64
+	clipped   int64
+	overs     int64
+}
+
+func newPeakMeter(channels, bitDepth int) *peakMeter {
+	return &peakMeter{
+		channels:  channels,
+		fullScale: int32(uint32(math.MaxInt32) &^ (1<<(32-bitDepth) - 1)),
+		peaks:     make([]truePeak, channels),
+		runs:      make([]int, channels),
+		last:      make([]int32, channels),
+	}
+}
+
+func (m *peakMeter) add(samples []int32) {
+	for i := 0; i+m.channels <= len(samples); i += m.channels {
+		for c := range m.channels {
+			s := samples[i+c]
+			if s >= m.fullScale || s == math.MinInt32 {
+				if s != m.last[c] {
+					m.runs[c] = 0 // clipped the other way
+				}
+				m.runs[c]++
+				switch {
+				case m.runs[c] == clipRun:
+					m.clipped += clipRun
+				case m.runs[c] > clipRun:
+					m.clipped++
+				}
+			} else {
+				m.runs[c] = 0
+			}
+			m.last[c] = s
+			if m.peaks[c].add(float64(s) / (1 << 31)) {
+				m.overs++
+			}
+		}
+	}
+	m.samples += int64(len(samples) - len(samples)%m.channels)
+}
+
+// truePeak is the highest true peak across channels, as a linear
+// amplitude.
+func (m *peakMeter) truePeak() float64 {
+	peak := 0.0
+	for c := range m.peaks {
+		peak = max(peak, m.peaks[c].peak)
+	}
+	return peak
+}
+
+func (m *peakMeter) finish(r *Result) {
+	if m.samples == 0 {
+		return
+	}
+	r.ClippedSamples = m.clipped
+	r.ClippedPercent = percent(m.clipped, m.samples)
+	r.InterSamplePeaks = m.overs
+	overPercent := percent(m.overs, m.samples)
+	if r.ClippedPercent < damagedPercent && overPercent < damagedPercent {
+		return
+	}
+	r.Clipping = true
+	switch {
+	case r.ClippedPercent >= damagedPercent && overPercent >= damagedPercent:
+		r.ClippingLabel = fmt.Sprintf("%g%% of samples clipped, %d inter-sample peaks above 0 dBFS", r.ClippedPercent, m.overs)
+	case r.ClippedPercent >= damagedPercent:
+		r.ClippingLabel = fmt.Sprintf("%g%% of samples clipped", r.ClippedPercent)
+	default:
+		r.ClippingLabel = fmt.Sprintf("%d inter-sample peaks above 0 dBFS", m.overs)
+	}
+}
+
+// percent is n as a percentage of total, to three decimals.
+func percent(n, total int64) float64 {
+	return math.Round(float64(n)/float64(total)*1e5) / 1e3
+}
+
+// True peak is estimated, as BS.1770 describes, from the signal
+// oversampled four times, catching the peaks between samples that
+// clip once the audio is converted to analogue or lossy formats.
+const (
+	oversample = 4
+	phaseTaps  = 12 // taps per phase of the interpolation filter
+)
+
+// interpolator holds the phases of a windowed-sinc low-pass at the
+// original Nyquist frequency, each normalized to unity gain.
+var interpolator = func() [oversample][phaseTaps]float64 {
+	var phases [oversample][phaseTaps]float64
+	const n = oversample * phaseTaps
+	for i := range n {
+		t := float64(i) - float64(n-1)/2
+		x := math.Pi * t / oversample
+		sinc := 1.0
+		if x != 0 {
+			sinc = math.Sin(x) / x
+		}
+		window := 0.5 - 0.5*math.Cos(2*math.Pi*(float64(i)+0.5)/n) // Hann
+		phases[i%oversample][i/oversample] = sinc * window
+	}
+	for p := range phases {
+		sum := 0.0
+		for _, h := range phases[p] {
+			sum += h
+		}
+		for k := range phases[p] {
+			phases[p][k] /= sum
+		}
+	}
+	return phases
+}()
+
+// truePeak tracks the peak of one channel, oversampled.
+type truePeak struct {
+	history [phaseTaps]float64 // the latest samples, newest last
+	peak    float64
+}
+
+// add takes the next sample and reports whether the signal between it and
+// the previous samples goes above 0 dBFS.
+func (t *truePeak) add(x float64) (over bool) {
+	copy(t.history[:], t.history[1:])
+	t.history[phaseTaps-1] = x
+	t.peak = max(t.peak, math.Abs(x))
+	for p := range interpolator {
+		y := 0.0
+		for k, h := range interpolator[p] {
+			y += h * t.history[phaseTaps-1-k]
+		}
+		y = math.Abs(y)
+		t.peak = max(t.peak, y)
+		over = over || y > 1
+	}
+	return over
+}
@@ -0,0 +1,207 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+)
+
+const (
+	// spectrumStride is every how many chunks the spectrum meter
+	// transforms one, enough for an average while sparing the CPU.
+	spectrumStride = 4
+
+	// maxSpectrumBlocks caps how many transformed chunks the spectrum
+	// meter keeps for hole counting.
+	maxSpectrumBlocks = 1024
+
+	// bandHz is the width of the bands the spectrum is read in.
+	bandHz = 200
+
+	// maxShelfHz is the highest cutoff taken for a lossy encoder's
+	// lowpass; above it the audio is full band, or a lossless 44.1/48 kHz
+	// master resampled.
+	maxShelfHz = 20500
+)
+
+// spectrumMeter averages the spectrum of the decoded audio, mixed to
+// mono, and keeps each transformed chunk's band powers, to fingerprint
+// the lossy codec an upscaled file came from.
+type spectrumMeter struct {
+	channels   int
+	sampleRate int
+	chunks     int
+	window     []float64
+	buf        []complex128
+	sum        []float64   // power per band over all blocks
+	blocks     [][]float32 // power per band of each block
+}
+
+func newSpectrumMeter(sampleRate, channels int) *spectrumMeter {
+	m := &spectrumMeter{
+		channels:   channels,
+		sampleRate: sampleRate,
+		window:     make([]float64, chunkFrames),
+		buf:        make([]complex128, chunkFrames),
+		sum:        make([]float64, sampleRate/2/bandHz+1),
+	}
+	for i := range m.window {
+		m.window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/chunkFrames) // Hann
+	}
+	return m
+}
+
+func (m *spectrumMeter) add(samples []int32) {
+	m.chunks++
+	if (m.chunks-1)%spectrumStride != 0 || len(samples) < chunkFrames*m.channels || len(m.blocks) == maxSpectrumBlocks {
+		return
+	}
+	for i := range chunkFrames {
+		var v float64
+		for _, s := range samples[i*m.channels : (i+1)*m.channels] {
+			v += float64(s)
+		}
+		m.buf[i] = complex(v/float64(m.channels)/(1<<31)*m.window[i], 0)
+	}
+	fft(m.buf)
+	block := make([]float32, len(m.sum))
+	for k := range chunkFrames / 2 {
+		p := real(m.buf[k])*real(m.buf[k]) + imag(m.buf[k])*imag(m.buf[k])
+		b := min(k*m.sampleRate/chunkFrames/bandHz, len(block)-1)
+		block[b] += float32(p)
+		m.sum[b] += p
+	}
+	m.blocks = append(m.blocks, block)
+}
+
+func (m *spectrumMeter) finish(r *Result) {
+	if len(m.blocks) == 0 {
+		return
+	}
+	level := make([]float64, len(m.sum))
+	for b, p := range m.sum {
+		level[b] = db(p / float64(len(m.blocks)))
+	}
+	// The floor is the empty band above a lossy encoder's lowpass, or
+	// the quietest band of a full-band file, though no more than 100 dB
+	// under the loudest: below that is only leakage of the window
+	floor := max(slices.Min(level[1000/bandHz:]), slices.Max(level)-100)
+	full, cutoff := -1, -1
+	for b := len(level) - 1; b >= 0 && full < 0; b-- {
+		if cutoff < 0 && level[b] >= floor+10 {
+			cutoff = b
+		}
+		if level[b] >= floor+30 {
+			full = b
+		}
+	}
+	cutoffHz := cutoff * bandHz
+	if full < 0 || cutoffHz > maxShelfHz || cutoffHz > m.sampleRate/2-1000 {
+		return
+	}
+	width := (cutoff - full) * bandHz
+	holes := m.holes(full)
+	var codec, shape string
+	switch {
+	case holes >= 0.1:
+		// MP3 leaves its highest scalefactor band (sfb21) empty whenever
+		// the bits run short, so the top of the spectrum comes and goes
+		codec, shape = "MP3", fmt.Sprintf("%.1f kHz lowpass with holes in %.0f%% of the audio", khz(cutoffHz), holes*100)
+	case width <= 600:
+		codec, shape = "AAC", fmt.Sprintf("sharp %.1f kHz lowpass", khz(cutoffHz))
+	default:
+		// Vorbis rolls off gradually and fills what it drops with noise
+		codec, shape = "Vorbis", fmt.Sprintf("gradual rolloff to %.1f kHz", khz(cutoffHz))
+	}
+	r.LossyCodec = codec
+	r.LossyCodecLabel = fmt.Sprintf("%s, ~%s kbps (%s)", codec, bitrateFor(cutoffHz), shape)
+}
+
+// holes is the share of the blocks in which the top 2 kHz up to band
+// top, the last full band, drop out while the 3 kHz under them play on.
+func (m *spectrumMeter) holes(top int) float64 {
+	hiFrom := max(top+1-2000/bandHz, 1)
+	loFrom := max(hiFrom-3000/bandHz, 1)
+	if loFrom == hiFrom {
+		return 0
+	}
+	var ratios []float64
+	for _, block := range m.blocks {
+		lo, hi := sum(block[loFrom:hiFrom]), sum(block[hiFrom:top+1])
+		if lo > 0 {
+			ratios = append(ratios, db(hi)-db(lo))
+		}
+	}
+	if len(ratios) == 0 {
+		return 0
+	}
+	// Against the 90th percentile, what the top sounds like when present
+	slices.Sort(ratios)
+	typical := ratios[len(ratios)*9/10]
+	n := 0
+	for _, r := range ratios {
+		if r < typical-20 {
+			n++
+		}
+	}
+	return float64(n) / float64(len(ratios))
+}
+
+// bitrateFor is the bitrate lossy encoders typically cut at cutoffHz by
+// default: about 15.5 or 16 kHz at 128 kbps, 19 kHz at 192 kbps and 20 kHz
+// at 256 kbps and above.
+func bitrateFor(cutoffHz int) string {
+	switch {
+	case cutoffHz < 15750:
+		return "96–128"
+	case cutoffHz < 17500:
+		return "128"
+	case cutoffHz < 19500:
+		return "192"
+	}
+	return "256–320"
+}
+
+// db is power p in decibels, floored at -200 dB for silence.
+func db(p float64) float64 {
+	return 10 * math.Log10(p+1e-20)
+}
+
+func khz(hz int) float64 {
+	return float64(hz) / 1000
+}
+
+func sum(v []float32) float64 {
+	var s float64
+	for _, x := range v {
+		s += float64(x)
+	}
+	return s
+}
+
+// fft transforms x in place; len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := range size / 2 {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// monoCorrelation is the correlation from which two channels count as
+	// the same signal: mono padded to stereo, give or take dither.
+	monoCorrelation = 0.999
+
+	// phaseCorrelation is the correlation below which the channels count
+	// as out of phase, as with a wire swapped somewhere in the chain;
+	// real stereo mixes stay well above it.
+	phaseCorrelation = -0.2
+)
+
+// stereoMeter correlates the two channels of stereo audio.
+type stereoMeter struct {
+	ll, rr, lr float64
+	identical  bool // every frame so far has equal channels
+}
+
+func (m *stereoMeter) add(samples []int32) {
+	for i := 0; i+1 < len(samples); i += 2 {
+		l, r := float64(samples[i])/(1<<31), float64(samples[i+1])/(1<<31)
+		m.ll += l * l
+		m.rr += r * r
+		m.lr += l * r
+		if samples[i] != samples[i+1] {
+			m.identical = false
+		}
+	}
+}
+
+func (m *stereoMeter) finish(r *Result) {
+	if m.ll == 0 || m.rr == 0 {
+		// Silence, or a channel left empty, correlates with nothing
+		return
+	}
+	r.Correlation = m.lr / math.Sqrt(m.ll*m.rr)
+	switch {
+	case m.identical:
+		r.FakeStereo = true
+		r.StereoLabel = "mono: both channels are identical"
+	case r.Correlation >= monoCorrelation:
+		r.FakeStereo = true
+		r.StereoLabel = fmt.Sprintf("mono padded to stereo (correlation %.4f)", r.Correlation)
+	case r.Correlation < phaseCorrelation:
+		r.OutOfPhase = true
+		r.StereoLabel = fmt.Sprintf("channels out of phase (correlation %.2f)", r.Correlation)
+	}
+}
@@ -0,0 +1,54 @@
+package analysis
+
+import "fmt"
+
+const (
+	// shortTolerance is how many seconds fewer than STREAMINFO records a
+	// decode may yield before the file counts as truncated.
+	shortTolerance = 0.5
+
+	// longSilence is how many seconds of digital silence a track may end
+	// in before it counts as truncated: a download cut off and
+	// zero-filled. Hidden tracks after a long gap are flagged too.
+	longSilence = 10
+)
+
+// truncationMeter counts the decoded frames and the digital silence
+// they end in.
+type truncationMeter struct {
+	channels   int
+	sampleRate int
+	declared   uint64 // STREAMINFO's frames; 0 when the encoder didn't record them
+	frames     uint64
+	silent     uint64 // frames of digital silence at the end so far
+}
+
+func (m *truncationMeter) add(samples []int32) {
+	for i := 0; i+m.channels <= len(samples); i += m.channels {
+		m.frames++
+		m.silent++
+		for _, s := range samples[i : i+m.channels] {
+			if s != 0 {
+				m.silent = 0
+				break
+			}
+		}
+	}
+}
+
+func (m *truncationMeter) finish(r *Result) {
+	rate := float64(m.sampleRate)
+	if m.frames > m.silent {
+		// A track of nothing but silence doesn't end in any
+		r.TrailingSilence = float64(m.silent) / rate
+	}
+	switch {
+	case m.declared > m.frames && float64(m.declared-m.frames)/rate > shortTolerance:
+		r.Truncated = true
+		r.MissingSeconds = float64(m.declared-m.frames) / rate
+		r.TruncatedLabel = fmt.Sprintf("%.1f s shorter than its header records", r.MissingSeconds)
+	case r.TrailingSilence >= longSilence:
+		r.Truncated = true
+		r.TruncatedLabel = fmt.Sprintf("ends in %.0f s of digital silence", r.TrailingSilence)
+	}
+}
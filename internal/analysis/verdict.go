@@ -0,0 +1,125 @@
+package analysis
+
+import "fmt"
+
+// Thresholds turn the spectral cutoff flacidal-core measures into a
+// verdict (see Judge): the frequencies, in Hz, at which a file counts as
+// lossless, likely upscaled, upscaled or upsampled, and how confident
+// each verdict is, in percent. A zero field means its default (see DefaultThresholds).
+type Thresholds struct {
+	// LosslessHz is the lowest cutoff of a lossless file. Encoders at
+	// their best settings cut around 20 kHz.
+	LosslessHz int `json:"losslessHz"`
+
+	// LikelyHz is the lowest cutoff of a file that is only likely
+	// upscaled, as from a high-bitrate MP3 or AAC cutting at 18–20 kHz.
+	LikelyHz int `json:"likelyHz"`
+
+	// UpscaledHz is the cutoff below which a file is upscaled beyond
+	// doubt; a 128 kbps MP3 cuts at 16 kHz. Files between it and
+	// LikelyHz are upscaled, with UpscaledConfidence.
+	UpscaledHz int `json:"upscaledHz"`
+
+	// HiResHz is the lowest cutoff of a true hi-res file, 88.2 kHz or
+	// more. Below it, such a file whose cutoff still makes it lossless is
+	// upsampled from 44.1 or 48 kHz, whose audio stops at 22–24 kHz:
+	// fake hi-res.
+	HiResHz int `json:"hiResHz"`
+
+	LosslessConfidence int `json:"losslessConfidence"`
+	LikelyConfidence   int `json:"likelyConfidence"`
+	UpscaledConfidence int `json:"upscaledConfidence"`
+	// CertainConfidence is the confidence below UpscaledHz.
+	CertainConfidence   int `json:"certainConfidence"`
+	UpsampledConfidence int `json:"upsampledConfidence"`
+}
+
+// DefaultThresholds are the thresholds of an unconfigured analyzer.
+var DefaultThresholds = Thresholds{
+	LosslessHz:          20000,
+	LikelyHz:            18000,
+	UpscaledHz:          16000,
+	LosslessConfidence:  95,
+	LikelyConfidence:    70,
+	UpscaledConfidence:  85,
+	CertainConfidence:   95,
+	HiResHz:             24000,
+	UpsampledConfidence: 90,
+}
+
+// WithDefaults returns t with its zero fields set to DefaultThresholds'.
+func (t Thresholds) WithDefaults() Thresholds {
+	def := func(v *int, d int) {
+		if *v == 0 {
+			*v = d
+		}
+	}
+	d := DefaultThresholds
+	def(&t.LosslessHz, d.LosslessHz)
+	def(&t.LikelyHz, d.LikelyHz)
+	def(&t.UpscaledHz, d.UpscaledHz)
+	def(&t.LosslessConfidence, d.LosslessConfidence)
+	def(&t.LikelyConfidence, d.LikelyConfidence)
+	def(&t.UpscaledConfidence, d.UpscaledConfidence)
+	def(&t.CertainConfidence, d.CertainConfidence)
+	def(&t.HiResHz, d.HiResHz)
+	def(&t.UpsampledConfidence, d.UpsampledConfidence)
+	return t
+}
+
+// Validate rejects negative values, confidences above 100 and cutoffs
+// out of order once defaults are applied.
+func (t Thresholds) Validate() error {
+	for _, v := range []int{t.LosslessHz, t.LikelyHz, t.UpscaledHz, t.HiResHz} {
+		if v < 0 {
+			return fmt.Errorf("cutoff %d Hz must not be negative", v)
+		}
+	}
+	for _, v := range []int{t.LosslessConfidence, t.LikelyConfidence, t.UpscaledConfidence, t.CertainConfidence, t.UpsampledConfidence} {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("confidence %d must be between 0 and 100", v)
+		}
+	}
+	t = t.WithDefaults()
+	if t.UpscaledHz > t.LikelyHz || t.LikelyHz > t.LosslessHz || t.LosslessHz > t.HiResHz {
+		return fmt.Errorf("cutoffs must rise from upscaled (%d Hz) to likely (%d Hz) to lossless (%d Hz) to hi-res (%d Hz)", t.UpscaledHz, t.LikelyHz, t.LosslessHz, t.HiResHz)
+	}
+	return nil
+}
+
+// Judgement is the verdict Judge gives a cutoff.
+type Judgement struct {
+	Verdict      string // "lossless", "likely_upscaled", "upscaled" or "upsampled"
+	VerdictLabel string
+	Lossless     bool
+	Confidence   int
+}
+
+// Judge gives the verdict on a file at sampleRate Hz whose spectrum is cut
+// at cutoff Hz, by t with its defaults applied. An upsampled file is
+// lossless, but not the hi-res it is sold as, and upscaling from a lossy
+// source takes precedence.
+func Judge(cutoff, sampleRate int, t Thresholds) Judgement {
+	t = t.WithDefaults()
+	switch {
+	case sampleRate >= 88200 && cutoff >= t.LosslessHz && cutoff < t.HiResHz:
+		return Judgement{"upsampled", "Upsampled from " + baseRate(sampleRate), false, t.UpsampledConfidence}
+	case cutoff >= t.LosslessHz:
+		return Judgement{"lossless", "Lossless", true, t.LosslessConfidence}
+	case cutoff >= t.LikelyHz:
+		return Judgement{"likely_upscaled", "Likely Upscaled", false, t.LikelyConfidence}
+	case cutoff >= t.UpscaledHz:
+		return Judgement{"upscaled", "Upscaled", false, t.UpscaledConfidence}
+	default:
+		return Judgement{"upscaled", "Upscaled", false, t.CertainConfidence}
+	}
+}
+
+// baseRate is the CD or video rate a file at sampleRate is a multiple
+// of, as in "44.1 kHz".
+func baseRate(sampleRate int) string {
+	if sampleRate%44100 == 0 {
+		return "44.1 kHz"
+	}
+	return "48 kHz"
+}
@@ -0,0 +1,44 @@
+package analysis
+
+import "testing"
+
+func TestJudge(t *testing.T) {
+	for _, tc := range []struct {
+		cutoff int
+		rate   int
+		t      Thresholds
+		want   Judgement
+	}{
+		{21000, 44100, Thresholds{}, Judgement{"lossless", "Lossless", true, 95}},
+		{19000, 44100, Thresholds{}, Judgement{"likely_upscaled", "Likely Upscaled", false, 70}},
+		{17000, 44100, Thresholds{}, Judgement{"upscaled", "Upscaled", false, 85}},
+		{15000, 44100, Thresholds{}, Judgement{"upscaled", "Upscaled", false, 95}},
+		{19000, 44100, Thresholds{LosslessHz: 19000, LosslessConfidence: 80}, Judgement{"lossless", "Lossless", true, 80}},
+		{22000, 96000, Thresholds{}, Judgement{"upsampled", "Upsampled from 48 kHz", false, 90}},
+		{22000, 176400, Thresholds{}, Judgement{"upsampled", "Upsampled from 44.1 kHz", false, 90}},
+		{40000, 96000, Thresholds{}, Judgement{"lossless", "Lossless", true, 95}},
+		{15000, 96000, Thresholds{}, Judgement{"upscaled", "Upscaled", false, 95}},
+		{22000, 48000, Thresholds{}, Judgement{"lossless", "Lossless", true, 95}},
+	} {
+		if got := Judge(tc.cutoff, tc.rate, tc.t); got != tc.want {
+			t.Errorf("Judge(%d, %d, %+v) = %+v, want %+v", tc.cutoff, tc.rate, tc.t, got, tc.want)
+		}
+	}
+}
+
+func TestThresholds_Validate(t *testing.T) {
+	if err := (Thresholds{}).Validate(); err != nil {
+		t.Errorf("defaults: %v", err)
+	}
+	for _, bad := range []Thresholds{
+		{LosslessHz: -1},
+		{LikelyConfidence: 101},
+		{LosslessHz: 17000}, // below the default likely cutoff
+		{UpscaledHz: 19000, LikelyHz: 18500},
+		{HiResHz: 19000}, // below the default lossless cutoff
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v validated", bad)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os/exec"
+	"strings"
+
+	"flacidal/internal/flacmeta"
+)
+
+// Verification is the outcome of Verify, like `flac -t`: whether the file
+// decodes cleanly to the audio its STREAMINFO signature was computed from.
+type Verification struct {
+	Path            string   `json:"path"`
+	OK              bool     `json:"ok"`
+	MD5             string   `json:"md5"`                   // of the decoded audio
+	ExpectedMD5     string   `json:"expectedMd5,omitempty"` // STREAMINFO's; empty when the encoder didn't record one
+	Samples         uint64   `json:"samples"`               // decoded, per channel
+	ExpectedSamples uint64   `json:"expectedSamples,omitempty"`
+	Problems        []string `json:"problems,omitempty"` // decode errors, a short or long decode, an MD5 mismatch
+}
+
+// VerifyArgs returns DecodeArgs with the decoder checking every frame's
+// CRC and reporting damaged frames.
+func VerifyArgs(path string) []string {
+	return append([]string{"-err_detect", "crccheck+bitstream+buffer"}, DecodeArgs(path)...)
+}
+
+// Verify fully decodes the FLAC file at path with the ffmpeg binary at
+// ffmpeg and checks the audio against its STREAMINFO: the sample count
+// and MD5 signature, when the encoder recorded them. A damaged file is a
+// Verification with Problems, not an error; errors are for files that
+// aren't FLAC or ffmpeg failing to run.
+func Verify(ctx context.Context, ffmpeg, path string) (*Verification, error) {
+	si, err := flacmeta.ReadStreamInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	var v *Verification
+	stderr, err := decode(ctx, ffmpeg, VerifyArgs(path), func(out io.Reader) (err error) {
+		v, err = verifySamples(out, si)
+		return err
+	})
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		return nil, err
+	}
+	if v == nil {
+		v = &Verification{ExpectedSamples: si.Samples}
+	}
+	v.Path = path
+	if stderr != "" {
+		// Only the first of what may be thousands of damaged frames
+		lines := strings.Split(stderr, "\n")
+		problem := "decode error: " + strings.TrimSpace(lines[0])
+		if len(lines) > 1 {
+			problem += fmt.Sprintf(" (and %d more)", len(lines)-1)
+		}
+		v.Problems = append([]string{problem}, v.Problems...)
+	} else if exit != nil {
+		v.Problems = append([]string{"decode failed: " + exit.Error()}, v.Problems...)
+	}
+	v.OK = len(v.Problems) == 0
+	return v, nil
+}
+
+// verifySamples checks the decoded audio of a file with the format si,
+// read from r as Measure reads it, against si.
+func verifySamples(r io.Reader, si flacmeta.StreamInfo) (*Verification, error) {
+	if si.Channels <= 0 || si.BitDepth <= 0 {
+		return nil, fmt.Errorf("unknown audio format")
+	}
+	m := newMD5Meter(si.BitDepth)
+	if err := feed(r, si.Channels, m); err != nil {
+		return nil, err
+	}
+	v := &Verification{
+		MD5:             hex.EncodeToString(m.hash.Sum(nil)),
+		Samples:         m.samples / uint64(si.Channels),
+		ExpectedSamples: si.Samples,
+	}
+	switch {
+	case si.Samples > 0 && v.Samples < si.Samples:
+		v.Problems = append(v.Problems, fmt.Sprintf("truncated: %d of %d samples decoded", v.Samples, si.Samples))
+	case si.Samples > 0 && v.Samples > si.Samples:
+		v.Problems = append(v.Problems, fmt.Sprintf("%d samples decoded, STREAMINFO says %d", v.Samples, si.Samples))
+	}
+	if si.MD5 != ([16]byte{}) {
+		v.ExpectedMD5 = hex.EncodeToString(si.MD5[:])
+		if v.MD5 != v.ExpectedMD5 {
+			v.Problems = append(v.Problems, "MD5 mismatch: the audio differs from what was encoded")
+		}
+	}
+	v.OK = len(v.Problems) == 0
+	return v, nil
+}
+
+// md5Meter hashes the decoded audio the way FLAC encoders sign it: each
+// sample little-endian in the fewest whole bytes its bit depth fits,
+// channels interleaved.
+type md5Meter struct {
+	bitDepth int
+	width    int // bytes per sample
+	hash     hash.Hash
+	buf      []byte
+	samples  uint64
+}
+
+func newMD5Meter(bitDepth int) *md5Meter {
+	return &md5Meter{bitDepth: bitDepth, width: (bitDepth + 7) / 8, hash: md5.New()}
+}
+
+func (m *md5Meter) add(samples []int32) {
+	m.buf = m.buf[:0]
+	for _, s := range samples {
+		v := s >> (32 - m.bitDepth)
+		for b := range m.width {
+			m.buf = append(m.buf, byte(v>>(8*b)))
+		}
+	}
+	m.hash.Write(m.buf)
+	m.samples += uint64(len(samples))
+}
+
+func (m *md5Meter) finish(*Result) {}
@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"context"
+	"crypto/md5"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/flacmeta/flactest"
+)
+
+func TestVerifySamples(t *testing.T) {
+	// Two stereo frames of 24-bit audio as FLAC signs them: 3 bytes per
+	// sample, little-endian
+	si := flacmeta.StreamInfo{SampleRate: 44100, Channels: 2, BitDepth: 24, Samples: 2}
+	si.MD5 = md5.Sum([]byte{0x01, 0, 0, 0xfe, 0xff, 0xff, 0xff, 0xff, 0x7f, 0, 0, 0x80})
+	samples := []int32{1, -2, 1<<23 - 1, -1 << 23}
+
+	v, err := verifySamples(pcm(24, samples...), si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.OK || v.MD5 != v.ExpectedMD5 || v.Samples != 2 {
+		t.Errorf("intact: %+v", v)
+	}
+
+	samples[1] = -3 // one flipped bit
+	if v, _ = verifySamples(pcm(24, samples...), si); v.OK || !strings.Contains(strings.Join(v.Problems, ";"), "MD5 mismatch") {
+		t.Errorf("bit rot: %+v", v)
+	}
+
+	if v, _ = verifySamples(pcm(24, samples[:2]...), si); v.OK || !strings.HasPrefix(v.Problems[0], "truncated: 1 of 2") {
+		t.Errorf("truncated: %+v", v)
+	}
+
+	// Without a recorded signature only the decode is checked
+	si.MD5 = [16]byte{}
+	if v, _ = verifySamples(pcm(24, samples...), si); !v.OK || v.ExpectedMD5 != "" {
+		t.Errorf("unsigned: %+v", v)
+	}
+}
+
+func TestVerify_LateDecodeError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg is a shell script")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "damaged.flac")
+	flactest.Write(t, path, flactest.CD)
+	// Closes stdout, the end of the audio, before reporting the damage
+	ffmpeg := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\nexec >&-\nsleep 0.2\necho '[flac @ 0x1] CRC mismatch' >&2\n"
+	if err := os.WriteFile(ffmpeg, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := Verify(context.Background(), ffmpeg, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.OK || len(v.Problems) == 0 || v.Problems[0] != "decode error: [flac @ 0x1] CRC mismatch" {
+		t.Errorf("Verify = %+v, want ffmpeg's decode error", v)
+	}
+}
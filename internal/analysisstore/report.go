@@ -0,0 +1,173 @@
+package analysisstore
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"flacidal/internal/quality"
+	"flacidal/internal/timestamp"
+)
+
+// Formats are the report encodings Encode accepts.
+var Formats = []string{"csv", "json"}
+
+// Report is the quality of every indexed track of the library, as far as
+// it has been analyzed.
+type Report struct {
+	CreatedAt string         `json:"createdAt"` // UTC RFC3339, see internal/timestamp
+	Tracks    int            `json:"tracks"`
+	Analyzed  int            `json:"analyzed"` // tracks with a current analysis
+	Outdated  int            `json:"outdated"` // tracks changed since their analysis
+	Verdicts  map[string]int `json:"verdicts"` // analyzed tracks per verdict
+	Padded    int            `json:"padded"`
+	Clipping  int            `json:"clipping"`
+	Flagged   int            `json:"flagged"` // see Verdict.Flagged
+	Files     []Entry        `json:"files"`
+}
+
+// Entry is one track. Its verdict fields are empty unless it has a current
+// analysis.
+type Entry struct {
+	Path           string          `json:"path"`
+	Artist         string          `json:"artist"`
+	Album          string          `json:"album"`
+	Title          string          `json:"title"`
+	Quality        quality.Quality `json:"quality,omitempty"` // see quality.Tier
+	SampleRate     int             `json:"sampleRate,omitempty"`
+	BitDepth       int             `json:"bitDepth,omitempty"`
+	Verdict        string          `json:"verdict,omitempty"`
+	VerdictLabel   string          `json:"verdictLabel,omitempty"`
+	PaddedBitDepth bool            `json:"paddedBitDepth,omitempty"`
+	Clipping       bool            `json:"clipping,omitempty"`
+	AnalyzedAt     string          `json:"analyzedAt,omitempty"` // UTC RFC3339
+	Outdated       bool            `json:"outdated,omitempty"`   // analyzed, but changed since
+}
+
+// Report lists the library index's tracks, by artist, album and track
+// number, with their analyses. It reads the index as of its last scan,
+// so files added since are missing until the next one; whether an
+// analysis is current is checked against the file itself.
+func (s *Store) Report() (*Report, error) {
+	r := &Report{CreatedAt: timestamp.Format(time.Now()), Verdicts: map[string]int{}, Files: []Entry{}}
+	if s == nil {
+		return r, nil
+	}
+	rows, err := s.db.Query(`SELECT l.path, l.artist, l.album, l.title, l.sample_rate, l.bit_depth,
+		a.size, a.mod_time, a.verdict, a.verdict_label, a.padded, a.clipping, a.analyzed_at
+		FROM library l LEFT JOIN analyses a ON a.path = l.path
+		ORDER BY COALESCE(NULLIF(l.album_artist, ''), l.artist) COLLATE NOCASE, l.album COLLATE NOCASE, l.disc_number, l.track_number, l.path`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e Entry
+		var aSize, aModTime, analyzedAt sql.NullInt64
+		var verdict, label sql.NullString
+		var padded, clipping sql.NullBool
+		if err := rows.Scan(&e.Path, &e.Artist, &e.Album, &e.Title, &e.SampleRate, &e.BitDepth,
+			&aSize, &aModTime, &verdict, &label, &padded, &clipping, &analyzedAt); err != nil {
+			return nil, err
+		}
+		e.Quality = quality.Tier(e.BitDepth, e.SampleRate)
+		r.add(e, aSize, aModTime, Verdict{
+			Verdict: verdict.String, VerdictLabel: label.String, PaddedBitDepth: padded.Bool, Clipping: clipping.Bool,
+			AnalyzedAt: time.Unix(0, analyzedAt.Int64),
+		})
+	}
+	return r, rows.Err()
+}
+
+// add lists e with its analysis v, stamped size and modTime, when that
+// is current.
+func (r *Report) add(e Entry, size, modTime sql.NullInt64, v Verdict) {
+	r.Tracks++
+	st, err := os.Stat(e.Path)
+	switch {
+	case !size.Valid || err != nil:
+	case !current(st, size.Int64, modTime.Int64):
+		e.Outdated = true
+		r.Outdated++
+	default:
+		e.Verdict, e.VerdictLabel = v.Verdict, v.VerdictLabel
+		e.PaddedBitDepth, e.Clipping = v.PaddedBitDepth, v.Clipping
+		e.AnalyzedAt = timestamp.Format(v.AnalyzedAt)
+		r.Analyzed++
+		r.Verdicts[v.Verdict]++
+		if v.PaddedBitDepth {
+			r.Padded++
+		}
+		if v.Clipping {
+			r.Clipping++
+		}
+		if v.Flagged() {
+			r.Flagged++
+		}
+	}
+	r.Files = append(r.Files, e)
+}
+
+// ValidateFormat rejects formats Encode doesn't know.
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown report format %q (want %s)", format, strings.Join(Formats, " or "))
+}
+
+// csvHeader names the CSV columns, in Entry's field order.
+var csvHeader = []string{"path", "artist", "album", "title", "quality", "sample_rate", "bit_depth", "verdict", "verdict_label", "padded_bit_depth", "clipping", "analyzed_at", "outdated"}
+
+// CSV encodes r's files as CSV with a header row.
+func (r *Report) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(csvHeader) //nolint:errcheck // reported by w.Error
+	for _, e := range r.Files {
+		w.Write([]string{ //nolint:errcheck // reported by w.Error
+			e.Path, e.Artist, e.Album, e.Title, e.Quality.String(), number(e.SampleRate), number(e.BitDepth),
+			e.Verdict, e.VerdictLabel, flag(e.PaddedBitDepth), flag(e.Clipping), e.AnalyzedAt, flag(e.Outdated),
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// number formats n, leaving unknown (zero) values blank.
+func number(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// flag formats b as "yes" or blank.
+func flag(b bool) string {
+	if b {
+		return "yes"
+	}
+	return ""
+}
+
+// Encode returns r in format ("csv" or "json") and the file extension that
+// goes with it.
+func (r *Report) Encode(format string) ([]byte, string, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, "", err
+	}
+	if format == "csv" {
+		data, err := r.CSV()
+		return data, ".csv", err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	return data, ".json", err
+}
@@ -0,0 +1,77 @@
+package analysisstore
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReport_Add(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.flac")
+	if err := os.WriteFile(path, []byte("fLaC"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := sql.NullInt64{Int64: st.Size(), Valid: true}
+	modTime := sql.NullInt64{Int64: st.ModTime().UnixNano(), Valid: true}
+	stale := sql.NullInt64{Int64: st.ModTime().UnixNano() - 1, Valid: true}
+	analyzed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := &Report{Verdicts: map[string]int{}}
+	r.add(Entry{Path: path, Title: "Current"}, size, modTime, Verdict{Verdict: "upscaled", VerdictLabel: "Upscaled", Clipping: true, AnalyzedAt: analyzed})
+	r.add(Entry{Path: path, Title: "Changed"}, size, stale, Verdict{Verdict: "lossless"})
+	r.add(Entry{Path: path, Title: "Never"}, sql.NullInt64{}, sql.NullInt64{}, Verdict{})
+	r.add(Entry{Path: filepath.Join(dir, "gone.flac"), Title: "Gone"}, size, modTime, Verdict{Verdict: "lossless"})
+
+	if r.Tracks != 4 || r.Analyzed != 1 || r.Outdated != 1 || r.Clipping != 1 || r.Flagged != 1 || r.Verdicts["upscaled"] != 1 {
+		t.Errorf("report = %+v", r)
+	}
+	if e := r.Files[0]; e.Verdict != "upscaled" || e.AnalyzedAt != "2026-01-02T03:04:05Z" {
+		t.Errorf("current entry = %+v", e)
+	}
+	if e := r.Files[1]; e.Verdict != "" || !e.Outdated {
+		t.Errorf("changed entry = %+v", e)
+	}
+	if e := r.Files[3]; e.Verdict != "" || e.Outdated {
+		t.Errorf("missing file's entry = %+v", e)
+	}
+
+	data, ext, err := r.Encode("csv")
+	if err != nil || ext != ".csv" {
+		t.Fatalf("Encode(csv) = %q, %v", ext, err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 || records[1][7] != "upscaled" || records[1][10] != "yes" || records[2][12] != "yes" {
+		t.Errorf("csv = %q", records)
+	}
+	if _, _, err := r.Encode("xml"); err == nil {
+		t.Error("Encode(xml) succeeded")
+	}
+}
+
+func TestVerdict_Flagged(t *testing.T) {
+	for _, tc := range []struct {
+		v    Verdict
+		want bool
+	}{
+		{Verdict{Verdict: "lossless"}, false},
+		{Verdict{Verdict: "lossless", PaddedBitDepth: true}, true},
+		{Verdict{Verdict: "lossless", Clipping: true}, true},
+		{Verdict{Verdict: "likely_upscaled"}, true},
+	} {
+		if got := tc.v.Flagged(); got != tc.want {
+			t.Errorf("%+v.Flagged() = %v, want %v", tc.v, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package analysisstore keeps the latest quality analysis of each analyzed
+// file in the library database, stamped with the file's size and
+// modification time, so verdicts outlive restarts, file listings can flag
+// files without decoding them, and the whole library's quality can be
+// reported at once. An analysis counts only while its file is unchanged.
+package analysisstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"flacidal/internal/library"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS analyses (
+	path          TEXT PRIMARY KEY,
+	size          INTEGER NOT NULL,
+	mod_time      INTEGER NOT NULL, -- UnixNano, like the library table's
+	verdict       TEXT NOT NULL,
+	verdict_label TEXT NOT NULL DEFAULT '',
+	padded        INTEGER NOT NULL DEFAULT 0,
+	clipping      INTEGER NOT NULL DEFAULT 0,
+	result        TEXT NOT NULL, -- the whole analysis, as JSON
+	analyzed_at   INTEGER NOT NULL
+);
+`
+
+// Verdict is what listings show of a file's analysis.
+type Verdict struct {
+	Verdict        string    `json:"verdict"` // "lossless", "upsampled", "likely_upscaled", "upscaled"…
+	VerdictLabel   string    `json:"verdictLabel,omitempty"`
+	PaddedBitDepth bool      `json:"paddedBitDepth,omitempty"`
+	Clipping       bool      `json:"clipping,omitempty"`
+	AnalyzedAt     time.Time `json:"analyzedAt"`
+}
+
+// Flagged reports whether v is worth a look: anything but a clean
+// lossless verdict.
+func (v Verdict) Flagged() bool {
+	return v.Verdict != "lossless" || v.PaddedBitDepth || v.Clipping
+}
+
+// Store keeps analyses in the library database. A nil Store keeps
+// nothing.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the analyses table of the library database in dataDir,
+// creating it.
+func Open(dataDir string) (*Store, error) {
+	db, err := sql.Open(library.Driver, "file:"+filepath.Join(dataDir, library.FileName)+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Put stores result, the analysis of the file at path, with its verdict v,
+// replacing the file's earlier analysis. The file is stamped as it is now,
+// so call Put after anything the analysis writes to it.
+func (s *Store) Put(path string, v Verdict, result any) error {
+	if s == nil {
+		return nil
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec("INSERT OR REPLACE INTO analyses (path, size, mod_time, verdict, verdict_label, padded, clipping, result, analyzed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		path, st.Size(), st.ModTime().UnixNano(), v.Verdict, v.VerdictLabel, v.PaddedBitDepth, v.Clipping, string(data), v.AnalyzedAt.UnixNano())
+	return err
+}
+
+// Get decodes the stored analysis of the file at path into result and
+// returns its verdict, or nil when there is none or the file has changed
+// since.
+func (s *Store) Get(path string, result any) (*Verdict, error) {
+	if s == nil {
+		return nil, nil
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var size, modTime, analyzedAt int64
+	var data string
+	v := &Verdict{}
+	err = s.db.QueryRow("SELECT size, mod_time, verdict, verdict_label, padded, clipping, result, analyzed_at FROM analyses WHERE path = ?", path).
+		Scan(&size, &modTime, &v.Verdict, &v.VerdictLabel, &v.PaddedBitDepth, &v.Clipping, &data, &analyzedAt)
+	if err == sql.ErrNoRows || err == nil && !current(st, size, modTime) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(data), result); err != nil {
+		return nil, err
+	}
+	v.AnalyzedAt = time.Unix(0, analyzedAt).UTC()
+	return v, nil
+}
+
+// Verdicts returns the verdicts of those of paths whose stored analysis
+// is current, by path.
+func (s *Store) Verdicts(paths []string) (map[string]Verdict, error) {
+	verdicts := map[string]Verdict{}
+	if s == nil || len(paths) == 0 {
+		return verdicts, nil
+	}
+	want := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		want[p] = true
+	}
+	// One pass over the table beats a query per listed file
+	rows, err := s.db.Query("SELECT path, size, mod_time, verdict, verdict_label, padded, clipping, analyzed_at FROM analyses")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var path string
+		var size, modTime, analyzedAt int64
+		var v Verdict
+		if err := rows.Scan(&path, &size, &modTime, &v.Verdict, &v.VerdictLabel, &v.PaddedBitDepth, &v.Clipping, &analyzedAt); err != nil {
+			return nil, err
+		}
+		if !want[path] {
+			continue
+		}
+		if st, err := os.Stat(path); err != nil || !current(st, size, modTime) {
+			continue
+		}
+		v.AnalyzedAt = time.Unix(0, analyzedAt).UTC()
+		verdicts[path] = v
+	}
+	return verdicts, rows.Err()
+}
+
+// Forget drops the analysis of the file at path, such as a deleted one.
+func (s *Store) Forget(path string) error {
+	if s == nil {
+		return nil
+	}
+	_, err := s.db.Exec("DELETE FROM analyses WHERE path = ?", path)
+	return err
+}
+
+// current reports whether a file as st is the one stamped size and
+// modTime.
+func current(st os.FileInfo, size, modTime int64) bool {
+	return st.Size() == size && st.ModTime().UnixNano() == modTime
+}
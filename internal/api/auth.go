@@ -0,0 +1,128 @@
+package api
+
+import (
+	"crypto/subtle"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Role is what a request's API token allows.
+type Role string
+
+const (
+	RoleAdmin Role = "admin" // everything; also every request when no API token is set
+	RoleGuest Role = "guest" // read-only: GET requests outside guestHidden
+)
+
+// guestHidden are GET routes guests can't use although they change nothing:
+// the config and settings hold credentials and API keys, the logs may quote
+// them, and manifests resolve stream URLs with the account.
+var guestHidden = []string{"/api/config", "/api/settings", "/api/logs", "/api/downloads/manifest"}
+
+// guestPathParams are the query parameters through which GET routes read
+// files (metadata, pictures, covers, validation, exports). A guest's must
+// name something inside the download folder or a library folder.
+var guestPathParams = []string{"path", "folder"}
+
+// roleKey is the fiber.Ctx local authGuard stores the request's Role under.
+const roleKey = "role"
+
+// requestToken returns the API token a request carries, either as an
+// "Authorization: Bearer" header or a ?token= query parameter (the latter
+// for WebSockets and links, which can't set headers).
+func requestToken(c *fiber.Ctx) string {
+	if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+		return token
+	}
+	return c.Query("token")
+}
+
+// tokenRole returns the Role token grants, or false if it grants none.
+// An empty admin token leaves the API open, so everyone is an admin.
+func tokenRole(token, admin, guest string) (Role, bool) {
+	switch {
+	case admin == "":
+		return RoleAdmin, true
+	case subtle.ConstantTimeCompare([]byte(token), []byte(admin)) == 1:
+		return RoleAdmin, true
+	case guest != "" && subtle.ConstantTimeCompare([]byte(token), []byte(guest)) == 1:
+		return RoleGuest, true
+	}
+	return "", false
+}
+
+// guestAllowed reports whether a guest may make a method request to path.
+// Paths are compared the way fiber routes them by default: ignoring case
+// and a trailing slash.
+func guestAllowed(method, path string) bool {
+	if method != fiber.MethodGet && method != fiber.MethodHead {
+		return false
+	}
+	path = strings.TrimSuffix(strings.ToLower(path), "/")
+	for _, hidden := range guestHidden {
+		if path == hidden || strings.HasPrefix(path, hidden+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// guestPathsAllowed reports whether every file path c's query names is
+// inside roots, or is one of them.
+func guestPathsAllowed(c *fiber.Ctx, roots []string) bool {
+	for _, param := range guestPathParams {
+		if p := c.Query(param); p != "" && !underRoot(roots, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// underRoot reports whether path is one of roots or inside one.
+func underRoot(roots []string, path string) bool {
+	path = filepath.Clean(path)
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Clean(root), path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// authGuard rejects API and WebSocket requests without a valid token (401),
+// and guests' requests that could change anything, reveal credentials or
+// read files outside roots, the download and library folders (403).
+// /api/health stays open for container health checks.
+func authGuard(admin, guest string, roots func() []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Path() == "/api/health" || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+		role, ok := tokenRole(requestToken(c), admin, guest)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid API token"})
+		}
+		if role == RoleGuest && (!guestAllowed(c.Method(), c.Path()) || !guestPathsAllowed(c, roots())) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "read-only access"})
+		}
+		c.Locals(roleKey, role)
+		return c.Next()
+	}
+}
+
+// handleGetAccess implements GET /api/access: the caller's role, and
+// whether the server requires a token at all, so the browser UI can ask for
+// one or mark itself read-only.
+func (s *Server) handleGetAccess(c *fiber.Ctx) error {
+	role, ok := c.Locals(roleKey).(Role)
+	if !ok {
+		role = RoleAdmin
+	}
+	return c.JSON(fiber.Map{"role": role, "required": s.apiToken != ""})
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+)
+
+func TestAuthGuard(t *testing.T) {
+	library := t.TempDir()
+	s := NewServer(ServerConfig{Config: &core.Config{DownloadFolder: library}, FrontendDir: t.TempDir(), APIToken: "admin-secret", GuestToken: "guest-secret"})
+
+	for _, tc := range []struct {
+		method, path, header string
+		want                 int
+	}{
+		{"GET", "/api/health", "", fiber.StatusOK},
+		{"GET", "/api/version", "", fiber.StatusUnauthorized},
+		{"GET", "/api/version", "Bearer wrong", fiber.StatusUnauthorized},
+		{"GET", "/api/version", "Bearer guest-secret", fiber.StatusOK},
+		{"GET", "/api/version?token=guest-secret", "", fiber.StatusOK},
+		{"GET", "/api/config", "Bearer guest-secret", fiber.StatusForbidden},
+		{"GET", "/API/Config/", "Bearer guest-secret", fiber.StatusForbidden},
+		{"GET", "/api/logs/levels", "Bearer guest-secret", fiber.StatusForbidden},
+		{"POST", "/api/downloads/pause", "Bearer guest-secret", fiber.StatusForbidden},
+		{"DELETE", "/api/files?path=/x.flac", "Bearer guest-secret", fiber.StatusForbidden},
+		{"GET", "/api/config", "Bearer admin-secret", fiber.StatusOK},
+		{"GET", "/api/files/metadata?path=/etc/passwd", "Bearer guest-secret", fiber.StatusForbidden},
+		{"GET", "/api/files/validate?path=" + library + "/../../etc/passwd", "Bearer guest-secret", fiber.StatusForbidden},
+		{"GET", "/api/files/export?folder=/", "Bearer guest-secret", fiber.StatusForbidden},
+		{"GET", "/api/files/export?folder=" + library, "Bearer guest-secret", fiber.StatusOK},
+		{"GET", "/ws", "", fiber.StatusUnauthorized},
+		{"GET", "/ws?token=guest-secret", "", fiber.StatusUpgradeRequired},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		resp, err := s.app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.method, tc.path, err)
+		}
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s (%q): status %d, want %d", tc.method, tc.path, tc.header, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestHandleGetAccess(t *testing.T) {
+	var got struct {
+		Role     Role `json:"role"`
+		Required bool `json:"required"`
+	}
+	doRequest(t, newTestServer(t), "GET", "/api/access", nil, &got)
+	if got.Role != RoleAdmin || got.Required {
+		t.Errorf("open server: %+v", got)
+	}
+
+	s := NewServer(ServerConfig{Config: &core.Config{}, APIToken: "admin-secret", GuestToken: "guest-secret"})
+	doRequest(t, s, "GET", "/api/access?token=guest-secret", nil, &got)
+	if got.Role != RoleGuest || !got.Required {
+		t.Errorf("guest: %+v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+)
+
+// How long browsers may reuse responses of cacheable endpoints before
+// revalidating them. Revalidation sends the ETag back, and an unchanged
+// response is a bodyless 304, which is what keeps a remote web UI snappy
+// over slow links.
+const (
+	// revalidate makes browsers check every time, for what can change at
+	// any moment: config and settings (from another client, too), sources,
+	// a file's cover, the library's covers.
+	revalidate time.Duration = 0
+	// listMaxAge is for lists that only change with an upgrade, such as
+	// filename tokens and rename templates.
+	listMaxAge = time.Minute
+	// hashedMaxAge is for responses addressed by their content's hash,
+	// which never change.
+	hashedMaxAge = 24 * time.Hour
+)
+
+// cacheFor lets browsers cache a GET endpoint's successful responses for
+// maxAge (see the constants above), tagging them with an ETag of their
+// body and answering a matching If-None-Match with 304 Not Modified.
+// Responses are "private": they may be behind an API token, so shared
+// caches must not keep them.
+func cacheFor(maxAge time.Duration) fiber.Handler {
+	control := fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds()))
+	if maxAge == revalidate {
+		control = "private, no-cache"
+	}
+	tag := etag.New()
+	return func(c *fiber.Ctx) error {
+		if err := tag(c); err != nil {
+			return err
+		}
+		if status := c.Response().StatusCode(); status == fiber.StatusOK || status == fiber.StatusNotModified {
+			c.Set(fiber.HeaderCacheControl, control)
+		}
+		return nil
+	}
+}
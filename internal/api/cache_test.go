@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCacheFor_ConditionalGet(t *testing.T) {
+	s := newTestServer(t)
+
+	resp := doRequest(t, s, "GET", "/api/filename-tokens", nil, nil)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if got := resp.Header.Get(fiber.HeaderCacheControl); got != "private, max-age=60" {
+		t.Errorf("Cache-Control = %q, want private, max-age=60", got)
+	}
+
+	req := httptest.NewRequest("GET", "/api/filename-tokens", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, etag)
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusNotModified {
+		t.Errorf("revalidation status = %d, want %d", resp.StatusCode, fiber.StatusNotModified)
+	}
+
+	// Config can change from another client, so it is revalidated every time.
+	resp = doRequest(t, s, "GET", "/api/config", nil, nil)
+	if got := resp.Header.Get(fiber.HeaderCacheControl); got != "private, no-cache" {
+		t.Errorf("config Cache-Control = %q, want private, no-cache", got)
+	}
+	// Errors are never cached.
+	resp = doRequest(t, s, "GET", "/api/covers", nil, nil)
+	if resp.StatusCode == fiber.StatusOK || resp.Header.Get(fiber.HeaderCacheControl) != "" {
+		t.Errorf("covers without a store: status %d, Cache-Control %q; want an uncached error", resp.StatusCode, resp.Header.Get(fiber.HeaderCacheControl))
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,6 +12,15 @@ import (
 	core "github.com/kushiemoon-dev/flacidal-core"
 
 	"flacidal/internal/app"
+	"flacidal/internal/configdiff"
+	"flacidal/internal/convert"
+	"flacidal/internal/fileerr"
+	"flacidal/internal/jobs"
+	"flacidal/internal/logging"
+	"flacidal/internal/lyricsfile"
+	"flacidal/internal/lyricsmatch"
+	"flacidal/internal/quality"
+	"flacidal/internal/timestamp"
 )
 
 // Health check
@@ -23,33 +33,86 @@ func (s *Server) handleHealth(c *fiber.Ctx) error {
 
 // Config handlers
 func (s *Server) handleGetConfig(c *fiber.Ctx) error {
-	return c.JSON(s.config)
+	return c.JSON(s.currentConfig())
 }
 
+// currentConfig returns the config in effect, which updateConfig may be
+// replacing meanwhile.
+func (s *Server) currentConfig() *core.Config {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	return s.config
+}
+
+// handleSaveConfig implements POST /api/config. Besides restartRequired it
+// responds with every changed setting and its effect, {"key", "effect"},
+// or with the refused one on a validation error (see configdiff). Mirrors
+// internal/app's App.SaveConfig.
 func (s *Server) handleSaveConfig(c *fiber.Ctx) error {
 	var config core.Config
 	if err := c.BodyParser(&config); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
-	if err := core.SaveConfig(&config); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	if err := app.NormalizeQualityConfig(&config); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error(), "changes": configdiff.Refused(err)})
 	}
-	s.config = &config
-	return c.JSON(fiber.Map{"success": true})
+	old, restart, status, err := s.updateConfig(func(*core.Config) (*core.Config, error) { return &config, nil })
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error(), "changes": configdiff.Refused(err)})
+	}
+	return c.JSON(fiber.Map{"success": true, "restartRequired": restart, "changes": app.ConfigChanges(old, &config, restart)})
 }
 
-func (s *Server) handleResetConfig(c *fiber.Ctx) error {
-	config := core.GetDefaultConfig()
-
-	// Preserve download folder if set — mirrors internal/app's App.ResetToDefaults.
-	if s.config != nil && s.config.DownloadFolder != "" {
-		config.DownloadFolder = s.config.DownloadFolder
+// updateConfig makes the config next builds from the current one current:
+// it applies it to the running subsystems, saves it and swaps it in. When
+// next, applying or saving fails the previous config stays in effect, and
+// the returned status says whose fault it was. The whole change holds
+// s.configMu, so concurrent requests apply one after the other, each on
+// top of the last. It returns the previous config and the settings that
+// only take effect after a restart (see app.ApplyConfig).
+func (s *Server) updateConfig(next func(old *core.Config) (*core.Config, error)) (*core.Config, []string, int, error) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	old := s.config
+	config, err := next(old)
+	if err != nil {
+		return old, nil, fiber.StatusBadRequest, err
+	}
+	targets := app.ConfigTargets{
+		Downloader:      s.downloader,
+		DownloadManager: s.downloadManager,
+		SourceManager:   s.sourceManager,
+		TidalSource:     s.tidalSource,
+		QobuzSource:     s.qobuzSource,
+		Gate:            &s.downloadGate,
+	}
+	restart, err := app.ApplyConfig(targets, old, config)
+	if err != nil {
+		return old, nil, fiber.StatusBadRequest, err
 	}
-
 	if err := core.SaveConfig(config); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		if old != nil {
+			app.ApplyConfig(targets, config, old) //nolint:errcheck // restoring the config that was running
+		}
+		return old, nil, fiber.StatusInternalServerError, err
 	}
 	s.config = config
+	return old, restart, fiber.StatusOK, nil
+}
+
+func (s *Server) handleResetConfig(c *fiber.Ctx) error {
+	var config *core.Config
+	_, _, status, err := s.updateConfig(func(old *core.Config) (*core.Config, error) {
+		config = core.GetDefaultConfig()
+		// Preserve download folder if set — mirrors internal/app's App.ResetToDefaults.
+		if old != nil && old.DownloadFolder != "" {
+			config.DownloadFolder = old.DownloadFolder
+		}
+		return config, nil
+	})
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
 	return c.JSON(config)
 }
 
@@ -154,6 +217,7 @@ func (s *Server) fetchContentByURL(rawURL string) (fiber.Map, int, error) {
 	if resolvedViaOdesli {
 		result["resolvedVia"] = "odesli"
 	}
+	app.NoteOrigin(s.origins, source.Name(), id, contentType, rawURL)
 
 	switch contentType {
 	case "track":
@@ -183,9 +247,11 @@ func (s *Server) fetchContentByURL(rawURL string) (fiber.Map, int, error) {
 		}
 		result["title"] = playlist.Title
 		result["creator"] = playlist.Creator
+		result["description"] = playlist.Description
 		result["coverUrl"] = playlist.CoverURL
 		result["tracks"] = playlist.Tracks
 	}
+	app.DescribeOrigin(s.origins, source.Name(), id, result)
 
 	return result, fiber.StatusOK, nil
 }
@@ -254,6 +320,8 @@ func (s *Server) handleQueueDownloads(c *fiber.Ctx) error {
 		Tracks      []core.TidalTrack `json:"tracks"`
 		OutputDir   string            `json:"outputDir"`
 		ContentName string            `json:"contentName"`
+		ContentID   string            `json:"contentId"`
+		ContentType string            `json:"contentType"`
 	}
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
@@ -267,10 +335,32 @@ func (s *Server) handleQueueDownloads(c *fiber.Ctx) error {
 		outputDir = core.GetDefaultDownloadFolder()
 	}
 
-	count := s.downloadManager.QueueMultiple(req.Tracks, outputDir)
+	count := s.queueTidalBatch(req.Tracks, outputDir, req.ContentName, req.ContentID, req.ContentType)
 	return c.JSON(fiber.Map{"queued": count})
 }
 
+// queueTidalBatch queues tracks into outputDir and starts their history
+// record. Shared by handleQueueDownloads and handleImportURLs.
+func (s *Server) queueTidalBatch(tracks []core.TidalTrack, outputDir, contentName, contentID, contentType string) int {
+	count := s.downloadManager.QueueMultiple(s.fitTidalTracks(tracks, outputDir), outputDir)
+	ids := make([]int, len(tracks))
+	for i, t := range tracks {
+		ids[i] = t.ID
+	}
+	if err := s.batches.Start(s.db, core.DownloadRecord{
+		TidalContentID:   contentID,
+		TidalContentName: contentName,
+		ContentType:      contentType,
+		TracksTotal:      count,
+	}, ids); err != nil {
+		s.component(logging.Downloads).Warn("failed to save download history", "err", err)
+	} else if err := s.origins.Commit(contentID); err != nil {
+		s.component(logging.Downloads).Warn("failed to save history origin", "err", err)
+	}
+	app.RememberTidalTracks(&s.postTracks, tracks, contentType)
+	return count
+}
+
 func (s *Server) handleQueueSingle(c *fiber.Ctx) error {
 	var req struct {
 		TrackID   int    `json:"trackId"`
@@ -294,6 +384,14 @@ func (s *Server) handleQueueSingle(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	if err := s.batches.Start(s.db, core.DownloadRecord{
+		TidalContentID:   strconv.Itoa(req.TrackID),
+		TidalContentName: req.Title,
+		ContentType:      "track",
+		TracksTotal:      1,
+	}, []int{req.TrackID}); err != nil {
+		s.component(logging.Downloads).Warn("failed to save download history", "err", err)
+	}
 
 	return c.JSON(fiber.Map{"success": true})
 }
@@ -305,19 +403,23 @@ func (s *Server) handleGetQueueStatus(c *fiber.Ctx) error {
 		"activeCount": s.downloadManager.GetActiveCount(),
 		"queueLength": s.downloadManager.GetQueueLength(),
 		"failedCount": s.downloadManager.GetFailedCount(),
+		"throughput":  app.QueueRate(&s.throughput, s.downloadManager),
+		"batches":     s.batches.Status(&s.jobs),
 	})
 }
 
 func (s *Server) handleGetDownloadOptions(c *fiber.Ctx) error {
+	config := s.currentConfig()
 	return c.JSON(fiber.Map{
-		"quality":         s.config.DownloadQuality,
-		"fileNameFormat":  s.config.FileNameFormat,
-		"organizeFolders": s.config.OrganizeFolders,
-		"embedCover":      s.config.EmbedCover,
-		"saveCoverFile":   s.config.SaveCoverFile,
-		"saveFolderCover": s.config.SaveFolderCover,
-		"autoAnalyze":     s.config.AutoAnalyze,
-		"embedLyrics":     s.config.EmbedLyrics,
+		"quality":             config.DownloadQuality,
+		"fileNameFormat":      config.FileNameFormat,
+		"organizeFolders":     config.OrganizeFolders,
+		"embedCover":          config.EmbedCover,
+		"saveCoverFile":       config.SaveCoverFile,
+		"saveFolderCover":     config.SaveFolderCover,
+		"autoAnalyze":         config.AutoAnalyze,
+		"embedLyrics":         config.EmbedLyrics,
+		"concurrentDownloads": app.DownloadWorkers(config.ConcurrentDownloads),
 	})
 }
 
@@ -329,23 +431,34 @@ func (s *Server) handleSetDownloadOptions(c *fiber.Ctx) error {
 		EmbedCover      bool   `json:"embedCover"`
 		SaveCoverFile   bool   `json:"saveCoverFile"`
 		AutoAnalyze     bool   `json:"autoAnalyze"`
+		// 0 or absent keeps the current value
+		ConcurrentDownloads int `json:"concurrentDownloads"`
 	}
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	s.config.DownloadQuality = req.Quality
-	s.config.FileNameFormat = req.FileNameFormat
-	s.config.OrganizeFolders = req.OrganizeFolders
-	s.config.EmbedCover = req.EmbedCover
-	s.config.SaveCoverFile = req.SaveCoverFile
-	s.config.AutoAnalyze = req.AutoAnalyze
-
-	if err := core.SaveConfig(s.config); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	dlQuality, err := quality.ParseOrDefault(req.Quality)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
-
-	return c.JSON(fiber.Map{"success": true})
+	_, restart, status, err := s.updateConfig(func(old *core.Config) (*core.Config, error) {
+		config := *old
+		if err := app.SetConcurrentDownloads(&config, req.ConcurrentDownloads); err != nil {
+			return nil, err
+		}
+		config.DownloadQuality = dlQuality.String()
+		config.FileNameFormat = req.FileNameFormat
+		config.OrganizeFolders = req.OrganizeFolders
+		config.EmbedCover = req.EmbedCover
+		config.SaveCoverFile = req.SaveCoverFile
+		config.AutoAnalyze = req.AutoAnalyze
+		return &config, nil
+	})
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "restartRequired": restart})
 }
 
 func (s *Server) handleRetryDownload(c *fiber.Ctx) error {
@@ -380,6 +493,8 @@ func (s *Server) handleCancelDownload(c *fiber.Ctx) error {
 	if err := s.downloadManager.CancelDownload(id); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	// A download waiting for its turn holds its worker until let go
+	s.downloadGate.Leave(id)
 
 	return c.JSON(fiber.Map{"success": true})
 }
@@ -409,7 +524,7 @@ func (s *Server) handleGetHistory(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(records)
+	return c.JSON(app.UTCRecords(records))
 }
 
 func (s *Server) handleGetHistoryFiltered(c *fiber.Ctx) error {
@@ -433,7 +548,7 @@ func (s *Server) handleGetHistoryFiltered(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(fiber.Map{
-		"records": records,
+		"records": app.UTCRecords(records),
 		"total":   total,
 	})
 }
@@ -448,7 +563,7 @@ func (s *Server) handleDeleteHistory(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Database not available"})
 	}
 
-	if err := s.db.DeleteDownloadRecord(id); err != nil {
+	if err := app.DeleteHistory(s.db, s.origins, id); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -463,6 +578,12 @@ func (s *Server) handleClearHistory(c *fiber.Ctx) error {
 	if err := s.db.ClearAllHistory(); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	if err := s.origins.Clear(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := s.timings.Clear(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	return c.JSON(fiber.Map{"success": true})
 }
@@ -484,16 +605,9 @@ func (s *Server) handleRefetchFromHistory(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "history record not found"})
 	}
 
-	var url string
-	switch record.ContentType {
-	case "playlist":
-		url = fmt.Sprintf("https://tidal.com/browse/playlist/%s", tidalContentID)
-	case "album":
-		url = fmt.Sprintf("https://tidal.com/browse/album/%s", tidalContentID)
-	case "track":
-		url = fmt.Sprintf("https://tidal.com/browse/track/%s", tidalContentID)
-	default:
-		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("unknown content type: %s", record.ContentType)})
+	url, err := app.RefetchURL(s.origins, record)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	result, status, err := s.fetchContentByURL(url)
@@ -507,7 +621,7 @@ func (s *Server) handleRefetchFromHistory(c *fiber.Ctx) error {
 func (s *Server) handleListFiles(c *fiber.Ctx) error {
 	folder := s.config.DownloadFolder
 	if folder == "" {
-		return c.JSON([]core.DownloadedFileInfo{})
+		return c.JSON([]app.FileInfo{})
 	}
 
 	files, err := core.ListFLACFiles(folder)
@@ -515,7 +629,7 @@ func (s *Server) handleListFiles(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(files)
+	return c.JSON(app.WithVerdicts(s.analyses, app.WithAudioInfo(&s.fileMeta, app.UTCFiles(files))))
 }
 
 func (s *Server) handleDeleteFile(c *fiber.Ctx) error {
@@ -525,8 +639,11 @@ func (s *Server) handleDeleteFile(c *fiber.Ctx) error {
 	}
 
 	if err := os.Remove(path); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return fileError(c, 500, fileerr.Wrap(err))
 	}
+	s.fileMeta.Forget(path)
+	s.analyses.Forget(path)                //nolint:errcheck // a stale row only costs space
+	app.RemoveFromLibrary(s.library, path) //nolint:errcheck // the next scan catches up
 
 	return c.JSON(fiber.Map{"success": true})
 }
@@ -537,7 +654,7 @@ func (s *Server) handleGetMetadata(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Path required"})
 	}
 
-	meta, err := core.ReadFLACMetadata(path)
+	meta, err := app.ReadFileMetadata(path)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -551,16 +668,16 @@ func (s *Server) handleGetCoverArt(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Path required"})
 	}
 
-	base64Data, mimeType, err := core.GetCoverArtBase64(path)
+	cover, err := app.FileCoverArt(path)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(fiber.Map{"data": base64Data, "mimeType": mimeType})
+	return c.JSON(cover)
 }
 
 func (s *Server) handleGetRenameTemplates(c *fiber.Ctx) error {
-	return c.JSON(core.GetRenameTemplates())
+	return c.JSON(app.RenameTemplates())
 }
 
 func (s *Server) handlePreviewRename(c *fiber.Ctx) error {
@@ -572,8 +689,7 @@ func (s *Server) handlePreviewRename(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	previews := core.PreviewRename(req.Files, req.Template)
-	return c.JSON(previews)
+	return c.JSON(app.PreviewRenames(s.currentSettings(), s.config.DownloadFolder, req.Files, req.Template))
 }
 
 func (s *Server) handleRenameFiles(c *fiber.Ctx) error {
@@ -585,8 +701,7 @@ func (s *Server) handleRenameFiles(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	results := core.RenameFiles(req.Files, req.Template)
-	return c.JSON(results)
+	return c.JSON(app.ApplyRenames(s.currentSettings(), s.library, s.config.DownloadFolder, req.Files, req.Template))
 }
 
 // Conversion handlers
@@ -606,6 +721,20 @@ func (s *Server) handleGetConversionFormats(c *fiber.Ctx) error {
 	return c.JSON(conv.GetFormats())
 }
 
+// conversionMessage wraps a conversion's progress for the WebSocket, which
+// dispatches on "type" like the Wails "conversion-progress" event.
+func conversionMessage(p convert.Progress) map[string]any {
+	return map[string]any{
+		"type":         "conversion-progress",
+		"file":         p.File,
+		"index":        p.Index,
+		"total":        p.Total,
+		"percent":      p.Percent,
+		"done":         p.Done,
+		"batchPercent": p.BatchPercent,
+	}
+}
+
 func (s *Server) handleConvertFiles(c *fiber.Ctx) error {
 	var req struct {
 		Files        []string `json:"files"`
@@ -618,8 +747,8 @@ func (s *Server) handleConvertFiles(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	conv := core.GetConverter()
-	if conv == nil {
+	ffmpeg, err := app.FFmpegPath()
+	if err != nil {
 		results := make([]core.ConversionResult, len(req.Files))
 		for i, f := range req.Files {
 			results[i] = core.ConversionResult{
@@ -637,7 +766,91 @@ func (s *Server) handleConvertFiles(c *fiber.Ctx) error {
 		DeleteSource: req.DeleteSource,
 	}
 
-	return c.JSON(conv.ConvertMultiple(req.Files, opts))
+	st := s.currentSettings()
+	conv := app.Converter(c.UserContext(), ffmpeg, st.FileConflict, app.ConversionWorkers(st), func(p convert.Progress) {
+		s.wsHub.Broadcast(conversionMessage(p))
+	})
+	return c.JSON(app.StrictResults(st, req.Files, func(files []string) []core.ConversionResult {
+		return app.ConvertAndTag(c.UserContext(), conv, files, opts)
+	}, func(path, reason string) core.ConversionResult {
+		return core.ConversionResult{SourcePath: path, Error: reason}
+	}))
+}
+
+// conversionJobMessage wraps a conversion job's progress event for the
+// WebSocket, which dispatches on "type" like the Wails
+// "conversion-job-progress" event.
+func conversionJobMessage(ev jobs.Event) map[string]any {
+	return map[string]any{
+		"type":      "conversion-job-progress",
+		"id":        ev.ID,
+		"kind":      ev.Kind,
+		"state":     ev.State,
+		"total":     ev.Total,
+		"processed": ev.Processed,
+		"failed":    ev.Failed,
+		"item":      ev.Item,
+	}
+}
+
+// handleStartConversion implements POST /api/convert/jobs. Body:
+// {"files": [...], "format": "mp3", "quality": "320k", "outputDir": "...",
+// "deleteSource": false}. Mirrors internal/app's App.StartConversion.
+func (s *Server) handleStartConversion(c *fiber.Ctx) error {
+	var req struct {
+		Files        []string `json:"files"`
+		Format       string   `json:"format"`
+		Quality      string   `json:"quality"`
+		OutputDir    string   `json:"outputDir"`
+		DeleteSource bool     `json:"deleteSource"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	opts := core.ConversionOptions{
+		Format:       req.Format,
+		Quality:      req.Quality,
+		OutputDir:    req.OutputDir,
+		DeleteSource: req.DeleteSource,
+	}
+	j, err := app.StartConversion(&s.conversionJobs, req.Files, opts, s.currentSettings(), func(p convert.Progress) {
+		s.wsHub.Broadcast(conversionMessage(p))
+	})
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Server).Info("queued conversion job", "id", j.ID, "files", j.Total, "format", req.Format)
+	return c.Status(fiber.StatusAccepted).JSON(j)
+}
+
+// handleListConversionJobs implements GET /api/convert/jobs. Mirrors
+// internal/app's App.ListConversionJobs.
+func (s *Server) handleListConversionJobs(c *fiber.Ctx) error {
+	return c.JSON(s.conversionJobs.List())
+}
+
+// handleGetConversionJob implements GET /api/convert/jobs/:id. Mirrors
+// internal/app's App.GetConversionJob.
+func (s *Server) handleGetConversionJob(c *fiber.Ctx) error {
+	j, ok := s.conversionJobs.Get(c.Params("id"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": jobs.ErrNotFound.Error()})
+	}
+	return c.JSON(j)
+}
+
+// handleCancelConversionJob implements POST /api/convert/jobs/:id/cancel.
+// Mirrors internal/app's App.CancelConversionJob.
+func (s *Server) handleCancelConversionJob(c *fiber.Ctx) error {
+	j, err := s.conversionJobs.Cancel(c.Params("id"))
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Server).Info("cancelled conversion job", "id", j.ID)
+	return c.JSON(j)
 }
 
 // Lyrics handlers
@@ -650,7 +863,7 @@ func (s *Server) handleFetchLyrics(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Title and artist required"})
 	}
 
-	lyrics, err := s.lyricsClient.SearchLyrics(title, artist, duration)
+	lyrics, err := app.LookupLyrics(s.lyricsCache, s.lyricsClient, title, artist, duration)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -680,11 +893,7 @@ func (s *Server) handleFetchLyricsForFile(c *fiber.Ctx) error {
 // fetchLyricsForFile reads a FLAC file's metadata and looks up matching
 // lyrics. Mirrors internal/app's App.FetchLyricsForFile.
 func (s *Server) fetchLyricsForFile(filePath string) (*core.Lyrics, error) {
-	meta, err := core.ReadFLACMetadata(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read metadata: %w", err)
-	}
-	return s.lyricsClient.FetchLyricsForFile(meta)
+	return app.LookupFileLyrics(s.lyricsCache, s.lyricsClient, filePath)
 }
 
 func (s *Server) handleEmbedLyrics(c *fiber.Ctx) error {
@@ -697,14 +906,43 @@ func (s *Server) handleEmbedLyrics(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	tagger := core.NewFLACTagger()
-	if err := tagger.EmbedLyrics(req.FilePath, req.Plain, req.Synced); err != nil {
+	cfg := s.currentSettings()
+	if err := app.CheckStrict(cfg, req.FilePath); err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := app.SaveLyrics(cfg.LyricsOutput, req.FilePath, req.Plain, req.Synced); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(fiber.Map{"success": true})
 }
 
+// handleSaveLyricsSidecar implements POST /api/lyrics/sidecar. Mirrors
+// internal/app's App.SaveLyricsSidecar.
+func (s *Server) handleSaveLyricsSidecar(c *fiber.Ctx) error {
+	var req struct {
+		FilePath string `json:"filePath"`
+		Plain    string `json:"plain"`
+		Synced   string `json:"synced"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.FilePath == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "File path required"})
+	}
+
+	path, err := lyricsfile.Write(req.FilePath, req.Plain, req.Synced)
+	if errors.Is(err, lyricsfile.ErrEmpty) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"path": path})
+}
+
 func (s *Server) handleFetchAndEmbedLyrics(c *fiber.Ctx) error {
 	var req struct {
 		FilePath string `json:"filePath"`
@@ -724,21 +962,31 @@ func (s *Server) handleFetchAndEmbedLyrics(c *fiber.Ctx) error {
 	return c.JSON(lyrics)
 }
 
-// fetchAndEmbedLyrics fetches lyrics for a file and embeds them, optionally
-// saving a sidecar .lrc/.txt file when enabled in config. Mirrors
+// fetchAndEmbedLyrics fetches lyrics for a file and embeds them (or writes
+// them to its .lrc sidecar, per Settings.LyricsOutput), optionally saving a
+// sidecar .lrc/.txt file when enabled in config. Mirrors
 // internal/app's App.FetchAndEmbedLyrics.
 func (s *Server) fetchAndEmbedLyrics(filePath string) (*core.Lyrics, error) {
-	lyrics, err := s.fetchLyricsForFile(filePath)
+	cfg := s.currentSettings()
+	if err := app.CheckStrict(cfg, filePath); err != nil {
+		return nil, err
+	}
+	if lyricsmatch.IsInstrumental(filePath) {
+		return nil, lyricsmatch.ErrInstrumental
+	}
+	lyrics, match, err := app.MatchFileLyrics(s.lyricsCache, s.lyricsClient, filePath)
 	if err != nil {
 		return nil, err
 	}
+	if err := app.AcceptLyrics(filePath, match); err != nil {
+		return lyrics, err
+	}
 
-	tagger := core.NewFLACTagger()
-	if err := tagger.EmbedLyrics(filePath, lyrics.Plain, lyrics.Synced); err != nil {
+	if err := app.SaveLyrics(cfg.LyricsOutput, filePath, lyrics.Plain, lyrics.Synced); err != nil {
 		return lyrics, err
 	}
 
-	if s.config != nil && s.config.SaveLyricsFile {
+	if s.config != nil && s.config.SaveLyricsFile && !cfg.LyricsOutput.File() {
 		core.SaveLyricsFile(filePath, lyrics.Synced, lyrics.Plain) //nolint:errcheck // best-effort sidecar file, embedding already succeeded
 	}
 
@@ -761,7 +1009,10 @@ func (s *Server) handleFetchAndEmbedMultiple(c *fiber.Ctx) error {
 		}
 
 		lyrics, err := s.fetchAndEmbedLyrics(filePath)
-		if err != nil {
+		if errors.Is(err, lyricsmatch.ErrInstrumental) {
+			result["success"] = true
+			result["instrumental"] = true
+		} else if err != nil {
 			result["error"] = err.Error()
 		} else {
 			result["success"] = true
@@ -775,6 +1026,18 @@ func (s *Server) handleFetchAndEmbedMultiple(c *fiber.Ctx) error {
 	return c.JSON(results)
 }
 
+// handlePreviewLyricsEmbed implements POST /api/lyrics/fetch-embed/preview.
+// Mirrors internal/app's App.PreviewLyricsEmbed.
+func (s *Server) handlePreviewLyricsEmbed(c *fiber.Ctx) error {
+	var req struct {
+		FilePaths []string `json:"filePaths"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(app.PreviewLyrics(s.lyricsCache, s.lyricsClient, req.FilePaths))
+}
+
 // Qobuz handlers
 func (s *Server) handleUpdateQobuzCredentials(c *fiber.Ctx) error {
 	var req struct {
@@ -829,6 +1092,12 @@ func (s *Server) handleGetVersion(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"version": "1.0.0"})
 }
 
+// handleGetLocaleHint implements GET /api/locale.
+// Mirrors internal/app's App.GetLocaleHint.
+func (s *Server) handleGetLocaleHint(c *fiber.Ctx) error {
+	return c.JSON(timestamp.Hint())
+}
+
 func (s *Server) handleGetLogs(c *fiber.Ctx) error {
 	// Implement log retrieval
 	return c.JSON([]core.LogEntry{})
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/logging"
+)
+
+// handleGetAcoustIDInfo implements GET /api/files/acoustid/status. Mirrors
+// internal/app's App.GetAcoustIDInfo.
+func (s *Server) handleGetAcoustIDInfo(c *fiber.Ctx) error {
+	return c.JSON(app.AcoustIDInfo(c.UserContext(), s.currentSettings()))
+}
+
+// handlePreviewAcoustIDTags implements POST /api/files/acoustid/preview.
+// Body: {"files": [...]}. Mirrors internal/app's App.PreviewAcoustIDTags.
+func (s *Server) handlePreviewAcoustIDTags(c *fiber.Ctx) error {
+	return s.acoustIDTags(c, true)
+}
+
+// handleAcoustIDTags implements POST /api/files/acoustid. Same body as the
+// preview. Mirrors internal/app's App.AcoustIDTags.
+func (s *Server) handleAcoustIDTags(c *fiber.Ctx) error {
+	return s.acoustIDTags(c, false)
+}
+
+func (s *Server) acoustIDTags(c *fiber.Ctx, dryRun bool) error {
+	var req struct {
+		Files []string `json:"files"`
+	}
+	if err := c.BodyParser(&req); err != nil || len(req.Files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "files are required"})
+	}
+	results := app.AcoustIDTags(c.UserContext(), s.currentSettings(), req.Files, dryRun)
+	if !dryRun {
+		s.component(logging.Downloads).Info("tagged from AcoustID", "written", app.TagsWritten(results), "files", len(req.Files))
+	}
+	return c.JSON(results)
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleAcoustIDTags(t *testing.T) {
+	s := newTestServer(t)
+	for _, path := range []string{"/api/files/acoustid/preview", "/api/files/acoustid"} {
+		if resp := doRequest(t, s, "POST", path, map[string]any{"files": []string{}}, nil); resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", path, resp.StatusCode)
+		}
+	}
+
+	var info struct {
+		KeySet bool `json:"keySet"`
+	}
+	if resp := doRequest(t, s, "GET", "/api/files/acoustid/status", nil, &info); resp.StatusCode != fiber.StatusOK || info.KeySet {
+		t.Errorf("status: %d, %+v; want no key in fresh settings", resp.StatusCode, info)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +10,12 @@ import (
 	core "github.com/kushiemoon-dev/flacidal-core"
 
 	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/analysis"
+	"flacidal/internal/analysisstore"
+	"flacidal/internal/app"
+	"flacidal/internal/jobs"
+	"flacidal/internal/logging"
 )
 
 // analyzeRequest accepts either a JSON body {"path": "/abs/path.flac"}
@@ -28,7 +35,12 @@ func (s *Server) handleAnalyzeFileImpl(c *fiber.Ctx) error {
 		defer cleanupTemp(tempPath)
 	}
 
-	result, err := core.AnalyzeFLAC(filePath)
+	st, store := s.currentSettings(), s.analyses
+	if tempPath != "" {
+		// The upload is deleted afterwards
+		st.LoudnessTags, store = false, nil
+	}
+	result, err := app.Analyze(c.UserContext(), filePath, st, store)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -37,10 +49,12 @@ func (s *Server) handleAnalyzeFileImpl(c *fiber.Ctx) error {
 }
 
 // handleAnalyzeMultipleImpl implements POST /api/analyze/multiple.
-// Accepts {"paths": ["/abs/path1.flac", "/abs/path2.flac"]}.
+// Accepts {"paths": ["/abs/path1.flac", "/abs/path2.flac"], "force": false};
+// unchanged files reuse their saved analysis unless force is set.
 func (s *Server) handleAnalyzeMultipleImpl(c *fiber.Ctx) error {
 	var req struct {
 		Paths []string `json:"paths"`
+		Force bool     `json:"force"`
 	}
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
@@ -49,7 +63,7 @@ func (s *Server) handleAnalyzeMultipleImpl(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "paths array is required"})
 	}
 
-	results := core.AnalyzeMultiple(req.Paths)
+	results := app.AnalyzeMultiple(c.UserContext(), req.Paths, req.Force, s.currentSettings(), s.analyses)
 
 	responses := make([]fiber.Map, 0, len(results))
 	for _, r := range results {
@@ -75,7 +89,194 @@ func (s *Server) handleQuickAnalyzeImpl(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(buildAnalyzeResponse(result))
+	return c.JSON(buildAnalyzeResponse(&app.AnalysisResult{AnalysisResult: *result}))
+}
+
+// handleVerify implements POST /api/analyze/verify.
+// Body: {"paths": [...]} or {"folder": "..."}. Mirrors internal/app's
+// App.VerifyFiles and App.VerifyFolder.
+func (s *Server) handleVerify(c *fiber.Ctx) error {
+	var req struct {
+		Paths  []string `json:"paths"`
+		Folder string   `json:"folder"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	var results []analysis.Verification
+	var err error
+	switch {
+	case req.Folder != "":
+		results, err = app.VerifyFolder(c.UserContext(), req.Folder)
+	case len(req.Paths) > 0:
+		results, err = app.VerifyFLACs(c.UserContext(), req.Paths)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "paths or folder is required"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	damaged := 0
+	for _, v := range results {
+		if !v.OK {
+			damaged++
+		}
+	}
+	s.component(logging.Server).Info("verified files", "files", len(results), "damaged", damaged)
+	return c.JSON(results)
+}
+
+// handleCompareFiles implements POST /api/analyze/compare.
+// Body: {"a": "...", "b": "..."}. Mirrors internal/app's App.CompareFiles.
+func (s *Server) handleCompareFiles(c *fiber.Ctx) error {
+	var req struct {
+		A string `json:"a"`
+		B string `json:"b"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.A == "" || req.B == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "a and b are required"})
+	}
+	if !filepath.IsAbs(req.A) || !filepath.IsAbs(req.B) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "paths must be absolute"})
+	}
+	result, err := app.CompareFiles(c.UserContext(), req.A, req.B, s.currentSettings(), s.analyses)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Server).Info("compared files", "a", req.A, "b", req.B, "keep", result.Keep, "reason", result.Reason)
+	return c.JSON(result)
+}
+
+// handleCheckAlbum implements POST /api/analyze/album.
+// Body: {"folder": "..."}. Mirrors internal/app's App.CheckAlbum.
+func (s *Server) handleCheckAlbum(c *fiber.Ctx) error {
+	var req struct {
+		Folder string `json:"folder"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	report, err := app.CheckAlbum(c.UserContext(), req.Folder, s.currentSettings(), s.analyses)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Server).Info("checked album", "folder", req.Folder, "tracks", len(report.Tracks), "issues", len(report.Issues))
+	return c.JSON(report)
+}
+
+// analysisMessage wraps an analysis job's progress event for the WebSocket,
+// which dispatches on "type" like the Wails "analysis-progress" event.
+func analysisMessage(ev jobs.Event) map[string]any {
+	return map[string]any{
+		"type":      "analysis-progress",
+		"id":        ev.ID,
+		"kind":      ev.Kind,
+		"state":     ev.State,
+		"total":     ev.Total,
+		"processed": ev.Processed,
+		"failed":    ev.Failed,
+		"summary":   ev.Summary,
+		"item":      ev.Item,
+	}
+}
+
+// handleStartAnalysis implements POST /api/analyze/jobs.
+// Body: {"paths": [...], "force": false}. Mirrors internal/app's
+// App.StartAnalysis.
+func (s *Server) handleStartAnalysis(c *fiber.Ctx) error {
+	var req struct {
+		Paths []string `json:"paths"`
+		Force bool     `json:"force"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	j, err := app.StartAnalysis(&s.analysisJobs, req.Paths, req.Force, s.currentSettings(), s.analyses)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Server).Info("queued analysis job", "id", j.ID, "files", j.Total)
+	return c.Status(fiber.StatusAccepted).JSON(j)
+}
+
+// handleAnalyzeFolder implements POST /api/analyze/folder.
+// Body: {"folder": "...", "recursive": true, "force": false}. Mirrors
+// internal/app's App.AnalyzeFolder.
+func (s *Server) handleAnalyzeFolder(c *fiber.Ctx) error {
+	var req struct {
+		Folder    string `json:"folder"`
+		Recursive bool   `json:"recursive"`
+		Force     bool   `json:"force"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	j, err := app.AnalyzeFolder(c.UserContext(), &s.analysisJobs, req.Folder, req.Recursive, req.Force, s.currentSettings(), s.analyses)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Server).Info("queued analysis job", "id", j.ID, "files", j.Total, "folder", req.Folder)
+	return c.Status(fiber.StatusAccepted).JSON(j)
+}
+
+// handleListAnalysisJobs implements GET /api/analyze/jobs. Mirrors
+// internal/app's App.ListAnalysisJobs.
+func (s *Server) handleListAnalysisJobs(c *fiber.Ctx) error {
+	return c.JSON(s.analysisJobs.List())
+}
+
+// handleGetAnalysisJob implements GET /api/analyze/jobs/:id. Mirrors
+// internal/app's App.GetAnalysisJob.
+func (s *Server) handleGetAnalysisJob(c *fiber.Ctx) error {
+	j, ok := s.analysisJobs.Get(c.Params("id"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": jobs.ErrNotFound.Error()})
+	}
+	return c.JSON(j)
+}
+
+// handleCancelAnalysisJob implements POST /api/analyze/jobs/:id/cancel.
+// Mirrors internal/app's App.CancelAnalysisJob.
+func (s *Server) handleCancelAnalysisJob(c *fiber.Ctx) error {
+	j, err := s.analysisJobs.Cancel(c.Params("id"))
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Server).Info("cancelled analysis job", "id", j.ID)
+	return c.JSON(j)
+}
+
+// handleExportQualityReport implements GET /api/analyze/report?format=csv|json.
+// Returns the library's quality report as an attachment; format defaults
+// to csv. Mirrors internal/app's App.ExportQualityReport, minus the native
+// OS save dialog.
+func (s *Server) handleExportQualityReport(c *fiber.Ctx) error {
+	format := c.Query("format", "csv")
+	if err := analysisstore.ValidateFormat(format); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	r, err := app.QualityReport(s.analyses)
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+	}
+	data, ext, err := r.Encode(format)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	contentType := "application/json"
+	if format == "csv" {
+		contentType = "text/csv"
+	}
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, app.QualityReportFileName+ext))
+	return c.Send(data)
 }
 
 // RegisterAnalyzerRoutes wires the real analyzer handlers onto an existing
@@ -86,6 +287,15 @@ func RegisterAnalyzerRoutes(router fiber.Router, s *Server) {
 	router.Post("/analyze", s.handleAnalyzeFileImpl)
 	router.Post("/analyze/multiple", s.handleAnalyzeMultipleImpl)
 	router.Post("/analyze/quick", s.handleQuickAnalyzeImpl)
+	router.Post("/analyze/verify", s.handleVerify)
+	router.Post("/analyze/compare", s.handleCompareFiles)
+	router.Post("/analyze/album", s.handleCheckAlbum)
+	router.Get("/analyze/report", s.handleExportQualityReport)
+	router.Get("/analyze/jobs", s.handleListAnalysisJobs)
+	router.Post("/analyze/jobs", s.handleStartAnalysis)
+	router.Post("/analyze/folder", s.handleAnalyzeFolder)
+	router.Get("/analyze/jobs/:id", s.handleGetAnalysisJob)
+	router.Post("/analyze/jobs/:id/cancel", s.handleCancelAnalysisJob)
 }
 
 // --- helpers ----------------------------------------------------------------
@@ -121,13 +331,16 @@ func resolveAnalyzePath(c *fiber.Ctx) (filePath, tempPath string, err error) {
 	return req.Path, "", nil
 }
 
-// buildAnalyzeResponse converts core.AnalysisResult → AnalyzeResponse fiber.Map.
-func buildAnalyzeResponse(r *core.AnalysisResult) fiber.Map {
+// buildAnalyzeResponse converts app.AnalysisResult → AnalyzeResponse fiber.Map.
+func buildAnalyzeResponse(r *app.AnalysisResult) fiber.Map {
 	msg := r.Details
 	if msg == "" {
-		if r.IsTrueLossless {
+		switch {
+		case r.IsTrueLossless:
 			msg = "Authentic lossless"
-		} else {
+		case r.Verdict == "upsampled":
+			msg = fmt.Sprintf("%s — spectral cutoff: %d Hz", r.VerdictLabel, r.SpectrumCutoff)
+		default:
 			msg = fmt.Sprintf("Upscaled lossy detected — spectral cutoff: %d Hz", r.SpectrumCutoff)
 		}
 	}
@@ -143,6 +356,36 @@ func buildAnalyzeResponse(r *core.AnalysisResult) fiber.Map {
 		"fileName":       r.FileName,
 		"sampleRate":     r.SampleRate,
 		"bitDepth":       r.BitDepth,
+
+		"effectiveBitDepth": r.EffectiveBitDepth,
+		"paddedBitDepth":    r.PaddedBitDepth,
+		"bitDepthLabel":     r.BitDepthLabel,
+		"loudness":          r.Loudness,
+		"loudnessTagged":    r.LoudnessTagged,
+		"clippedSamples":    r.ClippedSamples,
+		"clippedPercent":    r.ClippedPercent,
+		"interSamplePeaks":  r.InterSamplePeaks,
+		"clipping":          r.Clipping,
+		"clippingLabel":     r.ClippingLabel,
+		"mqa":               r.MQA,
+		"mqaLabel":          r.MQALabel,
+		"thresholds":        r.Thresholds,
+		"cached":            r.Cached,
+
+		"mqaOriginalSampleRate": r.MQAOriginalSampleRate,
+
+		"trailingSilence": r.TrailingSilence,
+		"truncated":       r.Truncated,
+		"missingSeconds":  r.MissingSeconds,
+		"truncatedLabel":  r.TruncatedLabel,
+
+		"lossyCodec":      r.LossyCodec,
+		"lossyCodecLabel": r.LossyCodecLabel,
+
+		"correlation": r.Correlation,
+		"fakeStereo":  r.FakeStereo,
+		"outOfPhase":  r.OutOfPhase,
+		"stereoLabel": r.StereoLabel,
 	}
 }
 
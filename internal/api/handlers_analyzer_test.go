@@ -0,0 +1,73 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleVerify_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/analyze/verify", map[string]any{}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status without paths or folder = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestHandleCompareFiles_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/analyze/compare", map[string]any{"a": "/music/a.flac"}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("without b = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "POST", "/api/analyze/compare", map[string]any{"a": "a.flac", "b": "b.flac"}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("relative paths = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestHandleCheckAlbum_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/analyze/album", map[string]any{"folder": t.TempDir()}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("folder without FLACs = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestAnalysisJobs_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/analyze/jobs", map[string]any{"paths": []string{}}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("start without paths = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "GET", "/api/analyze/jobs/nope", nil, nil)
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("get unknown job = %d, want 404", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "POST", "/api/analyze/jobs/nope/cancel", nil, nil)
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("cancel unknown job = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestHandleAnalyzeFolder_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/analyze/folder", map[string]any{}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("without a folder = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "POST", "/api/analyze/folder", map[string]any{"folder": t.TempDir(), "recursive": true}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("folder without FLACs = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestHandleExportQualityReport(t *testing.T) {
+	s := newTestServer(t)
+	if resp := doRequest(t, s, "GET", "/api/analyze/report?format=xml", nil, nil); resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("unknown format = %d, want 400", resp.StatusCode)
+	}
+	if resp := doRequest(t, s, "GET", "/api/analyze/report", nil, nil); resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("without an analysis store = %d, want 503", resp.StatusCode)
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/jobs"
+	"flacidal/internal/logging"
+)
+
+// batchMessage wraps a batch progress event for the WebSocket, which
+// dispatches on "type" like the Wails "batch-progress" event.
+func batchMessage(ev jobs.Event) map[string]any {
+	return map[string]any{
+		"type":      "batch-progress",
+		"id":        ev.ID,
+		"kind":      ev.Kind,
+		"state":     ev.State,
+		"total":     ev.Total,
+		"processed": ev.Processed,
+		"failed":    ev.Failed,
+		"item":      ev.Item,
+	}
+}
+
+// handleStartBatch implements POST /api/batches. Body: an app.BatchRequest,
+// e.g. {"op": "move", "files": [...], "dest": "..."}. Mirrors internal/app's
+// App.StartBatch.
+func (s *Server) handleStartBatch(c *fiber.Ctx) error {
+	var req app.BatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	req.DownloadFolder = s.config.DownloadFolder
+	req.Library = s.library
+	work, err := app.BatchWork(s.currentSettings(), req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	b := s.fileBatches.Start(req.Op, req.Files, work, jobs.Options{Atomic: req.Atomic})
+	s.component(logging.Downloads).Info("started batch", "id", b.ID, "op", b.Kind, "files", b.Total)
+	return c.Status(fiber.StatusAccepted).JSON(b)
+}
+
+// handleListBatches implements GET /api/batches. Mirrors internal/app's
+// App.ListBatches.
+func (s *Server) handleListBatches(c *fiber.Ctx) error {
+	return c.JSON(s.fileBatches.List())
+}
+
+// handleGetBatch implements GET /api/batches/:id. Mirrors internal/app's
+// App.GetBatch.
+func (s *Server) handleGetBatch(c *fiber.Ctx) error {
+	b, ok := s.fileBatches.Get(c.Params("id"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": jobs.ErrNotFound.Error()})
+	}
+	return c.JSON(b)
+}
+
+// handleUndoBatch implements POST /api/batches/:id/undo. Mirrors
+// internal/app's App.UndoBatch.
+func (s *Server) handleUndoBatch(c *fiber.Ctx) error {
+	b, err := s.fileBatches.Undo(c.Params("id"))
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Downloads).Info("undid batch", "id", b.ID, "op", b.Kind)
+	return c.JSON(b)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/jobs"
+)
+
+func TestHandleBatch_MoveAndUndo(t *testing.T) {
+	s := newTestServer(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.flac")
+	if err := os.WriteFile(src, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "moved")
+
+	var started jobs.Job
+	resp := doRequest(t, s, "POST", "/api/batches", map[string]any{"op": "move", "files": []string{src}, "dest": dest}, &started)
+	if resp.StatusCode != fiber.StatusAccepted || started.ID == "" {
+		t.Fatalf("start: status %d, %+v", resp.StatusCode, started)
+	}
+
+	var b jobs.Job
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		doRequest(t, s, "GET", "/api/batches/"+started.ID, nil, &b)
+		if b.State.Finished() || time.Now().After(deadline) {
+			break
+		}
+	}
+	if b.State != jobs.Done || !b.Items[0].Done || b.Items[0].Output != filepath.Join(dest, "a.flac") {
+		t.Fatalf("batch = %+v", b)
+	}
+
+	resp = doRequest(t, s, "POST", "/api/batches/"+b.ID+"/undo", nil, &b)
+	if resp.StatusCode != fiber.StatusOK || b.State != jobs.Undone {
+		t.Fatalf("undo: status %d, %+v", resp.StatusCode, b)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("file not moved back: %v", err)
+	}
+	if resp := doRequest(t, s, "POST", "/api/batches/"+b.ID+"/undo", nil, nil); resp.StatusCode != fiber.StatusConflict {
+		t.Errorf("second undo: status %d, want 409", resp.StatusCode)
+	}
+}
+
+func TestHandleBatch_Validation(t *testing.T) {
+	s := newTestServer(t)
+	for name, body := range map[string]map[string]any{
+		"unknown op":   {"op": "shred", "files": []string{"a.flac"}},
+		"no files":     {"op": "move", "dest": "/tmp"},
+		"move no dest": {"op": "move", "files": []string{"a.flac"}},
+	} {
+		if resp := doRequest(t, s, "POST", "/api/batches", body, nil); resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, resp.StatusCode)
+		}
+	}
+	if resp := doRequest(t, s, "GET", "/api/batches/nope", nil, nil); resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("unknown batch: status %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/logging"
+)
+
+// completenessRequest is the body of the album completeness endpoints.
+type completenessRequest struct {
+	Dir string `json:"dir"`
+	URL string `json:"url"` // optional for checks; see app.CheckCompleteness
+}
+
+// handleCheckAlbumCompleteness implements POST /api/downloads/completeness.
+// Body: {"dir": "...", "url": "..."}. Mirrors internal/app's
+// App.CheckAlbumCompleteness.
+func (s *Server) handleCheckAlbumCompleteness(c *fiber.Ctx) error {
+	var req completenessRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.Dir == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "dir is required"})
+	}
+	if s.sourceManager == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "source manager not initialized"})
+	}
+	report, err := app.CheckCompleteness(s.sourceManager, req.Dir, req.URL, s.currentSettings().MatchNormalization)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// handleCheckHistoryCompleteness implements GET
+// /api/history/completeness/:id. Mirrors internal/app's
+// App.CheckHistoryCompleteness.
+func (s *Server) handleCheckHistoryCompleteness(c *fiber.Ctx) error {
+	if s.db == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Database not available"})
+	}
+	if s.sourceManager == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "source manager not initialized"})
+	}
+	record, err := s.db.GetDownloadRecord(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if record == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "history record not found"})
+	}
+	url, err := app.RefetchURL(s.origins, record)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	st := s.currentSettings()
+	dir := app.HistoryFolder(s.downloadFolder(), record, st.FilenameUnicode)
+	report, err := app.CheckCompleteness(s.sourceManager, dir, url, st.MatchNormalization)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// handleQueueMissingTracks implements POST
+// /api/downloads/completeness/queue. Body: {"dir": "...", "url": "..."}.
+// Mirrors internal/app's App.QueueMissingTracks.
+func (s *Server) handleQueueMissingTracks(c *fiber.Ctx) error {
+	var req completenessRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.Dir == "" || req.URL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "dir and url are required"})
+	}
+	if s.downloadManager == nil || s.sourceManager == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "download manager not initialized"})
+	}
+	content, missing, err := app.MissingTracks(s.sourceManager, req.Dir, req.URL, s.currentSettings().MatchNormalization)
+	if err == nil {
+		err = app.CheckDownloadable(content.Source)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var queued int
+	if content.Source == "qobuz" {
+		if queued, err = s.queueQobuzBatch(missing, req.Dir, "", "", content.Type); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	} else {
+		queued = s.queueTidalBatch(app.TidalTracksFromSource(missing), req.Dir, "", "", content.Type)
+	}
+	s.component(logging.Downloads).Info("queued missing tracks", "title", content.Title, "dir", req.Dir, "queued", queued)
+	return c.JSON(fiber.Map{"queued": queued})
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleCompleteness_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/downloads/completeness", map[string]any{"url": "https://tidal.com/browse/album/1"}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("check without dir = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "POST", "/api/downloads/completeness/queue", map[string]any{"dir": t.TempDir()}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("queue without url = %d, want 400", resp.StatusCode)
+	}
+}
@@ -1,11 +1,16 @@
 package api
 
 import (
+	"fmt"
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/configdiff"
 )
 
 // Tests for the ResetConfig / DownloadOptions parity fixes: preserving the
@@ -69,3 +74,151 @@ func TestHandleSetDownloadOptions_PersistsAutoAnalyze(t *testing.T) {
 		t.Error("config.AutoAnalyze = false, want true to have been persisted")
 	}
 }
+
+func TestHandleSetDownloadOptions_Quality(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	s := NewServer(ServerConfig{Config: &core.Config{DownloadQuality: "HI_RES"}})
+
+	resp := doRequest(t, s, "POST", "/api/downloads/options", map[string]interface{}{
+		"quality": "ultra",
+	}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+	if s.config.DownloadQuality != "HI_RES" {
+		t.Errorf("rejected quality overwrote config: %q", s.config.DownloadQuality)
+	}
+
+	resp = doRequest(t, s, "POST", "/api/downloads/options", map[string]interface{}{
+		"quality": "cd",
+	}, nil)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+	if s.config.DownloadQuality != "LOSSLESS" {
+		t.Errorf("DownloadQuality = %q, want canonical LOSSLESS", s.config.DownloadQuality)
+	}
+}
+
+func TestHandleSetDownloadOptions_ConcurrentDownloads(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	s := NewServer(ServerConfig{Config: &core.Config{}})
+
+	var body map[string]interface{}
+	doRequest(t, s, "POST", "/api/downloads/options", map[string]interface{}{"concurrentDownloads": 6}, &body)
+	if restart, _ := body["restartRequired"].([]interface{}); s.config.ConcurrentDownloads != 6 || len(restart) != 0 {
+		t.Errorf("ConcurrentDownloads = %d, body = %v", s.config.ConcurrentDownloads, body)
+	}
+
+	doRequest(t, s, "GET", "/api/downloads/options", nil, &body)
+	if body["concurrentDownloads"] != float64(6) {
+		t.Errorf("GET body = %v", body)
+	}
+
+	resp := doRequest(t, s, "POST", "/api/downloads/options", map[string]interface{}{"concurrentDownloads": 99}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+}
+
+func TestConfigChanges_ApplyOneAfterAnother(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	s := NewServer(ServerConfig{Config: &core.Config{DownloadFolder: "/music"}})
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			switch i % 3 {
+			case 0:
+				doRequest(t, s, "POST", "/api/config/reset", nil, nil)
+			case 1:
+				doRequest(t, s, "POST", "/api/downloads/options", map[string]interface{}{"fileNameFormat": fmt.Sprintf("{title} %d", i)}, nil)
+			default:
+				doRequest(t, s, "POST", "/api/config", map[string]interface{}{"downloadFolder": "/music", "fileNameFormat": fmt.Sprintf("{artist} %d", i)}, nil)
+			}
+		})
+	}
+	wg.Wait()
+
+	saved, err := core.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur := s.currentConfig(); saved.FileNameFormat != cur.FileNameFormat || saved.DownloadFolder != cur.DownloadFolder {
+		t.Errorf("saved config %+v differs from the running one %+v", saved, cur)
+	}
+}
+
+func TestHandleSaveConfig_RejectsUnknownQuality(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	s := NewServer(ServerConfig{Config: &core.Config{}})
+
+	resp := doRequest(t, s, "POST", "/api/config", map[string]interface{}{
+		"downloadQuality": "ultra",
+	}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+}
+
+func TestHandleSaveConfig_AppliesToDownloader(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	downloader := core.NewTidalHifiService()
+	s := NewServer(ServerConfig{Config: &core.Config{}, Downloader: downloader})
+
+	var body struct {
+		RestartRequired []string            `json:"restartRequired"`
+		Changes         []configdiff.Change `json:"changes"`
+	}
+	resp := doRequest(t, s, "POST", "/api/config", map[string]interface{}{
+		"downloadQuality":     "HI_RES",
+		"concurrentDownloads": 4,
+	}, &body)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if got := downloader.GetOptions().Quality; got != "HI_RES" {
+		t.Errorf("downloader quality = %q, want HI_RES applied live", got)
+	}
+	if body.RestartRequired == nil || len(body.RestartRequired) != 0 {
+		t.Errorf("restartRequired = %v, want []", body.RestartRequired)
+	}
+	want := []configdiff.Change{
+		{Key: "concurrentDownloads", Effect: configdiff.Live},
+		{Key: "downloadQuality", Effect: configdiff.Live},
+	}
+	if !slices.Equal(body.Changes, want) {
+		t.Errorf("changes = %+v, want %+v", body.Changes, want)
+	}
+
+	doRequest(t, s, "POST", "/api/config", map[string]interface{}{
+		"downloadQuality":     "HI_RES",
+		"concurrentDownloads": 8,
+	}, &body)
+	want = []configdiff.Change{{Key: "concurrentDownloads", Effect: configdiff.Restart}}
+	if !slices.Equal(body.Changes, want) {
+		t.Errorf("pool resize: changes = %+v, want %+v", body.Changes, want)
+	}
+}
+
+func TestHandleSaveConfig_RejectsBadProxyAndKeepsConfig(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	s := NewServer(ServerConfig{Config: &core.Config{DownloadQuality: "LOSSLESS"}})
+
+	var body struct {
+		Changes []configdiff.Change `json:"changes"`
+	}
+	resp := doRequest(t, s, "POST", "/api/config", map[string]interface{}{
+		"downloadQuality": "HI_RES",
+		"proxyUrl":        "gopher://proxy.example",
+	}, &body)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+	if len(body.Changes) != 1 || body.Changes[0].Key != "proxyUrl" || body.Changes[0].Effect != configdiff.Invalid {
+		t.Errorf("changes = %+v, want proxyUrl invalid", body.Changes)
+	}
+	if s.config.DownloadQuality != "LOSSLESS" {
+		t.Errorf("rejected config replaced the running one: %+v", s.config)
+	}
+}
@@ -9,7 +9,7 @@ import (
 )
 
 // Tests for GET /api/convert/ffmpeg, GET /api/convert/available,
-// GET /api/convert/formats and POST /api/convert.
+// GET /api/convert/formats, POST /api/convert and the conversion jobs.
 
 func TestHandleIsConverterAvailable_MatchesCoreCheck(t *testing.T) {
 	s := newTestServer(t)
@@ -146,3 +146,27 @@ func TestHandleConvertFiles_NoConverterAvailable(t *testing.T) {
 		}
 	}
 }
+
+func TestConversionJobs_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/convert/jobs", map[string]any{"files": []string{}, "format": "mp3"}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("start without files = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "POST", "/api/convert/jobs", map[string]any{"files": []string{"/tmp/a.flac"}, "format": "wma"}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("start with an unknown format = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "POST", "/api/convert/jobs", map[string]any{"files": []string{"/tmp/a/x.flac", "/tmp/b/x.flac"}, "format": "mp3", "outputDir": "/tmp/out"}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("start with two files converting to one path = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "GET", "/api/convert/jobs/nope", nil, nil)
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("get unknown job = %d, want 404", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "POST", "/api/convert/jobs/nope/cancel", nil, nil)
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("cancel unknown job = %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/coverproxy"
+	"flacidal/internal/coverstore"
+)
+
+// handleLibraryCovers implements GET /api/covers. Mirrors internal/app's
+// App.GetLibraryCovers.
+func (s *Server) handleLibraryCovers(c *fiber.Ctx) error {
+	if s.covers == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "cover store not initialized"})
+	}
+	lib, err := app.LibraryCovers(s.covers, s.config.DownloadFolder)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(lib)
+}
+
+// handleCoverThumbnail implements GET /api/covers/:hash/thumbnail?size=.
+// Mirrors internal/app's App.GetCoverThumbnail.
+func (s *Server) handleCoverThumbnail(c *fiber.Ctx) error {
+	if s.covers == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "cover store not initialized"})
+	}
+	thumb, err := app.CoverThumbnail(s.covers, c.Params("hash"), c.QueryInt("size"))
+	if errors.Is(err, coverstore.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(thumb)
+}
+
+// thumbnailMaxAge is how long browsers reuse a file's thumbnail before
+// revalidating it; the ETag makes the revalidation a 304 until the cover
+// changes.
+const thumbnailMaxAge = 300
+
+// handleGetFileThumbnail implements GET
+// /api/files/cover/thumbnail?path=&size=. Unlike the other cover
+// endpoints it serves the image itself, so a file list can use it as an
+// <img> source and let the browser cache it. Mirrors internal/app's
+// App.GetFileThumbnail.
+func (s *Server) handleGetFileThumbnail(c *fiber.Ctx) error {
+	if s.covers == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "cover store not initialized"})
+	}
+	path := c.Query("path")
+	if path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "path is required"})
+	}
+	data, mimeType, hash, err := app.FileThumbnail(s.covers, path, c.QueryInt("size"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	etag := fmt.Sprintf(`"%s-%d"`, hash[:16], c.QueryInt("size"))
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", thumbnailMaxAge))
+	c.Set(fiber.HeaderETag, etag)
+	if c.Get(fiber.HeaderIfNoneMatch) == etag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, mimeType)
+	return c.Send(data)
+}
+
+// handleProxyCover implements GET /api/proxy/cover?url=. It serves a
+// source's cover image through the server, for web clients that can't
+// reach the image hosts themselves; only coverproxy.Hosts are fetched.
+// Server-only: the desktop app loads covers directly.
+func (s *Server) handleProxyCover(c *fiber.Ctx) error {
+	if s.coverProxy == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "cover proxy not initialized"})
+	}
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "url is required"})
+	}
+	data, mimeType, err := s.coverProxy.Fetch(rawURL, s.currentSettings().CoverUserAgents)
+	if errors.Is(err, coverproxy.ErrNotAllowed) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": err.Error()})
+	}
+	// A cover URL names one image for good.
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", int(hashedMaxAge.Seconds())))
+	c.Set(fiber.HeaderContentType, mimeType)
+	return c.Send(data)
+}
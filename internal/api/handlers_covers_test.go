@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/coverproxy"
+	"flacidal/internal/coverstore"
+	"flacidal/internal/flacmeta"
+)
+
+func TestHandleCovers(t *testing.T) {
+	s := newTestServer(t)
+	if resp := doRequest(t, s, "GET", "/api/covers", nil, nil); resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("without a store: status %d, want 500", resp.StatusCode)
+	}
+
+	store, err := coverstore.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.covers = store
+	var lib coverstore.Library
+	if resp := doRequest(t, s, "GET", "/api/covers", nil, &lib); resp.StatusCode != fiber.StatusOK || lib.Files != 0 || lib.Covers == nil {
+		t.Errorf("no download folder: status %d, %+v", resp.StatusCode, lib)
+	}
+
+	gif := []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+	hash, err := store.Put(gif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var thumb map[string]string
+	if resp := doRequest(t, s, "GET", "/api/covers/"+hash+"/thumbnail?size=64", nil, &thumb); resp.StatusCode != fiber.StatusOK || thumb["mimeType"] != "image/gif" || thumb["data"] == "" {
+		t.Errorf("thumbnail: status %d, %v", resp.StatusCode, thumb)
+	}
+	if resp := doRequest(t, s, "GET", "/api/covers/nope/thumbnail", nil, nil); resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("unknown cover: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestHandleGetFileThumbnail(t *testing.T) {
+	s := newTestServer(t)
+	store, err := coverstore.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.covers = store
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	q := "/api/files/cover/thumbnail?size=64&path=" + url.QueryEscape(path)
+	if resp := doRequest(t, s, "GET", q, nil, nil); resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("no cover: status %d, want 404", resp.StatusCode)
+	}
+
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gif := []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+	f.AddPicture(flacmeta.Picture{Type: flacmeta.PictureFrontCover, MIME: "image/gif", Data: gif})
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	resp := doRequest(t, s, "GET", q, nil, nil)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get(fiber.HeaderContentType) != "image/gif" || etag == "" ||
+		resp.Header.Get(fiber.HeaderCacheControl) == "" {
+		t.Fatalf("thumbnail: status %d, headers %v", resp.StatusCode, resp.Header)
+	}
+
+	req := httptest.NewRequest("GET", q, nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, etag)
+	resp, err = s.app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusNotModified {
+		t.Errorf("revalidation: status %d, want 304", resp.StatusCode)
+	}
+}
+
+func TestHandleProxyCover_Rejects(t *testing.T) {
+	s := newTestServer(t)
+	proxy, err := coverproxy.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.coverProxy = proxy
+	if resp := doRequest(t, s, "GET", "/api/proxy/cover", nil, nil); resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("no url: status %d, want 400", resp.StatusCode)
+	}
+	for _, target := range []string{"http://127.0.0.1:8080/api/config", "file:///etc/passwd"} {
+		if resp := doRequest(t, s, "GET", "/api/proxy/cover?url="+url.QueryEscape(target), nil, nil); resp.StatusCode != fiber.StatusForbidden {
+			t.Errorf("%s: status %d, want 403", target, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/app"
+)
+
+// handleGetAlbumCredits implements GET
+// /api/content/credits?source=&id=&refresh=. Mirrors internal/app's
+// App.GetAlbumCredits.
+func (s *Server) handleGetAlbumCredits(c *fiber.Ctx) error {
+	source, id := c.Query("source"), c.Query("id")
+	if source == "" || id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "source and id are required"})
+	}
+	config := s.config
+	if config == nil {
+		config = &core.Config{}
+	}
+	album, err := app.AlbumCredits(s.credits, app.CreditFetchers(config), source, id, c.QueryBool("refresh"))
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(album)
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleGetAlbumCredits_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "GET", "/api/content/credits?source=tidal", nil, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status without id = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "GET", "/api/content/credits?source=deezer&id=1", nil, nil)
+	if resp.StatusCode != fiber.StatusBadGateway {
+		t.Errorf("status for a service without credits = %d, want 502", resp.StatusCode)
+	}
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/app"
+)
+
+// handleRunDoctor implements POST /api/doctor with {"download": bool}.
+// Mirrors internal/app's App.RunDoctor.
+func (s *Server) handleRunDoctor(c *fiber.Ctx) error {
+	var req struct {
+		Download bool `json:"download"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	env := app.DoctorEnv{
+		Config:     s.config,
+		Settings:   s.currentSettings(),
+		DataDir:    core.GetDataDir(),
+		Downloader: s.downloader,
+	}
+	if req.Download {
+		env.TrackID = app.DoctorTrackID
+	}
+	return c.JSON(app.Doctor(c.UserContext(), env))
+}
@@ -5,6 +5,9 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/inventory"
 )
 
 // handleExportFailedDownloads implements GET /api/downloads/export.
@@ -46,3 +49,30 @@ func (s *Server) handleExportFailedDownloads(c *fiber.Ctx) error {
 	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
 	return c.SendString(sb.String())
 }
+
+// handleExportLibrary implements GET /api/files/export?folder=...&format=csv|json.
+// Returns the metadata of every FLAC under folder (the download folder when
+// omitted) as an attachment; format defaults to csv. Mirrors internal/app's
+// App.ExportLibrary, minus the native OS save dialog.
+func (s *Server) handleExportLibrary(c *fiber.Ctx) error {
+	format := c.Query("format", "csv")
+	if err := inventory.ValidateFormat(format); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	r, err := app.LibraryReport(c.UserContext(), c.Query("folder"), s.config.DownloadFolder)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	data, ext, err := r.Encode(format)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	contentType := "application/json"
+	if format == "csv" {
+		contentType = "text/csv"
+	}
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, app.LibraryReportFileName(r, ext)))
+	return c.Send(data)
+}
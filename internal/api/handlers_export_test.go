@@ -1,6 +1,11 @@
 package api
 
 import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -40,3 +45,26 @@ func TestHandleExportFailedDownloads_NoFailedJobs(t *testing.T) {
 		t.Errorf("body = %v, want an 'error' key", body)
 	}
 }
+
+// Tests for GET /api/files/export.
+
+func TestHandleExportLibrary(t *testing.T) {
+	s := newTestServer(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.flac"), append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644)
+
+	resp := doRequest(t, s, "GET", "/api/files/export?folder="+url.QueryEscape(dir), nil, nil)
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusOK || !strings.HasPrefix(string(body), "path,title,") || !strings.Contains(string(body), "a.flac") {
+		t.Fatalf("status %d, body %q", resp.StatusCode, body)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); !strings.Contains(cd, filepath.Base(dir)+".metadata.csv") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	for _, query := range []string{"", "?folder=" + url.QueryEscape(dir) + "&format=xml"} {
+		if resp := doRequest(t, s, "GET", "/api/files/export"+query, nil, nil); resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%q: status %d, want 400", query, resp.StatusCode)
+		}
+	}
+}
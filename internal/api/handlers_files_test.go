@@ -1,13 +1,25 @@
 package api
 
 import (
+	"encoding/json"
+	"io/fs"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"syscall"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/app"
+	"flacidal/internal/fileerr"
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/flacmeta/flactest"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/quality"
 )
 
 // Tests for GET /api/files, GET /api/files/metadata and GET /api/files/cover.
@@ -45,6 +57,21 @@ func TestHandleListFiles_EmptyDownloadFolder(t *testing.T) {
 	}
 }
 
+func TestHandleListFiles_AudioFormat(t *testing.T) {
+	dir := t.TempDir()
+	flactest.Write(t, filepath.Join(dir, "hires.flac"), flactest.HiRes)
+	s := NewServer(ServerConfig{Config: &core.Config{DownloadFolder: dir}})
+
+	var files []app.FileInfo
+	resp := doRequest(t, s, "GET", "/api/files", nil, &files)
+	if resp.StatusCode != fiber.StatusOK || len(files) != 1 {
+		t.Fatalf("status %d, files %+v", resp.StatusCode, files)
+	}
+	if f := files[0]; f.SampleRate != 96000 || f.BitDepth != 24 || f.Tier != quality.HiRes {
+		t.Errorf("file = %+v, want 24/96 hi-res", f)
+	}
+}
+
 func TestHandleGetMetadata_MissingPath(t *testing.T) {
 	s := newTestServer(t)
 
@@ -78,6 +105,57 @@ func TestHandleGetMetadata_InvalidFile(t *testing.T) {
 	}
 }
 
+func TestHandleGetMetadata_Provenance(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := flacmeta.UpdateComments(path, func(c *flacmeta.Comments) {
+		c.Set("TITLE", "Song")
+		c.Set(postprocess.TagSource, "tidal")
+		c.Set(postprocess.TagSourceID, "12345")
+		c.Set(postprocess.TagDownloadDate, "2026-03-14T14:09:26Z")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var body map[string]interface{}
+	resp := doRequest(t, s, "GET", "/api/files/metadata?path="+path, nil, &body)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, body = %v", resp.StatusCode, body)
+	}
+	if body["title"] != "Song" || body["source"] != "tidal" || body["sourceId"] != "12345" || body["downloadDate"] != "2026-03-14T14:09:26Z" {
+		t.Errorf("body = %v, want core's metadata with the provenance tags", body)
+	}
+}
+
+func TestHandleGetMetadata_MultiValued(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := flacmeta.UpdateComments(path, func(c *flacmeta.Comments) {
+		c.Set("ARTIST", "A", "B")
+		c.Set("GENRE", "Rock", "Pop")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var body struct {
+		Artists []string `json:"artists"`
+		Genres  []string `json:"genres"`
+	}
+	resp := doRequest(t, s, "GET", "/api/files/metadata?path="+path, nil, &body)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if !slices.Equal(body.Artists, []string{"A", "B"}) || !slices.Equal(body.Genres, []string{"Rock", "Pop"}) {
+		t.Errorf("artists = %v, genres = %v; want every value", body.Artists, body.Genres)
+	}
+}
+
 func TestHandleGetCoverArt_MissingPath(t *testing.T) {
 	s := newTestServer(t)
 
@@ -110,3 +188,59 @@ func TestHandleGetCoverArt_InvalidFile(t *testing.T) {
 		t.Errorf("body = %v, want an 'error' key", body)
 	}
 }
+
+func TestFileError_Details(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.flac")
+	f := fiber.New()
+	f.Get("/", func(c *fiber.Ctx) error {
+		return fileError(c, fiber.StatusBadRequest, fileerr.Wrap(&fs.PathError{Op: "open", Path: path, Err: syscall.EDQUOT}))
+	})
+	resp, err := f.Test(httptest.NewRequest("GET", "/", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusInsufficientStorage || body["code"] != string(fileerr.QuotaExceeded) || body["mount"] == "" || filepath.Base(body["path"]) != "song.flac" {
+		t.Errorf("status %d, body %v", resp.StatusCode, body)
+	}
+}
+
+func TestHandlePreviewRename_FolderTemplate(t *testing.T) {
+	dir := t.TempDir()
+	s := NewServer(ServerConfig{Config: &core.Config{DownloadFolder: dir}})
+	path := filepath.Join(dir, "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := flacmeta.UpdateComments(path, func(c *flacmeta.Comments) {
+		c.Set("ARTIST", "Daft Punk")
+		c.Set("ALBUM", "RAM")
+		c.Set("TITLE", "Contact")
+		c.Set("TRACKNUMBER", "8")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var previews []core.RenamePreview
+	body := map[string]any{"files": []string{path}, "template": "{artist}/{album}/{tracknumber} - {title}"}
+	resp := doRequest(t, s, "POST", "/api/files/rename/preview", body, &previews)
+	want := filepath.Join(dir, "Daft Punk", "RAM", "08 - Contact.flac")
+	if resp.StatusCode != fiber.StatusOK || len(previews) != 1 || previews[0].NewPath != want {
+		t.Fatalf("status %d, previews %+v, want %s", resp.StatusCode, previews, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("preview moved the file")
+	}
+
+	var results []core.RenameResult
+	doRequest(t, s, "POST", "/api/files/rename", body, &results)
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v", results)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("file not moved to %s", want)
+	}
+}
@@ -1,9 +1,15 @@
 package api
 
 import (
+	"errors"
+	"os"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/timestamp"
 )
 
 // handleGetTrackHistory returns the per-track download log with pagination.
@@ -22,6 +28,7 @@ func (s *Server) handleGetTrackHistory(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	timestamp.UTCFields(entries)
 
 	total, err := s.db.GetHistoryCount()
 	if err != nil {
@@ -31,6 +38,67 @@ func (s *Server) handleGetTrackHistory(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"entries": entries, "total": total})
 }
 
+// handleGetActivityHeatmap implements GET /api/history/heatmap?days=&tz=.
+// days is the period (default 30, 0 for all time) and tz the IANA time zone
+// to bucket hours in, as browsers report it (default the server's). Mirrors
+// internal/app's App.GetActivityHeatmap.
+func (s *Server) handleGetActivityHeatmap(c *fiber.Ctx) error {
+	days, err := strconv.Atoi(c.Query("days", "30"))
+	if err != nil || days < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "days must be a number of days, or 0"})
+	}
+	loc := time.Local
+	if tz := c.Query("tz"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "unknown time zone " + strconv.Quote(tz)})
+		}
+	}
+	heatmap, err := app.ActivityHeatmap(s.db, days, loc)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(heatmap)
+}
+
+// handleGetHistoryTimings implements GET /api/history/timings. Mirrors
+// internal/app's App.GetHistoryTimings.
+func (s *Server) handleGetHistoryTimings(c *fiber.Ctx) error {
+	return c.JSON(s.timings.All())
+}
+
+// handleGetHistorySnapshots implements GET /api/history/snapshots. Mirrors
+// internal/app's App.GetHistorySnapshots.
+func (s *Server) handleGetHistorySnapshots(c *fiber.Ctx) error {
+	return c.JSON(s.origins.Snapshots())
+}
+
+// handleGetHistoryCover implements GET /api/history/cover?id=, id being a
+// history record's content ID. Mirrors internal/app's App.GetHistoryCover.
+func (s *Server) handleGetHistoryCover(c *fiber.Ctx) error {
+	cover, err := app.HistoryCover(s.origins, c.Query("id"))
+	if errors.Is(err, os.ErrNotExist) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "no archived cover"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(cover)
+}
+
+// handleGetHistoryFile implements GET /api/history/file?trackId=. Mirrors
+// internal/app's App.GetHistoryFile.
+func (s *Server) handleGetHistoryFile(c *fiber.Ctx) error {
+	trackID, err := strconv.Atoi(c.Query("trackId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid trackId"})
+	}
+	path, err := app.HistoryFile(s.library, trackID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"path": path})
+}
+
 // RegisterHistoryRoutes registers the per-track history route on the given router group.
 func RegisterHistoryRoutes(api fiber.Router, s *Server) {
 	api.Get("/track-history", s.handleGetTrackHistory)
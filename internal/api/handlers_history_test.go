@@ -0,0 +1,42 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/history"
+)
+
+func TestHandleGetActivityHeatmap(t *testing.T) {
+	s := newTestServerWithDB(t)
+
+	var h history.Heatmap
+	resp := doRequest(t, s, "GET", "/api/history/heatmap?days=7&tz=Asia/Tokyo", nil, &h)
+	if resp.StatusCode != fiber.StatusOK || h.Total != 0 || h.Days != 7 {
+		t.Errorf("empty history: status %d, %+v", resp.StatusCode, h)
+	}
+
+	for _, query := range []string{"days=-1", "days=week", "tz=Nowhere/City"} {
+		if resp := doRequest(t, s, "GET", "/api/history/heatmap?"+query, nil, nil); resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandleHistorySnapshots(t *testing.T) {
+	s := newTestServer(t)
+	s.origins, _ = history.Open(t.TempDir())
+	s.origins.Note("p1", history.Origin{Source: "tidal", ContentType: "playlist"})
+	s.origins.Describe("p1", history.Snapshot{Title: "Mix", Description: "Late night"})
+	s.origins.Commit("p1")
+
+	var snaps map[string]history.Snapshot
+	resp := doRequest(t, s, "GET", "/api/history/snapshots", nil, &snaps)
+	if resp.StatusCode != fiber.StatusOK || snaps["p1"].Description != "Late night" {
+		t.Errorf("status %d, %+v", resp.StatusCode, snaps)
+	}
+	if resp := doRequest(t, s, "GET", "/api/history/cover?id=p1", nil, nil); resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("cover without an archived one: status %d, want 404", resp.StatusCode)
+	}
+}
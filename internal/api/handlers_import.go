@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/app"
+	"flacidal/internal/history"
+	"flacidal/internal/logging"
+)
+
+// maxImportSize caps an uploaded URL list; a few thousand URLs fit easily.
+const maxImportSize = 1 << 20
+
+// handleImportURLs implements POST /api/downloads/import. The URL list is
+// either an uploaded text file (multipart field "file") or the raw request
+// body, one URL per line; outputDir comes from the form or query string.
+// Mirrors internal/app's App.ImportURLs.
+func (s *Server) handleImportURLs(c *fiber.Ctx) error {
+	if s.downloadManager == nil || s.sourceManager == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "download manager not initialized"})
+	}
+
+	text, err := importText(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	urls := app.ParseImportList(text)
+	if len(urls) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "no URLs to import"})
+	}
+
+	return c.JSON(s.importURLs(urls, c.FormValue("outputDir", c.Query("outputDir"))))
+}
+
+// importURLs resolves and queues urls into outputDir, or the configured
+// download folder when it is empty. Shared by handleImportURLs and the
+// watch folder.
+func (s *Server) importURLs(urls []string, outputDir string) []app.ImportResult {
+	if outputDir == "" {
+		outputDir = s.config.DownloadFolder
+	}
+	if outputDir == "" {
+		outputDir = core.GetDefaultDownloadFolder()
+	}
+
+	results := make([]app.ImportResult, len(urls))
+	for i, rawURL := range urls {
+		results[i] = s.importURL(rawURL, outputDir)
+	}
+	s.component(logging.Downloads).Info("imported URLs", "count", len(urls), "summary", app.ImportSummary(results))
+	return results
+}
+
+// importWatchedFile imports a URL list dropped into Settings.WatchFolder.
+func (s *Server) importWatchedFile(name, text string) {
+	s.component(logging.Downloads).Info("watch folder: importing", "file", name)
+	s.importURLs(app.ParseImportList(text), "")
+}
+
+// importText returns the URL list of an import request.
+func importText(c *fiber.Ctx) (string, error) {
+	if fh, err := c.FormFile("file"); err == nil {
+		if fh.Size > maxImportSize {
+			return "", fmt.Errorf("file too large")
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, maxImportSize))
+		return string(data), err
+	}
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return "", fmt.Errorf("missing file")
+	}
+	if len(c.Body()) > maxImportSize {
+		return "", fmt.Errorf("body too large")
+	}
+	return string(c.Body()), nil
+}
+
+// importURL resolves and queues one imported URL.
+func (s *Server) importURL(rawURL, outputDir string) app.ImportResult {
+	result := app.ImportResult{URL: rawURL}
+	content, err := app.ResolveContent(s.sourceManager, rawURL)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Source, result.Type, result.Title = content.Source, content.Type, content.Title
+	app.NoteOrigin(s.origins, content.Source, content.ID, content.Type, content.URL)
+	if err := app.CheckDownloadable(content.Source); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if content.Source == "qobuz" {
+		result.Queued, err = s.queueQobuzBatch(content.Tracks, outputDir, content.Title, content.ID, content.Type)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		return result
+	}
+
+	// Same content-name subfolder as the desktop app's QueueDownloads
+	dir := outputDir
+	if content.Title != "" {
+		dir = filepath.Join(outputDir, s.folderName(content.Title))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			result.Error = fmt.Sprintf("failed to create folder: %v", err)
+			return result
+		}
+	}
+	result.Queued = s.queueTidalBatch(app.TidalTracksFromSource(content.Tracks), dir, content.Title,
+		history.Key(content.Source, content.ID), content.Type)
+	return result
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/app"
+)
+
+// Tests for POST /api/downloads/import. The server has an empty
+// SourceManager, so every URL fails to resolve without any network call;
+// the per-URL report is what's under test.
+
+func newImportTestServer(t *testing.T) *Server {
+	t.Helper()
+	return NewServer(ServerConfig{
+		Config:          &core.Config{DownloadFolder: t.TempDir()},
+		DownloadManager: core.NewDownloadManager(core.NewTidalHifiService(), 1),
+		SourceManager:   core.NewSourceManager(),
+	})
+}
+
+func TestHandleImportURLs_NoDownloadManager(t *testing.T) {
+	s := newTestServer(t)
+
+	resp, err := s.app.Test(httptest.NewRequest("POST", "/api/downloads/import", strings.NewReader("https://tidal.com/browse/album/1")), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusInternalServerError)
+	}
+}
+
+func TestHandleImportURLs_Empty(t *testing.T) {
+	s := newImportTestServer(t)
+
+	resp, err := s.app.Test(httptest.NewRequest("POST", "/api/downloads/import", strings.NewReader("\n# nothing\n")), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+}
+
+func TestHandleImportURLs_TextBody(t *testing.T) {
+	s := newImportTestServer(t)
+
+	req := httptest.NewRequest("POST", "/api/downloads/import", strings.NewReader("https://example.com/a\nhttps://example.com/b\n"))
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	var results []app.ImportResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].URL != "https://example.com/a" || results[0].Error == "" || results[1].Queued != 0 {
+		t.Errorf("results = %+v, want two failed URLs", results)
+	}
+}
+
+func TestHandleImportURLs_File(t *testing.T) {
+	s := newImportTestServer(t)
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fw, err := w.CreateFormFile("file", "urls.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte("https://example.com/a\n"))
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/api/downloads/import", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	var results []app.ImportResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].URL != "https://example.com/a" {
+		t.Errorf("results = %+v, want the uploaded URL", results)
+	}
+}
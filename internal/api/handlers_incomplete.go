@@ -0,0 +1,90 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/app"
+	"flacidal/internal/incomplete"
+	"flacidal/internal/logging"
+)
+
+// incompleteCleanupInterval is how often incomplete downloads older than
+// Settings.IncompleteCleanupDays are deleted.
+const incompleteCleanupInterval = 6 * time.Hour
+
+// handleListIncompleteFiles implements GET /api/files/incomplete. Mirrors
+// internal/app's App.ListIncompleteFiles.
+func (s *Server) handleListIncompleteFiles(c *fiber.Ctx) error {
+	files, err := app.ListIncomplete(s.libraryRoots(), s.downloadManager)
+	if err != nil && len(files) == 0 {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(files)
+}
+
+// handleDeleteIncompleteFile implements DELETE /api/files/incomplete?path=.
+// Mirrors internal/app's App.DeleteIncompleteFile.
+func (s *Server) handleDeleteIncompleteFile(c *fiber.Ctx) error {
+	path := c.Query("path")
+	if path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "path is required"})
+	}
+	if err := incomplete.Remove(s.libraryRoots(), path); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// handleRequeueIncompleteFile implements POST /api/files/incomplete/requeue.
+// Body: {"path": "..."}. Mirrors internal/app's App.RequeueIncompleteFile.
+func (s *Server) handleRequeueIncompleteFile(c *fiber.Ctx) error {
+	var req struct {
+		Path string `json:"path"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "path is required"})
+	}
+	if s.downloadManager == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "download manager not initialized"})
+	}
+	trackID, err := app.RemoveForRequeue(s.libraryRoots(), s.downloadManager, req.Path)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := s.downloadManager.QueueDownload(trackID, s.downloadFolder(), "", ""); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "trackId": trackID})
+}
+
+// libraryRoots is app.LibraryRoots with the current config.
+func (s *Server) libraryRoots() []string {
+	if s.config == nil {
+		return app.LibraryRoots(core.GetDefaultDownloadFolder(), nil)
+	}
+	return app.LibraryRoots(s.downloadFolder(), s.config.ExternalLibraryPaths)
+}
+
+// downloadFolder returns the configured download folder, or core's
+// default.
+func (s *Server) downloadFolder() string {
+	if s.config != nil && s.config.DownloadFolder != "" {
+		return s.config.DownloadFolder
+	}
+	return core.GetDefaultDownloadFolder()
+}
+
+// reportIncompleteCleanup logs a run of the automatic cleanup.
+func (s *Server) reportIncompleteCleanup(removed []string, err error) {
+	log := s.component(logging.Downloads)
+	if len(removed) > 0 {
+		log.Info("removed incomplete downloads", "count", len(removed), "olderThanDays", s.currentSettings().IncompleteCleanupDays)
+	}
+	if err != nil {
+		log.Warn("incomplete download cleanup failed", "err", err)
+	}
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleIncompleteFiles_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "DELETE", "/api/files/incomplete", nil, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("delete without path = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "DELETE", "/api/files/incomplete?path=/etc/passwd", nil, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("delete outside the library = %d, want 400", resp.StatusCode)
+	}
+	resp = doRequest(t, s, "POST", "/api/files/incomplete/requeue", map[string]any{}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("requeue without path = %d, want 400", resp.StatusCode)
+	}
+}
@@ -0,0 +1,11 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// handleGetDownloadJobs implements GET /api/downloads/jobs.
+// Mirrors internal/app's App.GetDownloadJobs.
+func (s *Server) handleGetDownloadJobs(c *fiber.Ctx) error {
+	return c.JSON(s.jobs.Jobs())
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/downloads"
+)
+
+func TestHandleGetDownloadJobs(t *testing.T) {
+	s := newTestServer(t)
+	for _, status := range []string{"queued", "downloading", "completed"} {
+		s.BroadcastDownloadEvent(core.DownloadEvent{TrackID: 42, Status: status})
+	}
+	// Out-of-order event: rejected by the state machine, job unchanged.
+	s.BroadcastDownloadEvent(core.DownloadEvent{TrackID: 42, Status: "downloading"})
+
+	var jobs []downloads.Job
+	resp := doRequest(t, s, "GET", "/api/downloads/jobs", nil, &jobs)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("len(jobs) = %d, want 1", len(jobs))
+	}
+	if jobs[0].ID != 42 || jobs[0].State != downloads.Completed || len(jobs[0].Transitions) != 3 {
+		t.Errorf("job = %+v", jobs[0])
+	}
+}
+
+func TestDownloadEvents_ReachEverySubscriber(t *testing.T) {
+	s := newTestServer(t)
+	_, first := s.DownloadEvents().Subscribe(1)
+	_, second := s.DownloadEvents().Subscribe(1)
+
+	s.BroadcastDownloadEvent(core.DownloadEvent{TrackID: 7, Status: "queued"})
+	for _, ch := range []<-chan core.DownloadEvent{first, second} {
+		if ev := <-ch; ev.TrackID != 7 || ev.Status != "queued" {
+			t.Errorf("subscriber got %+v", ev)
+		}
+	}
+}
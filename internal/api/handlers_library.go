@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/library"
+	"flacidal/internal/logging"
+)
+
+// libraryWatchInterval is how often the library folders are rescanned with
+// Settings.WatchLibrary on where their changes can't be watched.
+const libraryWatchInterval = 30 * time.Second
+
+// scanLibrary runs app.ScanLibrary over the library roots, broadcasting
+// "library-scan-progress" WebSocket messages, and logs the outcome.
+func (s *Server) scanLibrary(ctx context.Context) (*library.ScanReport, error) {
+	report, err := app.ScanLibrary(ctx, s.library, s.libraryRoots(), func(files int) {
+		s.wsHub.Broadcast(fiber.Map{"type": "library-scan-progress", "files": files})
+	})
+	log := s.component(logging.Server)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			log.Warn("library scan failed", "err", err)
+		}
+		return nil, err
+	}
+	log.Info("library scanned", "summary", app.LibraryScanSummary(report))
+	return report, nil
+}
+
+// watchLibrary indexes the files other programs put in the library while
+// Settings.WatchLibrary is on, until ctx is done, broadcasting a
+// "library-updated" WebSocket message (the scan report) after each change.
+func (s *Server) watchLibrary(ctx context.Context) {
+	log := s.component(logging.Server)
+	s.library.Watch(ctx, libraryWatchInterval, func() []string {
+		return app.WatchedLibraryRoots(s.currentSettings(), s.libraryRoots())
+	}, func(report *library.ScanReport, err error) {
+		if err != nil {
+			log.Warn("library watch failed", "err", err)
+			return
+		}
+		log.Info("library changed", "summary", app.LibraryScanSummary(report))
+		s.wsHub.Broadcast(fiber.Map{"type": "library-updated", "report": report})
+		if st := s.currentSettings(); st.AnalyzeNewFiles {
+			app.AnalyzeNewFiles(ctx, report.New, st, s.analyses, func(path string, r *app.AnalysisResult, err error) {
+				switch {
+				case err != nil:
+					log.Warn("could not analyze new file", "path", path, "err", err)
+				case r.Truncated:
+					log.Warn("new file looks truncated", "path", path, "truncated", r.TruncatedLabel)
+				case !r.IsTrueLossless:
+					log.Warn("new file looks upscaled", "path", path, "verdict", r.VerdictLabel, "source", r.LossyCodecLabel)
+				case r.PaddedBitDepth:
+					log.Warn("new file has padded bit depth", "path", path, "bitDepth", r.BitDepthLabel)
+				case r.Clipping:
+					log.Warn("new file clips", "path", path, "clipping", r.ClippingLabel)
+				case r.MQA:
+					log.Warn("new file is MQA", "path", path, "mqa", r.MQALabel)
+				case r.FakeStereo || r.OutOfPhase:
+					log.Warn("new file has suspect stereo", "path", path, "stereo", r.StereoLabel)
+				}
+			})
+		}
+	})
+}
+
+// handleGetLibraryStatus implements GET /api/library. Mirrors
+// internal/app's App.GetLibraryStatus.
+func (s *Server) handleGetLibraryStatus(c *fiber.Ctx) error {
+	status, err := app.LibraryStatus(s.library)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(status)
+}
+
+// handleScanLibrary implements POST /api/library/scan. The scan runs to
+// the end before the report is returned. Mirrors internal/app's
+// App.ScanLibrary.
+func (s *Server) handleScanLibrary(c *fiber.Ctx) error {
+	report, err := s.scanLibrary(c.UserContext())
+	if errors.Is(err, library.ErrScanning) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// libraryQuery reads a library.Query from the query string: search,
+// artist, album, genre, label, root, sort, desc, limit and offset.
+func libraryQuery(c *fiber.Ctx) library.Query {
+	return library.Query{
+		Search: c.Query("search"),
+		Artist: c.Query("artist"),
+		Album:  c.Query("album"),
+		Genre:  c.Query("genre"),
+		Label:  c.Query("label"),
+		Root:   c.Query("root"),
+		Sort:   c.Query("sort"),
+		Desc:   c.QueryBool("desc"),
+		Limit:  c.QueryInt("limit"),
+		Offset: c.QueryInt("offset"),
+	}
+}
+
+// handleBrowseLibrary implements GET /api/library/tracks?search=&artist=
+// &album=&genre=&label=&root=&sort=&desc=&limit=&offset=. Mirrors internal/app's
+// App.BrowseLibrary.
+func (s *Server) handleBrowseLibrary(c *fiber.Ctx) error {
+	q := libraryQuery(c)
+	if err := q.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	page, err := app.LibraryTracks(s.library, q)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(page)
+}
+
+// handleGetLibraryArtists implements GET /api/library/artists, filtered
+// like /api/library/tracks. Mirrors internal/app's App.GetLibraryArtists.
+func (s *Server) handleGetLibraryArtists(c *fiber.Ctx) error {
+	artists, err := app.LibraryArtists(s.library, libraryQuery(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(artists)
+}
+
+// handleGetLibraryAlbums implements GET /api/library/albums, filtered
+// like /api/library/tracks. Mirrors internal/app's App.GetLibraryAlbums.
+func (s *Server) handleGetLibraryAlbums(c *fiber.Ctx) error {
+	albums, err := app.LibraryAlbums(s.library, libraryQuery(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(albums)
+}
+
+// handleGetLibraryLabels implements GET /api/library/labels, filtered
+// like /api/library/tracks. Mirrors internal/app's App.GetLibraryLabels.
+func (s *Server) handleGetLibraryLabels(c *fiber.Ctx) error {
+	labels, err := app.LibraryLabels(s.library, libraryQuery(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(labels)
+}
+
+// handleGetLibraryDuplicates implements GET /api/library/duplicates?by=,
+// by being "isrc", "md5" or empty for either. Mirrors internal/app's
+// App.GetLibraryDuplicates.
+func (s *Server) handleGetLibraryDuplicates(c *fiber.Ctx) error {
+	by := c.Query("by")
+	if err := library.CheckMatch(by); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	groups, err := app.LibraryDuplicates(s.library, by)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(groups)
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleLibrary_WithoutIndex(t *testing.T) {
+	s := newTestServer(t)
+	for _, path := range []string{"/api/library", "/api/library/tracks", "/api/library/artists", "/api/library/albums", "/api/library/labels", "/api/library/duplicates"} {
+		if resp := doRequest(t, s, "GET", path, nil, nil); resp.StatusCode != fiber.StatusInternalServerError {
+			t.Errorf("%s: status %d, want 500", path, resp.StatusCode)
+		}
+	}
+	if resp := doRequest(t, s, "POST", "/api/library/scan", nil, nil); resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("scan: status %d, want 500", resp.StatusCode)
+	}
+}
+
+func TestHandleBrowseLibrary_BadQuery(t *testing.T) {
+	s := newTestServer(t)
+	for _, path := range []string{"/api/library/tracks?sort=bpm", "/api/library/tracks?limit=-1", "/api/library/duplicates?by=title"} {
+		if resp := doRequest(t, s, "GET", path, nil, nil); resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", path, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"testing"
+
+	"flacidal/internal/timestamp"
+)
+
+func TestHandleGetLocaleHint(t *testing.T) {
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	t.Setenv("TZ", "Europe/Berlin")
+	s := newTestServer(t)
+
+	var hint timestamp.LocaleHint
+	doRequest(t, s, "GET", "/api/locale", nil, &hint)
+	if hint.Locale != "de-DE" || hint.TimeZone != "Europe/Berlin" {
+		t.Errorf("GET /api/locale = %+v", hint)
+	}
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/logging"
+)
+
+// handleGetLogLevels implements GET /api/logs/levels.
+// Mirrors internal/app's App.GetLogLevels.
+func (s *Server) handleGetLogLevels(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"levels":     s.logLevels.Snapshot(),
+		"components": logging.Components(),
+	})
+}
+
+// handleSetLogLevel implements POST /api/logs/levels.
+// Mirrors internal/app's App.SetLogLevel.
+func (s *Server) handleSetLogLevel(c *fiber.Ctx) error {
+	var req struct {
+		Component string `json:"component"`
+		Level     string `json:"level"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.logLevels.Set(req.Component, level)
+	return c.JSON(fiber.Map{"levels": s.logLevels.Snapshot()})
+}
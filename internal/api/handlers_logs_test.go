@@ -0,0 +1,42 @@
+package api
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/logging"
+)
+
+func TestHandleLogLevels_SetAndGet(t *testing.T) {
+	levels := &logging.Levels{}
+	s := NewServer(ServerConfig{Config: &core.Config{}, LogLevels: levels})
+
+	resp := doRequest(t, s, "POST", "/api/logs/levels", map[string]string{"component": "http", "level": "warn"}, nil)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("POST status = %d", resp.StatusCode)
+	}
+	if levels.Level(logging.HTTP) != slog.LevelWarn {
+		t.Errorf("http level = %v, want warn", levels.Level(logging.HTTP))
+	}
+
+	var body struct {
+		Levels     map[string]string `json:"levels"`
+		Components []string          `json:"components"`
+	}
+	doRequest(t, s, "GET", "/api/logs/levels", nil, &body)
+	if body.Levels["http"] != "warn" || body.Levels["*"] != "info" || len(body.Components) == 0 {
+		t.Errorf("GET body = %+v", body)
+	}
+}
+
+func TestHandleSetLogLevel_RejectsUnknownLevel(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/logs/levels", map[string]string{"component": "http", "level": "loud"}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+}
@@ -8,8 +8,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-// Tests for POST /api/lyrics/file, POST /api/lyrics/fetch-embed and
-// POST /api/lyrics/fetch-embed/multiple.
+// Tests for POST /api/lyrics/file, POST /api/lyrics/fetch-embed,
+// POST /api/lyrics/fetch-embed/multiple and /api/lyrics/fetch-embed/preview.
 //
 // NOT tested here (documented, not fixed): success paths reach a live
 // network call to LRCLIB with no injectable HTTP seam (same limitation as
@@ -130,3 +130,55 @@ func TestHandleFetchAndEmbedMultiple_EmptyList(t *testing.T) {
 		t.Errorf("results = %v, want empty", results)
 	}
 }
+
+func TestHandleSaveLyricsSidecar(t *testing.T) {
+	s := newTestServer(t)
+	flac := filepath.Join(t.TempDir(), "01 - Song.flac")
+
+	var body map[string]interface{}
+	resp := doRequest(t, s, "POST", "/api/lyrics/sidecar", map[string]interface{}{
+		"filePath": flac, "plain": "la la", "synced": "[00:01.00]la la",
+	}, &body)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d (body %v)", resp.StatusCode, fiber.StatusOK, body)
+	}
+	want := filepath.Join(filepath.Dir(flac), "01 - Song.lrc")
+	if body["path"] != want {
+		t.Errorf("path = %v, want %s", body["path"], want)
+	}
+	if data, err := os.ReadFile(want); err != nil || string(data) != "[00:01.00]la la\n" {
+		t.Errorf("sidecar = %q, %v; want the synced lyrics", data, err)
+	}
+
+	resp = doRequest(t, s, "POST", "/api/lyrics/sidecar", map[string]interface{}{"filePath": flac}, &body)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("no lyrics: status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+}
+
+func TestHandlePreviewLyricsEmbed_InvalidFile(t *testing.T) {
+	s := newTestServer(t)
+
+	path := filepath.Join(t.TempDir(), "not-a-real-flac.flac")
+	if err := os.WriteFile(path, []byte("nope"), 0644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	var previews []map[string]interface{}
+	resp := doRequest(t, s, "POST", "/api/lyrics/fetch-embed/preview", map[string]interface{}{
+		"filePaths": []string{path},
+	}, &previews)
+
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+	if len(previews) != 1 || previews[0]["filePath"] != path {
+		t.Fatalf("previews = %v, want 1 entry for %s", previews, path)
+	}
+	if _, ok := previews[0]["error"]; !ok {
+		t.Errorf("previews[0] = %v, want an 'error' key", previews[0])
+	}
+	if _, ok := previews[0]["match"]; ok {
+		t.Errorf("previews[0] = %v, want no match", previews[0])
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/app"
+	"flacidal/internal/manifest"
+)
+
+// handleExportManifest implements GET /api/downloads/manifest?url=...&format=json|aria2.
+// Returns the download manifest as an attachment. Mirrors internal/app's
+// App.ExportDownloadManifest, minus the native OS save dialog.
+func (s *Server) handleExportManifest(c *fiber.Ctx) error {
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "url is required"})
+	}
+	format := c.Query("format", "json")
+	if err := manifest.ValidateFormat(format); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if s.sourceManager == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "source manager not initialized"})
+	}
+	config := s.config
+	if config == nil {
+		config = &core.Config{}
+	}
+
+	m, err := app.BuildManifest(s.sourceManager, manifest.TidalResolver{Endpoints: app.TidalEndpoints(config)}, config, rawURL)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	data, ext, err := m.Encode(format)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	contentType := "application/json"
+	if format == "aria2" {
+		contentType = "text/plain"
+	}
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, app.ManifestFileName(m, ext)))
+	return c.Send(data)
+}
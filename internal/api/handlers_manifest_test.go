@@ -0,0 +1,19 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleExportManifest_Validation(t *testing.T) {
+	s := newTestServer(t)
+	for path, want := range map[string]int{
+		"/api/downloads/manifest": fiber.StatusBadRequest,
+		"/api/downloads/manifest?url=https://tidal.com/browse/album/1&format=xml": fiber.StatusBadRequest,
+	} {
+		if resp := doRequest(t, s, "GET", path, nil, nil); resp.StatusCode != want {
+			t.Errorf("GET %s status = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
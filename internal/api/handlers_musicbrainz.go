@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/logging"
+)
+
+// handlePreviewMusicBrainzTags implements POST
+// /api/files/musicbrainz/preview. Body: {"files": [...]}. Mirrors
+// internal/app's App.PreviewMusicBrainzTags.
+func (s *Server) handlePreviewMusicBrainzTags(c *fiber.Ctx) error {
+	return s.musicBrainzTags(c, true)
+}
+
+// handleMusicBrainzTags implements POST /api/files/musicbrainz. Same body
+// as the preview. Mirrors internal/app's App.MusicBrainzTags.
+func (s *Server) handleMusicBrainzTags(c *fiber.Ctx) error {
+	return s.musicBrainzTags(c, false)
+}
+
+func (s *Server) musicBrainzTags(c *fiber.Ctx, dryRun bool) error {
+	var req struct {
+		Files []string `json:"files"`
+	}
+	if err := c.BodyParser(&req); err != nil || len(req.Files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "files are required"})
+	}
+	results := app.MusicBrainzTags(c.UserContext(), s.currentSettings(), req.Files, dryRun)
+	if !dryRun {
+		s.component(logging.Downloads).Info("tagged from MusicBrainz", "written", app.TagsWritten(results), "files", len(req.Files))
+	}
+	return c.JSON(results)
+}
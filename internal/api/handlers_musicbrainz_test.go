@@ -0,0 +1,16 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleMusicBrainzTags_RequiresFiles(t *testing.T) {
+	s := newTestServer(t)
+	for _, path := range []string{"/api/files/musicbrainz/preview", "/api/files/musicbrainz"} {
+		if resp := doRequest(t, s, "POST", path, map[string]any{"files": []string{}}, nil); resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", path, resp.StatusCode)
+		}
+	}
+}
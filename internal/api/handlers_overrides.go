@@ -0,0 +1,27 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/postprocess"
+)
+
+// handleSetTrackOverrides implements POST /api/downloads/overrides/:id.
+// Mirrors internal/app's App.SetTrackOverrides.
+func (s *Server) handleSetTrackOverrides(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+	var overrides postprocess.Overrides
+	if err := c.BodyParser(&overrides); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := overrides.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.postTracks.SetOverrides(id, overrides)
+	return c.JSON(fiber.Map{"success": true})
+}
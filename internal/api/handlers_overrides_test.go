@@ -0,0 +1,30 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/postprocess"
+)
+
+func TestHandleSetTrackOverrides(t *testing.T) {
+	s := newTestServer(t)
+
+	resp := doRequest(t, s, "POST", "/api/downloads/overrides/12", postprocess.Overrides{Title: "Fixed", TrackNumber: 5}, nil)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+	if got, ok := s.postTracks.Take(12); !ok || got.Title != "Fixed" || got.TrackNumber != 5 {
+		t.Errorf("stored track = %+v, %v", got, ok)
+	}
+
+	resp = doRequest(t, s, "POST", "/api/downloads/overrides/12", postprocess.Overrides{TrackNumber: -1}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("negative track number: status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+	resp = doRequest(t, s, "POST", "/api/downloads/overrides/abc", postprocess.Overrides{}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("bad id: status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+}
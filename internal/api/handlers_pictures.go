@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/logging"
+)
+
+// handleListPictures implements GET /api/files/pictures?path=. Mirrors
+// internal/app's App.ListFilePictures.
+func (s *Server) handleListPictures(c *fiber.Ctx) error {
+	path := c.Query("path")
+	if path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "path is required"})
+	}
+	pics, err := app.ListPictures(path)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(pics)
+}
+
+// handleAddPicture implements POST /api/files/pictures. Body: {"path": "...",
+// "data": "<base64 image>", "type": 4, "description": "..."}. Mirrors
+// internal/app's App.AddFilePicture.
+func (s *Server) handleAddPicture(c *fiber.Ctx) error {
+	var req struct {
+		Path        string `json:"path"`
+		Data        string `json:"data"`
+		Type        uint32 `json:"type"`
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Path == "" || req.Data == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "path and data are required"})
+	}
+	img, err := base64.StdEncoding.DecodeString(req.Data)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid image data"})
+	}
+	if err := app.AddPicture(s.currentSettings(), req.Path, img, req.Type, req.Description); err != nil {
+		return fileError(c, fiber.StatusBadRequest, err)
+	}
+	s.component(logging.Downloads).Info("added picture", "file", filepath.Base(req.Path), "type", req.Type)
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// handleRemovePicture implements DELETE /api/files/pictures?path=&index=.
+// Mirrors internal/app's App.RemoveFilePicture.
+func (s *Server) handleRemovePicture(c *fiber.Ctx) error {
+	path := c.Query("path")
+	index, err := strconv.Atoi(c.Query("index"))
+	if path == "" || err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "path and index are required"})
+	}
+	if err := app.RemovePicture(s.currentSettings(), path, index); err != nil {
+		return fileError(c, fiber.StatusBadRequest, err)
+	}
+	s.component(logging.Downloads).Info("removed picture", "file", filepath.Base(path), "index", index)
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// handleSaveCover implements POST /api/files/cover/save. Body: {"path":
+// "...", "output": "..."}; an empty output saves beside the file. Mirrors
+// internal/app's App.SaveCoverArt.
+func (s *Server) handleSaveCover(c *fiber.Ctx) error {
+	var req struct {
+		Path   string `json:"path"`
+		Output string `json:"output"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "path is required"})
+	}
+	written, err := app.SaveCover(req.Path, req.Output)
+	if err != nil {
+		return fileError(c, fiber.StatusBadRequest, err)
+	}
+	s.component(logging.Downloads).Info("saved cover", "file", filepath.Base(req.Path), "to", written)
+	return c.JSON(fiber.Map{"path": written})
+}
+
+// handleSaveFolderCovers implements POST /api/files/covers/save. Body:
+// {"folder": "...", "overwrite": false}. Mirrors internal/app's
+// App.ExtractFolderCovers.
+func (s *Server) handleSaveFolderCovers(c *fiber.Ctx) error {
+	var req struct {
+		Folder    string `json:"folder"`
+		Overwrite bool   `json:"overwrite"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Folder == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "folder is required"})
+	}
+	results, err := app.SaveFolderCovers(req.Folder, req.Overwrite)
+	if err != nil {
+		return fileError(c, fiber.StatusBadRequest, err)
+	}
+	return c.JSON(results)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+)
+
+func TestHandlePictures(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gif := []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+	body := map[string]any{"path": path, "data": base64.StdEncoding.EncodeToString(gif), "type": 4, "description": "back"}
+	if resp := doRequest(t, s, "POST", "/api/files/pictures", body, nil); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("add: status %d", resp.StatusCode)
+	}
+
+	q := "/api/files/pictures?path=" + url.QueryEscape(path)
+	var pics []app.PictureInfo
+	if resp := doRequest(t, s, "GET", q, nil, &pics); resp.StatusCode != fiber.StatusOK || len(pics) != 1 || pics[0].TypeName != "Back cover" || pics[0].MIMEType != "image/gif" {
+		t.Fatalf("list: status %d, %+v", resp.StatusCode, pics)
+	}
+
+	if resp := doRequest(t, s, "DELETE", q+"&index=0", nil, nil); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("remove: status %d", resp.StatusCode)
+	}
+	if resp := doRequest(t, s, "DELETE", q+"&index=0", nil, nil); resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("remove missing: status %d, want 400", resp.StatusCode)
+	}
+	if resp := doRequest(t, s, "POST", "/api/files/pictures", map[string]any{"path": path, "data": "!!"}, nil); resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("bad data: status %d, want 400", resp.StatusCode)
+	}
+}
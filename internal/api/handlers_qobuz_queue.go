@@ -8,6 +8,10 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/app"
+	"flacidal/internal/history"
+	"flacidal/internal/logging"
 )
 
 // handleQueueQobuzDownloads implements POST /api/downloads/queue/qobuz.
@@ -17,6 +21,8 @@ func (s *Server) handleQueueQobuzDownloads(c *fiber.Ctx) error {
 		Tracks      []core.SourceTrack `json:"tracks"`
 		OutputDir   string             `json:"outputDir"`
 		ContentName string             `json:"contentName"`
+		ContentID   string             `json:"contentId"`
+		ContentType string             `json:"contentType"`
 	}
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
@@ -28,14 +34,41 @@ func (s *Server) handleQueueQobuzDownloads(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "no output directory specified"})
 	}
 
-	outputDir := req.OutputDir
-	if req.ContentName != "" {
-		outputDir = filepath.Join(outputDir, core.SanitizeFileName(req.ContentName))
+	queued, err := s.queueQobuzBatch(req.Tracks, req.OutputDir, req.ContentName, req.ContentID, req.ContentType)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"queued": queued})
+}
+
+// queueQobuzBatch queues Qobuz tracks into a contentName subfolder of
+// outputDir and starts their "qobuz:<id>" history record. Shared by
+// handleQueueQobuzDownloads and handleImportURLs.
+func (s *Server) queueQobuzBatch(tracks []core.SourceTrack, outputDir, contentName, contentID, contentType string) (int, error) {
+	if contentName != "" {
+		outputDir = filepath.Join(outputDir, s.folderName(contentName))
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fmt.Sprintf("failed to create folder: %v", err)})
+			return 0, fmt.Errorf("failed to create folder: %w", err)
 		}
 	}
 
-	queued := s.downloadManager.QueueQobuzTracks(req.Tracks, outputDir)
-	return c.JSON(fiber.Map{"queued": queued})
+	fitted := app.FitSourceTracks(&s.postTracks, tracks, outputDir, s.config, s.currentSettings().MaxPathLength)
+	queued := s.downloadManager.QueueQobuzTracks(fitted, outputDir)
+	app.RememberSourceTracks(&s.postTracks, tracks, "qobuz", contentType)
+
+	key := ""
+	if contentID != "" {
+		key = history.Key("qobuz", contentID)
+	}
+	if err := s.batches.Start(s.db, core.DownloadRecord{
+		TidalContentID:   key,
+		TidalContentName: contentName,
+		ContentType:      contentType,
+		TracksTotal:      queued,
+	}, app.SourceTrackIDs(tracks)); err != nil {
+		s.component(logging.Downloads).Warn("failed to save download history", "err", err)
+	} else if err := s.origins.Commit(key); err != nil {
+		s.component(logging.Downloads).Warn("failed to save history origin", "err", err)
+	}
+	return queued, nil
 }
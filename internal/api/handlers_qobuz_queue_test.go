@@ -65,3 +65,35 @@ func TestHandleQueueQobuzDownloads_EmptyTracks(t *testing.T) {
 		t.Errorf("queued = %v, want 0", body["queued"])
 	}
 }
+
+func TestHandleQueueQobuzDownloads_SavesHistory(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	db, err := core.NewDatabase()
+	if err != nil {
+		t.Fatalf("core.NewDatabase: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s := NewServer(ServerConfig{
+		Config:          &core.Config{},
+		DB:              db,
+		DownloadManager: core.NewDownloadManager(core.NewTidalHifiService(), 1),
+	})
+
+	resp := doRequest(t, s, "POST", "/api/downloads/queue/qobuz", map[string]interface{}{
+		"tracks":      []core.SourceTrack{},
+		"outputDir":   t.TempDir(),
+		"contentId":   "abc",
+		"contentType": "album",
+	}, nil)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	rec, err := db.GetDownloadRecord("qobuz:abc")
+	if err != nil {
+		t.Fatalf("GetDownloadRecord: %v", err)
+	}
+	if rec == nil || rec.ContentType != "album" {
+		t.Errorf("history record = %+v, want a qobuz:abc album record", rec)
+	}
+}
@@ -1,10 +1,10 @@
 package api
 
 import (
-	"log"
-
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+
+	"flacidal/internal/logging"
 )
 
 // handleQueueWebSocket upgrades the connection and streams QueueEvents to the client.
@@ -18,14 +18,14 @@ func (s *Server) handleQueueWebSocket(c *websocket.Conn) {
 		Jobs: s.queueBroadcaster.Snapshot(),
 	}
 	if err := c.WriteJSON(snapshot); err != nil {
-		log.Printf("queue ws: snapshot write error: %v", err)
+		s.component(logging.WebSocket).Warn("queue ws: snapshot write error", "err", err)
 		return
 	}
 
 	// Forward events until the client disconnects.
 	for event := range ch {
 		if err := c.WriteJSON(event); err != nil {
-			log.Printf("queue ws: write error: %v", err)
+			s.component(logging.WebSocket).Warn("queue ws: write error", "err", err)
 			return
 		}
 	}
@@ -7,7 +7,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
-	core "github.com/kushiemoon-dev/flacidal-core"
+	"flacidal/internal/app"
 )
 
 // handleQueueArtistAlbum implements POST /api/downloads/queue/album.
@@ -34,16 +34,17 @@ func (s *Server) handleQueueArtistAlbum(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fmt.Sprintf("failed to fetch album: %v", err)})
 	}
 
-	artistFolder := core.SanitizeFileName(req.ArtistName)
+	artistFolder := s.folderName(req.ArtistName)
 	if artistFolder == "" {
-		artistFolder = core.SanitizeFileName(album.Artist)
+		artistFolder = s.folderName(album.Artist)
 	}
-	albumFolder := core.SanitizeFileName(album.Title)
+	albumFolder := s.folderName(album.Title)
 	albumDir := filepath.Join(req.OutputDir, artistFolder, albumFolder)
 	if err := os.MkdirAll(albumDir, 0755); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fmt.Sprintf("failed to create album folder: %v", err)})
 	}
 
-	queued := s.downloadManager.QueueMultiple(album.Tracks, albumDir)
+	queued := s.downloadManager.QueueMultiple(s.fitTidalTracks(album.Tracks, albumDir), albumDir)
+	app.RememberTidalTracks(&s.postTracks, album.Tracks, "album")
 	return c.JSON(fiber.Map{"queued": queued})
 }
@@ -68,3 +68,28 @@ func (s *Server) handleSearchDeezer(c *fiber.Ctx) error {
 
 	return c.JSON(tracks)
 }
+
+// handleGetAlbumEditions implements GET
+// /api/content/editions?artist=&album=. Mirrors internal/app's
+// App.GetAlbumEditions.
+func (s *Server) handleGetAlbumEditions(c *fiber.Ctx) error {
+	artist, album := c.Query("artist"), c.Query("album")
+	if artist == "" || album == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "artist and album are required"})
+	}
+	editions, err := app.AlbumEditions(c.UserContext(), s.currentSettings(), artist, album)
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(editions)
+}
+
+// handleGetEditionURL implements GET /api/content/editions/url?barcode=.
+// Mirrors internal/app's App.GetEditionURL.
+func (s *Server) handleGetEditionURL(c *fiber.Ctx) error {
+	u, err := app.EditionURL(c.Query("barcode"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"url": u})
+}
@@ -84,3 +84,17 @@ func TestHandleSearchDeezer_EmptyQuery(t *testing.T) {
 		t.Errorf("results = %v, want empty for an empty query", results)
 	}
 }
+
+func TestHandleAlbumEditions_MissingParams(t *testing.T) {
+	s := newTestServer(t)
+	for _, path := range []string{"/api/content/editions?artist=A", "/api/content/editions/url"} {
+		var body map[string]interface{}
+		resp := doRequest(t, s, "GET", path, nil, &body)
+		if resp.StatusCode < 400 {
+			t.Errorf("%s: status = %d, want an error", path, resp.StatusCode)
+		}
+		if _, ok := body["error"]; !ok {
+			t.Errorf("%s: body = %v, want an 'error' key", path, body)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/configdiff"
+	"flacidal/internal/naming"
+	"flacidal/internal/settings"
+)
+
+// handleGetSettings implements GET /api/settings.
+// Mirrors internal/app's App.GetSettings.
+func (s *Server) handleGetSettings(c *fiber.Ctx) error {
+	if s.settings == nil {
+		return c.JSON(settings.Settings{})
+	}
+	return c.JSON(s.settings.Get())
+}
+
+// handleSaveSettings implements POST /api/settings. It responds with the
+// changed settings, {"success": true, "changes": [{"key", "effect"}]}, or
+// with the refused one on a validation error (see configdiff). Mirrors
+// internal/app's App.SaveSettings.
+func (s *Server) handleSaveSettings(c *fiber.Ctx) error {
+	if s.settings == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "settings not initialized"})
+	}
+	var req settings.Settings
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "changes": configdiff.Refused(err)})
+	}
+	old := s.settings.Get()
+	if err := s.settings.Update(req); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "changes": app.SettingsChanges(old, req)})
+}
+
+// handleGetFilenameTokens implements GET /api/filename-tokens.
+// Mirrors internal/app's App.GetFilenameTokens.
+func (s *Server) handleGetFilenameTokens(c *fiber.Ctx) error {
+	return c.JSON(naming.Tokens())
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/configdiff"
+	"flacidal/internal/settings"
+)
+
+// Tests for GET/POST /api/settings.
+
+func TestHandleSettings_NotConfigured(t *testing.T) {
+	s := newTestServer(t)
+
+	var got settings.Settings
+	resp := doRequest(t, s, "GET", "/api/settings", nil, &got)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("GET status = %d, want 200", resp.StatusCode)
+	}
+	if got.DiscSubfolders {
+		t.Errorf("got %+v, want defaults", got)
+	}
+
+	var body map[string]interface{}
+	resp = doRequest(t, s, "POST", "/api/settings", map[string]interface{}{"discSubfolders": true}, &body)
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Fatalf("POST status = %d, want %d", resp.StatusCode, fiber.StatusInternalServerError)
+	}
+}
+
+func TestHandleSettings_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := settings.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(ServerConfig{Config: &core.Config{}, Settings: store})
+
+	var body map[string]interface{}
+	resp := doRequest(t, s, "POST", "/api/settings", map[string]interface{}{"discSubfolders": true}, &body)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("POST status = %d, body = %v", resp.StatusCode, body)
+	}
+
+	var got settings.Settings
+	doRequest(t, s, "GET", "/api/settings", nil, &got)
+	if !got.DiscSubfolders {
+		t.Error("GET did not return the saved setting")
+	}
+	if onDisk, _ := settings.Load(dir); !onDisk.DiscSubfolders {
+		t.Error("setting was not persisted")
+	}
+}
+
+func TestHandleGetFilenameTokens(t *testing.T) {
+	s := newTestServer(t)
+
+	var tokens []map[string]interface{}
+	resp := doRequest(t, s, "GET", "/api/filename-tokens", nil, &tokens)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	found := false
+	for _, tok := range tokens {
+		if tok["name"] == "playlistindex" && tok["numeric"] == true {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("tokens = %v, want a numeric playlistindex entry", tokens)
+	}
+}
+
+func TestHandleSaveSettings_RejectsInvalid(t *testing.T) {
+	store, err := settings.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(ServerConfig{Config: &core.Config{}, Settings: store})
+
+	var body struct {
+		Changes []configdiff.Change `json:"changes"`
+	}
+	resp := doRequest(t, s, "POST", "/api/settings", map[string]interface{}{"filenameUnicode": "latin1"}, &body)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+	if len(body.Changes) != 1 || body.Changes[0].Key != "filenameUnicode" || body.Changes[0].Effect != configdiff.Invalid {
+		t.Errorf("changes = %+v, want filenameUnicode invalid", body.Changes)
+	}
+}
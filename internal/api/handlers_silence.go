@@ -0,0 +1,35 @@
+package api
+
+import (
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/logging"
+)
+
+// handleTrimSilence implements POST /api/files/silence.
+// Body: {"file": "...", "dryRun": false}. Mirrors internal/app's
+// App.TrimSilence.
+func (s *Server) handleTrimSilence(c *fiber.Ctx) error {
+	var req struct {
+		File   string `json:"file"`
+		DryRun bool   `json:"dryRun"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.File == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file is required"})
+	}
+
+	report, err := app.TrimSilence(c.UserContext(), req.File, s.currentSettings(), req.DryRun)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if report.Trimmed {
+		s.component(logging.Downloads).Info("trimmed silence", "file", filepath.Base(req.File), "summary", app.SilenceSummary(report))
+	}
+	return c.JSON(report)
+}
@@ -0,0 +1,15 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleTrimSilence_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/files/silence", map[string]any{"dryRun": true}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status without file = %d, want 400", resp.StatusCode)
+	}
+}
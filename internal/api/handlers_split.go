@@ -0,0 +1,34 @@
+package api
+
+import (
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/logging"
+)
+
+// handleSplitAlbum implements POST /api/files/split.
+// Body: {"file": "...", "cue": "..."}, cue being a .cue or tracklist path,
+// pasted tracklist text or an album URL; empty for the .cue beside file.
+// Mirrors internal/app's App.SplitAlbum.
+func (s *Server) handleSplitAlbum(c *fiber.Ctx) error {
+	var req struct {
+		File string `json:"file"`
+		Cue  string `json:"cue"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.File == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file is required"})
+	}
+
+	report, err := app.SplitAlbum(c.UserContext(), s.sourceManager, req.File, req.Cue, s.postOptions())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Downloads).Info("split album", "file", filepath.Base(req.File), "split", report.Split, "tracks", len(report.Pieces))
+	return c.JSON(report)
+}
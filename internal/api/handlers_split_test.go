@@ -0,0 +1,22 @@
+package api
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleSplitAlbum_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/files/split", map[string]any{"cue": "0:00 One\n"}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status without file = %d, want 400", resp.StatusCode)
+	}
+
+	var body map[string]string
+	resp = doRequest(t, s, "POST", "/api/files/split", map[string]any{"file": filepath.Join(t.TempDir(), "rip.ape")}, &body)
+	if resp.StatusCode != fiber.StatusBadRequest || body["error"] == "" {
+		t.Errorf("unsupported format = %d %v, want 400 with an error", resp.StatusCode, body)
+	}
+}
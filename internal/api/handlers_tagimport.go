@@ -0,0 +1,53 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/app"
+	"flacidal/internal/logging"
+)
+
+// handleImportAndTag implements POST /api/downloads/import/tag.
+// Body: {"dir": "...", "url": "...", "dryRun": false}. Mirrors internal/app's
+// App.ImportAndTagFolder.
+func (s *Server) handleImportAndTag(c *fiber.Ctx) error {
+	var req struct {
+		Dir    string `json:"dir"`
+		URL    string `json:"url"`
+		DryRun bool   `json:"dryRun"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.Dir == "" || req.URL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "dir and url are required"})
+	}
+	if s.sourceManager == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "source manager not initialized"})
+	}
+
+	opts := app.TagImportOptions{Post: s.postOptions(), DryRun: req.DryRun}
+	if s.config != nil {
+		opts.OutputDir = s.config.DownloadFolder
+		opts.EmbedCover = s.config.EmbedCover
+		opts.SaveFolderCover = s.config.SaveFolderCover
+		opts.EmbedLyrics = s.config.EmbedLyrics
+		opts.SaveLyricsFile = s.config.SaveLyricsFile
+	}
+	opts.LyricsOutput = s.currentSettings().LyricsOutput
+	opts.Lyrics = s.lyricsCache
+	if opts.OutputDir == "" {
+		opts.OutputDir = core.GetDefaultDownloadFolder()
+	}
+
+	report, err := app.TagImport(s.sourceManager, req.Dir, req.URL, opts)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !req.DryRun {
+		s.component(logging.Downloads).Info("tag import", "dir", req.Dir, "title", report.Title, "summary", app.TagImportSummary(report))
+	}
+	return c.JSON(report)
+}
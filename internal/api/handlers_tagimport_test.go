@@ -0,0 +1,15 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleImportAndTag_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/downloads/import/tag", map[string]any{"url": "https://tidal.com/browse/album/1"}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status without dir = %d, want 400", resp.StatusCode)
+	}
+}
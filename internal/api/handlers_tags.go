@@ -0,0 +1,194 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/logging"
+	"flacidal/internal/tagedit"
+	"flacidal/internal/tagrules"
+)
+
+// setTagsRequest is the body of the batch tag editor endpoints.
+type setTagsRequest struct {
+	Files []string          `json:"files"`
+	Tags  map[string]string `json:"tags"`
+	Mode  string            `json:"mode"`
+}
+
+// handlePreviewSetTags implements POST /api/files/tags/preview.
+// Body: {"files": [...], "tags": {...}, "mode": "merge"|"replace"}.
+// Mirrors internal/app's App.PreviewSetTags.
+func (s *Server) handlePreviewSetTags(c *fiber.Ctx) error {
+	return s.setTags(c, true)
+}
+
+// handleSetTags implements POST /api/files/tags. Same body as the preview.
+// Mirrors internal/app's App.SetTags.
+func (s *Server) handleSetTags(c *fiber.Ctx) error {
+	return s.setTags(c, false)
+}
+
+func (s *Server) setTags(c *fiber.Ctx, dryRun bool) error {
+	var req setTagsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if len(req.Files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "files are required"})
+	}
+	results, err := app.SetTags(s.currentSettings(), req.Files, req.Tags, tagedit.Mode(req.Mode), dryRun)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !dryRun {
+		s.component(logging.Downloads).Info("updated tags", "written", app.TagsWritten(results), "files", len(req.Files))
+	}
+	return c.JSON(results)
+}
+
+// stripTagsRequest is the body of the tag stripping endpoints.
+type stripTagsRequest struct {
+	Files []string `json:"files"`
+	tagedit.StripOptions
+}
+
+// handlePreviewStripTags implements POST /api/files/tags/strip/preview.
+// Body: {"files": [...], "fields": [...], "all": bool, "covers": bool}.
+// Mirrors internal/app's App.PreviewStripTags.
+func (s *Server) handlePreviewStripTags(c *fiber.Ctx) error {
+	return s.stripTags(c, true)
+}
+
+// handleStripTags implements POST /api/files/tags/strip. Same body as the
+// preview. Mirrors internal/app's App.StripTags.
+func (s *Server) handleStripTags(c *fiber.Ctx) error {
+	return s.stripTags(c, false)
+}
+
+func (s *Server) stripTags(c *fiber.Ctx, dryRun bool) error {
+	var req stripTagsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if len(req.Files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "files are required"})
+	}
+	results, err := app.StripTags(s.currentSettings(), req.Files, req.StripOptions, dryRun)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !dryRun {
+		s.component(logging.Downloads).Info("stripped tags", "written", app.TagsWritten(results), "files", len(req.Files))
+	}
+	return c.JSON(results)
+}
+
+// nameTagsRequest is the body of the tags-from-filename endpoints.
+type nameTagsRequest struct {
+	Files   []string `json:"files"`
+	Pattern string   `json:"pattern"`
+}
+
+// handlePreviewTagsFromNames implements POST
+// /api/files/tags/filename/preview. Body: {"files": [...], "pattern":
+// "{track} - {artist} - {title}"}. Mirrors internal/app's
+// App.PreviewTagsFromNames.
+func (s *Server) handlePreviewTagsFromNames(c *fiber.Ctx) error {
+	return s.tagsFromNames(c, true)
+}
+
+// handleTagsFromNames implements POST /api/files/tags/filename. Same body
+// as the preview. Mirrors internal/app's App.TagsFromNames.
+func (s *Server) handleTagsFromNames(c *fiber.Ctx) error {
+	return s.tagsFromNames(c, false)
+}
+
+func (s *Server) tagsFromNames(c *fiber.Ctx, dryRun bool) error {
+	var req nameTagsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if len(req.Files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "files are required"})
+	}
+	results, err := app.TagsFromNames(s.currentSettings(), req.Files, req.Pattern, dryRun)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !dryRun {
+		s.component(logging.Downloads).Info("tagged from file names", "written", app.TagsWritten(results), "files", len(req.Files))
+	}
+	return c.JSON(results)
+}
+
+// normalizeTagsRequest is the body of the tag normalization endpoints.
+type normalizeTagsRequest struct {
+	Files []string       `json:"files"`
+	Rules tagrules.Rules `json:"rules"`
+}
+
+// handlePreviewNormalizeTags implements POST
+// /api/files/tags/normalize/preview. Body: {"files": [...], "rules":
+// {"titleCase", "featuring", "stripRemaster", "stripExplicit"}}. Mirrors
+// internal/app's App.PreviewNormalizeTags.
+func (s *Server) handlePreviewNormalizeTags(c *fiber.Ctx) error {
+	return s.normalizeTags(c, true)
+}
+
+// handleNormalizeTags implements POST /api/files/tags/normalize. Same body
+// as the preview. Mirrors internal/app's App.NormalizeTags.
+func (s *Server) handleNormalizeTags(c *fiber.Ctx) error {
+	return s.normalizeTags(c, false)
+}
+
+func (s *Server) normalizeTags(c *fiber.Ctx, dryRun bool) error {
+	var req normalizeTagsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if len(req.Files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "files are required"})
+	}
+	results, err := app.NormalizeTags(s.currentSettings(), req.Files, req.Rules, dryRun)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !dryRun {
+		s.component(logging.Downloads).Info("normalized tags", "written", app.TagsWritten(results), "files", len(req.Files))
+	}
+	return c.JSON(results)
+}
+
+// handlePreviewRemapGenres implements POST /api/files/tags/genres/preview.
+// Body: {"files": [...]}; the mapping is the genreMap setting. Mirrors
+// internal/app's App.PreviewRemapGenres.
+func (s *Server) handlePreviewRemapGenres(c *fiber.Ctx) error {
+	return s.remapGenres(c, true)
+}
+
+// handleRemapGenres implements POST /api/files/tags/genres. Same body as
+// the preview. Mirrors internal/app's App.RemapGenres.
+func (s *Server) handleRemapGenres(c *fiber.Ctx) error {
+	return s.remapGenres(c, false)
+}
+
+func (s *Server) remapGenres(c *fiber.Ctx, dryRun bool) error {
+	var req struct {
+		Files []string `json:"files"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if len(req.Files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "files are required"})
+	}
+	results, err := app.RemapGenres(s.currentSettings(), req.Files, dryRun)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !dryRun {
+		s.component(logging.Downloads).Info("remapped genres", "written", app.TagsWritten(results), "files", len(req.Files))
+	}
+	return c.JSON(results)
+}
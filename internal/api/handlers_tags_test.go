@@ -0,0 +1,141 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/tagedit"
+)
+
+func TestHandleSetTags(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := flacmeta.UpdateComments(path, func(c *flacmeta.Comments) { c.Set("GENRE", "Rock") }); err != nil {
+		t.Fatal(err)
+	}
+	body := map[string]any{"files": []string{path}, "tags": map[string]string{"genre": "Jazz"}}
+
+	var preview []tagedit.Result
+	resp := doRequest(t, s, "POST", "/api/files/tags/preview", body, &preview)
+	if resp.StatusCode != fiber.StatusOK || len(preview) != 1 || len(preview[0].Changes) != 1 || preview[0].Written {
+		t.Fatalf("preview: status %d, %+v", resp.StatusCode, preview)
+	}
+
+	var results []tagedit.Result
+	resp = doRequest(t, s, "POST", "/api/files/tags", body, &results)
+	if resp.StatusCode != fiber.StatusOK || len(results) != 1 || !results[0].Written {
+		t.Fatalf("set: status %d, %+v", resp.StatusCode, results)
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := f.Comments()
+	if got := c.Get("GENRE"); got != "Jazz" {
+		t.Errorf("GENRE = %q, want Jazz", got)
+	}
+}
+
+func TestHandleSetTags_Validation(t *testing.T) {
+	s := newTestServer(t)
+	for name, body := range map[string]map[string]any{
+		"no files":     {"tags": map[string]string{"GENRE": "x"}},
+		"bad mode":     {"files": []string{"a.flac"}, "tags": map[string]string{"GENRE": "x"}, "mode": "append"},
+		"bad tag name": {"files": []string{"a.flac"}, "tags": map[string]string{"A=B": "x"}},
+	} {
+		if resp := doRequest(t, s, "POST", "/api/files/tags", body, nil); resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandleStripTags(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := flacmeta.UpdateComments(path, func(c *flacmeta.Comments) { c.Set("TITLE", "Song"); c.Set("COMMENT", "x") }); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []tagedit.Result
+	resp := doRequest(t, s, "POST", "/api/files/tags/strip", map[string]any{"files": []string{path}, "fields": []string{"comment"}}, &results)
+	if resp.StatusCode != fiber.StatusOK || len(results) != 1 || !results[0].Written {
+		t.Fatalf("status %d, %+v", resp.StatusCode, results)
+	}
+
+	resp = doRequest(t, s, "POST", "/api/files/tags/strip/preview", map[string]any{"files": []string{path}}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("nothing to strip: status %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestHandleTagsFromNames(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "05 - Song.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	body := map[string]any{"files": []string{path}, "pattern": "{track} - {title}"}
+
+	var preview []tagedit.Result
+	resp := doRequest(t, s, "POST", "/api/files/tags/filename/preview", body, &preview)
+	if resp.StatusCode != fiber.StatusOK || len(preview) != 1 || len(preview[0].Changes) != 2 || preview[0].Written {
+		t.Fatalf("preview: status %d, %+v", resp.StatusCode, preview)
+	}
+	var results []tagedit.Result
+	resp = doRequest(t, s, "POST", "/api/files/tags/filename", body, &results)
+	if resp.StatusCode != fiber.StatusOK || len(results) != 1 || !results[0].Written {
+		t.Fatalf("apply: status %d, %+v", resp.StatusCode, results)
+	}
+	if resp := doRequest(t, s, "POST", "/api/files/tags/filename", map[string]any{"files": []string{path}, "pattern": "{bogus}"}, nil); resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("bad pattern: status %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestHandleNormalizeTags(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := flacmeta.UpdateComments(path, func(c *flacmeta.Comments) {
+		c.Set("TITLE", "song (Remastered 2011)")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	body := map[string]any{"files": []string{path}, "rules": map[string]bool{"titleCase": true, "stripRemaster": true}}
+
+	var preview []tagedit.Result
+	resp := doRequest(t, s, "POST", "/api/files/tags/normalize/preview", body, &preview)
+	if resp.StatusCode != fiber.StatusOK || len(preview) != 1 || len(preview[0].Changes) != 1 || preview[0].Written {
+		t.Fatalf("preview: status %d, %+v", resp.StatusCode, preview)
+	}
+	if got := preview[0].Changes[0].New; len(got) != 1 || got[0] != "Song" {
+		t.Errorf("new TITLE = %v, want Song", got)
+	}
+	var results []tagedit.Result
+	resp = doRequest(t, s, "POST", "/api/files/tags/normalize", body, &results)
+	if resp.StatusCode != fiber.StatusOK || len(results) != 1 || !results[0].Written {
+		t.Fatalf("apply: status %d, %+v", resp.StatusCode, results)
+	}
+	if resp := doRequest(t, s, "POST", "/api/files/tags/normalize", map[string]any{"files": []string{path}}, nil); resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("no rules: status %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestHandleRemapGenres_NoMapping(t *testing.T) {
+	s := newTestServer(t)
+	body := map[string]any{"files": []string{filepath.Join(t.TempDir(), "track.flac")}}
+	if resp := doRequest(t, s, "POST", "/api/files/tags/genres/preview", body, nil); resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status %d, want 400", resp.StatusCode)
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+
+	"flacidal/internal/app"
+	"flacidal/internal/logging"
+)
+
+// handleValidateFLAC implements GET /api/files/validate?path=. It returns
+// {"broken": bool, "reason": "..."}. Mirrors internal/app's App.ValidateFLAC.
+func (s *Server) handleValidateFLAC(c *fiber.Ctx) error {
+	path := c.Query("path")
+	if path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "path is required"})
+	}
+	reason, err := app.ValidateFLAC(path)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"broken": reason != "", "reason": reason})
+}
+
+// handleRedownloadBrokenFile implements POST /api/files/redownload.
+// Body: {"file": "..."}. Mirrors internal/app's App.RedownloadBrokenFile.
+func (s *Server) handleRedownloadBrokenFile(c *fiber.Ctx) error {
+	var req struct {
+		File string `json:"file"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.File == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file is required"})
+	}
+	track, err := app.RedownloadBroken(s.downloader, s.downloadManager, req.File, s.downloadFolder())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.component(logging.Downloads).Info("re-downloading broken file", "file", filepath.Base(req.File), "trackId", track.ID)
+	return c.JSON(track)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleValidateFLAC(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "broken.flac")
+	if err := os.WriteFile(path, []byte("fLaC"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Broken bool   `json:"broken"`
+		Reason string `json:"reason"`
+	}
+	resp := doRequest(t, s, "GET", "/api/files/validate?path="+url.QueryEscape(path), nil, &got)
+	if resp.StatusCode != fiber.StatusOK || !got.Broken || got.Reason == "" {
+		t.Errorf("status %d, %+v; want a broken file with a reason", resp.StatusCode, got)
+	}
+
+	resp = doRequest(t, s, "GET", "/api/files/validate", nil, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status without path = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestHandleRedownloadBrokenFile_Validation(t *testing.T) {
+	s := newTestServer(t)
+	resp := doRequest(t, s, "POST", "/api/files/redownload", map[string]any{}, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status without file = %d, want 400", resp.StatusCode)
+	}
+}
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/google/uuid"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
 )
 
 // QueueEvent is a typed event emitted by the download system.
@@ -111,3 +114,36 @@ func (b *QueueBroadcaster) updateSnapshot(event QueueEvent) {
 		}
 	}
 }
+
+// queueEventFor maps a download progress event to the queue event sent to
+// /ws/queue subscribers; ok is false for statuses the queue view ignores.
+func queueEventFor(ev core.DownloadEvent) (event QueueEvent, ok bool) {
+	event = QueueEvent{JobID: fmt.Sprintf("%d", ev.TrackID)}
+	result := ev.Result
+	if result != nil {
+		event.Title = result.Title
+		event.Artist = result.Artist
+	}
+
+	switch ev.Status {
+	case "queued":
+		event.Type = "queued"
+	case "downloading":
+		event.Type = "started"
+		// Compute 0-100 progress from byte counters when available.
+		if result != nil && result.BytesTotal > 0 {
+			event.Type = "progress"
+			event.Progress = int(result.BytesDownloaded * 100 / result.BytesTotal)
+		}
+	case "completed":
+		event.Type = "completed"
+	case "error", "cancelled":
+		event.Type = "failed"
+		if result != nil {
+			event.Error = result.Error
+		}
+	default:
+		return QueueEvent{}, false
+	}
+	return event, true
+}
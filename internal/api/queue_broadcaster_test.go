@@ -0,0 +1,34 @@
+package api
+
+import (
+	"testing"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+)
+
+func TestQueueEventFor(t *testing.T) {
+	tests := []struct {
+		ev   core.DownloadEvent
+		want QueueEvent
+	}{
+		{core.DownloadEvent{TrackID: 1, Status: "queued"}, QueueEvent{Type: "queued", JobID: "1"}},
+		{
+			core.DownloadEvent{TrackID: 2, Status: "downloading", Result: &core.DownloadResult{Title: "T", BytesDownloaded: 25, BytesTotal: 100}},
+			QueueEvent{Type: "progress", JobID: "2", Title: "T", Progress: 25},
+		},
+		{
+			core.DownloadEvent{TrackID: 3, Status: "cancelled", Result: &core.DownloadResult{Error: "stopped"}},
+			QueueEvent{Type: "failed", JobID: "3", Error: "stopped"},
+		},
+	}
+	for _, tt := range tests {
+		got, ok := queueEventFor(tt.ev)
+		if !ok || got.Type != tt.want.Type || got.JobID != tt.want.JobID || got.Title != tt.want.Title ||
+			got.Progress != tt.want.Progress || got.Error != tt.want.Error {
+			t.Errorf("queueEventFor(%+v) = %+v, %v; want %+v", tt.ev, got, ok, tt.want)
+		}
+	}
+	if _, ok := queueEventFor(core.DownloadEvent{TrackID: 4, Status: "cooldown"}); ok {
+		t.Error("unknown status should be ignored")
+	}
+}
@@ -2,23 +2,45 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"embed"
 	"fmt"
 	"io/fs"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/pprof"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/websocket/v2"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/analysisstore"
+	"flacidal/internal/app"
+	"flacidal/internal/coverproxy"
+	"flacidal/internal/coverstore"
+	"flacidal/internal/credits"
+	"flacidal/internal/downloads"
+	"flacidal/internal/events"
+	"flacidal/internal/fileerr"
+	"flacidal/internal/history"
+	"flacidal/internal/incomplete"
+	"flacidal/internal/jobs"
+	"flacidal/internal/library"
+	"flacidal/internal/logging"
+	"flacidal/internal/lyricscache"
+	"flacidal/internal/metacache"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/settings"
+	"flacidal/internal/timestamp"
+	"flacidal/internal/watchfolder"
 )
 
 // defaultFrontendDir is where the built Svelte SPA is expected to live on
@@ -29,26 +51,69 @@ const defaultFrontendDir = "frontend/dist"
 type ServerConfig struct {
 	Config          *core.Config
 	DB              *core.Database
-	DownloadManager *core.DownloadManager
+	Downloader      *core.TidalHifiService // DownloadManager's downloader; config changes update its options
+	DownloadManager *core.DownloadManager  // started with app.MaxDownloadWorkers; the server holds it to ConcurrentDownloads
 	SourceManager   *core.SourceManager
 	TidalSource     *core.TidalSource
 	QobuzSource     *core.QobuzSource
 	LyricsClient    *core.LyricsClient
+	LyricsCache     *lyricscache.Cache   // LRCLIB lookups; nil looks every track up
+	Settings        *settings.Store      // App-local settings; nil disables /api/settings
+	HistoryOrigins  *history.Origins     // Source URLs of history records; nil refetches Tidal records only
+	HistoryTimings  *history.Timings     // State transitions and timings of finished downloads; nil keeps none
+	Covers          *coverstore.Store    // Content-addressed cover cache; nil disables /api/covers
+	CoverProxy      *coverproxy.Proxy    // Remote cover images for web clients; nil disables /api/proxy/cover
+	Library         *library.Index       // Indexed tags of the library's FLACs; nil disables /api/library
+	Credits         *credits.Store       // Fetched album credits; nil fetches them every time
+	Analyses        *analysisstore.Store // Saved quality analyses; nil saves none
 	Context         context.Context
-	FrontendFS      embed.FS // Embedded frontend assets
-	FrontendDir     string   // Filesystem path to the built SPA when FrontendFS is empty (default: "frontend/dist")
+	FrontendFS      embed.FS        // Embedded frontend assets
+	FrontendDir     string          // Filesystem path to the built SPA when FrontendFS is empty (default: "frontend/dist")
+	Pprof           bool            // Expose net/http/pprof under /debug/pprof (off by default)
+	PprofToken      string          // Optional shared secret required to reach /debug/pprof; without it, APIToken guards it when set, and the endpoints are open otherwise
+	APIToken        string          // Token required for /api and /ws; empty leaves them open
+	GuestToken      string          // Read-only token (see auth.go); needs APIToken
+	LogLevels       *logging.Levels // Per-component log levels, adjustable via /api/logs/levels (default: info everywhere)
 }
 
 // Server represents the HTTP API server
 type Server struct {
 	app              *fiber.App
 	config           *core.Config
+	configMu         sync.Mutex // held while the config changes; see updateConfig
 	db               *core.Database
+	downloader       *core.TidalHifiService
 	downloadManager  *core.DownloadManager
+	downloadGate     downloads.Gate
 	sourceManager    *core.SourceManager
 	tidalSource      *core.TidalSource
 	qobuzSource      *core.QobuzSource
 	lyricsClient     *core.LyricsClient
+	lyricsCache      *lyricscache.Cache
+	settings         *settings.Store
+	postTracks       postprocess.Registry
+	batches          app.ContentBatches
+	origins          *history.Origins
+	timings          *history.Timings
+	covers           *coverstore.Store
+	coverProxy       *coverproxy.Proxy
+	library          *library.Index
+	credits          *credits.Store
+	analyses         *analysisstore.Store
+	jobs             downloads.Tracker
+	throughput       downloads.Throughput
+	finisher         downloads.Finisher
+	downloadEvents   events.Bus[core.DownloadEvent]
+	fileBatches      jobs.Manager
+	analysisJobs     jobs.Manager
+	conversionJobs   jobs.Manager
+	fileMeta         metacache.Cache
+	stopWatchFolder  context.CancelFunc
+	stopCleanup      context.CancelFunc
+	stopLibraryScan  context.CancelFunc
+	logLevels        *logging.Levels
+	log              *slog.Logger
+	apiToken         string
 	wsHub            *WebSocketHub
 	queueBroadcaster *QueueBroadcaster
 	ctx              context.Context
@@ -64,8 +129,14 @@ func NewServer(cfg ServerConfig) *Server {
 		BodyLimit:    50 * 1024 * 1024, // 50MB
 	})
 
+	logLevels := cfg.LogLevels
+	if logLevels == nil {
+		logLevels = &logging.Levels{}
+	}
+	baseLog := logging.NewText(logLevels, os.Stderr)
+
 	// Create WebSocket hub
-	wsHub := NewWebSocketHub()
+	wsHub := NewWebSocketHub(logging.Component(baseLog, logging.WebSocket))
 	go wsHub.Run()
 
 	// Create queue event broadcaster
@@ -80,82 +151,158 @@ func NewServer(cfg ServerConfig) *Server {
 		app:              app,
 		config:           cfg.Config,
 		db:               cfg.DB,
+		downloader:       cfg.Downloader,
 		downloadManager:  cfg.DownloadManager,
 		sourceManager:    cfg.SourceManager,
 		tidalSource:      cfg.TidalSource,
 		qobuzSource:      cfg.QobuzSource,
 		lyricsClient:     cfg.LyricsClient,
+		lyricsCache:      cfg.LyricsCache,
+		settings:         cfg.Settings,
+		origins:          cfg.HistoryOrigins,
+		timings:          cfg.HistoryTimings,
+		covers:           cfg.Covers,
+		coverProxy:       cfg.CoverProxy,
+		library:          cfg.Library,
+		credits:          cfg.Credits,
+		analyses:         cfg.Analyses,
 		wsHub:            wsHub,
 		queueBroadcaster: queueBroadcaster,
 		ctx:              cfg.Context,
 		frontendFS:       cfg.FrontendFS,
 		frontendDir:      frontendDir,
+		logLevels:        logLevels,
+		log:              baseLog,
+		apiToken:         cfg.APIToken,
 	}
 
-	// Hook queue events into the download manager's progress callback.
-	// This forwards queued/downloading/completed/failed states to all WS subscribers.
+	// The download manager's single progress callback publishes to
+	// server.downloadEvents; the WebSocket hubs listen there alongside any
+	// other subscriber (see DownloadEvents).
+	server.downloadEvents.Keep = app.KeepDownloadEvent
+	events.Listen(&server.downloadEvents, 256, server.sendDownloadEvent)
+	events.Listen(&server.downloadEvents, 256, func(ev core.DownloadEvent) {
+		if qe, ok := queueEventFor(ev); ok {
+			queueBroadcaster.Broadcast(qe)
+		}
+	})
+	events.Listen(&server.fileBatches.Events, 256, func(ev jobs.Event) {
+		wsHub.Broadcast(batchMessage(ev))
+	})
+	events.Listen(&server.analysisJobs.Events, 256, func(ev jobs.Event) {
+		if ev.State.Finished() {
+			server.component(logging.Server).Info("analysis job finished", "id", ev.ID, "state", ev.State, "files", ev.Processed, "summary", app.AnalysisSummary(ev.Summary))
+		}
+		wsHub.Broadcast(analysisMessage(ev))
+	})
+	events.Listen(&server.conversionJobs.Events, 256, func(ev jobs.Event) {
+		if ev.State.Finished() {
+			server.component(logging.Server).Info("conversion job finished", "id", ev.ID, "state", ev.State, "files", ev.Processed, "failed", ev.Failed)
+		}
+		wsHub.Broadcast(conversionJobMessage(ev))
+	})
 	if cfg.DownloadManager != nil {
+		if cfg.Config != nil {
+			server.downloadGate.SetLimit(app.DownloadWorkers(cfg.Config.ConcurrentDownloads))
+		}
 		cfg.DownloadManager.SetProgressCallback(func(trackID int, status string, result *core.DownloadResult) {
-			jobID := fmt.Sprintf("%d", trackID)
-			event := QueueEvent{JobID: jobID}
-
-			title := ""
-			artist := ""
-			if result != nil {
-				if result.Title != "" {
-					title = result.Title
-				}
-				if result.Artist != "" {
-					artist = result.Artist
+			status = app.GateDownload(&server.downloadGate, trackID, status)
+			status = app.RejectBroken(server.currentSettings(), status, result)
+			event := core.DownloadEvent{TrackID: trackID, Status: status, Result: result}
+			// Record the job first, so its time and speed are the download's alone
+			server.recordDownloadEvent(event)
+			finish := func() {
+				if err := server.FinishDownload(trackID, status, result); err != nil {
+					server.component(logging.Downloads).Warn("post-download processing failed", "track", trackID, "err", err)
 				}
-			}
-			event.Title = title
-			event.Artist = artist
-
-			switch status {
-			case "queued":
-				event.Type = "queued"
-			case "downloading":
-				event.Type = "started"
-				// Compute 0-100 progress from byte counters when available.
-				if result != nil && result.BytesTotal > 0 {
-					event.Type = "progress"
-					event.Progress = int(result.BytesDownloaded * 100 / result.BytesTotal)
+				if status == "completed" && result != nil {
+					if err := app.LinkDownload(server.library, server.libraryRoots(), trackID, result.FilePath); err != nil {
+						server.component(logging.Downloads).Warn("failed to index download", "track", trackID, "err", err)
+					}
 				}
-			case "completed":
-				event.Type = "completed"
-			case "error", "cancelled":
-				event.Type = "failed"
-				if result != nil {
-					event.Error = result.Error
+				if err := server.batches.Finish(server.db, trackID, status); err != nil {
+					server.component(logging.Downloads).Warn("failed to update download history", "err", err)
 				}
-			default:
-				return
+				server.downloadEvents.Publish(event)
+			}
+			if status == "completed" {
+				server.finisher.Go(finish)
+			} else {
+				finish()
 			}
-
-			queueBroadcaster.Broadcast(event)
 		})
 
 		cfg.DownloadManager.SetJobCompleteCallback(func(entry core.HistoryEntry) {
 			if cfg.DB != nil {
+				timestamp.UTCFields(&entry)
 				if err := cfg.DB.InsertHistoryEntry(entry); err != nil {
-					log.Printf("WARN: failed to insert history entry: %v", err)
+					server.component(logging.Downloads).Warn("failed to insert history entry", "err", err)
 				}
 			}
 		})
 	}
 
+	// Import URL lists dropped into Settings.WatchFolder
+	if cfg.Settings != nil && cfg.DownloadManager != nil && cfg.SourceManager != nil {
+		var watchCtx context.Context
+		watchCtx, server.stopWatchFolder = context.WithCancel(context.Background())
+		go watchfolder.Run(watchCtx, watchfolder.Interval, func() string {
+			return server.currentSettings().WatchFolder
+		}, server.importWatchedFile, func(err error) {
+			server.component(logging.Downloads).Warn("watch folder scan failed", "err", err)
+		})
+	}
+
+	// Delete what interrupted downloads left behind, once it is old enough
+	// (opt-in via Settings.IncompleteCleanupDays)
+	if cfg.Settings != nil {
+		var cleanupCtx context.Context
+		cleanupCtx, server.stopCleanup = context.WithCancel(context.Background())
+		go incomplete.Run(cleanupCtx, incompleteCleanupInterval, server.libraryRoots, func() time.Duration {
+			return app.CleanupAge(server.currentSettings())
+		}, server.reportIncompleteCleanup)
+	}
+
+	// Archive the covers of queued playlists for the history
+	if cfg.HistoryOrigins != nil {
+		cfg.HistoryOrigins.FetchCover = app.HistoryCoverFetcher(server.currentSettings)
+	}
+
+	// Pick up what changed in the library while the server wasn't running,
+	// then what other programs put there (opt-in via Settings.WatchLibrary)
+	if cfg.Library != nil {
+		var scanCtx context.Context
+		scanCtx, server.stopLibraryScan = context.WithCancel(context.Background())
+		go server.scanLibrary(scanCtx) //nolint:errcheck // logged
+		go server.watchLibrary(scanCtx)
+	}
+
 	// Middleware
 	app.Use(recover.New())
-	app.Use(logger.New(logger.Config{
-		Format: "[${time}] ${status} - ${method} ${path} (${latency})\n",
-	}))
+	app.Use(accessLog(server.component(logging.HTTP)))
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
 		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
 	}))
 
+	if cfg.Pprof {
+		guard := pprofGuard(cfg.PprofToken)
+		if cfg.PprofToken == "" && cfg.APIToken != "" {
+			// A locked-down server doesn't serve profiles to anyone: without
+			// a secret of its own, pprof takes the admin API token.
+			guard = authGuard(cfg.APIToken, "", server.libraryRoots)
+		}
+		app.Use("/debug/pprof", guard)
+		app.Use(pprof.New())
+		server.component(logging.Server).Warn("pprof endpoints enabled at /debug/pprof")
+	}
+
+	if cfg.APIToken != "" {
+		app.Use("/api", authGuard(cfg.APIToken, cfg.GuestToken, server.libraryRoots))
+		app.Use("/ws", authGuard(cfg.APIToken, cfg.GuestToken, server.libraryRoots))
+	}
+
 	// Setup routes
 	server.setupRoutes()
 
@@ -171,13 +318,17 @@ func (s *Server) setupRoutes() {
 	api := s.app.Group("/api")
 
 	// Config routes
-	api.Get("/config", s.handleGetConfig)
+	api.Get("/config", cacheFor(revalidate), s.handleGetConfig)
 	api.Post("/config", s.handleSaveConfig)
 	api.Post("/config/reset", s.handleResetConfig)
+	api.Get("/settings", cacheFor(revalidate), s.handleGetSettings)
+	api.Post("/settings", s.handleSaveSettings)
+	api.Get("/filename-tokens", cacheFor(listMaxAge), s.handleGetFilenameTokens)
+	api.Post("/doctor", s.handleRunDoctor)
 
 	// Source routes
-	api.Get("/sources", s.handleGetSources)
-	api.Get("/sources/preferred", s.handleGetPreferredSource)
+	api.Get("/sources", cacheFor(revalidate), s.handleGetSources)
+	api.Get("/sources/preferred", cacheFor(revalidate), s.handleGetPreferredSource)
 	api.Post("/sources/preferred", s.handleSetPreferredSource)
 	api.Post("/sources/detect", s.handleDetectSource)
 	api.Post("/sources/order", s.handleSetSourceOrder)
@@ -191,6 +342,9 @@ func (s *Server) setupRoutes() {
 	api.Get("/content/search/albums", s.handleSearchTidalAlbums)
 	api.Get("/content/search/artists", s.handleSearchTidalArtists)
 	api.Get("/content/search/deezer", s.handleSearchDeezer)
+	api.Get("/content/editions", s.handleGetAlbumEditions)
+	api.Get("/content/editions/url", s.handleGetEditionURL)
+	api.Get("/content/credits", s.handleGetAlbumCredits)
 
 	// Download routes
 	api.Get("/downloads/queue", s.handleGetQueue)
@@ -198,7 +352,10 @@ func (s *Server) setupRoutes() {
 	api.Post("/downloads/queue/album", s.handleQueueArtistAlbum)
 	api.Post("/downloads/queue/qobuz", s.handleQueueQobuzDownloads)
 	api.Post("/downloads/single", s.handleQueueSingle)
+	api.Post("/downloads/import", s.handleImportURLs)
+	api.Post("/downloads/overrides/:id", s.handleSetTrackOverrides)
 	api.Get("/downloads/status", s.handleGetQueueStatus)
+	api.Get("/downloads/jobs", s.handleGetDownloadJobs)
 	api.Get("/downloads/options", s.handleGetDownloadOptions)
 	api.Post("/downloads/options", s.handleSetDownloadOptions)
 	api.Post("/downloads/retry/:id", s.handleRetryDownload)
@@ -208,29 +365,88 @@ func (s *Server) setupRoutes() {
 	api.Post("/downloads/resume", s.handleResumeDownloads)
 	api.Get("/downloads/paused", s.handleIsPaused)
 	api.Get("/downloads/export", s.handleExportFailedDownloads)
+	api.Get("/downloads/manifest", s.handleExportManifest)
+	api.Post("/downloads/import/tag", s.handleImportAndTag)
+	api.Post("/downloads/completeness", s.handleCheckAlbumCompleteness)
+	api.Post("/downloads/completeness/queue", s.handleQueueMissingTracks)
 
 	// History routes
 	api.Get("/history", s.handleGetHistory)
 	api.Get("/history/filtered", s.handleGetHistoryFiltered)
+	api.Get("/history/heatmap", s.handleGetActivityHeatmap)
+	api.Get("/history/snapshots", s.handleGetHistorySnapshots)
+	api.Get("/history/timings", s.handleGetHistoryTimings)
+	api.Get("/history/cover", s.handleGetHistoryCover)
+	api.Get("/history/file", s.handleGetHistoryFile)
 	api.Delete("/history/:id", s.handleDeleteHistory)
 	api.Post("/history/clear", s.handleClearHistory)
 	api.Post("/history/refetch/:id", s.handleRefetchFromHistory)
+	api.Get("/history/completeness/:id", s.handleCheckHistoryCompleteness)
 	api.Get("/history/recent", s.handleGetRecentAlbums)
 
 	// Files routes
 	api.Get("/files", s.handleListFiles)
 	api.Delete("/files", s.handleDeleteFile)
+	api.Get("/files/export", s.handleExportLibrary)
 	api.Get("/files/metadata", s.handleGetMetadata)
-	api.Get("/files/cover", s.handleGetCoverArt)
-	api.Get("/files/templates", s.handleGetRenameTemplates)
+	api.Get("/files/cover", cacheFor(revalidate), s.handleGetCoverArt)
+	api.Get("/files/cover/thumbnail", s.handleGetFileThumbnail)
+	api.Post("/files/cover/save", s.handleSaveCover)
+	api.Post("/files/covers/save", s.handleSaveFolderCovers)
+	api.Get("/files/pictures", s.handleListPictures)
+	api.Post("/files/pictures", s.handleAddPicture)
+	api.Delete("/files/pictures", s.handleRemovePicture)
+	api.Get("/covers", cacheFor(revalidate), s.handleLibraryCovers)
+	api.Get("/covers/:hash/thumbnail", cacheFor(hashedMaxAge), s.handleCoverThumbnail)
+	api.Get("/proxy/cover", s.handleProxyCover)
+
+	// Library index
+	api.Get("/library", s.handleGetLibraryStatus)
+	api.Post("/library/scan", s.handleScanLibrary)
+	api.Get("/library/tracks", s.handleBrowseLibrary)
+	api.Get("/library/artists", s.handleGetLibraryArtists)
+	api.Get("/library/albums", s.handleGetLibraryAlbums)
+	api.Get("/library/labels", s.handleGetLibraryLabels)
+	api.Get("/library/duplicates", s.handleGetLibraryDuplicates)
+	api.Get("/files/templates", cacheFor(listMaxAge), s.handleGetRenameTemplates)
 	api.Post("/files/rename/preview", s.handlePreviewRename)
 	api.Post("/files/rename", s.handleRenameFiles)
+	api.Post("/files/split", s.handleSplitAlbum)
+	api.Post("/files/silence", s.handleTrimSilence)
+	api.Get("/files/incomplete", s.handleListIncompleteFiles)
+	api.Delete("/files/incomplete", s.handleDeleteIncompleteFile)
+	api.Post("/files/incomplete/requeue", s.handleRequeueIncompleteFile)
+	api.Get("/files/validate", s.handleValidateFLAC)
+	api.Post("/files/redownload", s.handleRedownloadBrokenFile)
+	api.Post("/files/tags/preview", s.handlePreviewSetTags)
+	api.Post("/files/tags", s.handleSetTags)
+	api.Post("/files/tags/strip/preview", s.handlePreviewStripTags)
+	api.Post("/files/tags/strip", s.handleStripTags)
+	api.Post("/files/tags/filename/preview", s.handlePreviewTagsFromNames)
+	api.Post("/files/tags/filename", s.handleTagsFromNames)
+	api.Post("/files/tags/normalize/preview", s.handlePreviewNormalizeTags)
+	api.Post("/files/tags/normalize", s.handleNormalizeTags)
+	api.Post("/files/tags/genres/preview", s.handlePreviewRemapGenres)
+	api.Post("/files/tags/genres", s.handleRemapGenres)
+	api.Post("/files/musicbrainz/preview", s.handlePreviewMusicBrainzTags)
+	api.Post("/files/musicbrainz", s.handleMusicBrainzTags)
+	api.Get("/files/acoustid/status", s.handleGetAcoustIDInfo)
+	api.Post("/files/acoustid/preview", s.handlePreviewAcoustIDTags)
+	api.Post("/files/acoustid", s.handleAcoustIDTags)
+	api.Get("/batches", s.handleListBatches)
+	api.Post("/batches", s.handleStartBatch)
+	api.Get("/batches/:id", s.handleGetBatch)
+	api.Post("/batches/:id/undo", s.handleUndoBatch)
 
 	// Conversion routes
 	api.Get("/convert/available", s.handleIsConverterAvailable)
 	api.Get("/convert/ffmpeg", s.handleGetFFmpegInfo)
-	api.Get("/convert/formats", s.handleGetConversionFormats)
+	api.Get("/convert/formats", cacheFor(revalidate), s.handleGetConversionFormats)
 	api.Post("/convert", s.handleConvertFiles)
+	api.Get("/convert/jobs", s.handleListConversionJobs)
+	api.Post("/convert/jobs", s.handleStartConversion)
+	api.Get("/convert/jobs/:id", s.handleGetConversionJob)
+	api.Post("/convert/jobs/:id/cancel", s.handleCancelConversionJob)
 
 	// Analysis routes
 	RegisterAnalyzerRoutes(api, s)
@@ -239,8 +455,10 @@ func (s *Server) setupRoutes() {
 	api.Get("/lyrics", s.handleFetchLyrics)
 	api.Post("/lyrics/file", s.handleFetchLyricsForFile)
 	api.Post("/lyrics/embed", s.handleEmbedLyrics)
+	api.Post("/lyrics/sidecar", s.handleSaveLyricsSidecar)
 	api.Post("/lyrics/fetch-embed", s.handleFetchAndEmbedLyrics)
 	api.Post("/lyrics/fetch-embed/multiple", s.handleFetchAndEmbedMultiple)
+	api.Post("/lyrics/fetch-embed/preview", s.handlePreviewLyricsEmbed)
 
 	// Qobuz routes
 	api.Post("/qobuz/credentials", s.handleUpdateQobuzCredentials)
@@ -252,8 +470,12 @@ func (s *Server) setupRoutes() {
 
 	// System routes
 	api.Get("/version", s.handleGetVersion)
+	api.Get("/locale", s.handleGetLocaleHint)
+	api.Get("/access", s.handleGetAccess)
 	api.Get("/logs", s.handleGetLogs)
 	api.Post("/logs/clear", s.handleClearLogs)
+	api.Get("/logs/levels", s.handleGetLogLevels)
+	api.Post("/logs/levels", s.handleSetLogLevel)
 	api.Get("/connection", s.handleGetConnectionStatus)
 	api.Get("/downloader/available", s.handleIsDownloaderAvailable)
 
@@ -295,37 +517,197 @@ func (s *Server) setupRoutes() {
 		msg := fmt.Sprintf(
 			"Frontend not built. Run `cd frontend && npm install && npm run build` "+
 				"(or `make serve`), then restart the server. Looked for: %s", indexPath)
-		log.Printf("WARN: %s", msg)
+		s.component(logging.Server).Warn(msg)
 		s.app.Get("/*", func(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusServiceUnavailable).SendString(msg)
 		})
 	}
 }
 
+// pprofGuard rejects /debug/pprof requests that don't carry token as an
+// X-Pprof-Token header. It isn't taken from the query, where URLs would
+// spread it to shell history and proxy logs. An empty token leaves the
+// endpoints open, relying on the opt-in flag alone.
+func pprofGuard(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.Next()
+		}
+		if subtle.ConstantTimeCompare([]byte(c.Get("X-Pprof-Token")), []byte(token)) == 1 {
+			return c.Next()
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid pprof token"})
+	}
+}
+
+// accessLog logs each request under the "http" component. It replaces
+// fiber's logger middleware so access logs follow the runtime log levels.
+// Only the path is logged, never the query string or headers, which carry
+// the API token (authGuard) and pprof secret (pprofGuard).
+func accessLog(log *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			// Let the error handler set the status before it is logged, as
+			// fiber's own logger does.
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelWarn
+		}
+		log.Log(c.UserContext(), level, "request",
+			"method", c.Method(), "path", c.Path(), "status", status, "latency", time.Since(start))
+		return nil
+	}
+}
+
+// component returns the server's logger for one component.
+func (s *Server) component(name string) *slog.Logger {
+	return logging.Component(s.log, name)
+}
+
 // fileExists reports whether path exists and is a regular file.
 func fileExists(path string) bool {
 	info, err := os.Stat(path)
 	return err == nil && !info.IsDir()
 }
 
+// fileError responds with err and status. Permission, read-only and disk
+// space errors (see internal/fileerr) get 403 or 507 instead, along with
+// their code, the resolved path and its mount point.
+func fileError(c *fiber.Ctx, status int, err error) error {
+	e := fileerr.As(err)
+	if e == nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	status = fiber.StatusForbidden
+	if e.Code == fileerr.QuotaExceeded || e.Code == fileerr.DiskFull {
+		status = fiber.StatusInsufficientStorage
+	}
+	return c.Status(status).JSON(fiber.Map{"error": e.Message, "code": e.Code, "path": e.Path, "mount": e.Mount})
+}
+
 // Listen starts the HTTP server
 func (s *Server) Listen(addr string) error {
 	return s.app.Listen(addr)
 }
 
+// ReleaseDownloads lets the download manager's workers waiting at the
+// download gate go, and turns away later ones. Call it before stopping the
+// download manager, which would otherwise wait on them.
+func (s *Server) ReleaseDownloads() {
+	s.downloadGate.Close()
+}
+
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown() error {
+	if s.stopWatchFolder != nil {
+		s.stopWatchFolder()
+	}
+	if s.stopCleanup != nil {
+		s.stopCleanup()
+	}
+	if s.stopLibraryScan != nil {
+		s.stopLibraryScan()
+	}
+	s.ReleaseDownloads()
+	s.downloadEvents.Close()
+	s.fileBatches.Close()
+	s.fileBatches.Events.Close()
+	s.analysisJobs.Close()
+	s.analysisJobs.Events.Close()
+	s.conversionJobs.Close()
+	s.conversionJobs.Events.Close()
 	s.wsHub.Close()
 	return s.app.Shutdown()
 }
 
-// BroadcastDownloadEvent sends a download event to all connected WebSocket clients
+// FinishDownload runs the post-download steps (tags, renames, disc subfolders) for a
+// progress event. The progress callback NewServer installs calls it before
+// broadcasting, so clients see the file's final path.
+func (s *Server) FinishDownload(trackID int, status string, result *core.DownloadResult) error {
+	return app.FinishDownload(&s.postTracks, s.postOptions(), trackID, status, result)
+}
+
+// postOptions returns the options FinishDownload applies to new downloads.
+func (s *Server) postOptions() postprocess.Options {
+	opts := postprocess.Options{Settings: s.currentSettings()}
+	if s.config != nil {
+		opts.FileNameFormat = s.config.FileNameFormat
+		opts.OrganizeFolders = s.config.OrganizeFolders
+		opts.FolderTemplate = s.config.FolderTemplate
+		opts.Credits = app.CreditFetchers(s.config)["tidal"]
+	}
+	return opts
+}
+
+// currentSettings returns the app-local settings, or every option off when
+// the server runs without a settings store.
+func (s *Server) currentSettings() settings.Settings {
+	if s.settings == nil {
+		return settings.Settings{}
+	}
+	return s.settings.Get()
+}
+
+// fitTidalTracks is app.FitTidalTracks with the current config and
+// settings.
+func (s *Server) fitTidalTracks(tracks []core.TidalTrack, outputDir string) []core.TidalTrack {
+	return app.FitTidalTracks(&s.postTracks, tracks, outputDir, s.config, s.currentSettings().MaxPathLength)
+}
+
+// folderName is app.SafeFolderName with the current settings.
+func (s *Server) folderName(name string) string {
+	return app.SafeFolderName(name, s.currentSettings().FilenameUnicode)
+}
+
+// DownloadEvents returns the bus download progress is published on. Each
+// subscriber gets every event in order unless its buffer fills up, in which
+// case it misses events rather than slowing the downloads.
+func (s *Server) DownloadEvents() *events.Bus[core.DownloadEvent] {
+	return &s.downloadEvents
+}
+
+// BroadcastDownloadEvent records the job's state transition and speed and
+// publishes the event to every DownloadEvents subscriber, the WebSocket
+// clients included
 func (s *Server) BroadcastDownloadEvent(event core.DownloadEvent) {
+	s.recordDownloadEvent(event)
+	s.downloadEvents.Publish(event)
+}
+
+// recordDownloadEvent records the job's state transition and speed.
+func (s *Server) recordDownloadEvent(event core.DownloadEvent) {
+	job, err := s.jobs.Record(event.TrackID, event.Status)
+	if err != nil {
+		s.component(logging.Downloads).Warn("download state", "err", err)
+	}
+	app.RecordThroughput(&s.throughput, job, event.Status, event.Result)
+	if err := s.timings.Record(job); err != nil {
+		s.component(logging.Downloads).Warn("saving download timings", "err", err)
+	}
+}
+
+// sendDownloadEvent sends a download event, with the job's timings and the
+// queue's throughput, to all connected WebSocket clients.
+func (s *Server) sendDownloadEvent(event core.DownloadEvent) {
+	var job *downloads.Job
+	if j, ok := s.jobs.Job(event.TrackID); ok {
+		job = &j
+	}
+	bytesPerSec, _ := s.throughput.Track(event.TrackID)
 	s.wsHub.Broadcast(map[string]interface{}{
-		"type":    "download-progress",
-		"trackId": event.TrackID,
-		"status":  event.Status,
-		"result":  event.Result,
+		"type":        "download-progress",
+		"trackId":     event.TrackID,
+		"status":      event.Status,
+		"result":      event.Result,
+		"job":         job,
+		"bytesPerSec": bytesPerSec,
+		"throughput":  app.QueueRate(&s.throughput, s.downloadManager),
 	})
 }
 
@@ -337,16 +719,18 @@ type WebSocketHub struct {
 	unregister chan *websocket.Conn
 	mu         sync.RWMutex
 	done       chan struct{}
+	log        *slog.Logger
 }
 
-// NewWebSocketHub creates a new WebSocket hub
-func NewWebSocketHub() *WebSocketHub {
+// NewWebSocketHub creates a new WebSocket hub logging to log
+func NewWebSocketHub(log *slog.Logger) *WebSocketHub {
 	return &WebSocketHub{
 		clients:    make(map[*websocket.Conn]bool),
 		broadcast:  make(chan interface{}, 256),
 		register:   make(chan *websocket.Conn),
 		unregister: make(chan *websocket.Conn),
 		done:       make(chan struct{}),
+		log:        log,
 	}
 }
 
@@ -360,7 +744,7 @@ func (h *WebSocketHub) Run() {
 			h.mu.Lock()
 			h.clients[conn] = true
 			h.mu.Unlock()
-			log.Printf("WebSocket client connected (total: %d)", len(h.clients))
+			h.log.Info("client connected", "total", len(h.clients))
 		case conn := <-h.unregister:
 			h.mu.Lock()
 			if _, ok := h.clients[conn]; ok {
@@ -368,12 +752,12 @@ func (h *WebSocketHub) Run() {
 				conn.Close()
 			}
 			h.mu.Unlock()
-			log.Printf("WebSocket client disconnected (total: %d)", len(h.clients))
+			h.log.Info("client disconnected", "total", len(h.clients))
 		case message := <-h.broadcast:
 			h.mu.RLock()
 			for conn := range h.clients {
 				if err := conn.WriteJSON(message); err != nil {
-					log.Printf("WebSocket write error: %v", err)
+					h.log.Warn("write error", "err", err)
 					h.mu.RUnlock()
 					h.unregister <- conn
 					h.mu.RLock()
@@ -389,7 +773,7 @@ func (h *WebSocketHub) Broadcast(message interface{}) {
 	select {
 	case h.broadcast <- message:
 	default:
-		log.Println("WebSocket broadcast channel full, dropping message")
+		h.log.Warn("broadcast channel full, dropping message")
 	}
 }
 
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"bytes"
+	"log/slog"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
@@ -68,3 +70,95 @@ func TestServer_DefaultFrontendDir(t *testing.T) {
 		t.Errorf("frontendDir = %q, want default %q", s.frontendDir, "frontend/dist")
 	}
 }
+
+// Tests for the opt-in /debug/pprof endpoints.
+
+func TestServer_Pprof_DisabledByDefault(t *testing.T) {
+	s := NewServer(ServerConfig{Config: &core.Config{}, FrontendDir: t.TempDir()})
+
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/debug/pprof/", nil), -1)
+	if err != nil {
+		t.Fatalf("GET /debug/pprof/: %v", err)
+	}
+	if resp.StatusCode == fiber.StatusOK {
+		t.Fatalf("status = %d, want pprof not served when disabled", resp.StatusCode)
+	}
+}
+
+func TestServer_Pprof_Enabled(t *testing.T) {
+	s := NewServer(ServerConfig{Config: &core.Config{}, Pprof: true})
+
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/debug/pprof/", nil), -1)
+	if err != nil {
+		t.Fatalf("GET /debug/pprof/: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+}
+
+func TestServer_Pprof_Token(t *testing.T) {
+	s := NewServer(ServerConfig{Config: &core.Config{}, Pprof: true, PprofToken: "s3cret"})
+
+	tests := []struct {
+		name string
+		path string
+		hdr  string
+		want int
+	}{
+		{name: "missing token", path: "/debug/pprof/", want: fiber.StatusUnauthorized},
+		{name: "wrong token", path: "/debug/pprof/?token=nope", want: fiber.StatusUnauthorized},
+		{name: "query token", path: "/debug/pprof/?token=s3cret", want: fiber.StatusUnauthorized},
+		{name: "header token", path: "/debug/pprof/", hdr: "s3cret", want: fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.hdr != "" {
+				req.Header.Set("X-Pprof-Token", tt.hdr)
+			}
+			resp, err := s.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("GET %s: %v", tt.path, err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_Pprof_APIToken(t *testing.T) {
+	s := NewServer(ServerConfig{Config: &core.Config{}, Pprof: true, APIToken: "admin", GuestToken: "guest"})
+
+	for token, want := range map[string]int{"": fiber.StatusUnauthorized, "guest": fiber.StatusUnauthorized, "admin": fiber.StatusOK} {
+		req := httptest.NewRequest("GET", "/debug/pprof/", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := s.app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("GET /debug/pprof/: %v", err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("token %q: status = %d, want %d", token, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestAccessLog_LeavesOutSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	app := fiber.New()
+	app.Use(accessLog(slog.New(slog.NewTextHandler(&buf, nil))))
+	app.Get("/api/queue", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	req := httptest.NewRequest("GET", "/api/queue?token=apisecret", nil)
+	req.Header.Set("X-Pprof-Token", "pprofsecret")
+	req.Header.Set("Authorization", "Bearer apisecret")
+	if _, err := app.Test(req, -1); err != nil {
+		t.Fatal(err)
+	}
+	if log := buf.String(); !strings.Contains(log, "/api/queue") || strings.Contains(log, "secret") {
+		t.Errorf("access log = %q", log)
+	}
+}
@@ -7,12 +7,29 @@ import (
 	"os/exec"
 	"path/filepath"
 	goruntime "runtime"
-	"sync"
 	"time"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/analysisstore"
+	"flacidal/internal/coverstore"
+	"flacidal/internal/credits"
+	"flacidal/internal/downloads"
+	"flacidal/internal/events"
+	"flacidal/internal/history"
+	"flacidal/internal/incomplete"
+	"flacidal/internal/jobs"
+	"flacidal/internal/library"
+	"flacidal/internal/logging"
+	"flacidal/internal/lyricscache"
+	"flacidal/internal/metacache"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/quality"
+	"flacidal/internal/settings"
+	"flacidal/internal/timestamp"
+	"flacidal/internal/watchfolder"
 )
 
 // App struct - main Wails application
@@ -24,19 +41,39 @@ type App struct {
 	tidalClient     *core.TidalClient
 	spotifySearch   *core.SpotifyClient // For search/matching (Client Credentials, no login)
 	matcher         *core.Matcher
-	downloader      *core.TidalHifiService     // FLAC downloader
-	downloadManager *core.DownloadManager      // Concurrent download manager
-	logBuffer       *core.LogBuffer            // Log buffer for Terminal page
-	sourceManager   *core.SourceManager        // Multi-source manager
-	tidalSource     *core.TidalSource          // Tidal source
-	qobuzSource     *core.QobuzSource          // Qobuz source
-	amazonSource    *core.AmazonSource         // Amazon Music fallback source
-	soulseekSource  *core.SoulseekSource       // Soulseek last-resort source
-	deezerSource    *core.DeezerSource         // Deezer metadata-only source
-	spotifySource   *core.SpotifySource        // Spotify metadata-only source
-	bandcampSource  *core.BandcampSource       // Bandcamp name-your-price source
-	orchestrator    *core.DownloadOrchestrator // Download orchestrator for live priority updates
-	trackContentMap sync.Map                   // maps trackID (int) → contentID (string) for history tracking
+	downloader      *core.TidalHifiService         // FLAC downloader
+	downloadManager *core.DownloadManager          // Concurrent download manager
+	downloadGate    downloads.Gate                 // Holds downloadManager to ConcurrentDownloads
+	logBuffer       *core.LogBuffer                // Log buffer for Terminal page
+	sourceManager   *core.SourceManager            // Multi-source manager
+	tidalSource     *core.TidalSource              // Tidal source
+	qobuzSource     *core.QobuzSource              // Qobuz source
+	amazonSource    *core.AmazonSource             // Amazon Music fallback source
+	soulseekSource  *core.SoulseekSource           // Soulseek last-resort source
+	deezerSource    *core.DeezerSource             // Deezer metadata-only source
+	spotifySource   *core.SpotifySource            // Spotify metadata-only source
+	bandcampSource  *core.BandcampSource           // Bandcamp name-your-price source
+	orchestrator    *core.DownloadOrchestrator     // Download orchestrator for live priority updates
+	batches         ContentBatches                 // Queued track → history record, for download counts
+	origins         *history.Origins               // Source and URL of each history record, for refetch
+	timings         *history.Timings               // State transitions and timings of finished downloads
+	settings        *settings.Store                // App-local settings (settings.json)
+	postTracks      postprocess.Registry           // Queue-time metadata for post-download steps
+	jobs            downloads.Tracker              // Per-job state machine and timings
+	throughput      downloads.Throughput           // Finished-download speeds, for queue ETA
+	finisher        downloads.Finisher             // Post-download steps, off the download workers
+	logLevels       logging.Levels                 // Runtime per-component log levels
+	downloadEvents  events.Bus[core.DownloadEvent] // Download progress, fanned out to listeners
+	fileBatches     jobs.Manager                   // Batch file operations, for progress and undo
+	analysisJobs    jobs.Manager                   // Background analyses, for progress and cancellation
+	conversionJobs  jobs.Manager                   // Background conversions, for progress and cancellation
+	covers          *coverstore.Store              // Content-addressed cover cache and thumbnails
+	fileMeta        metacache.Cache                // Audio formats of listed files
+	lyrics          *lyricscache.Cache             // LRCLIB lookups, shared by fetches and tag imports
+	library         *library.Index                 // Indexed tags of the library's FLACs
+	credits         *credits.Store                 // Fetched album credits
+	analyses        *analysisstore.Store           // Latest analysis of each file, for listings and the quality report
+	stopWatchers    context.CancelFunc             // Stops the clipboard and folder watchers and the cleanup
 }
 
 // NewApp creates a new App application struct
@@ -96,6 +133,56 @@ func (a *App) Startup(ctx context.Context) {
 	a.config = config
 	a.logBuffer.Success("Configuration loaded")
 
+	// Load app-local settings (options flacidal-core's Config doesn't cover)
+	a.settings, err = settings.Open(core.GetDataDir())
+	if err != nil {
+		a.logBuffer.Warn("Could not load settings: " + err.Error())
+	}
+	// Re-register start on login so it follows the executable if the app
+	// was moved or updated
+	if a.currentSettings().StartOnLogin {
+		if err := applyStartOnLogin(true); err != nil {
+			a.logBuffer.Warn("Could not register start on login: " + err.Error())
+		}
+	}
+	a.origins, err = history.Open(core.GetDataDir())
+	if err != nil {
+		a.logBuffer.Warn("Could not load history origins: " + err.Error())
+	}
+	a.origins.FetchCover = HistoryCoverFetcher(a.currentSettings)
+	a.timings, err = history.OpenTimings(core.GetDataDir())
+	if err != nil {
+		a.logBuffer.Warn("Could not load download timings: " + err.Error())
+	}
+	a.covers, err = coverstore.Open(core.GetDataDir())
+	if err != nil {
+		a.logBuffer.Warn("Could not open cover store: " + err.Error())
+	}
+	a.lyrics, err = lyricscache.Open(core.GetDataDir())
+	if err != nil {
+		a.logBuffer.Warn("Could not load lyrics cache: " + err.Error())
+	}
+	a.library, err = library.Open(core.GetDataDir())
+	if err != nil {
+		a.logBuffer.Warn("Could not open library index: " + err.Error())
+	}
+	a.credits, err = credits.Open(core.GetDataDir())
+	if err != nil {
+		a.logBuffer.Warn("Could not open credits store: " + err.Error())
+	}
+	a.analyses, err = analysisstore.Open(core.GetDataDir())
+	if err != nil {
+		a.logBuffer.Warn("Could not open analysis store: " + err.Error())
+	}
+
+	// Stored times predating UTC timestamps are rewritten before the
+	// database is opened
+	if n, err := MigrateTimestamps(a.ctx, core.GetDataDir()); err != nil {
+		a.logBuffer.Warn("Could not migrate stored timestamps: " + err.Error())
+	} else if n > 0 {
+		a.logBuffer.Info(fmt.Sprintf("Migrated %d stored timestamps to UTC", n))
+	}
+
 	// Initialize database
 	db, err := core.NewDatabase()
 	if err != nil {
@@ -149,19 +236,21 @@ func (a *App) Startup(ctx context.Context) {
 		}
 	}
 	// Set download options from config
-	quality := config.DownloadQuality
-	if quality == "" {
-		quality = "LOSSLESS"
+	if err := NormalizeQualityConfig(config); err != nil {
+		a.logBuffer.Warn(fmt.Sprintf("Invalid quality setting, using %s: %v", quality.Default, err))
+		config.DownloadQuality = ""
+		config.QualityOrder = nil
 	}
+	dlQuality, _ := quality.ParseOrDefault(config.DownloadQuality)
 	fileNameFormat := config.FileNameFormat
 	if fileNameFormat == "" {
 		fileNameFormat = "{artist} - {title}"
 	}
 	a.downloader.SetOptions(core.DownloadOptions{
-		Quality:              quality,
-		FileNameFormat:       fileNameFormat,
+		Quality:              dlQuality.String(),
+		FileNameFormat:       coreFileNameFormat(fileNameFormat),
 		OrganizeFolders:      config.OrganizeFolders,
-		FolderTemplate:       config.FolderTemplate,
+		FolderTemplate:       coreTemplate(config.FolderTemplate),
 		EmbedCover:           config.EmbedCover,
 		SaveCoverFile:        config.SaveCoverFile,
 		AutoAnalyze:          config.AutoAnalyze,
@@ -177,109 +266,44 @@ func (a *App) Startup(ctx context.Context) {
 	})
 	a.logBuffer.Info("FLAC downloader service ready")
 
-	// Initialize download manager with 4 concurrent workers
-	a.downloadManager = core.NewDownloadManager(a.downloader, 4)
+	// Initialize download manager with the largest pool; the gate holds it
+	// to the configured number of downloads
+	a.downloadGate.SetLimit(DownloadWorkers(config.ConcurrentDownloads))
+	a.downloadManager = core.NewDownloadManager(a.downloader, MaxDownloadWorkers)
 	a.downloadManager.SetJellyfin(config.JellyfinEnabled, config.JellyfinURL, config.JellyfinAPIKey)
 
-	// Serialized event channel to avoid concurrent ExecuteJS calls that crash WebKit on Linux.
-	// Events are queued and emitted one at a time from a dedicated goroutine.
-	type progressEvent struct {
-		eventType string // "download-progress" if empty
-		trackID   int
-		status    string
-		result    *core.DownloadResult
-	}
-	eventCh := make(chan progressEvent, 64)
-	go func() {
-		for ev := range eventCh {
-			evType := ev.eventType
-			if evType == "" {
-				evType = "download-progress"
-			}
-			runtime.EventsEmit(ctx, evType, map[string]interface{}{
-				"trackId": ev.trackID,
-				"status":  ev.status,
-				"result":  ev.result,
-			})
-			// Small delay between events to let WebKit/GTK process JS
-			time.Sleep(50 * time.Millisecond)
-		}
-	}()
-
-	a.downloadManager.SetProgressCallback(func(trackID int, status string, result *core.DownloadResult) {
-		// Log download events
-		if a.logBuffer != nil {
-			switch status {
-			case "queued":
-				a.logBuffer.Info(fmt.Sprintf("Track %d added to queue", trackID))
-			case "downloading":
-				a.logBuffer.Info(fmt.Sprintf("Downloading track %d...", trackID))
-			case "completed":
-				if result != nil {
-					a.logBuffer.Success(fmt.Sprintf("Downloaded: %s (quality: %s)", result.FilePath, result.Quality))
-					if result.QualityMismatch {
-						a.logBuffer.Warn(fmt.Sprintf("Quality mismatch: requested %s but got %s",
-							result.RequestedQuality, result.Quality))
-					}
-					if result.Analysis != nil {
-						if result.Analysis.IsTrueLossless {
-							a.logBuffer.Info(fmt.Sprintf("Analysis: %s - True lossless", result.Analysis.VerdictLabel))
-						} else {
-							a.logBuffer.Warn(fmt.Sprintf("Analysis: %s - May be upscaled from lossy source", result.Analysis.VerdictLabel))
-						}
-					}
-				}
-			case "error":
-				if result != nil && result.Error != "" {
-					a.logBuffer.Error(fmt.Sprintf("Download failed: %s", result.Error))
-				}
-				// Auto-stop if all endpoints are in cooldown and the feature is enabled
-				if a.config.AutoStopOnCooldown && !a.downloader.HasHealthyEndpoints() {
-					if a.downloadManager.PauseQueue() {
-						a.logBuffer.Warn("All Tidal endpoints in cooldown — queue paused")
-						// Find the minimum cooldown across all dead endpoints
-						minCooldown := 0
-						for _, stat := range a.downloader.PoolSnapshot() {
-							if stat.CooldownSecs > 0 && (minCooldown == 0 || stat.CooldownSecs < minCooldown) {
-								minCooldown = stat.CooldownSecs
-							}
-						}
-						eventCh <- progressEvent{eventType: "endpoint-cooldown", trackID: -1, status: "cooldown", result: &core.DownloadResult{
-							Error: fmt.Sprintf("all endpoints in cooldown, resuming in %ds", minCooldown),
-						}}
-					}
-				}
-			case "cancelled":
-				a.logBuffer.Warn(fmt.Sprintf("Track %d cancelled", trackID))
-			}
+	// The progress callback updates FLACidal's own state, then publishes to
+	// a.downloadEvents; the Terminal log and the frontend emitter below are
+	// listeners like any other.
+	a.downloadEvents.Keep = KeepDownloadEvent
+	a.downloadManager.SetProgressCallback(a.handleDownloadProgress)
+	a.downloadManager.SetJobCompleteCallback(func(entry core.HistoryEntry) {
+		if a.db == nil {
+			return
 		}
-
-		// Update download history counts
-		if a.db != nil {
-			switch status {
-			case "completed":
-				if cid, ok := a.trackContentMap.Load(trackID); ok {
-					if err := a.db.IncrementDownloadCounts(cid.(string), true); err != nil {
-						a.logBuffer.Warn(fmt.Sprintf("Failed to update download counts for %s: %v", cid.(string), err))
-					}
-					a.trackContentMap.Delete(trackID)
-				}
-			case "error":
-				if cid, ok := a.trackContentMap.Load(trackID); ok {
-					if err := a.db.IncrementDownloadCounts(cid.(string), false); err != nil {
-						a.logBuffer.Warn(fmt.Sprintf("Failed to update download counts for %s: %v", cid.(string), err))
-					}
-					a.trackContentMap.Delete(trackID)
-				}
-			}
+		timestamp.UTCFields(&entry)
+		if err := a.db.InsertHistoryEntry(entry); err != nil {
+			a.logBuffer.Warn(fmt.Sprintf("Failed to save track history: %v", err))
 		}
-
-		// Queue event for serialized emission (blocking — workers wait
-		// briefly if buffer is full, which is negligible vs download time)
-		eventCh <- progressEvent{trackID: trackID, status: status, result: result}
 	})
+	events.Listen(&a.downloadEvents, 256, a.logDownloadEvent)
+
+	// Serialized emission to avoid concurrent ExecuteJS calls that crash
+	// WebKit on Linux: a single listener emits one batch at a time.
+	events.ListenBatched(&a.downloadEvents, 1024, downloads.BatchWindow, a.emitDownloadEvents)
 	a.downloadManager.Start()
-	a.logBuffer.Success("Download manager started (4 workers)")
+	a.logBuffer.Success(fmt.Sprintf("Download manager started (%d downloads at once)", DownloadWorkers(config.ConcurrentDownloads)))
+	events.Listen(&a.fileBatches.Events, 256, func(ev jobs.Event) {
+		runtime.EventsEmit(ctx, "batch-progress", ev)
+	})
+	events.Listen(&a.analysisJobs.Events, 256, func(ev jobs.Event) {
+		a.logAnalysisJob(ev)
+		runtime.EventsEmit(ctx, "analysis-progress", ev)
+	})
+	events.Listen(&a.conversionJobs.Events, 256, func(ev jobs.Event) {
+		a.logConversionJob(ev)
+		runtime.EventsEmit(ctx, "conversion-job-progress", ev)
+	})
 
 	// Initialize source manager
 	a.sourceManager = core.NewSourceManager()
@@ -401,15 +425,52 @@ func (a *App) Startup(ctx context.Context) {
 	a.downloadManager.SetGenerateM3U8(config.GenerateM3U8)
 	a.downloadManager.SetSkipUnavailable(config.SkipUnavailableTracks)
 
+	// Offer copied music URLs for download and import dropped URL lists
+	// (opt-in via Settings.WatchClipboard / Settings.WatchFolder)
+	var watchCtx context.Context
+	watchCtx, a.stopWatchers = context.WithCancel(ctx)
+	go a.watchClipboard(watchCtx)
+	go watchfolder.Run(watchCtx, watchfolder.Interval, func() string {
+		return a.currentSettings().WatchFolder
+	}, a.importWatchedFile, func(err error) {
+		a.logBuffer.Warn(err.Error())
+	})
+
+	// Delete what interrupted downloads left behind, once it is old enough
+	// (opt-in via Settings.IncompleteCleanupDays)
+	go incomplete.Run(watchCtx, incompleteCleanupInterval, a.libraryRoots, func() time.Duration {
+		return CleanupAge(a.currentSettings())
+	}, a.reportIncompleteCleanup)
+
+	// Pick up what changed in the library while FLACidal wasn't running,
+	// then what other programs put there (opt-in via Settings.WatchLibrary)
+	if a.library != nil {
+		go a.scanLibrary(watchCtx) //nolint:errcheck // logged
+		go a.watchLibrary(watchCtx)
+	}
+
 	a.logBuffer.Success("FLACidal ready!")
 }
 
 // Shutdown is called when the app is closing
 func (a *App) Shutdown(ctx context.Context) {
-	// Stop download manager
+	if a.stopWatchers != nil {
+		a.stopWatchers()
+	}
+
+	// Stop download manager, then its event listeners. Workers waiting for
+	// their turn at the gate are let go first, or Stop would wait on them.
+	a.downloadGate.Close()
 	if a.downloadManager != nil {
 		a.downloadManager.Stop()
 	}
+	a.downloadEvents.Close()
+	a.fileBatches.Close()
+	a.fileBatches.Events.Close()
+	a.analysisJobs.Close()
+	a.analysisJobs.Events.Close()
+	a.conversionJobs.Close()
+	a.conversionJobs.Events.Close()
 
 	// Save config
 	if a.config != nil {
@@ -420,4 +481,9 @@ func (a *App) Shutdown(ctx context.Context) {
 	if a.db != nil {
 		a.db.Close()
 	}
+	if a.library != nil {
+		a.library.Close()
+	}
+	a.credits.Close()
+	a.analyses.Close()
 }
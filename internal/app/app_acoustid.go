@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"flacidal/internal/acoustid"
+	"flacidal/internal/settings"
+	"flacidal/internal/tagedit"
+)
+
+// =============================================================================
+// AcoustID Fingerprinting (exposed to frontend)
+// =============================================================================
+
+// GetAcoustIDInfo reports whether fpcalc is installed and an AcoustID key
+// is set, so the file browser can explain why identification is off.
+func (a *App) GetAcoustIDInfo() acoustid.Info {
+	return AcoustIDInfo(a.ctx, a.currentSettings())
+}
+
+// PreviewAcoustIDTags fingerprints files and lists, per file, the tags
+// AcoustIDTags would write.
+func (a *App) PreviewAcoustIDTags(files []string) []tagedit.Result {
+	return AcoustIDTags(a.ctx, a.currentSettings(), files, true)
+}
+
+// AcoustIDTags identifies files by their audio fingerprint and writes their
+// AcoustID and MusicBrainz IDs, replacing missing or garbage titles,
+// artists and albums.
+func (a *App) AcoustIDTags(files []string) []tagedit.Result {
+	results := AcoustIDTags(a.ctx, a.currentSettings(), files, false)
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Tagged %d/%d files from AcoustID fingerprints", TagsWritten(results), len(files)))
+	}
+	return results
+}
+
+// AcoustIDInfo reports fpcalc's availability and whether s has an AcoustID
+// key. Shared by the desktop (Wails) and HTTP server APIs.
+func AcoustIDInfo(ctx context.Context, s settings.Settings) acoustid.Info {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	info := acoustid.Default.Info(ctx)
+	info.KeySet = s.AcoustIDKey != ""
+	return info
+}
+
+// AcoustIDTags tags files from their AcoustID fingerprints (see
+// acoustid.Client.Identify), refusing broken files in strict mode. Without
+// fpcalc or an API key every file fails with the reason. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func AcoustIDTags(ctx context.Context, s settings.Settings, files []string, dryRun bool) []tagedit.Result {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	failed := func(path, reason string) tagedit.Result {
+		return tagedit.Result{Path: path, Changes: []tagedit.Change{}, Error: reason}
+	}
+	if err := acoustid.Default.Ready(s.AcoustIDKey); err != nil {
+		results := make([]tagedit.Result, len(files))
+		for i, f := range files {
+			results[i] = failed(f, err.Error())
+		}
+		return results
+	}
+	return StrictResults(s, files, func(files []string) []tagedit.Result {
+		results := make([]tagedit.Result, len(files))
+		for i, f := range files {
+			results[i], _ = acoustid.Default.Identify(ctx, s.AcoustIDKey, f, dryRun)
+		}
+		return results
+	}, failed)
+}
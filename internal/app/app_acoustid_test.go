@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"flacidal/internal/acoustid"
+	"flacidal/internal/settings"
+)
+
+func TestAcoustIDTags(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "ok", "results": [{"id": "aid-1", "score": 0.97, "recordings": [{"id": "rec-1", "title": "Song", "artists": [{"id": "art-a", "name": "A"}]}]}]}`))
+	}))
+	defer srv.Close()
+	saved := acoustid.Default
+	acoustid.Default = &acoustid.Client{BaseURL: srv.URL, Interval: time.Millisecond, Fpcalc: "fpcalc", Run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return []byte(`{"duration": 64, "fingerprint": "FP"}`), nil
+	}}
+	t.Cleanup(func() { acoustid.Default = saved })
+
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path, map[string]string{"TITLE": "Track 01"}, nil, 64)
+
+	if results := AcoustIDTags(context.Background(), settings.Settings{}, []string{path}, true); results[0].Error == "" {
+		t.Errorf("no key: %+v, want an error", results[0])
+	}
+	if info := AcoustIDInfo(context.Background(), settings.Settings{AcoustIDKey: "key"}); !info.Available || !info.KeySet {
+		t.Errorf("AcoustIDInfo = %+v", info)
+	}
+
+	s := settings.Settings{AcoustIDKey: "key"}
+	results := AcoustIDTags(context.Background(), settings.Settings{AcoustIDKey: "key", StrictValidation: true}, []string{path}, false)
+	if results[0].Error == "" || results[0].Written {
+		t.Errorf("strict mode: %+v, want a refusal", results[0])
+	}
+	results = AcoustIDTags(context.Background(), s, []string{path}, true)
+	if results[0].Error != "" || results[0].Written || len(results[0].Changes) != 5 {
+		t.Errorf("preview: %+v", results[0])
+	}
+	results = AcoustIDTags(context.Background(), s, []string{path}, false)
+	if !results[0].Written || TagsWritten(results) != 1 {
+		t.Errorf("tagging: %+v", results[0])
+	}
+
+	if _, err := BatchWork(settings.Settings{}, BatchRequest{Op: BatchAcoustID, Files: []string{path}}); err == nil {
+		t.Error("acoustid batch without a key: want an error")
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goruntime "runtime"
+	"slices"
+	"strings"
+
+	"flacidal/internal/analysis"
+	"flacidal/internal/analysisstore"
+	"flacidal/internal/jobs"
+	"flacidal/internal/settings"
+)
+
+// AnalysisJob is the kind of the jobs StartAnalysis runs.
+const AnalysisJob = "analysis"
+
+// AnalysisWorkers is how many files analysis jobs and AnalyzeMultiple
+// analyze at once: one per CPU core (GOMAXPROCS), capped by
+// s.AnalysisWorkers when set. The spectral analysis is CPU bound.
+func AnalysisWorkers(s settings.Settings) int {
+	n := goruntime.GOMAXPROCS(0)
+	if s.AnalysisWorkers > 0 {
+		n = min(n, s.AnalysisWorkers)
+	}
+	return n
+}
+
+// =============================================================================
+// Analysis Jobs (exposed to frontend)
+// =============================================================================
+
+// StartAnalysis queues an analysis of paths in the background and returns
+// the new job; "analysis-progress" events follow it, each carrying the
+// AnalysisResult of the file just analyzed. Unchanged files reuse their
+// saved analysis unless force is set.
+func (a *App) StartAnalysis(paths []string, force bool) (jobs.Job, error) {
+	j, err := StartAnalysis(&a.analysisJobs, paths, force, a.currentSettings(), a.analyses)
+	if err == nil && a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Queued analysis job %s (%d files)", j.ID, j.Total))
+	}
+	return j, err
+}
+
+// AnalyzeFolder queues an analysis of the FLAC files in folder and, if
+// recursive, its subfolders (see StartAnalysis). The finished job's
+// summary counts the files by verdict.
+func (a *App) AnalyzeFolder(folder string, recursive, force bool) (jobs.Job, error) {
+	j, err := AnalyzeFolder(context.Background(), &a.analysisJobs, folder, recursive, force, a.currentSettings(), a.analyses)
+	if err == nil && a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Queued analysis job %s (%d files in %s)", j.ID, j.Total, folder))
+	}
+	return j, err
+}
+
+// GetAnalysisJob returns an analysis job with its per-file results.
+func (a *App) GetAnalysisJob(id string) (jobs.Job, error) {
+	j, ok := a.analysisJobs.Get(id)
+	if !ok {
+		return jobs.Job{}, jobs.ErrNotFound
+	}
+	return j, nil
+}
+
+// ListAnalysisJobs lists the recent analysis jobs, newest first.
+func (a *App) ListAnalysisJobs() []jobs.Job {
+	return a.analysisJobs.List()
+}
+
+// CancelAnalysisJob stops an analysis job, keeping the results it has.
+func (a *App) CancelAnalysisJob(id string) (jobs.Job, error) {
+	j, err := a.analysisJobs.Cancel(id)
+	if err == nil && a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Cancelled analysis job %s", id))
+	}
+	return j, err
+}
+
+// logAnalysisJob logs the summary of a finished analysis job.
+func (a *App) logAnalysisJob(ev jobs.Event) {
+	if a.logBuffer == nil || !ev.State.Finished() {
+		return
+	}
+	msg := fmt.Sprintf("Analysis job %s %s: %d of %d files analyzed", ev.ID, ev.State, ev.Processed, ev.Total)
+	if len(ev.Summary) > 0 {
+		msg += " (" + AnalysisSummary(ev.Summary) + ")"
+	}
+	a.logBuffer.Info(msg)
+}
+
+// StartAnalysis queues a job on m running Analyze over paths with s,
+// AnalysisWorkers(s) files at a time, saving the results in store. Files
+// unchanged since their analysis in store reuse it unless force is set
+// (see CachedAnalysis). Shared by the desktop (Wails) and HTTP server
+// APIs.
+func StartAnalysis(m *jobs.Manager, paths []string, force bool, s settings.Settings, store *analysisstore.Store) (jobs.Job, error) {
+	if len(paths) == 0 {
+		return jobs.Job{}, errors.New("paths are required")
+	}
+	m.SetWorkers(AnalysisWorkers(s))
+	return m.Start(AnalysisJob, paths, AnalysisWork(force, s, store), jobs.Options{Tally: AnalysisTally}), nil
+}
+
+// AnalyzeFolder is StartAnalysis for the FLAC files in folder and, if
+// recursive, its subfolders. Shared by the desktop (Wails) and HTTP server
+// APIs.
+func AnalyzeFolder(ctx context.Context, m *jobs.Manager, folder string, recursive, force bool, s settings.Settings, store *analysisstore.Store) (jobs.Job, error) {
+	if folder == "" {
+		return jobs.Job{}, errors.New("folder is required")
+	}
+	paths, err := analysis.FLACFiles(ctx, folder, recursive)
+	if err != nil {
+		return jobs.Job{}, err
+	}
+	if len(paths) == 0 {
+		return jobs.Job{}, fmt.Errorf("no FLAC files in %s", folder)
+	}
+	return StartAnalysis(m, paths, force, s, store)
+}
+
+// AnalysisTally counts an analyzed file in its job's summary by verdict
+// ("lossless", "upsampled", "likely_upscaled", "upscaled"), and a file that couldn't
+// be analyzed as "error".
+func AnalysisTally(result any, err error) string {
+	r, ok := result.(*AnalysisResult)
+	if err != nil || !ok {
+		return "error"
+	}
+	return r.Verdict
+}
+
+// AnalysisSummary describes an analysis job's summary in one line for the
+// logs, e.g. "12 lossless, 1 upscaled, 2 error".
+func AnalysisSummary(summary map[string]int) string {
+	keys := make([]string, 0, len(summary))
+	for k := range summary {
+		keys = append(keys, k)
+	}
+	// Verdicts from best to worst, errors last
+	order := []string{"lossless", "upsampled", "likely_upscaled", "upscaled"}
+	slices.SortFunc(keys, func(a, b string) int {
+		ia, ib := slices.Index(order, a), slices.Index(order, b)
+		if ia < 0 {
+			ia = len(order)
+		}
+		if ib < 0 {
+			ib = len(order)
+		}
+		if ia != ib {
+			return ia - ib
+		}
+		return strings.Compare(a, b)
+	})
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%d %s", summary[k], strings.ReplaceAll(k, "_", " "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// AnalysisWork is Analyze as job work, reusing saved analyses unless
+// force is set (see AnalyzeCached): each item's result is an
+// *AnalysisResult. A cancelled job leaves the file it was measuring
+// unprocessed rather than recording a partial analysis.
+func AnalysisWork(force bool, s settings.Settings, store *analysisstore.Store) jobs.Work {
+	return func(ctx context.Context, path string) (any, error) {
+		r, err := AnalyzeCached(ctx, path, force, s, store)
+		if err == nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+}
@@ -1,18 +1,53 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/analysis"
+	"flacidal/internal/analysisstore"
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/settings"
+	"flacidal/internal/tagedit"
+	"flacidal/internal/timestamp"
 )
 
+// AnalysisResult is flacidal-core's spectral analysis of a file with
+// FLACidal's measurements of its decoded samples (see internal/analysis)
+// alongside. The measurements are zero when FFmpeg isn't available.
+type AnalysisResult struct {
+	core.AnalysisResult
+	analysis.Result
+
+	// Thresholds are the ones the verdict was judged by (see the
+	// AnalyzerThresholds setting), defaults applied, so it can be
+	// reproduced.
+	Thresholds analysis.Thresholds `json:"thresholds"`
+
+	// LoudnessTagged is set when the measured loudness was written to the
+	// file as ReplayGain tags (see the LoudnessTags setting).
+	LoudnessTagged bool `json:"loudnessTagged,omitempty"`
+
+	// Cached is set on an analysis reused from the analysis store, the
+	// file being unchanged since (see CachedAnalysis). It is never saved.
+	Cached bool `json:"cached,omitempty"`
+}
+
 // =============================================================================
 // Analyzer Methods (exposed to frontend)
 // =============================================================================
 
 // AnalyzeFile analyzes a single FLAC file for quality/authenticity
-func (a *App) AnalyzeFile(filePath string) (*core.AnalysisResult, error) {
-	result, err := core.AnalyzeFLAC(filePath)
+func (a *App) AnalyzeFile(filePath string) (*AnalysisResult, error) {
+	result, err := Analyze(context.Background(), filePath, a.currentSettings(), a.analyses)
 	if err != nil {
 		return nil, err
 	}
@@ -24,21 +59,65 @@ func (a *App) AnalyzeFile(filePath string) (*core.AnalysisResult, error) {
 	return result, nil
 }
 
-// AnalyzeMultiple analyzes multiple files
-func (a *App) AnalyzeMultiple(filePaths []string) []core.AnalysisResult {
-	results := core.AnalyzeMultiple(filePaths)
+// AnalyzeMultiple analyzes multiple files, reusing the saved analyses of
+// unchanged ones
+func (a *App) AnalyzeMultiple(filePaths []string) []AnalysisResult {
+	results := AnalyzeMultiple(context.Background(), filePaths, false, a.currentSettings(), a.analyses)
 
 	if a.logBuffer != nil {
 		lossless := 0
+		upsampled := 0
 		upscaled := 0
+		padded := 0
+		clipping := 0
+		mqa := 0
+		truncated := 0
+		stereo := 0
 		for _, r := range results {
-			if r.IsTrueLossless {
+			switch {
+			case r.IsTrueLossless:
 				lossless++
-			} else if r.Verdict != "error" {
+			case r.Verdict == "upsampled":
+				upsampled++
+			case r.Verdict != "error":
 				upscaled++
 			}
+			if r.PaddedBitDepth {
+				padded++
+			}
+			if r.Clipping {
+				clipping++
+			}
+			if r.MQA {
+				mqa++
+			}
+			if r.Truncated {
+				truncated++
+			}
+			if r.FakeStereo || r.OutOfPhase {
+				stereo++
+			}
 		}
-		a.logBuffer.Info(fmt.Sprintf("Analyzed %d files: %d lossless, %d upscaled", len(results), lossless, upscaled))
+		msg := fmt.Sprintf("Analyzed %d files: %d lossless, %d upscaled", len(results), lossless, upscaled)
+		if upsampled > 0 {
+			msg += fmt.Sprintf(", %d upsampled", upsampled)
+		}
+		if padded > 0 {
+			msg += fmt.Sprintf(", %d with padded bit depth", padded)
+		}
+		if clipping > 0 {
+			msg += fmt.Sprintf(", %d clipping", clipping)
+		}
+		if mqa > 0 {
+			msg += fmt.Sprintf(", %d MQA", mqa)
+		}
+		if truncated > 0 {
+			msg += fmt.Sprintf(", %d truncated", truncated)
+		}
+		if stereo > 0 {
+			msg += fmt.Sprintf(", %d with fake or out-of-phase stereo", stereo)
+		}
+		a.logBuffer.Info(msg)
 	}
 
 	return results
@@ -48,3 +127,219 @@ func (a *App) AnalyzeMultiple(filePaths []string) []core.AnalysisResult {
 func (a *App) QuickAnalyze(filePath string) (*core.AnalysisResult, error) {
 	return core.QuickAnalyze(filePath)
 }
+
+// ExportQualityReport saves the library's quality report (see
+// QualityReport), in format "csv" or "json", where the user chooses.
+// Returns the path of the saved file, or empty string if cancelled.
+func (a *App) ExportQualityReport(format string) (string, error) {
+	if err := analysisstore.ValidateFormat(format); err != nil {
+		return "", err
+	}
+	r, err := QualityReport(a.analyses)
+	if err != nil {
+		return "", err
+	}
+	data, ext, err := r.Encode(format)
+	if err != nil {
+		return "", err
+	}
+
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultFilename: QualityReportFileName + ext,
+	})
+	if err != nil || savePath == "" {
+		return "", err
+	}
+	if err := os.WriteFile(savePath, data, 0644); err != nil {
+		return "", err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Exported quality report: %d of %d tracks analyzed, %d flagged", r.Analyzed, r.Tracks, r.Flagged))
+	}
+	return savePath, nil
+}
+
+// Analyze runs flacidal-core's spectral analysis of the FLAC at path,
+// judges its cutoff by s.AnalyzerThresholds and, when FFmpeg is available, measures its decoded samples, writing the
+// loudness to the file when s.LoudnessTags is on and noting the lossy
+// codec an upscaled file likely came from in its details. The result is saved in
+// store (nil saves nothing). A failed measurement, tag write or save is
+// noted in Details rather than failing the analysis. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func Analyze(ctx context.Context, path string, s settings.Settings, store *analysisstore.Store) (*AnalysisResult, error) {
+	r, err := core.AnalyzeFLAC(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &AnalysisResult{AnalysisResult: *r}
+	result.judge(s.AnalyzerThresholds.WithDefaults())
+	measure(ctx, result, path, s)
+	result.lossySource()
+	mqaTags(result, path)
+	if ctx.Err() == nil {
+		if err := store.Put(path, result.storedVerdict(), result); err != nil {
+			result.Details = joinDetails(result.Details, "not saved: "+err.Error())
+		}
+	}
+	return result, nil
+}
+
+// AnalyzeCached is Analyze, unless force is off and store has a current
+// analysis of the file at path (see CachedAnalysis), which it returns
+// instead. Shared by the desktop (Wails) and HTTP server APIs.
+func AnalyzeCached(ctx context.Context, path string, force bool, s settings.Settings, store *analysisstore.Store) (*AnalysisResult, error) {
+	if !force {
+		if r := CachedAnalysis(path, s, store); r != nil {
+			return r, nil
+		}
+	}
+	return Analyze(ctx, path, s, store)
+}
+
+// CachedAnalysis is the analysis of the file at path saved in store, or
+// nil when there is none, the file has changed size or modification time
+// since, or it was judged by other thresholds than s sets. Re-analyzing
+// an unchanged library would otherwise take hours.
+func CachedAnalysis(path string, s settings.Settings, store *analysisstore.Store) *AnalysisResult {
+	var r AnalysisResult
+	v, err := store.Get(path, &r)
+	if err != nil || v == nil || r.Thresholds != s.AnalyzerThresholds.WithDefaults() {
+		return nil
+	}
+	r.Cached = true
+	return &r
+}
+
+// judge replaces core's verdict on r with the one t gives its cutoff and
+// sample rate. A file core couldn't measure a cutoff of keeps core's
+// verdict.
+func (r *AnalysisResult) judge(t analysis.Thresholds) {
+	r.Thresholds = t
+	if r.SpectrumCutoff <= 0 {
+		return
+	}
+	j := analysis.Judge(r.SpectrumCutoff, r.SampleRate, t)
+	r.Verdict, r.VerdictLabel = j.Verdict, j.VerdictLabel
+	r.IsTrueLossless = j.Lossless
+	r.Confidence = float64(j.Confidence)
+}
+
+// measure adds the measurements of the decoded samples of the FLAC at
+// path to result, when FFmpeg is available, and writes its loudness tags
+// (see Analyze).
+func measure(ctx context.Context, result *AnalysisResult, path string, s settings.Settings) {
+	ffmpeg, err := FFmpegPath()
+	if err != nil {
+		return
+	}
+	m, err := analysis.Analyze(ctx, ffmpeg, path)
+	if err != nil {
+		result.Details = joinDetails(result.Details, "samples not measured: "+err.Error())
+		return
+	}
+	result.Result = *m
+	if s.LoudnessTags && m.Loudness != nil {
+		if r := tagedit.Edit(path, m.Loudness.Tags(), tagedit.Merge, false); r.Error != "" {
+			result.Details = joinDetails(result.Details, "loudness tags not written: "+r.Error)
+		} else {
+			result.LoudnessTagged = true
+		}
+	}
+}
+
+// lossySource notes the lossy codec the spectrum points to in r's
+// details when r is judged upscaled, and drops it when r is lossless: a
+// lossless file's natural rolloff says nothing of a codec.
+func (r *AnalysisResult) lossySource() {
+	if r.IsTrueLossless {
+		r.LossyCodec, r.LossyCodecLabel = "", ""
+		return
+	}
+	if r.LossyCodecLabel != "" {
+		r.Details = joinDetails(r.Details, "likely source: "+r.LossyCodecLabel)
+	}
+}
+
+// mqaTags marks result as MQA when the FLAC at path has MQA's tags,
+// which needs no FFmpeg (see analysis.MQATags).
+func mqaTags(result *AnalysisResult, path string) {
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return
+	}
+	if c, err := f.Comments(); err == nil {
+		analysis.MQATags(&result.Result, c)
+	}
+}
+
+// storedVerdict is what analysisstore keeps of r for listings.
+func (r *AnalysisResult) storedVerdict() analysisstore.Verdict {
+	return analysisstore.Verdict{
+		Verdict:        r.Verdict,
+		VerdictLabel:   r.VerdictLabel,
+		PaddedBitDepth: r.PaddedBitDepth,
+		Clipping:       r.Clipping,
+		AnalyzedAt:     timestamp.UTC(time.Now()),
+	}
+}
+
+// AnalyzeMultiple analyzes paths AnalysisWorkers(s) at a time (see
+// AnalyzeCached), returning the results in the order of paths; a file
+// that fails gets an "error" result carrying the reason in its details.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func AnalyzeMultiple(ctx context.Context, paths []string, force bool, s settings.Settings, store *analysisstore.Store) []AnalysisResult {
+	results := make([]AnalysisResult, len(paths))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(AnalysisWorkers(s), max(len(paths), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = analyzeOrError(ctx, paths[i], force, s, store)
+			}
+		}()
+	}
+	for i := range paths {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// analyzeOrError is path's analysis, or an "error" result when it fails.
+func analyzeOrError(ctx context.Context, path string, force bool, s settings.Settings, store *analysisstore.Store) AnalysisResult {
+	r, err := AnalyzeCached(ctx, path, force, s, store)
+	if err != nil {
+		return AnalysisResult{AnalysisResult: core.AnalysisResult{
+			FilePath:     path,
+			FileName:     filepath.Base(path),
+			Verdict:      "error",
+			VerdictLabel: "Error",
+			Details:      err.Error(),
+		}}
+	}
+	return *r
+}
+
+// QualityReportFileName is the suggested name of a saved quality report,
+// before its extension.
+const QualityReportFileName = "quality-report"
+
+// QualityReport lists the library index's tracks with their saved
+// analyses. Shared by the desktop (Wails) and HTTP server APIs.
+func QualityReport(store *analysisstore.Store) (*analysisstore.Report, error) {
+	if store == nil {
+		return nil, errors.New("analysis store not available")
+	}
+	return store.Report()
+}
+
+// joinDetails appends note to an analysis' details.
+func joinDetails(details, note string) string {
+	if details == "" {
+		return note
+	}
+	return details + "; " + note
+}
@@ -1,9 +1,17 @@
 package app
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/analysis"
+	"flacidal/internal/analysisstore"
+	"flacidal/internal/settings"
 )
 
 // Characterization tests for the "Analyzer Methods" section of app.go.
@@ -33,3 +41,52 @@ func TestQuickAnalyze_InvalidFile(t *testing.T) {
 		t.Error("QuickAnalyze() on a missing file: want error, got nil")
 	}
 }
+
+func TestCachedAnalysis(t *testing.T) {
+	dir := t.TempDir()
+	store, err := analysisstore.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	path := filepath.Join(dir, "a.flac")
+	if err := os.WriteFile(path, []byte("fLaC"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := settings.Settings{}
+	saved := &AnalysisResult{
+		AnalysisResult: core.AnalysisResult{FilePath: path, Verdict: "upscaled"},
+		Thresholds:     analysis.DefaultThresholds,
+	}
+	if err := store.Put(path, saved.storedVerdict(), saved); err != nil {
+		t.Fatal(err)
+	}
+
+	r := CachedAnalysis(path, s, store)
+	if r == nil || !r.Cached || r.Verdict != "upscaled" {
+		t.Fatalf("unchanged file: %+v", r)
+	}
+	if r, err := AnalyzeCached(context.Background(), path, false, s, store); err != nil || !r.Cached {
+		t.Errorf("AnalyzeCached() = %+v, %v; want the saved analysis", r, err)
+	}
+	if _, err := AnalyzeCached(context.Background(), path, true, s, store); err == nil {
+		t.Error("AnalyzeCached(force) reused the saved analysis of an invalid FLAC")
+	}
+
+	s.AnalyzerThresholds.LosslessHz = 19000
+	if r := CachedAnalysis(path, s, store); r != nil {
+		t.Errorf("other thresholds: %+v", r)
+	}
+
+	s = settings.Settings{}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if r := CachedAnalysis(path, s, store); r != nil {
+		t.Errorf("changed file: %+v", r)
+	}
+	if r := CachedAnalysis(path, s, nil); r != nil {
+		t.Errorf("no store: %+v", r)
+	}
+}
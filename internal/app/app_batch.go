@@ -0,0 +1,307 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/acoustid"
+	"flacidal/internal/convert"
+	"flacidal/internal/jobs"
+	"flacidal/internal/library"
+	"flacidal/internal/musicbrainz"
+	"flacidal/internal/naming"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/settings"
+	"flacidal/internal/tagedit"
+	"flacidal/internal/tagrules"
+)
+
+// Batch operations StartBatch accepts.
+const (
+	BatchRename      = "rename"
+	BatchRetag       = "retag"
+	BatchStrip       = "strip"
+	BatchFromNames   = "filename"
+	BatchMove        = "move"
+	BatchConvert     = "convert"
+	BatchMusicBrainz = "musicbrainz"
+	BatchAcoustID    = "acoustid"
+	BatchNormalize   = "normalize"
+	BatchGenres      = "genres"
+	BatchDelete      = "delete"
+)
+
+// BatchRequest describes a file operation to run as a batch. Op selects it
+// and which of the other fields apply.
+type BatchRequest struct {
+	Op     string   `json:"op"`
+	Files  []string `json:"files"`
+	Atomic bool     `json:"atomic"` // roll everything back on the first failure
+
+	Template string `json:"template,omitempty"` // rename: core rename template, or a folder template (see ApplyRenames)
+
+	// DownloadFolder is where a rename's folder template starts for the
+	// files inside it (see RenameRoot). Callers set it from the config.
+	DownloadFolder string `json:"-"`
+
+	// Library is the index renames and moves record the files' new paths
+	// in, and deletions drop the files from; nil leaves them to the next
+	// scan. Callers set it.
+	Library *library.Index `json:"-"`
+
+	Tags map[string]string `json:"tags,omitempty"` // retag: see SetTags
+	Mode string            `json:"mode,omitempty"`
+
+	Strip tagedit.StripOptions `json:"strip,omitzero"` // strip: see StripTags
+
+	Pattern string `json:"pattern,omitempty"` // filename: see TagsFromNames
+
+	Rules tagrules.Rules `json:"rules,omitzero"` // normalize: see NormalizeTags
+
+	Dest string `json:"dest,omitempty"` // move: destination folder
+
+	Format    string `json:"format,omitempty"` // convert: see ConversionWork; sources are kept
+	Quality   string `json:"quality,omitempty"`
+	OutputDir string `json:"outputDir,omitempty"`
+}
+
+// =============================================================================
+// Batch Operations (exposed to frontend)
+// =============================================================================
+
+// StartBatch queues a file operation over many files as a job and returns
+// it; "batch-progress" events follow it.
+func (a *App) StartBatch(req BatchRequest) (jobs.Job, error) {
+	req.DownloadFolder = a.GetDownloadFolder()
+	req.Library = a.library
+	work, err := BatchWork(a.currentSettings(), req)
+	if err != nil {
+		return jobs.Job{}, err
+	}
+	b := a.fileBatches.Start(req.Op, req.Files, work, jobs.Options{Atomic: req.Atomic})
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Started %s batch %s (%d files)", req.Op, b.ID, len(req.Files)))
+	}
+	return b, nil
+}
+
+// GetBatch returns a batch with its per-file results.
+func (a *App) GetBatch(id string) (jobs.Job, error) {
+	b, ok := a.fileBatches.Get(id)
+	if !ok {
+		return jobs.Job{}, jobs.ErrNotFound
+	}
+	return b, nil
+}
+
+// ListBatches lists the recent batches, newest first.
+func (a *App) ListBatches() []jobs.Job {
+	return a.fileBatches.List()
+}
+
+// UndoBatch reverts what a finished batch changed.
+func (a *App) UndoBatch(id string) (jobs.Job, error) {
+	b, err := a.fileBatches.Undo(id)
+	if err == nil && a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Undid %s batch %s", b.Kind, id))
+	}
+	return b, err
+}
+
+// BatchWork validates req and returns the job work running its operation
+// on one file, refusing broken files in strict mode. Each file's
+// jobs.Change records how to undo it: renames and moves are moved back,
+// tag edits restore the saved metadata, and conversions delete their
+// output. Conversions are ConversionWork, as in conversion jobs. Deletions
+// can't be undone, and are the one operation strict mode lets through for
+// broken files. Shared by the desktop (Wails) and HTTP server APIs.
+func BatchWork(s settings.Settings, req BatchRequest) (jobs.Work, error) {
+	if len(req.Files) == 0 {
+		return nil, errors.New("files are required")
+	}
+	var op jobs.Work
+	switch req.Op {
+	case BatchRename:
+		if req.Template == "" {
+			return nil, errors.New("template is required")
+		}
+		op = renameStep(req.Library, req.Template, req.DownloadFolder, s.MaxPathLength, s.FileConflict)
+	case BatchRetag:
+		mode := tagedit.Mode(req.Mode)
+		fields, err := tagedit.Fields(req.Tags, mode)
+		if err != nil {
+			return nil, err
+		}
+		op = tagStep(func(_ context.Context, path string) tagedit.Result { return tagedit.Edit(path, fields, mode, false) })
+	case BatchStrip:
+		opts, err := req.Strip.Check()
+		if err != nil {
+			return nil, err
+		}
+		op = tagStep(func(_ context.Context, path string) tagedit.Result { return tagedit.Strip(path, opts, false) })
+	case BatchFromNames:
+		p, err := tagedit.ParseNamePattern(req.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		op = tagStep(func(_ context.Context, path string) tagedit.Result { return tagedit.FromName(path, p, false) })
+	case BatchNormalize:
+		if req.Rules.IsZero() {
+			return nil, errNoRules
+		}
+		rules := req.Rules
+		op = tagStep(func(_ context.Context, path string) tagedit.Result { return tagedit.Normalize(path, rules, false) })
+	case BatchGenres:
+		if len(s.GenreMap) == 0 {
+			return nil, errNoGenreMap
+		}
+		m := s.GenreMap
+		op = tagStep(func(_ context.Context, path string) tagedit.Result { return tagedit.RemapGenres(path, m, false) })
+	case BatchMusicBrainz:
+		op = tagStep(func(ctx context.Context, path string) tagedit.Result {
+			r, _ := musicbrainz.Default.Enrich(ctx, path, false)
+			return r
+		})
+	case BatchAcoustID:
+		if err := acoustid.Default.Ready(s.AcoustIDKey); err != nil {
+			return nil, err
+		}
+		op = tagStep(func(ctx context.Context, path string) tagedit.Result {
+			r, _ := acoustid.Default.Identify(ctx, s.AcoustIDKey, path, false)
+			return r
+		})
+	case BatchMove:
+		if req.Dest == "" {
+			return nil, errors.New("dest is required")
+		}
+		op = moveStep(req.Library, req.Dest)
+	case BatchConvert:
+		ffmpeg, err := FFmpegPath()
+		if err != nil {
+			return nil, err
+		}
+		if !convert.Supported(req.Format) {
+			return nil, fmt.Errorf("unsupported format %q", req.Format)
+		}
+		// ConversionWork checks strict validation itself
+		return convertStep(ConversionWork(ffmpeg, core.ConversionOptions{Format: req.Format, Quality: req.Quality, OutputDir: req.OutputDir}, s, nil)), nil
+	case BatchDelete:
+		return deleteStep(req.Library), nil
+	default:
+		return nil, fmt.Errorf("unknown batch operation %q", req.Op)
+	}
+	return func(ctx context.Context, path string) (any, error) {
+		if err := CheckStrict(s, path); err != nil {
+			return nil, err
+		}
+		return op(ctx, path)
+	}, nil
+}
+
+// moveBack returns an undo func moving the file at to back to from,
+// recreating from's folder if a folder rename removed it, and recording
+// the move in idx.
+func moveBack(idx *library.Index, from, to string) func() error {
+	return func() error {
+		if err := os.MkdirAll(filepath.Dir(from), 0755); err != nil {
+			return err
+		}
+		if _, err := postprocess.Move(to, from); err != nil {
+			return err
+		}
+		MoveInLibrary(idx, to, from) //nolint:errcheck // the next scan catches up
+		return nil
+	}
+}
+
+func renameStep(idx *library.Index, template, downloadFolder string, maxPath int, conflict naming.Conflict) jobs.Work {
+	return func(_ context.Context, path string) (any, error) {
+		var r core.RenameResult
+		if naming.IsFolderTemplate(template) {
+			r = FolderRename(idx, downloadFolder, maxPath, conflict, []string{path}, template)[0]
+		} else {
+			r = core.RenameFiles([]string{path}, template)[0]
+		}
+		if !r.Success {
+			return nil, errors.New(r.Error)
+		}
+		if r.NewPath == "" || r.NewPath == path {
+			return nil, nil
+		}
+		if !naming.IsFolderTemplate(template) {
+			MoveInLibrary(idx, path, r.NewPath) //nolint:errcheck // the next scan catches up
+		}
+		return jobs.Change{Output: r.NewPath, Undo: moveBack(idx, path, r.NewPath)}, nil
+	}
+}
+
+func moveStep(idx *library.Index, dest string) jobs.Work {
+	return func(_ context.Context, path string) (any, error) {
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return nil, err
+		}
+		to := filepath.Join(dest, filepath.Base(path))
+		if to == path {
+			return nil, nil
+		}
+		if _, err := postprocess.Move(path, to); err != nil {
+			return nil, err
+		}
+		MoveInLibrary(idx, path, to) //nolint:errcheck // the next scan catches up
+		return jobs.Change{Output: to, Undo: moveBack(idx, path, to)}, nil
+	}
+}
+
+// tagStep wraps a tagedit operation, snapshotting the metadata first so it
+// can be restored.
+func tagStep(edit func(ctx context.Context, path string) tagedit.Result) jobs.Work {
+	return func(ctx context.Context, path string) (any, error) {
+		restore, err := tagedit.Snapshot(path)
+		if err != nil {
+			return nil, err
+		}
+		r := edit(ctx, path)
+		if r.Detail != nil {
+			return nil, r.Detail // keeps the code, path and mount for the batch item
+		}
+		if r.Error != "" {
+			return nil, errors.New(r.Error)
+		}
+		if !r.Written {
+			return nil, nil
+		}
+		return jobs.Change{Undo: restore}, nil
+	}
+}
+
+// deleteStep deletes path and its same-named .lrc lyrics sidecar, if any,
+// dropping the file from idx.
+func deleteStep(idx *library.Index) jobs.Work {
+	return func(_ context.Context, path string) (any, error) {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+		os.Remove(strings.TrimSuffix(path, filepath.Ext(path)) + ".lrc") //nolint:errcheck // usually absent
+		RemoveFromLibrary(idx, path)                                     //nolint:errcheck // the next scan catches up
+		return nil, nil
+	}
+}
+
+// convertStep makes the conversion of a file by work, a ConversionWork,
+// undoable by deleting its output.
+func convertStep(work jobs.Work) jobs.Work {
+	return func(ctx context.Context, path string) (any, error) {
+		res, err := work(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		r := res.(core.ConversionResult)
+		return jobs.Change{Result: r, Output: r.OutputPath, Undo: func() error { return os.Remove(r.OutputPath) }}, nil
+	}
+}
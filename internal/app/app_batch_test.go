@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/jobs"
+	"flacidal/internal/settings"
+)
+
+// runBatch runs work over paths as a job on m and returns it finished.
+func runBatch(t *testing.T, m *jobs.Manager, paths []string, work jobs.Work) jobs.Job {
+	t.Helper()
+	_, ch := m.Events.Subscribe(16)
+	j := m.Start("test", paths, work, jobs.Options{})
+	for ev := range ch {
+		if ev.ID == j.ID && ev.State.Finished() {
+			break
+		}
+	}
+	j, _ = m.Get(j.ID)
+	return j
+}
+
+func TestBatchWork_RetagUndo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path, map[string]string{"GENRE": "Rock"}, nil, 64)
+
+	work, err := BatchWork(settings.Settings{}, BatchRequest{Op: BatchRetag, Files: []string{path}, Tags: map[string]string{"GENRE": "Jazz"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m jobs.Manager
+	b := runBatch(t, &m, []string{path}, work)
+	if b.State != jobs.Done || !b.Undoable {
+		t.Fatalf("batch = %+v", b)
+	}
+	if _, err := m.Undo(b.ID); err != nil {
+		t.Fatal(err)
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := f.Comments(); c.Get("GENRE") != "Rock" {
+		t.Errorf("GENRE = %q after undo, want Rock", c.Get("GENRE"))
+	}
+}
+
+func TestBatchWork_StrictAndMove(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.flac")
+	writeTestFLAC(t, broken, nil, nil, 64)
+	other := filepath.Join(dir, "other.flac")
+	if err := os.WriteFile(filepath.Join(dir, "other.lrc"), []byte("[00:01.00]x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFLAC(t, other, nil, nil, 64)
+	dest := filepath.Join(dir, "out")
+
+	work, err := BatchWork(settings.Settings{StrictValidation: true}, BatchRequest{Op: BatchMove, Files: []string{broken}, Dest: dest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := work(context.Background(), broken); err == nil {
+		t.Error("strict mode moved a broken file")
+	}
+
+	work, _ = BatchWork(settings.Settings{}, BatchRequest{Op: BatchMove, Files: []string{other}, Dest: dest})
+	res, err := work(context.Background(), other)
+	change, _ := res.(jobs.Change)
+	if err != nil || change.Output != filepath.Join(dest, "other.flac") {
+		t.Fatalf("work = %+v, %v", res, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "other.lrc")); err != nil {
+		t.Error("lyrics sidecar not moved")
+	}
+	if err := change.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Error("undo didn't move the file back")
+	}
+}
+
+func TestBatchWork_DeleteBroken(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.flac")
+	writeTestFLAC(t, broken, nil, nil, 64)
+	lyrics := filepath.Join(dir, "broken.lrc")
+	if err := os.WriteFile(lyrics, []byte("[00:01.00]x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	work, err := BatchWork(settings.Settings{StrictValidation: true}, BatchRequest{Op: BatchDelete, Files: []string{broken}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := work(context.Background(), broken); err != nil || res != nil {
+		t.Fatalf("work = %+v, %v; strict mode may delete broken files, for good", res, err)
+	}
+	for _, p := range []string{broken, lyrics} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s not deleted", p)
+		}
+	}
+	if _, err := work(context.Background(), broken); err == nil {
+		t.Error("deleting a missing file succeeded")
+	}
+}
+
+func TestBatchWork_Validation(t *testing.T) {
+	for name, req := range map[string]BatchRequest{
+		"unknown op":      {Op: "shred", Files: []string{"a"}},
+		"no files":        {Op: BatchMove, Dest: "d"},
+		"rename template": {Op: BatchRename, Files: []string{"a"}},
+		"retag bad mode":  {Op: BatchRetag, Files: []string{"a"}, Tags: map[string]string{"A": "b"}, Mode: "append"},
+		"strip nothing":   {Op: BatchStrip, Files: []string{"a"}},
+	} {
+		if _, err := BatchWork(settings.Settings{}, req); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/logging"
+)
+
+// =============================================================================
+// Clipboard Watcher
+// =============================================================================
+
+// clipboardPollInterval is how often the clipboard is read while watching.
+const clipboardPollInterval = time.Second
+
+// clipboardEvent is emitted with the detected URL when the user copies a
+// supported music URL and Settings.WatchClipboard is on.
+const clipboardEvent = "clipboard-url"
+
+// clipboardURL returns the URL in copied text, or "" when the text isn't a
+// single http(s) URL. Anything longer than a line is left alone so copying
+// prose that mentions a link doesn't prompt.
+func clipboardURL(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, " \t\r\n") {
+		return ""
+	}
+	if !strings.HasPrefix(text, "https://") && !strings.HasPrefix(text, "http://") {
+		return ""
+	}
+	return text
+}
+
+// clipboardOffer validates copied text through the source manager and
+// returns the event payload to offer it for download, or false when no
+// registered source can handle it.
+func (a *App) clipboardOffer(text string) (map[string]interface{}, bool) {
+	url := clipboardURL(text)
+	if url == "" || a.sourceManager == nil {
+		return nil, false
+	}
+	detected := a.DetectSourceFromURL(url)
+	if detected["detected"] != true {
+		return nil, false
+	}
+	detected["url"] = url
+	return detected, true
+}
+
+// watchClipboard polls the clipboard until ctx is done, emitting
+// clipboardEvent for each newly copied supported URL. Text already on the
+// clipboard when watching starts (or is re-enabled) is never offered.
+func (a *App) watchClipboard(ctx context.Context) {
+	ticker := time.NewTicker(clipboardPollInterval)
+	defer ticker.Stop()
+
+	last, watching := "", false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !a.currentSettings().WatchClipboard {
+			watching = false
+			continue
+		}
+		text, err := runtime.ClipboardGetText(a.ctx)
+		if err != nil || (watching && text == last) {
+			continue
+		}
+		first := !watching
+		last, watching = text, true
+		if first {
+			continue
+		}
+
+		if offer, ok := a.clipboardOffer(text); ok {
+			a.logger(logging.Downloads).Debug("clipboard URL detected", "url", offer["url"])
+			runtime.EventsEmit(a.ctx, clipboardEvent, offer)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"testing"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+)
+
+func TestClipboardURL(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"https://tidal.com/browse/album/123", "https://tidal.com/browse/album/123"},
+		{"  https://open.qobuz.com/album/abc\n", "https://open.qobuz.com/album/abc"},
+		{"http://tidal.com/track/1", "http://tidal.com/track/1"},
+		{"", ""},
+		{"tidal.com/browse/album/123", ""},
+		{"check this out https://tidal.com/browse/album/123", ""},
+		{"https://tidal.com/browse/album/1\nhttps://tidal.com/browse/album/2", ""},
+		{"ftp://example.com/file", ""},
+	}
+	for _, tt := range tests {
+		if got := clipboardURL(tt.text); got != tt.want {
+			t.Errorf("clipboardURL(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestClipboardOffer_Unsupported(t *testing.T) {
+	if _, ok := (&App{}).clipboardOffer("https://tidal.com/browse/album/1"); ok {
+		t.Error("clipboardOffer() with nil sourceManager: want no offer")
+	}
+
+	a := &App{sourceManager: core.NewSourceManager()}
+	if _, ok := a.clipboardOffer("https://example.com/album/1"); ok {
+		t.Error("clipboardOffer() for a URL no source handles: want no offer")
+	}
+	if _, ok := a.clipboardOffer("not a url"); ok {
+		t.Error("clipboardOffer() for plain text: want no offer")
+	}
+}
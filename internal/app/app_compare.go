@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"flacidal/internal/analysis"
+	"flacidal/internal/analysisstore"
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/settings"
+)
+
+// =============================================================================
+// File and Album Comparison (exposed to frontend)
+// =============================================================================
+
+// CompareFiles analyzes two copies of the same track, such as its Tidal
+// and Qobuz downloads, and tells which is the better keep (see
+// analysis.Compare).
+func (a *App) CompareFiles(fileA, fileB string) (*analysis.Comparison, error) {
+	c, err := CompareFiles(a.ctx, fileA, fileB, a.currentSettings(), a.analyses)
+	if err == nil && a.logBuffer != nil {
+		keep := "either"
+		switch c.Keep {
+		case "a":
+			keep = filepath.Base(fileA)
+		case "b":
+			keep = filepath.Base(fileB)
+		}
+		a.logBuffer.Info(fmt.Sprintf("Compared %s and %s: keep %s, %s", filepath.Base(fileA), filepath.Base(fileB), keep, c.Reason))
+	}
+	return c, err
+}
+
+// CompareFiles analyzes the FLACs fileA and fileB (see Analyze), saving
+// the analyses in store, and compares them. Shared by the desktop (Wails)
+// and HTTP server APIs.
+func CompareFiles(ctx context.Context, fileA, fileB string, s settings.Settings, store *analysisstore.Store) (*analysis.Comparison, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if fileA == "" || fileB == "" {
+		return nil, errors.New("two files are required")
+	}
+	if filepath.Clean(fileA) == filepath.Clean(fileB) {
+		return nil, errors.New("pick two different files")
+	}
+	a, err := compareVersion(ctx, fileA, s, store)
+	if err != nil {
+		return nil, err
+	}
+	b, err := compareVersion(ctx, fileB, s, store)
+	if err != nil {
+		return nil, err
+	}
+	c := analysis.Compare(a, b)
+	return &c, nil
+}
+
+// compareVersion analyzes the FLAC at path for CompareFiles.
+func compareVersion(ctx context.Context, path string, s settings.Settings, store *analysisstore.Store) (analysis.Version, error) {
+	r, err := Analyze(ctx, path, s, store)
+	if err != nil {
+		return analysis.Version{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return r.version(path, audioMD5(ctx, path)), nil
+}
+
+// version is what analysis.Compare weighs of r, the analysis of the file
+// at path whose decoded audio hashes to md5.
+func (r *AnalysisResult) version(path, md5 string) analysis.Version {
+	return analysis.Version{
+		Path:              path,
+		SampleRate:        r.SampleRate,
+		BitDepth:          r.BitDepth,
+		EffectiveBitDepth: r.EffectiveBitDepth,
+		Cutoff:            r.SpectrumCutoff,
+		Verdict:           r.Verdict,
+		VerdictLabel:      r.VerdictLabel,
+		MQA:               r.MQA,
+		Truncated:         r.Truncated,
+		Clipping:          r.Clipping,
+		ClippedPercent:    r.ClippedPercent,
+		Loudness:          r.Loudness,
+		AudioMD5:          md5,
+	}
+}
+
+// audioMD5 is the MD5 of the decoded audio of the FLAC at path: the
+// signature its encoder stored in STREAMINFO or, without one, that of a
+// full decode (see analysis.Verify). Empty when neither is to be had.
+func audioMD5(ctx context.Context, path string) string {
+	si, err := flacmeta.ReadStreamInfo(path)
+	if err != nil {
+		return ""
+	}
+	if si.MD5 != ([16]byte{}) {
+		return hex.EncodeToString(si.MD5[:])
+	}
+	ffmpeg, err := FFmpegPath()
+	if err != nil {
+		return ""
+	}
+	v, err := analysis.Verify(ctx, ffmpeg, path)
+	if err != nil {
+		return ""
+	}
+	return v.MD5
+}
+
+// CheckAlbum analyzes the album in folder, subfolders such as "Disc 2"
+// included, and flags the tracks that stand out from the rest (see
+// analysis.CheckAlbum).
+func (a *App) CheckAlbum(folder string) (*analysis.AlbumReport, error) {
+	r, err := CheckAlbum(a.ctx, folder, a.currentSettings(), a.analyses)
+	if err == nil && a.logBuffer != nil {
+		if r.Consistent {
+			a.logBuffer.Info(fmt.Sprintf("Album %s: %d tracks, all %s %s", filepath.Base(folder), len(r.Tracks), r.Format, r.Verdict))
+		}
+		for _, issue := range r.Issues {
+			a.logBuffer.Warn(fmt.Sprintf("Album %s: %s is %s", filepath.Base(folder), filepath.Base(issue.Path), issue.Problem))
+		}
+	}
+	return r, err
+}
+
+// CheckAlbum analyzes the FLACs in folder and its subfolders (see
+// AnalyzeMultiple), saving the analyses in store, and checks them for
+// consistency. Shared by the desktop (Wails) and HTTP server APIs.
+func CheckAlbum(ctx context.Context, folder string, s settings.Settings, store *analysisstore.Store) (*analysis.AlbumReport, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if folder == "" {
+		return nil, errors.New("folder is required")
+	}
+	paths, err := analysis.FLACFiles(ctx, folder, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no FLAC files in %s", folder)
+	}
+	results := AnalyzeMultiple(ctx, paths, false, s, store)
+	tracks := make([]analysis.Version, len(results))
+	for i := range results {
+		tracks[i] = results[i].version(paths[i], "")
+	}
+	r := analysis.CheckAlbum(folder, tracks)
+	return &r, nil
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"flacidal/internal/settings"
+)
+
+func TestCompareFiles_Validation(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.flac")
+	for _, tc := range [][2]string{{"", a}, {a, ""}, {a, filepath.Join(dir, ".", "a.flac")}} {
+		if _, err := CompareFiles(context.Background(), tc[0], tc[1], settings.Settings{}, nil); err == nil {
+			t.Errorf("CompareFiles(%q, %q) succeeded", tc[0], tc[1])
+		}
+	}
+	if _, err := CompareFiles(context.Background(), a, filepath.Join(dir, "b.flac"), settings.Settings{}, nil); err == nil {
+		t.Error("CompareFiles on missing files succeeded")
+	}
+}
+
+func TestCheckAlbum_Validation(t *testing.T) {
+	if _, err := CheckAlbum(context.Background(), "", settings.Settings{}, nil); err == nil {
+		t.Error("CheckAlbum without a folder succeeded")
+	}
+	if _, err := CheckAlbum(context.Background(), t.TempDir(), settings.Settings{}, nil); err == nil {
+		t.Error("CheckAlbum on a folder without FLACs succeeded")
+	}
+}
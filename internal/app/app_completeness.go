@@ -0,0 +1,274 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/naming"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/textmatch"
+)
+
+// =============================================================================
+// Album Completeness (exposed to frontend)
+// =============================================================================
+
+// CompletenessTrack is one track of the content a folder is checked
+// against, and the file holding it.
+type CompletenessTrack struct {
+	TrackID     string `json:"trackId"`
+	Title       string `json:"title"`
+	Artist      string `json:"artist"`
+	TrackNumber int    `json:"trackNumber,omitempty"`
+	DiscNumber  int    `json:"discNumber,omitempty"`
+	ISRC        string `json:"isrc,omitempty"`
+	File        string `json:"file,omitempty"` // empty when the track is missing
+	By          string `json:"by,omitempty"`   // "id", "isrc", or as in postprocess.MatchFiles
+}
+
+// CompletenessReport compares the FLAC files in a folder with the track
+// list of the album (or playlist) they were downloaded from.
+type CompletenessReport struct {
+	Folder  string              `json:"folder"`
+	URL     string              `json:"url"` // the content's, to queue the missing tracks from
+	Source  string              `json:"source"`
+	Type    string              `json:"type"`
+	Title   string              `json:"title"`
+	Artist  string              `json:"artist,omitempty"`
+	Tracks  []CompletenessTrack `json:"tracks"` // in the source's order
+	Missing int                 `json:"missing"`
+	Extra   []string            `json:"extra,omitempty"` // files no track matches
+}
+
+// CheckAlbumCompleteness compares the FLAC files in dir with the track list
+// of rawURL, or, when rawURL is empty, of the album the files' provenance
+// tags say they were downloaded from.
+func (a *App) CheckAlbumCompleteness(dir, rawURL string) (*CompletenessReport, error) {
+	if a.sourceManager == nil {
+		return nil, fmt.Errorf("source manager not initialized")
+	}
+	return CheckCompleteness(a.sourceManager, dir, rawURL, a.currentSettings().MatchNormalization)
+}
+
+// CheckHistoryCompleteness checks the folder a history record's content
+// was downloaded into (see HistoryFolder) against the content.
+func (a *App) CheckHistoryCompleteness(contentID string) (*CompletenessReport, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	record, err := a.db.GetDownloadRecord(contentID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("history record not found")
+	}
+	url, err := RefetchURL(a.origins, record)
+	if err != nil {
+		return nil, err
+	}
+	return a.CheckAlbumCompleteness(HistoryFolder(a.GetDownloadFolder(), record, a.currentSettings().FilenameUnicode), url)
+}
+
+// QueueMissingTracks checks dir against rawURL again and queues the tracks
+// no file holds into dir, next to the ones that are there.
+func (a *App) QueueMissingTracks(dir, rawURL string) (int, error) {
+	if a.downloadManager == nil || a.sourceManager == nil {
+		return 0, fmt.Errorf("download manager not initialized")
+	}
+	content, missing, err := MissingTracks(a.sourceManager, dir, rawURL, a.currentSettings().MatchNormalization)
+	if err != nil {
+		return 0, err
+	}
+	if err := CheckDownloadable(content.Source); err != nil {
+		return 0, err
+	}
+	var queued int
+	if content.Source == "qobuz" {
+		queued, err = a.QueueQobuzDownloads(missing, dir, "", "", content.Type)
+	} else {
+		queued, err = a.QueueDownloads(TidalTracksFromSource(missing), dir, "", "", content.Type)
+	}
+	if err == nil && a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Queued %d missing tracks of %s into %s", queued, content.Title, dir))
+	}
+	return queued, err
+}
+
+// HistoryFolder returns the folder a history record's content was queued
+// into: its name's subfolder of the download folder, as QueueDownloads
+// creates it. Downloads moved by OrganizeFolders are elsewhere. Shared by
+// the desktop (Wails) and HTTP server APIs.
+func HistoryFolder(downloadFolder string, record *core.DownloadRecord, unicode naming.UnicodeMode) string {
+	return filepath.Join(downloadFolder, SafeFolderName(record.TidalContentName, unicode))
+}
+
+// CheckCompleteness resolves rawURL through sm — or, when it is empty, the
+// album the files in dir were downloaded from (see ProvenanceAlbumURL) —
+// and matches the files to its tracks (see MatchCompleteness). Shared by
+// the desktop (Wails) and HTTP server APIs.
+func CheckCompleteness(sm *core.SourceManager, dir, rawURL string, mode textmatch.Mode) (*CompletenessReport, error) {
+	content, files, err := resolveCompleteness(sm, dir, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return CompletenessOf(dir, content, files, mode), nil
+}
+
+// MissingTracks returns the content CheckCompleteness compares dir with
+// and its tracks no file in dir holds. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func MissingTracks(sm *core.SourceManager, dir, rawURL string, mode textmatch.Mode) (*ResolvedContent, []core.SourceTrack, error) {
+	content, files, err := resolveCompleteness(sm, dir, rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var missing []core.SourceTrack
+	for i, t := range CompletenessOf(dir, content, files, mode).Tracks {
+		if t.File == "" {
+			missing = append(missing, content.Tracks[i])
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil, fmt.Errorf("no track of %s is missing", content.Title)
+	}
+	return content, missing, nil
+}
+
+// resolveCompleteness reads the files in dir and resolves the content to
+// check them against.
+func resolveCompleteness(sm *core.SourceManager, dir, rawURL string) (*ResolvedContent, []postprocess.LocalFile, error) {
+	if dir == "" {
+		return nil, nil, fmt.Errorf("no folder specified")
+	}
+	files, err := postprocess.ReadLocalFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rawURL == "" {
+		if rawURL, err = ProvenanceAlbumURL(sm, files); err != nil {
+			return nil, nil, err
+		}
+	}
+	content, err := ResolveContent(sm, rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, files, nil
+}
+
+// ProvenanceAlbumURL returns the URL of the album the first of files with
+// provenance tags belongs to, looked up through sm by its track ID.
+func ProvenanceAlbumURL(sm *core.SourceManager, files []postprocess.LocalFile) (string, error) {
+	for _, f := range files {
+		formats := exportServices[f.Source]
+		if f.SourceID == "" || formats == nil {
+			continue
+		}
+		track, err := ResolveContent(sm, fmt.Sprintf(formats["track"], f.SourceID))
+		if err != nil {
+			return "", fmt.Errorf("look up %s: %w", filepath.Base(f.Path), err)
+		}
+		if albumID := track.Tracks[0].AlbumID; albumID != "" {
+			return fmt.Sprintf(formats["album"], albumID), nil
+		}
+	}
+	return "", fmt.Errorf("no file says which album it was downloaded from; give the album's URL")
+}
+
+// CompletenessOf matches files to content's tracks (see MatchCompleteness)
+// and reports which tracks no file holds.
+func CompletenessOf(dir string, content *ResolvedContent, files []postprocess.LocalFile, mode textmatch.Mode) *CompletenessReport {
+	report := &CompletenessReport{
+		Folder: dir,
+		URL:    content.URL,
+		Source: content.Source,
+		Type:   content.Type,
+		Title:  content.Title,
+		Artist: content.Artist,
+		Tracks: make([]CompletenessTrack, len(content.Tracks)),
+	}
+	for i, t := range content.Tracks {
+		report.Tracks[i] = CompletenessTrack{
+			TrackID:     t.ID,
+			Title:       t.Title,
+			Artist:      t.Artist,
+			TrackNumber: t.TrackNumber,
+			DiscNumber:  t.DiscNumber,
+			ISRC:        t.ISRC,
+		}
+	}
+	matched := make([]bool, len(files))
+	for _, m := range MatchCompleteness(files, content, mode) {
+		matched[m.File] = true
+		report.Tracks[m.Track].File, report.Tracks[m.Track].By = files[m.File].Path, m.By
+	}
+	for _, t := range report.Tracks {
+		if t.File == "" {
+			report.Missing++
+		}
+	}
+	for i, f := range files {
+		if !matched[i] {
+			report.Extra = append(report.Extra, f.Path)
+		}
+	}
+	return report
+}
+
+// MatchCompleteness pairs files with content's tracks: by the track ID a
+// file's provenance tags name ("id"), then by ISRC ("isrc"), and the rest
+// as postprocess.MatchFiles pairs them. Unpaired files and tracks are left
+// out.
+func MatchCompleteness(files []postprocess.LocalFile, content *ResolvedContent, mode textmatch.Mode) []postprocess.Match {
+	var matches []postprocess.Match
+	usedFiles := make([]bool, len(files))
+	usedTracks := make([]bool, len(content.Tracks))
+	pair := func(by string, same func(f postprocess.LocalFile, t core.SourceTrack) bool) {
+		for ti, t := range content.Tracks {
+			for fi, f := range files {
+				if !usedTracks[ti] && !usedFiles[fi] && same(f, t) {
+					usedTracks[ti], usedFiles[fi] = true, true
+					matches = append(matches, postprocess.Match{File: fi, Track: ti, By: by})
+				}
+			}
+		}
+	}
+	pair("id", func(f postprocess.LocalFile, t core.SourceTrack) bool {
+		return f.SourceID != "" && f.SourceID == t.ID && f.Source == content.Source
+	})
+	pair("isrc", func(f postprocess.LocalFile, t core.SourceTrack) bool {
+		return f.ISRC != "" && strings.EqualFold(f.ISRC, t.ISRC)
+	})
+
+	var restFiles, restTracks []int
+	for fi := range files {
+		if !usedFiles[fi] {
+			restFiles = append(restFiles, fi)
+		}
+	}
+	for ti := range content.Tracks {
+		if !usedTracks[ti] {
+			restTracks = append(restTracks, ti)
+		}
+	}
+	if len(restFiles) == 0 || len(restTracks) == 0 {
+		return matches
+	}
+	all := ImportTracks(content)
+	subFiles := make([]postprocess.LocalFile, len(restFiles))
+	for i, fi := range restFiles {
+		subFiles[i] = files[fi]
+	}
+	subTracks := make([]postprocess.Track, len(restTracks))
+	for i, ti := range restTracks {
+		subTracks[i] = all[ti]
+	}
+	for _, m := range postprocess.MatchFiles(subFiles, subTracks, mode) {
+		matches = append(matches, postprocess.Match{File: restFiles[m.File], Track: restTracks[m.Track], By: m.By})
+	}
+	return matches
+}
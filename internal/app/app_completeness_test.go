@@ -0,0 +1,40 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/postprocess"
+	"flacidal/internal/textmatch"
+)
+
+func TestCompletenessOf(t *testing.T) {
+	content := &ResolvedContent{URL: "https://tidal.com/browse/album/9", Source: "tidal", Type: "album", Title: "Album", Tracks: []core.SourceTrack{
+		{ID: "1", Title: "One", TrackNumber: 1, Duration: 180},
+		{ID: "2", Title: "Two", TrackNumber: 2, ISRC: "USAAA0000002", Duration: 200},
+		{ID: "3", Title: "Three", TrackNumber: 3, Duration: 240},
+		{ID: "4", Title: "Four", TrackNumber: 4, Duration: 300},
+	}}
+	files := []postprocess.LocalFile{
+		{Path: "/a/01.flac", Source: "tidal", SourceID: "1", Duration: 10 * time.Second},
+		{Path: "/a/02.flac", ISRC: "usaaa0000002"},
+		{Path: "/a/04.flac", Title: "Four", Duration: 301 * time.Second},
+		{Path: "/a/bonus.flac", Source: "qobuz", SourceID: "3", Duration: 30 * time.Second},
+	}
+
+	report := CompletenessOf("/a", content, files, textmatch.Default)
+	if report.Missing != 1 || report.URL != content.URL {
+		t.Fatalf("report = %+v, want 1 missing", report)
+	}
+	want := []struct{ file, by string }{{"/a/01.flac", "id"}, {"/a/02.flac", "isrc"}, {"", ""}, {"/a/04.flac", "duration"}}
+	for i, w := range want {
+		if got := report.Tracks[i]; got.File != w.file || got.By != w.by {
+			t.Errorf("track %d = %+v, want %s by %q", i+1, got, w.file, w.by)
+		}
+	}
+	if len(report.Extra) != 1 || report.Extra[0] != "/a/bonus.flac" {
+		t.Errorf("extra = %v, want the file from another source", report.Extra)
+	}
+}
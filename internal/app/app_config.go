@@ -2,12 +2,17 @@ package app
 
 import (
 	"fmt"
+	"net/url"
 	"os/exec"
 	goruntime "runtime"
 	"strings"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/configdiff"
+	"flacidal/internal/downloads"
+	"flacidal/internal/quality"
 )
 
 // =============================================================================
@@ -19,69 +24,31 @@ func (a *App) GetConfig() *core.Config {
 	return a.config
 }
 
-// SaveConfig saves configuration
-func (a *App) SaveConfig(config core.Config) error {
-	a.config = &config
-	if a.downloadManager != nil {
-		a.downloadManager.SetGenerateM3U8(config.GenerateM3U8)
-		a.downloadManager.SetSkipUnavailable(config.SkipUnavailableTracks)
-		a.downloadManager.SetJellyfin(config.JellyfinEnabled, config.JellyfinURL, config.JellyfinAPIKey)
+// SaveConfig applies config to the running subsystems and saves it. It
+// returns the changed settings, each marked applied live or needing a
+// restart (see ConfigChanges); when applying or saving fails the previous
+// config stays in effect.
+func (a *App) SaveConfig(config core.Config) ([]configdiff.Change, error) {
+	if err := NormalizeQualityConfig(&config); err != nil {
+		return nil, err
 	}
-	if a.downloader != nil {
-		opts := a.downloader.GetOptions()
-		opts.AutoQualityFallback = config.AutoQualityFallback
-		opts.QualityFallbackOrder = config.QualityOrder
-		opts.FirstArtistOnly = config.FirstArtistOnly
-		opts.SkipExisting = config.SkipExisting
-		opts.ExternalLibraryPaths = config.ExternalLibraryPaths
-		opts.ArtistSeparator = config.ArtistSeparator
-		opts.PlaylistSubfolder = config.PlaylistSubfolder
-		if config.DownloadQuality != "" {
-			opts.Quality = config.DownloadQuality
-		}
-		if config.FileNameFormat != "" {
-			opts.FileNameFormat = config.FileNameFormat
-		}
-		opts.OrganizeFolders = config.OrganizeFolders
-		opts.FolderTemplate = config.FolderTemplate
-		opts.EmbedCover = config.EmbedCover
-		opts.SaveCoverFile = config.SaveCoverFile
-		opts.AutoAnalyze = config.AutoAnalyze
-		opts.SaveLyricsFile = config.SaveLyricsFile
-		opts.SaveFolderCover = config.SaveFolderCover
-		a.downloader.SetOptions(opts)
-		// Re-apply endpoints live without restart: override wins outright, else priority prepends to the public pool.
-		if len(config.TidalHifiEndpoints) > 0 {
-			a.downloader.SetEndpoints(config.TidalHifiEndpoints)
-		} else {
-			base := core.GetTidalEndpoints()
-			priority := config.TidalPriorityEndpoints
-			if len(priority) == 0 && config.TidalCustomEndpoint != "" {
-				priority = []string{config.TidalCustomEndpoint}
-			}
-			if len(priority) > 0 {
-				a.downloader.SetEndpoints(append(priority, base...))
-			} else {
-				a.downloader.SetEndpoints(base)
-			}
-		}
+	targets := ConfigTargets{
+		Downloader:      a.downloader,
+		DownloadManager: a.downloadManager,
+		SourceManager:   a.sourceManager,
+		TidalSource:     a.tidalSource,
+		QobuzSource:     a.qobuzSource,
+		Gate:            &a.downloadGate,
 	}
-	if a.qobuzSource != nil {
-		// Re-apply endpoints live without restart: override wins outright, else priority prepends to the public pool.
-		if len(config.QobuzEndpoints) > 0 {
-			a.qobuzSource.SetEndpoints(config.QobuzEndpoints)
-		} else {
-			base := core.DefaultQobuzEndpoints()
-			priority := config.QobuzPriorityEndpoints
-			if len(priority) == 0 && config.QobuzCustomEndpoint != "" {
-				priority = []string{config.QobuzCustomEndpoint}
-			}
-			if len(priority) > 0 {
-				a.qobuzSource.SetEndpoints(append(priority, base...))
-			} else {
-				a.qobuzSource.SetEndpoints(base)
-			}
+	restart, err := ApplyConfig(targets, a.config, &config)
+	if err != nil {
+		return nil, err
+	}
+	if err := core.SaveConfig(&config); err != nil {
+		if a.config != nil {
+			ApplyConfig(targets, &config, a.config) //nolint:errcheck // restoring the config that was running
 		}
+		return nil, err
 	}
 	if a.amazonSource != nil {
 		// Re-apply endpoints live without restart: override wins outright, else priority prepends to the public pool.
@@ -96,25 +63,12 @@ func (a *App) SaveConfig(config core.Config) error {
 			}
 		}
 	}
-	if a.downloadManager != nil {
-		a.downloadManager.SetSourceOrder(config.SourceOrder)
-	}
 	// Apply proxy changes immediately (no restart needed)
 	if a.tidalClient != nil {
 		if err := a.tidalClient.SetProxy(config.ProxyURL); err != nil {
 			a.logBuffer.Warn("Proxy config error (Tidal API): " + err.Error())
 		}
 	}
-	if a.downloader != nil {
-		if err := a.downloader.SetProxy(config.ProxyURL); err != nil {
-			a.logBuffer.Warn("Proxy config error (downloader): " + err.Error())
-		}
-	}
-	if a.qobuzSource != nil {
-		if err := a.qobuzSource.SetProxy(config.ProxyURL); err != nil {
-			a.logBuffer.Warn("Proxy config error (Qobuz): " + err.Error())
-		}
-	}
 	// Re-initialize Soulseek source when credentials or enabled state change
 	sldlPath := config.SoulseekBinaryPath
 	if sldlPath == "" {
@@ -139,7 +93,209 @@ func (a *App) SaveConfig(config core.Config) error {
 		}
 	}
 
-	return core.SaveConfig(&config)
+	changes := ConfigChanges(a.config, &config, restart)
+	a.config = &config
+	return changes, nil
+}
+
+// ConfigChanges reports the settings that differ between old and config,
+// those in restart (see ApplyConfig) as needing a restart and the rest as
+// applied live. Shared by the desktop (Wails) and HTTP server APIs.
+func ConfigChanges(old, config *core.Config, restart []string) []configdiff.Change {
+	keys, err := configdiff.Keys(old, config)
+	if err != nil {
+		keys = nil // core.Config always marshals; report restarts alone
+	}
+	return configdiff.Report(keys, restart)
+}
+
+// DownloaderOptions returns opts updated with config's download settings.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func DownloaderOptions(opts core.DownloadOptions, config *core.Config) core.DownloadOptions {
+	opts.AutoQualityFallback = config.AutoQualityFallback
+	opts.QualityFallbackOrder = config.QualityOrder
+	opts.FirstArtistOnly = config.FirstArtistOnly
+	opts.SkipExisting = config.SkipExisting
+	opts.ExternalLibraryPaths = config.ExternalLibraryPaths
+	opts.ArtistSeparator = config.ArtistSeparator
+	opts.PlaylistSubfolder = config.PlaylistSubfolder
+	if config.DownloadQuality != "" {
+		opts.Quality = config.DownloadQuality
+	}
+	if config.FileNameFormat != "" {
+		opts.FileNameFormat = coreFileNameFormat(config.FileNameFormat)
+	}
+	opts.OrganizeFolders = config.OrganizeFolders
+	opts.FolderTemplate = coreTemplate(config.FolderTemplate)
+	opts.EmbedCover = config.EmbedCover
+	opts.SaveCoverFile = config.SaveCoverFile
+	opts.AutoAnalyze = config.AutoAnalyze
+	opts.SaveLyricsFile = config.SaveLyricsFile
+	opts.SaveFolderCover = config.SaveFolderCover
+	return opts
+}
+
+// TidalEndpoints returns the Tidal HiFi endpoint pool config asks for: the
+// override list wins outright, else priority endpoints (or the legacy
+// single custom endpoint) are prepended to the public pool.
+func TidalEndpoints(config *core.Config) []string {
+	if len(config.TidalHifiEndpoints) > 0 {
+		return config.TidalHifiEndpoints
+	}
+	base := core.GetTidalEndpoints()
+	priority := config.TidalPriorityEndpoints
+	if len(priority) == 0 && config.TidalCustomEndpoint != "" {
+		priority = []string{config.TidalCustomEndpoint}
+	}
+	return append(append([]string(nil), priority...), base...)
+}
+
+// QobuzEndpoints is TidalEndpoints for the Qobuz source.
+func QobuzEndpoints(config *core.Config) []string {
+	if len(config.QobuzEndpoints) > 0 {
+		return config.QobuzEndpoints
+	}
+	base := core.DefaultQobuzEndpoints()
+	priority := config.QobuzPriorityEndpoints
+	if len(priority) == 0 && config.QobuzCustomEndpoint != "" {
+		priority = []string{config.QobuzCustomEndpoint}
+	}
+	return append(append([]string(nil), priority...), base...)
+}
+
+// ConfigTargets are the running subsystems ApplyConfig updates. Nil ones
+// are skipped; Gate holds DownloadManager to ConcurrentDownloads.
+type ConfigTargets struct {
+	Downloader      *core.TidalHifiService
+	DownloadManager *core.DownloadManager
+	SourceManager   *core.SourceManager
+	TidalSource     *core.TidalSource
+	QobuzSource     *core.QobuzSource
+	Gate            *downloads.Gate
+}
+
+// ApplyConfig propagates config to the running subsystems in t, replacing
+// old. It validates first and, if a subsystem rejects a value, re-applies
+// old so nothing is left half-updated. It returns the JSON names of
+// changed settings the subsystems can't pick up live, which take effect
+// on the next start. Shared by the desktop (Wails) and HTTP server APIs.
+func ApplyConfig(t ConfigTargets, old, config *core.Config) ([]string, error) {
+	if err := ValidateProxyURL(config.ProxyURL); err != nil {
+		return nil, configdiff.Field("proxyUrl", err)
+	}
+	if err := t.apply(config); err != nil {
+		if old != nil {
+			t.apply(old) //nolint:errcheck // old was applied successfully before
+		}
+		return nil, err
+	}
+	restart := []string{}
+	if old != nil {
+		// The download manager's Qobuz fallback can be set but not removed
+		if old.QobuzEnabled && !config.QobuzEnabled {
+			restart = append(restart, "qobuzEnabled")
+		}
+		// Without a token there are no credentials to re-apply live
+		if config.QobuzAuthToken == "" && (old.QobuzAppID != config.QobuzAppID || old.QobuzAppSecret != config.QobuzAppSecret) {
+			restart = append(restart, "qobuzAppId")
+		}
+	}
+	return restart, nil
+}
+
+// apply pushes config into each non-nil target.
+func (t ConfigTargets) apply(config *core.Config) error {
+	if t.Downloader != nil {
+		if err := t.Downloader.SetProxy(config.ProxyURL); err != nil {
+			return fmt.Errorf("downloader proxy: %w", err)
+		}
+		t.Downloader.SetOptions(DownloaderOptions(t.Downloader.GetOptions(), config))
+		t.Downloader.SetEndpoints(TidalEndpoints(config))
+	}
+	if t.QobuzSource != nil {
+		if err := t.QobuzSource.SetProxy(config.ProxyURL); err != nil {
+			return fmt.Errorf("qobuz proxy: %w", err)
+		}
+		t.QobuzSource.SetEndpoints(QobuzEndpoints(config))
+		if config.QobuzAuthToken != "" {
+			t.QobuzSource.SetCredentials(config.QobuzAppID, config.QobuzAppSecret, config.QobuzAuthToken)
+		}
+	}
+	if t.TidalSource != nil {
+		t.TidalSource.SetAvailable(config.TidalEnabled)
+	}
+	if t.DownloadManager != nil {
+		t.DownloadManager.SetGenerateM3U8(config.GenerateM3U8)
+		t.DownloadManager.SetSkipUnavailable(config.SkipUnavailableTracks)
+		t.DownloadManager.SetJellyfin(config.JellyfinEnabled, config.JellyfinURL, config.JellyfinAPIKey)
+		t.DownloadManager.SetSourceOrder(config.SourceOrder)
+		if config.QobuzEnabled && t.QobuzSource != nil && t.QobuzSource.IsAvailable() {
+			t.DownloadManager.SetFallbackQobuzSource(t.QobuzSource)
+		}
+	}
+	if t.Gate != nil {
+		t.Gate.SetLimit(DownloadWorkers(config.ConcurrentDownloads))
+	}
+	if t.SourceManager != nil && config.PreferredSource != "" {
+		t.SourceManager.SetPreferredSource(config.PreferredSource)
+	}
+	return nil
+}
+
+// ValidateProxyURL rejects a ProxyURL the downloaders can't use: it must
+// be empty or an http, https or socks5 URL with a host.
+func ValidateProxyURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("proxy URL: unsupported scheme %q (want http, https or socks5)", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy URL: missing host")
+	}
+	return nil
+}
+
+// NormalizeQualityConfig validates cfg's DownloadQuality and QualityOrder
+// and rewrites them in canonical form ("cd" → "LOSSLESS"), so the core
+// downloader never sees an alias it doesn't recognise. Empty values are
+// left empty for core to default. Shared by the desktop (Wails) and HTTP
+// server APIs.
+func NormalizeQualityConfig(cfg *core.Config) error {
+	if cfg.DownloadQuality != "" {
+		q, err := quality.Parse(cfg.DownloadQuality)
+		if err != nil {
+			return configdiff.Field("downloadQuality", fmt.Errorf("download quality: %w", err))
+		}
+		cfg.DownloadQuality = q.String()
+	}
+	if len(cfg.QualityOrder) > 0 {
+		order, err := quality.ParseOrder(cfg.QualityOrder)
+		if err != nil {
+			return configdiff.Field("qualityOrder", fmt.Errorf("quality order: %w", err))
+		}
+		cfg.QualityOrder = quality.Strings(order)
+	}
+	return nil
+}
+
+// logQualityMismatch reports a download delivered at a different quality
+// than requested: a downgrade is a warning, an upgrade just informational.
+func logQualityMismatch(log *core.LogBuffer, requested, got string) {
+	req, reqErr := quality.Parse(requested)
+	q, gotErr := quality.Parse(got)
+	if reqErr == nil && gotErr == nil && q.Compare(req) > 0 {
+		log.Info(fmt.Sprintf("Quality upgrade: requested %s, got %s", requested, got))
+		return
+	}
+	log.Warn(fmt.Sprintf("Quality mismatch: requested %s but got %s", requested, got))
 }
 
 // SetSourceOrder updates the download source priority order live and persists it
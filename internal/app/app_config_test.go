@@ -4,8 +4,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/downloads"
 )
 
 // Characterization tests for the "Config Methods" section of app.go
@@ -41,7 +44,7 @@ func TestSaveConfig(t *testing.T) {
 		SourceOrder:        []string{"tidal"},
 	}
 
-	if err := a.SaveConfig(cfg); err != nil {
+	if _, err := a.SaveConfig(cfg); err != nil {
 		t.Fatalf("SaveConfig() error = %v", err)
 	}
 	if a.config == nil || a.config.Theme != "light" {
@@ -74,7 +77,7 @@ func TestSaveConfig_AmazonEndpointPriority(t *testing.T) {
 	// Priority endpoint set, no override -> self-host prepended before the public pool.
 	cfg := baseCfg
 	cfg.AmazonPriorityEndpoints = []string{"https://my-amazon-proxy.example"}
-	if err := a.SaveConfig(cfg); err != nil {
+	if _, err := a.SaveConfig(cfg); err != nil {
 		t.Fatalf("SaveConfig() error = %v", err)
 	}
 	snap := a.amazonSource.PoolSnapshot()
@@ -86,7 +89,7 @@ func TestSaveConfig_AmazonEndpointPriority(t *testing.T) {
 	cfg = baseCfg
 	cfg.AmazonPriorityEndpoints = []string{"https://my-amazon-proxy.example"}
 	cfg.AmazonProxyEndpoints = []string{"https://override.example"}
-	if err := a.SaveConfig(cfg); err != nil {
+	if _, err := a.SaveConfig(cfg); err != nil {
 		t.Fatalf("SaveConfig() error = %v", err)
 	}
 	snap = a.amazonSource.PoolSnapshot()
@@ -211,3 +214,109 @@ func TestEndpointStatToStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeQualityConfig(t *testing.T) {
+	cfg := core.Config{DownloadQuality: "cd", QualityOrder: []string{"hi-res", "lossless"}}
+	if err := NormalizeQualityConfig(&cfg); err != nil {
+		t.Fatalf("NormalizeQualityConfig() error = %v", err)
+	}
+	if cfg.DownloadQuality != "LOSSLESS" {
+		t.Errorf("DownloadQuality = %q, want LOSSLESS", cfg.DownloadQuality)
+	}
+	if len(cfg.QualityOrder) != 2 || cfg.QualityOrder[0] != "HI_RES" || cfg.QualityOrder[1] != "LOSSLESS" {
+		t.Errorf("QualityOrder = %v, want [HI_RES LOSSLESS]", cfg.QualityOrder)
+	}
+
+	empty := core.Config{}
+	if err := NormalizeQualityConfig(&empty); err != nil || empty.DownloadQuality != "" || empty.QualityOrder != nil {
+		t.Errorf("empty config changed: %+v, %v", empty, err)
+	}
+
+	if err := NormalizeQualityConfig(&core.Config{DownloadQuality: "ULTRA"}); err == nil {
+		t.Error("unknown quality should be rejected")
+	}
+	if err := NormalizeQualityConfig(&core.Config{QualityOrder: []string{"CD", "LOSSLESS"}}); err == nil {
+		t.Error("duplicate quality in order should be rejected")
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	downloader := core.NewTidalHifiService()
+	targets := ConfigTargets{
+		Downloader:      downloader,
+		DownloadManager: core.NewDownloadManager(downloader, 2),
+		SourceManager:   core.NewSourceManager(),
+		TidalSource:     core.NewTidalSource(),
+		Gate:            &downloads.Gate{},
+	}
+	old := &core.Config{DownloadQuality: "LOSSLESS", ConcurrentDownloads: 2, QobuzEnabled: true}
+
+	config := &core.Config{DownloadQuality: "HI_RES", ConcurrentDownloads: 6, FileNameFormat: "{title}"}
+	restart, err := ApplyConfig(targets, old, config)
+	if err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	if opts := downloader.GetOptions(); opts.Quality != "HI_RES" || opts.FileNameFormat != "{title}" {
+		t.Errorf("downloader options not updated: %+v", opts)
+	}
+	if len(restart) != 1 || restart[0] != "qobuzEnabled" {
+		t.Errorf("restart = %v, want [qobuzEnabled]", restart)
+	}
+	// ConcurrentDownloads applies live: a seventh download waits
+	for id := range 6 {
+		targets.Gate.Enter(id)
+	}
+	entered := make(chan struct{})
+	go func() {
+		targets.Gate.Enter(6)
+		close(entered)
+	}()
+	select {
+	case <-entered:
+		t.Error("seventh download started past ConcurrentDownloads")
+	case <-time.After(50 * time.Millisecond):
+	}
+	targets.Gate.Leave(0)
+	<-entered
+
+	bad := &core.Config{DownloadQuality: "HIGH", ProxyURL: "ftp://proxy.example"}
+	if _, err := ApplyConfig(targets, config, bad); err == nil {
+		t.Fatal("ApplyConfig() with ftp proxy: want error, got nil")
+	}
+	if got := downloader.GetOptions().Quality; got != "HI_RES" {
+		t.Errorf("rejected config was applied: Quality = %q", got)
+	}
+}
+
+func TestApplyConfig_NoTargets(t *testing.T) {
+	restart, err := ApplyConfig(ConfigTargets{}, nil, &core.Config{})
+	if err != nil || len(restart) != 0 {
+		t.Errorf("ApplyConfig() = %v, %v; want no restart, no error", restart, err)
+	}
+}
+
+func TestValidateProxyURL(t *testing.T) {
+	for raw, ok := range map[string]bool{
+		"":                         true,
+		"http://127.0.0.1:8080":    true,
+		"socks5://proxy.example:1": true,
+		"ftp://proxy.example":      false,
+		"http://":                  false,
+		"::":                       false,
+	} {
+		if err := ValidateProxyURL(raw); (err == nil) != ok {
+			t.Errorf("ValidateProxyURL(%q) = %v, want ok=%v", raw, err, ok)
+		}
+	}
+}
+
+func TestTidalEndpoints(t *testing.T) {
+	override := &core.Config{TidalHifiEndpoints: []string{"https://only.example"}}
+	if got := TidalEndpoints(override); len(got) != 1 || got[0] != "https://only.example" {
+		t.Errorf("override: TidalEndpoints() = %v", got)
+	}
+	legacy := &core.Config{TidalCustomEndpoint: "https://mine.example"}
+	if got := TidalEndpoints(legacy); len(got) == 0 || got[0] != "https://mine.example" {
+		t.Errorf("custom endpoint not prepended: %v", got)
+	}
+}
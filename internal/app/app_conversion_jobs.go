@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goruntime "runtime"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/convert"
+	"flacidal/internal/jobs"
+	"flacidal/internal/settings"
+)
+
+// ConversionJob is the kind of the jobs StartConversion runs.
+const ConversionJob = "conversion"
+
+// ConversionWorkers is how many ffmpeg processes conversion jobs and
+// ConvertFiles run at once: s.ConversionWorkers when set, else one per
+// two CPU cores (GOMAXPROCS), leaving room for the rest of the app.
+func ConversionWorkers(s settings.Settings) int {
+	if s.ConversionWorkers > 0 {
+		return s.ConversionWorkers
+	}
+	return max(goruntime.GOMAXPROCS(0)/2, 1)
+}
+
+// =============================================================================
+// Conversion Jobs (exposed to frontend)
+// =============================================================================
+
+// StartConversion queues a conversion of files in the background and
+// returns the new job; "conversion-job-progress" events follow it, each
+// carrying the ConversionResult of the file just converted, and
+// "conversion-progress" events report how far along each running file is.
+func (a *App) StartConversion(files []string, format, quality, outputDir string, deleteSource bool) (jobs.Job, error) {
+	opts := core.ConversionOptions{
+		Format:       format,
+		Quality:      quality,
+		OutputDir:    outputDir,
+		DeleteSource: deleteSource,
+	}
+	j, err := StartConversion(&a.conversionJobs, files, opts, a.currentSettings(), func(p convert.Progress) {
+		runtime.EventsEmit(a.ctx, "conversion-progress", p)
+	})
+	if err == nil && a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Queued conversion job %s (%d files to %s)", j.ID, j.Total, format))
+	}
+	return j, err
+}
+
+// GetConversionJob returns a conversion job with its per-file results.
+func (a *App) GetConversionJob(id string) (jobs.Job, error) {
+	j, ok := a.conversionJobs.Get(id)
+	if !ok {
+		return jobs.Job{}, jobs.ErrNotFound
+	}
+	return j, nil
+}
+
+// ListConversionJobs lists the recent conversion jobs, newest first.
+func (a *App) ListConversionJobs() []jobs.Job {
+	return a.conversionJobs.List()
+}
+
+// CancelConversionJob stops a conversion job: the files being converted
+// are abandoned, leaving no partial output, and the rest are skipped.
+func (a *App) CancelConversionJob(id string) (jobs.Job, error) {
+	j, err := a.conversionJobs.Cancel(id)
+	if err == nil && a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Cancelled conversion job %s", id))
+	}
+	return j, err
+}
+
+// logConversionJob logs the outcome of a finished conversion job.
+func (a *App) logConversionJob(ev jobs.Event) {
+	if a.logBuffer == nil || !ev.State.Finished() {
+		return
+	}
+	a.logBuffer.Info(fmt.Sprintf("Conversion job %s %s: %d of %d files converted, %d failed", ev.ID, ev.State, ev.Processed-ev.Failed, ev.Total, ev.Failed))
+}
+
+// StartConversion queues a job on m converting files with opts,
+// ConversionWorkers(s) at a time. Files that would convert to the same
+// path are refused before any starts. progress, if not nil, hears how far
+// along each file is (see ConversionWork). Shared by the desktop (Wails)
+// and HTTP server APIs.
+func StartConversion(m *jobs.Manager, files []string, opts core.ConversionOptions, s settings.Settings, progress func(convert.Progress)) (jobs.Job, error) {
+	if len(files) == 0 {
+		return jobs.Job{}, errors.New("files are required")
+	}
+	if !convert.Supported(opts.Format) {
+		return jobs.Job{}, fmt.Errorf("unsupported format %q", opts.Format)
+	}
+	// Each file is its own conversion, so two writing one path would race
+	if err := convert.CheckOutputs(files, convert.Options{Format: opts.Format, OutputDir: opts.OutputDir}); err != nil {
+		return jobs.Job{}, err
+	}
+	ffmpeg, err := FFmpegPath()
+	if err != nil {
+		return jobs.Job{}, err
+	}
+	m.SetWorkers(ConversionWorkers(s))
+	return m.Start(ConversionJob, files, ConversionWork(ffmpeg, opts, s, progress), jobs.Options{}), nil
+}
+
+// ConversionWork is ConvertAndTag as job work, for one file at a time:
+// each item's result is a core.ConversionResult. A file strict validation
+// rejects, or that doesn't convert, fails. progress gets the file's path
+// and percent only; the job's events tell how the batch is doing.
+func ConversionWork(ffmpeg string, opts core.ConversionOptions, s settings.Settings, progress func(convert.Progress)) jobs.Work {
+	return func(ctx context.Context, path string) (any, error) {
+		if err := CheckStrict(s, path); err != nil {
+			return nil, err
+		}
+		conv := Converter(ctx, ffmpeg, s.FileConflict, 1, func(p convert.Progress) {
+			if progress != nil {
+				progress(convert.Progress{File: p.File, Percent: p.Percent})
+			}
+		})
+		r := ConvertAndTag(ctx, conv, []string{path}, opts)[0]
+		if !r.Success {
+			return nil, errors.New(r.Error)
+		}
+		return r, nil
+	}
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,6 +9,11 @@ import (
 
 	core "github.com/kushiemoon-dev/flacidal-core"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/convert"
+	"flacidal/internal/convtag"
+	"flacidal/internal/naming"
+	"flacidal/internal/silence"
 )
 
 // =============================================================================
@@ -62,10 +68,12 @@ func (a *App) GetConversionFormats() []core.ConversionFormat {
 	return conv.GetFormats()
 }
 
-// ConvertFiles converts files to the specified format
+// ConvertFiles converts files to the specified format, ConversionWorkers
+// at a time, emitting a "conversion-progress" event as ffmpeg works through
+// each
 func (a *App) ConvertFiles(files []string, format, quality, outputDir string, deleteSource bool) []core.ConversionResult {
-	conv := core.GetConverter()
-	if conv == nil {
+	ffmpeg, err := FFmpegPath()
+	if err != nil {
 		results := make([]core.ConversionResult, len(files))
 		for i, f := range files {
 			results[i] = core.ConversionResult{
@@ -83,7 +91,16 @@ func (a *App) ConvertFiles(files []string, format, quality, outputDir string, de
 		DeleteSource: deleteSource,
 	}
 
-	results := conv.ConvertMultiple(files, opts)
+	s := a.currentSettings()
+	ctx := context.Background()
+	conv := Converter(ctx, ffmpeg, s.FileConflict, ConversionWorkers(s), func(p convert.Progress) {
+		runtime.EventsEmit(a.ctx, "conversion-progress", p)
+	})
+	results := StrictResults(s, files, func(files []string) []core.ConversionResult {
+		return ConvertAndTag(ctx, conv, files, opts)
+	}, func(path, reason string) core.ConversionResult {
+		return core.ConversionResult{SourcePath: path, Error: reason}
+	})
 
 	// Log results
 	if a.logBuffer != nil {
@@ -99,6 +116,73 @@ func (a *App) ConvertFiles(files []string, format, quality, outputDir string, de
 	return results
 }
 
+// Converter returns a converter for ConvertAndTag that runs ffmpeg itself,
+// workers files at a time, so that progress, if not nil, hears how far
+// along each file and the whole batch are. An output whose name is taken
+// is named per conflict, never overwritten. Shared by the desktop (Wails)
+// and HTTP server APIs.
+func Converter(ctx context.Context, ffmpeg string, conflict naming.Conflict, workers int, progress func(convert.Progress)) func([]string, core.ConversionOptions) []core.ConversionResult {
+	return func(files []string, opts core.ConversionOptions) []core.ConversionResult {
+		outcomes := convert.Run(ctx, ffmpeg, files, convert.Options{
+			Format:    opts.Format,
+			Quality:   opts.Quality,
+			OutputDir: opts.OutputDir,
+			Conflict:  conflict,
+		}, workers, progress)
+		results := make([]core.ConversionResult, len(outcomes))
+		for i, o := range outcomes {
+			r := core.ConversionResult{SourcePath: o.Source, OutputPath: o.Output, Success: o.Err == nil}
+			if o.Err != nil {
+				r.Error = o.Err.Error()
+			}
+			if fi, err := os.Stat(o.Source); err == nil {
+				r.SourceSize = fi.Size()
+			}
+			if fi, err := os.Stat(o.Output); err == nil && r.Success {
+				r.OutputSize = fi.Size()
+			}
+			results[i] = r
+		}
+		return results
+	}
+}
+
+// ConvertAndTag converts files with convert, then copies each FLAC
+// source's tags and cover onto its output, which ffmpeg doesn't reliably
+// do itself; other sources keep the tags and cover ffmpeg carried across.
+// With opts.DeleteSource the sources are deleted only once tagged. A file
+// that converted but couldn't be tagged or deleted stays successful, with
+// the reason in its Error. Shared by the desktop (Wails) and HTTP server
+// APIs.
+func ConvertAndTag(ctx context.Context, convert func([]string, core.ConversionOptions) []core.ConversionResult, files []string, opts core.ConversionOptions) []core.ConversionResult {
+	deleteSource := opts.DeleteSource
+	opts.DeleteSource = false
+	results := convert(files, opts)
+	ffmpeg, ffmpegErr := FFmpegPath()
+	for i := range results {
+		r := &results[i]
+		if !r.Success {
+			continue
+		}
+		var err error
+		if convtag.ContainerOf(r.OutputPath) != "" && strings.EqualFold(filepath.Ext(r.SourcePath), ".flac") {
+			if err = ffmpegErr; err == nil {
+				err = convtag.Tag(ctx, silence.ExecRunner, ffmpeg, r.SourcePath, r.OutputPath)
+			}
+		}
+		if err != nil {
+			r.Error = "tags not copied: " + err.Error()
+			continue // keep the source, the only copy of the tags
+		}
+		if deleteSource {
+			if err := os.Remove(r.SourcePath); err != nil {
+				r.Error = "source not deleted: " + err.Error()
+			}
+		}
+	}
+	return results
+}
+
 // SelectFolderForConversion opens a directory dialog and returns paths of FLAC files within it
 func (a *App) SelectFolderForConversion() ([]string, error) {
 	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
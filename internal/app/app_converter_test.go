@@ -1,6 +1,10 @@
 package app
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
@@ -81,3 +85,49 @@ func TestConvertFolder_NoFlacFiles(t *testing.T) {
 		t.Errorf("ConvertFolder() on an empty folder = %v, want nil", got)
 	}
 }
+
+func TestConvertAndTag(t *testing.T) {
+	dir := t.TempDir()
+	wav, mp3 := filepath.Join(dir, "a.flac"), filepath.Join(dir, "b.flac")
+	writeTestFLAC(t, wav, map[string]string{"TITLE": "A"}, nil, 0)
+	writeTestFLAC(t, mp3, map[string]string{"TITLE": "B"}, nil, 0)
+	convert := func(files []string, opts core.ConversionOptions) []core.ConversionResult {
+		if opts.DeleteSource {
+			t.Error("the converter was asked to delete sources before tagging")
+		}
+		results := make([]core.ConversionResult, len(files))
+		for i, f := range files {
+			out := strings.TrimSuffix(f, ".flac") + "." + opts.Format
+			os.WriteFile(out, nil, 0644)
+			results[i] = core.ConversionResult{SourcePath: f, OutputPath: out, Success: true}
+		}
+		return results
+	}
+
+	got := ConvertAndTag(context.Background(), convert, []string{wav}, core.ConversionOptions{Format: "wav", DeleteSource: true})
+	if !got[0].Success || got[0].Error != "" {
+		t.Errorf("untaggable output: %+v", got[0])
+	}
+	if _, err := os.Stat(wav); !os.IsNotExist(err) {
+		t.Errorf("source kept after converting to WAV: %v", err)
+	}
+
+	// Only FLAC sources are retagged; ffmpeg carried the tags of the rest
+	ogg := filepath.Join(dir, "c.ogg")
+	os.WriteFile(ogg, nil, 0644)
+	got = ConvertAndTag(context.Background(), convert, []string{ogg}, core.ConversionOptions{Format: "mp3"})
+	if !got[0].Success || got[0].Error != "" {
+		t.Errorf("non-FLAC source: %+v", got[0])
+	}
+
+	if _, err := FFmpegPath(); err == nil {
+		t.Skip("FFmpeg is available on this machine; the 'untagged' branch isn't reachable here")
+	}
+	got = ConvertAndTag(context.Background(), convert, []string{mp3}, core.ConversionOptions{Format: "mp3", DeleteSource: true})
+	if !got[0].Success || !strings.HasPrefix(got[0].Error, "tags not copied") {
+		t.Errorf("without FFmpeg: %+v", got[0])
+	}
+	if _, err := os.Stat(mp3); err != nil {
+		t.Errorf("source deleted though its tags weren't copied: %v", err)
+	}
+}
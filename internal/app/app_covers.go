@@ -0,0 +1,121 @@
+package app
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/coverstore"
+)
+
+// errNoCoverStore is returned when the cover store couldn't be opened.
+var errNoCoverStore = errors.New("cover store unavailable")
+
+// errNoCoverArt is returned for files without an embedded picture.
+var errNoCoverArt = errors.New("no cover art found")
+
+// FileCoverArt returns the full-size cover of the FLAC at path as base64
+// data and its MIME type. Shared by the desktop (Wails) and HTTP server
+// APIs.
+func FileCoverArt(path string) (map[string]string, error) {
+	pic, err := coverstore.ReadCover(path)
+	if errors.Is(err, coverstore.ErrNotFound) {
+		return nil, errNoCoverArt
+	}
+	if err != nil {
+		return nil, err
+	}
+	mimeType := pic.MIME
+	if mimeType == "" {
+		mimeType = http.DetectContentType(pic.Data)
+	}
+	return map[string]string{
+		"data":     base64.StdEncoding.EncodeToString(pic.Data),
+		"mimeType": mimeType,
+	}, nil
+}
+
+// FileThumbnail returns a thumbnail of the FLAC at path's cover, at most
+// size pixels per side, its MIME type and the cover's hash (see
+// coverstore.Store.FileThumbnail). Shared by the desktop (Wails) and HTTP
+// server APIs.
+func FileThumbnail(store *coverstore.Store, path string, size int) ([]byte, string, string, error) {
+	if store == nil {
+		return nil, "", "", errNoCoverStore
+	}
+	data, mimeType, hash, err := store.FileThumbnail(path, size)
+	if errors.Is(err, coverstore.ErrNotFound) {
+		return nil, "", "", errNoCoverArt
+	}
+	return data, mimeType, hash, err
+}
+
+// LibraryCovers stores the covers of the FLACs in folder and sums them up
+// (see coverstore.Store.Library). Shared by the desktop (Wails) and HTTP
+// server APIs.
+func LibraryCovers(store *coverstore.Store, folder string) (coverstore.Library, error) {
+	if store == nil {
+		return coverstore.Library{}, errNoCoverStore
+	}
+	if folder == "" {
+		return coverstore.Library{Covers: []coverstore.Cover{}}, nil
+	}
+	files, err := core.ListFLACFiles(folder)
+	if err != nil {
+		return coverstore.Library{}, err
+	}
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	return store.Library(paths), nil
+}
+
+// CoverThumbnail returns the stored cover hash scaled to size (see
+// coverstore.Store.Thumbnail) as base64 data and its MIME type, like
+// App.GetFileCoverArt. Shared by the desktop (Wails) and HTTP server APIs.
+func CoverThumbnail(store *coverstore.Store, hash string, size int) (map[string]string, error) {
+	if store == nil {
+		return nil, errNoCoverStore
+	}
+	data, mimeType, err := store.Thumbnail(hash, size)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"data":     base64.StdEncoding.EncodeToString(data),
+		"mimeType": mimeType,
+	}, nil
+}
+
+// =============================================================================
+// Cover Store Methods (exposed to frontend)
+// =============================================================================
+
+// GetLibraryCovers lists the distinct covers in the download folder with
+// their total embedded and deduplicated sizes.
+func (a *App) GetLibraryCovers() (coverstore.Library, error) {
+	return LibraryCovers(a.covers, a.GetDownloadFolder())
+}
+
+// GetFileThumbnail returns a thumbnail of the FLAC at path's cover, at most
+// size pixels per side (0 for the default), for list views that would
+// otherwise load every full-size cover.
+func (a *App) GetFileThumbnail(path string, size int) (map[string]string, error) {
+	data, mimeType, _, err := FileThumbnail(a.covers, path, size)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"data":     base64.StdEncoding.EncodeToString(data),
+		"mimeType": mimeType,
+	}, nil
+}
+
+// GetCoverThumbnail returns a thumbnail of the stored cover hash, at most
+// size pixels per side (0 for the default).
+func (a *App) GetCoverThumbnail(hash string, size int) (map[string]string, error) {
+	return CoverThumbnail(a.covers, hash, size)
+}
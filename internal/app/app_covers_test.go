@@ -0,0 +1,35 @@
+package app
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"flacidal/internal/coverstore"
+)
+
+func TestCoverThumbnail(t *testing.T) {
+	if _, err := CoverThumbnail(nil, "", 0); err == nil {
+		t.Error("no store: want error")
+	}
+	store, err := coverstore.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gif := []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+	hash, err := store.Put(gif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumb, err := CoverThumbnail(store, hash, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := base64.StdEncoding.DecodeString(thumb["data"]); string(data) != string(gif) || thumb["mimeType"] != "image/gif" {
+		t.Errorf("thumbnail = %v", thumb)
+	}
+
+	lib, err := LibraryCovers(store, "")
+	if err != nil || lib.Files != 0 || lib.Covers == nil {
+		t.Errorf("LibraryCovers without a folder = %+v, %v", lib, err)
+	}
+}
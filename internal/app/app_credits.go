@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/credits"
+	"flacidal/internal/flacmeta"
+)
+
+// =============================================================================
+// Credits (exposed to frontend)
+// =============================================================================
+
+// GetAlbumCredits returns the credits of source's album id: producers,
+// engineers and musicians, track by track and by role. They are fetched
+// once and then read from the credits store, unless refresh is set.
+func (a *App) GetAlbumCredits(source, albumID string, refresh bool) (*credits.Album, error) {
+	config := a.config
+	if config == nil {
+		config = &core.Config{}
+	}
+	return AlbumCredits(a.credits, CreditFetchers(config), source, albumID, refresh)
+}
+
+// CreditFetchers returns the credits fetchers of the services publishing
+// credits, by source name, with config's credentials; a service whose
+// credentials are unset fails its lookups. Shared by the desktop (Wails)
+// and HTTP server APIs.
+func CreditFetchers(config *core.Config) map[string]credits.Fetcher {
+	return map[string]credits.Fetcher{
+		"tidal": &credits.Tidal{Token: config.TidalClientID},
+		"qobuz": &credits.Qobuz{AppID: config.QobuzAppID},
+	}
+}
+
+// AlbumCredits returns the credits of source's album id from store, or
+// fetches them through fetchers and stores them when store has none or
+// refresh is set. Shared by the desktop (Wails) and HTTP server APIs.
+func AlbumCredits(store *credits.Store, fetchers map[string]credits.Fetcher, source, albumID string, refresh bool) (*credits.Album, error) {
+	if albumID == "" {
+		return nil, fmt.Errorf("no album specified")
+	}
+	if !refresh {
+		if album, err := store.Get(source, albumID); err != nil || album != nil {
+			return album, err
+		}
+	}
+	f, ok := fetchers[source]
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't publish credits", source)
+	}
+	album, err := f.Album(albumID)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Put(album); err != nil {
+		return nil, err
+	}
+	return album, nil
+}
+
+// WritePerformers looks the credits of the track queued as trackID up
+// through f and writes them to the FLAC at path as PERFORMER tags,
+// replacing any it has. Tracks without credits are left alone.
+func WritePerformers(f credits.Fetcher, path, trackID string) error {
+	cs, err := f.Track(trackID)
+	if err != nil {
+		return err
+	}
+	performers := credits.Performers(cs)
+	if len(performers) == 0 {
+		return nil
+	}
+	file, err := flacmeta.Read(path)
+	if err != nil {
+		return err
+	}
+	c, err := file.Comments()
+	if err != nil {
+		return err
+	}
+	c.Set("PERFORMER", performers...)
+	file.SetComments(c)
+	return file.Save()
+}
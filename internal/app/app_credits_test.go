@@ -0,0 +1,52 @@
+package app
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"flacidal/internal/credits"
+	"flacidal/internal/flacmeta"
+)
+
+// fakeCredits is a credits.Fetcher counting its album lookups.
+type fakeCredits struct {
+	albums int
+	track  []credits.Credit
+}
+
+func (f *fakeCredits) Album(id string) (*credits.Album, error) {
+	f.albums++
+	return &credits.Album{Source: "tidal", ID: id}, nil
+}
+
+func (f *fakeCredits) Track(string) ([]credits.Credit, error) { return f.track, nil }
+
+func TestAlbumCredits(t *testing.T) {
+	f := &fakeCredits{}
+	fetchers := map[string]credits.Fetcher{"tidal": f}
+	album, err := AlbumCredits(nil, fetchers, "tidal", "9", false)
+	if err != nil || album.ID != "9" || f.albums != 1 {
+		t.Fatalf("AlbumCredits = %+v, %v after %d lookups", album, err, f.albums)
+	}
+	if _, err := AlbumCredits(nil, fetchers, "deezer", "9", false); err == nil {
+		t.Error("no error for a service without credits")
+	}
+}
+
+func TestWritePerformers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path, map[string]string{"PERFORMER": "Old (Bass)"}, nil, 16)
+	f := &fakeCredits{track: []credits.Credit{{Name: "Artist", Role: "Main Artist"}, {Name: "Jane", Role: "Producer"}}}
+	if err := WritePerformers(f, path, "1"); err != nil {
+		t.Fatal(err)
+	}
+	file, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := file.Comments()
+	if got := c.GetAll("PERFORMER"); !reflect.DeepEqual(got, []string{"Jane (Producer)"}) {
+		t.Errorf("PERFORMER = %q", got)
+	}
+}
@@ -0,0 +1,243 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/doctor"
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/library"
+	"flacidal/internal/quality"
+	"flacidal/internal/settings"
+)
+
+// DoctorTrackID is the Tidal track the doctor's test download fetches
+// unless told another.
+const DoctorTrackID = 251380837
+
+// DoctorEnv is what Doctor checks.
+type DoctorEnv struct {
+	Config     *core.Config
+	Settings   settings.Settings
+	DataDir    string
+	Downloader *core.TidalHifiService
+	TrackID    int // the test download's Tidal track; 0 skips it
+}
+
+// =============================================================================
+// Doctor (exposed to frontend)
+// =============================================================================
+
+// RunDoctor runs the self-check, with the test download of DoctorTrackID
+// when download is set.
+func (a *App) RunDoctor(download bool) doctor.Report {
+	env := DoctorEnv{
+		Config:     a.config,
+		Settings:   a.currentSettings(),
+		DataDir:    core.GetDataDir(),
+		Downloader: a.downloader,
+	}
+	if download {
+		env.TrackID = DoctorTrackID
+	}
+	return Doctor(a.ctx, env)
+}
+
+// Doctor runs the self-check of env: config, storage, databases, ffmpeg,
+// the sources' endpoints and proxy, a test download and a tag round-trip.
+// Shared by the desktop (Wails) and HTTP server APIs and the server's
+// doctor command.
+func Doctor(ctx context.Context, env DoctorEnv) doctor.Report {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return doctor.Run(ctx, DoctorChecks(env))
+}
+
+// DoctorChecks returns the checks Doctor runs.
+func DoctorChecks(env DoctorEnv) []doctor.Check {
+	config := env.Config
+	if config == nil {
+		config = &core.Config{}
+	}
+	downloadFolder := config.DownloadFolder
+	if downloadFolder == "" {
+		downloadFolder = core.GetDefaultDownloadFolder()
+	}
+	return []doctor.Check{
+		{Name: "Config", Run: func(context.Context) (string, error) {
+			return checkConfig(env.Config, env.Settings)
+		}},
+		{Name: "Data folder", Run: func(context.Context) (string, error) {
+			return doctor.Writable(env.DataDir)
+		}},
+		{Name: "Download folder", Run: func(context.Context) (string, error) {
+			return doctor.Writable(downloadFolder)
+		}},
+		{Name: "Databases", Run: func(ctx context.Context) (string, error) {
+			return doctor.Databases(ctx, library.Driver, env.DataDir)
+		}},
+		{Name: "ffmpeg", Run: func(context.Context) (string, error) {
+			if !core.IsConverterAvailable() {
+				return "", doctor.Warning("not found; conversion and the features built on it are unavailable")
+			}
+			version, _ := core.GetFFmpegInfo()["version"].(string)
+			return version, nil
+		}},
+		{Name: "Tidal endpoints", Run: func(ctx context.Context) (string, error) {
+			return doctor.Reachable(ctx, nil, TidalEndpoints(config))
+		}},
+		{Name: "Qobuz endpoints", Run: func(ctx context.Context) (string, error) {
+			if !config.QobuzEnabled {
+				return "", doctor.Skipped("Qobuz is disabled")
+			}
+			return doctor.Reachable(ctx, nil, QobuzEndpoints(config))
+		}},
+		{Name: "Proxy", Run: func(ctx context.Context) (string, error) {
+			if config.ProxyURL == "" {
+				return "", doctor.Skipped("no proxy configured")
+			}
+			return doctor.Dial(ctx, config.ProxyURL)
+		}},
+		{Name: "Download pool", Run: func(context.Context) (string, error) {
+			return checkPool(env.Downloader)
+		}},
+		{Name: "Test download", Run: func(ctx context.Context) (string, error) {
+			return testDownload(ctx, env.Config, env.TrackID)
+		}},
+		{Name: "Tag round-trip", Run: func(context.Context) (string, error) {
+			return doctor.TagRoundTrip(os.TempDir())
+		}},
+	}
+}
+
+// checkConfig reports the first problem with config and s.
+func checkConfig(config *core.Config, s settings.Settings) (string, error) {
+	if config == nil {
+		return "", fmt.Errorf("no config loaded")
+	}
+	if err := ValidateProxyURL(config.ProxyURL); err != nil {
+		return "", err
+	}
+	if err := s.Validate(); err != nil {
+		return "", fmt.Errorf("settings: %w", err)
+	}
+	if _, err := os.Stat(core.GetConfigPath()); os.IsNotExist(err) {
+		return "", doctor.Warning("no config file at %s; running on defaults", core.GetConfigPath())
+	}
+	return core.GetConfigPath(), nil
+}
+
+// checkPool reports the health of the downloader's endpoint pool, as
+// observed by past downloads.
+func checkPool(downloader *core.TidalHifiService) (string, error) {
+	if downloader == nil {
+		return "", doctor.Skipped("no downloader")
+	}
+	snaps := downloader.PoolSnapshot()
+	switch poolSnapshotStatus(snaps) {
+	case "untested":
+		return "no downloads yet", nil
+	case "dead":
+		return "", fmt.Errorf("all %d endpoints are failing", len(snaps))
+	case "degraded":
+		return "", doctor.Warning("some of %d endpoints are failing", len(snaps))
+	}
+	return fmt.Sprintf("%d endpoints healthy", len(snaps)), nil
+}
+
+// testDownload downloads Tidal track trackID at the lowest quality into a
+// temporary folder, checks the result is audio and removes it. It uses its
+// own downloader set up from config, so the test neither changes the
+// running downloader's options nor waits behind its downloads.
+// Once ctx is done it stops waiting; the download finishes and is removed
+// in the background, as core's downloader can't be interrupted.
+func testDownload(ctx context.Context, config *core.Config, trackID int) (string, error) {
+	if trackID == 0 {
+		return "", doctor.Skipped("not requested")
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	probe := core.NewTidalHifiService()
+	if config != nil {
+		if err := (ConfigTargets{Downloader: probe}).apply(config); err != nil {
+			return "", err
+		}
+	}
+	probe.SetOptions(testDownloadOptions(probe.GetOptions()))
+
+	tmp, err := os.MkdirTemp("", "flacidal-doctor-")
+	if err != nil {
+		return "", err
+	}
+	type outcome struct {
+		res *core.DownloadResult
+		err error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		res, err := probe.DownloadTrack(trackID, tmp, "", "", "", nil)
+		done <- outcome{res, err}
+	}()
+	var o outcome
+	select {
+	case <-ctx.Done():
+		go func() {
+			<-done
+			os.RemoveAll(tmp)
+		}()
+		return "", ctx.Err()
+	case o = <-done:
+	}
+	defer os.RemoveAll(tmp)
+	if o.err != nil {
+		return "", o.err
+	}
+	if !o.res.Success {
+		return "", fmt.Errorf("track %d: %s", trackID, o.res.Error)
+	}
+	fi, err := os.Stat(o.res.FilePath)
+	if err != nil {
+		return "", err
+	}
+	if fi.Size() == 0 {
+		return "", fmt.Errorf("track %d: %s is empty", trackID, filepath.Base(o.res.FilePath))
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(o.res.FilePath)), ".")
+	if format == "flac" {
+		si, err := flacmeta.ReadStreamInfo(o.res.FilePath)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", filepath.Base(o.res.FilePath), err)
+		}
+		format = fmt.Sprintf("%d-bit/%g kHz", si.BitDepth, float64(si.SampleRate)/1000)
+	}
+	return fmt.Sprintf("track %d: %s, %.1f MB in %s", trackID, format,
+		float64(fi.Size())/(1<<20), time.Since(start).Round(100*time.Millisecond)), nil
+}
+
+// testDownloadOptions returns opts for the doctor's test download: the
+// lowest quality with no fallback, and nothing saved beside the file or
+// skipped because the library has it.
+func testDownloadOptions(opts core.DownloadOptions) core.DownloadOptions {
+	opts.Quality = quality.High.String()
+	opts.AutoQualityFallback = false
+	opts.QualityFallbackOrder = nil
+	opts.SkipExisting = false
+	opts.ExternalLibraryPaths = nil
+	opts.OrganizeFolders = false
+	opts.PlaylistSubfolder = false
+	opts.EmbedCover = false
+	opts.SaveCoverFile = false
+	opts.SaveFolderCover = false
+	opts.SaveLyricsFile = false
+	opts.AutoAnalyze = false
+	return opts
+}
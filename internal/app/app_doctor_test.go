@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/doctor"
+	"flacidal/internal/settings"
+)
+
+func TestCheckConfig(t *testing.T) {
+	if _, err := checkConfig(nil, settings.Settings{}); err == nil {
+		t.Error("nil config passed")
+	}
+	if _, err := checkConfig(&core.Config{ProxyURL: "ftp://proxy"}, settings.Settings{}); err == nil {
+		t.Error("ftp proxy passed")
+	}
+	if _, err := checkConfig(&core.Config{}, settings.Settings{MaxPathLength: -1}); err == nil {
+		t.Error("negative maxPathLength passed")
+	}
+}
+
+func TestDoctorSkipsUnrequested(t *testing.T) {
+	checks := DoctorChecks(DoctorEnv{Config: &core.Config{}, DataDir: t.TempDir()})
+	var picked []doctor.Check
+	for _, c := range checks {
+		switch c.Name {
+		case "Qobuz endpoints", "Proxy", "Test download", "Data folder":
+			picked = append(picked, c)
+		}
+	}
+	r := doctor.Run(context.Background(), picked)
+	want := map[string]doctor.Status{
+		"Data folder":     doctor.Pass,
+		"Qobuz endpoints": doctor.Skip,
+		"Proxy":           doctor.Skip,
+		"Test download":   doctor.Skip,
+	}
+	for _, res := range r.Results {
+		if res.Status != want[res.Name] {
+			t.Errorf("%s: %s (%s), want %s", res.Name, res.Status, res.Detail, want[res.Name])
+		}
+	}
+}
+
+func TestTestDownloadHonoursContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := testDownload(ctx, &core.Config{}, DoctorTrackID); err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestTestDownloadOptions(t *testing.T) {
+	opts := testDownloadOptions(core.DownloadOptions{Quality: "HI_RES", AutoQualityFallback: true, SkipExisting: true, SaveLyricsFile: true})
+	if opts.Quality != "HIGH" || opts.AutoQualityFallback || opts.SkipExisting || opts.SaveLyricsFile {
+		t.Errorf("options = %+v", opts)
+	}
+}
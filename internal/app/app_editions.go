@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"flacidal/internal/musicbrainz"
+	"flacidal/internal/settings"
+)
+
+// =============================================================================
+// Album Editions (exposed to frontend)
+// =============================================================================
+
+// GetAlbumEditions lists the regional and other editions of album by
+// artist, with their country, track count and barcode, in the order of
+// Settings.EditionCountries.
+func (a *App) GetAlbumEditions(artist, album string) ([]musicbrainz.Edition, error) {
+	return AlbumEditions(a.ctx, a.currentSettings(), artist, album)
+}
+
+// GetEditionURL returns the Deezer URL of the edition with barcode, which
+// FetchContentFromURL then resolves like any pasted link.
+func (a *App) GetEditionURL(barcode string) (string, error) {
+	return EditionURL(barcode)
+}
+
+// AlbumEditions looks album's editions up on MusicBrainz and orders them
+// by s.EditionCountries (see musicbrainz.SortEditions). An album
+// MusicBrainz doesn't know has no editions. Shared by the desktop (Wails)
+// and HTTP server APIs.
+func AlbumEditions(ctx context.Context, s settings.Settings, artist, album string) ([]musicbrainz.Edition, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	editions, err := musicbrainz.Default.Editions(ctx, artist, album)
+	if errors.Is(err, musicbrainz.ErrNoMatch) {
+		return []musicbrainz.Edition{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	musicbrainz.SortEditions(editions, s.EditionCountries)
+	return editions, nil
+}
+
+// deezerAPI is the public Deezer API EditionURL looks barcodes up on.
+var deezerAPI = "https://api.deezer.com"
+
+// EditionURL finds the album with barcode (its UPC or EAN) on Deezer, which
+// indexes albums by barcode, and returns its URL. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func EditionURL(barcode string) (string, error) {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return "", fmt.Errorf("barcode is required")
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(deezerAPI + "/album/upc:" + url.PathEscape(barcode))
+	if err != nil {
+		return "", fmt.Errorf("deezer lookup: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var album struct {
+		ID    int64 `json:"id"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&album); err != nil {
+		return "", fmt.Errorf("deezer lookup: %w", err)
+	}
+	if album.Error != nil || album.ID == 0 {
+		return "", fmt.Errorf("no album with barcode %s on Deezer", barcode)
+	}
+	return fmt.Sprintf("https://www.deezer.com/album/%d", album.ID), nil
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEditionURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/album/upc:0602537" {
+			w.Write([]byte(`{"id": 302127, "title": "Album"}`))
+			return
+		}
+		w.Write([]byte(`{"error": {"type": "DataException", "message": "no data", "code": 800}}`))
+	}))
+	defer srv.Close()
+	old := deezerAPI
+	deezerAPI = srv.URL
+	defer func() { deezerAPI = old }()
+
+	if got, err := EditionURL(" 0602537 "); err != nil || got != "https://www.deezer.com/album/302127" {
+		t.Errorf("EditionURL() = %q, %v", got, err)
+	}
+	if _, err := EditionURL("123"); err == nil {
+		t.Error("EditionURL() of an unknown barcode: want error, got nil")
+	}
+	if _, err := EditionURL(""); err == nil {
+		t.Error("EditionURL(\"\"): want error, got nil")
+	}
+}
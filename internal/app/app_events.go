@@ -0,0 +1,187 @@
+package app
+
+import (
+	"fmt"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/downloads"
+	"flacidal/internal/logging"
+)
+
+// statusCooldown is the Status of the event published when the queue is
+// paused because every Tidal endpoint is cooling down (TrackID -1).
+const statusCooldown = "cooldown"
+
+// =============================================================================
+// Download Events
+// =============================================================================
+
+// handleDownloadProgress is the download manager's progress callback. A
+// track about to download waits for its turn at a.downloadGate first,
+// unless its job is cancelled meanwhile, and a completed download strict
+// validation rejects is reported as failed. It records the job's state and
+// speed at once, then finishes the file and updates history before
+// publishing the event, so every listener sees the final path and state.
+// A completed download is finished on a.finisher, leaving the worker free
+// for the next track.
+func (a *App) handleDownloadProgress(trackID int, status string, result *core.DownloadResult) {
+	status = GateDownload(&a.downloadGate, trackID, status)
+	status = RejectBroken(a.currentSettings(), status, result)
+	// Record the job first, so its time and speed are the download's alone
+	job, err := a.jobs.Record(trackID, status)
+	if err != nil {
+		a.logger(logging.Downloads).Warn("Download state", "err", err)
+	}
+	if err := a.timings.Record(job); err != nil {
+		a.logger(logging.Downloads).Warn("Saving download timings", "err", err)
+	}
+	RecordThroughput(&a.throughput, job, status, result)
+	if status == "completed" {
+		a.finisher.Go(func() { a.finishDownload(trackID, status, result) })
+		return
+	}
+	a.finishDownload(trackID, status, result)
+}
+
+// finishDownload runs the post-download steps for a progress event, updates
+// the history and publishes the event.
+func (a *App) finishDownload(trackID int, status string, result *core.DownloadResult) {
+	// Finish the file first so everything below reports its final path
+	if err := FinishDownload(&a.postTracks, a.postOptions(), trackID, status, result); err != nil {
+		a.logBuffer.Warn(fmt.Sprintf("Post-download processing failed for track %d: %v", trackID, err))
+	}
+	if status == "completed" && result != nil {
+		if err := LinkDownload(a.library, a.libraryRoots(), trackID, result.FilePath); err != nil {
+			a.logBuffer.Warn(fmt.Sprintf("Failed to index track %d: %v", trackID, err))
+		}
+	}
+	if err := a.batches.Finish(a.db, trackID, status); err != nil {
+		a.logBuffer.Warn(fmt.Sprintf("Failed to update download history: %v", err))
+	}
+
+	a.downloadEvents.Publish(core.DownloadEvent{TrackID: trackID, Status: status, Result: result})
+
+	if status == "error" {
+		a.pauseOnCooldown()
+	}
+}
+
+// KeepDownloadEvent reports whether ev must reach every listener even one
+// that has fallen behind: a finished download's, which settles the
+// track's state, or a cooldown. Shared by the desktop (Wails) and HTTP
+// server APIs.
+func KeepDownloadEvent(ev core.DownloadEvent) bool {
+	return downloads.State(ev.Status).Terminal() || ev.Status == statusCooldown
+}
+
+// pauseOnCooldown pauses the queue when every Tidal endpoint is in
+// cooldown and the AutoStopOnCooldown option is on, publishing a cooldown
+// event with the shortest remaining wait.
+func (a *App) pauseOnCooldown() {
+	if a.config == nil || !a.config.AutoStopOnCooldown || a.downloader.HasHealthyEndpoints() {
+		return
+	}
+	if !a.downloadManager.PauseQueue() {
+		return
+	}
+	a.logBuffer.Warn("All Tidal endpoints in cooldown — queue paused")
+	// Find the minimum cooldown across all dead endpoints
+	minCooldown := 0
+	for _, stat := range a.downloader.PoolSnapshot() {
+		if stat.CooldownSecs > 0 && (minCooldown == 0 || stat.CooldownSecs < minCooldown) {
+			minCooldown = stat.CooldownSecs
+		}
+	}
+	a.downloadEvents.Publish(core.DownloadEvent{TrackID: -1, Status: statusCooldown, Result: &core.DownloadResult{
+		Error: fmt.Sprintf("all endpoints in cooldown, resuming in %ds", minCooldown),
+	}})
+}
+
+// emitDownloadEvents forwards a batch of download events to the frontend
+// at the configured EventVerbosity. Cooldown events are always sent on
+// their own as "endpoint-cooldown"; with verbosity "all" every progress
+// event is its own "download-progress", otherwise the coalesced batch goes
+// out as one "download-progress-batch" holding the same payloads.
+func (a *App) emitDownloadEvents(evs []core.DownloadEvent) {
+	progress := make([]core.DownloadEvent, 0, len(evs))
+	for _, ev := range evs {
+		if ev.Status == statusCooldown {
+			runtime.EventsEmit(a.ctx, "endpoint-cooldown", a.progressPayload(ev))
+			continue
+		}
+		progress = append(progress, ev)
+	}
+	v := a.currentSettings().EventVerbosity
+	progress = downloads.Coalesce(progress, v, func(ev core.DownloadEvent) (int, string) {
+		return ev.TrackID, ev.Status
+	})
+	if v == downloads.Every {
+		for _, ev := range progress {
+			runtime.EventsEmit(a.ctx, "download-progress", a.progressPayload(ev))
+		}
+		return
+	}
+	if len(progress) == 0 {
+		return
+	}
+	payloads := make([]map[string]interface{}, len(progress))
+	for i, ev := range progress {
+		payloads[i] = a.progressPayload(ev)
+	}
+	runtime.EventsEmit(a.ctx, "download-progress-batch", payloads)
+}
+
+// progressPayload is the frontend's view of a download event: the event
+// plus the track's job, its transfer rate and the queue's.
+func (a *App) progressPayload(ev core.DownloadEvent) map[string]interface{} {
+	var job *downloads.Job
+	if j, ok := a.jobs.Job(ev.TrackID); ok {
+		job = &j
+	}
+	bytesPerSec, _ := a.throughput.Track(ev.TrackID)
+	return map[string]interface{}{
+		"trackId":     ev.TrackID,
+		"status":      ev.Status,
+		"result":      ev.Result,
+		"job":         job,
+		"bytesPerSec": bytesPerSec,
+		"throughput":  QueueRate(&a.throughput, a.downloadManager),
+	}
+}
+
+// logDownloadEvent writes a download event to the Terminal log.
+func (a *App) logDownloadEvent(ev core.DownloadEvent) {
+	if a.logBuffer == nil {
+		return
+	}
+	dlog := a.logger(logging.Downloads)
+	result := ev.Result
+	switch ev.Status {
+	case "queued":
+		dlog.Info(fmt.Sprintf("Track %d added to queue", ev.TrackID))
+	case "downloading":
+		dlog.Info(fmt.Sprintf("Downloading track %d...", ev.TrackID))
+	case "completed":
+		if result != nil {
+			a.logBuffer.Success(fmt.Sprintf("Downloaded: %s (quality: %s)", result.FilePath, result.Quality))
+			if result.QualityMismatch {
+				logQualityMismatch(a.logBuffer, result.RequestedQuality, result.Quality)
+			}
+			if result.Analysis != nil {
+				if result.Analysis.IsTrueLossless {
+					a.logBuffer.Info(fmt.Sprintf("Analysis: %s - True lossless", result.Analysis.VerdictLabel))
+				} else {
+					a.logBuffer.Warn(fmt.Sprintf("Analysis: %s - May be upscaled from lossy source", result.Analysis.VerdictLabel))
+				}
+			}
+		}
+	case "error":
+		if result != nil && result.Error != "" {
+			a.logBuffer.Error(fmt.Sprintf("Download failed: %s", result.Error))
+		}
+	case "cancelled":
+		dlog.Warn(fmt.Sprintf("Track %d cancelled", ev.TrackID))
+	}
+}
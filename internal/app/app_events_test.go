@@ -0,0 +1,35 @@
+package app
+
+import (
+	"testing"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/downloads"
+)
+
+func TestHandleDownloadProgress_PublishesAfterRecording(t *testing.T) {
+	a := &App{logBuffer: core.NewLogBuffer(10)}
+	_, ch := a.downloadEvents.Subscribe(4)
+
+	a.handleDownloadProgress(5, "queued", nil)
+
+	ev := <-ch
+	if ev.TrackID != 5 || ev.Status != "queued" {
+		t.Errorf("published %+v, want track 5 queued", ev)
+	}
+	if job, ok := a.jobs.Job(5); !ok || job.State != downloads.Queued {
+		t.Errorf("job = %+v, %v; want recorded before publishing", job, ok)
+	}
+}
+
+func TestLogDownloadEvent(t *testing.T) {
+	lb := core.NewLogBuffer(10)
+	a := &App{logBuffer: lb}
+
+	a.logDownloadEvent(core.DownloadEvent{TrackID: 3, Status: "error", Result: &core.DownloadResult{Error: "boom"}})
+	got := lb.GetAll()
+	if len(got) != 1 || got[0].Level != "error" || got[0].Message != "Download failed: boom" {
+		t.Errorf("entries = %+v", got)
+	}
+}
@@ -2,8 +2,16 @@ package app
 
 import (
 	"fmt"
+	"time"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/analysisstore"
+	"flacidal/internal/fileerr"
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/metacache"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/timestamp"
 )
 
 // =============================================================================
@@ -11,50 +19,135 @@ import (
 // =============================================================================
 
 // ListDownloadedFiles lists all downloaded FLAC files
-func (a *App) ListDownloadedFiles() ([]core.DownloadedFileInfo, error) {
+func (a *App) ListDownloadedFiles() ([]FileInfo, error) {
 	folder := a.GetDownloadFolder()
 	if folder == "" {
-		return []core.DownloadedFileInfo{}, nil
+		return []FileInfo{}, nil
+	}
+
+	files, err := core.ListFLACFiles(folder)
+	return WithVerdicts(a.analyses, WithAudioInfo(&a.fileMeta, UTCFiles(files))), err
+}
+
+// FileInfo is a listed FLAC with its audio format, so the Files grid can
+// badge 24/96 and 16/44.1 files without fetching each file's metadata.
+// The format fields are zero when the file's STREAMINFO can't be read.
+// Analysis is the verdict of the file's saved analysis, if it has one and
+// hasn't changed since.
+type FileInfo struct {
+	core.DownloadedFileInfo
+	metacache.Info
+	Analysis *analysisstore.Verdict `json:"analysis,omitempty"`
+}
+
+// WithAudioInfo adds the audio format of each file from cache. Shared by
+// the desktop (Wails) and HTTP server APIs.
+func WithAudioInfo(cache *metacache.Cache, files []core.DownloadedFileInfo) []FileInfo {
+	out := make([]FileInfo, len(files))
+	for i, f := range files {
+		out[i].DownloadedFileInfo = f
+		out[i].Info, _ = cache.Get(f.Path)
 	}
+	return out
+}
 
-	return core.ListFLACFiles(folder)
+// WithVerdicts adds the saved analysis verdicts from store to files and
+// returns them; files keep none when store can't be read. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func WithVerdicts(store *analysisstore.Store, files []FileInfo) []FileInfo {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	verdicts, err := store.Verdicts(paths)
+	if err != nil {
+		return files
+	}
+	for i, f := range files {
+		if v, ok := verdicts[f.Path]; ok {
+			files[i].Analysis = &v
+		}
+	}
+	return files
+}
+
+// UTCFiles rewrites the files' modification times as API timestamps (UTC,
+// see internal/timestamp) and returns files. Shared by the desktop (Wails)
+// and HTTP server APIs.
+func UTCFiles(files []core.DownloadedFileInfo) []core.DownloadedFileInfo {
+	now := time.Now()
+	for i := range files {
+		files[i].ModTime = timestamp.Normalize(files[i].ModTime, time.Local, now)
+	}
+	return files
 }
 
 // DeleteFile deletes a file from the filesystem
 func (a *App) DeleteFile(path string) error {
-	return core.DeleteFile(path)
+	if err := core.DeleteFile(path); err != nil {
+		return fileerr.Wrap(err)
+	}
+	a.fileMeta.Forget(path)
+	a.analyses.Forget(path)            //nolint:errcheck // a stale row only costs space
+	RemoveFromLibrary(a.library, path) //nolint:errcheck // the next scan catches up
+	return nil
 }
 
 // GetFileMetadata reads and returns metadata from a FLAC file
-func (a *App) GetFileMetadata(filePath string) (*core.FLACMetadata, error) {
-	return core.ReadFLACMetadata(filePath)
+func (a *App) GetFileMetadata(filePath string) (*FileMetadata, error) {
+	return ReadFileMetadata(filePath)
 }
 
-// GetFileCoverArt returns cover art as base64 encoded string
-func (a *App) GetFileCoverArt(filePath string) (map[string]string, error) {
-	base64Data, mimeType, err := core.GetCoverArtBase64(filePath)
+// FileMetadata is core's metadata of a FLAC file plus the provenance tags
+// FLACidal writes to downloads (see postprocess.Provenance). Core keeps
+// one artist and genre, the last of the file's; Artists and Genres list
+// every ARTIST and GENRE comment, for files that repeat them.
+type FileMetadata struct {
+	*core.FLACMetadata
+	postprocess.Provenance
+	Artists []string `json:"artists,omitempty"`
+	Genres  []string `json:"genres,omitempty"`
+}
+
+// ReadFileMetadata reads the metadata of the FLAC at path. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func ReadFileMetadata(path string) (*FileMetadata, error) {
+	meta, err := core.ReadFLACMetadata(path)
 	if err != nil {
 		return nil, err
 	}
-	return map[string]string{
-		"data":     base64Data,
-		"mimeType": mimeType,
-	}, nil
+	m := &FileMetadata{FLACMetadata: meta}
+	if f, err := flacmeta.Read(path); err == nil {
+		if c, err := f.Comments(); err == nil {
+			m.Provenance = postprocess.ReadProvenance(c)
+			m.Artists = c.GetAll("ARTIST")
+			m.Genres = c.GetAll("GENRE")
+		}
+	}
+	return m, nil
+}
+
+// GetFileCoverArt returns cover art as base64 encoded string
+func (a *App) GetFileCoverArt(filePath string) (map[string]string, error) {
+	return FileCoverArt(filePath)
 }
 
 // GetRenameTemplates returns available rename templates
 func (a *App) GetRenameTemplates() []map[string]string {
-	return core.GetRenameTemplates()
+	return RenameTemplates()
 }
 
-// PreviewRename generates a preview of rename operations
+// PreviewRename generates a preview of rename operations. Templates with
+// folders ("{artist}/{album}/{tracknumber} - {title}") preview each file's
+// destination path.
 func (a *App) PreviewRename(files []string, template string) []core.RenamePreview {
-	return core.PreviewRename(files, template)
+	return PreviewRenames(a.currentSettings(), a.GetDownloadFolder(), files, template)
 }
 
-// RenameFiles renames files according to the template
+// RenameFiles renames files according to the template, moving them into
+// the folders a template with folders names.
 func (a *App) RenameFiles(files []string, template string) []core.RenameResult {
-	results := core.RenameFiles(files, template)
+	results := ApplyRenames(a.currentSettings(), a.library, a.GetDownloadFolder(), files, template)
 
 	// Log results
 	if a.logBuffer != nil {
@@ -1,11 +1,15 @@
 package app
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/metacache"
+	"flacidal/internal/quality"
 )
 
 // Characterization tests for the "File Browser Methods" section of app.go.
@@ -34,6 +38,20 @@ func TestListDownloadedFiles(t *testing.T) {
 	})
 }
 
+func TestWithAudioInfo(t *testing.T) {
+	dir := t.TempDir()
+	flac := filepath.Join(dir, "cd.flac")
+	writeTestFLAC(t, flac, nil, nil, 64)
+	var cache metacache.Cache
+	got := WithAudioInfo(&cache, []core.DownloadedFileInfo{{Path: flac, Title: "Song"}, {Path: filepath.Join(dir, "gone.flac")}})
+	if got[0].DownloadedFileInfo.Title != "Song" || got[0].SampleRate != 44100 || got[0].BitDepth != 16 || got[0].Tier != quality.Lossless {
+		t.Errorf("WithAudioInfo[0] = %+v", got[0])
+	}
+	if got[1].SampleRate != 0 || got[1].Tier != "" {
+		t.Errorf("unreadable file: %+v, want no format", got[1])
+	}
+}
+
 func TestDeleteFile(t *testing.T) {
 	a := &App{}
 	t.Run("rejects non-flac files", func(t *testing.T) {
@@ -106,3 +124,48 @@ func TestRenameFiles(t *testing.T) {
 		t.Fatalf("RenameFiles() returned %d results, want 1", len(got))
 	}
 }
+
+// Benchmarks for the file browser's hot paths. Run with `make bench`.
+
+func BenchmarkGetFileMetadata(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "bench.flac")
+	writeTestFLAC(b, path, testTags, make([]byte, 512*1024), 64*1024)
+	a := &App{}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := a.GetFileMetadata(path); err != nil {
+			b.Fatalf("GetFileMetadata() error = %v", err)
+		}
+	}
+}
+
+func BenchmarkGetFileCoverArt(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "bench.flac")
+	writeTestFLAC(b, path, testTags, make([]byte, 1024*1024), 64*1024)
+	a := &App{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := a.GetFileCoverArt(path); err != nil {
+			b.Fatalf("GetFileCoverArt() error = %v", err)
+		}
+	}
+}
+
+func BenchmarkListDownloadedFiles(b *testing.B) {
+	core.SetDataDir(b.TempDir())
+	dir := b.TempDir()
+	for i := 0; i < 200; i++ {
+		writeTestFLAC(b, filepath.Join(dir, fmt.Sprintf("Artist - Track %03d.flac", i)), testTags, nil, 4*1024)
+	}
+	a := &App{config: &core.Config{DownloadFolder: dir}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := a.ListDownloadedFiles(); err != nil {
+			b.Fatalf("ListDownloadedFiles() error = %v", err)
+		}
+	}
+}
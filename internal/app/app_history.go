@@ -1,12 +1,25 @@
 package app
 
 import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/coverproxy"
+	"flacidal/internal/downloads"
+	"flacidal/internal/history"
+	"flacidal/internal/library"
+	"flacidal/internal/settings"
+	"flacidal/internal/timestamp"
 )
 
 // =============================================================================
@@ -35,7 +48,8 @@ func (a *App) GetDownloadHistory() ([]core.DownloadRecord, error) {
 	if a.db == nil {
 		return nil, nil
 	}
-	return a.db.GetAllDownloadRecords()
+	records, err := a.db.GetAllDownloadRecords()
+	return UTCRecords(records), err
 }
 
 // GetRecentAlbums returns deduplicated recent album downloads for the home page grid
@@ -88,12 +102,258 @@ func RecentAlbums(db *core.Database, limit int) ([]map[string]interface{}, error
 			"source":        r.ContentType,
 			"content_id":    r.TidalContentID,
 			"content_type":  r.ContentType,
-			"downloaded_at": r.LastDownloadAt.Format(time.RFC3339),
+			"downloaded_at": timestamp.Format(r.LastDownloadAt),
 		})
 	}
 	return result, nil
 }
 
+// UTCRecords converts records' times to API timestamps (UTC, see
+// internal/timestamp) in place and returns records. The database hands
+// them back in the local zone. Shared by the desktop (Wails) and HTTP
+// server APIs.
+func UTCRecords(records []core.DownloadRecord) []core.DownloadRecord {
+	for i := range records {
+		records[i].LastDownloadAt = timestamp.UTC(records[i].LastDownloadAt)
+		records[i].CreatedAt = timestamp.UTC(records[i].CreatedAt)
+	}
+	return records
+}
+
+// MigrateTimestamps rewrites the times stored in the databases in dir —
+// flacidal-core's among them — in UTC (see timestamp.MigrateDB) and
+// returns how many it rewrote. Run it before the databases are opened.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func MigrateTimestamps(ctx context.Context, dir string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.db"))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	var errs []error
+	for _, path := range paths {
+		db, err := sql.Open(library.Driver, "file:"+path+"?_busy_timeout=5000")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		changed, err := timestamp.MigrateDB(ctx, db)
+		db.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+		}
+		n += changed
+	}
+	return n, errors.Join(errs...)
+}
+
+// ContentBatches remembers which history record (content ID) each queued
+// track belongs to, so finished tracks are counted against the playlist,
+// album or single they were queued for, and keeps each batch's progress
+// for Status. The zero value is ready to use. Shared by the desktop (Wails)
+// and HTTP server APIs.
+type ContentBatches struct {
+	m sync.Map // trackID (int) → contentID (string)
+
+	mu      sync.Mutex
+	order   []string                // content IDs, in the order first queued
+	batches map[string]*BatchStatus // contentID → counts; Queued and Active are left 0
+}
+
+// BatchStatus is the progress of one queued playlist, album or single.
+type BatchStatus struct {
+	ID     string `json:"id"` // content ID, as in the download history
+	Name   string `json:"name"`
+	Type   string `json:"type"` // "playlist", "album", "track"…
+	Total  int    `json:"total"`
+	Queued int    `json:"queued"`
+	Active int    `json:"active"`
+	Done   int    `json:"done"`
+	Failed int    `json:"failed"`
+}
+
+// Start saves the content-level history record for a newly queued batch
+// and assigns trackIDs to it. Batches without a content ID are not
+// tracked; without a database they are tracked but not saved. Finished
+// batches are dropped from Status once a new batch starts.
+func (b *ContentBatches) Start(db *core.Database, rec core.DownloadRecord, trackIDs []int) error {
+	if rec.TidalContentID == "" {
+		return nil
+	}
+	if db != nil {
+		if err := db.SaveDownloadRecord(&rec); err != nil {
+			return fmt.Errorf("save download history for %s: %w", rec.TidalContentID, err)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pending := b.pending()
+	kept := b.order[:0]
+	for _, id := range b.order {
+		if pending[id] > 0 {
+			kept = append(kept, id)
+		} else {
+			delete(b.batches, id)
+		}
+	}
+	b.order = kept
+	if b.batches == nil {
+		b.batches = make(map[string]*BatchStatus)
+	}
+	st, ok := b.batches[rec.TidalContentID]
+	if !ok {
+		st = &BatchStatus{ID: rec.TidalContentID}
+		b.batches[rec.TidalContentID] = st
+		b.order = append(b.order, rec.TidalContentID)
+	}
+	st.Name, st.Type = rec.TidalContentName, rec.ContentType
+	for _, id := range trackIDs {
+		if _, queued := b.m.Swap(id, rec.TidalContentID); !queued {
+			st.Total++
+		}
+	}
+	return nil
+}
+
+// Finish counts a finished track against its batch's record: downloaded
+// on "completed", failed on "error". Cancelled tracks just leave the batch.
+func (b *ContentBatches) Finish(db *core.Database, trackID int, status string) error {
+	if status != "completed" && status != "error" && status != "cancelled" {
+		return nil
+	}
+	cid, ok := b.m.LoadAndDelete(trackID)
+	if !ok {
+		return nil
+	}
+	b.mu.Lock()
+	if st := b.batches[cid.(string)]; st != nil {
+		switch status {
+		case "completed":
+			st.Done++
+		case "error":
+			st.Failed++
+		default:
+			st.Total--
+		}
+	}
+	b.mu.Unlock()
+	if db == nil || status == "cancelled" {
+		return nil
+	}
+	if err := db.IncrementDownloadCounts(cid.(string), status == "completed"); err != nil {
+		return fmt.Errorf("update download counts for %s: %w", cid.(string), err)
+	}
+	return nil
+}
+
+// Status returns every batch's progress in the order they were queued.
+// Tracks still pending count as active when tracker has them downloading,
+// otherwise as queued.
+func (b *ContentBatches) Status(tracker *downloads.Tracker) []BatchStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]BatchStatus, len(b.order))
+	index := make(map[string]int, len(b.order))
+	for i, id := range b.order {
+		out[i] = *b.batches[id]
+		index[id] = i
+	}
+	b.m.Range(func(trackID, cid any) bool {
+		i, ok := index[cid.(string)]
+		if !ok {
+			return true
+		}
+		if job, ok := tracker.Job(trackID.(int)); ok && job.State == downloads.Downloading {
+			out[i].Active++
+		} else {
+			out[i].Queued++
+		}
+		return true
+	})
+	return out
+}
+
+// pending counts each batch's unfinished tracks. b.mu must be held.
+func (b *ContentBatches) pending() map[string]int {
+	n := make(map[string]int, len(b.order))
+	b.m.Range(func(_, cid any) bool {
+		n[cid.(string)]++
+		return true
+	})
+	return n
+}
+
+// GetTrackHistory returns one page of the per-track download log, newest
+// first, with the total entry count
+func (a *App) GetTrackHistory(limit, offset int) (map[string]interface{}, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset = max(offset, 0)
+
+	entries, err := a.db.ListHistory(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	timestamp.UTCFields(entries)
+	total, err := a.db.GetHistoryCount()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"entries": entries, "total": total}, nil
+}
+
+// GetActivityHeatmap counts the tracks downloaded over the last days days
+// (0 for all time) by day of the week and hour of the day, in local time
+func (a *App) GetActivityHeatmap(days int) (history.Heatmap, error) {
+	return ActivityHeatmap(a.db, days, time.Local)
+}
+
+// heatmapPage is how many history entries ActivityHeatmap reads at a time.
+const heatmapPage = 200
+
+// ActivityHeatmap buckets the tracks in db's per-track history finished
+// over the last days days (0 for all time) by weekday and hour in loc. A
+// nil db yields an empty heatmap. Shared by the desktop (Wails) and HTTP
+// server APIs.
+func ActivityHeatmap(db *core.Database, days int, loc *time.Location) (history.Heatmap, error) {
+	if days < 0 {
+		return history.Heatmap{}, fmt.Errorf("days must not be negative")
+	}
+	var since time.Time
+	if days > 0 {
+		since = time.Now().AddDate(0, 0, -days)
+	}
+	times := []time.Time{}
+	for offset := 0; db != nil; offset += heatmapPage {
+		entries, err := db.ListHistory(heatmapPage, offset)
+		if err != nil {
+			return history.Heatmap{}, err
+		}
+		older := false
+		for _, e := range entries {
+			t := timestamp.Latest(e)
+			if t.IsZero() {
+				continue
+			}
+			// Entries come newest first
+			if t.Before(since) {
+				older = true
+				break
+			}
+			times = append(times, t)
+		}
+		if older || len(entries) < heatmapPage {
+			break
+		}
+	}
+	return history.BuildHeatmap(times, days, loc), nil
+}
+
 // GetDownloadHistoryFiltered returns filtered download history with pagination
 func (a *App) GetDownloadHistoryFiltered(filter map[string]interface{}) (map[string]interface{}, error) {
 	if a.db == nil {
@@ -122,7 +382,7 @@ func (a *App) GetDownloadHistoryFiltered(filter map[string]interface{}) (map[str
 	}
 
 	return map[string]interface{}{
-		"records": records,
+		"records": UTCRecords(records),
 		"total":   total,
 	}, nil
 }
@@ -132,7 +392,31 @@ func (a *App) DeleteHistoryRecord(id int64) error {
 	if a.db == nil {
 		return fmt.Errorf("database not initialized")
 	}
-	return a.db.DeleteDownloadRecord(id)
+	return DeleteHistory(a.db, a.origins, id)
+}
+
+// DeleteHistory deletes the download history record id and forgets its
+// origin, snapshot and archived cover in origins. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func DeleteHistory(db *core.Database, origins *history.Origins, id int64) error {
+	records, err := db.GetAllDownloadRecords()
+	if err != nil {
+		return err
+	}
+	contentID := ""
+	for _, r := range records {
+		if r.ID == id {
+			contentID = r.TidalContentID
+			break
+		}
+	}
+	if err := db.DeleteDownloadRecord(id); err != nil {
+		return err
+	}
+	if contentID == "" {
+		return nil
+	}
+	return origins.Delete(contentID)
 }
 
 // ClearDownloadHistory removes all download history
@@ -141,6 +425,12 @@ func (a *App) ClearDownloadHistory() error {
 		return fmt.Errorf("database not initialized")
 	}
 	err := a.db.ClearAllHistory()
+	if err == nil {
+		err = a.origins.Clear()
+	}
+	if err == nil {
+		err = a.timings.Clear()
+	}
 	if err == nil && a.logBuffer != nil {
 		a.logBuffer.Info("Download history cleared")
 	}
@@ -162,21 +452,108 @@ func (a *App) RefetchFromHistory(tidalContentID string) (map[string]interface{},
 		return nil, fmt.Errorf("history record not found")
 	}
 
-	// Reconstruct the Tidal URL
-	var url string
+	url, err := RefetchURL(a.origins, record)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the content through whichever source the URL belongs to
+	return a.FetchContentFromURL(url)
+}
+
+// GetHistoryTimings returns the state transitions and timings of each
+// track's latest finished download, by track ID, for the per-track history
+func (a *App) GetHistoryTimings() map[string]downloads.Job {
+	return a.timings.All()
+}
+
+// GetHistorySnapshots returns what each history record's content looked
+// like when it was queued (title, creator, description, cover), by content
+// ID. Records queued before snapshots were kept have none.
+func (a *App) GetHistorySnapshots() map[string]history.Snapshot {
+	return a.origins.Snapshots()
+}
+
+// GetHistoryCover returns the archived cover of a history record's
+// playlist as base64 data and its MIME type.
+func (a *App) GetHistoryCover(contentID string) (map[string]string, error) {
+	return HistoryCover(a.origins, contentID)
+}
+
+// NoteOrigin remembers the URL fetched content came from, so the history
+// record saved when the content is queued can be refetched from the same
+// source. Shared by the desktop (Wails) and HTTP server APIs.
+func NoteOrigin(origins *history.Origins, source, id, contentType, rawURL string) {
+	origins.Note(history.Key(source, id), history.Origin{
+		Source:      source,
+		URL:         rawURL,
+		ContentType: contentType,
+	})
+}
+
+// DescribeOrigin adds the title, creator, description and cover URL of
+// fetched content (the "title", "creator", "description" and "coverUrl" of
+// content) to the origin NoteOrigin noted for it, so its history record
+// keeps showing them once the source deletes the content. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func DescribeOrigin(origins *history.Origins, source, id string, content map[string]interface{}) {
+	str := func(key string) string {
+		s, _ := content[key].(string)
+		return s
+	}
+	origins.Describe(history.Key(source, id), history.Snapshot{
+		Title:       str("title"),
+		Creator:     str("creator"),
+		Description: str("description"),
+		CoverURL:    str("coverUrl"),
+	})
+}
+
+// HistoryCoverFetcher returns the func history.Origins archives playlist
+// covers with. It goes through coverproxy, so only the music services'
+// image hosts are reached, with the User-Agents current's settings give.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func HistoryCoverFetcher(current func() settings.Settings) func(rawURL string) ([]byte, error) {
+	return func(rawURL string) ([]byte, error) {
+		data, _, err := coverproxy.Download(rawURL, current().CoverUserAgents)
+		return data, err
+	}
+}
+
+// HistoryCover returns the archived cover of a history record's content as
+// base64 data and its MIME type. Shared by the desktop (Wails) and HTTP
+// server APIs.
+func HistoryCover(origins *history.Origins, contentID string) (map[string]string, error) {
+	data, mimeType, err := origins.Cover(contentID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"data":     base64.StdEncoding.EncodeToString(data),
+		"mimeType": mimeType,
+	}, nil
+}
+
+// RefetchURL returns the URL a history record's content can be fetched
+// again from: the URL it was originally fetched from when known, otherwise
+// a tidal.com URL rebuilt from the content ID (records saved before origins
+// were kept are all Tidal). Shared by the desktop (Wails) and HTTP server
+// APIs.
+func RefetchURL(origins *history.Origins, record *core.DownloadRecord) (string, error) {
+	id := record.TidalContentID
+	if origin, ok := origins.Get(id); ok && origin.URL != "" {
+		return origin.URL, nil
+	}
+	if strings.Contains(id, ":") {
+		return "", fmt.Errorf("no source URL recorded for %s", id)
+	}
+
 	switch record.ContentType {
-	case "playlist":
-		url = fmt.Sprintf("https://tidal.com/browse/playlist/%s", tidalContentID)
-	case "album":
-		url = fmt.Sprintf("https://tidal.com/browse/album/%s", tidalContentID)
-	case "track":
-		url = fmt.Sprintf("https://tidal.com/browse/track/%s", tidalContentID)
+	case "playlist", "album", "track":
+		return fmt.Sprintf("https://tidal.com/browse/%s/%s", record.ContentType, id), nil
 	default:
-		return nil, fmt.Errorf("unknown content type: %s", record.ContentType)
+		return "", fmt.Errorf("unknown content type: %s", record.ContentType)
 	}
-
-	// Fetch the content
-	return a.FetchTidalContent(url)
 }
 
 // GetMatchFailures returns all match failures
@@ -184,5 +561,9 @@ func (a *App) GetMatchFailures() ([]core.MatchFailure, error) {
 	if a.db == nil {
 		return nil, nil
 	}
-	return a.db.GetMatchFailures()
+	failures, err := a.db.GetMatchFailures()
+	for i := range failures {
+		failures[i].LastAttemptAt = timestamp.UTC(failures[i].LastAttemptAt)
+	}
+	return failures, err
 }
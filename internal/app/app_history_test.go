@@ -1,9 +1,14 @@
 package app
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/downloads"
+	"flacidal/internal/history"
 )
 
 // Characterization tests for the "Database Methods" section of app.go
@@ -15,13 +20,12 @@ import (
 // database_test.go uses — so no real user data under ~/.flacidal is touched.
 //
 // Bug note (not fixed): RefetchFromHistory's "known content type" success path
-// calls a.FetchTidalContent(url), whose per-type branches (e.g. the "track"
-// case calling a.downloader.GetTrackAsTidalTrack) dereference a.downloader /
-// a.tidalClient without a nil-guard. Not reachable in production (startup()
-// always initializes those fields), but inconsistent with the nil-guard
-// pattern used by sibling methods like SearchTidal/DownloadTrack. Only the
-// "not found" and "unknown content type" branches (which return before
-// reaching FetchTidalContent) are exercised here.
+// calls a.FetchContentFromURL(url), which dereferences a.sourceManager without
+// a nil-guard. Not reachable in production (startup() always initializes it),
+// but inconsistent with the nil-guard pattern used by sibling methods like
+// SearchTidal/DownloadTrack. Only the "not found" and "unknown content type"
+// branches (which return before reaching FetchContentFromURL) are exercised
+// here; the URL it would fetch is covered by TestRefetchURL.
 
 func newTestApp(t *testing.T) *App {
 	t.Helper()
@@ -186,6 +190,22 @@ func TestDeleteHistoryRecord(t *testing.T) {
 			t.Errorf("DeleteHistoryRecord() error = %v", err)
 		}
 	})
+	t.Run("forgets the origin", func(t *testing.T) {
+		a := newTestApp(t)
+		a.origins, _ = history.Open(t.TempDir())
+		a.origins.Note("1", history.Origin{Source: "tidal", URL: "https://tidal.com/browse/track/1"})
+		a.origins.Commit("1")
+		if err := a.db.SaveDownloadRecord(&core.DownloadRecord{TidalContentID: "1", TidalContentName: "X", ContentType: "track"}); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		record, _ := a.db.GetDownloadRecord("1")
+		if err := a.DeleteHistoryRecord(record.ID); err != nil {
+			t.Fatalf("DeleteHistoryRecord() error = %v", err)
+		}
+		if _, ok := a.origins.Get("1"); ok {
+			t.Error("origin kept after its record was deleted")
+		}
+	})
 }
 
 func TestClearDownloadHistory(t *testing.T) {
@@ -238,6 +258,59 @@ func TestRefetchFromHistory(t *testing.T) {
 	})
 }
 
+func TestRefetchURL(t *testing.T) {
+	origins, err := history.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("history.Open: %v", err)
+	}
+	NoteOrigin(origins, "qobuz", "abc", "album", "https://open.qobuz.com/album/abc")
+	if err := origins.Commit("qobuz:abc"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		origins *history.Origins
+		record  core.DownloadRecord
+		want    string
+		wantErr bool
+	}{
+		{"recorded origin", origins, core.DownloadRecord{TidalContentID: "qobuz:abc", ContentType: "album"}, "https://open.qobuz.com/album/abc", false},
+		{"legacy tidal album", origins, core.DownloadRecord{TidalContentID: "42", ContentType: "album"}, "https://tidal.com/browse/album/42", false},
+		{"legacy tidal without origins", nil, core.DownloadRecord{TidalContentID: "7", ContentType: "track"}, "https://tidal.com/browse/track/7", false},
+		{"other source without origin", origins, core.DownloadRecord{TidalContentID: "qobuz:zzz", ContentType: "album"}, "", true},
+		{"unknown content type", origins, core.DownloadRecord{TidalContentID: "1", ContentType: "mix"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RefetchURL(tt.origins, &tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RefetchURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RefetchURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeOrigin(t *testing.T) {
+	origins, _ := history.Open(t.TempDir())
+	NoteOrigin(origins, "qobuz", "p1", "playlist", "https://open.qobuz.com/playlist/p1")
+	DescribeOrigin(origins, "qobuz", "p1", map[string]interface{}{
+		"title":       "Mix",
+		"creator":     "me",
+		"description": "Late night",
+		"coverUrl":    "https://static.qobuz.com/images/p1.jpg",
+		"tracks":      []string{"ignored"},
+	})
+	origins.Commit("qobuz:p1")
+	want := history.Snapshot{Title: "Mix", Creator: "me", Description: "Late night", CoverURL: "https://static.qobuz.com/images/p1.jpg"}
+	if got, _ := origins.Get("qobuz:p1"); got.Snapshot != want {
+		t.Errorf("snapshot = %+v, want %+v", got.Snapshot, want)
+	}
+}
+
 func TestGetMatchFailures(t *testing.T) {
 	t.Run("nil db", func(t *testing.T) {
 		a := &App{}
@@ -257,3 +330,96 @@ func TestGetMatchFailures(t *testing.T) {
 		}
 	})
 }
+
+func TestContentBatches(t *testing.T) {
+	db := newTestApp(t).db
+
+	var b ContentBatches
+	rec := core.DownloadRecord{TidalContentID: "pl-1", TidalContentName: "Mix", ContentType: "playlist", TracksTotal: 3}
+	if err := b.Start(db, rec, []int{1, 2, 3}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	for id, status := range map[int]string{1: "completed", 2: "error", 3: "cancelled"} {
+		if err := b.Finish(db, id, status); err != nil {
+			t.Fatalf("Finish(%d, %s): %v", id, status, err)
+		}
+	}
+	b.Finish(db, 1, "completed") // already finished: not counted twice
+
+	got, err := db.GetDownloadRecord("pl-1")
+	if err != nil || got == nil {
+		t.Fatalf("GetDownloadRecord: %v, %v", got, err)
+	}
+	if got.TracksTotal != 3 || got.TracksDownloaded != 1 || got.TracksFailed != 1 {
+		t.Errorf("record = %+v, want 3 total, 1 downloaded, 1 failed", got)
+	}
+}
+
+func TestContentBatches_NoContentID(t *testing.T) {
+	var b ContentBatches
+	if err := b.Start(nil, core.DownloadRecord{}, []int{1}); err != nil {
+		t.Errorf("Start without db: %v", err)
+	}
+	if err := b.Finish(nil, 1, "completed"); err != nil {
+		t.Errorf("Finish for untracked track: %v", err)
+	}
+}
+
+func TestContentBatches_Status(t *testing.T) {
+	var b ContentBatches
+	var jobs downloads.Tracker
+	b.Start(nil, core.DownloadRecord{TidalContentID: "pl-1", TidalContentName: "Mix", ContentType: "playlist"}, []int{1, 2, 3, 4})
+	b.Start(nil, core.DownloadRecord{TidalContentID: "al-1", TidalContentName: "LP", ContentType: "album"}, []int{5})
+	jobs.Record(2, "downloading")
+	b.Finish(nil, 3, "completed")
+	b.Finish(nil, 4, "error")
+
+	want := []BatchStatus{
+		{ID: "pl-1", Name: "Mix", Type: "playlist", Total: 4, Queued: 1, Active: 1, Done: 1, Failed: 1},
+		{ID: "al-1", Name: "LP", Type: "album", Total: 1, Queued: 1},
+	}
+	if got := b.Status(&jobs); !slices.Equal(got, want) {
+		t.Errorf("Status = %+v, want %+v", got, want)
+	}
+
+	// Once finished, a batch is dropped when the next one starts.
+	b.Finish(nil, 5, "completed")
+	b.Start(nil, core.DownloadRecord{TidalContentID: "tr-9", ContentType: "track"}, []int{9})
+	if got := b.Status(&jobs); len(got) != 2 || got[0].ID != "pl-1" || got[1].ID != "tr-9" {
+		t.Errorf("after al-1 finished: %+v", got)
+	}
+}
+
+func TestGetTrackHistory_NoDB(t *testing.T) {
+	a := &App{}
+	if _, err := a.GetTrackHistory(10, 0); err == nil {
+		t.Error("GetTrackHistory() with nil db: want error, got nil")
+	}
+}
+
+func TestActivityHeatmap(t *testing.T) {
+	h, err := ActivityHeatmap(nil, 30, time.UTC)
+	if err != nil || h.Total != 0 || h.Days != 30 {
+		t.Errorf("nil db: %+v, %v; want an empty 30-day heatmap", h, err)
+	}
+	if _, err := ActivityHeatmap(nil, -1, time.UTC); err == nil {
+		t.Error("negative days: want an error")
+	}
+}
+
+func TestUTCRecords(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	records := UTCRecords([]core.DownloadRecord{{
+		LastDownloadAt: time.Date(2026, 3, 1, 20, 4, 5, 999, paris),
+		CreatedAt:      time.Date(2026, 2, 1, 1, 0, 0, 0, paris),
+	}})
+	if got := records[0].LastDownloadAt; got.Location() != time.UTC || got.Format(time.RFC3339) != "2026-03-01T19:04:05Z" {
+		t.Errorf("LastDownloadAt = %v", got)
+	}
+	if got := records[0].CreatedAt.Format(time.RFC3339); got != "2026-02-01T00:00:00Z" {
+		t.Errorf("CreatedAt = %s", got)
+	}
+	if got := UTCRecords(nil); got != nil {
+		t.Errorf("UTCRecords(nil) = %v", got)
+	}
+}
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/history"
+)
+
+// =============================================================================
+// URL Import (exposed to frontend)
+// =============================================================================
+
+// ImportResult reports what became of one URL in an import.
+type ImportResult struct {
+	URL    string `json:"url"`
+	Source string `json:"source,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Queued int    `json:"queued"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ResolvedContent is a URL resolved into the tracks to queue for it.
+type ResolvedContent struct {
+	URL    string // URL actually fetched (after Odesli resolution)
+	Source string
+	ID     string
+	Type   string
+	Title  string
+	Artist string // album artist; empty for playlists and tracks
+	Tracks []core.SourceTrack
+}
+
+// ImportURLs resolves each URL in text (one per line) and queues its tracks
+// into outputDir, reporting success or failure per URL. A bad URL doesn't
+// stop the rest of the import.
+func (a *App) ImportURLs(text string, outputDir string) ([]ImportResult, error) {
+	if a.downloadManager == nil || a.sourceManager == nil {
+		return nil, fmt.Errorf("download manager not initialized")
+	}
+	if outputDir == "" {
+		return nil, fmt.Errorf("no output directory specified")
+	}
+
+	urls := ParseImportList(text)
+	results := make([]ImportResult, len(urls))
+	for i, rawURL := range urls {
+		results[i] = ImportResult{URL: rawURL}
+		content, err := ResolveContent(a.sourceManager, rawURL)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Source, results[i].Type, results[i].Title = content.Source, content.Type, content.Title
+		if err := CheckDownloadable(content.Source); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		NoteOrigin(a.origins, content.Source, content.ID, content.Type, content.URL)
+
+		var queued int
+		if content.Source == "qobuz" {
+			queued, err = a.QueueQobuzDownloads(content.Tracks, outputDir, content.Title, content.ID, content.Type)
+		} else {
+			queued, err = a.QueueDownloads(TidalTracksFromSource(content.Tracks), outputDir, content.Title,
+				history.Key(content.Source, content.ID), content.Type)
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Queued = queued
+	}
+
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Imported %d URLs: %s", len(urls), ImportSummary(results)))
+	}
+	return results, nil
+}
+
+// importWatchedFile imports a URL list dropped into the watch folder.
+func (a *App) importWatchedFile(name, text string) {
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Watch folder: importing %s", name))
+	}
+	if _, err := a.ImportURLs(text, a.GetDownloadFolder()); err != nil && a.logBuffer != nil {
+		a.logBuffer.Warn(fmt.Sprintf("Watch folder: %s: %v", name, err))
+	}
+}
+
+// ParseImportList returns the URLs in an import file, one per line, in
+// order. Blank lines, "#" comments (which covers M3U directives) and
+// repeated URLs are skipped. CSV and JSON exports of other downloaders,
+// with a service and ID or a URL per row, are read row by row instead.
+func ParseImportList(text string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	if rows, ok := exportURLs(text); ok {
+		for _, u := range rows {
+			if !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+		}
+		return urls
+	}
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+			continue
+		}
+		seen[line] = true
+		urls = append(urls, line)
+	}
+	return urls
+}
+
+// ImportSummary describes an import's results in one line for the logs.
+func ImportSummary(results []ImportResult) string {
+	ok, tracks := 0, 0
+	for _, r := range results {
+		if r.Error == "" {
+			ok++
+			tracks += r.Queued
+		}
+	}
+	return fmt.Sprintf("%d queued (%d tracks), %d failed", ok, tracks, len(results)-ok)
+}
+
+// ResolveContent resolves a track, album or playlist URL through sm,
+// falling back to Odesli for services sm can't parse, into its tracks.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func ResolveContent(sm *core.SourceManager, rawURL string) (*ResolvedContent, error) {
+	source, err := sm.DetectSource(rawURL)
+	if err != nil {
+		resolvedURL, rerr := ResolveViaOdesli(sm, rawURL)
+		if rerr != nil {
+			return nil, fmt.Errorf("unknown URL format")
+		}
+		rawURL = resolvedURL
+		if source, err = sm.DetectSource(rawURL); err != nil {
+			return nil, fmt.Errorf("unknown URL format")
+		}
+	}
+
+	id, contentType, err := source.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	content := &ResolvedContent{URL: rawURL, Source: source.Name(), ID: id, Type: contentType}
+
+	switch contentType {
+	case "track":
+		track, err := source.GetTrack(id)
+		if err != nil {
+			return nil, err
+		}
+		content.Title = track.Title
+		content.Tracks = []core.SourceTrack{*track}
+	case "album":
+		album, err := source.GetAlbum(id)
+		if err != nil {
+			return nil, err
+		}
+		content.Title = album.Title
+		content.Artist = album.Artist
+		content.Tracks = album.Tracks
+	case "playlist":
+		playlist, err := source.GetPlaylist(id)
+		if err != nil {
+			return nil, err
+		}
+		content.Title = playlist.Title
+		content.Tracks = playlist.Tracks
+	default:
+		return nil, fmt.Errorf("%s URLs can't be imported", contentType)
+	}
+	return content, nil
+}
+
+// CheckDownloadable returns an error unless source's tracks can be queued:
+// only Tidal's and Qobuz's can. Another service's IDs would be taken for
+// Tidal track IDs and fetch the wrong tracks. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func CheckDownloadable(source string) error {
+	switch source {
+	case "tidal", "qobuz":
+		return nil
+	}
+	return fmt.Errorf("%s tracks can't be downloaded, only Tidal and Qobuz tracks", source)
+}
+
+// TidalTracksFromSource converts source tracks for the Tidal download queue,
+// the same mapping FetchContentFromURL hands the frontend. Tracks from
+// another source, or without a numeric ID, are left out rather than queued
+// as some other Tidal track.
+func TidalTracksFromSource(tracks []core.SourceTrack) []core.TidalTrack {
+	result := make([]core.TidalTrack, 0, len(tracks))
+	for _, t := range tracks {
+		id, err := strconv.Atoi(t.ID)
+		if err != nil || id <= 0 || (t.Source != "" && t.Source != "tidal") {
+			continue
+		}
+		artists := t.Artist
+		if len(t.Artists) > 0 {
+			artists = strings.Join(t.Artists, ", ")
+		}
+		result = append(result, core.TidalTrack{
+			ID:         id,
+			Title:      t.Title,
+			Artist:     t.Artist,
+			Artists:    artists,
+			Album:      t.Album,
+			Duration:   t.Duration,
+			TrackNum:   t.TrackNumber,
+			DiscNumber: t.DiscNumber,
+			CoverURL:   t.CoverURL,
+			Explicit:   t.Explicit,
+			ISRC:       t.ISRC,
+		})
+	}
+	return result
+}
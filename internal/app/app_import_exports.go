@@ -0,0 +1,223 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Exports of web-based downloaders (favorites, libraries, download
+// histories) are CSV or JSON files with a row per item. Each row names its
+// service and ID, or carries a URL. exportURLs turns those rows into the
+// URLs ImportURLs resolves, so the set can be queued like any URL list.
+
+// exportColumns maps the column (or JSON key) names used by known exports,
+// normalized by exportKey, to the field they hold.
+var exportColumns = map[string]string{
+	"url": "url", "link": "url", "href": "url", "trackurl": "url", "sourceurl": "url",
+	"service": "service", "source": "service", "platform": "service", "provider": "service", "site": "service",
+	"id": "id", "itemid": "id", "trackid": "id", "contentid": "id", "mediaid": "id",
+	"albumid": "album", "playlistid": "playlist",
+	"type": "type", "kind": "type", "itemtype": "type", "contenttype": "type", "mediatype": "type",
+}
+
+// exportServices maps service names, normalized by exportKey, to the URL
+// format of each content type.
+var exportServices = map[string]map[string]string{
+	"tidal": {
+		"track": "https://tidal.com/browse/track/%s", "album": "https://tidal.com/browse/album/%s",
+		"playlist": "https://tidal.com/browse/playlist/%s",
+	},
+	"qobuz": {
+		"track": "https://open.qobuz.com/track/%s", "album": "https://open.qobuz.com/album/%s",
+		"playlist": "https://open.qobuz.com/playlist/%s",
+	},
+	"deezer": {
+		"track": "https://www.deezer.com/track/%s", "album": "https://www.deezer.com/album/%s",
+		"playlist": "https://www.deezer.com/playlist/%s",
+	},
+	"spotify": {
+		"track": "https://open.spotify.com/track/%s", "album": "https://open.spotify.com/album/%s",
+		"playlist": "https://open.spotify.com/playlist/%s",
+	},
+	"applemusic": {
+		"track": "https://music.apple.com/us/song/%s", "album": "https://music.apple.com/us/album/%s",
+	},
+	"amazonmusic": {
+		"track": "https://music.amazon.com/tracks/%s", "album": "https://music.amazon.com/albums/%s",
+	},
+}
+
+// exportServiceAliases are other names exports give the services above.
+var exportServiceAliases = map[string]string{
+	"apple": "applemusic", "itunes": "applemusic", "amazon": "amazonmusic",
+}
+
+// exportTypes maps the content types exports use to FLACidal's.
+var exportTypes = map[string]string{
+	"": "track", "track": "track", "song": "track", "tracks": "track", "songs": "track",
+	"album": "album", "albums": "album", "release": "album",
+	"playlist": "playlist", "playlists": "playlist",
+}
+
+// exportKey lowercases s and drops everything but letters and digits, so
+// "Track ID", "track_id" and "trackId" compare equal.
+func exportKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// exportURLs returns the URLs of the rows of a CSV or JSON export, in
+// order, or false when text isn't one. A row whose service has no known
+// URL format yields "service:type:id", which then fails to resolve and is
+// reported like any bad URL.
+func exportURLs(text string) ([]string, bool) {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "\ufeff"))
+	var rows []map[string]string
+	var ok bool
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		rows, ok = jsonExportRows(text)
+	} else {
+		rows, ok = csvExportRows(text)
+	}
+	if !ok {
+		return nil, false
+	}
+	urls := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if u := exportRowURL(row); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls, true
+}
+
+// exportRowURL builds the URL of one row from its fields (named as in
+// exportColumns), or "" when the row has neither a URL nor an ID.
+func exportRowURL(row map[string]string) string {
+	if u := row["url"]; strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") {
+		return u
+	}
+	id, kind := row["id"], row["type"]
+	for _, k := range []string{"album", "playlist"} {
+		if id == "" && row[k] != "" {
+			id, kind = row[k], k
+		}
+	}
+	if id == "" {
+		return ""
+	}
+	service := exportKey(row["service"])
+	if alias, ok := exportServiceAliases[service]; ok {
+		service = alias
+	}
+	contentType, ok := exportTypes[exportKey(kind)]
+	if !ok {
+		contentType = exportKey(kind)
+	}
+	if format, ok := exportServices[service][contentType]; ok {
+		return fmt.Sprintf(format, id)
+	}
+	return fmt.Sprintf("%s:%s:%s", row["service"], contentType, id)
+}
+
+// csvExportRows reads a CSV (or semicolon- or tab-separated) export. Its
+// header must name a URL or ID column; anything else, such as a plain URL
+// list, isn't an export.
+func csvExportRows(text string) ([]map[string]string, bool) {
+	header, _, _ := strings.Cut(text, "\n")
+	comma := ','
+	for _, sep := range []rune{';', '\t'} {
+		if strings.Count(header, string(sep)) > strings.Count(header, string(comma)) {
+			comma = sep
+		}
+	}
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, false
+	}
+
+	fields := make([]string, len(records[0]))
+	known := false
+	for i, name := range records[0] {
+		fields[i] = exportColumns[exportKey(name)]
+		if fields[i] == "url" || fields[i] == "id" || fields[i] == "album" || fields[i] == "playlist" {
+			known = true
+		}
+	}
+	if !known {
+		return nil, false
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		row := make(map[string]string)
+		for i, v := range rec {
+			if i < len(fields) && fields[i] != "" && row[fields[i]] == "" {
+				row[fields[i]] = strings.TrimSpace(v)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, true
+}
+
+// jsonExportRows reads a JSON export: an array of objects, or an object
+// holding one under a key such as "tracks", "items" or "favorites".
+func jsonExportRows(text string) ([]map[string]string, bool) {
+	var doc any
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false
+	}
+	items, ok := doc.([]any)
+	if obj, isObj := doc.(map[string]any); isObj {
+		for _, key := range []string{"tracks", "items", "favorites", "data", "albums", "playlists"} {
+			if items, ok = obj[key].([]any); ok {
+				break
+			}
+		}
+		if !ok {
+			items, ok = []any{obj}, true
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		obj, isObj := item.(map[string]any)
+		if !isObj {
+			continue
+		}
+		row := make(map[string]string)
+		for _, k := range slices.Sorted(maps.Keys(obj)) {
+			v := obj[k]
+			field := exportColumns[exportKey(k)]
+			if field == "" || row[field] != "" {
+				continue
+			}
+			switch v := v.(type) {
+			case string:
+				row[field] = strings.TrimSpace(v)
+			case json.Number:
+				row[field] = v.String()
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, true
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+)
+
+func TestParseImportList(t *testing.T) {
+	text := "#EXTM3U\n" +
+		"https://tidal.com/browse/album/1\n" +
+		"\n" +
+		"  https://open.qobuz.com/album/abc  \r\n" +
+		"# a comment\n" +
+		"https://tidal.com/browse/album/1\n"
+	want := []string{"https://tidal.com/browse/album/1", "https://open.qobuz.com/album/abc"}
+	if got := ParseImportList(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseImportList() = %v, want %v", got, want)
+	}
+	if got := ParseImportList(""); len(got) != 0 {
+		t.Errorf("ParseImportList(\"\") = %v, want none", got)
+	}
+}
+
+func TestImportSummary(t *testing.T) {
+	got := ImportSummary([]ImportResult{
+		{URL: "a", Queued: 10},
+		{URL: "b", Queued: 1},
+		{URL: "c", Error: "unknown URL format"},
+	})
+	if want := "2 queued (11 tracks), 1 failed"; got != want {
+		t.Errorf("ImportSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestTidalTracksFromSource(t *testing.T) {
+	got := TidalTracksFromSource([]core.SourceTrack{
+		{ID: "42", Title: "T", Artist: "A", Artists: []string{"A", "B"}, TrackNumber: 3},
+		{ID: "abc", Title: "No ID"},
+		{ID: "7", Title: "Elsewhere", Source: "deezer"},
+	})
+	if len(got) != 1 || got[0].ID != 42 || got[0].Artists != "A, B" || got[0].TrackNum != 3 {
+		t.Errorf("TidalTracksFromSource() = %+v", got)
+	}
+	if CheckDownloadable("tidal") != nil || CheckDownloadable("qobuz") != nil || CheckDownloadable("deezer") == nil {
+		t.Error("CheckDownloadable: only Tidal and Qobuz should be downloadable")
+	}
+}
+
+func TestImportURLs_Guards(t *testing.T) {
+	if _, err := (&App{}).ImportURLs("https://tidal.com/browse/album/1", "/tmp/out"); err == nil {
+		t.Error("ImportURLs() with nil downloadManager: want error, got nil")
+	}
+	a := &App{
+		downloadManager: core.NewDownloadManager(core.NewTidalHifiService(), 1),
+		sourceManager:   core.NewSourceManager(),
+	}
+	if _, err := a.ImportURLs("https://tidal.com/browse/album/1", ""); err == nil {
+		t.Error("ImportURLs() with empty outputDir: want error, got nil")
+	}
+}
+
+func TestParseImportList_Exports(t *testing.T) {
+	csvText := "\ufeffService,Type,ID,Title\n" +
+		"tidal,album,1,A\n" +
+		"Qobuz,track,abc,B\n" +
+		"deezer,,7,C\n" +
+		"tidal,album,1,A\n" +
+		"soundcloud,track,9,D\n"
+	want := []string{
+		"https://tidal.com/browse/album/1",
+		"https://open.qobuz.com/track/abc",
+		"https://www.deezer.com/track/7",
+		"soundcloud:track:9",
+	}
+	if got := ParseImportList(csvText); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseImportList(csv) = %v, want %v", got, want)
+	}
+
+	if got := ParseImportList("platform;album_id\nspotify;xyz\n"); !reflect.DeepEqual(got, []string{"https://open.spotify.com/album/xyz"}) {
+		t.Errorf("ParseImportList(semicolon csv) = %v", got)
+	}
+
+	jsonText := `{"favorites": [{"source": "tidal", "trackId": 12345678901}, {"url": "https://open.qobuz.com/album/x"}]}`
+	want = []string{"https://tidal.com/browse/track/12345678901", "https://open.qobuz.com/album/x"}
+	if got := ParseImportList(jsonText); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseImportList(json) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/incomplete"
+	"flacidal/internal/settings"
+)
+
+// =============================================================================
+// Incomplete Downloads (exposed to frontend)
+// =============================================================================
+
+// incompleteCleanupInterval is how often incomplete downloads older than
+// Settings.IncompleteCleanupDays are deleted.
+const incompleteCleanupInterval = 6 * time.Hour
+
+// ListIncompleteFiles lists the partial files and zero-byte FLACs in the
+// download folder and external library paths, linked to the failed
+// downloads they were probably left by.
+func (a *App) ListIncompleteFiles() ([]incomplete.File, error) {
+	return ListIncomplete(a.libraryRoots(), a.downloadManager)
+}
+
+// DeleteIncompleteFile deletes an incomplete file ListIncompleteFiles
+// reported.
+func (a *App) DeleteIncompleteFile(path string) error {
+	return incomplete.Remove(a.libraryRoots(), path)
+}
+
+// RequeueIncompleteFile deletes an incomplete file and downloads the
+// failed track it was left by again.
+func (a *App) RequeueIncompleteFile(path string) error {
+	if a.downloadManager == nil {
+		return fmt.Errorf("download manager not initialized")
+	}
+	trackID, err := RemoveForRequeue(a.libraryRoots(), a.downloadManager, path)
+	if err != nil {
+		return err
+	}
+	return a.RetryDownload(trackID)
+}
+
+// libraryRoots is LibraryRoots with the current config.
+func (a *App) libraryRoots() []string {
+	var external []string
+	if a.config != nil {
+		external = a.config.ExternalLibraryPaths
+	}
+	return LibraryRoots(a.GetDownloadFolder(), external)
+}
+
+// reportIncompleteCleanup logs a run of the automatic cleanup.
+func (a *App) reportIncompleteCleanup(removed []string, err error) {
+	if len(removed) > 0 {
+		a.logBuffer.Info(fmt.Sprintf("Removed %d incomplete downloads older than %d days", len(removed), a.currentSettings().IncompleteCleanupDays))
+	}
+	if err != nil {
+		a.logBuffer.Warn("Incomplete download cleanup: " + err.Error())
+	}
+}
+
+// LibraryRoots returns the folders scanned for incomplete downloads: the
+// download folder and the external library paths, without blanks or
+// duplicates. Shared by the desktop (Wails) and HTTP server APIs.
+func LibraryRoots(downloadFolder string, external []string) []string {
+	var roots []string
+	for _, r := range append([]string{downloadFolder}, external...) {
+		if r != "" && !slices.Contains(roots, r) {
+			roots = append(roots, r)
+		}
+	}
+	return roots
+}
+
+// ListIncomplete scans roots for incomplete files and matches them against
+// dm's failed downloads; dm may be nil. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func ListIncomplete(roots []string, dm *core.DownloadManager) ([]incomplete.File, error) {
+	files, err := incomplete.Scan(roots)
+	if files == nil {
+		files = []incomplete.File{}
+	}
+	if dm != nil {
+		var candidates []incomplete.Candidate
+		for _, job := range dm.GetFailedJobs() {
+			candidates = append(candidates, incomplete.Candidate{TrackID: job.TrackID, Artist: job.Artist, Title: job.Title})
+		}
+		incomplete.Match(files, candidates)
+	}
+	return files, err
+}
+
+// RemoveForRequeue deletes the incomplete file at path and returns the ID
+// of the failed download it was left by, for the caller to queue again.
+// Files no failed download matches are left alone. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func RemoveForRequeue(roots []string, dm *core.DownloadManager, path string) (int, error) {
+	files, _ := ListIncomplete(roots, dm) // a missing root doesn't matter here
+	for _, f := range files {
+		if f.Path != path {
+			continue
+		}
+		if f.TrackID == 0 {
+			return 0, fmt.Errorf("no failed download matches %s", path)
+		}
+		if err := incomplete.Remove(roots, path); err != nil {
+			return 0, err
+		}
+		return f.TrackID, nil
+	}
+	return 0, fmt.Errorf("%s is not an incomplete download", path)
+}
+
+// CleanupAge converts Settings.IncompleteCleanupDays into the age at which
+// incomplete downloads are deleted; 0 disables cleanup.
+func CleanupAge(s settings.Settings) time.Duration {
+	return time.Duration(s.IncompleteCleanupDays) * 24 * time.Hour
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"flacidal/internal/settings"
+)
+
+func TestLibraryRoots(t *testing.T) {
+	got := LibraryRoots("/music", []string{"", "/ext", "/music"})
+	if !slices.Equal(got, []string{"/music", "/ext"}) {
+		t.Errorf("roots = %v", got)
+	}
+	if got := LibraryRoots("", nil); len(got) != 0 {
+		t.Errorf("roots = %v, want none", got)
+	}
+}
+
+func TestListIncompleteAndRequeue(t *testing.T) {
+	root := t.TempDir()
+	part := filepath.Join(root, "Artist - Song.flac.part")
+	os.WriteFile(part, []byte("x"), 0644)
+
+	files, err := ListIncomplete([]string{root}, nil)
+	if err != nil || len(files) != 1 || files[0].Path != part || files[0].TrackID != 0 {
+		t.Fatalf("files = %+v, %v", files, err)
+	}
+	if _, err := RemoveForRequeue([]string{root}, nil, part); err == nil {
+		t.Error("RemoveForRequeue accepted a file no failed download matches")
+	}
+	if _, err := os.Stat(part); err != nil {
+		t.Error("unmatched file was deleted")
+	}
+}
+
+func TestCleanupAge(t *testing.T) {
+	if got := CleanupAge(settings.Settings{IncompleteCleanupDays: 7}); got != 7*24*time.Hour {
+		t.Errorf("age = %v", got)
+	}
+}
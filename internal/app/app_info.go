@@ -2,10 +2,11 @@ package app
 
 import (
 	"encoding/json"
-	"io"
 	"net/http"
 	"strings"
 	"time"
+
+	"flacidal/internal/timestamp"
 )
 
 // =============================================================================
@@ -17,6 +18,12 @@ func (a *App) GetAppVersion() string {
 	return a.version
 }
 
+// GetLocaleHint returns the locale and time zone the frontend formats API
+// timestamps (always UTC) with
+func (a *App) GetLocaleHint() timestamp.LocaleHint {
+	return timestamp.Hint()
+}
+
 // UpdateInfo represents available update information
 type UpdateInfo struct {
 	HasUpdate  bool   `json:"hasUpdate"`
@@ -53,12 +60,9 @@ func (a *App) CheckForUpdate() (*UpdateInfo, error) {
 		} `json:"assets"`
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return &UpdateInfo{HasUpdate: false}, nil
-	}
-
-	if err := json.Unmarshal(body, &release); err != nil {
+	// Decode straight from the body: the releases payload embeds the full
+	// changelog and asset list, so there's no point buffering it first.
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
 		return &UpdateInfo{HasUpdate: false}, nil
 	}
 
@@ -14,3 +14,12 @@ func TestGetAppVersion(t *testing.T) {
 		t.Errorf("GetAppVersion() = %q, want %q", got, "1.2.3")
 	}
 }
+
+func TestGetLocaleHint(t *testing.T) {
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	t.Setenv("TZ", "Europe/Paris")
+	a := &App{}
+	if got := a.GetLocaleHint(); got.Locale != "fr-FR" || got.TimeZone != "Europe/Paris" {
+		t.Errorf("GetLocaleHint() = %+v", got)
+	}
+}
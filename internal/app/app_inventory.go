@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/inventory"
+	"flacidal/internal/naming"
+)
+
+// =============================================================================
+// Library Export (exposed to frontend)
+// =============================================================================
+
+// ExportLibrary saves a report of every FLAC under folder (the download
+// folder when empty), in format "csv" or "json", where the user chooses.
+// Returns the path of the saved file, or empty string if cancelled.
+func (a *App) ExportLibrary(folder, format string) (string, error) {
+	if err := inventory.ValidateFormat(format); err != nil {
+		return "", err
+	}
+	r, err := LibraryReport(a.ctx, folder, a.GetDownloadFolder())
+	if err != nil {
+		return "", err
+	}
+	data, ext, err := r.Encode(format)
+	if err != nil {
+		return "", err
+	}
+
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultFilename: LibraryReportFileName(r, ext),
+	})
+	if err != nil || savePath == "" {
+		return "", err
+	}
+	if err := os.WriteFile(savePath, data, 0644); err != nil {
+		return "", err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Exported metadata of %d files in %s", len(r.Files), r.Folder))
+	}
+	return savePath, nil
+}
+
+// LibraryReport reads the metadata of every FLAC under folder, or under
+// downloadFolder when folder is empty. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func LibraryReport(ctx context.Context, folder, downloadFolder string) (*inventory.Report, error) {
+	if folder == "" {
+		folder = downloadFolder
+	}
+	if folder == "" {
+		return nil, errors.New("no folder to export")
+	}
+	return inventory.Scan(ctx, folder)
+}
+
+// LibraryReportFileName suggests a file name for r encoded with extension
+// ext, after the folder it lists.
+func LibraryReportFileName(r *inventory.Report, ext string) string {
+	name := naming.SanitizeComponent(filepath.Base(r.Folder))
+	if name == "" || name == "-" { // the root folder
+		name = "library"
+	}
+	return name + ".metadata" + ext
+}
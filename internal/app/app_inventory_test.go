@@ -0,0 +1,30 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"flacidal/internal/inventory"
+)
+
+func TestLibraryReport(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFLAC(t, filepath.Join(dir, "a.flac"), map[string]string{"TITLE": "A", "DATE": "1999"}, nil, 0)
+
+	r, err := LibraryReport(context.Background(), "", dir)
+	if err != nil || r.Folder != dir || len(r.Files) != 1 || r.Files[0].Title != "A" || r.Files[0].Year != "1999" {
+		t.Fatalf("LibraryReport = %+v, %v", r, err)
+	}
+	if _, err := LibraryReport(context.Background(), "", ""); err == nil {
+		t.Error("no folder: want an error")
+	}
+}
+
+func TestLibraryReportFileName(t *testing.T) {
+	for folder, want := range map[string]string{"/music/My: Library": "My- Library.metadata.csv", "/": "library.metadata.csv"} {
+		if got := LibraryReportFileName(&inventory.Report{Folder: folder}, ".csv"); got != want {
+			t.Errorf("LibraryReportFileName(%q) = %q, want %q", folder, got, want)
+		}
+	}
+}
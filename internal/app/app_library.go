@@ -0,0 +1,290 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // library.Driver
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/analysisstore"
+	"flacidal/internal/library"
+	"flacidal/internal/settings"
+)
+
+// libraryWatchInterval is how often the library folders are rescanned with
+// Settings.WatchLibrary on where their changes can't be watched.
+const libraryWatchInterval = 30 * time.Second
+
+// =============================================================================
+// Library Index (exposed to frontend)
+// =============================================================================
+
+// errNoLibrary is returned when the library index couldn't be opened.
+var errNoLibrary = errors.New("library index unavailable")
+
+// ScanLibrary brings the library index up to date with the download
+// folder and external library paths, emitting "library-scan-progress"
+// events ({"files"}) as files are found.
+func (a *App) ScanLibrary() (*library.ScanReport, error) {
+	return a.scanLibrary(a.ctx)
+}
+
+// scanLibrary is ScanLibrary, stopping early once ctx is cancelled; the
+// outcome is logged.
+func (a *App) scanLibrary(ctx context.Context) (*library.ScanReport, error) {
+	report, err := ScanLibrary(ctx, a.library, a.libraryRoots(), func(files int) {
+		runtime.EventsEmit(a.ctx, "library-scan-progress", map[string]int{"files": files})
+	})
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			a.logBuffer.Warn("Library scan failed: " + err.Error())
+		}
+		return nil, err
+	}
+	a.logBuffer.Info("Library scanned: " + LibraryScanSummary(report))
+	return report, nil
+}
+
+// watchLibrary indexes the files other programs put in the library while
+// Settings.WatchLibrary is on, until ctx is done, emitting a
+// "library-updated" event (the scan report) after each change.
+func (a *App) watchLibrary(ctx context.Context) {
+	a.library.Watch(ctx, libraryWatchInterval, func() []string {
+		return WatchedLibraryRoots(a.currentSettings(), a.libraryRoots())
+	}, func(report *library.ScanReport, err error) {
+		if err != nil {
+			a.logBuffer.Warn("Library watch failed: " + err.Error())
+			return
+		}
+		a.logBuffer.Info("Library changed: " + LibraryScanSummary(report))
+		runtime.EventsEmit(a.ctx, "library-updated", report)
+		if s := a.currentSettings(); s.AnalyzeNewFiles {
+			AnalyzeNewFiles(ctx, report.New, s, a.analyses, func(path string, r *AnalysisResult, err error) {
+				switch {
+				case err != nil:
+					a.logBuffer.Warn(fmt.Sprintf("Could not analyze %s: %v", filepath.Base(path), err))
+				case r.Truncated:
+					a.logBuffer.Warn(fmt.Sprintf("New file %s looks truncated: %s", r.FileName, r.TruncatedLabel))
+				case !r.IsTrueLossless && r.LossyCodecLabel != "":
+					a.logBuffer.Warn(fmt.Sprintf("New file %s: %s, likely from %s", r.FileName, r.VerdictLabel, r.LossyCodecLabel))
+				case !r.IsTrueLossless:
+					a.logBuffer.Warn(fmt.Sprintf("New file %s: %s", r.FileName, r.VerdictLabel))
+				case r.PaddedBitDepth:
+					a.logBuffer.Warn(fmt.Sprintf("New file %s: %s", r.FileName, r.BitDepthLabel))
+				case r.Clipping:
+					a.logBuffer.Warn(fmt.Sprintf("New file %s: %s", r.FileName, r.ClippingLabel))
+				case r.MQA:
+					a.logBuffer.Warn(fmt.Sprintf("New file %s: %s", r.FileName, r.MQALabel))
+				case r.FakeStereo || r.OutOfPhase:
+					a.logBuffer.Warn(fmt.Sprintf("New file %s: %s", r.FileName, r.StereoLabel))
+				}
+			})
+		}
+	})
+}
+
+// GetHistoryFile returns where the file trackID's download became is now,
+// following it through renames and moves, or "" if it is gone.
+func (a *App) GetHistoryFile(trackID int) (string, error) {
+	return HistoryFile(a.library, trackID)
+}
+
+// GetLibraryStatus returns the number of indexed tracks and the last scan.
+func (a *App) GetLibraryStatus() (*library.Status, error) {
+	return LibraryStatus(a.library)
+}
+
+// BrowseLibrary returns the page of indexed tracks q selects.
+func (a *App) BrowseLibrary(q library.Query) (*library.Page, error) {
+	return LibraryTracks(a.library, q)
+}
+
+// GetLibraryArtists returns the artists of the indexed tracks q selects.
+func (a *App) GetLibraryArtists(q library.Query) ([]library.Artist, error) {
+	return LibraryArtists(a.library, q)
+}
+
+// GetLibraryAlbums returns the albums of the indexed tracks q selects.
+func (a *App) GetLibraryAlbums(q library.Query) ([]library.Album, error) {
+	return LibraryAlbums(a.library, q)
+}
+
+// GetLibraryLabels returns the record labels of the indexed tracks q
+// selects.
+func (a *App) GetLibraryLabels(q library.Query) ([]library.Label, error) {
+	return LibraryLabels(a.library, q)
+}
+
+// GetLibraryDuplicates returns the groups of indexed files holding the
+// same track, by ISRC, audio MD5 or either (by "isrc", "md5" or ""), best
+// copy first. Deleting the rest is a "delete" batch.
+func (a *App) GetLibraryDuplicates(by string) ([]library.DuplicateGroup, error) {
+	return LibraryDuplicates(a.library, by)
+}
+
+// ScanLibrary updates idx with the FLACs under roots (see
+// library.Index.Scan). Shared by the desktop (Wails) and HTTP server APIs.
+func ScanLibrary(ctx context.Context, idx *library.Index, roots []string, progress func(files int)) (*library.ScanReport, error) {
+	if idx == nil {
+		return nil, errNoLibrary
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no download folder set")
+	}
+	return idx.Scan(ctx, roots, progress)
+}
+
+// LibraryScanSummary describes a scan in one line, for the logs.
+func LibraryScanSummary(r *library.ScanReport) string {
+	s := fmt.Sprintf("%d tracks in %.1fs: %d added, %d updated, %d removed", r.Files, r.Duration, r.Added, r.Updated, r.Removed)
+	if r.Failed > 0 {
+		s += fmt.Sprintf(", %d unreadable", r.Failed)
+	}
+	for _, e := range r.Errors {
+		s += "; " + e
+	}
+	return s
+}
+
+// LibraryStatus returns idx's status. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func LibraryStatus(idx *library.Index) (*library.Status, error) {
+	if idx == nil {
+		return nil, errNoLibrary
+	}
+	return idx.Status()
+}
+
+// LibraryTracks returns the page of idx's tracks q selects. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func LibraryTracks(idx *library.Index, q library.Query) (*library.Page, error) {
+	if idx == nil {
+		return nil, errNoLibrary
+	}
+	return idx.Tracks(q)
+}
+
+// LibraryArtists returns the artists of idx's tracks q selects. Shared by
+// the desktop (Wails) and HTTP server APIs.
+func LibraryArtists(idx *library.Index, q library.Query) ([]library.Artist, error) {
+	if idx == nil {
+		return nil, errNoLibrary
+	}
+	return idx.Artists(q)
+}
+
+// LibraryAlbums returns the albums of idx's tracks q selects. Shared by
+// the desktop (Wails) and HTTP server APIs.
+func LibraryAlbums(idx *library.Index, q library.Query) ([]library.Album, error) {
+	if idx == nil {
+		return nil, errNoLibrary
+	}
+	return idx.Albums(q)
+}
+
+// LibraryLabels returns the record labels of idx's tracks q selects.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func LibraryLabels(idx *library.Index, q library.Query) ([]library.Label, error) {
+	if idx == nil {
+		return nil, errNoLibrary
+	}
+	return idx.Labels(q)
+}
+
+// LibraryDuplicates returns idx's groups of files holding the same track.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func LibraryDuplicates(idx *library.Index, by string) ([]library.DuplicateGroup, error) {
+	if idx == nil {
+		return nil, errNoLibrary
+	}
+	return idx.Duplicates(by)
+}
+
+// LinkDownload links the history of trackID's download to the FLAC at
+// path in idx, so the history can find the file after it is moved or
+// renamed. Files outside roots, the library folders, aren't indexed and
+// are left alone, as is everything with no idx. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func LinkDownload(idx *library.Index, roots []string, trackID int, path string) error {
+	if idx == nil || !strings.EqualFold(filepath.Ext(path), ".flac") {
+		return nil
+	}
+	root := libraryRoot(roots, path)
+	if root == "" {
+		return nil
+	}
+	return idx.Link(strconv.Itoa(trackID), root, path)
+}
+
+// HistoryFile returns where the file trackID's download became is now, or
+// "" if idx doesn't know. Shared by the desktop (Wails) and HTTP server
+// APIs.
+func HistoryFile(idx *library.Index, trackID int) (string, error) {
+	if idx == nil {
+		return "", errNoLibrary
+	}
+	return idx.LinkedPath(strconv.Itoa(trackID))
+}
+
+// MoveInLibrary records in idx that the file at from is now at to; with
+// no idx it does nothing. Shared by the desktop (Wails) and HTTP server
+// APIs.
+func MoveInLibrary(idx *library.Index, from, to string) error {
+	if idx == nil {
+		return nil
+	}
+	return idx.Move(from, to)
+}
+
+// RemoveFromLibrary drops the files at paths from idx; with no idx it
+// does nothing. Shared by the desktop (Wails) and HTTP server APIs.
+func RemoveFromLibrary(idx *library.Index, paths ...string) error {
+	if idx == nil || len(paths) == 0 {
+		return nil
+	}
+	return idx.Remove(paths...)
+}
+
+// libraryRoot returns the innermost of roots holding path, or "".
+func libraryRoot(roots []string, path string) string {
+	var best string
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+			continue
+		}
+		if len(root) > len(best) {
+			best = root
+		}
+	}
+	return best
+}
+
+// WatchedLibraryRoots returns roots when s has Settings.WatchLibrary on,
+// else nil, which pauses library.Index.Watch. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func WatchedLibraryRoots(s settings.Settings, roots []string) []string {
+	if !s.WatchLibrary {
+		return nil
+	}
+	return roots
+}
+
+// AnalyzeNewFiles runs the quality analyzer on each of paths, one at a
+// time, saving the results in store and calling fn with each, until ctx
+// is done. Shared by the desktop (Wails) and HTTP server APIs.
+func AnalyzeNewFiles(ctx context.Context, paths []string, s settings.Settings, store *analysisstore.Store, fn func(path string, r *AnalysisResult, err error)) {
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return
+		}
+		r, err := Analyze(ctx, path, s, store)
+		fn(path, r, err)
+	}
+}
@@ -1,8 +1,17 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
 	core "github.com/kushiemoon-dev/flacidal-core"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/logging"
+	"flacidal/internal/timestamp"
 )
 
 // =============================================================================
@@ -14,7 +23,17 @@ func (a *App) GetLogs() []core.LogEntry {
 	if a.logBuffer == nil {
 		return []core.LogEntry{}
 	}
-	return a.logBuffer.GetAll()
+	return UTCLogEntries(a.logBuffer.GetAll(), time.Now())
+}
+
+// UTCLogEntries rewrites the entries' local timestamps as API timestamps
+// (UTC, see internal/timestamp) and returns entries. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func UTCLogEntries(entries []core.LogEntry, now time.Time) []core.LogEntry {
+	for i := range entries {
+		entries[i].Timestamp = timestamp.Normalize(entries[i].Timestamp, time.Local, now)
+	}
+	return entries
 }
 
 // ClearLogs clears all log entries
@@ -29,6 +48,68 @@ func (a *App) AddLog(level, message string) {
 	if a.logBuffer != nil {
 		entry := a.logBuffer.Add(level, message)
 		// Emit log event to frontend
-		runtime.EventsEmit(a.ctx, "log", entry)
+		runtime.EventsEmit(a.ctx, "log", UTCLogEntries([]core.LogEntry{entry}, time.Now())[0])
 	}
 }
+
+// GetLogLevels returns the per-component log levels ("*" is the default)
+// and the known component names
+func (a *App) GetLogLevels() map[string]interface{} {
+	return map[string]interface{}{
+		"levels":     a.logLevels.Snapshot(),
+		"components": logging.Components(),
+	}
+}
+
+// SetLogLevel changes the minimum level logged for component ("" or "*"
+// for the default) until the app restarts
+func (a *App) SetLogLevel(component, level string) error {
+	lvl, err := logging.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	a.logLevels.Set(component, lvl)
+	return nil
+}
+
+// logger returns a structured logger for component that writes to the
+// Terminal log buffer, filtered by the runtime log levels.
+func (a *App) logger(component string) *slog.Logger {
+	return logging.Component(logging.New(&a.logLevels, &bufferHandler{buf: a.logBuffer}), component)
+}
+
+// bufferHandler is a slog.Handler writing records to a core.LogBuffer as
+// "message key=value…" lines. Level filtering is left to logging.New.
+type bufferHandler struct {
+	buf   *core.LogBuffer
+	attrs []slog.Attr
+}
+
+func (h *bufferHandler) Enabled(context.Context, slog.Level) bool {
+	return h.buf != nil
+}
+
+func (h *bufferHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	write := func(attr slog.Attr) bool {
+		if attr.Key != logging.ComponentKey {
+			fmt.Fprintf(&b, " %s=%v", attr.Key, attr.Value)
+		}
+		return true
+	}
+	for _, attr := range h.attrs {
+		write(attr)
+	}
+	r.Attrs(write)
+	h.buf.Add(logging.LevelName(r.Level), b.String())
+	return nil
+}
+
+func (h *bufferHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &bufferHandler{buf: h.buf, attrs: append(append([]slog.Attr(nil), h.attrs...), attrs...)}
+}
+
+func (h *bufferHandler) WithGroup(string) slog.Handler {
+	return h
+}
@@ -2,8 +2,11 @@ package app
 
 import (
 	"testing"
+	"time"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/logging"
 )
 
 // Characterization tests for the "Logging Methods" section of app.go.
@@ -52,3 +55,50 @@ func TestAddLog_NilLogBufferNoOp(t *testing.T) {
 	a := &App{}
 	a.AddLog("info", "hello") // must not panic, must not touch a.ctx
 }
+
+func TestLogger_FollowsLogLevels(t *testing.T) {
+	lb := core.NewLogBuffer(10)
+	a := &App{logBuffer: lb}
+
+	a.logger(logging.Downloads).Info("Downloading", "track", 7)
+	got := lb.GetAll()
+	if len(got) != 1 || got[0].Message != "Downloading track=7" || got[0].Level != "info" {
+		t.Fatalf("entries = %+v", got)
+	}
+
+	if err := a.SetLogLevel(logging.Downloads, "warn"); err != nil {
+		t.Fatalf("SetLogLevel() error = %v", err)
+	}
+	a.logger(logging.Downloads).Info("quiet now")
+	a.logger(logging.HTTP).Info("other components unaffected")
+	if got := lb.GetAll(); len(got) != 2 || got[1].Message != "other components unaffected" {
+		t.Errorf("entries after SetLogLevel = %+v", got)
+	}
+
+	levels := a.GetLogLevels()["levels"].(map[string]string)
+	if levels[logging.Downloads] != "warn" {
+		t.Errorf("GetLogLevels() = %v", levels)
+	}
+	if err := a.SetLogLevel(logging.Downloads, "loud"); err == nil {
+		t.Error("SetLogLevel() with unknown level: want error, got nil")
+	}
+}
+
+func TestLogger_NilBuffer(t *testing.T) {
+	a := &App{}
+	a.logger(logging.Downloads).Warn("dropped") // must not panic
+}
+
+func TestUTCLogEntries(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := UTCLogEntries([]core.LogEntry{
+		{Timestamp: "2026-03-01T13:30:00+01:00"},
+		{Timestamp: "not a time"},
+	}, now)
+	if entries[0].Timestamp != "2026-03-01T12:30:00Z" {
+		t.Errorf("entries[0].Timestamp = %q", entries[0].Timestamp)
+	}
+	if entries[1].Timestamp != "not a time" {
+		t.Errorf("unparseable timestamp rewritten to %q", entries[1].Timestamp)
+	}
+}
@@ -1,10 +1,16 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/lyricscache"
+	"flacidal/internal/lyricsfile"
+	"flacidal/internal/lyricsmatch"
 )
 
 // =============================================================================
@@ -13,8 +19,7 @@ import (
 
 // FetchLyrics fetches lyrics for a track from LRCLIB
 func (a *App) FetchLyrics(title, artist string, durationSec int) (*core.Lyrics, error) {
-	client := core.NewLyricsClient()
-	lyrics, err := client.SearchLyrics(title, artist, durationSec)
+	lyrics, err := LookupLyrics(a.lyrics, nil, title, artist, durationSec)
 	if err != nil {
 		if a.logBuffer != nil {
 			a.logBuffer.Warn(fmt.Sprintf("Lyrics not found for %s - %s", artist, title))
@@ -35,19 +40,181 @@ func (a *App) FetchLyrics(title, artist string, durationSec int) (*core.Lyrics,
 
 // FetchLyricsForFile fetches lyrics based on a FLAC file's metadata
 func (a *App) FetchLyricsForFile(filePath string) (*core.Lyrics, error) {
-	meta, err := core.ReadFLACMetadata(filePath)
+	return LookupFileLyrics(a.lyrics, nil, filePath)
+}
+
+// LookupLyrics looks up lyrics for a track on LRCLIB with client (a new
+// one when nil), through cache: repeated lookups are answered from it, and
+// the ones it sends on are paced. Shared by the desktop (Wails) and HTTP
+// server APIs, and by tag imports.
+func LookupLyrics(cache *lyricscache.Cache, client *core.LyricsClient, title, artist string, duration int) (*core.Lyrics, error) {
+	if client == nil {
+		client = core.NewLyricsClient()
+	}
+	key := lyricscache.Key{Title: title, Artist: artist, Duration: duration}
+	return lyricscache.Lookup(cache, key, func() (*core.Lyrics, error) {
+		return client.SearchLyrics(title, artist, duration)
+	})
+}
+
+// LookupFileLyrics looks up lyrics for the FLAC file at path by its tags,
+// like LookupLyrics. Shared by the desktop (Wails) and HTTP server APIs.
+func LookupFileLyrics(cache *lyricscache.Cache, client *core.LyricsClient, path string) (*core.Lyrics, error) {
+	meta, err := core.ReadFLACMetadata(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read metadata: %w", err)
 	}
+	return lookupMetaLyrics(cache, client, meta)
+}
+
+// lookupMetaLyrics looks up lyrics for a file by its tags, meta.
+func lookupMetaLyrics(cache *lyricscache.Cache, client *core.LyricsClient, meta *core.FLACMetadata) (*core.Lyrics, error) {
+	if client == nil {
+		client = core.NewLyricsClient()
+	}
+	key := lyricscache.Key{Title: meta.Title, Artist: meta.Artist, Duration: int(meta.Duration)}
+	return lyricscache.Lookup(cache, key, func() (*core.Lyrics, error) {
+		return client.FetchLyricsForFile(meta)
+	})
+}
+
+// MatchLyrics describes lyrics found for a file fileSec seconds long and
+// judges whether they are its song's; see lyricsmatch.
+func MatchLyrics(lyrics *core.Lyrics, fileSec float64) lyricsmatch.Match {
+	m := lyricsmatch.Match{
+		Track:        lyrics.TrackName,
+		Artist:       lyrics.ArtistName,
+		Album:        lyrics.AlbumName,
+		Duration:     float64(lyrics.Duration),
+		FileDuration: fileSec,
+		HasPlain:     lyrics.Plain != "",
+		HasSynced:    lyrics.HasSynced,
+		Instrumental: lyrics.Instrumental || lyricsmatch.IsInstrumentalText(lyrics.Plain, lyrics.Synced),
+	}
+	m.Judge()
+	return m
+}
+
+// AcceptLyrics decides whether lyrics found for the FLAC at path, matched
+// as m, may be embedded: nil if so, else why not. Confident instrumental
+// matches get the file tagged instrumental (see lyricsmatch) and return
+// lyricsmatch.ErrInstrumental. Shared by the desktop (Wails) and HTTP
+// server APIs, and by tag imports.
+func AcceptLyrics(path string, m lyricsmatch.Match) error {
+	if err := m.Err(); err != nil {
+		return err
+	}
+	if m.Instrumental {
+		if err := lyricsmatch.MarkInstrumental(path); err != nil {
+			return fmt.Errorf("tag as instrumental: %w", err)
+		}
+		return lyricsmatch.ErrInstrumental
+	}
+	return nil
+}
 
-	client := core.NewLyricsClient()
-	return client.FetchLyricsForFile(meta)
+// MatchFileLyrics looks up lyrics for the FLAC file at path like
+// LookupFileLyrics, and judges whether they are its song's. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func MatchFileLyrics(cache *lyricscache.Cache, client *core.LyricsClient, path string) (*core.Lyrics, lyricsmatch.Match, error) {
+	meta, err := core.ReadFLACMetadata(path)
+	if err != nil {
+		return nil, lyricsmatch.Match{}, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	lyrics, err := lookupMetaLyrics(cache, client, meta)
+	if err != nil {
+		return nil, lyricsmatch.Match{}, err
+	}
+	return lyrics, MatchLyrics(lyrics, float64(meta.Duration)), nil
+}
+
+// LyricsPreview reports what fetching and embedding lyrics would do to a
+// file: the lyrics it would find, and whether they are confident enough to
+// be embedded, or why none would be.
+type LyricsPreview struct {
+	FilePath     string             `json:"filePath"`
+	Instrumental bool               `json:"instrumental,omitempty"` // tagged instrumental, so not looked up
+	Match        *lyricsmatch.Match `json:"match,omitempty"`
+	Error        string             `json:"error,omitempty"`
 }
 
-// EmbedLyricsToFile embeds lyrics into a FLAC file
+// PreviewLyrics looks up lyrics for files without embedding them, for
+// review before a bulk embed. Shared by the desktop (Wails) and HTTP server
+// APIs.
+func PreviewLyrics(cache *lyricscache.Cache, client *core.LyricsClient, files []string) []LyricsPreview {
+	previews := make([]LyricsPreview, len(files))
+	for i, path := range files {
+		previews[i].FilePath = path
+		if lyricsmatch.IsInstrumental(path) {
+			previews[i].Instrumental = true
+			continue
+		}
+		_, m, err := MatchFileLyrics(cache, client, path)
+		if err != nil {
+			previews[i].Error = err.Error()
+			continue
+		}
+		previews[i].Match = &m
+	}
+	return previews
+}
+
+// PreviewLyricsEmbed reports the lyrics FetchAndEmbedLyricsMultiple would
+// embed into files, and which it would refuse as unsure, without writing.
+func (a *App) PreviewLyricsEmbed(filePaths []string) []LyricsPreview {
+	return PreviewLyrics(a.lyrics, nil, filePaths)
+}
+
+// EmbedLyrics writes lyrics into the FLAC file at path with core's tagger,
+// keeping the SEEKTABLE, CUESHEET and other blocks its rebuild drops.
+func EmbedLyrics(path, plain, synced string) error {
+	return flacmeta.Preserve(path, func() error {
+		return core.NewFLACTagger().EmbedLyrics(path, plain, synced)
+	})
+}
+
+// SaveLyrics writes lyrics for the FLAC at path where out asks: into its
+// tags (see EmbedLyrics), into a .lrc sidecar (see lyricsfile.Write), or
+// both. Shared by the desktop (Wails) and HTTP server APIs.
+func SaveLyrics(out lyricsfile.Output, path, plain, synced string) error {
+	if out.Tags() {
+		if err := EmbedLyrics(path, plain, synced); err != nil {
+			return err
+		}
+	}
+	if out.File() {
+		if _, err := lyricsfile.Write(path, plain, synced); err != nil {
+			return fmt.Errorf("lyrics file: %w", err)
+		}
+	}
+	return nil
+}
+
+// SaveLyricsSidecar writes lyrics to a .lrc file next to a FLAC file, e.g.
+// "01 - Song.lrc" for "01 - Song.flac", and returns its path. The FLAC is
+// left untouched.
+func (a *App) SaveLyricsSidecar(filePath string, plain, synced string) (string, error) {
+	if filePath == "" {
+		return "", fmt.Errorf("file path required")
+	}
+	path, err := lyricsfile.Write(filePath, plain, synced)
+	if err != nil {
+		return "", err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Success(fmt.Sprintf("Lyrics saved to %s", filepath.Base(path)))
+	}
+	return path, nil
+}
+
+// EmbedLyricsToFile embeds lyrics into a FLAC file, or writes them to its
+// .lrc sidecar as well or instead, per Settings.LyricsOutput
 func (a *App) EmbedLyricsToFile(filePath string, plain, synced string) error {
-	tagger := core.NewFLACTagger()
-	err := tagger.EmbedLyrics(filePath, plain, synced)
+	s := a.currentSettings()
+	err := CheckStrict(s, filePath)
+	if err == nil {
+		err = SaveLyrics(s.LyricsOutput, filePath, plain, synced)
+	}
 	if err != nil {
 		if a.logBuffer != nil {
 			a.logBuffer.Error(fmt.Sprintf("Failed to embed lyrics: %s", err.Error()))
@@ -61,13 +228,31 @@ func (a *App) EmbedLyricsToFile(filePath string, plain, synced string) error {
 	return nil
 }
 
-// FetchAndEmbedLyrics fetches and embeds lyrics for a file in one operation
+// FetchAndEmbedLyrics fetches and embeds lyrics for a file in one operation.
+// Lyrics whose length is more than lyricsmatch.MaxDelta seconds off the
+// file's are returned unembedded, with an error. Instrumental tracks
+// return lyricsmatch.ErrInstrumental, and are tagged so they aren't looked
+// up again.
 func (a *App) FetchAndEmbedLyrics(filePath string) (*core.Lyrics, error) {
-	// Fetch lyrics based on file metadata
-	lyrics, err := a.FetchLyricsForFile(filePath)
+	// Refuse a broken file before looking its lyrics up
+	if err := CheckStrict(a.currentSettings(), filePath); err != nil {
+		return nil, err
+	}
+	if lyricsmatch.IsInstrumental(filePath) {
+		return nil, lyricsmatch.ErrInstrumental
+	}
+
+	// Fetch lyrics based on file metadata, and check they are its song's
+	lyrics, match, err := MatchFileLyrics(a.lyrics, nil, filePath)
 	if err != nil {
 		return nil, err
 	}
+	if err := AcceptLyrics(filePath, match); err != nil {
+		if a.logBuffer != nil && !errors.Is(err, lyricsmatch.ErrInstrumental) {
+			a.logBuffer.Warn(fmt.Sprintf("Lyrics not embedded to %s: %v", filepath.Base(filePath), err))
+		}
+		return lyrics, err
+	}
 
 	// Embed lyrics
 	err = a.EmbedLyricsToFile(filePath, lyrics.Plain, lyrics.Synced)
@@ -75,8 +260,9 @@ func (a *App) FetchAndEmbedLyrics(filePath string) (*core.Lyrics, error) {
 		return lyrics, err // Return lyrics even if embedding failed
 	}
 
-	// Save lyrics as separate file if enabled
-	if a.config != nil && a.config.SaveLyricsFile {
+	// Save lyrics as separate file if enabled, unless the .lrc sidecar of
+	// LyricsOutput is already written
+	if a.config != nil && a.config.SaveLyricsFile && !a.currentSettings().LyricsOutput.File() {
 		if saveErr := core.SaveLyricsFile(filePath, lyrics.Synced, lyrics.Plain); saveErr != nil {
 			a.logBuffer.Warn("Failed to save lyrics file: " + saveErr.Error())
 		}
@@ -96,7 +282,10 @@ func (a *App) FetchAndEmbedLyricsMultiple(filePaths []string) []map[string]inter
 		}
 
 		lyrics, err := a.FetchAndEmbedLyrics(filePath)
-		if err != nil {
+		if errors.Is(err, lyricsmatch.ErrInstrumental) {
+			result["success"] = true
+			result["instrumental"] = true
+		} else if err != nil {
 			result["error"] = err.Error()
 		} else {
 			result["success"] = true
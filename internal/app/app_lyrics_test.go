@@ -69,3 +69,19 @@ func TestFetchAndEmbedLyricsMultiple_InvalidFiles(t *testing.T) {
 		t.Errorf("FetchAndEmbedLyricsMultiple() = %v, want an 'error' key", got[0])
 	}
 }
+
+// BenchmarkEmbedLyricsToFile measures a full tagger rebuild (read, re-encode
+// metadata, rewrite audio) on a file with a typical 1 MB embedded cover.
+func BenchmarkEmbedLyricsToFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "bench.flac")
+	writeTestFLAC(b, path, testTags, make([]byte, 1024*1024), 8*1024*1024)
+	a := &App{}
+
+	b.SetBytes(8 * 1024 * 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := a.EmbedLyricsToFile(path, "plain lyrics", "[00:01.00] synced lyrics"); err != nil {
+			b.Fatalf("EmbedLyricsToFile() error = %v", err)
+		}
+	}
+}
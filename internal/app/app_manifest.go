@@ -0,0 +1,133 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/manifest"
+	"flacidal/internal/naming"
+	"flacidal/internal/quality"
+	"flacidal/internal/timestamp"
+)
+
+// =============================================================================
+// Download Manifest Export (exposed to frontend)
+// =============================================================================
+
+// ExportDownloadManifest resolves rawURL's tracks and their stream URLs into
+// a manifest (format "json" or "aria2") saved where the user chooses, for
+// running the transfer with an external downloader. Returns the path of the
+// saved file, or empty string if cancelled.
+func (a *App) ExportDownloadManifest(rawURL, format string) (string, error) {
+	if a.sourceManager == nil {
+		return "", fmt.Errorf("source manager not initialized")
+	}
+	config := a.config
+	if config == nil {
+		config = &core.Config{}
+	}
+	if err := manifest.ValidateFormat(format); err != nil {
+		return "", err
+	}
+	m, err := BuildManifest(a.sourceManager, manifest.TidalResolver{Endpoints: TidalEndpoints(config)}, config, rawURL)
+	if err != nil {
+		return "", err
+	}
+	data, ext, err := m.Encode(format)
+	if err != nil {
+		return "", err
+	}
+
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultFilename: ManifestFileName(m, ext),
+	})
+	if err != nil || savePath == "" {
+		return "", err
+	}
+	if err := os.WriteFile(savePath, data, 0644); err != nil {
+		return "", err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Exported manifest for %s: %d of %d stream URLs resolved", m.Title, m.Resolved(), len(m.Tracks)))
+	}
+	return savePath, nil
+}
+
+// BuildManifest resolves rawURL through sm (see ResolveContent) and looks
+// up each Tidal track's stream URL in config's download quality. Tracks
+// from other sources, and streams that aren't a single plain file, are
+// listed with an error instead of a URL. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func BuildManifest(sm *core.SourceManager, resolver manifest.TidalResolver, config *core.Config, rawURL string) (*manifest.Manifest, error) {
+	content, err := ResolveContent(sm, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	q, _ := quality.ParseOrDefault(config.DownloadQuality)
+	format := config.FileNameFormat
+	if format == "" {
+		format = "{artist} - {title}"
+	}
+
+	m := &manifest.Manifest{
+		URL:       content.URL,
+		Source:    content.Source,
+		Type:      content.Type,
+		Title:     content.Title,
+		Quality:   q.String(),
+		CreatedAt: timestamp.Format(time.Now()),
+		Tracks:    make([]manifest.Entry, len(content.Tracks)),
+	}
+	for i, t := range content.Tracks {
+		e := manifest.Entry{
+			ID:          t.ID,
+			Title:       t.Title,
+			Artist:      t.Artist,
+			Album:       t.Album,
+			ISRC:        t.ISRC,
+			TrackNumber: t.TrackNumber,
+			DiscNumber:  t.DiscNumber,
+			Duration:    t.Duration,
+		}
+		if content.Source == "tidal" {
+			e.StreamURL, e.MimeType, err = resolver.Resolve(t.ID, q.String())
+			if err != nil {
+				e.Error = err.Error()
+			}
+		} else {
+			e.Error = "stream URLs are only resolved for Tidal tracks"
+		}
+		name := naming.SanitizeComponent(naming.Render(format, naming.Values{
+			Title: t.Title, Artist: t.Artist, Album: t.Album, ISRC: t.ISRC, Source: content.Source,
+			ID: t.ID, Track: t.TrackNumber, Disc: t.DiscNumber, PlaylistIndex: i + 1,
+		}))
+		e.FileName = name + streamExtension(e.MimeType)
+		m.Tracks[i] = e
+	}
+	return m, nil
+}
+
+// ManifestFileName suggests a file name for m encoded with extension ext.
+func ManifestFileName(m *manifest.Manifest, ext string) string {
+	name := naming.SanitizeComponent(m.Title)
+	if name == "" {
+		name = "manifest"
+	}
+	return name + ".manifest" + ext
+}
+
+// streamExtension maps a stream's MIME type to a file extension.
+func streamExtension(mimeType string) string {
+	switch {
+	case strings.Contains(mimeType, "mp4"), strings.Contains(mimeType, "aac"):
+		return ".m4a"
+	case strings.Contains(mimeType, "mpeg"):
+		return ".mp3"
+	}
+	return ".flac"
+}
@@ -1,6 +1,10 @@
 package app
 
-import core "github.com/kushiemoon-dev/flacidal-core"
+import (
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/textmatch"
+)
 
 // =============================================================================
 // Matcher Methods (exposed to frontend)
@@ -11,7 +15,12 @@ func (a *App) MatchPlaylistTracks(tracks []core.TidalTrack) []core.MatchResult {
 	if a.matcher == nil {
 		return nil
 	}
-	return a.matcher.MatchPlaylist(tracks)
+	results := a.matcher.MatchPlaylist(tracks)
+	mode := a.currentSettings().MatchNormalization
+	for i := range results {
+		results[i] = RetryNormalized(a.matcher.MatchTrack, results[i], mode)
+	}
+	return results
 }
 
 // MatchSingleTrack matches a single track
@@ -19,5 +28,33 @@ func (a *App) MatchSingleTrack(track core.TidalTrack) core.MatchResult {
 	if a.matcher == nil {
 		return core.MatchResult{TidalTrack: track, Matched: false, MatchMethod: "none"}
 	}
-	return a.matcher.MatchTrack(track)
+	return RetryNormalized(a.matcher.MatchTrack, a.matcher.MatchTrack(track), a.currentSettings().MatchNormalization)
+}
+
+// RetryNormalized gives a failed match a second attempt with the track's
+// title and artists folded by mode (see textmatch.Fold): flacidal-core's
+// Matcher compares text as-is, so accented, full-width or original-script
+// titles miss catalogs that spell them otherwise. Results that matched, and
+// tracks folding doesn't change, are returned untouched. A successful
+// retry keeps the original track and reports its method with a
+// "+normalized" suffix.
+func RetryNormalized(match func(core.TidalTrack) core.MatchResult, res core.MatchResult, mode textmatch.Mode) core.MatchResult {
+	if res.Matched {
+		return res
+	}
+	track := res.TidalTrack
+	folded := track
+	folded.Title = textmatch.Fold(track.Title, mode)
+	folded.Artist = textmatch.Fold(track.Artist, mode)
+	folded.Artists = textmatch.Fold(track.Artists, mode)
+	if folded.Title == track.Title && folded.Artist == track.Artist && folded.Artists == track.Artists {
+		return res
+	}
+	retry := match(folded)
+	if !retry.Matched {
+		return res
+	}
+	retry.TidalTrack = track
+	retry.MatchMethod += "+normalized"
+	return retry
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/textmatch"
 )
 
 // Characterization tests for the "Matcher Methods" section of app.go.
@@ -29,3 +31,30 @@ func TestMatchSingleTrack_NilMatcher(t *testing.T) {
 		t.Errorf("MatchSingleTrack() with nil matcher = %+v, want %+v", got, want)
 	}
 }
+
+func TestRetryNormalized(t *testing.T) {
+	var queried []string
+	match := func(track core.TidalTrack) core.MatchResult {
+		queried = append(queried, track.Title)
+		return core.MatchResult{TidalTrack: track, Matched: track.Title == "Joga", MatchMethod: "search"}
+	}
+	track := core.TidalTrack{ID: 1, Title: "Jóga", Artist: "Björk"}
+
+	got := RetryNormalized(match, core.MatchResult{TidalTrack: track, MatchMethod: "search"}, textmatch.Default)
+	if !got.Matched || got.TidalTrack != track || got.MatchMethod != "search+normalized" {
+		t.Errorf("retry = %+v, want a normalized match keeping the original track", got)
+	}
+	if len(queried) != 1 || queried[0] != "Joga" {
+		t.Errorf("queried %v, want the folded title", queried)
+	}
+
+	// Nothing to fold, or accents kept: no second attempt.
+	queried = nil
+	plain := core.MatchResult{TidalTrack: core.TidalTrack{Title: "Plain"}}
+	if got := RetryNormalized(match, plain, textmatch.Default); got != plain || len(queried) != 0 {
+		t.Errorf("plain title: %+v, queried %v", got, queried)
+	}
+	if got := RetryNormalized(match, core.MatchResult{TidalTrack: track}, textmatch.KeepAccents); got.Matched || len(queried) != 0 {
+		t.Errorf("KeepAccents: %+v, queried %v", got, queried)
+	}
+}
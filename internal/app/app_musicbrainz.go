@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"flacidal/internal/musicbrainz"
+	"flacidal/internal/settings"
+	"flacidal/internal/tagedit"
+)
+
+// =============================================================================
+// MusicBrainz Tagging (exposed to frontend)
+// =============================================================================
+
+// PreviewMusicBrainzTags looks files up on MusicBrainz and lists, per file,
+// the tags MusicBrainzTags would write.
+func (a *App) PreviewMusicBrainzTags(files []string) []tagedit.Result {
+	return MusicBrainzTags(a.ctx, a.currentSettings(), files, true)
+}
+
+// MusicBrainzTags looks files up on MusicBrainz, by ISRC or by artist and
+// title, and writes their MusicBrainz ID tags along with any missing year,
+// genre and label.
+func (a *App) MusicBrainzTags(files []string) []tagedit.Result {
+	results := MusicBrainzTags(a.ctx, a.currentSettings(), files, false)
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Tagged %d/%d files from MusicBrainz", TagsWritten(results), len(files)))
+	}
+	return results
+}
+
+// MusicBrainzTags tags files from MusicBrainz (see
+// musicbrainz.Client.Enrich), refusing broken files in strict mode. Lookups
+// are rate limited, so this takes about a second per file. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func MusicBrainzTags(ctx context.Context, s settings.Settings, files []string, dryRun bool) []tagedit.Result {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return StrictResults(s, files, func(files []string) []tagedit.Result {
+		results := make([]tagedit.Result, len(files))
+		for i, f := range files {
+			results[i], _ = musicbrainz.Default.Enrich(ctx, f, dryRun)
+		}
+		return results
+	}, func(path, reason string) tagedit.Result {
+		return tagedit.Result{Path: path, Changes: []tagedit.Change{}, Error: reason}
+	})
+}
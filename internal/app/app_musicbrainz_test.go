@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"flacidal/internal/musicbrainz"
+	"flacidal/internal/settings"
+)
+
+func TestMusicBrainzTags(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/isrc/USABC0000001" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"recordings": [{"id": "rec-1", "title": "Song", "artist-credit": [{"name": "A", "artist": {"id": "art-a"}}]}]}`))
+	}))
+	defer srv.Close()
+	saved := musicbrainz.Default
+	musicbrainz.Default = &musicbrainz.Client{BaseURL: srv.URL, Interval: time.Millisecond}
+	t.Cleanup(func() { musicbrainz.Default = saved })
+
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path, map[string]string{"TITLE": "Song", "ISRC": "USABC0000001"}, nil, 64)
+
+	results := MusicBrainzTags(context.Background(), settings.Settings{StrictValidation: true}, []string{path}, false)
+	if results[0].Error == "" || results[0].Written {
+		t.Errorf("strict mode: %+v, want a refusal", results[0])
+	}
+	results = MusicBrainzTags(context.Background(), settings.Settings{}, []string{path}, true)
+	if results[0].Error != "" || results[0].Written || len(results[0].Changes) != 2 {
+		t.Errorf("preview: %+v", results[0])
+	}
+	results = MusicBrainzTags(context.Background(), settings.Settings{}, []string{path}, false)
+	if !results[0].Written || TagsWritten(results) != 1 {
+		t.Errorf("tagging: %+v", results[0])
+	}
+}
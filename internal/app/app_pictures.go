@@ -0,0 +1,263 @@
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // decoders for image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/fileerr"
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/settings"
+)
+
+// PictureInfo describes one picture embedded in a FLAC file.
+type PictureInfo struct {
+	Index       int    `json:"index"` // position among the file's pictures, for RemovePicture
+	Type        uint32 `json:"type"`
+	TypeName    string `json:"typeName"`
+	MIMEType    string `json:"mimeType"`
+	Description string `json:"description"`
+	Width       uint32 `json:"width"`
+	Height      uint32 `json:"height"`
+	Size        int    `json:"size"`
+	Data        string `json:"data"` // base64
+}
+
+// =============================================================================
+// Embedded Pictures (exposed to frontend)
+// =============================================================================
+
+// ListFilePictures lists every picture embedded in a FLAC file.
+func (a *App) ListFilePictures(path string) ([]PictureInfo, error) {
+	return ListPictures(path)
+}
+
+// AddFilePicture embeds a base64-encoded image of PICTURE type pictureType
+// (e.g. 4 for a back cover) in a FLAC file, keeping its other pictures.
+func (a *App) AddFilePicture(path, data string, pictureType uint32, description string) error {
+	img, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return fmt.Errorf("invalid image data: %w", err)
+	}
+	return AddPicture(a.currentSettings(), path, img, pictureType, description)
+}
+
+// RemoveFilePicture removes the picture at index (see ListFilePictures)
+// from a FLAC file.
+func (a *App) RemoveFilePicture(path string, index int) error {
+	return RemovePicture(a.currentSettings(), path, index)
+}
+
+// SaveCoverArt writes a FLAC's embedded cover to outputPath, or beside it
+// when outputPath is empty, returning the path written (see SaveCover).
+func (a *App) SaveCoverArt(filePath, outputPath string) (string, error) {
+	return SaveCover(filePath, outputPath)
+}
+
+// ExtractFolderCovers writes a cover image beside the FLACs in every folder
+// under folder (see SaveFolderCovers).
+func (a *App) ExtractFolderCovers(folder string, overwrite bool) ([]FolderCover, error) {
+	return SaveFolderCovers(folder, overwrite)
+}
+
+// ListPictures reads the pictures embedded in the FLAC at path. Shared by
+// the desktop (Wails) and HTTP server APIs.
+func ListPictures(path string) ([]PictureInfo, error) {
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	pics, err := f.Pictures()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]PictureInfo, len(pics))
+	for i, p := range pics {
+		infos[i] = PictureInfo{
+			Index:       i,
+			Type:        p.Type,
+			TypeName:    flacmeta.PictureTypeName(p.Type),
+			MIMEType:    p.MIME,
+			Description: p.Description,
+			Width:       p.Width,
+			Height:      p.Height,
+			Size:        len(p.Data),
+			Data:        base64.StdEncoding.EncodeToString(p.Data),
+		}
+	}
+	return infos, nil
+}
+
+// AddPicture embeds img in the FLAC at path as a picture of pictureType,
+// refusing broken files in strict mode. The MIME type and dimensions are
+// read from the image itself. File icons (types 1 and 2) may only appear
+// once and replace an existing one; other types are added alongside.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func AddPicture(s settings.Settings, path string, img []byte, pictureType uint32, description string) error {
+	if !flacmeta.ValidPictureType(pictureType) {
+		return fmt.Errorf("unknown picture type %d", pictureType)
+	}
+	mime := http.DetectContentType(img)
+	if !strings.HasPrefix(mime, "image/") {
+		return errors.New("not an image")
+	}
+	if err := CheckStrict(s, path); err != nil {
+		return err
+	}
+	p := flacmeta.Picture{Type: pictureType, MIME: mime, Description: description, Data: img}
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(img)); err == nil {
+		p.Width, p.Height = uint32(cfg.Width), uint32(cfg.Height)
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return fileerr.Wrap(err)
+	}
+	if pictureType == 1 || pictureType == 2 {
+		f.SetPicture(p)
+	} else {
+		f.AddPicture(p)
+	}
+	return fileerr.Wrap(f.Save())
+}
+
+// RemovePicture removes the picture at index from the FLAC at path,
+// refusing broken files in strict mode. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func RemovePicture(s settings.Settings, path string, index int) error {
+	if err := CheckStrict(s, path); err != nil {
+		return err
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return fileerr.Wrap(err)
+	}
+	if err := f.RemovePicture(index); err != nil {
+		return err
+	}
+	return fileerr.Wrap(f.Save())
+}
+
+// CoverFileName is the name SaveCover and SaveFolderCovers give a cover
+// saved beside the music, before the extension matching its format.
+const CoverFileName = "cover"
+
+// coverExts maps the image formats a cover can be saved as to extensions.
+var coverExts = map[string]string{"image/jpeg": ".jpg", "image/png": ".png"}
+
+// FolderCover is what SaveFolderCovers did for one folder.
+type FolderCover struct {
+	Folder  string `json:"folder"`
+	Path    string `json:"path,omitempty"`    // cover file written or already there
+	Skipped bool   `json:"skipped,omitempty"` // a cover file existed and was kept
+	Error   string `json:"error,omitempty"`
+}
+
+// SaveCover writes the front cover embedded in the FLAC at path (or its
+// first picture, when it has no front cover) to outputPath and returns the
+// path written. An empty outputPath, or an existing folder, saves it there
+// as CoverFileName; an outputPath without an extension gets the one
+// matching the image's format. Shared by the desktop (Wails) and HTTP
+// server APIs.
+func SaveCover(path, outputPath string) (string, error) {
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return "", fileerr.Wrap(err)
+	}
+	pics, err := f.Pictures()
+	if err != nil {
+		return "", err
+	}
+	if len(pics) == 0 {
+		return "", errors.New("no embedded cover art")
+	}
+	pic := pics[0]
+	for _, p := range pics {
+		if p.Type == flacmeta.PictureFrontCover {
+			pic = p
+			break
+		}
+	}
+	ext, ok := coverExts[pic.MIME]
+	if !ok {
+		return "", fmt.Errorf("cover is %s, not JPEG or PNG", pic.MIME)
+	}
+
+	if outputPath == "" {
+		outputPath = filepath.Dir(path)
+	}
+	if info, err := os.Stat(outputPath); err == nil && info.IsDir() {
+		outputPath = filepath.Join(outputPath, CoverFileName)
+	}
+	if filepath.Ext(outputPath) == "" {
+		outputPath += ext
+	}
+	if err := os.WriteFile(outputPath, pic.Data, 0644); err != nil {
+		return "", fileerr.Wrap(err)
+	}
+	return outputPath, nil
+}
+
+// SaveFolderCovers saves a CoverFileName image in every folder under root
+// holding FLACs, from the first of its files with embedded art. Folders
+// that already have a cover.jpg or cover.png are skipped unless overwrite
+// is set. Shared by the desktop (Wails) and HTTP server APIs.
+func SaveFolderCovers(root string, overwrite bool) ([]FolderCover, error) {
+	files, err := core.ListFLACFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	var folders []string
+	byFolder := make(map[string][]string)
+	for _, f := range files {
+		dir := filepath.Dir(f.Path)
+		if _, ok := byFolder[dir]; !ok {
+			folders = append(folders, dir)
+		}
+		byFolder[dir] = append(byFolder[dir], f.Path)
+	}
+
+	results := make([]FolderCover, 0, len(folders))
+	for _, dir := range folders {
+		res := FolderCover{Folder: dir}
+		if existing := existingCover(dir); existing != "" && !overwrite {
+			res.Path, res.Skipped = existing, true
+			results = append(results, res)
+			continue
+		}
+		for _, path := range byFolder[dir] {
+			written, err := SaveCover(path, dir)
+			if err == nil {
+				res.Path, res.Error = written, ""
+				break
+			}
+			if res.Error == "" {
+				res.Error = err.Error()
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// existingCover returns the cover file SaveCover would write in dir, if
+// one is already there.
+func existingCover(dir string) string {
+	for _, ext := range []string{".jpg", ".png"} {
+		path := filepath.Join(dir, CoverFileName+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/settings"
+)
+
+func TestPictures_AddListRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path, nil, []byte("front"), 64)
+
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := AddPicture(settings.Settings{}, path, img.Bytes(), flacmeta.PictureArtist, "band"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pics, err := ListPictures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pics) != 3 || pics[0].Type != flacmeta.PictureFrontCover {
+		t.Fatalf("pictures = %+v", pics)
+	}
+	if p := pics[2]; p.Index != 2 || p.TypeName != "Artist" || p.MIMEType != "image/png" || p.Width != 3 || p.Height != 2 || p.Description != "band" {
+		t.Errorf("added picture = %+v", p)
+	}
+
+	if err := RemovePicture(settings.Settings{}, path, 0); err != nil {
+		t.Fatal(err)
+	}
+	if pics, _ = ListPictures(path); len(pics) != 2 || pics[0].Type != flacmeta.PictureArtist {
+		t.Errorf("after removal: %+v", pics)
+	}
+}
+
+func TestAddPicture_Rejects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path, nil, nil, 64)
+	if err := AddPicture(settings.Settings{}, path, []byte("plain text"), flacmeta.PictureFrontCover, ""); err == nil {
+		t.Error("accepted a non-image")
+	}
+	if err := AddPicture(settings.Settings{}, path, []byte("\x89PNG\r\n\x1a\n"), 42, ""); err == nil {
+		t.Error("accepted an unknown picture type")
+	}
+	if err := RemovePicture(settings.Settings{StrictValidation: true}, path, 0); err == nil {
+		t.Error("strict mode edited a broken file")
+	}
+}
+
+func TestSaveCover(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "track.flac")
+	writeTestFLAC(t, path, nil, []byte("front"), 64)
+
+	written, err := SaveCover(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "cover.jpg"); written != want {
+		t.Errorf("wrote %s, want %s", written, want)
+	}
+	if data, _ := os.ReadFile(written); string(data) != "front" {
+		t.Errorf("cover = %q", data)
+	}
+	if written, err := SaveCover(path, filepath.Join(dir, "art")); err != nil || written != filepath.Join(dir, "art.jpg") {
+		t.Errorf("SaveCover(art) = %s, %v", written, err)
+	}
+
+	bare := filepath.Join(dir, "bare.flac")
+	writeTestFLAC(t, bare, nil, nil, 64)
+	if _, err := SaveCover(bare, ""); err == nil {
+		t.Error("a file without art should fail")
+	}
+}
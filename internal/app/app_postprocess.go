@@ -0,0 +1,359 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/fileerr"
+	"flacidal/internal/musicbrainz"
+	"flacidal/internal/naming"
+	"flacidal/internal/postprocess"
+)
+
+// RememberTidalTracks records the queue-time metadata FinishDownload needs
+// for each track, including its 1-based position in the batch for the
+// {playlistindex} token and, when contentType is "playlist", its playlist
+// position for {playlistnum} and the UsePlaylistOrder setting. Albums from
+// the proxy often leave TotalDiscs unset, so it falls back to the highest
+// disc number in the batch. Albums that look like various-artists
+// compilations (postprocess.IsCompilation) are tagged and filed as such.
+// Shared by the desktop (Wails) and HTTP server APIs (same sharing pattern
+// as ConvertTidalSearchResults in app_search.go).
+func RememberTidalTracks(reg *postprocess.Registry, tracks []core.TidalTrack, contentType string) {
+	playlist := contentType == "playlist"
+	maxDisc := 0
+	artists := make([]string, len(tracks))
+	for i, t := range tracks {
+		if t.DiscNumber > maxDisc {
+			maxDisc = t.DiscNumber
+		}
+		artists[i] = t.Artist
+	}
+	remembered := make([]postprocess.Track, len(tracks))
+	for i, t := range tracks {
+		total := t.TotalDiscs
+		if total == 0 {
+			total = maxDisc
+		}
+		year := t.ReleaseDate
+		if len(year) > 4 {
+			year = year[:4]
+		}
+		track := postprocess.Track{
+			ID:            strconv.Itoa(t.ID),
+			Title:         t.Title,
+			Version:       t.Version,
+			Artist:        t.Artist,
+			Artists:       splitArtists(t.Artist, t.Artists),
+			AlbumArtist:   t.AlbumArtist,
+			Album:         t.Album,
+			Label:         t.Label,
+			Copyright:     t.Copyright,
+			Year:          year,
+			ISRC:          t.ISRC,
+			TrackNumber:   t.TrackNum,
+			DiscNumber:    t.DiscNumber,
+			TotalDiscs:    total,
+			PlaylistIndex: i + 1,
+		}
+		if playlist {
+			track.PlaylistNum = i + 1
+			track.PlaylistTotal = len(tracks)
+		}
+		remembered[i] = track
+	}
+	if contentType == "album" && len(tracks) > 0 && postprocess.IsCompilation(tracks[0].AlbumArtist, artists) {
+		postprocess.MarkCompilation(remembered)
+	}
+	for i, t := range tracks {
+		reg.Remember(t.ID, remembered[i])
+	}
+}
+
+// RememberSourceTracks is RememberTidalTracks for tracks queued from any
+// source as core.SourceTracks, such as Qobuz's: it records the metadata
+// ImportTracks derives from them under their numeric IDs, which the
+// download manager reports them by. The queue carries no album artist,
+// so albums aren't checked for being compilations. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func RememberSourceTracks(reg *postprocess.Registry, tracks []core.SourceTrack, source, contentType string) {
+	if contentType == "album" {
+		contentType = ""
+	}
+	remembered := ImportTracks(&ResolvedContent{Source: source, Type: contentType, Tracks: tracks})
+	for i, t := range tracks {
+		if id, err := strconv.Atoi(t.ID); err == nil {
+			reg.Remember(id, remembered[i])
+		}
+	}
+}
+
+// splitArtists turns a TidalTrack's comma-joined Artists into one name per
+// contributing artist, for the multi-valued ARTIST tag. The main artist is
+// matched first so names containing a comma ("Tyler, The Creator") survive.
+// Lists shortened for display ("A, B +2", see ConvertTidalSearchResults)
+// and single artists yield nil: the tagger then leaves core's ARTIST alone.
+func splitArtists(artist, artists string) []string {
+	rest := strings.TrimSpace(artists)
+	var names []string
+	if after, ok := strings.CutPrefix(rest, artist+","); ok && artist != "" {
+		names = append(names, artist)
+		rest = after
+	}
+	for _, name := range strings.Split(rest, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) < 2 || displayTruncated.MatchString(names[len(names)-1]) {
+		return nil
+	}
+	return names
+}
+
+// displayTruncated matches the " +N" suffix of a shortened artist list.
+var displayTruncated = regexp.MustCompile(`(^|\s)\+\d+$`)
+
+// FinishDownload runs FLACidal's own post-download steps for one progress
+// event. On "completed" it tags and, if configured, renames or moves the
+// file, updating result.FilePath so every later consumer (logs, history,
+// the frontend) sees the final location, then trims silence and adds
+// MusicBrainz and PERFORMER tags when the TrimSilence, MusicBrainzTagging
+// and PerformerTags settings are on. A FLAC download that fails the full
+// decode check with VerifyDownloads on is left as it is and reported
+// instead. Failed and cancelled jobs, downloads RejectBroken failed
+// included, just drop their metadata. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func FinishDownload(reg *postprocess.Registry, opts postprocess.Options, trackID int, status string, result *core.DownloadResult) error {
+	switch status {
+	case "completed":
+		t, ok := reg.Take(trackID)
+		if !ok || result == nil || result.FilePath == "" {
+			return nil
+		}
+		if opts.VerifyDownloads && strings.EqualFold(filepath.Ext(result.FilePath), ".flac") {
+			if err := VerifyDownload(context.Background(), result.FilePath); err != nil {
+				return err
+			}
+		}
+		t.Quality = result.Quality
+		t.Source = result.Source
+		t.Downloaded = time.Now()
+		path, err := postprocess.Apply(result.FilePath, t, opts)
+		result.FilePath = path
+		if err != nil {
+			return fileerr.Wrap(err)
+		}
+		if opts.TrimSilence {
+			if _, err := TrimSilence(context.Background(), path, opts.Settings, false); err != nil {
+				return fmt.Errorf("trim silence: %w", err)
+			}
+		}
+		if opts.MusicBrainzTagging {
+			// Tracks MusicBrainz doesn't know are simply left as they are.
+			r, err := musicbrainz.Default.Enrich(context.Background(), path, false)
+			if r.Error != "" && !errors.Is(err, musicbrainz.ErrNoMatch) {
+				return errors.New(r.Error)
+			}
+		}
+		if opts.PerformerTags && opts.Credits != nil {
+			if err := WritePerformers(opts.Credits, path, t.ID); err != nil {
+				return fmt.Errorf("performer tags: %w", err)
+			}
+		}
+	case "error", "cancelled":
+		reg.Forget(trackID)
+	}
+	return nil
+}
+
+// coreTemplate rewrites FLACidal's template aliases into the token names
+// flacidal-core's formatter understands, so {disc} works in both the
+// filename and folder templates.
+func coreTemplate(tmpl string) string {
+	return strings.ReplaceAll(tmpl, "{disc}", "{discnumber}")
+}
+
+// corePlaceholderName is the name core gives downloads whose template it
+// can't expand, until FinishDownload renames them. Core has no track ID
+// token, so it combines every token that tells two tracks apart; two
+// same-titled tracks by one artist must not share it, or core would skip
+// the second as already downloaded before FileConflict could name it.
+const corePlaceholderName = "{artist} - {album} - {discnumber}-{track} - {title} {isrc}"
+
+// coreFileNameFormat is the filename template handed to the core
+// downloader. Templates using tokens core can't expand get
+// corePlaceholderName instead; FinishDownload renames the file afterwards.
+func coreFileNameFormat(tmpl string) string {
+	if naming.NeedsRender(tmpl) {
+		return corePlaceholderName
+	}
+	return coreTemplate(tmpl)
+}
+
+// SafeFolderName sanitizes name with flacidal-core's rules and then the
+// Windows ones core misses (reserved device names, trailing dots, length),
+// after applying the FilenameUnicode setting mode, for the
+// artist/album/playlist folders FLACidal creates itself. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func SafeFolderName(name string, mode naming.UnicodeMode) string {
+	return naming.SafeName(core.SanitizeFileName(mode.Apply(name)))
+}
+
+// folderName is SafeFolderName with the current settings.
+func (a *App) folderName(name string) string {
+	return SafeFolderName(name, a.currentSettings().FilenameUnicode)
+}
+
+// fitTidalTracks is FitTidalTracks with the current config and settings.
+func (a *App) fitTidalTracks(tracks []core.TidalTrack, outputDir string) []core.TidalTrack {
+	return FitTidalTracks(&a.postTracks, tracks, outputDir, a.config, a.currentSettings().MaxPathLength)
+}
+
+// FitTidalTracks returns the tracks to hand core for a download into
+// outputDir, fitted so the file core writes is valid where it lands:
+// with config's OrganizeFolders on, the artist and album names core makes
+// folders of get the Windows rules (naming.SafeName), and titles are
+// shortened where the path, predicted from config's folder and filename
+// templates, would run past the MaxPathLength limit maxPath (see
+// naming.PathLimit). Renaming afterwards is too late on Windows, where the
+// over-long write itself fails. The full values are recorded in reg for
+// post-processing to write back to the tags; tracks is left as is. Shared
+// by the desktop (Wails) and HTTP server APIs.
+func FitTidalTracks(reg *postprocess.Registry, tracks []core.TidalTrack, outputDir string, config *core.Config, maxPath int) []core.TidalTrack {
+	fit := newPathFit(outputDir, config, maxPath)
+	fitted := slices.Clone(tracks)
+	for i := range fitted {
+		t := &fitted[i]
+		year := t.ReleaseDate
+		if len(year) > 4 {
+			year = year[:4]
+		}
+		v := naming.Values{ISRC: t.ISRC, ID: strconv.Itoa(t.ID), Year: year, Track: t.TrackNum, Disc: t.DiscNumber}
+		reg.SetFitted(t.ID, fit.apply(&t.Title, &t.Artist, &t.AlbumArtist, &t.Album, v))
+	}
+	return fitted
+}
+
+// FitSourceTracks is FitTidalTracks for tracks queued from other sources as
+// core.SourceTracks, such as Qobuz's. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func FitSourceTracks(reg *postprocess.Registry, tracks []core.SourceTrack, outputDir string, config *core.Config, maxPath int) []core.SourceTrack {
+	fit := newPathFit(outputDir, config, maxPath)
+	fitted := slices.Clone(tracks)
+	for i := range fitted {
+		t := &fitted[i]
+		id, err := strconv.Atoi(t.ID)
+		if err != nil {
+			continue // the download manager can't report it by ID
+		}
+		albumArtist := ""
+		v := naming.Values{ISRC: t.ISRC, ID: t.ID, Year: t.Year, Track: t.TrackNumber, Disc: t.DiscNumber}
+		reg.SetFitted(id, fit.apply(&t.Title, &t.Artist, &albumArtist, &t.Album, v))
+	}
+	return fitted
+}
+
+// pathFit predicts where core writes a download and fits the names it
+// builds that path from.
+type pathFit struct {
+	dir      string
+	tmpl     string // core's filename template, under its folder template when organizing
+	organize bool
+	limit    int // naming.PathLimit; 0 is none
+}
+
+// newPathFit returns the pathFit for downloads into dir with config's
+// templates; a nil config means core's defaults.
+func newPathFit(dir string, config *core.Config, maxPath int) pathFit {
+	if config == nil {
+		config = &core.Config{}
+	}
+	format := config.FileNameFormat
+	if format == "" {
+		format = "{artist} - {title}"
+	}
+	fit := pathFit{dir: dir, tmpl: coreFileNameFormat(format), organize: config.OrganizeFolders, limit: naming.PathLimit(maxPath)}
+	if fit.organize && config.FolderTemplate != "" {
+		fit.tmpl = coreTemplate(config.FolderTemplate) + "/" + fit.tmpl
+	}
+	return fit
+}
+
+// apply fits one track's names in place, given its other template values
+// in v, and returns the full values of the title, artist and album it
+// changed. The album artist only names folders, and FLACidal writes its
+// tag from the remembered track anyway.
+func (f pathFit) apply(title, artist, albumArtist, album *string, v naming.Values) postprocess.Overrides {
+	var full postprocess.Overrides
+	if f.organize {
+		for _, name := range []struct{ value, full *string }{{artist, &full.Artist}, {albumArtist, nil}, {album, &full.Album}} {
+			if safe := naming.SafeName(*name.value); safe != *name.value && safe != "" {
+				if name.full != nil {
+					*name.full = *name.value
+				}
+				*name.value = safe
+			}
+		}
+	}
+	v.Title, v.Artist, v.AlbumArtist, v.Album = *title, *artist, *albumArtist, *album
+	for f.limit > 0 && strings.Contains(f.tmpl, "{title") {
+		rel := naming.RenderPath(f.tmpl, v)
+		over := naming.Overflow(filepath.Join(f.dir, rel)+".flac", f.limit)
+		if rel == "" || over == 0 {
+			break
+		}
+		short := naming.Shorten(*title, over)
+		if short == "" {
+			break // the rest of the path leaves the title no room
+		}
+		if full.Title == "" {
+			full.Title = *title
+		}
+		*title, v.Title = short, short
+	}
+	return full
+}
+
+// postOptions returns the options FinishDownload applies to new downloads.
+func (a *App) postOptions() postprocess.Options {
+	opts := postprocess.Options{Settings: a.currentSettings()}
+	if a.config != nil {
+		opts.FileNameFormat = a.config.FileNameFormat
+		opts.OrganizeFolders = a.config.OrganizeFolders
+		opts.FolderTemplate = a.config.FolderTemplate
+		opts.Credits = CreditFetchers(a.config)["tidal"]
+	}
+	return opts
+}
+
+// =============================================================================
+// Filename Template Methods (exposed to frontend)
+// =============================================================================
+
+// SetTrackOverrides sets the title, artist, album or track number to use for
+// trackID instead of the source's, in both the filename and the tags. Call
+// it before queueing the track (or while it waits); empty overrides clear
+// earlier ones
+func (a *App) SetTrackOverrides(trackID int, overrides postprocess.Overrides) error {
+	if err := overrides.Validate(); err != nil {
+		return err
+	}
+	a.postTracks.SetOverrides(trackID, overrides)
+	return nil
+}
+
+// GetFilenameTokens lists the tokens the filename template accepts, for the
+// settings UI.
+func (a *App) GetFilenameTokens() []naming.Token {
+	return naming.Tokens()
+}
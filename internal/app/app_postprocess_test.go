@@ -0,0 +1,258 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/naming"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/settings"
+)
+
+func TestRememberTidalTracks_InfersTotalDiscs(t *testing.T) {
+	var reg postprocess.Registry
+	RememberTidalTracks(&reg, []core.TidalTrack{
+		{ID: 1, DiscNumber: 1},
+		{ID: 2, DiscNumber: 2},
+		{ID: 3, DiscNumber: 2, TotalDiscs: 3},
+	}, "album")
+
+	if got, _ := reg.Take(1); got.TotalDiscs != 2 {
+		t.Errorf("track 1 TotalDiscs = %d, want 2 (max disc in batch)", got.TotalDiscs)
+	}
+	if got, _ := reg.Take(3); got.TotalDiscs != 3 {
+		t.Errorf("track 3 TotalDiscs = %d, want 3 (reported by source)", got.TotalDiscs)
+	}
+}
+
+func TestRememberTidalTracks_PlaylistPosition(t *testing.T) {
+	var reg postprocess.Registry
+	tracks := []core.TidalTrack{{ID: 1}, {ID: 2}, {ID: 3}}
+	RememberTidalTracks(&reg, tracks, "playlist")
+	if got, _ := reg.Take(2); got.PlaylistNum != 2 || got.PlaylistTotal != 3 {
+		t.Errorf("playlist track = %+v, want PlaylistNum 2 of 3", got)
+	}
+
+	RememberTidalTracks(&reg, tracks, "album")
+	if got, _ := reg.Take(2); got.PlaylistNum != 0 || got.PlaylistIndex != 2 {
+		t.Errorf("album track = %+v, want no playlist position", got)
+	}
+}
+
+func TestRememberTidalTracks_Compilation(t *testing.T) {
+	var reg postprocess.Registry
+	tracks := []core.TidalTrack{
+		{ID: 1, Artist: "A", AlbumArtist: "Various Artists"},
+		{ID: 2, Artist: "B", AlbumArtist: "Various Artists"},
+	}
+	RememberTidalTracks(&reg, tracks, "album")
+	if got, _ := reg.Take(2); !got.Compilation || got.AlbumArtist != "Various Artists" {
+		t.Errorf("compilation track = %+v", got)
+	}
+
+	RememberTidalTracks(&reg, tracks, "playlist")
+	if got, _ := reg.Take(2); got.Compilation {
+		t.Errorf("playlist track marked as compilation: %+v", got)
+	}
+}
+
+func TestRememberTidalTracks_ExtendedTags(t *testing.T) {
+	var reg postprocess.Registry
+	RememberTidalTracks(&reg, []core.TidalTrack{
+		{ID: 1, Artist: "A", Artists: "A, B", Label: "L", Copyright: "C"},
+	}, "album")
+	got, _ := reg.Take(1)
+	if len(got.Artists) != 2 || got.Label != "L" || got.Copyright != "C" {
+		t.Errorf("track = %+v", got)
+	}
+}
+
+func TestSplitArtists(t *testing.T) {
+	tests := []struct {
+		artist, artists string
+		want            []string
+	}{
+		{"A", "A, B, C", []string{"A", "B", "C"}},
+		{"Tyler, The Creator", "Tyler, The Creator, Kali Uchis", []string{"Tyler, The Creator", "Kali Uchis"}},
+		{"A", "AB, C", []string{"AB", "C"}},
+		{"A", "A", nil},
+		{"A", "", nil},
+		{"A", "A, B +2", nil}, // shortened for display
+	}
+	for _, tt := range tests {
+		got := splitArtists(tt.artist, tt.artists)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("splitArtists(%q, %q) = %q, want %q", tt.artist, tt.artists, got, tt.want)
+		}
+	}
+}
+
+func TestFinishDownload_CompletedMovesAndTags(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "02 - Song.flac")
+	writeTestFLAC(t, path, testTags, nil, 64)
+
+	var reg postprocess.Registry
+	RememberTidalTracks(&reg, []core.TidalTrack{{ID: 42, DiscNumber: 2, TotalDiscs: 2}}, "album")
+	result := &core.DownloadResult{FilePath: path}
+
+	err := FinishDownload(&reg, postprocess.Options{Settings: settings.Settings{DiscSubfolders: true}}, 42, "completed", result)
+	if err != nil {
+		t.Fatalf("FinishDownload: %v", err)
+	}
+	want := filepath.Join(dir, "Disc 2", "02 - Song.flac")
+	if result.FilePath != want {
+		t.Fatalf("result.FilePath = %q, want %q", result.FilePath, want)
+	}
+	f, err := flacmeta.Read(want)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	c, _ := f.Comments()
+	if c.Get("DISCNUMBER") != "2" || c.Get("TITLE") != testTags["TITLE"] {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+}
+
+func TestFinishDownload_ErrorForgetsTrack(t *testing.T) {
+	var reg postprocess.Registry
+	RememberTidalTracks(&reg, []core.TidalTrack{{ID: 7, DiscNumber: 1}}, "album")
+
+	if err := FinishDownload(&reg, postprocess.Options{}, 7, "error", &core.DownloadResult{Error: "boom"}); err != nil {
+		t.Fatalf("FinishDownload: %v", err)
+	}
+	if _, ok := reg.Take(7); ok {
+		t.Error("metadata for a failed job should be dropped")
+	}
+}
+
+func TestCoreTemplate_DiscAlias(t *testing.T) {
+	if got := coreTemplate("{disc}-{track} - {title}"); got != "{discnumber}-{track} - {title}" {
+		t.Errorf("coreTemplate = %q", got)
+	}
+	if got := coreTemplate("{discnumber}"); got != "{discnumber}" {
+		t.Errorf("coreTemplate rewrote an existing token: %q", got)
+	}
+}
+
+func TestCoreFileNameFormat(t *testing.T) {
+	tests := map[string]string{
+		"{artist} - {title}":       "{artist} - {title}",
+		"{disc}-{track} - {title}": "{discnumber}-{track} - {title}",
+		"{track:3} - {title}":      corePlaceholderName,
+		"{title} [{quality}]":      corePlaceholderName,
+		"{playlistindex}. {title}": corePlaceholderName,
+	}
+	for in, want := range tests {
+		if got := coreFileNameFormat(in); got != want {
+			t.Errorf("coreFileNameFormat(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFinishDownload_FillsQualityAndSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Artist - Song.flac")
+	writeTestFLAC(t, path, testTags, nil, 64)
+
+	var reg postprocess.Registry
+	RememberTidalTracks(&reg, []core.TidalTrack{{ID: 5, Title: "Song", ReleaseDate: "2013-05-17"}}, "album")
+	result := &core.DownloadResult{FilePath: path, Quality: "LOSSLESS", Source: "tidal"}
+
+	opts := postprocess.Options{FileNameFormat: "{year} {title} [{quality} {source} {id}]"}
+	if err := FinishDownload(&reg, opts, 5, "completed", result); err != nil {
+		t.Fatalf("FinishDownload: %v", err)
+	}
+	if want := filepath.Join(dir, "2013 Song [LOSSLESS tidal 5].flac"); result.FilePath != want {
+		t.Errorf("result.FilePath = %q, want %q", result.FilePath, want)
+	}
+}
+
+func TestGetFilenameTokens(t *testing.T) {
+	a := &App{}
+	seen := map[string]bool{}
+	for _, tok := range a.GetFilenameTokens() {
+		seen[tok.Name] = true
+	}
+	for _, name := range []string{"year", "albumartist", "disc", "quality", "source", "id", "playlistindex"} {
+		if !seen[name] {
+			t.Errorf("GetFilenameTokens() missing %q", name)
+		}
+	}
+}
+
+func TestSafeFolderName_ReservedNames(t *testing.T) {
+	if got := SafeFolderName("NUL", naming.UnicodeKeep); got != "NUL_" {
+		t.Errorf("SafeFolderName(%q) = %q, want %q", "NUL", got, "NUL_")
+	}
+	if got := SafeFolderName("Greatest Hits...", naming.UnicodeKeep); got != "Greatest Hits" {
+		t.Errorf("trailing dots kept: %q", got)
+	}
+}
+
+func TestFitTidalTracks(t *testing.T) {
+	dir := filepath.Join("music", "downloads")
+	config := &core.Config{OrganizeFolders: true, FolderTemplate: "{artist}/{album}"}
+	tracks := []core.TidalTrack{
+		{ID: 1, Title: strings.Repeat("Long ", 40), Artist: "CON", Album: "Hits..."},
+		{ID: 2, Title: "Short", Artist: "Band", Album: "Album"},
+	}
+	var reg postprocess.Registry
+	fitted := FitTidalTracks(&reg, tracks, dir, config, 120)
+
+	long := fitted[0]
+	path := filepath.Join(dir, "CON_", "Hits", "CON_ - "+long.Title+".flac")
+	if long.Artist != "CON_" || long.Album != "Hits" || len(path) > 120 || long.Title == "" {
+		t.Errorf("fitted = %+v (path %d long)", long, len(path))
+	}
+	if tracks[0].Title != strings.Repeat("Long ", 40) {
+		t.Error("the tracks passed in were changed")
+	}
+	reg.Remember(1, postprocess.Track{ID: "1"})
+	if got, _ := reg.Take(1); got.Fitted.Title != tracks[0].Title || got.Fitted.Artist != "CON" || got.Fitted.Album != "Hits..." {
+		t.Errorf("recorded full values = %+v", got.Fitted)
+	}
+	if f := fitted[1]; f.Title != "Short" || f.Artist != "Band" || f.Album != "Album" {
+		t.Errorf("track that fits changed: %+v", f)
+	}
+	if got := FitTidalTracks(&reg, tracks, dir, &core.Config{}, 4096); got[0].Artist != "CON" {
+		t.Errorf("artist changed without OrganizeFolders: %q", got[0].Artist)
+	}
+}
+
+func TestFolderName_FollowsUnicodeSetting(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	a := &App{}
+	if got := a.folderName("Motörhead"); got != "Motörhead" {
+		t.Errorf("folderName without settings = %q, want unchanged", got)
+	}
+	st, err := settings.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.settings = st
+	if _, err := a.SaveSettings(settings.Settings{FilenameUnicode: naming.UnicodeASCII}); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.folderName("Motörhead"); got != "Motorhead" {
+		t.Errorf("folderName = %q, want %q", got, "Motorhead")
+	}
+}
+
+func TestSetTrackOverrides(t *testing.T) {
+	a := &App{}
+	if err := a.SetTrackOverrides(9, postprocess.Overrides{TrackNumber: -2}); err == nil {
+		t.Error("SetTrackOverrides() with negative track number: want error, got nil")
+	}
+	if err := a.SetTrackOverrides(9, postprocess.Overrides{Title: "Fixed"}); err != nil {
+		t.Fatalf("SetTrackOverrides() error = %v", err)
+	}
+	RememberTidalTracks(&a.postTracks, []core.TidalTrack{{ID: 9, Title: "Typo"}}, "album")
+	if got, _ := a.postTracks.Take(9); got.Title != "Fixed" {
+		t.Errorf("Title = %q, want override", got.Title)
+	}
+}
@@ -8,9 +8,14 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"flacidal/internal/downloads"
+	"flacidal/internal/history"
+	"flacidal/internal/quality"
 )
 
 // =============================================================================
@@ -100,35 +105,34 @@ func (a *App) QueueDownloads(tracks []core.TidalTrack, outputDir string, content
 
 	// Create subfolder with content name (playlist/album/track title)
 	if contentName != "" {
-		outputDir = filepath.Join(outputDir, core.SanitizeFileName(contentName))
+		outputDir = filepath.Join(outputDir, a.folderName(contentName))
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
 			return 0, fmt.Errorf("failed to create folder: %w", err)
 		}
 	}
 
-	queued := a.downloadManager.QueueMultiple(tracks, outputDir)
+	queued := a.downloadManager.QueueMultiple(a.fitTidalTracks(tracks, outputDir), outputDir)
 
-	// Save initial history record
-	if a.db != nil && contentID != "" {
-		if err := a.db.SaveDownloadRecord(&core.DownloadRecord{
-			TidalContentID:   contentID,
-			TidalContentName: contentName,
-			ContentType:      contentType,
-			TracksTotal:      queued,
-		}); err != nil {
-			a.logBuffer.Warn(fmt.Sprintf("Failed to save download history for %s: %v", contentID, err))
-		}
-	}
-	// Map each trackID → contentID for progress callback
-	for _, t := range tracks {
-		a.trackContentMap.Store(t.ID, contentID)
+	// Save the batch's history record; finished tracks update its counts
+	if err := a.batches.Start(a.db, core.DownloadRecord{
+		TidalContentID:   contentID,
+		TidalContentName: contentName,
+		ContentType:      contentType,
+		TracksTotal:      queued,
+	}, tidalTrackIDs(tracks)); err != nil {
+		a.logBuffer.Warn(fmt.Sprintf("Failed to save download history: %v", err))
+	} else if err := a.origins.Commit(contentID); err != nil {
+		a.logBuffer.Warn(fmt.Sprintf("Failed to save history origin: %v", err))
 	}
+	RememberTidalTracks(&a.postTracks, tracks, contentType)
 
 	return queued, nil
 }
 
-// QueueQobuzDownloads queues Qobuz-sourced tracks for concurrent download
-func (a *App) QueueQobuzDownloads(tracks []core.SourceTrack, outputDir string, contentName string) (int, error) {
+// QueueQobuzDownloads queues Qobuz-sourced tracks for concurrent download.
+// contentID is the Qobuz album/playlist/track ID; its history record is
+// keyed "qobuz:<id>" so it can't collide with a Tidal ID.
+func (a *App) QueueQobuzDownloads(tracks []core.SourceTrack, outputDir string, contentName string, contentID string, contentType string) (int, error) {
 	if a.downloadManager == nil {
 		return 0, fmt.Errorf("download manager not initialized")
 	}
@@ -136,12 +140,31 @@ func (a *App) QueueQobuzDownloads(tracks []core.SourceTrack, outputDir string, c
 		return 0, fmt.Errorf("no output directory specified")
 	}
 	if contentName != "" {
-		outputDir = filepath.Join(outputDir, core.SanitizeFileName(contentName))
+		outputDir = filepath.Join(outputDir, a.folderName(contentName))
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
 			return 0, fmt.Errorf("failed to create folder: %w", err)
 		}
 	}
-	return a.downloadManager.QueueQobuzTracks(tracks, outputDir), nil
+
+	fitted := FitSourceTracks(&a.postTracks, tracks, outputDir, a.config, a.currentSettings().MaxPathLength)
+	queued := a.downloadManager.QueueQobuzTracks(fitted, outputDir)
+	RememberSourceTracks(&a.postTracks, tracks, "qobuz", contentType)
+
+	key := ""
+	if contentID != "" {
+		key = history.Key("qobuz", contentID)
+	}
+	if err := a.batches.Start(a.db, core.DownloadRecord{
+		TidalContentID:   key,
+		TidalContentName: contentName,
+		ContentType:      contentType,
+		TracksTotal:      queued,
+	}, SourceTrackIDs(tracks)); err != nil {
+		a.logBuffer.Warn(fmt.Sprintf("Failed to save download history: %v", err))
+	} else if err := a.origins.Commit(key); err != nil {
+		a.logBuffer.Warn(fmt.Sprintf("Failed to save history origin: %v", err))
+	}
+	return queued, nil
 }
 
 // QueueArtistAlbum fetches a Tidal album's tracks and queues them all for download.
@@ -160,17 +183,18 @@ func (a *App) QueueArtistAlbum(albumID string, artistName string, outputDir stri
 	}
 
 	// Create {Artist}/{Album} folder structure
-	artistFolder := core.SanitizeFileName(artistName)
+	artistFolder := a.folderName(artistName)
 	if artistFolder == "" {
-		artistFolder = core.SanitizeFileName(album.Artist)
+		artistFolder = a.folderName(album.Artist)
 	}
-	albumFolder := core.SanitizeFileName(album.Title)
+	albumFolder := a.folderName(album.Title)
 	albumDir := filepath.Join(outputDir, artistFolder, albumFolder)
 	if err := os.MkdirAll(albumDir, 0755); err != nil {
 		return 0, fmt.Errorf("failed to create album folder: %w", err)
 	}
 
-	queued := a.downloadManager.QueueMultiple(album.Tracks, albumDir)
+	queued := a.downloadManager.QueueMultiple(a.fitTidalTracks(album.Tracks, albumDir), albumDir)
+	RememberTidalTracks(&a.postTracks, album.Tracks, "album")
 	return queued, nil
 }
 
@@ -201,7 +225,7 @@ func (a *App) DownloadArtistAssets(artistID string, artistName string, outputDir
 	}
 
 	// Save to {outputDir}/{artistName}/
-	destDir := filepath.Join(outputDir, core.SanitizeFileName(artistName))
+	destDir := filepath.Join(outputDir, a.folderName(artistName))
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return 0, fmt.Errorf("failed to create artist folder: %w", err)
 	}
@@ -254,7 +278,9 @@ func (a *App) QueueSingleDownload(trackID int, outputDir, title, artist string)
 
 	// Fetch ISRC from Tidal metadata so the orchestrator can search by ISRC on fallback sources.
 	isrc := ""
+	var remember []core.TidalTrack
 	if track, err := a.downloader.GetTrackAsTidalTrack(trackID); err == nil && track != nil {
+		remember = append(remember, *track)
 		isrc = track.ISRC
 		if title == "" {
 			title = track.Title
@@ -265,22 +291,22 @@ func (a *App) QueueSingleDownload(trackID int, outputDir, title, artist string)
 	}
 
 	err := a.downloadManager.QueueDownloadWithISRC(trackID, outputDir, title, artist, isrc)
-	if err == nil && a.db != nil {
-		contentID := strconv.Itoa(trackID)
-		if saveErr := a.db.SaveDownloadRecord(&core.DownloadRecord{
-			TidalContentID:   contentID,
+	if err == nil {
+		RememberTidalTracks(&a.postTracks, remember, "track")
+		if saveErr := a.batches.Start(a.db, core.DownloadRecord{
+			TidalContentID:   strconv.Itoa(trackID),
 			TidalContentName: title,
 			ContentType:      "track",
 			TracksTotal:      1,
-		}); saveErr != nil {
-			a.logBuffer.Warn(fmt.Sprintf("Failed to save download history for %s: %v", contentID, saveErr))
+		}, []int{trackID}); saveErr != nil {
+			a.logBuffer.Warn(fmt.Sprintf("Failed to save download history: %v", saveErr))
 		}
-		a.trackContentMap.Store(trackID, contentID)
 	}
 	return err
 }
 
-// GetDownloadQueueStatus returns current queue status
+// GetDownloadQueueStatus returns current queue status, with the progress
+// of each queued playlist, album or single under "batches"
 func (a *App) GetDownloadQueueStatus() map[string]interface{} {
 	if a.downloadManager == nil {
 		return map[string]interface{}{"running": false}
@@ -291,26 +317,110 @@ func (a *App) GetDownloadQueueStatus() map[string]interface{} {
 		"paused":      a.downloadManager.IsPaused(),
 		"activeCount": a.downloadManager.GetActiveCount(),
 		"queueLength": a.downloadManager.GetQueueLength(),
+		"throughput":  QueueRate(&a.throughput, a.downloadManager),
+		"batches":     a.batches.Status(&a.jobs),
+	}
+}
+
+// GetDownloadJobs returns the state history and timings of every job seen
+// this session, finished ones included (see downloads.MaxFinished)
+func (a *App) GetDownloadJobs() []downloads.Job {
+	return a.jobs.Jobs()
+}
+
+// RecordThroughput adds a completed job's file to tp and returns the
+// track's speed in bytes per second. Other statuses, and files that can't
+// be measured, record nothing and return 0. Shared by the desktop (Wails)
+// and HTTP server APIs.
+func RecordThroughput(tp *downloads.Throughput, job downloads.Job, status string, result *core.DownloadResult) int64 {
+	if status != "completed" || result == nil || result.FilePath == "" || job.DownloadTimeMs <= 0 {
+		return 0
+	}
+	info, err := os.Stat(result.FilePath)
+	if err != nil {
+		return 0
+	}
+	return tp.Add(job.ID, info.Size(), time.Duration(job.DownloadTimeMs)*time.Millisecond)
+}
+
+// QueueRate returns the current download speed and the ETA for the tracks
+// still queued or downloading in dm. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func QueueRate(tp *downloads.Throughput, dm *core.DownloadManager) downloads.Rate {
+	if dm == nil {
+		return tp.Rate(0)
+	}
+	return tp.Rate(dm.GetQueueLength() + dm.GetActiveCount())
+}
+
+// DefaultDownloadWorkers and MaxDownloadWorkers bound how many tracks
+// download at once (the ConcurrentDownloads config value). The download
+// manager's pool is started at MaxDownloadWorkers and a downloads.Gate
+// holds it to the configured number, so changing it applies live.
+const (
+	DefaultDownloadWorkers = 4
+	MaxDownloadWorkers     = 10
+)
+
+// DownloadWorkers resolves the ConcurrentDownloads config value to how
+// many tracks download at once: DefaultDownloadWorkers when unset, capped
+// at MaxDownloadWorkers. Shared by the desktop (Wails) app and cmd/server.
+func DownloadWorkers(n int) int {
+	if n <= 0 {
+		return DefaultDownloadWorkers
 	}
+	return min(n, MaxDownloadWorkers)
 }
 
-// GetDownloadOptions returns current download options
+// SetConcurrentDownloads stores n (0 keeps the current value) as config's
+// ConcurrentDownloads. Applying config (see ApplyConfig) resizes the
+// running downloads. Shared by the desktop (Wails) and HTTP server APIs.
+func SetConcurrentDownloads(config *core.Config, n int) error {
+	if n < 0 || n > MaxDownloadWorkers {
+		return fmt.Errorf("concurrent downloads must be between 1 and %d", MaxDownloadWorkers)
+	}
+	if n > 0 {
+		config.ConcurrentDownloads = n
+	}
+	return nil
+}
+
+// GateDownload holds a download manager worker at g until the download of
+// trackID may start, and frees its place once the download ended. It is
+// called first thing in the download manager's progress callback, on the
+// worker, and returns the status to carry on with: "cancelled" for a job
+// cancelled while it waited, whose worker is let go at once. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func GateDownload(g *downloads.Gate, trackID int, status string) string {
+	switch state := downloads.State(status); {
+	case state == downloads.Downloading:
+		if !g.Enter(trackID) {
+			return string(downloads.Cancelled)
+		}
+	case state.Terminal():
+		g.Leave(trackID)
+	}
+	return status
+}
+
+// GetDownloadOptions returns current download options.
 func (a *App) GetDownloadOptions() map[string]interface{} {
 	if a.config == nil {
 		return map[string]interface{}{
-			"quality":         "LOSSLESS",
-			"fileNameFormat":  "{artist} - {title}",
-			"organizeFolders": false,
-			"embedCover":      true,
-			"saveCoverFile":   true,
-			"saveFolderCover": true,
-			"autoAnalyze":     false,
+			"quality":             quality.Default.String(),
+			"fileNameFormat":      "{artist} - {title}",
+			"organizeFolders":     false,
+			"embedCover":          true,
+			"saveCoverFile":       true,
+			"saveFolderCover":     true,
+			"autoAnalyze":         false,
+			"concurrentDownloads": DefaultDownloadWorkers,
 		}
 	}
 
-	quality := a.config.DownloadQuality
-	if quality == "" {
-		quality = "LOSSLESS"
+	dlQuality, err := quality.ParseOrDefault(a.config.DownloadQuality)
+	if err != nil {
+		dlQuality = quality.Default
 	}
 	format := a.config.FileNameFormat
 	if format == "" {
@@ -318,23 +428,33 @@ func (a *App) GetDownloadOptions() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"quality":         quality,
-		"fileNameFormat":  format,
-		"organizeFolders": a.config.OrganizeFolders,
-		"embedCover":      a.config.EmbedCover,
-		"saveCoverFile":   a.config.SaveCoverFile,
-		"saveFolderCover": a.config.SaveFolderCover,
-		"autoAnalyze":     a.config.AutoAnalyze,
+		"quality":             dlQuality.String(),
+		"fileNameFormat":      format,
+		"organizeFolders":     a.config.OrganizeFolders,
+		"embedCover":          a.config.EmbedCover,
+		"saveCoverFile":       a.config.SaveCoverFile,
+		"saveFolderCover":     a.config.SaveFolderCover,
+		"autoAnalyze":         a.config.AutoAnalyze,
+		"concurrentDownloads": DownloadWorkers(a.config.ConcurrentDownloads),
 	}
 }
 
-// SetDownloadOptions updates download options
-func (a *App) SetDownloadOptions(quality, fileNameFormat string, organizeFolders, embedCover, saveCoverFile, autoAnalyze bool) error {
+// SetDownloadOptions updates download options. concurrentDownloads of 0
+// keeps the current value; see SetConcurrentDownloads.
+func (a *App) SetDownloadOptions(qualityName, fileNameFormat string, organizeFolders, embedCover, saveCoverFile, autoAnalyze bool, concurrentDownloads int) error {
+	dlQuality, err := quality.ParseOrDefault(qualityName)
+	if err != nil {
+		return err
+	}
 	if a.config == nil {
 		a.config = &core.Config{}
 	}
+	if err := SetConcurrentDownloads(a.config, concurrentDownloads); err != nil {
+		return err
+	}
+	a.downloadGate.SetLimit(DownloadWorkers(a.config.ConcurrentDownloads))
 
-	a.config.DownloadQuality = quality
+	a.config.DownloadQuality = dlQuality.String()
 	a.config.FileNameFormat = fileNameFormat
 	a.config.OrganizeFolders = organizeFolders
 	a.config.EmbedCover = embedCover
@@ -348,8 +468,8 @@ func (a *App) SetDownloadOptions(quality, fileNameFormat string, organizeFolders
 			autoQualityFallback = a.config.AutoQualityFallback
 		}
 		a.downloader.SetOptions(core.DownloadOptions{
-			Quality:             quality,
-			FileNameFormat:      fileNameFormat,
+			Quality:             dlQuality.String(),
+			FileNameFormat:      coreFileNameFormat(fileNameFormat),
 			OrganizeFolders:     organizeFolders,
 			EmbedCover:          embedCover,
 			SaveCoverFile:       saveCoverFile,
@@ -454,7 +574,12 @@ func (a *App) CancelDownload(trackID int) error {
 		return fmt.Errorf("download manager not initialized")
 	}
 
-	return a.downloadManager.CancelDownload(trackID)
+	if err := a.downloadManager.CancelDownload(trackID); err != nil {
+		return err
+	}
+	// A download waiting for its turn holds its worker until let go
+	a.downloadGate.Leave(trackID)
+	return nil
 }
 
 // PauseDownloads pauses the download queue
@@ -492,3 +617,25 @@ func (a *App) IsQueuePaused() bool {
 	}
 	return a.downloadManager.IsPaused()
 }
+
+// tidalTrackIDs returns the IDs of tracks, in order.
+func tidalTrackIDs(tracks []core.TidalTrack) []int {
+	ids := make([]int, len(tracks))
+	for i, t := range tracks {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// SourceTrackIDs returns the numeric IDs of tracks, as the download manager
+// reports them in progress events. IDs that aren't numeric are skipped.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func SourceTrackIDs(tracks []core.SourceTrack) []int {
+	ids := make([]int, 0, len(tracks))
+	for _, t := range tracks {
+		if id, err := strconv.Atoi(t.ID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
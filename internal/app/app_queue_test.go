@@ -1,9 +1,14 @@
 package app
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/downloads"
 )
 
 // Characterization tests for the "Download Methods" section of app.go, plus
@@ -103,13 +108,13 @@ func TestQueueDownloads_Guards(t *testing.T) {
 func TestQueueQobuzDownloads_Guards(t *testing.T) {
 	t.Run("nil downloadManager", func(t *testing.T) {
 		a := &App{}
-		if _, err := a.QueueQobuzDownloads(nil, "/tmp/out", "name"); err == nil {
+		if _, err := a.QueueQobuzDownloads(nil, "/tmp/out", "name", "1", "album"); err == nil {
 			t.Error("QueueQobuzDownloads() with nil downloadManager: want error, got nil")
 		}
 	})
 	t.Run("empty outputDir", func(t *testing.T) {
 		a := &App{downloadManager: core.NewDownloadManager(core.NewTidalHifiService(), 1)}
-		if _, err := a.QueueQobuzDownloads(nil, "", "name"); err == nil {
+		if _, err := a.QueueQobuzDownloads(nil, "", "name", "1", "album"); err == nil {
 			t.Error("QueueQobuzDownloads() with empty outputDir: want error, got nil")
 		}
 	})
@@ -196,7 +201,7 @@ func TestGetDownloadOptions(t *testing.T) {
 func TestSetDownloadOptions(t *testing.T) {
 	core.SetDataDir(t.TempDir())
 	a := &App{}
-	err := a.SetDownloadOptions("HI_RES", "{title}", true, true, true, true)
+	err := a.SetDownloadOptions("HI_RES", "{title}", true, true, true, true, 0)
 	if err != nil {
 		t.Fatalf("SetDownloadOptions() error = %v", err)
 	}
@@ -208,6 +213,91 @@ func TestSetDownloadOptions(t *testing.T) {
 	}
 }
 
+func TestSetDownloadOptions_Quality(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	a := &App{}
+	if err := a.SetDownloadOptions("cd", "{title}", false, false, false, false, 0); err != nil {
+		t.Fatalf("SetDownloadOptions() error = %v", err)
+	}
+	if a.config.DownloadQuality != "LOSSLESS" {
+		t.Errorf("alias not canonicalized: DownloadQuality = %q", a.config.DownloadQuality)
+	}
+	if err := a.SetDownloadOptions("ULTRA", "{title}", false, false, false, false, 0); err == nil {
+		t.Error("SetDownloadOptions() with unknown quality: want error, got nil")
+	}
+	if a.config.DownloadQuality != "LOSSLESS" {
+		t.Errorf("rejected quality overwrote config: %q", a.config.DownloadQuality)
+	}
+}
+
+func TestDownloadWorkers(t *testing.T) {
+	for n, want := range map[int]int{-1: DefaultDownloadWorkers, 0: DefaultDownloadWorkers, 2: 2, 99: MaxDownloadWorkers} {
+		if got := DownloadWorkers(n); got != want {
+			t.Errorf("DownloadWorkers(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestSetDownloadOptions_ConcurrentDownloads(t *testing.T) {
+	core.SetDataDir(t.TempDir())
+	a := &App{config: &core.Config{ConcurrentDownloads: 2}}
+	if err := a.SetDownloadOptions("LOSSLESS", "{title}", false, false, false, false, 0); err != nil {
+		t.Fatalf("SetDownloadOptions() error = %v", err)
+	}
+	if a.config.ConcurrentDownloads != 2 {
+		t.Errorf("0 overwrote ConcurrentDownloads: %d", a.config.ConcurrentDownloads)
+	}
+	if err := a.SetDownloadOptions("LOSSLESS", "{title}", false, false, false, false, 6); err != nil {
+		t.Fatalf("SetDownloadOptions() error = %v", err)
+	}
+	if got := a.GetDownloadOptions(); got["concurrentDownloads"] != 6 {
+		t.Errorf("GetDownloadOptions() = %v, want concurrentDownloads 6", got)
+	}
+	// Applied live: six tracks download at once
+	for id := range 6 {
+		GateDownload(&a.downloadGate, id, "downloading")
+	}
+	if n := a.downloadGate.Running(); n != 6 {
+		t.Errorf("%d downloads running, want 6", n)
+	}
+	if err := a.SetDownloadOptions("LOSSLESS", "{title}", false, false, false, false, MaxDownloadWorkers+1); err == nil {
+		t.Error("SetDownloadOptions() above MaxDownloadWorkers: want error, got nil")
+	}
+	if a.config.ConcurrentDownloads != 6 {
+		t.Errorf("rejected value overwrote ConcurrentDownloads: %d", a.config.ConcurrentDownloads)
+	}
+}
+
+func TestGateDownload(t *testing.T) {
+	var g downloads.Gate
+	g.SetLimit(1)
+	GateDownload(&g, 1, "downloading")
+	entered := make(chan struct{})
+	go func() {
+		GateDownload(&g, 2, "downloading")
+		close(entered)
+	}()
+	select {
+	case <-entered:
+		t.Fatal("second download started past the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	GateDownload(&g, 1, "completed")
+	<-entered
+	if n := g.Running(); n != 1 {
+		t.Errorf("%d downloads running, want 1", n)
+	}
+
+	// A job cancelled while waiting lets its worker go as cancelled
+	status := make(chan string)
+	go func() { status <- GateDownload(&g, 3, "downloading") }()
+	time.Sleep(50 * time.Millisecond)
+	GateDownload(&g, 3, "cancelled")
+	if got := <-status; got != "cancelled" {
+		t.Errorf("cancelled while waiting: status = %q, want cancelled", got)
+	}
+}
+
 func TestOpenDownloadFolder_EmptyFolder(t *testing.T) {
 	a := &App{}
 	if err := a.OpenDownloadFolder(""); err == nil {
@@ -313,3 +403,49 @@ func TestPauseResumeQueue(t *testing.T) {
 		}
 	})
 }
+
+func TestGetDownloadJobs(t *testing.T) {
+	a := &App{}
+	if got := a.GetDownloadJobs(); len(got) != 0 {
+		t.Fatalf("GetDownloadJobs() on a fresh app = %v, want empty", got)
+	}
+	a.jobs.Record(5, "queued")
+	a.jobs.Record(5, "downloading")
+	got := a.GetDownloadJobs()
+	if len(got) != 1 || got[0].ID != 5 || got[0].State != downloads.Downloading {
+		t.Errorf("GetDownloadJobs() = %+v", got)
+	}
+}
+
+func TestRecordThroughput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, make([]byte, 4000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	job := downloads.Job{ID: 5, DownloadTimeMs: 2000}
+	result := &core.DownloadResult{FilePath: path}
+
+	var tp downloads.Throughput
+	if got := RecordThroughput(&tp, job, "downloading", result); got != 0 {
+		t.Errorf("RecordThroughput(downloading) = %d, want 0", got)
+	}
+	if got := RecordThroughput(&tp, job, "completed", &core.DownloadResult{FilePath: path + ".missing"}); got != 0 {
+		t.Errorf("RecordThroughput(missing file) = %d, want 0", got)
+	}
+	if got := RecordThroughput(&tp, job, "completed", result); got != 2000 {
+		t.Errorf("RecordThroughput(completed) = %d, want 2000", got)
+	}
+	if got, ok := tp.Track(5); !ok || got != 2000 {
+		t.Errorf("Track(5) = %d, %v; want 2000, true", got, ok)
+	}
+	if rate := QueueRate(&tp, nil); rate.BytesPerSec == 0 || rate.ETASeconds != 0 {
+		t.Errorf("QueueRate with no manager = %+v, want a speed and no ETA", rate)
+	}
+}
+
+func TestSourceTrackIDs(t *testing.T) {
+	got := SourceTrackIDs([]core.SourceTrack{{ID: "12"}, {ID: "not-numeric"}, {ID: "34"}})
+	if len(got) != 2 || got[0] != 12 || got[1] != 34 {
+		t.Errorf("SourceTrackIDs() = %v, want [12 34]", got)
+	}
+}
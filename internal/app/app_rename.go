@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/library"
+	"flacidal/internal/naming"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/settings"
+)
+
+// RenameTemplates returns flacidal-core's rename templates followed by a
+// folder template filing files as artist/album/track. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func RenameTemplates() []map[string]string {
+	return append(core.GetRenameTemplates(), map[string]string{
+		"name":     "Artist/Album folders",
+		"template": "{artist}/{album}/{tracknumber} - {title}",
+	})
+}
+
+// PreviewRenames previews renaming files with tmpl, refusing broken files
+// in strict mode. Templates with path separators (see
+// naming.IsFolderTemplate) are planned by PreviewFolderRename, others by
+// flacidal-core. Shared by the desktop (Wails) and HTTP server APIs.
+func PreviewRenames(s settings.Settings, downloadFolder string, files []string, tmpl string) []core.RenamePreview {
+	return StrictResults(s, files, func(files []string) []core.RenamePreview {
+		if naming.IsFolderTemplate(tmpl) {
+			return PreviewFolderRename(downloadFolder, s.MaxPathLength, s.FileConflict, files, tmpl)
+		}
+		return core.PreviewRename(files, tmpl)
+	}, func(path, reason string) core.RenamePreview {
+		return core.RenamePreview{OldPath: path, OldName: filepath.Base(path), HasError: true, Error: reason}
+	})
+}
+
+// ApplyRenames renames files with tmpl the way PreviewRenames previews it,
+// recording the new paths in idx, which may be nil. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func ApplyRenames(s settings.Settings, idx *library.Index, downloadFolder string, files []string, tmpl string) []core.RenameResult {
+	return StrictResults(s, files, func(files []string) []core.RenameResult {
+		if naming.IsFolderTemplate(tmpl) {
+			return FolderRename(idx, downloadFolder, s.MaxPathLength, s.FileConflict, files, tmpl)
+		}
+		results := core.RenameFiles(files, tmpl)
+		for _, r := range results {
+			if r.Success && r.NewPath != "" {
+				MoveInLibrary(idx, r.OldPath, r.NewPath) //nolint:errcheck // the next scan catches up
+			}
+		}
+		return results
+	}, func(path, reason string) core.RenameResult {
+		return core.RenameResult{OldPath: path, Error: reason}
+	})
+}
+
+// RenameRoot returns the folder a folder template's path starts in for the
+// file at path: downloadFolder when the file is inside it, so renamed
+// files land in the library's layout, and otherwise the file's own folder.
+func RenameRoot(downloadFolder, path string) string {
+	if downloadFolder != "" {
+		root := filepath.Clean(downloadFolder)
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return root
+		}
+	}
+	return filepath.Dir(path)
+}
+
+// PreviewFolderRename plans moving files to the paths the folder template
+// tmpl renders from their tags, under RenameRoot, without touching them.
+// Each preview's NewPath is the file's destination and NewName that path
+// relative to the root. A destination another file already has is given
+// the name conflict picks (see naming.Conflict), and is an error with
+// naming.ConflictKeep; one an earlier file of files claims is an error.
+// Paths longer than the MaxPathLength limit maxPath (see
+// naming.PathLimit) have their name shortened. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func PreviewFolderRename(downloadFolder string, maxPath int, conflict naming.Conflict, files []string, tmpl string) []core.RenamePreview {
+	maxPath = naming.PathLimit(maxPath)
+	previews := make([]core.RenamePreview, len(files))
+	claimed := map[string]string{}
+	for i, path := range files {
+		p := &previews[i]
+		p.OldPath, p.OldName = path, filepath.Base(path)
+		fail := func(err error) {
+			p.HasError, p.Error = true, err.Error()
+		}
+		v, err := renameValues(path)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		rel := naming.RenderPath(tmpl, v)
+		if rel == "" {
+			fail(fmt.Errorf("template gives %s an empty name", p.OldName))
+			continue
+		}
+		root := RenameRoot(downloadFolder, path)
+		dest, err := naming.FitPath(filepath.Join(root, rel+filepath.Ext(path)), maxPath)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		if dest != path && taken(dest, path) {
+			free, ok := conflict.Resolve(dest, naming.Version(v.Title), v.ID)
+			if !ok {
+				p.NewPath = dest
+				p.NewName, _ = filepath.Rel(root, dest)
+				fail(fmt.Errorf("%s already exists", p.NewName))
+				continue
+			}
+			dest = free
+		}
+		p.NewPath = dest
+		p.NewName, _ = filepath.Rel(root, dest)
+		key := strings.ToLower(dest) // case-insensitive filesystems collide too
+		if other, ok := claimed[key]; ok {
+			fail(fmt.Errorf("%s is also where %s goes", p.NewName, filepath.Base(other)))
+			continue
+		}
+		claimed[key] = path
+	}
+	return previews
+}
+
+// FolderRename moves files where PreviewFolderRename plans them, with
+// their .lrc lyrics, creating the folders on the way and recording the
+// moves in idx, which may be nil. The cover images left alone in a folder
+// follow the last track out, and the folders left empty are removed, up to
+// the root. Files whose plan has an error stay put. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func FolderRename(idx *library.Index, downloadFolder string, maxPath int, conflict naming.Conflict, files []string, tmpl string) []core.RenameResult {
+	previews := PreviewFolderRename(downloadFolder, maxPath, conflict, files, tmpl)
+	results := make([]core.RenameResult, len(previews))
+	for i, p := range previews {
+		r := &results[i]
+		r.OldPath = p.OldPath
+		if p.HasError {
+			r.Error = p.Error
+			continue
+		}
+		if p.NewPath == p.OldPath {
+			r.NewPath, r.Success = p.OldPath, true
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p.NewPath), 0755); err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		if _, err := postprocess.Move(p.OldPath, p.NewPath); err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		r.NewPath, r.Success = p.NewPath, true
+		MoveInLibrary(idx, p.OldPath, p.NewPath) //nolint:errcheck // the next scan catches up
+		postprocess.LeaveFolder(filepath.Dir(p.OldPath), filepath.Dir(p.NewPath), RenameRoot(downloadFolder, p.OldPath))
+	}
+	return results
+}
+
+// renameValues reads the template values of the FLAC at path from its tags.
+func renameValues(path string) (naming.Values, error) {
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return naming.Values{}, err
+	}
+	c, err := f.Comments()
+	if err != nil {
+		return naming.Values{}, err
+	}
+	date := c.Get("DATE")
+	year := date
+	if len(year) > 4 {
+		year = year[:4]
+	}
+	number := func(tag string) int {
+		n, _ := strconv.Atoi(strings.TrimSpace(strings.SplitN(c.Get(tag), "/", 2)[0]))
+		return n
+	}
+	return naming.Values{
+		Title:       c.Get("TITLE"),
+		Artist:      c.Get("ARTIST"),
+		AlbumArtist: c.Get("ALBUMARTIST"),
+		Album:       c.Get("ALBUM"),
+		Year:        year,
+		Date:        date,
+		Genre:       c.Get("GENRE"),
+		ISRC:        c.Get("ISRC"),
+		Source:      c.Get(postprocess.TagSource),
+		ID:          c.Get(postprocess.TagSourceID),
+		Track:       number("TRACKNUMBER"),
+		Disc:        number("DISCNUMBER"),
+	}, nil
+}
+
+// taken reports whether something other than the file at path is at dest;
+// on case-insensitive filesystems a case-only rename finds the file itself.
+func taken(dest, path string) bool {
+	fi, err := os.Stat(dest)
+	if err != nil {
+		return false
+	}
+	self, err := os.Stat(path)
+	return err != nil || !os.SameFile(fi, self)
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"flacidal/internal/jobs"
+	"flacidal/internal/naming"
+	"flacidal/internal/settings"
+)
+
+func TestPreviewFolderRename(t *testing.T) {
+	root := t.TempDir()
+	a := filepath.Join(root, "incoming", "a.flac")
+	b := filepath.Join(root, "incoming", "b.flac")
+	c := filepath.Join(root, "incoming", "c.flac")
+	os.MkdirAll(filepath.Dir(a), 0755)
+	writeTestFLAC(t, a, map[string]string{"ARTIST": "AC/DC", "ALBUM": "High Voltage", "TITLE": "T.N.T.", "TRACKNUMBER": "3/9"}, nil, 64)
+	writeTestFLAC(t, b, map[string]string{"ARTIST": "AC/DC", "ALBUM": "High Voltage", "TITLE": "T.N.T.", "TRACKNUMBER": "3"}, nil, 64)
+	writeTestFLAC(t, c, map[string]string{"ARTIST": "AC/DC", "ALBUM": "High Voltage"}, nil, 64)
+
+	previews := PreviewFolderRename(root, 0, "", []string{a, b, c}, "{artist}/{album}/{tracknumber} - {title}")
+	want := filepath.Join("AC-DC", "High Voltage", "03 - T.N.T.flac")
+	if p := previews[0]; p.HasError || p.NewName != want || p.NewPath != filepath.Join(root, want) {
+		t.Errorf("a: %+v, want %s under the download folder", p, want)
+	}
+	if p := previews[1]; !p.HasError || !strings.Contains(p.Error, "a.flac") {
+		t.Errorf("b renders like a: %+v, want a collision error", p)
+	}
+	if p := previews[2]; !p.HasError {
+		t.Errorf("c has no title: %+v, want an empty name error", p)
+	}
+	if _, err := os.Stat(a); err != nil {
+		t.Error("preview moved a file")
+	}
+
+	// A taken destination is an error too; outside the download folder,
+	// paths start in the file's own folder.
+	os.MkdirAll(filepath.Join(root, "AC-DC", "High Voltage"), 0755)
+	os.WriteFile(filepath.Join(root, want), nil, 0644)
+	if p := PreviewFolderRename(root, 0, "", []string{a}, "{artist}/{album}/{tracknumber} - {title}")[0]; !p.HasError {
+		t.Errorf("existing destination: %+v, want an error", p)
+	}
+	if p := PreviewFolderRename(root, 0, naming.ConflictCounter, []string{a}, "{artist}/{album}/{tracknumber} - {title}")[0]; p.HasError || p.NewPath != filepath.Join(root, "AC-DC", "High Voltage", "03 - T.N.T (2).flac") {
+		t.Errorf("existing destination, counter: %+v", p)
+	}
+	if p := PreviewFolderRename(t.TempDir(), 0, "", []string{a}, "{artist}/{title}")[0]; p.NewPath != filepath.Join(root, "incoming", "AC-DC", "T.N.T.flac") {
+		t.Errorf("outside the download folder: %s", p.NewPath)
+	}
+}
+
+func TestFolderRename(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "incoming", "a.flac")
+	os.MkdirAll(filepath.Dir(src), 0755)
+	writeTestFLAC(t, src, map[string]string{"ARTIST": "Daft Punk", "ALBUM": "RAM", "TITLE": "Contact", "TRACKNUMBER": "8"}, nil, 64)
+	os.WriteFile(strings.TrimSuffix(src, ".flac")+".lrc", []byte("[00:00.00]"), 0644)
+	os.WriteFile(filepath.Join(filepath.Dir(src), "folder.jpg"), []byte("jpeg"), 0644)
+
+	r := FolderRename(nil, root, 0, "", []string{src}, "{artist}/{album}/{tracknumber} - {title}")[0]
+	dest := filepath.Join(root, "Daft Punk", "RAM", "08 - Contact.flac")
+	if !r.Success || r.NewPath != dest {
+		t.Fatalf("result = %+v, want %s", r, dest)
+	}
+	if _, err := os.Stat(strings.TrimSuffix(dest, ".flac") + ".lrc"); err != nil {
+		t.Error("lyrics sidecar not moved along")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "folder.jpg")); err != nil {
+		t.Error("cover not moved along")
+	}
+	if _, err := os.Stat(filepath.Dir(src)); !os.IsNotExist(err) {
+		t.Error("emptied source folder left behind")
+	}
+
+	work, err := BatchWork(settings.Settings{}, BatchRequest{Op: BatchRename, Files: []string{dest}, Template: "{album}/{title}", DownloadFolder: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := work(context.Background(), dest)
+	change, _ := res.(jobs.Change)
+	if err != nil || change.Output != filepath.Join(root, "RAM", "Contact.flac") {
+		t.Fatalf("batch rename = %+v, %v", res, err)
+	}
+	if err := change.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dest); err != nil {
+		t.Error("undo didn't move the file back into its removed folder")
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"flacidal/internal/autostart"
+	"flacidal/internal/configdiff"
+	"flacidal/internal/settings"
+)
+
+// =============================================================================
+// App Settings Methods (exposed to frontend)
+// =============================================================================
+
+// GetSettings returns the app-local settings (options implemented in this
+// repository rather than in flacidal-core's Config).
+func (a *App) GetSettings() settings.Settings {
+	return a.currentSettings()
+}
+
+// SaveSettings persists the app-local settings and returns the changed
+// ones (see SettingsChanges). They take effect for the next download that
+// finishes — nothing needs re-applying to core. Turning StartOnLogin on or
+// off (un)registers the app with the OS first, so a failed registration
+// leaves the saved setting unchanged.
+func (a *App) SaveSettings(s settings.Settings) ([]configdiff.Change, error) {
+	if a.settings == nil {
+		return nil, fmt.Errorf("settings not initialized")
+	}
+	old := a.settings.Get()
+	if s.StartOnLogin != old.StartOnLogin {
+		if err := applyStartOnLogin(s.StartOnLogin); err != nil {
+			return nil, configdiff.Field("startOnLogin", fmt.Errorf("start on login: %w", err))
+		}
+	}
+	if err := a.settings.Update(s); err != nil {
+		return nil, err
+	}
+	return SettingsChanges(old, s), nil
+}
+
+// SettingsChanges reports the app-local settings that differ between old
+// and s. They are read as they are used, so every change applies live.
+// Shared by the desktop (Wails) and HTTP server APIs.
+func SettingsChanges(old, s settings.Settings) []configdiff.Change {
+	keys, err := configdiff.Keys(old, s)
+	if err != nil {
+		return []configdiff.Change{} // settings.Settings always marshals
+	}
+	return configdiff.Report(keys, nil)
+}
+
+// applyStartOnLogin registers or unregisters the running executable to
+// start at login.
+func applyStartOnLogin(enabled bool) error {
+	if !enabled {
+		return autostart.Disable()
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	return autostart.Enable(exe)
+}
+
+// currentSettings returns the live settings, or every option off before
+// Startup.
+func (a *App) currentSettings() settings.Settings {
+	if a.settings == nil {
+		return settings.Settings{}
+	}
+	return a.settings.Get()
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"flacidal/internal/configdiff"
+	"flacidal/internal/settings"
+)
+
+func TestSettings_NilStore(t *testing.T) {
+	a := &App{}
+	if got := a.GetSettings(); got.DiscSubfolders {
+		t.Errorf("GetSettings() before Startup = %+v, want defaults", got)
+	}
+	if _, err := a.SaveSettings(settings.Settings{DiscSubfolders: true}); err == nil {
+		t.Error("SaveSettings() with nil store should error")
+	}
+}
+
+func TestSettings_SaveThenGet(t *testing.T) {
+	store, err := settings.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &App{settings: store}
+	changes, err := a.SaveSettings(settings.Settings{DiscSubfolders: true})
+	if err != nil {
+		t.Fatalf("SaveSettings: %v", err)
+	}
+	if !a.GetSettings().DiscSubfolders {
+		t.Error("GetSettings did not return the saved value")
+	}
+	want := []configdiff.Change{{Key: "discSubfolders", Effect: configdiff.Live}}
+	if !slices.Equal(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"flacidal/internal/settings"
+	"flacidal/internal/silence"
+)
+
+// =============================================================================
+// Silence Trimming (exposed to frontend)
+// =============================================================================
+
+// TrimSilence trims the leading and trailing silence of the FLAC at path,
+// using the silence thresholds in settings. With dryRun the silence is only
+// detected, for a preview.
+func (a *App) TrimSilence(path string, dryRun bool) (*silence.Report, error) {
+	report, err := TrimSilence(context.Background(), path, a.currentSettings(), dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if a.logBuffer != nil && report.Trimmed {
+		a.logBuffer.Info(fmt.Sprintf("Trimmed silence from %s: %s", filepath.Base(path), SilenceSummary(report)))
+	}
+	return report, nil
+}
+
+// TrimSilence detects the silence in the FLAC at path with FFmpeg and,
+// unless dryRun, trims it from both ends. Shared by the desktop (Wails) and
+// HTTP server APIs, and by FinishDownload when the TrimSilence setting is
+// on.
+func TrimSilence(ctx context.Context, path string, s settings.Settings, dryRun bool) (*silence.Report, error) {
+	if err := CheckStrict(s, path); err != nil {
+		return nil, err
+	}
+	ffmpeg, err := FFmpegPath()
+	if err != nil {
+		return nil, err
+	}
+	report, err := silence.Detect(ctx, silence.ExecRunner, ffmpeg, path, SilenceOptions(s))
+	if err != nil || dryRun {
+		return report, err
+	}
+	return report, silence.Trim(ctx, silence.ExecRunner, ffmpeg, report)
+}
+
+// SilenceOptions converts the silence settings into detection options.
+func SilenceOptions(s settings.Settings) silence.Options {
+	return silence.Options{
+		Threshold:   s.SilenceThreshold,
+		MinDuration: time.Duration(s.SilenceMinSeconds * float64(time.Second)),
+	}
+}
+
+// SilenceSummary describes a silence report in one line for the logs.
+func SilenceSummary(r *silence.Report) string {
+	return fmt.Sprintf("%.1fs leading, %.1fs trailing", r.Leading, r.Trailing)
+}
@@ -0,0 +1,19 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"flacidal/internal/settings"
+	"flacidal/internal/silence"
+)
+
+func TestSilenceOptions(t *testing.T) {
+	got := SilenceOptions(settings.Settings{SilenceThreshold: -50, SilenceMinSeconds: 1.5})
+	if got != (silence.Options{Threshold: -50, MinDuration: 1500 * time.Millisecond}) {
+		t.Errorf("options = %+v", got)
+	}
+	if got := SilenceOptions(settings.Settings{}); got != (silence.Options{}) {
+		t.Errorf("defaults = %+v, want zero options", got)
+	}
+}
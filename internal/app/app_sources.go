@@ -441,6 +441,7 @@ func (a *App) FetchContentFromURL(rawURL string) (map[string]interface{}, error)
 	if resolvedViaOdesli {
 		result["resolvedVia"] = "odesli"
 	}
+	NoteOrigin(a.origins, source.Name(), id, contentType, rawURL)
 
 	// Helper to convert SourceTrack to frontend-compatible format
 	convertTrack := func(t core.SourceTrack) map[string]interface{} {
@@ -458,6 +459,7 @@ func (a *App) FetchContentFromURL(rawURL string) (map[string]interface{}, error)
 			"album":       t.Album,
 			"duration":    t.Duration,
 			"trackNumber": t.TrackNumber,
+			"discNumber":  t.DiscNumber,
 			"coverUrl":    t.CoverURL,
 			"explicit":    t.Explicit,
 			"isrc":        t.ISRC,
@@ -500,6 +502,7 @@ func (a *App) FetchContentFromURL(rawURL string) (map[string]interface{}, error)
 		}
 		result["title"] = playlist.Title
 		result["creator"] = playlist.Creator
+		result["description"] = playlist.Description
 		result["coverUrl"] = playlist.CoverURL
 		result["tracks"] = convertTracks(playlist.Tracks)
 
@@ -522,6 +525,7 @@ func (a *App) FetchContentFromURL(rawURL string) (map[string]interface{}, error)
 				ISRC:        t.ISRC,
 				Duration:    t.Duration,
 				TrackNumber: t.TrackNum,
+				DiscNumber:  t.DiscNumber,
 				CoverURL:    t.CoverURL,
 				Explicit:    t.Explicit,
 				SourceURL:   t.TidalURL,
@@ -530,6 +534,7 @@ func (a *App) FetchContentFromURL(rawURL string) (map[string]interface{}, error)
 		}
 		result["tracks"] = convertTracks(tidalTracks)
 	}
+	DescribeOrigin(a.origins, source.Name(), id, result)
 
 	if a.logBuffer != nil {
 		a.logBuffer.Info(fmt.Sprintf("Fetched %s from %s: %s", contentType, source.DisplayName(), id))
@@ -614,16 +619,17 @@ func (a *App) QueueDiscographyAlbums(spotifyAlbumURLs []string, outputDir string
 			continue
 		}
 
-		artistFolder := core.SanitizeFileName(tidalAlbum.Artist)
+		artistFolder := a.folderName(tidalAlbum.Artist)
 		if artistFolder == "" {
-			artistFolder = core.SanitizeFileName(artistName)
+			artistFolder = a.folderName(artistName)
 		}
-		albumDir := filepath.Join(outputDir, artistFolder, core.SanitizeFileName(tidalAlbum.Title))
+		albumDir := filepath.Join(outputDir, artistFolder, a.folderName(tidalAlbum.Title))
 		if err := os.MkdirAll(albumDir, 0755); err != nil {
 			continue
 		}
 
-		n := a.downloadManager.QueueMultiple(album.Tracks, albumDir)
+		n := a.downloadManager.QueueMultiple(a.fitTidalTracks(album.Tracks, albumDir), albumDir)
+		RememberTidalTracks(&a.postTracks, album.Tracks, "album")
 		queued += n
 	}
 
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/naming"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/split"
+)
+
+// =============================================================================
+// Album Splitting (exposed to frontend)
+// =============================================================================
+
+// splitFileNameFormat names split tracks when no filename template is
+// configured; unlike downloads, their number is all that orders them.
+const splitFileNameFormat = "{track} - {title}"
+
+// SplitReport is the outcome of splitting an album rip.
+type SplitReport struct {
+	File   string        `json:"file"`
+	Album  string        `json:"album"`
+	Split  int           `json:"split"`
+	Pieces []split.Piece `json:"pieces"`
+}
+
+// SplitAlbum splits the single-file album rip file (FLAC or WAV) into one
+// tagged FLAC per track beside it. cueOrTracklist gives the track
+// positions: a .cue file, a text file or pasted text with one start time
+// per track, or an album URL whose track lengths are used. Left empty, the
+// .cue file named like the rip is used.
+func (a *App) SplitAlbum(file, cueOrTracklist string) (*SplitReport, error) {
+	report, err := SplitAlbum(context.Background(), a.sourceManager, file, cueOrTracklist, a.postOptions())
+	if err != nil {
+		return nil, err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Split %s: %d of %d tracks", filepath.Base(file), report.Split, len(report.Pieces)))
+	}
+	return report, nil
+}
+
+// SplitAlbum reads the track positions cueOrTracklist gives (see
+// LoadSplitAlbum) and cuts file into one FLAC per track with ffmpeg, named
+// with opts' filename template. Shared by the desktop (Wails) and HTTP
+// server APIs.
+func SplitAlbum(ctx context.Context, sm *core.SourceManager, file, cueOrTracklist string, opts postprocess.Options) (*SplitReport, error) {
+	if file == "" {
+		return nil, fmt.Errorf("no file specified")
+	}
+	if err := CheckStrict(opts.Settings, file); err != nil {
+		return nil, err
+	}
+	stream, err := split.Probe(file)
+	if err != nil {
+		return nil, err
+	}
+	album, err := LoadSplitAlbum(sm, file, cueOrTracklist)
+	if err != nil {
+		return nil, err
+	}
+	ffmpeg, err := FFmpegPath()
+	if err != nil {
+		return nil, err
+	}
+	pieces, err := split.Plan(album, stream, filepath.Dir(file), splitName(album, opts))
+	if err != nil {
+		return nil, err
+	}
+	report := &SplitReport{File: file, Album: album.Title}
+	report.Pieces = split.Split(ctx, split.ExecRunner, ffmpeg, file, album, pieces)
+	for _, p := range report.Pieces {
+		if p.Error == "" {
+			report.Split++
+		}
+	}
+	return report, nil
+}
+
+// LoadSplitAlbum returns the album and track positions spec describes for
+// the rip at file: an http(s) album URL, resolved through sm, whose track
+// lengths are added up (split.FromDurations); a .cue file; any other file,
+// or text spanning several lines, as a tracklist (split.ParseTracklist).
+// An empty spec means the .cue file beside file with the same name.
+func LoadSplitAlbum(sm *core.SourceManager, file, spec string) (*split.Album, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		spec = strings.TrimSuffix(file, filepath.Ext(file)) + ".cue"
+		if _, err := os.Stat(spec); err != nil {
+			return nil, fmt.Errorf("no cue sheet or tracklist given, and no %s", filepath.Base(spec))
+		}
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		if sm == nil {
+			return nil, fmt.Errorf("source manager not initialized")
+		}
+		content, err := ResolveContent(sm, spec)
+		if err != nil {
+			return nil, err
+		}
+		return splitAlbumFromContent(content)
+	case strings.Contains(spec, "\n"):
+		return split.ParseTracklist(spec)
+	}
+
+	data, err := os.ReadFile(spec)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(spec), ".cue") {
+		return split.ParseCue(string(data))
+	}
+	return split.ParseTracklist(string(data))
+}
+
+// splitAlbumFromContent builds a split album from a resolved album's
+// tracks, each starting where the lengths of the ones before it add up to.
+func splitAlbumFromContent(content *ResolvedContent) (*split.Album, error) {
+	if content.Type != "album" {
+		return nil, fmt.Errorf("%s URLs can't be used to split an album", content.Type)
+	}
+	album := &split.Album{Title: content.Title, Performer: content.Artist}
+	durations := make([]int, len(content.Tracks))
+	for i, t := range content.Tracks {
+		if album.Date == "" && len(t.Year) >= 4 {
+			album.Date = t.Year[:4]
+		}
+		track := split.Track{Number: i + 1, Title: t.Title, ISRC: t.ISRC}
+		if t.Artist != content.Artist {
+			track.Performer = t.Artist
+		}
+		album.Tracks = append(album.Tracks, track)
+		durations[i] = t.Duration
+	}
+	if err := split.FromDurations(album, durations); err != nil {
+		return nil, err
+	}
+	return album, nil
+}
+
+// splitName names split tracks with opts' filename template.
+func splitName(album *split.Album, opts postprocess.Options) func(split.Track) string {
+	tmpl := opts.FileNameFormat
+	if tmpl == "" {
+		tmpl = splitFileNameFormat
+	}
+	return func(t split.Track) string {
+		artist := t.Performer
+		if artist == "" {
+			artist = album.Performer
+		}
+		name := naming.SanitizeComponent(naming.Render(tmpl, naming.Values{
+			Title:       t.Title,
+			Artist:      artist,
+			AlbumArtist: album.Performer,
+			Album:       album.Title,
+			Year:        album.Date,
+			ISRC:        t.ISRC,
+			Track:       t.Number,
+		}))
+		if name == "" || strings.Contains(name, "{") {
+			name = fmt.Sprintf("%02d", t.Number)
+		}
+		return name
+	}
+}
+
+// FFmpegPath returns the ffmpeg FLACidal installed, or else the one on the
+// PATH. Shared by the desktop (Wails) and HTTP server APIs.
+func FFmpegPath() (string, error) {
+	if core.IsFFmpegInstalledLocally() {
+		return core.GetLocalFFmpegPath(), nil
+	}
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return "", fmt.Errorf("FFmpeg not available")
+	}
+	return path, nil
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/postprocess"
+	"flacidal/internal/split"
+)
+
+func TestLoadSplitAlbum_CueBesideRip(t *testing.T) {
+	dir := t.TempDir()
+	rip := filepath.Join(dir, "Album.flac")
+	cue := "TITLE \"Album\"\nFILE \"Album.flac\" WAVE\nTRACK 01 AUDIO\nINDEX 01 00:00:00\nTRACK 02 AUDIO\nINDEX 01 01:00:00\n"
+	os.WriteFile(filepath.Join(dir, "Album.cue"), []byte(cue), 0644)
+
+	album, err := LoadSplitAlbum(nil, rip, "")
+	if err != nil {
+		t.Fatalf("LoadSplitAlbum: %v", err)
+	}
+	if album.Title != "Album" || len(album.Tracks) != 2 || album.Tracks[1].Start != 60*split.FramesPerSecond {
+		t.Errorf("album = %+v", album)
+	}
+
+	pasted, err := LoadSplitAlbum(nil, rip, "0:00 One\n3:00 Two\n")
+	if err != nil || len(pasted.Tracks) != 2 || pasted.Tracks[1].Title != "Two" {
+		t.Errorf("pasted tracklist = %+v, %v", pasted, err)
+	}
+	if _, err := LoadSplitAlbum(nil, filepath.Join(dir, "Other.flac"), ""); err == nil {
+		t.Error("LoadSplitAlbum without a cue sheet succeeded")
+	}
+}
+
+func TestSplitAlbumFromContent(t *testing.T) {
+	content := &ResolvedContent{Type: "album", Title: "Album", Artist: "Artist", Tracks: []core.SourceTrack{
+		{Title: "One", Artist: "Artist", Year: "2026-01-01", Duration: 120},
+		{Title: "Two", Artist: "Guest", Duration: 200},
+	}}
+	album, err := splitAlbumFromContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if album.Date != "2026" || album.Tracks[0].Performer != "" || album.Tracks[1].Performer != "Guest" ||
+		album.Tracks[1].Start != 120*split.FramesPerSecond {
+		t.Errorf("album = %+v", album)
+	}
+	if _, err := splitAlbumFromContent(&ResolvedContent{Type: "playlist"}); err == nil {
+		t.Error("playlist accepted")
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	album := &split.Album{Title: "Album", Performer: "Artist"}
+	name := splitName(album, postprocess.Options{})
+	if got := name(split.Track{Number: 3, Title: "A/B"}); got != "03 - A-B" {
+		t.Errorf("default name = %q", got)
+	}
+	name = splitName(album, postprocess.Options{FileNameFormat: "{artist} - {title}"})
+	if got := name(split.Track{Number: 1, Title: "One", Performer: "Guest"}); got != "Guest - One" {
+		t.Errorf("templated name = %q", got)
+	}
+}
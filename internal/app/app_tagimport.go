@@ -0,0 +1,358 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // cover dimensions
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/lyricscache"
+	"flacidal/internal/lyricsfile"
+	"flacidal/internal/lyricsmatch"
+	"flacidal/internal/postprocess"
+	"flacidal/internal/tagedit"
+)
+
+// =============================================================================
+// Import and Tag (exposed to frontend)
+// =============================================================================
+
+// TagImportOptions configure TagImport.
+type TagImportOptions struct {
+	Post      postprocess.Options
+	OutputDir string // files end up under OutputDir/<content title>
+	DryRun    bool   // only report the matches; no file is touched
+
+	EmbedCover      bool               // embed the track's cover unless the file has one
+	SaveFolderCover bool               // save cover.jpg next to the tracks
+	EmbedLyrics     bool               // embed lyrics from LRCLIB
+	SaveLyricsFile  bool               // also save them as a .lrc sidecar
+	LyricsOutput    lyricsfile.Output  // tags, sidecar or both; see Settings.LyricsOutput
+	Lyrics          *lyricscache.Cache // LRCLIB lookup cache; nil looks every track up
+}
+
+// TagImportResult reports one file of a tag import, or one track no file
+// matched (File empty).
+type TagImportResult struct {
+	File    string `json:"file,omitempty"`
+	Dest    string `json:"dest,omitempty"` // final path; empty on dry runs and errors
+	TrackID string `json:"trackId,omitempty"`
+	Track   string `json:"track,omitempty"` // "Artist - Title"; empty for unmatched files
+	By      string `json:"by,omitempty"`    // "order", "duration" or "title", see postprocess.MatchFiles
+	Warning string `json:"warning,omitempty"`
+	Error   string `json:"error,omitempty"`
+
+	// Changes lists, on dry runs, the tags the import would change: the
+	// current value next to the one that replaces it.
+	Changes []tagedit.Change `json:"changes,omitempty"`
+}
+
+// TagImportReport is the outcome of a tag import.
+type TagImportReport struct {
+	Source  string            `json:"source"`
+	Type    string            `json:"type"`
+	Title   string            `json:"title"`
+	Tagged  int               `json:"tagged"`
+	Results []TagImportResult `json:"results"`
+}
+
+// coverClient fetches cover art for imported files.
+var coverClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxCoverSize caps a downloaded cover image.
+const maxCoverSize = 16 << 20
+
+// ImportAndTagFolder tags the FLAC files in dir — downloaded outside
+// FLACidal, e.g. from an exported manifest — as the tracks of rawURL and
+// files them into the download folder as if FLACidal had downloaded them.
+// With dryRun only the file-to-track matches and the tags each file would
+// change are reported.
+func (a *App) ImportAndTagFolder(dir, rawURL string, dryRun bool) (*TagImportReport, error) {
+	if a.sourceManager == nil {
+		return nil, fmt.Errorf("source manager not initialized")
+	}
+	opts := TagImportOptions{
+		Post:      a.postOptions(),
+		OutputDir: a.GetDownloadFolder(),
+		DryRun:    dryRun,
+	}
+	if a.config != nil {
+		opts.EmbedCover = a.config.EmbedCover
+		opts.SaveFolderCover = a.config.SaveFolderCover
+		opts.EmbedLyrics = a.config.EmbedLyrics
+		opts.SaveLyricsFile = a.config.SaveLyricsFile
+	}
+	opts.LyricsOutput = a.currentSettings().LyricsOutput
+	opts.Lyrics = a.lyrics
+	report, err := TagImport(a.sourceManager, dir, rawURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	if a.logBuffer != nil && !dryRun {
+		a.logBuffer.Info(fmt.Sprintf("Tag import of %s as %s: %s", dir, report.Title, TagImportSummary(report)))
+	}
+	return report, nil
+}
+
+// TagImport resolves rawURL through sm (see ResolveContent), matches the
+// FLAC files in dir to its tracks (postprocess.MatchFiles) and imports
+// each match with postprocess.Import, then adds the cover and lyrics the
+// options ask for. Shared by the desktop (Wails) and HTTP server APIs.
+func TagImport(sm *core.SourceManager, dir, rawURL string, opts TagImportOptions) (*TagImportReport, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("no folder specified")
+	}
+	files, err := postprocess.ReadLocalFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no FLAC files in %s", dir)
+	}
+	content, err := ResolveContent(sm, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return TagImportFiles(content, files, opts), nil
+}
+
+// TagImportFiles is TagImport for content and files already resolved.
+func TagImportFiles(content *ResolvedContent, files []postprocess.LocalFile, opts TagImportOptions) *TagImportReport {
+	tracks := ImportTracks(content)
+	report := &TagImportReport{Source: content.Source, Type: content.Type, Title: content.Title}
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Dir(files[0].Path)
+	}
+	if content.Title != "" {
+		outputDir = filepath.Join(outputDir, SafeFolderName(content.Title, opts.Post.FilenameUnicode))
+	}
+
+	matchedFiles := make([]bool, len(files))
+	matchedTracks := make([]bool, len(tracks))
+	covers := map[string]*flacmeta.Picture{} // by URL, shared by an album's tracks
+	for _, m := range postprocess.MatchFiles(files, tracks, opts.Post.MatchNormalization) {
+		matchedFiles[m.File], matchedTracks[m.Track] = true, true
+		t := tracks[m.Track]
+		result := TagImportResult{
+			File:    files[m.File].Path,
+			TrackID: t.ID,
+			Track:   t.Artist + " - " + t.Title,
+			By:      m.By,
+		}
+		if opts.DryRun {
+			changes, err := postprocess.PreviewImport(result.File, t, opts.Post)
+			if err != nil {
+				result.Error = err.Error()
+			}
+			result.Changes = changes
+		} else {
+			importFile(&result, t, content.Tracks[m.Track].CoverURL, covers, opts, outputDir)
+			if result.Error == "" {
+				report.Tagged++
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	for i, f := range files {
+		if !matchedFiles[i] {
+			report.Results = append(report.Results, TagImportResult{File: f.Path, Error: "no track matches this file"})
+		}
+	}
+	for i, t := range tracks {
+		if !matchedTracks[i] {
+			report.Results = append(report.Results, TagImportResult{TrackID: t.ID, Track: t.Artist + " - " + t.Title, Error: "no file matches this track"})
+		}
+	}
+	return report
+}
+
+// importFile imports one matched file, recording the outcome in result.
+// Cover and lyrics failures are warnings: the file is tagged and filed.
+func importFile(result *TagImportResult, t postprocess.Track, coverURL string, covers map[string]*flacmeta.Picture, opts TagImportOptions, outputDir string) {
+	if err := CheckStrict(opts.Post.Settings, result.File); err != nil {
+		result.Error = err.Error()
+		return
+	}
+	dest, err := postprocess.Import(result.File, t, opts.Post, outputDir)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	result.Dest = dest
+
+	var warnings []string
+	if (opts.EmbedCover || opts.SaveFolderCover) && coverURL != "" {
+		pic, ok := covers[coverURL]
+		if !ok {
+			pic, err = fetchCover(coverURL)
+			if err != nil {
+				warnings = append(warnings, "cover: "+err.Error())
+			}
+			covers[coverURL] = pic // nil after a failure, so it isn't retried per track
+		}
+		if pic != nil {
+			if err := applyCover(dest, pic, opts); err != nil {
+				warnings = append(warnings, "cover: "+err.Error())
+			}
+		}
+	}
+	if opts.EmbedLyrics {
+		if err := embedImportLyrics(dest, t, opts); err != nil {
+			warnings = append(warnings, "lyrics: "+err.Error())
+		}
+	}
+	result.Warning = strings.Join(warnings, "; ")
+}
+
+// fetchCover downloads a cover image as a front-cover PICTURE.
+func fetchCover(url string) (*flacmeta.Picture, error) {
+	resp, err := coverClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCoverSize))
+	if err != nil {
+		return nil, err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("not an image: %w", err)
+	}
+	return &flacmeta.Picture{
+		Type:   flacmeta.PictureFrontCover,
+		MIME:   "image/" + format,
+		Width:  uint32(cfg.Width),
+		Height: uint32(cfg.Height),
+		Depth:  24,
+		Data:   data,
+	}, nil
+}
+
+// applyCover embeds pic in the FLAC at path unless it already has a front
+// cover, and saves it as cover.jpg beside it when asked.
+func applyCover(path string, pic *flacmeta.Picture, opts TagImportOptions) error {
+	if opts.SaveFolderCover && pic.MIME == "image/jpeg" {
+		cover := filepath.Join(filepath.Dir(path), "cover.jpg")
+		if _, err := os.Stat(cover); os.IsNotExist(err) {
+			if err := os.WriteFile(cover, pic.Data, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	if !opts.EmbedCover {
+		return nil
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return err
+	}
+	if f.HasPicture(flacmeta.PictureFrontCover) {
+		return nil
+	}
+	f.SetPicture(*pic)
+	return f.Save()
+}
+
+// embedImportLyrics looks up t's lyrics on LRCLIB and embeds them, the way
+// App.FetchAndEmbedLyrics does for existing files: unsure lyrics are
+// refused, and instrumentals tagged instead.
+func embedImportLyrics(path string, t postprocess.Track, opts TagImportOptions) error {
+	lyrics, err := LookupLyrics(opts.Lyrics, nil, t.Title, t.Artist, t.Duration)
+	if err != nil {
+		return err
+	}
+	if err := AcceptLyrics(path, MatchLyrics(lyrics, float64(t.Duration))); err != nil {
+		if errors.Is(err, lyricsmatch.ErrInstrumental) {
+			return nil
+		}
+		return err
+	}
+	if err := SaveLyrics(opts.LyricsOutput, path, lyrics.Plain, lyrics.Synced); err != nil {
+		return err
+	}
+	if opts.SaveLyricsFile && !opts.LyricsOutput.File() {
+		return core.SaveLyricsFile(path, lyrics.Synced, lyrics.Plain)
+	}
+	return nil
+}
+
+// ImportTracks converts content's tracks into the metadata tag imports
+// write, with the same disc, playlist and compilation handling as
+// RememberTidalTracks gives queued downloads.
+func ImportTracks(content *ResolvedContent) []postprocess.Track {
+	maxDisc := 0
+	artists := make([]string, len(content.Tracks))
+	for i, t := range content.Tracks {
+		if t.DiscNumber > maxDisc {
+			maxDisc = t.DiscNumber
+		}
+		artists[i] = t.Artist
+	}
+	playlist := content.Type == "playlist"
+	tracks := make([]postprocess.Track, len(content.Tracks))
+	for i, t := range content.Tracks {
+		year := t.Year
+		if len(year) > 4 {
+			year = year[:4]
+		}
+		track := postprocess.Track{
+			ID:            t.ID,
+			Title:         t.Title,
+			Artist:        t.Artist,
+			Album:         t.Album,
+			Year:          year,
+			ISRC:          t.ISRC,
+			Duration:      t.Duration,
+			TrackNumber:   t.TrackNumber,
+			DiscNumber:    t.DiscNumber,
+			TotalDiscs:    maxDisc,
+			PlaylistIndex: i + 1,
+			Source:        content.Source,
+		}
+		if len(t.Artists) > 1 {
+			track.Artists = t.Artists
+		}
+		if content.Type == "album" {
+			track.AlbumArtist = content.Artist
+		}
+		if playlist {
+			track.PlaylistNum = i + 1
+			track.PlaylistTotal = len(content.Tracks)
+		}
+		tracks[i] = track
+	}
+	if content.Type == "album" && postprocess.IsCompilation(content.Artist, artists) {
+		postprocess.MarkCompilation(tracks)
+	}
+	return tracks
+}
+
+// TagImportSummary describes a tag import's results in one line for the
+// logs.
+func TagImportSummary(report *TagImportReport) string {
+	files, tracks := 0, 0
+	for _, r := range report.Results {
+		if r.File != "" {
+			files++
+		} else {
+			tracks++
+		}
+	}
+	return fmt.Sprintf("%d of %d files tagged, %d tracks without a file", report.Tagged, files, tracks)
+}
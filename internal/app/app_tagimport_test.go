@@ -0,0 +1,64 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/postprocess"
+)
+
+func TestImportTracks(t *testing.T) {
+	content := &ResolvedContent{Source: "qobuz", Type: "album", Title: "Hits", Artist: "Various Artists", Tracks: []core.SourceTrack{
+		{ID: "1", Title: "A", Artist: "X", Artists: []string{"X", "Y"}, Year: "2026-03-01", DiscNumber: 1, Duration: 180},
+		{ID: "2", Title: "B", Artist: "Z", DiscNumber: 2},
+	}}
+	got := ImportTracks(content)
+	if len(got) != 2 || got[0].Year != "2026" || got[0].TotalDiscs != 2 || len(got[0].Artists) != 2 || got[0].Duration != 180 {
+		t.Fatalf("tracks = %+v", got)
+	}
+	if !got[1].Compilation || got[1].AlbumArtist != "Various Artists" || got[1].Artists != nil {
+		t.Errorf("track 2 = %+v, want a compilation track with one artist", got[1])
+	}
+}
+
+func TestTagImportFiles(t *testing.T) {
+	src := t.TempDir()
+	writeTestFLAC(t, filepath.Join(src, "01.flac"), nil, nil, 16)
+	writeTestFLAC(t, filepath.Join(src, "02.flac"), nil, nil, 16)
+	files, err := postprocess.ReadLocalFiles(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := &ResolvedContent{Source: "tidal", Type: "album", Title: "Album", Artist: "Artist", Tracks: []core.SourceTrack{
+		{ID: "1", Title: "One", Artist: "Artist", Album: "Album", TrackNumber: 1, Duration: 180},
+		{ID: "2", Title: "Two", Artist: "Artist", Album: "Album", TrackNumber: 2, Duration: 180},
+		{ID: "3", Title: "Three", Artist: "Artist", Album: "Album", TrackNumber: 3, Duration: 240},
+	}}
+	out := t.TempDir()
+	opts := TagImportOptions{OutputDir: out, Post: postprocess.Options{FileNameFormat: "{track} - {title}"}}
+
+	dry := TagImportFiles(content, files, TagImportOptions{OutputDir: out, DryRun: true})
+	if dry.Tagged != 0 || len(dry.Results) != 3 {
+		t.Fatalf("dry run = %+v", dry)
+	}
+	if _, err := os.Stat(files[0].Path); err != nil {
+		t.Fatal("dry run moved a file")
+	}
+
+	report := TagImportFiles(content, files, opts)
+	if report.Tagged != 2 {
+		t.Fatalf("report = %+v, want 2 files tagged", report)
+	}
+	if want := filepath.Join(out, "Album", "02 - Two.flac"); report.Results[1].Dest != want {
+		t.Errorf("dest = %q, want %q", report.Results[1].Dest, want)
+	}
+	if last := report.Results[2]; last.File != "" || last.TrackID != "3" || last.Error == "" {
+		t.Errorf("unmatched track = %+v", last)
+	}
+	if got := TagImportSummary(report); got != "2 of 2 files tagged, 1 tracks without a file" {
+		t.Errorf("summary = %q", got)
+	}
+}
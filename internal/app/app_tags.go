@@ -0,0 +1,219 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"flacidal/internal/settings"
+	"flacidal/internal/tagedit"
+	"flacidal/internal/tagrules"
+)
+
+// =============================================================================
+// Batch Tag Editor (exposed to frontend)
+// =============================================================================
+
+// PreviewSetTags lists, per file, the tag changes SetTags would make.
+func (a *App) PreviewSetTags(files []string, tags map[string]string, mode string) ([]tagedit.Result, error) {
+	return SetTags(a.currentSettings(), files, tags, tagedit.Mode(mode), true)
+}
+
+// SetTags sets tags on every file at once. In "merge" mode other tags are
+// kept and an empty value removes a tag; in "replace" mode the files keep
+// only the given tags.
+func (a *App) SetTags(files []string, tags map[string]string, mode string) ([]tagedit.Result, error) {
+	results, err := SetTags(a.currentSettings(), files, tags, tagedit.Mode(mode), false)
+	if err != nil {
+		return nil, err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Updated tags of %d/%d files", TagsWritten(results), len(files)))
+	}
+	return results, nil
+}
+
+// PreviewStripTags lists, per file, what StripTags would remove.
+func (a *App) PreviewStripTags(files []string, opts tagedit.StripOptions) ([]tagedit.Result, error) {
+	return StripTags(a.currentSettings(), files, opts, true)
+}
+
+// StripTags removes the named tags, all tags and/or the embedded covers
+// from every file, e.g. to sanitize files before sharing them or to
+// re-tag them from scratch.
+func (a *App) StripTags(files []string, opts tagedit.StripOptions) ([]tagedit.Result, error) {
+	results, err := StripTags(a.currentSettings(), files, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Stripped tags of %d/%d files", TagsWritten(results), len(files)))
+	}
+	return results, nil
+}
+
+// PreviewTagsFromNames lists, per file, the tags TagsFromNames would read
+// from its name with pattern, e.g. "{track} - {artist} - {title}".
+func (a *App) PreviewTagsFromNames(files []string, pattern string) ([]tagedit.Result, error) {
+	return TagsFromNames(a.currentSettings(), files, pattern, true)
+}
+
+// TagsFromNames tags files with what pattern reads from their names,
+// keeping their other tags. Files whose names don't match are reported
+// and left alone.
+func (a *App) TagsFromNames(files []string, pattern string) ([]tagedit.Result, error) {
+	results, err := TagsFromNames(a.currentSettings(), files, pattern, false)
+	if err != nil {
+		return nil, err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Tagged %d/%d files from their names", TagsWritten(results), len(files)))
+	}
+	return results, nil
+}
+
+// PreviewNormalizeTags lists, per file, the tag changes NormalizeTags would
+// make with rules.
+func (a *App) PreviewNormalizeTags(files []string, rules tagrules.Rules) ([]tagedit.Result, error) {
+	return NormalizeTags(a.currentSettings(), files, rules, true)
+}
+
+// NormalizeTags applies normalization rules — title case, "feat.", no
+// remaster suffixes or explicit markers — to the title, artist and album
+// tags of every file.
+func (a *App) NormalizeTags(files []string, rules tagrules.Rules) ([]tagedit.Result, error) {
+	results, err := NormalizeTags(a.currentSettings(), files, rules, false)
+	if err != nil {
+		return nil, err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Normalized tags of %d/%d files", TagsWritten(results), len(files)))
+	}
+	return results, nil
+}
+
+// PreviewRemapGenres lists, per file, the genre changes RemapGenres would
+// make.
+func (a *App) PreviewRemapGenres(files []string) ([]tagedit.Result, error) {
+	return RemapGenres(a.currentSettings(), files, true)
+}
+
+// RemapGenres renames the genres of every file with the GenreMap setting.
+func (a *App) RemapGenres(files []string) ([]tagedit.Result, error) {
+	results, err := RemapGenres(a.currentSettings(), files, false)
+	if err != nil {
+		return nil, err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Remapped genres of %d/%d files", TagsWritten(results), len(files)))
+	}
+	return results, nil
+}
+
+// SetTags edits the tags of files (see tagedit.Edit), refusing broken
+// files in strict mode. Shared by the desktop (Wails) and HTTP server APIs.
+func SetTags(s settings.Settings, files []string, tags map[string]string, mode tagedit.Mode, dryRun bool) ([]tagedit.Result, error) {
+	fields, err := tagedit.Fields(tags, mode)
+	if err != nil {
+		return nil, err
+	}
+	return StrictResults(s, files, func(files []string) []tagedit.Result {
+		results := make([]tagedit.Result, len(files))
+		for i, f := range files {
+			results[i] = tagedit.Edit(f, fields, mode, dryRun)
+		}
+		return results
+	}, func(path, reason string) tagedit.Result {
+		return tagedit.Result{Path: path, Changes: []tagedit.Change{}, Error: reason}
+	}), nil
+}
+
+// StripTags strips files (see tagedit.Strip), refusing broken files in
+// strict mode. Shared by the desktop (Wails) and HTTP server APIs.
+func StripTags(s settings.Settings, files []string, opts tagedit.StripOptions, dryRun bool) ([]tagedit.Result, error) {
+	opts, err := opts.Check()
+	if err != nil {
+		return nil, err
+	}
+	return StrictResults(s, files, func(files []string) []tagedit.Result {
+		results := make([]tagedit.Result, len(files))
+		for i, f := range files {
+			results[i] = tagedit.Strip(f, opts, dryRun)
+		}
+		return results
+	}, func(path, reason string) tagedit.Result {
+		return tagedit.Result{Path: path, Changes: []tagedit.Change{}, Error: reason}
+	}), nil
+}
+
+// TagsFromNames tags files from their names (see tagedit.FromName),
+// refusing broken files in strict mode. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func TagsFromNames(s settings.Settings, files []string, pattern string, dryRun bool) ([]tagedit.Result, error) {
+	p, err := tagedit.ParseNamePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return StrictResults(s, files, func(files []string) []tagedit.Result {
+		results := make([]tagedit.Result, len(files))
+		for i, f := range files {
+			results[i] = tagedit.FromName(f, p, dryRun)
+		}
+		return results
+	}, func(path, reason string) tagedit.Result {
+		return tagedit.Result{Path: path, Changes: []tagedit.Change{}, Error: reason}
+	}), nil
+}
+
+// NormalizeTags normalizes the tags of files (see tagedit.Normalize),
+// refusing broken files in strict mode. Shared by the desktop (Wails) and
+// HTTP server APIs.
+func NormalizeTags(s settings.Settings, files []string, rules tagrules.Rules, dryRun bool) ([]tagedit.Result, error) {
+	if rules.IsZero() {
+		return nil, errNoRules
+	}
+	return StrictResults(s, files, func(files []string) []tagedit.Result {
+		results := make([]tagedit.Result, len(files))
+		for i, f := range files {
+			results[i] = tagedit.Normalize(f, rules, dryRun)
+		}
+		return results
+	}, func(path, reason string) tagedit.Result {
+		return tagedit.Result{Path: path, Changes: []tagedit.Change{}, Error: reason}
+	}), nil
+}
+
+// errNoRules rejects a normalization with every rule off.
+var errNoRules = errors.New("no normalization rules selected")
+
+// RemapGenres renames the genres of files with s.GenreMap (see
+// tagedit.RemapGenres), refusing broken files in strict mode. Shared by the
+// desktop (Wails) and HTTP server APIs.
+func RemapGenres(s settings.Settings, files []string, dryRun bool) ([]tagedit.Result, error) {
+	if len(s.GenreMap) == 0 {
+		return nil, errNoGenreMap
+	}
+	return StrictResults(s, files, func(files []string) []tagedit.Result {
+		results := make([]tagedit.Result, len(files))
+		for i, f := range files {
+			results[i] = tagedit.RemapGenres(f, s.GenreMap, dryRun)
+		}
+		return results
+	}, func(path, reason string) tagedit.Result {
+		return tagedit.Result{Path: path, Changes: []tagedit.Change{}, Error: reason}
+	}), nil
+}
+
+// errNoGenreMap rejects remapping genres before any mapping is set up.
+var errNoGenreMap = errors.New("no genre mappings set up in settings")
+
+// TagsWritten counts the files a SetTags, StripTags, TagsFromNames,
+// NormalizeTags or RemapGenres run rewrote.
+func TagsWritten(results []tagedit.Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Written {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"flacidal/internal/settings"
+	"flacidal/internal/tagedit"
+)
+
+func TestSetTags_StrictRefusesBroken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.flac")
+	writeTestFLAC(t, path, map[string]string{"GENRE": "Rock"}, nil, 64)
+	tags := map[string]string{"GENRE": "Jazz"}
+
+	results, err := SetTags(settings.Settings{StrictValidation: true}, []string{path}, tags, tagedit.Merge, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Error == "" || results[0].Written {
+		t.Errorf("strict mode: %+v, want a refusal", results[0])
+	}
+
+	results, err = SetTags(settings.Settings{}, []string{path}, tags, tagedit.Merge, false)
+	if err != nil || !results[0].Written || TagsWritten(results) != 1 {
+		t.Errorf("strict mode off: %+v, %v", results, err)
+	}
+
+	if _, err := SetTags(settings.Settings{}, []string{path}, tags, "append", true); err == nil {
+		t.Error("accepted an unknown mode")
+	}
+}
+
+func TestStripTags_Validation(t *testing.T) {
+	if _, err := StripTags(settings.Settings{}, []string{"a.flac"}, tagedit.StripOptions{}, true); err == nil {
+		t.Error("accepted an empty strip")
+	}
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path, map[string]string{"TITLE": "Song", "COMMENT": "x"}, []byte("jpeg"), 64)
+	results, err := StripTags(settings.Settings{}, []string{path}, tagedit.StripOptions{All: true, Covers: true}, true)
+	if err != nil || len(results[0].Changes) != 2 || results[0].Pictures != 1 {
+		t.Errorf("preview = %+v, %v", results, err)
+	}
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/incomplete"
+	"flacidal/internal/settings"
+	"flacidal/internal/textmatch"
+)
+
+// =============================================================================
+// FLAC Validation (exposed to frontend)
+// =============================================================================
+
+// brokenSuffix is appended to a broken file's name when it is re-downloaded,
+// so the new download doesn't collide with it and it isn't lost if the
+// download fails.
+const brokenSuffix = ".broken"
+
+// ValidateFLAC checks the structure of the FLAC file at path (see
+// flacmeta.Validate). It returns the reason the file is broken, or "" for
+// a sound file.
+func (a *App) ValidateFLAC(path string) (string, error) {
+	return ValidateFLAC(path)
+}
+
+// RedownloadBrokenFile queues the Tidal track a broken FLAC was downloaded
+// from, found by the ISRC, artist and title in its tags, and moves the
+// broken file aside. It returns the queued track.
+func (a *App) RedownloadBrokenFile(path string) (*core.TidalTrack, error) {
+	track, err := RedownloadBroken(a.downloader, a.downloadManager, path, a.GetDownloadFolder())
+	if err != nil {
+		return nil, err
+	}
+	if a.logBuffer != nil {
+		a.logBuffer.Info(fmt.Sprintf("Re-downloading broken file %s as %s - %s", filepath.Base(path), track.Artist, track.Title))
+	}
+	return track, nil
+}
+
+// ValidateFLAC is flacmeta.Validate reporting a broken file as a reason
+// rather than an error. Shared by the desktop (Wails) and HTTP server APIs.
+func ValidateFLAC(path string) (string, error) {
+	err := flacmeta.Validate(path)
+	if errors.Is(err, flacmeta.ErrBroken) {
+		return err.Error(), nil
+	}
+	return "", err
+}
+
+// CheckStrict returns why the FLAC at path must not be processed when the
+// StrictValidation setting is on, or nil. Files that aren't FLAC, and
+// errors that don't mean the file is broken (e.g. it's missing), are left
+// to the operation itself.
+func CheckStrict(s settings.Settings, path string) error {
+	if !s.StrictValidation || !strings.EqualFold(filepath.Ext(path), ".flac") {
+		return nil
+	}
+	if err := flacmeta.Validate(path); errors.Is(err, flacmeta.ErrBroken) {
+		return fmt.Errorf("%w; strict mode refuses it, re-download the track instead", err)
+	}
+	return nil
+}
+
+// RejectBroken returns the status to report for a download progress event:
+// "error" for a completed download that CheckStrict rejects, with the
+// reason as result.Error and the broken file left at result.FilePath for
+// RedownloadBroken, and status otherwise. Shared by the desktop (Wails)
+// and HTTP server APIs.
+func RejectBroken(s settings.Settings, status string, result *core.DownloadResult) string {
+	if status != "completed" || result == nil || result.FilePath == "" {
+		return status
+	}
+	if err := CheckStrict(s, result.FilePath); err != nil {
+		result.Success, result.Error = false, err.Error()
+		return "error"
+	}
+	return status
+}
+
+// StrictResults runs op on the paths CheckStrict passes and returns its
+// results in the order of paths, with rejected(path, reason) standing in
+// for each file it refused. op must return one result per path, in order.
+// Shared by the desktop (Wails) and HTTP server APIs for the batch
+// converter and renamer.
+func StrictResults[R any](s settings.Settings, paths []string, op func([]string) []R, rejected func(path, reason string) R) []R {
+	reasons := map[int]string{}
+	var ok []string
+	for i, p := range paths {
+		if err := CheckStrict(s, p); err != nil {
+			reasons[i] = err.Error()
+			continue
+		}
+		ok = append(ok, p)
+	}
+	if len(reasons) == 0 {
+		return op(paths)
+	}
+	var done []R
+	if len(ok) > 0 {
+		done = op(ok)
+	}
+	results := make([]R, 0, len(paths))
+	for i, p := range paths {
+		if reason, bad := reasons[i]; bad {
+			results = append(results, rejected(p, reason))
+		} else if len(done) > 0 {
+			results = append(results, done[0])
+			done = done[1:]
+		}
+	}
+	return results
+}
+
+// RedownloadBroken looks up the Tidal track the broken FLAC at path holds,
+// by the ISRC in its tags or else its artist and title (or its file name),
+// moves the file aside with brokenSuffix and queues the track into
+// outputDir. Shared by the desktop (Wails) and HTTP server APIs.
+func RedownloadBroken(svc *core.TidalHifiService, dm *core.DownloadManager, path, outputDir string) (*core.TidalTrack, error) {
+	if svc == nil || dm == nil {
+		return nil, fmt.Errorf("downloader not initialized")
+	}
+	if err := flacmeta.Validate(path); err == nil {
+		return nil, fmt.Errorf("%s is not broken", filepath.Base(path))
+	} else if !errors.Is(err, flacmeta.ErrBroken) {
+		return nil, err
+	}
+	isrc, artist, title := brokenFileTags(path)
+	query := isrc
+	if query == "" {
+		query = strings.TrimSpace(artist + " " + title)
+	}
+	results, err := svc.SearchTracks(query, 10)
+	if err != nil {
+		return nil, err
+	}
+	track, ok := PickRedownload(ConvertTidalSearchResults(results), isrc, title)
+	if !ok {
+		return nil, fmt.Errorf("no Tidal track matches %s", filepath.Base(path))
+	}
+	if outputDir == "" {
+		outputDir = filepath.Dir(path)
+	}
+	if err := os.Rename(path, path+brokenSuffix); err != nil {
+		return nil, err
+	}
+	if err := dm.QueueDownloadWithISRC(track.ID, outputDir, track.Title, track.Artist, track.ISRC); err != nil {
+		os.Rename(path+brokenSuffix, path) //nolint:errcheck // best effort: put the file back
+		return nil, err
+	}
+	return &track, nil
+}
+
+// brokenFileTags reads the ISRC, artist and title a broken file was tagged
+// with; the tags sit ahead of the audio, so they usually survive a broken
+// download. Without tags, the title is taken from an "Artist - Title" file
+// name.
+func brokenFileTags(path string) (isrc, artist, title string) {
+	if f, err := flacmeta.Read(path); err == nil {
+		if c, err := f.Comments(); err == nil {
+			isrc, artist, title = c.Get("ISRC"), c.Get("ARTIST"), c.Get("TITLE")
+		}
+	}
+	if title == "" {
+		title = incomplete.Stem(path)
+		if a, t, ok := strings.Cut(title, " - "); ok {
+			artist, title = strings.TrimLeft(a, "0123456789. "), t
+		}
+	}
+	return isrc, artist, title
+}
+
+// PickRedownload picks the search result to re-download a broken file
+// with: the one with the file's ISRC, or failing that the first one with
+// its title (see textmatch.Equal).
+func PickRedownload(tracks []core.TidalTrack, isrc, title string) (core.TidalTrack, bool) {
+	for _, t := range tracks {
+		if isrc != "" && strings.EqualFold(t.ISRC, isrc) {
+			return t, true
+		}
+	}
+	for _, t := range tracks {
+		if textmatch.Equal(t.Title, title, textmatch.Default) {
+			return t, true
+		}
+	}
+	return core.TidalTrack{}, false
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	core "github.com/kushiemoon-dev/flacidal-core"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/settings"
+)
+
+func TestCheckStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.flac")
+	writeTestFLAC(t, path, map[string]string{"TITLE": "Song"}, nil, 64)
+
+	if err := CheckStrict(settings.Settings{}, path); err != nil {
+		t.Errorf("strict mode off: %v", err)
+	}
+	strict := settings.Settings{StrictValidation: true}
+	if err := CheckStrict(strict, path); !errors.Is(err, flacmeta.ErrBroken) {
+		t.Errorf("strict mode on: %v, want ErrBroken", err)
+	}
+	if err := CheckStrict(strict, filepath.Join(t.TempDir(), "song.mp3")); err != nil {
+		t.Errorf("non-FLAC: %v", err)
+	}
+}
+
+func TestRejectBroken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.flac")
+	writeTestFLAC(t, path, nil, nil, 64)
+	strict := settings.Settings{StrictValidation: true}
+
+	result := &core.DownloadResult{FilePath: path, Success: true}
+	if got := RejectBroken(strict, "completed", result); got != "error" || result.Success || !strings.Contains(result.Error, "broken FLAC") || result.FilePath != path {
+		t.Errorf("RejectBroken = %q, result %+v; want a failure keeping the path", got, result)
+	}
+	result = &core.DownloadResult{FilePath: path, Success: true}
+	if got := RejectBroken(settings.Settings{}, "completed", result); got != "completed" || result.Error != "" {
+		t.Errorf("strict mode off: %q, %+v", got, result)
+	}
+	if got := RejectBroken(strict, "downloading", nil); got != "downloading" {
+		t.Errorf("downloading: %q", got)
+	}
+}
+
+func TestStrictResults_KeepsOrder(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.flac")
+	writeTestFLAC(t, broken, nil, nil, 64)
+	paths := []string{filepath.Join(dir, "a.mp3"), broken, filepath.Join(dir, "b.wav")}
+
+	var ran []string
+	got := StrictResults(settings.Settings{StrictValidation: true}, paths, func(files []string) []string {
+		ran = files
+		out := make([]string, len(files))
+		for i, f := range files {
+			out[i] = "ok " + filepath.Base(f)
+		}
+		return out
+	}, func(path, reason string) string {
+		return "refused " + filepath.Base(path)
+	})
+	if want := []string{paths[0], paths[2]}; !slices.Equal(ran, want) {
+		t.Errorf("op ran on %v, want %v", ran, want)
+	}
+	if want := []string{"ok a.mp3", "refused broken.flac", "ok b.wav"}; !slices.Equal(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+}
+
+func TestPickRedownload(t *testing.T) {
+	tracks := []core.TidalTrack{
+		{ID: 1, Title: "Song (Live)", ISRC: "AAA"},
+		{ID: 2, Title: "Song", ISRC: "BBB"},
+	}
+	if got, ok := PickRedownload(tracks, "aaa", "Song"); !ok || got.ID != 1 {
+		t.Errorf("by ISRC: %+v, %v", got, ok)
+	}
+	if got, ok := PickRedownload(tracks, "", "song"); !ok || got.ID != 2 {
+		t.Errorf("by title: %+v, %v", got, ok)
+	}
+	if _, ok := PickRedownload(tracks, "CCC", "Other"); ok {
+		t.Error("unrelated tags matched")
+	}
+}
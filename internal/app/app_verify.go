@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"flacidal/internal/analysis"
+)
+
+// =============================================================================
+// FLAC Verification (exposed to frontend)
+// =============================================================================
+
+// VerifyFiles fully decodes each of paths and checks its audio against
+// the MD5 signature in its STREAMINFO, like `flac -t`.
+func (a *App) VerifyFiles(paths []string) ([]analysis.Verification, error) {
+	results, err := VerifyFLACs(a.ctx, paths)
+	if err == nil {
+		a.logVerified(results)
+	}
+	return results, err
+}
+
+// VerifyFolder is VerifyFiles for every FLAC file under folder.
+func (a *App) VerifyFolder(folder string) ([]analysis.Verification, error) {
+	results, err := VerifyFolder(a.ctx, folder)
+	if err == nil {
+		a.logVerified(results)
+	}
+	return results, err
+}
+
+// logVerified logs a verification summary and each damaged file.
+func (a *App) logVerified(results []analysis.Verification) {
+	if a.logBuffer == nil {
+		return
+	}
+	damaged := 0
+	for _, v := range results {
+		if !v.OK {
+			damaged++
+			a.logBuffer.Warn(fmt.Sprintf("Verify %s: %s", v.Path, strings.Join(v.Problems, "; ")))
+		}
+	}
+	a.logBuffer.Info(fmt.Sprintf("Verified %d files: %d damaged", len(results), damaged))
+}
+
+// VerifyFLACs verifies each of paths in turn (see analysis.Verify); a file
+// that can't be verified at all, such as one that isn't FLAC, gets a
+// failed Verification saying why. It needs FFmpeg. Shared by the desktop
+// (Wails) and HTTP server APIs.
+func VerifyFLACs(ctx context.Context, paths []string) ([]analysis.Verification, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ffmpeg, err := FFmpegPath()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]analysis.Verification, 0, len(paths))
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		v, err := analysis.Verify(ctx, ffmpeg, path)
+		if err != nil {
+			v = &analysis.Verification{Path: path, Problems: []string{err.Error()}}
+		}
+		results = append(results, *v)
+	}
+	return results, nil
+}
+
+// VerifyFolder is VerifyFLACs for every FLAC file under folder. Shared by
+// the desktop (Wails) and HTTP server APIs.
+func VerifyFolder(ctx context.Context, folder string) ([]analysis.Verification, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	paths, err := analysis.FLACFiles(ctx, folder, true)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyFLACs(ctx, paths)
+}
+
+// VerifyDownload verifies a finished download for the VerifyDownloads
+// setting, returning why it is damaged, or nil.
+func VerifyDownload(ctx context.Context, path string) error {
+	results, err := VerifyFLACs(ctx, []string{path})
+	if err != nil {
+		return err
+	}
+	if v := results[0]; !v.OK {
+		return fmt.Errorf("%s failed verification: %s", path, strings.Join(v.Problems, "; "))
+	}
+	return nil
+}
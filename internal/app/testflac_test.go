@@ -0,0 +1,91 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"sort"
+	"testing"
+)
+
+// writeTestFLAC writes a structurally valid FLAC file to path: a STREAMINFO
+// block (44.1 kHz / 16-bit / stereo, 3 minutes), a VORBIS_COMMENT block
+// holding tags, an optional front-cover PICTURE block, then audioBytes of
+// zeroed "frame" data. Nothing decodes the audio, so the frames don't need to
+// be real — only the metadata has to parse, which is all the file browser,
+// tagger and library scan read. The zeroed frames lack a frame header, so
+// the file fails validation: strict mode tests use it as a broken file.
+func writeTestFLAC(tb testing.TB, path string, tags map[string]string, cover []byte, audioBytes int) {
+	tb.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString("fLaC")
+
+	// STREAMINFO: block sizes, frame sizes, then sample rate (20 bits),
+	// channels-1 (3 bits), bits-per-sample-1 (5 bits) and total samples (36 bits)
+	// packed into one 64-bit word, followed by the 16-byte audio MD5.
+	streamInfo := make([]byte, 34)
+	binary.BigEndian.PutUint16(streamInfo[0:], 4096)
+	binary.BigEndian.PutUint16(streamInfo[2:], 4096)
+	const sampleRate, channels, bitsPerSample, totalSamples = 44100, 2, 16, 44100 * 180
+	packed := uint64(sampleRate)<<44 | uint64(channels-1)<<41 | uint64(bitsPerSample-1)<<36 | uint64(totalSamples)
+	binary.BigEndian.PutUint64(streamInfo[10:], packed)
+	writeBlockHeader(&buf, 0, false, len(streamInfo))
+	buf.Write(streamInfo)
+
+	var vc bytes.Buffer
+	vendor := "FLACidal test fixture"
+	binary.Write(&vc, binary.LittleEndian, uint32(len(vendor)))
+	vc.WriteString(vendor)
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	binary.Write(&vc, binary.LittleEndian, uint32(len(keys)))
+	for _, k := range keys {
+		comment := k + "=" + tags[k]
+		binary.Write(&vc, binary.LittleEndian, uint32(len(comment)))
+		vc.WriteString(comment)
+	}
+	writeBlockHeader(&buf, 4, len(cover) == 0, vc.Len())
+	buf.Write(vc.Bytes())
+
+	if len(cover) > 0 {
+		var pic bytes.Buffer
+		mime := "image/jpeg"
+		binary.Write(&pic, binary.BigEndian, uint32(3)) // front cover
+		binary.Write(&pic, binary.BigEndian, uint32(len(mime)))
+		pic.WriteString(mime)
+		binary.Write(&pic, binary.BigEndian, uint32(0)) // empty description
+		binary.Write(&pic, binary.BigEndian, [4]uint32{1280, 1280, 24, 0})
+		binary.Write(&pic, binary.BigEndian, uint32(len(cover)))
+		pic.Write(cover)
+		writeBlockHeader(&buf, 6, true, pic.Len())
+		buf.Write(pic.Bytes())
+	}
+
+	buf.Write(make([]byte, audioBytes))
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		tb.Fatalf("writeTestFLAC: %v", err)
+	}
+}
+
+func writeBlockHeader(buf *bytes.Buffer, blockType byte, last bool, length int) {
+	if last {
+		blockType |= 0x80
+	}
+	buf.Write([]byte{blockType, byte(length >> 16), byte(length >> 8), byte(length)})
+}
+
+// testTags is the tag set used by benchmarks that need a realistically tagged file.
+var testTags = map[string]string{
+	"TITLE":       "Benchmark Track",
+	"ARTIST":      "Benchmark Artist",
+	"ALBUM":       "Benchmark Album",
+	"TRACKNUMBER": "1",
+	"DATE":        "2024",
+	"GENRE":       "Electronic",
+	"ISRC":        "USXXX2400001",
+}
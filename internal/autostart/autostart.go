@@ -0,0 +1,84 @@
+// Package autostart registers the desktop app to start when the user logs
+// in: a systemd user unit on Linux, a launchd agent on macOS and a Run key
+// on Windows. Registration points at the running executable, so it is
+// refreshed whenever the app starts with the option on.
+package autostart
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Name identifies FLACidal's registration on every platform.
+const Name = "flacidal"
+
+// ErrUnsupported is returned on platforms without a login-item mechanism.
+var ErrUnsupported = errors.New("start on login is not supported on this platform")
+
+// run executes a registration command; replaced in tests.
+var run = runCommand
+
+// runCommand runs name, folding its output into the error on failure.
+func runCommand(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// systemdUnit returns the user unit that starts exe with the graphical
+// session.
+func systemdUnit(exe string) string {
+	return `[Unit]
+Description=FLACidal
+After=graphical-session.target
+PartOf=graphical-session.target
+
+[Service]
+Type=simple
+ExecStart=` + systemdQuote(exe) + `
+Restart=no
+
+[Install]
+WantedBy=graphical-session.target
+`
+}
+
+// systemdQuote quotes path for an ExecStart line.
+func systemdQuote(path string) string {
+	if !strings.ContainsAny(path, " \t\"\\") {
+		return path
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(path) + `"`
+}
+
+// launchdLabel is the launchd agent's label and plist name.
+const launchdLabel = "dev.kushiemoon." + Name
+
+// launchdPlist returns the launch agent that runs exe at login.
+func launchdPlist(exe string) string {
+	r := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + launchdLabel + `</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>` + r.Replace(exe) + `</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`
+}
+
+// windowsRunKey is the Run key, under HKEY_CURRENT_USER, Windows starts
+// the user's programs from.
+const windowsRunKey = `Software\Microsoft\Windows\CurrentVersion\Run`
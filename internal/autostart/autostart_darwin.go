@@ -0,0 +1,51 @@
+package autostart
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// plistPath returns the launch agent's path in ~/Library/LaunchAgents.
+func plistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+}
+
+// Enable installs a launch agent that runs exe at login. launchd picks it
+// up at the next login; nothing is started now.
+func Enable(exe string) error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(launchdPlist(exe)), 0644)
+}
+
+// Disable removes the launch agent. Not being registered is not an error.
+func Disable() error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Enabled reports whether the launch agent is installed.
+func Enabled() bool {
+	path, err := plistPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
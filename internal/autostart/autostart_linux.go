@@ -0,0 +1,69 @@
+package autostart
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// unitPath returns the systemd user unit's path, honouring XDG_CONFIG_HOME.
+func unitPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "systemd", "user", Name+".service"), nil
+}
+
+// Enable installs and enables a systemd user unit that starts exe. An
+// installed unit that already starts exe is left as it is, so starting the
+// app doesn't reload systemd every time.
+func Enable(exe string) error {
+	path, err := unitPath()
+	if err != nil {
+		return err
+	}
+	unit := systemdUnit(exe)
+	if old, err := os.ReadFile(path); err == nil && string(old) == unit {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+		return err
+	}
+	if err := run("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	return run("systemctl", "--user", "enable", Name+".service")
+}
+
+// Disable disables and removes the unit. Not being registered is not an
+// error.
+func Disable() error {
+	path, err := unitPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := run("systemctl", "--user", "disable", Name+".service"); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return run("systemctl", "--user", "daemon-reload")
+}
+
+// Enabled reports whether the unit is installed.
+func Enabled() bool {
+	path, err := unitPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
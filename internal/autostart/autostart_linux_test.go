@@ -0,0 +1,51 @@
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnableDisable_Linux(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var calls []string
+	run = func(name string, args ...string) error {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return nil
+	}
+	t.Cleanup(func() { run = runCommand })
+
+	if Enabled() {
+		t.Fatal("Enabled() before Enable")
+	}
+	if err := Disable(); err != nil || len(calls) != 0 {
+		t.Fatalf("Disable() when not registered = %v, calls %v", err, calls)
+	}
+
+	if err := Enable("/usr/bin/flacidal"); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	path, _ := unitPath()
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "ExecStart=/usr/bin/flacidal") {
+		t.Fatalf("unit file = %q, %v", data, err)
+	}
+	if filepath.Base(filepath.Dir(path)) != "user" || !Enabled() {
+		t.Errorf("unit at %s, Enabled() = %v", path, Enabled())
+	}
+	if got := calls[len(calls)-1]; got != "systemctl --user enable flacidal.service" {
+		t.Errorf("last command = %q", got)
+	}
+	n := len(calls)
+	if err := Enable("/usr/bin/flacidal"); err != nil || len(calls) != n {
+		t.Errorf("Enable with the unit unchanged = %v, ran %v", err, calls[n:])
+	}
+
+	if err := Disable(); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if Enabled() {
+		t.Error("Enabled() after Disable")
+	}
+}
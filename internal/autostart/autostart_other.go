@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !windows
+
+package autostart
+
+// Enable always fails with ErrUnsupported.
+func Enable(exe string) error { return ErrUnsupported }
+
+// Disable has nothing to remove.
+func Disable() error { return nil }
+
+// Enabled is always false.
+func Enabled() bool { return false }
@@ -0,0 +1,34 @@
+package autostart
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	unit := systemdUnit("/opt/FLACidal/flacidal")
+	for _, want := range []string{
+		"ExecStart=/opt/FLACidal/flacidal\n",
+		"WantedBy=graphical-session.target",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit missing %q:\n%s", want, unit)
+		}
+	}
+	if got := systemdUnit(`/home/me/My Apps/flacidal`); !strings.Contains(got, `ExecStart="/home/me/My Apps/flacidal"`) {
+		t.Errorf("path with a space not quoted:\n%s", got)
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	plist := launchdPlist("/Applications/R&B.app/Contents/MacOS/FLACidal")
+	for _, want := range []string{
+		"<string>" + launchdLabel + "</string>",
+		"<string>/Applications/R&amp;B.app/Contents/MacOS/FLACidal</string>",
+		"<key>RunAtLoad</key>\n\t<true/>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %q:\n%s", want, plist)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package autostart
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// Enable adds exe to the current user's Run key.
+func Enable(exe string) error {
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, windowsRunKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	return k.SetStringValue(Name, `"`+exe+`"`)
+}
+
+// Disable removes the Run key value. Not being registered is not an error.
+func Disable() error {
+	k, err := registry.OpenKey(registry.CURRENT_USER, windowsRunKey, registry.SET_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	if err := k.DeleteValue(Name); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Enabled reports whether the Run key value exists.
+func Enabled() bool {
+	k, err := registry.OpenKey(registry.CURRENT_USER, windowsRunKey, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	defer k.Close()
+	_, _, err = k.GetStringValue(Name)
+	return err == nil
+}
@@ -0,0 +1,119 @@
+// Package configdiff reports what saving a config or settings object
+// changed: the JSON name of every field that differs from the running one,
+// and whether the change was applied live, needs a restart or was refused
+// as invalid. The settings UI uses it to say what a save did instead of
+// just "saved".
+package configdiff
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Effect is what became of a changed field.
+type Effect string
+
+const (
+	Live    Effect = "applied-live"  // in effect for the next download or request
+	Restart Effect = "needs-restart" // saved, but in effect after the next start
+	Invalid Effect = "invalid"       // refused; nothing was saved
+)
+
+// Change is one changed field.
+type Change struct {
+	Key    string `json:"key"` // JSON name, e.g. "proxyUrl"
+	Effect Effect `json:"effect"`
+	Error  string `json:"error,omitempty"` // why an Invalid value was refused
+}
+
+// FieldError is a validation error for one field, named by its JSON key,
+// so a refused save can say which field it refused.
+type FieldError struct {
+	Key string
+	Err error
+}
+
+func (e *FieldError) Error() string { return e.Err.Error() }
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// Field wraps err as a FieldError for key; nil stays nil.
+func Field(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FieldError{Key: key, Err: err}
+}
+
+// Keys lists the JSON names of the top-level fields whose values differ
+// between old and new, which must marshal to JSON objects, sorted. A field
+// present in only one of them counts as changed.
+func Keys(old, new any) ([]string, error) {
+	a, err := fields(old)
+	if err != nil {
+		return nil, err
+	}
+	b, err := fields(new)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for k, v := range b {
+		if w, ok := a[k]; !ok || string(v) != string(w) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// fields marshals v and splits the resulting object into its fields.
+func fields(v any) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]json.RawMessage{}
+	if string(data) == "null" {
+		return m, nil
+	}
+	return m, json.Unmarshal(data, &m)
+}
+
+// Report annotates keys, the changed fields of a successful save: those in
+// restart need a restart, the rest were applied live. restart may name
+// fields outside keys, e.g. a pool size that was changed by an earlier
+// save but is still waiting for the restart; they are reported too.
+func Report(keys, restart []string) []Change {
+	changes := make([]Change, 0, len(keys))
+	for _, k := range keys {
+		effect := Live
+		if slices.Contains(restart, k) {
+			effect = Restart
+		}
+		changes = append(changes, Change{Key: k, Effect: effect})
+	}
+	for _, k := range restart {
+		if !slices.Contains(keys, k) {
+			changes = append(changes, Change{Key: k, Effect: Restart})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Key, b.Key) })
+	return changes
+}
+
+// Refused reports a save that err refused: the invalid field when err is
+// (or wraps) a FieldError, else nothing.
+func Refused(err error) []Change {
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		return []Change{}
+	}
+	return []Change{{Key: fe.Key, Effect: Invalid, Error: fe.Err.Error()}}
+}
@@ -0,0 +1,58 @@
+package configdiff
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+type config struct {
+	Proxy   string   `json:"proxyUrl"`
+	Workers int      `json:"concurrentDownloads"`
+	Order   []string `json:"sourceOrder,omitempty"`
+}
+
+func TestKeys(t *testing.T) {
+	old := config{Proxy: "", Workers: 4, Order: []string{"tidal"}}
+	new := config{Proxy: "socks5://host", Workers: 4}
+	keys, err := Keys(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"proxyUrl", "sourceOrder"}; !slices.Equal(keys, want) {
+		t.Errorf("Keys = %q, want %q", keys, want)
+	}
+	if keys, _ := Keys(new, new); len(keys) != 0 {
+		t.Errorf("unchanged: Keys = %q", keys)
+	}
+	if keys, _ := Keys((*config)(nil), &new); !slices.Equal(keys, []string{"concurrentDownloads", "proxyUrl"}) {
+		t.Errorf("from nil: Keys = %q", keys)
+	}
+}
+
+func TestReport(t *testing.T) {
+	got := Report([]string{"proxyUrl", "qobuzEnabled"}, []string{"qobuzEnabled", "concurrentDownloads"})
+	want := []Change{
+		{Key: "concurrentDownloads", Effect: Restart},
+		{Key: "proxyUrl", Effect: Live},
+		{Key: "qobuzEnabled", Effect: Restart},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Report = %+v, want %+v", got, want)
+	}
+}
+
+func TestRefused(t *testing.T) {
+	err := fmt.Errorf("saving: %w", Field("proxyUrl", errors.New("proxy URL: missing host")))
+	want := []Change{{Key: "proxyUrl", Effect: Invalid, Error: "proxy URL: missing host"}}
+	if got := Refused(err); !slices.Equal(got, want) {
+		t.Errorf("Refused = %+v, want %+v", got, want)
+	}
+	if got := Refused(errors.New("disk full")); len(got) != 0 {
+		t.Errorf("Refused(plain error) = %+v", got)
+	}
+	if Field("proxyUrl", nil) != nil {
+		t.Error("Field(nil) != nil")
+	}
+}
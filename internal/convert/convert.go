@@ -0,0 +1,452 @@
+// Package convert converts FLAC files to other formats with ffmpeg,
+// reporting how far along each file is as ffmpeg works through it. ffmpeg
+// writes its progress to stdout (-progress pipe:1), and the position it has
+// reached against the source's duration, from its STREAMINFO, gives the
+// percent; a 24/192 album would otherwise look frozen while it converts.
+package convert
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/naming"
+)
+
+// Options configure a conversion.
+type Options struct {
+	Format    string          // "mp3", "aac", "ogg", "opus", "vorbis", "alac", "wav", "aiff" or "flac"
+	Quality   string          // "320k", "V0", "q6"…, "rate:bits" for flac; "" for the format's default
+	OutputDir string          // "" writes next to the source
+	Conflict  naming.Conflict // what to name the output when its name is taken; ConflictKeep fails
+}
+
+// format is how ffmpeg encodes one output format.
+type format struct {
+	ext     string
+	codec   string
+	quality func(q string, bits int) ([]string, error) // nil when the format has no quality setting
+	defQ    string
+	tags    []string // how the muxer takes the source's tags and cover
+}
+
+// The tag arguments of each container. The source's pictures are video
+// streams to ffmpeg, which only -map brings along, and only the MP3, MP4,
+// AIFF and FLAC muxers take them. The Ogg muxer writes the audio stream's
+// tags rather than the file's, and WAV gets its tags as an INFO chunk.
+var (
+	mp3Tags  = []string{"-map", "0:v?", "-c:v", "copy", "-id3v2_version", "3"}
+	mp4Tags  = []string{"-map", "0:v:0?", "-c:v", "copy", "-disposition:v", "attached_pic"}
+	oggTags  = []string{"-map_metadata:s:a", "0:g"}
+	aiffTags = []string{"-map", "0:v:0?", "-c:v", "copy", "-write_id3v2", "1"}
+	flacTags = []string{"-map", "0:v?", "-c:v", "copy"}
+)
+
+var formats = map[string]format{
+	"mp3":    {".mp3", "libmp3lame", lameQuality, "320k", mp3Tags},
+	"aac":    {".m4a", "aac", bitrate, "256k", mp4Tags},
+	"ogg":    {".ogg", "libvorbis", bitrate, "320k", oggTags},
+	"opus":   {".opus", "libopus", bitrate, "192k", oggTags},
+	"vorbis": {".ogg", "libvorbis", vorbisQuality, "q6", oggTags},
+	"alac":   {".m4a", "alac", nil, "", mp4Tags},
+	"wav":    {".wav", "pcm_s%dle", nil, "", nil},
+	"aiff":   {".aiff", "pcm_s%dbe", nil, "", aiffTags},
+	"flac":   {".flac", "flac", resample, "", flacTags},
+}
+
+// Supported reports whether Args knows how to encode format.
+func Supported(format string) bool {
+	_, ok := formats[strings.ToLower(format)]
+	return ok
+}
+
+// OutputPath is where converting src with opts writes: the source's name
+// with the format's extension, in opts.OutputDir or else next to it.
+func OutputPath(src string, opts Options) (string, error) {
+	f, ok := formats[strings.ToLower(opts.Format)]
+	if !ok {
+		return "", fmt.Errorf("unsupported format %q", opts.Format)
+	}
+	dir := opts.OutputDir
+	if dir == "" {
+		dir = filepath.Dir(src)
+	}
+	dst := filepath.Join(dir, strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))+f.ext)
+	if filepath.Clean(dst) == filepath.Clean(src) {
+		return "", errors.New("the output would overwrite the source; choose another output folder")
+	}
+	return dst, nil
+}
+
+// Args returns the ffmpeg arguments that convert src to dst with opts,
+// carrying its tags and, where the format allows, its pictures across and
+// reporting progress on stdout. bits is the source's bit depth, which the
+// uncompressed formats keep; 0 when unknown.
+func Args(src, dst string, opts Options, bits int) ([]string, error) {
+	f, ok := formats[strings.ToLower(opts.Format)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %q", opts.Format)
+	}
+	args := []string{"-hide_banner", "-nostdin", "-v", "error", "-nostats", "-progress", "pipe:1", "-n",
+		"-i", src, "-map", "0:a:0", "-map_metadata", "0"}
+	args = append(args, f.tags...)
+	codec := f.codec
+	if strings.Contains(codec, "%d") {
+		codec = fmt.Sprintf(codec, pcmBits(bits))
+	}
+	args = append(args, "-c:a", codec)
+	if f.quality != nil {
+		q := cmp.Or(opts.Quality, f.defQ)
+		if q != "" {
+			extra, err := f.quality(q, bits)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, extra...)
+		}
+	}
+	return append(args, dst), nil
+}
+
+// lameQuality is "320k" as a constant bitrate, or "V0" to "V9" as LAME's
+// variable bitrate presets.
+func lameQuality(q string, _ int) ([]string, error) {
+	if v, ok := strings.CutPrefix(strings.ToUpper(q), "V"); ok {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 9 {
+			return []string{"-q:a", v}, nil
+		}
+	}
+	return bitrate(q, 0)
+}
+
+// vorbisQuality is "q6" as libvorbis' quality scale, -1 to 10.
+func vorbisQuality(q string, _ int) ([]string, error) {
+	v := strings.TrimPrefix(strings.ToLower(q), "q")
+	if n, err := strconv.ParseFloat(v, 64); err != nil || n < -1 || n > 10 {
+		return nil, fmt.Errorf("invalid Vorbis quality %q", q)
+	}
+	return []string{"-q:a", v}, nil
+}
+
+// bitrate is "256k" as a bitrate.
+func bitrate(q string, _ int) ([]string, error) {
+	v, ok := strings.CutSuffix(strings.ToLower(q), "k")
+	if n, err := strconv.Atoi(v); !ok || err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid bitrate %q", q)
+	}
+	return []string{"-b:a", v + "k"}, nil
+}
+
+// resample is "44100:16" as the sample rate and bit depth a FLAC is
+// re-encoded at; either may be left empty to keep the source's.
+func resample(q string, _ int) ([]string, error) {
+	rate, bits, _ := strings.Cut(q, ":")
+	var args []string
+	if rate != "" {
+		if n, err := strconv.Atoi(rate); err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid sample rate %q", rate)
+		}
+		args = append(args, "-ar", rate)
+	}
+	switch bits {
+	case "":
+	case "16":
+		args = append(args, "-sample_fmt", "s16")
+	case "24":
+		args = append(args, "-sample_fmt", "s32", "-bits_per_raw_sample", "24")
+	default:
+		return nil, fmt.Errorf("invalid bit depth %q", bits)
+	}
+	return args, nil
+}
+
+// pcmBits is the PCM sample size that holds bits without loss.
+func pcmBits(bits int) int {
+	switch {
+	case bits <= 16:
+		return 16
+	case bits <= 24:
+		return 24
+	}
+	return 32
+}
+
+// File converts src with opts using the ffmpeg binary at ffmpeg and
+// returns the output's path. progress, if not nil, gets the percent of
+// src converted so far as ffmpeg reports it; a source that isn't FLAC, or
+// whose STREAMINFO lacks its length, reports none. ffmpeg encodes into a
+// temporary folder next to the output, which is moved into place only
+// once complete, so a failed conversion leaves nothing behind and never
+// touches a file already there: a taken name is resolved by
+// opts.Conflict.
+func File(ctx context.Context, ffmpeg, src string, opts Options, progress func(percent float64)) (string, error) {
+	dst, err := OutputPath(src, opts)
+	if err != nil {
+		return "", err
+	}
+	var duration float64
+	bits := 0
+	if si, err := flacmeta.ReadStreamInfo(src); err == nil {
+		bits = si.BitDepth
+		if si.SampleRate > 0 {
+			duration = float64(si.Samples) / float64(si.SampleRate)
+		}
+	}
+	if opts.OutputDir != "" {
+		if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+			return "", err
+		}
+	}
+	// Fail before encoding when the name is taken and must stay so
+	if _, err := place(dst, opts.Conflict); err != nil {
+		return "", err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dst), ".convert-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck // holds nothing once the output is in place
+	tmp := filepath.Join(tmpDir, filepath.Base(dst))
+	args, err := Args(src, tmp, opts, bits)
+	if err != nil {
+		return "", err
+	}
+	err = run(ctx, ffmpeg, args, func(stdout io.Reader) error {
+		return ParseProgress(stdout, func(seconds float64) {
+			if progress != nil && duration > 0 {
+				progress(min(seconds/duration*100, 100))
+			}
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return commit(tmp, dst, opts.Conflict)
+}
+
+// place returns the name to write dst under: dst itself when free, else
+// the one c gives it.
+func place(dst string, c naming.Conflict) (string, error) {
+	p, ok := c.Resolve(dst, "", "")
+	if !ok {
+		return "", fmt.Errorf("%s already exists", filepath.Base(dst))
+	}
+	return p, nil
+}
+
+// commit moves the finished output tmp to dst, or the name c gives it if
+// dst was taken meanwhile, and returns where it went. Hard linking claims
+// the name only if it's still free; without hard links (FAT, some network
+// shares) it is renamed.
+func commit(tmp, dst string, c naming.Conflict) (string, error) {
+	for {
+		final, err := place(dst, c)
+		if err != nil {
+			return "", err
+		}
+		err = os.Link(tmp, final)
+		switch {
+		case err == nil:
+			return final, nil
+		case errors.Is(err, fs.ErrExist):
+			continue // taken since place looked
+		}
+		if err := os.Rename(tmp, final); err != nil {
+			return "", err
+		}
+		return final, nil
+	}
+}
+
+// run runs ffmpeg with args, handing its stdout to read. ffmpeg exiting
+// unsuccessfully fails with the last line it wrote to stderr.
+func run(ctx context.Context, ffmpeg string, args []string, read func(io.Reader) error) error {
+	cmd := exec.CommandContext(ctx, ffmpeg, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	err = read(out)
+	if err != nil {
+		cmd.Process.Kill() //nolint:errcheck // reported by Wait, which follows
+	}
+	if werr := cmd.Wait(); werr != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if i := strings.LastIndexByte(msg, '\n'); i >= 0 {
+			msg = msg[i+1:]
+		}
+		if msg != "" {
+			werr = fmt.Errorf("%w: %s", werr, msg)
+		}
+		return errors.Join(err, werr)
+	}
+	return err
+}
+
+// ParseProgress reads ffmpeg's -progress output from r, calling at with
+// the position reached, in seconds of the source, at each report, until
+// r ends.
+func ParseProgress(r io.Reader, at func(seconds float64)) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(sc.Text()), "=")
+		if !ok {
+			continue
+		}
+		// out_time_ms is in microseconds too, a long-standing ffmpeg quirk
+		if key != "out_time_us" && key != "out_time_ms" {
+			continue
+		}
+		us, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || us < 0 { // "N/A" before the first frame
+			continue
+		}
+		at(float64(us) / 1e6)
+	}
+	return sc.Err()
+}
+
+// Progress is where a batch of conversions stands, as of an update on
+// one of its files.
+type Progress struct {
+	File         string  `json:"file"`
+	Index        int     `json:"index"` // of File in the batch, from 0
+	Total        int     `json:"total"`
+	Percent      float64 `json:"percent"` // of File
+	Done         int     `json:"done"`    // files finished, converted or not
+	BatchPercent float64 `json:"batchPercent"`
+}
+
+// Outcome is the result of converting one file.
+type Outcome struct {
+	Source string
+	Output string
+	Err    error
+}
+
+// clashes returns, for each of files, the index of an earlier file that
+// converts to the same path, or -1. Paths differing only in case clash,
+// as they do on Windows and macOS.
+func clashes(files []string, opts Options) []int {
+	first := make(map[string]int, len(files))
+	out := make([]int, len(files))
+	for i, f := range files {
+		out[i] = -1
+		dst, err := OutputPath(f, opts)
+		if err != nil {
+			continue // File reports it
+		}
+		key := strings.ToLower(filepath.Clean(dst))
+		if j, ok := first[key]; ok {
+			out[i] = j
+			continue
+		}
+		first[key] = i
+	}
+	return out
+}
+
+// CheckOutputs returns an error if two of files would convert to the
+// same path, as same-named files from different folders do when given
+// one OutputDir.
+func CheckOutputs(files []string, opts Options) error {
+	for i, j := range clashes(files, opts) {
+		if j >= 0 {
+			return clashError(files[j], files[i], opts)
+		}
+	}
+	return nil
+}
+
+func clashError(first, second string, opts Options) error {
+	dst, _ := OutputPath(second, opts)
+	return fmt.Errorf("%s and %s would both convert to %s", first, second, filepath.Base(dst))
+}
+
+// Run converts files as File does, workers at a time (at least one), and
+// returns their outcomes in order. A file that would convert to the same
+// path as an earlier one fails without being converted, as do the files
+// not yet started when ctx is done. progress, if not nil, gets the batch's
+// progress as each file starts, as ffmpeg reports on it and as it
+// finishes; calls to it never overlap.
+func Run(ctx context.Context, ffmpeg string, files []string, opts Options, workers int, progress func(Progress)) []Outcome {
+	out := make([]Outcome, len(files))
+	var mu sync.Mutex
+	percents := make([]float64, len(files))
+	done := 0
+	report := func(i int, percent float64, finished bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		percents[i] = percent
+		if finished {
+			done++
+		}
+		if progress == nil {
+			return
+		}
+		var sum float64
+		for _, p := range percents {
+			sum += p
+		}
+		progress(Progress{
+			File:         files[i],
+			Index:        i,
+			Total:        len(files),
+			Percent:      percent,
+			Done:         done,
+			BatchPercent: sum / float64(len(files)),
+		})
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(workers, 1), max(len(files), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				report(i, 0, false)
+				dst, err := File(ctx, ffmpeg, files[i], opts, func(percent float64) { report(i, percent, false) })
+				out[i] = Outcome{Source: files[i], Output: dst, Err: err}
+				report(i, 100, true)
+			}
+		}()
+	}
+	clash := clashes(files, opts)
+feed:
+	for i := range files {
+		if j := clash[i]; j >= 0 {
+			out[i] = Outcome{Source: files[i], Err: clashError(files[j], files[i], opts)}
+			report(i, 100, true)
+			continue
+		}
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+	for i, o := range out {
+		if o.Source == "" {
+			out[i] = Outcome{Source: files[i], Err: ctx.Err()}
+		}
+	}
+	return out
+}
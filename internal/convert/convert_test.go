@@ -0,0 +1,201 @@
+package convert
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"flacidal/internal/naming"
+)
+
+const progressLog = `frame=0
+out_time_us=N/A
+out_time_ms=N/A
+progress=continue
+out_time_us=1500000
+out_time_ms=1500000
+out_time=00:00:01.500000
+progress=continue
+out_time_us=3000000
+progress=end
+`
+
+func TestParseProgress(t *testing.T) {
+	var got []float64
+	if err := ParseProgress(strings.NewReader(progressLog), func(s float64) { got = append(got, s) }); err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1.5, 1.5, 3}
+	if !slices.Equal(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	tests := []struct {
+		opts Options
+		bits int
+		want string
+	}{
+		{Options{Format: "mp3"}, 16, "-c:a libmp3lame -b:a 320k"},
+		{Options{Format: "MP3", Quality: "V0"}, 16, "-c:a libmp3lame -q:a 0"},
+		{Options{Format: "vorbis", Quality: "q8"}, 16, "-c:a libvorbis -q:a 8"},
+		{Options{Format: "opus", Quality: "96k"}, 16, "-c:a libopus -b:a 96k"},
+		{Options{Format: "wav"}, 24, "-c:a pcm_s24le"},
+		{Options{Format: "aiff"}, 16, "-c:a pcm_s16be"},
+		{Options{Format: "flac", Quality: "44100:16"}, 24, "-c:a flac -ar 44100 -sample_fmt s16"},
+		{Options{Format: "flac", Quality: "96000:24"}, 24, "-c:a flac -ar 96000 -sample_fmt s32 -bits_per_raw_sample 24"},
+	}
+	for _, tt := range tests {
+		args, err := Args("in.flac", "out", tt.opts, tt.bits)
+		if err != nil {
+			t.Errorf("%+v: %v", tt.opts, err)
+			continue
+		}
+		got := strings.Join(args, " ")
+		if !strings.Contains(got, "-progress pipe:1") || !strings.Contains(got, tt.want+" out") {
+			t.Errorf("%+v: args = %s, want %q", tt.opts, got, tt.want)
+		}
+	}
+	mp3, _ := Args("in.flac", "out.mp3", Options{Format: "mp3"}, 16)
+	if got := strings.Join(mp3, " "); !strings.Contains(got, "-map 0:a:0 -map_metadata 0 -map 0:v? -c:v copy -id3v2_version 3") {
+		t.Errorf("MP3 args %q don't carry the tags and pictures", got)
+	}
+	opus, _ := Args("in.flac", "out.opus", Options{Format: "opus"}, 16)
+	if got := strings.Join(opus, " "); strings.Contains(got, "0:v") || !strings.Contains(got, "-map_metadata:s:a 0:g") {
+		t.Errorf("Opus args %q", got)
+	}
+	for _, opts := range []Options{{Format: "wma"}, {Format: "mp3", Quality: "loud"}, {Format: "flac", Quality: "44100:20"}} {
+		if _, err := Args("in.flac", "out", opts, 16); err == nil {
+			t.Errorf("%+v: no error", opts)
+		}
+	}
+}
+
+func TestOutputPath(t *testing.T) {
+	src := filepath.Join("music", "01 - Song.flac")
+	got, err := OutputPath(src, Options{Format: "aac", OutputDir: "out"})
+	if want := filepath.Join("out", "01 - Song.m4a"); err != nil || got != want {
+		t.Errorf("OutputPath = %q, %v, want %q", got, err, want)
+	}
+	got, err = OutputPath(src, Options{Format: "mp3"})
+	if want := filepath.Join("music", "01 - Song.mp3"); err != nil || got != want {
+		t.Errorf("OutputPath = %q, %v, want %q", got, err, want)
+	}
+	if _, err := OutputPath(src, Options{Format: "flac"}); err == nil {
+		t.Error("converting over the source: no error")
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg is a shell script")
+	}
+	dir := t.TempDir()
+	// Reports progress, then writes its last argument, the output
+	ffmpeg := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\necho out_time_us=N/A\necho progress=continue\nfor a; do out=$a; done\ncase $out in *bad*) echo broken >&2; exit 1;; esac\necho converted > \"$out\"\necho progress=end\n"
+	if err := os.WriteFile(ffmpeg, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{filepath.Join(dir, "a.flac"), filepath.Join(dir, "bad.flac"), filepath.Join(dir, "c.flac")}
+	var last Progress
+	calls := 0
+	out := Run(context.Background(), ffmpeg, files, Options{Format: "mp3", OutputDir: filepath.Join(dir, "out")}, 2, func(p Progress) {
+		calls++
+		last = p
+	})
+	if calls != 2*len(files) || last.Done != 3 || last.BatchPercent != 100 {
+		t.Errorf("%d progress calls, last %+v", calls, last)
+	}
+	for i, o := range out {
+		if o.Source != files[i] {
+			t.Errorf("outcome %d is for %s", i, o.Source)
+		}
+	}
+	if out[1].Err == nil || !strings.Contains(out[1].Err.Error(), "broken") {
+		t.Errorf("bad.flac: err = %v, want ffmpeg's message", out[1].Err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "c.mp3")); err != nil || out[2].Err != nil {
+		t.Errorf("c.flac: %v, %v", out[2].Err, err)
+	}
+}
+
+func TestRunClashesAndCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg is a shell script")
+	}
+	dir := t.TempDir()
+	ffmpeg := filepath.Join(dir, "ffmpeg")
+	if err := os.WriteFile(ffmpeg, []byte("#!/bin/sh\nfor a; do out=$a; done\necho converted > \"$out\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Format: "mp3", OutputDir: filepath.Join(dir, "out")}
+	files := []string{filepath.Join(dir, "a", "Song.flac"), filepath.Join(dir, "b", "song.flac")}
+	if err := CheckOutputs(files, opts); err == nil {
+		t.Error("CheckOutputs: no error for two files converting to one path")
+	}
+	out := Run(context.Background(), ffmpeg, files, opts, 2, nil)
+	if out[0].Err != nil || out[1].Err == nil || out[1].Output != "" {
+		t.Errorf("outcomes = %+v, want the second file refused", out)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	files = []string{filepath.Join(dir, "c.flac"), filepath.Join(dir, "d.flac"), filepath.Join(dir, "e.flac")}
+	for i, o := range Run(ctx, ffmpeg, files, opts, 1, nil) {
+		if o.Source != files[i] || o.Err == nil {
+			t.Errorf("cancelled batch: outcome %d = %+v", i, o)
+		}
+	}
+}
+
+func TestFileKeepsExisting(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg is a shell script")
+	}
+	dir := t.TempDir()
+	// Writes its last argument, the output, unless told to fail
+	ffmpeg := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\nfor a; do out=$a; done\necho converted > \"$out\"\n[ -e \"" + filepath.Join(dir, "fail") + "\" ] && exit 1\nexit 0\n"
+	if err := os.WriteFile(ffmpeg, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "a.flac")
+	dst := filepath.Join(dir, "a.mp3")
+	if err := os.WriteFile(dst, []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	kept := func() {
+		t.Helper()
+		if b, err := os.ReadFile(dst); err != nil || string(b) != "mine" {
+			t.Errorf("existing output = %q, %v", b, err)
+		}
+	}
+
+	if _, err := File(context.Background(), ffmpeg, src, Options{Format: "mp3"}, nil); err == nil {
+		t.Error("taken name, ConflictKeep: no error")
+	}
+	kept()
+
+	got, err := File(context.Background(), ffmpeg, src, Options{Format: "mp3", Conflict: naming.ConflictCounter}, nil)
+	if want := filepath.Join(dir, "a (2).mp3"); err != nil || got != want {
+		t.Errorf("File = %q, %v, want %q", got, err, want)
+	}
+	kept()
+
+	os.WriteFile(filepath.Join(dir, "fail"), nil, 0644) //nolint:errcheck
+	if _, err := File(context.Background(), ffmpeg, filepath.Join(dir, "b.flac"), Options{Format: "mp3"}, nil); err == nil {
+		t.Error("failed ffmpeg: no error")
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() == "b.mp3" || strings.HasPrefix(e.Name(), ".convert-") {
+			t.Errorf("failed conversion left %s behind", e.Name())
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Package convtag copies a FLAC's tags and front cover onto the MP3, AAC,
+// ALAC, Vorbis and Opus files the converter makes from it. Which tags
+// ffmpeg carries across on its own depends on the output format, and it
+// can't put artwork in Ogg, so the converted file is remuxed (without
+// re-encoding) to write ID3v2.3 frames for MP3, MP4 atoms for AAC and ALAC,
+// and Vorbis comments, cover included, for Ogg.
+package convtag
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"flacidal/internal/flacmeta"
+)
+
+// Container is the kind of file a conversion output is, by extension.
+type Container string
+
+const (
+	MP3 Container = "mp3"
+	MP4 Container = "mp4" // .m4a etc.: AAC and ALAC
+	Ogg Container = "ogg" // Vorbis and Opus
+)
+
+// ContainerOf returns the container of the file at path, or "" for formats
+// that hold no tags (raw ADTS .aac, WAV) or that aren't handled.
+func ContainerOf(path string) Container {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		return MP3
+	case ".m4a", ".m4b", ".mp4", ".alac":
+		return MP4
+	case ".ogg", ".oga", ".opus":
+		return Ogg
+	}
+	return ""
+}
+
+// Runner runs an external command and returns its combined output; tests
+// replace it.
+type Runner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// Field is one tag to write, under ffmpeg's name for it.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// mapped are the Vorbis comments ffmpeg's MP3 and MP4 muxers know under a
+// generic name. Track and disc numbers are handled apart, as they fold in
+// their totals.
+var mapped = map[string]string{
+	"TITLE":          "title",
+	"ARTIST":         "artist",
+	"ALBUM":          "album",
+	"ALBUMARTIST":    "album_artist",
+	"DATE":           "date",
+	"GENRE":          "genre",
+	"COMPOSER":       "composer",
+	"COPYRIGHT":      "copyright",
+	"LABEL":          "publisher",
+	"ORGANIZATION":   "publisher",
+	"COMMENT":        "comment",
+	"DESCRIPTION":    "comment",
+	"LYRICS":         "lyrics",
+	"UNSYNCEDLYRICS": "lyrics",
+	"GROUPING":       "grouping",
+}
+
+// numbers are the comments that make up track and disc: number, then the
+// names used for the total.
+var numbers = map[string][]string{
+	"track": {"TRACKNUMBER", "TRACKTOTAL", "TOTALTRACKS"},
+	"disc":  {"DISCNUMBER", "DISCTOTAL", "TOTALDISCS"},
+}
+
+// skipped are comments never copied: the picture travels separately and
+// the encoder is the converter's.
+var skipped = map[string]bool{"METADATA_BLOCK_PICTURE": true, "ENCODER": true}
+
+// Metadata returns the tags to write to a kind file from the source's
+// comments and cover, which may be nil. Repeated comments are joined with
+// "; ", as ffmpeg keeps one value per tag. Ogg files get every comment
+// under its own name plus the cover as METADATA_BLOCK_PICTURE; MP3 and MP4
+// files get ffmpeg's names, and MP3 files keep the rest as TXXX frames.
+func Metadata(kind Container, c *flacmeta.Comments, cover *flacmeta.Picture) []Field {
+	var fields []Field
+	seen := map[string]bool{}
+	add := func(key, value string) {
+		if value == "" || seen[strings.ToLower(key)] {
+			return
+		}
+		seen[strings.ToLower(key)] = true
+		fields = append(fields, Field{key, value})
+	}
+
+	if kind != Ogg {
+		for _, key := range []string{"track", "disc"} {
+			names := numbers[key]
+			n := c.Get(names[0])
+			if n == "" {
+				continue
+			}
+			if total := firstOf(c, names[1:]...); total != "" && !strings.Contains(n, "/") {
+				n += "/" + total
+			}
+			add(key, n)
+		}
+	}
+	for _, f := range c.Fields {
+		name := strings.ToUpper(f.Name)
+		if skipped[name] {
+			continue
+		}
+		value := strings.Join(c.GetAll(name), "; ")
+		switch {
+		case kind == Ogg:
+			add(name, value)
+		case mapped[name] != "":
+			add(mapped[name], value)
+		case isNumber(name):
+		case kind == MP3:
+			add(name, value)
+		}
+	}
+	if kind == Ogg && cover != nil {
+		add("METADATA_BLOCK_PICTURE", base64.StdEncoding.EncodeToString(cover.Marshal()))
+	}
+	return fields
+}
+
+func firstOf(c *flacmeta.Comments, names ...string) string {
+	for _, name := range names {
+		if v := c.Get(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func isNumber(name string) bool {
+	for _, names := range numbers {
+		if slices.Contains(names, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// FFMetadata encodes fields as an ffmpeg metadata file, which keeps long
+// values such as lyrics and Ogg's base64 cover off the command line.
+func FFMetadata(fields []Field) []byte {
+	esc := strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", "\\\n")
+	var b strings.Builder
+	b.WriteString(";FFMETADATA1\n")
+	for _, f := range fields {
+		fmt.Fprintf(&b, "%s=%s\n", esc.Replace(f.Key), esc.Replace(f.Value))
+	}
+	return []byte(b.String())
+}
+
+// Args returns the ffmpeg arguments remuxing audio into output with the
+// tags in the metadata file meta and, unless cover is "", that image
+// attached. Ogg files carry their cover in meta instead.
+func Args(kind Container, audio, meta, cover, output string) []string {
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-nostdin", "-y",
+		"-i", audio,
+		"-f", "ffmetadata", "-i", meta,
+	}
+	if cover != "" && kind != Ogg {
+		args = append(args, "-i", cover, "-map", "0:a", "-map", "2:0")
+	} else {
+		args = append(args, "-map", "0:a")
+	}
+	args = append(args, "-c", "copy")
+	switch kind {
+	case MP3:
+		args = append(args, "-map_metadata", "1", "-id3v2_version", "3")
+		if cover != "" {
+			args = append(args, "-metadata:s:v", "title=Album cover", "-metadata:s:v", "comment=Cover (front)")
+		}
+	case MP4:
+		args = append(args, "-map_metadata", "1")
+		if cover != "" {
+			args = append(args, "-disposition:v", "attached_pic")
+		}
+	case Ogg:
+		// The Ogg muxer writes the audio stream's tags, not the file's.
+		args = append(args, "-map_metadata", "-1", "-map_metadata:s:a", "1:g")
+	}
+	return append(args, output)
+}
+
+// coverOf returns the front cover among pics, or else the first picture,
+// or nil if there are none.
+func coverOf(pics []flacmeta.Picture) *flacmeta.Picture {
+	for i := range pics {
+		if pics[i].Type == flacmeta.PictureFrontCover {
+			return &pics[i]
+		}
+	}
+	if len(pics) > 0 {
+		return &pics[0]
+	}
+	return nil
+}
+
+// imageExt returns the file extension for an attachable cover's MIME type:
+// MP3 and MP4 files take JPEG and PNG.
+func imageExt(mime string) string {
+	switch strings.ToLower(mime) {
+	case "image/jpeg", "image/jpg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	}
+	return ""
+}
+
+// Tag copies the tags and cover of the FLAC at source onto the converted
+// file at output, replacing any ffmpeg carried across. Outputs whose
+// container holds no tags are left alone. The tagged file is written next
+// to output and renamed over it, so a failure leaves the conversion as it
+// was.
+func Tag(ctx context.Context, run Runner, ffmpeg, source, output string) error {
+	kind := ContainerOf(output)
+	if kind == "" {
+		return nil
+	}
+	f, err := flacmeta.Read(source)
+	if err != nil {
+		return err
+	}
+	c, err := f.Comments()
+	if err != nil {
+		return err
+	}
+	pics, err := f.Pictures()
+	if err != nil {
+		return err
+	}
+	cover := coverOf(pics)
+
+	dir := filepath.Dir(output)
+	var temps []string
+	defer func() {
+		for _, p := range temps {
+			os.Remove(p) // the tagged output is a no-op once renamed
+		}
+	}()
+	writeTemp := func(pattern string, data []byte) (string, error) {
+		tmp, err := os.CreateTemp(dir, pattern)
+		if err != nil {
+			return "", err
+		}
+		temps = append(temps, tmp.Name())
+		_, err = tmp.Write(data)
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		return tmp.Name(), err
+	}
+
+	meta, err := writeTemp(".convtag-*.txt", FFMetadata(Metadata(kind, c, cover)))
+	if err != nil {
+		return err
+	}
+	coverPath := ""
+	if cover != nil && kind != Ogg && imageExt(cover.MIME) != "" {
+		if coverPath, err = writeTemp(".convtag-*"+imageExt(cover.MIME), cover.Data); err != nil {
+			return err
+		}
+	}
+	tagged, err := writeTemp(".convtag-*"+filepath.Ext(output), nil)
+	if err != nil {
+		return err
+	}
+	if _, err := run(ctx, ffmpeg, Args(kind, output, meta, coverPath, tagged)...); err != nil {
+		return err
+	}
+	if st, err := os.Stat(output); err == nil {
+		os.Chmod(tagged, st.Mode().Perm()) //nolint:errcheck // keep the original's mode when possible
+	}
+	return os.Rename(tagged, output)
+}
@@ -0,0 +1,125 @@
+package convtag
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+)
+
+func testComments() *flacmeta.Comments {
+	return &flacmeta.Comments{Fields: []flacmeta.Field{
+		{Name: "TITLE", Value: "Song"},
+		{Name: "ARTIST", Value: "A"},
+		{Name: "ARTIST", Value: "B"},
+		{Name: "TRACKNUMBER", Value: "3"},
+		{Name: "TRACKTOTAL", Value: "12"},
+		{Name: "ISRC", Value: "USABC2600001"},
+		{Name: "ENCODER", Value: "whatever"},
+	}}
+}
+
+func TestContainerOf(t *testing.T) {
+	for path, want := range map[string]Container{
+		"a.MP3": MP3, "a.m4a": MP4, "a.opus": Ogg, "a.ogg": Ogg, "a.aac": "", "a.wav": "",
+	} {
+		if got := ContainerOf(path); got != want {
+			t.Errorf("ContainerOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	cover := &flacmeta.Picture{Type: flacmeta.PictureFrontCover, MIME: "image/jpeg", Data: []byte("jpeg")}
+
+	mp3 := Metadata(MP3, testComments(), cover)
+	want := []Field{{"track", "3/12"}, {"title", "Song"}, {"artist", "A; B"}, {"ISRC", "USABC2600001"}}
+	if !slices.Equal(mp3, want) {
+		t.Errorf("MP3 = %v, want %v", mp3, want)
+	}
+	if mp4 := Metadata(MP4, testComments(), cover); !slices.Equal(mp4, want[:3]) {
+		t.Errorf("MP4 = %v, want the mapped tags only", mp4)
+	}
+
+	ogg := Metadata(Ogg, testComments(), cover)
+	if len(ogg) != 6 || ogg[0] != (Field{"TITLE", "Song"}) || ogg[2] != (Field{"TRACKNUMBER", "3"}) {
+		t.Fatalf("Ogg = %v", ogg)
+	}
+	pic := ogg[len(ogg)-1]
+	data, _ := base64.StdEncoding.DecodeString(pic.Value)
+	if p, err := flacmeta.ParsePicture(data); pic.Key != "METADATA_BLOCK_PICTURE" || err != nil || string(p.Data) != "jpeg" {
+		t.Errorf("Ogg cover = %v, %v", pic.Key, err)
+	}
+}
+
+func TestFFMetadata(t *testing.T) {
+	got := string(FFMetadata([]Field{{"title", "a=b; #1\\"}, {"lyrics", "line\nline"}}))
+	want := ";FFMETADATA1\ntitle=a\\=b\\; \\#1\\\\\nlyrics=line\\\nline\n"
+	if got != want {
+		t.Errorf("FFMetadata = %q, want %q", got, want)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	mp3 := strings.Join(Args(MP3, "in.mp3", "meta", "cover.jpg", "out.mp3"), " ")
+	for _, part := range []string{"-i cover.jpg -map 0:a -map 2:0 -c copy", "-map_metadata 1 -id3v2_version 3", "comment=Cover (front) out.mp3"} {
+		if !strings.Contains(mp3, part) {
+			t.Errorf("MP3 args %q lack %q", mp3, part)
+		}
+	}
+	if m4a := strings.Join(Args(MP4, "in.m4a", "meta", "", "out.m4a"), " "); strings.Contains(m4a, "attached_pic") || strings.Contains(m4a, "2:0") {
+		t.Errorf("MP4 args without a cover: %q", m4a)
+	}
+	if ogg := strings.Join(Args(Ogg, "in.opus", "meta", "cover.jpg", "out.opus"), " "); strings.Contains(ogg, "cover.jpg") || !strings.Contains(ogg, "-map_metadata:s:a 1:g") {
+		t.Errorf("Ogg args: %q", ogg)
+	}
+}
+
+func TestTag(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "song.flac")
+	os.WriteFile(source, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644)
+	f, err := flacmeta.Read(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetComments(testComments())
+	f.AddPicture(flacmeta.Picture{Type: flacmeta.PictureFrontCover, MIME: "image/png", Data: []byte("png")})
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "song.mp3")
+	os.WriteFile(output, []byte("untagged"), 0644)
+
+	var meta, cover string
+	run := func(_ context.Context, name string, args ...string) ([]byte, error) {
+		i := slices.Index(args, "ffmetadata")
+		b, _ := os.ReadFile(args[i+2])
+		meta = string(b)
+		b, _ = os.ReadFile(args[slices.Index(args, "-map")-1])
+		cover = string(b)
+		return nil, os.WriteFile(args[len(args)-1], []byte("tagged"), 0644)
+	}
+	if err := Tag(context.Background(), run, "ffmpeg", source, output); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(meta, "title=Song\n") || cover != "png" {
+		t.Errorf("ffmpeg got metadata %q and cover %q", meta, cover)
+	}
+	if b, _ := os.ReadFile(output); string(b) != "tagged" {
+		t.Errorf("output = %q, want the tagged remux", b)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+
+	wav := filepath.Join(dir, "song.wav")
+	if err := Tag(context.Background(), nil, "ffmpeg", source, wav); err != nil {
+		t.Errorf("untaggable output: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+// Package coverart shrinks cover images before they are embedded: the
+// 1280×1280 JPEGs sources serve are needlessly large inside every track of
+// an album, and some players and DAPs struggle with them.
+package coverart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // covers are JPEG or PNG
+)
+
+// DefaultQuality is the JPEG quality used when none is configured.
+const DefaultQuality = 90
+
+// Result is a shrunk image.
+type Result struct {
+	Data          []byte
+	Width, Height int
+}
+
+// Shrink scales data down so neither side exceeds maxSize pixels (0 keeps
+// the size) and re-encodes it as a JPEG of quality (0 means
+// DefaultQuality). ok is false when that wouldn't make the image smaller,
+// in which case data should be kept as is.
+func Shrink(data []byte, maxSize, quality int) (res Result, ok bool, err error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, false, fmt.Errorf("decoding cover: %w", err)
+	}
+	if quality <= 0 {
+		quality = DefaultQuality
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maxSize > 0 && (w > maxSize || h > maxSize) {
+		if w >= h {
+			w, h = maxSize, max(1, h*maxSize/w)
+		} else {
+			w, h = max(1, w*maxSize/h), maxSize
+		}
+		src = scale(src, w, h)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: quality}); err != nil {
+		return Result{}, false, err
+	}
+	if buf.Len() >= len(data) {
+		return Result{}, false, nil
+	}
+	return Result{Data: buf.Bytes(), Width: w, Height: h}, true, nil
+}
+
+// scale resizes src to w×h by averaging the source pixels each destination
+// pixel covers (a box filter), which is accurate for the downscaling Shrink
+// does.
+func scale(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	sw, sh := b.Dx(), b.Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := range w {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride+x0*4 : sy*rgba.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			o := y*dst.Stride + x*4
+			for c := range 4 {
+				dst.Pix[o+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,89 @@
+package coverart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math/rand/v2"
+	"testing"
+)
+
+// testImage returns a w×h image of two colored halves, encoded by enc.
+func testImage(t *testing.T, w, h int, enc func(io.Writer, image.Image) error) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			c := color.RGBA{200, 30, 30, 255}
+			if x >= w/2 {
+				c = color.RGBA{30, 30, 200, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := enc(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(q int) func(io.Writer, image.Image) error {
+	return func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, &jpeg.Options{Quality: q}) }
+}
+
+func TestShrink_Resizes(t *testing.T) {
+	data := testImage(t, 1280, 640, encodeJPEG(100))
+	res, ok, err := Shrink(data, 500, 0)
+	if err != nil || !ok {
+		t.Fatalf("Shrink = %v, %v", ok, err)
+	}
+	if res.Width != 500 || res.Height != 250 || len(res.Data) >= len(data) {
+		t.Errorf("result %dx%d, %d bytes (from %d)", res.Width, res.Height, len(res.Data), len(data))
+	}
+	img, err := jpeg.Decode(bytes.NewReader(res.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 500 || b.Dy() != 250 {
+		t.Errorf("decoded %v", b)
+	}
+	// The halves keep their colors.
+	if r, _, bl, _ := img.At(10, 100).RGBA(); r>>8 < 150 || bl>>8 > 80 {
+		t.Errorf("left half color r=%d b=%d", r>>8, bl>>8)
+	}
+}
+
+func TestShrink_PNGToJPEG(t *testing.T) {
+	// Noise, like a photo: PNG stores it far less compactly than JPEG.
+	img := image.NewRGBA(image.Rect(0, 0, 300, 300))
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.UintN(256))
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	res, ok, err := Shrink(data, 0, 80)
+	if err != nil || !ok || res.Width != 300 {
+		t.Fatalf("Shrink = %+v, %v, %v", res.Width, ok, err)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(res.Data)); err != nil {
+		t.Errorf("not a JPEG: %v", err)
+	}
+}
+
+func TestShrink_KeepsSmallerOriginal(t *testing.T) {
+	data := testImage(t, 200, 200, encodeJPEG(30))
+	if _, ok, err := Shrink(data, 600, 95); err != nil || ok {
+		t.Errorf("Shrink = %v, %v; want the original kept", ok, err)
+	}
+	if _, _, err := Shrink([]byte("not an image"), 600, 0); err == nil {
+		t.Error("no error for garbage")
+	}
+}
@@ -0,0 +1,243 @@
+// Package coverproxy fetches cover images for remote web UI clients that
+// can't reach the music services' image hosts themselves, e.g. behind a
+// corporate proxy that blocks resources.tidal.com. Only the image hosts
+// listed in Hosts are fetched, so the server can't be used to reach
+// anything else, and images are kept on disk, so a cover every list shows
+// is downloaded once.
+package coverproxy
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DirName is the proxy's folder inside the data directory.
+const DirName = "cover_proxy"
+
+const (
+	// TTL is how long a fetched image is served from disk. Cover URLs
+	// name one image for good, so it is long.
+	TTL = 7 * 24 * time.Hour
+	// MaxSize caps a fetched image.
+	MaxSize = 16 << 20
+)
+
+// DefaultUserAgent is sent to the hosts of sources without a User-Agent
+// of their own.
+const DefaultUserAgent = "Mozilla/5.0 (compatible; FLACidal)"
+
+// DefaultClient fetches images when a Proxy has no Client.
+var DefaultClient = &http.Client{Timeout: 20 * time.Second}
+
+var (
+	// ErrNotAllowed is returned for URLs outside Hosts.
+	ErrNotAllowed = errors.New("not a cover image host")
+	// ErrNotImage is returned when a host answers with anything but an
+	// image.
+	ErrNotImage = errors.New("not an image")
+)
+
+// Hosts maps the hosts images are fetched from to their source, which
+// picks the User-Agent sent to them. A host's subdomains are allowed too.
+var Hosts = map[string]string{
+	"resources.tidal.com": "tidal",
+	"static.qobuz.com":    "qobuz",
+	"dzcdn.net":           "deezer",
+	"coverartarchive.org": "musicbrainz",
+	"archive.org":         "musicbrainz", // where the Cover Art Archive redirects
+	"scdn.co":             "spotify",
+	"mzstatic.com":        "apple",
+}
+
+// Sources returns the sources of Hosts, sorted.
+func Sources() []string {
+	var sources []string
+	for _, source := range Hosts {
+		if !slices.Contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+	slices.Sort(sources)
+	return sources
+}
+
+// Source returns the source of u's host, or ErrNotAllowed when u isn't an
+// http(s) URL on one of Hosts.
+func Source(u *url.URL) (string, error) {
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", ErrNotAllowed
+	}
+	host := strings.ToLower(u.Hostname())
+	for {
+		if source, ok := Hosts[host]; ok {
+			return source, nil
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			return "", ErrNotAllowed
+		}
+		host = parent
+	}
+}
+
+// Proxy fetches and keeps cover images. It is safe for concurrent use.
+type Proxy struct {
+	TTL    time.Duration // TTL when 0
+	Client *http.Client  // DefaultClient when nil
+
+	dir string
+}
+
+// Open returns the proxy keeping its images in dataDir's cover_proxy
+// folder, creating it, and deletes the images that have expired.
+func Open(dataDir string) (*Proxy, error) {
+	dir := filepath.Join(dataDir, DirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	p := &Proxy{dir: dir}
+	p.prune()
+	return p, nil
+}
+
+// Fetch returns the image at rawURL and its MIME type, from disk while it
+// is fresh. userAgents maps sources to the User-Agent sent to their hosts.
+// Redirects are only followed to Hosts.
+func (p *Proxy) Fetch(rawURL string, userAgents map[string]string) ([]byte, string, error) {
+	u, source, err := parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	path := p.path(u.String())
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < p.ttl() {
+		if data, err := os.ReadFile(path); err == nil {
+			return data, http.DetectContentType(data), nil
+		}
+	}
+
+	data, mimeType, err := p.download(u, cmp.Or(userAgents[source], DefaultUserAgent))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := writeFile(path, data); err != nil {
+		return nil, "", err
+	}
+	return data, mimeType, nil
+}
+
+// Download fetches the image at rawURL like Proxy.Fetch, without keeping
+// it, with DefaultClient.
+func Download(rawURL string, userAgents map[string]string) ([]byte, string, error) {
+	u, source, err := parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	return (&Proxy{}).download(u, cmp.Or(userAgents[source], DefaultUserAgent))
+}
+
+// parse parses rawURL and returns the source of its host, or
+// ErrNotAllowed.
+func parse(rawURL string) (*url.URL, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", ErrNotAllowed
+	}
+	source, err := Source(u)
+	if err != nil {
+		return nil, "", err
+	}
+	return u, source, nil
+}
+
+// download fetches the image at u.
+func (p *Proxy) download(u *url.URL, userAgent string) ([]byte, string, error) {
+	client := *cmp.Or(p.Client, DefaultClient)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if _, err := Source(req.URL); err != nil {
+			return fmt.Errorf("redirect to %s: %w", req.URL.Host, err)
+		}
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "image/*")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%s answered %s", u.Host, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > MaxSize {
+		return nil, "", fmt.Errorf("image over %d MB", MaxSize>>20)
+	}
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, "", fmt.Errorf("%s: %w (%s)", u.Host, ErrNotImage, mimeType)
+	}
+	return data, mimeType, nil
+}
+
+func (p *Proxy) ttl() time.Duration {
+	return cmp.Or(p.TTL, TTL)
+}
+
+// path is where the image at rawURL is kept.
+func (p *Proxy) path(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(p.dir, hex.EncodeToString(sum[:]))
+}
+
+// prune deletes the expired images.
+func (p *Proxy) prune() {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && time.Since(info.ModTime()) >= p.ttl() {
+			os.Remove(filepath.Join(p.dir, e.Name()))
+		}
+	}
+}
+
+// writeFile writes data to path through a temporary file, so concurrent
+// readers never see half an image.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,97 @@
+package coverproxy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// allowLocal lets the test servers, on 127.0.0.1, through as source "test".
+func allowLocal(t *testing.T) {
+	Hosts["127.0.0.1"] = "test"
+	t.Cleanup(func() { delete(Hosts, "127.0.0.1") })
+}
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"https://resources.tidal.com/images/a/b/640x640.jpg", "tidal"},
+		{"https://e-cdns-images.dzcdn.net/images/cover/x/500x500.jpg", "deezer"},
+		{"https://ia800.us.archive.org/x.jpg", "musicbrainz"},
+		{"https://tidal.com/images/a.jpg", ""},
+		{"https://evil.com/?resources.tidal.com", ""},
+		{"https://resources.tidal.com.evil.com/a.jpg", ""},
+		{"file://resources.tidal.com/etc/passwd", ""},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		got, err := Source(u)
+		if got != tt.want || (tt.want == "") != errors.Is(err, ErrNotAllowed) {
+			t.Errorf("Source(%s) = %q, %v; want %q", tt.url, got, err, tt.want)
+		}
+	}
+}
+
+func TestFetch_CachesAndSendsUserAgent(t *testing.T) {
+	allowLocal(t)
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if ua := r.Header.Get("User-Agent"); ua != "Custom/1.0" {
+			t.Errorf("User-Agent = %q", ua)
+		}
+		w.Write(png)
+	}))
+	defer srv.Close()
+
+	p, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		data, mimeType, err := p.Fetch(srv.URL+"/cover.png", map[string]string{"test": "Custom/1.0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mimeType != "image/png" || len(data) != len(png) {
+			t.Errorf("got %s, %d bytes", mimeType, len(data))
+		}
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, want 1", requests)
+	}
+}
+
+func TestFetch_Rejects(t *testing.T) {
+	allowLocal(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Write([]byte("<html>login</html>"))
+		case "/away":
+			http.Redirect(w, r, "https://example.com/x.png", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p, _ := Open(t.TempDir())
+	if _, _, err := p.Fetch("https://example.com/x.png", nil); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("other host: %v", err)
+	}
+	if _, _, err := p.Fetch(srv.URL+"/page", nil); !errors.Is(err, ErrNotImage) {
+		t.Errorf("HTML page: %v", err)
+	}
+	if _, _, err := p.Fetch(srv.URL+"/away", nil); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("redirect elsewhere: %v", err)
+	}
+	if _, _, err := p.Fetch(srv.URL+"/missing", nil); err == nil {
+		t.Error("404 fetched")
+	}
+}
@@ -0,0 +1,278 @@
+// Package coverstore keeps the library's cover images content-addressed:
+// each distinct image is stored once under its SHA-256, however many tracks
+// embed it, along with the thumbnails made from it. It backs the file
+// manager's Covers tab and the library's cover size totals.
+package coverstore
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"flacidal/internal/coverart"
+	"flacidal/internal/flacmeta"
+)
+
+// DirName is the store's folder inside the data directory.
+const DirName = "covers"
+
+// Thumbnail sizes, in pixels along the longest side.
+const (
+	DefaultThumbnail = 160
+	MaxThumbnail     = 1024
+)
+
+// ErrNotFound is returned for hashes the store doesn't hold.
+var ErrNotFound = errors.New("no such cover")
+
+// Store is a content-addressed cover store. A file's cover is only read
+// again once the file's size or modification time changes.
+type Store struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]fileCover
+}
+
+// fileCover is what the store last read from one file.
+type fileCover struct {
+	modTime time.Time
+	size    int64
+	hash    string // "" when the file has no cover
+	bytes   int64
+}
+
+// Open returns the store in dataDir's covers folder, creating it.
+func Open(dataDir string) (*Store, error) {
+	dir := filepath.Join(dataDir, DirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, files: map[string]fileCover{}}, nil
+}
+
+// Put stores data, unless an identical image is already stored, and
+// returns its hash.
+func (s *Store) Put(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	path := s.path(hash, "")
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+	return hash, writeFile(path, data)
+}
+
+// Get returns the image stored under hash and its MIME type.
+func (s *Store) Get(hash string) ([]byte, string, error) {
+	if !validHash(hash) {
+		return nil, "", ErrNotFound
+	}
+	data, err := os.ReadFile(s.path(hash, ""))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return data, http.DetectContentType(data), nil
+}
+
+// Thumbnail returns the image stored under hash scaled down to at most
+// size pixels per side (0 means DefaultThumbnail; larger than MaxThumbnail
+// is capped), and its MIME type. Thumbnails are made once and kept next to
+// the image; images already that small are returned as they are.
+func (s *Store) Thumbnail(hash string, size int) ([]byte, string, error) {
+	if size <= 0 {
+		size = DefaultThumbnail
+	}
+	size = min(size, MaxThumbnail)
+	if !validHash(hash) {
+		return nil, "", ErrNotFound
+	}
+	thumb := s.path(hash, "-"+strconv.Itoa(size)+".jpg")
+	if data, err := os.ReadFile(thumb); err == nil {
+		return data, "image/jpeg", nil
+	}
+	data, mime, err := s.Get(hash)
+	if err != nil {
+		return nil, "", err
+	}
+	res, ok, err := coverart.Shrink(data, size, 0)
+	if err != nil || !ok {
+		return data, mime, nil // undecodable images are shown as they are
+	}
+	if err := writeFile(thumb, res.Data); err != nil {
+		return nil, "", err
+	}
+	return res.Data, "image/jpeg", nil
+}
+
+// FileCover stores the cover of the FLAC at path (see cover) and returns
+// its hash and size, or "" when the file has none.
+func (s *Store) FileCover(path string) (string, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	s.mu.Lock()
+	c, ok := s.files[path]
+	s.mu.Unlock()
+	if ok && c.size == info.Size() && c.modTime.Equal(info.ModTime()) {
+		return c.hash, c.bytes, nil
+	}
+
+	c = fileCover{modTime: info.ModTime(), size: info.Size()}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return "", 0, err
+	}
+	if pic := cover(f); pic != nil {
+		if c.hash, err = s.Put(pic.Data); err != nil {
+			return "", 0, err
+		}
+		c.bytes = int64(len(pic.Data))
+	}
+	s.mu.Lock()
+	s.files[path] = c
+	s.mu.Unlock()
+	return c.hash, c.bytes, nil
+}
+
+// FileThumbnail returns the thumbnail of the FLAC at path's cover (see
+// FileCover and Thumbnail), its MIME type and the cover's hash, or
+// ErrNotFound when the file has no cover. Only a changed file is read
+// again, so repeated calls for a folder view cost a stat each.
+func (s *Store) FileThumbnail(path string, size int) ([]byte, string, string, error) {
+	hash, _, err := s.FileCover(path)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if hash == "" {
+		return nil, "", "", ErrNotFound
+	}
+	data, mime, err := s.Thumbnail(hash, size)
+	return data, mime, hash, err
+}
+
+// ReadCover returns the cover of the FLAC at path (see cover), or
+// ErrNotFound when it has none.
+func ReadCover(path string) (*flacmeta.Picture, error) {
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	pic := cover(f)
+	if pic == nil {
+		return nil, ErrNotFound
+	}
+	return pic, nil
+}
+
+// cover returns f's front cover, or its first picture when it has no front
+// cover, or nil.
+func cover(f *flacmeta.File) *flacmeta.Picture {
+	pics, err := f.Pictures()
+	if err != nil || len(pics) == 0 {
+		return nil
+	}
+	for i := range pics {
+		if pics[i].Type == flacmeta.PictureFrontCover {
+			return &pics[i]
+		}
+	}
+	return &pics[0]
+}
+
+// Cover is one distinct cover in a Library.
+type Cover struct {
+	Hash   string `json:"hash"`
+	Size   int64  `json:"size"`   // bytes, stored once
+	Tracks int    `json:"tracks"` // files embedding it
+	Path   string `json:"path"`   // the first of them
+}
+
+// Library sums up the covers embedded in a set of files.
+type Library struct {
+	Files         int     `json:"files"`
+	WithCover     int     `json:"withCover"`
+	EmbeddedBytes int64   `json:"embeddedBytes"` // every file's cover, counted per file
+	UniqueBytes   int64   `json:"uniqueBytes"`   // each distinct cover once
+	Covers        []Cover `json:"covers"`        // most embedded first
+}
+
+// Library stores the covers of the FLACs at paths and sums them up. Files
+// that can't be read are counted without a cover.
+func (s *Store) Library(paths []string) Library {
+	lib := Library{Files: len(paths), Covers: []Cover{}}
+	index := map[string]int{}
+	for _, path := range paths {
+		hash, size, err := s.FileCover(path)
+		if err != nil || hash == "" {
+			continue
+		}
+		lib.WithCover++
+		lib.EmbeddedBytes += size
+		i, ok := index[hash]
+		if !ok {
+			i = len(lib.Covers)
+			index[hash] = i
+			lib.Covers = append(lib.Covers, Cover{Hash: hash, Size: size, Path: path})
+			lib.UniqueBytes += size
+		}
+		lib.Covers[i].Tracks++
+	}
+	slices.SortStableFunc(lib.Covers, func(a, b Cover) int { return cmp.Compare(b.Tracks, a.Tracks) })
+	return lib
+}
+
+// path returns where the image hash (plus suffix, for thumbnails) lives,
+// fanned out by the hash's first byte.
+func (s *Store) path(hash, suffix string) string {
+	return filepath.Join(s.dir, hash[:2], hash+suffix)
+}
+
+// validHash reports whether hash is a hex SHA-256, which also keeps it from
+// naming anything outside the store.
+func validHash(hash string) bool {
+	if len(hash) != 2*sha256.Size {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// writeFile writes data to path via a temporary file, so a reader never
+// sees a partial image.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cover: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
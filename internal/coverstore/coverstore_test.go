@@ -0,0 +1,137 @@
+package coverstore
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/flacmeta/flactest"
+)
+
+// writeFLAC writes a minimal FLAC embedding pics.
+func writeFLAC(t *testing.T, path string, pics ...flacmeta.Picture) {
+	t.Helper()
+	blocks := make([]flacmeta.Block, len(pics))
+	for i, p := range pics {
+		blocks[i] = flactest.Picture(p)
+	}
+	flactest.Write(t, path, flactest.CD, blocks...)
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i * 13 % 251)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLibrary_DedupesCovers(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	album := flacmeta.Picture{Type: flacmeta.PictureFrontCover, MIME: "image/jpeg", Data: testJPEG(t, 64, 64)}
+	other := flacmeta.Picture{Type: flacmeta.PictureFrontCover, MIME: "image/jpeg", Data: []byte("another cover")}
+	back := flacmeta.Picture{Type: flacmeta.PictureBackCover, MIME: "image/jpeg", Data: []byte("back")}
+	var paths []string
+	for i, pics := range [][]flacmeta.Picture{{album}, {back, album}, {album}, {other}, nil} {
+		path := filepath.Join(dir, string(rune('a'+i))+".flac")
+		writeFLAC(t, path, pics...)
+		paths = append(paths, path)
+	}
+	paths = append(paths, filepath.Join(dir, "missing.flac"))
+
+	lib := s.Library(paths)
+	a, o := int64(len(album.Data)), int64(len(other.Data))
+	if lib.Files != 6 || lib.WithCover != 4 || lib.EmbeddedBytes != 3*a+o || lib.UniqueBytes != a+o {
+		t.Errorf("library = %+v", lib)
+	}
+	if len(lib.Covers) != 2 || lib.Covers[0].Tracks != 3 || lib.Covers[0].Path != paths[0] || lib.Covers[1].Tracks != 1 {
+		t.Fatalf("covers = %+v", lib.Covers)
+	}
+	data, mime, err := s.Get(lib.Covers[0].Hash)
+	if err != nil || mime != "image/jpeg" || !bytes.Equal(data, album.Data) {
+		t.Errorf("Get = %d bytes, %q, %v", len(data), mime, err)
+	}
+
+	// A changed file is read again.
+	writeFLAC(t, paths[3], album)
+	if lib = s.Library(paths); len(lib.Covers) != 1 || lib.Covers[0].Tracks != 4 {
+		t.Errorf("after retagging, covers = %+v", lib.Covers)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := s.Put(testJPEG(t, 400, 300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := s.Put(testJPEG(t, 400, 300)); again != hash {
+		t.Error("same image stored under another hash")
+	}
+	thumb, mime, err := s.Thumbnail(hash, 100)
+	if err != nil || mime != "image/jpeg" {
+		t.Fatalf("Thumbnail = %q, %v", mime, err)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumb))
+	if err != nil || cfg.Width != 100 || cfg.Height != 75 {
+		t.Errorf("thumbnail = %+v, %v", cfg, err)
+	}
+	if _, err := os.Stat(s.path(hash, "-100.jpg")); err != nil {
+		t.Errorf("thumbnail not kept: %v", err)
+	}
+
+	for _, bad := range []string{"", "../../etc/passwd", hash[:10], "zz" + hash[2:]} {
+		if _, _, err := s.Thumbnail(bad, 0); err != ErrNotFound {
+			t.Errorf("Thumbnail(%q) error = %v, want ErrNotFound", bad, err)
+		}
+	}
+}
+
+func TestFileThumbnail(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	withCover := filepath.Join(dir, "a.flac")
+	writeFLAC(t, withCover, flacmeta.Picture{Type: flacmeta.PictureFrontCover, MIME: "image/jpeg", Data: testJPEG(t, 600, 600)})
+	bare := filepath.Join(dir, "b.flac")
+	writeFLAC(t, bare)
+
+	data, mime, hash, err := s.FileThumbnail(withCover, 100)
+	if err != nil {
+		t.Fatalf("FileThumbnail() error = %v", err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || mime != "image/jpeg" || cfg.Width != 100 || hash == "" {
+		t.Errorf("FileThumbnail() = %dx%d %q hash %q, %v; want a 100px JPEG", cfg.Width, cfg.Height, mime, hash, err)
+	}
+	if _, _, _, err := s.FileThumbnail(bare, 100); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FileThumbnail() without cover: error = %v, want ErrNotFound", err)
+	}
+
+	pic, err := ReadCover(withCover)
+	if err != nil || pic.MIME != "image/jpeg" {
+		t.Errorf("ReadCover() = %+v, %v", pic, err)
+	}
+	if _, err := ReadCover(bare); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ReadCover() without cover: error = %v, want ErrNotFound", err)
+	}
+}
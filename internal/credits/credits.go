@@ -0,0 +1,143 @@
+// Package credits fetches the credits of albums and tracks — producers,
+// engineers, featured and session musicians — from the services that
+// publish them: Qobuz lists each track's performers, Tidal its
+// contributors. Fetched albums are kept in a table of the library database
+// (see Store), so album views don't ask the service again.
+package credits
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultClient is the HTTP client fetchers use when theirs is nil.
+var DefaultClient = &http.Client{Timeout: 20 * time.Second}
+
+// Credit is one person's role on a track.
+type Credit struct {
+	Name string `json:"name"`
+	Role string `json:"role"` // "Producer", "Mixing Engineer", "Guitar"…
+}
+
+// Track is a track's credits.
+type Track struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	TrackNumber int      `json:"trackNumber,omitempty"`
+	DiscNumber  int      `json:"discNumber,omitempty"`
+	Credits     []Credit `json:"credits"`
+}
+
+// Role is everyone credited with one role on an album.
+type Role struct {
+	Role  string   `json:"role"`
+	Names []string `json:"names"`
+}
+
+// Album is an album's credits, track by track, and summed up by role.
+type Album struct {
+	Source    string    `json:"source"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Artist    string    `json:"artist"`
+	Tracks    []Track   `json:"tracks"`
+	Roles     []Role    `json:"roles"` // see Summarize
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// Fetcher looks credits up at one service.
+type Fetcher interface {
+	Album(id string) (*Album, error)
+	Track(id string) ([]Credit, error)
+}
+
+// Summarize lists each role credited on tracks with the people holding it,
+// both in order of first appearance.
+func Summarize(tracks []Track) []Role {
+	roles := []Role{}
+	index := map[string]int{}
+	for _, t := range tracks {
+		for _, c := range t.Credits {
+			i, ok := index[c.Role]
+			if !ok {
+				i = len(roles)
+				index[c.Role] = i
+				roles = append(roles, Role{Role: c.Role})
+			}
+			if !slices.Contains(roles[i].Names, c.Name) {
+				roles[i].Names = append(roles[i].Names, c.Name)
+			}
+		}
+	}
+	return roles
+}
+
+// artistRoles are the roles the ARTIST tags already cover.
+var artistRoles = map[string]bool{"artist": true, "main artist": true}
+
+// Performers returns the PERFORMER tag values for credits: one per person,
+// "Name (Role, Role)", in order of first appearance. Main artists are left
+// out.
+func Performers(credits []Credit) []string {
+	var names []string
+	roles := map[string][]string{}
+	for _, c := range credits {
+		if artistRoles[strings.ToLower(c.Role)] {
+			continue
+		}
+		if _, ok := roles[c.Name]; !ok {
+			names = append(names, c.Name)
+		}
+		if !slices.Contains(roles[c.Name], c.Role) {
+			roles[c.Name] = append(roles[c.Name], c.Role)
+		}
+	}
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = fmt.Sprintf("%s (%s)", name, strings.Join(roles[name], ", "))
+	}
+	return values
+}
+
+// roleName spaces out a CamelCase role: "MixingEngineer" gives "Mixing
+// Engineer".
+func roleName(role string) string {
+	var b strings.Builder
+	prev := ' '
+	for _, r := range strings.TrimSpace(role) {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String()
+}
+
+// getJSON fetches rawURL with header into v.
+func getJSON(client *http.Client, rawURL string, header http.Header, v any) error {
+	if client == nil {
+		client = DefaultClient
+	}
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
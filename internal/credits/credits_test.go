@@ -0,0 +1,47 @@
+package credits
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQobuzPerformers(t *testing.T) {
+	got := ParseQobuzPerformers("Daft Punk, MainArtist - Nile Rodgers, Guitar, FeaturedArtist - , Producer - Mick Guzauski, MixingEngineer")
+	want := []Credit{
+		{"Daft Punk", "Main Artist"},
+		{"Nile Rodgers", "Guitar"},
+		{"Nile Rodgers", "Featured Artist"},
+		{"Mick Guzauski", "Mixing Engineer"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("credits = %+v, want %+v", got, want)
+	}
+	if got := ParseQobuzPerformers(""); got == nil || len(got) != 0 {
+		t.Errorf("empty list = %#v, want no credits", got)
+	}
+}
+
+func TestPerformers(t *testing.T) {
+	got := Performers([]Credit{
+		{"Daft Punk", "Main Artist"},
+		{"Nile Rodgers", "Guitar"},
+		{"Pharrell Williams", "Vocals"},
+		{"Nile Rodgers", "Composer"},
+		{"Nile Rodgers", "Guitar"},
+	})
+	want := []string{"Nile Rodgers (Guitar, Composer)", "Pharrell Williams (Vocals)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Performers = %q, want %q", got, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	got := Summarize([]Track{
+		{Credits: []Credit{{"A", "Producer"}, {"B", "Guitar"}}},
+		{Credits: []Credit{{"C", "Producer"}, {"A", "Producer"}}},
+	})
+	want := []Role{{"Producer", []string{"A", "C"}}, {"Guitar", []string{"B"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package credits
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQobuz_Album(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/album/get" || r.URL.Query().Get("app_id") != "app" || r.URL.Query().Get("album_id") != "a1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"title": "Album", "artist": {"name": "Artist"}, "tracks": {"items": [
+			{"id": 11, "title": "One", "track_number": 1, "media_number": 1, "performers": "Jane, Producer"}]}}`))
+	}))
+	defer srv.Close()
+
+	album, err := (&Qobuz{Base: srv.URL, AppID: "app"}).Album("a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if album.Title != "Album" || len(album.Tracks) != 1 || album.Tracks[0].ID != "11" || len(album.Roles) != 1 {
+		t.Errorf("album = %+v", album)
+	}
+	if _, err := (&Qobuz{Base: srv.URL}).Album("a1"); err == nil {
+		t.Error("no error without an app ID")
+	}
+}
+
+func TestTidal_Album(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Tidal-Token") != "tok" || r.URL.Query().Get("countryCode") != "US" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"totalNumberOfItems": 2, "items": [
+			{"type": "track", "item": {"id": 1, "title": "One", "trackNumber": 1, "volumeNumber": 1, "artist": {"name": "Artist"}, "album": {"title": "Album"}},
+			 "credits": [{"type": "Producer", "contributors": [{"name": "Jane"}, {"name": "John"}]}]},
+			{"type": "video", "item": {"id": 2, "title": "Clip"}}]}`))
+	}))
+	defer srv.Close()
+
+	album, err := (&Tidal{Base: srv.URL, Token: "tok"}).Album("9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if album.Title != "Album" || album.Artist != "Artist" || len(album.Tracks) != 1 || len(album.Tracks[0].Credits) != 2 {
+		t.Errorf("album = %+v", album)
+	}
+	if _, err := (&Tidal{Base: srv.URL, Token: "bad"}).Album("9"); err == nil {
+		t.Error("no error for a refused token")
+	}
+}
@@ -0,0 +1,82 @@
+package credits
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+
+	"flacidal/internal/library"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS credits (
+	source     TEXT NOT NULL,
+	album_id   TEXT NOT NULL,
+	album      TEXT NOT NULL, -- the Album, as JSON
+	fetched_at INTEGER NOT NULL,
+	PRIMARY KEY (source, album_id)
+);
+`
+
+// Store keeps fetched albums' credits in the library database. A nil
+// Store keeps nothing.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the credits table of the library database in dataDir,
+// creating it.
+func Open(dataDir string) (*Store, error) {
+	db, err := sql.Open(library.Driver, "file:"+filepath.Join(dataDir, library.FileName)+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Get returns the stored credits of source's album id, or nil if there
+// are none.
+func (s *Store) Get(source, id string) (*Album, error) {
+	if s == nil {
+		return nil, nil
+	}
+	var data string
+	err := s.db.QueryRow("SELECT album FROM credits WHERE source = ? AND album_id = ?", source, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var album Album
+	if err := json.Unmarshal([]byte(data), &album); err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+// Put stores album, replacing its earlier credits.
+func (s *Store) Put(album *Album) error {
+	if s == nil {
+		return nil
+	}
+	data, err := json.Marshal(album)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec("INSERT OR REPLACE INTO credits (source, album_id, album, fetched_at) VALUES (?, ?, ?, ?)",
+		album.Source, album.ID, string(data), album.FetchedAt.Unix())
+	return err
+}
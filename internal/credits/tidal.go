@@ -0,0 +1,130 @@
+package credits
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TidalAPI is Tidal's v1 API.
+const TidalAPI = "https://api.tidal.com/v1"
+
+// tidalPageSize is how many album items Tidal returns per request.
+const tidalPageSize = 100
+
+// Tidal fetches credits from the contributors of Tidal's tracks. Its
+// requests are authorized by a Tidal client ID alone (the X-Tidal-Token
+// header), no user login.
+type Tidal struct {
+	Base        string // TidalAPI when empty
+	Token       string // a Tidal client ID
+	CountryCode string // "US" when empty
+	Client      *http.Client
+}
+
+// tidalCredit is a role and its contributors, as Tidal lists them.
+type tidalCredit struct {
+	Type         string `json:"type"`
+	Contributors []struct {
+		Name string `json:"name"`
+	} `json:"contributors"`
+}
+
+// Album returns the credits of Tidal album id.
+func (t *Tidal) Album(id string) (*Album, error) {
+	album := &Album{Source: "tidal", ID: id, FetchedAt: time.Now().UTC()}
+	for offset := 0; ; offset += tidalPageSize {
+		var page struct {
+			Items []struct {
+				Item struct {
+					ID           int64  `json:"id"`
+					Title        string `json:"title"`
+					TrackNumber  int    `json:"trackNumber"`
+					VolumeNumber int    `json:"volumeNumber"`
+					Artist       struct {
+						Name string `json:"name"`
+					} `json:"artist"`
+					Album struct {
+						Title string `json:"title"`
+					} `json:"album"`
+				} `json:"item"`
+				Type    string        `json:"type"`
+				Credits []tidalCredit `json:"credits"`
+			} `json:"items"`
+			Total int `json:"totalNumberOfItems"`
+		}
+		params := url.Values{"limit": {strconv.Itoa(tidalPageSize)}, "offset": {strconv.Itoa(offset)}, "includeContributors": {"true"}}
+		if err := t.get("albums/"+url.PathEscape(id)+"/items/credits", params, &page); err != nil {
+			return nil, err
+		}
+		for _, it := range page.Items {
+			if album.Title == "" {
+				album.Title, album.Artist = it.Item.Album.Title, it.Item.Artist.Name
+			}
+			if it.Type != "" && it.Type != "track" {
+				continue // videos
+			}
+			track := Track{
+				ID:          strconv.FormatInt(it.Item.ID, 10),
+				Title:       it.Item.Title,
+				TrackNumber: it.Item.TrackNumber,
+				DiscNumber:  it.Item.VolumeNumber,
+				Credits:     []Credit{},
+			}
+			for _, c := range it.Credits {
+				for _, p := range c.Contributors {
+					track.Credits = append(track.Credits, Credit{Name: p.Name, Role: c.Type})
+				}
+			}
+			album.Tracks = append(album.Tracks, track)
+		}
+		if len(page.Items) < tidalPageSize || offset+len(page.Items) >= page.Total {
+			break
+		}
+	}
+	album.Roles = Summarize(album.Tracks)
+	return album, nil
+}
+
+// Track returns the credits of Tidal track id.
+func (t *Tidal) Track(id string) ([]Credit, error) {
+	var resp struct {
+		Items []struct {
+			Name string `json:"name"`
+			Role string `json:"role"`
+		} `json:"items"`
+	}
+	if err := t.get("tracks/"+url.PathEscape(id)+"/contributors", url.Values{"limit": {strconv.Itoa(tidalPageSize)}}, &resp); err != nil {
+		return nil, err
+	}
+	credits := []Credit{}
+	for _, c := range resp.Items {
+		credits = append(credits, Credit{Name: c.Name, Role: c.Role})
+	}
+	return credits, nil
+}
+
+// get calls a Tidal API path with params into v.
+func (t *Tidal) get(path string, params url.Values, v any) error {
+	if t.Token == "" {
+		return errors.New("Tidal credits need a Tidal client ID in Settings")
+	}
+	base := t.Base
+	if base == "" {
+		base = TidalAPI
+	}
+	country := t.CountryCode
+	if country == "" {
+		country = "US"
+	}
+	params.Set("countryCode", country)
+	header := http.Header{"X-Tidal-Token": {t.Token}}
+	if err := getJSON(t.Client, fmt.Sprintf("%s/%s?%s", strings.TrimRight(base, "/"), path, params.Encode()), header, v); err != nil {
+		return fmt.Errorf("tidal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package doctor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"flacidal/internal/flacmeta"
+)
+
+// DefaultClient is the HTTP client Reachable uses when given nil.
+var DefaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// dialTimeout bounds Dial's connection attempt.
+const dialTimeout = 5 * time.Second
+
+// Writable checks that dir exists and a file can be created in it.
+func Writable(dir string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("no folder set")
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return "", fmt.Errorf("%s is not a folder", dir)
+	}
+	f, err := os.CreateTemp(dir, ".flacidal-doctor-*")
+	if err != nil {
+		return "", err
+	}
+	_, werr := f.WriteString("ok")
+	cerr := f.Close()
+	os.Remove(f.Name())
+	if werr != nil {
+		return "", werr
+	}
+	if cerr != nil {
+		return "", cerr
+	}
+	return dir, nil
+}
+
+// Databases runs SQLite's quick_check on every database (*.db) in dir
+// through the database/sql driver named driver, which the program must have
+// registered.
+func Databases(ctx context.Context, driver, dir string) (string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.db"))
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", Skipped("no databases in %s", dir)
+	}
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+		if err := quickCheck(ctx, driver, path); err != nil {
+			return "", fmt.Errorf("%s: %w", names[i], err)
+		}
+	}
+	return strings.Join(names, ", ") + " intact", nil
+}
+
+// quickCheck runs quick_check on the database at path.
+func quickCheck(ctx context.Context, driver, path string) error {
+	db, err := sql.Open(driver, "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	rows, err := db.QueryContext(ctx, "PRAGMA quick_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("corrupt: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Reachable requests each of urls through client (DefaultClient when nil),
+// all at once. Any HTTP response counts as reachable. Some unreachable is a
+// warning, all a failure.
+func Reachable(ctx context.Context, client *http.Client, urls []string) (string, error) {
+	if len(urls) == 0 {
+		return "", fmt.Errorf("no endpoints configured")
+	}
+	if client == nil {
+		client = DefaultClient
+	}
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = reach(ctx, client, u)
+		}()
+	}
+	wg.Wait()
+	var down []string
+	for i, err := range errs {
+		if err != nil {
+			down = append(down, fmt.Sprintf("%s (%v)", host(urls[i]), err))
+		}
+	}
+	switch {
+	case len(down) == 0:
+		return fmt.Sprintf("%d of %d reachable", len(urls), len(urls)), nil
+	case len(down) == len(urls):
+		return "", fmt.Errorf("none of %d reachable: %s", len(urls), strings.Join(down, ", "))
+	default:
+		return "", Warning("%d of %d reachable; unreachable: %s", len(urls)-len(down), len(urls), strings.Join(down, ", "))
+	}
+}
+
+// reach requests rawURL, discarding the response.
+func reach(ctx context.Context, client *http.Client, rawURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return uerr.Err // without the method and URL, which the report names
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// host is rawURL's host, or rawURL when it has none.
+func host(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
+
+// Dial checks that the host of the proxy at rawURL accepts connections.
+func Dial(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		port := map[string]string{"http": "80", "https": "443", "socks5": "1080", "socks5h": "1080"}[u.Scheme]
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	start := time.Now()
+	d := net.Dialer{Timeout: dialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	conn.Close()
+	return fmt.Sprintf("%s answered in %dms", addr, time.Since(start).Milliseconds()), nil
+}
+
+// roundTripTags are the tags TagRoundTrip writes, with non-ASCII and
+// multi-valued ones, which tag writers most often get wrong.
+var roundTripTags = map[string][]string{
+	"TITLE":  {"Doctor ✓ テスト"},
+	"ARTIST": {"Artist One", "Artist Two"},
+	"ALBUM":  {"Self-check"},
+}
+
+// TagRoundTrip writes tags to a minimal FLAC file in a temporary folder in
+// dir, reads them back and compares.
+func TagRoundTrip(dir string) (string, error) {
+	tmp, err := os.MkdirTemp(dir, "flacidal-doctor-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	path := filepath.Join(tmp, "roundtrip.flac")
+	if err := os.WriteFile(path, minimalFLAC(), 0644); err != nil {
+		return "", err
+	}
+	err = flacmeta.UpdateComments(path, func(c *flacmeta.Comments) {
+		for name, values := range roundTripTags {
+			c.Set(name, values...)
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("writing tags: %w", err)
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return "", fmt.Errorf("reading back: %w", err)
+	}
+	c, err := f.Comments()
+	if err != nil {
+		return "", fmt.Errorf("reading back: %w", err)
+	}
+	for name, want := range roundTripTags {
+		if got := c.GetAll(name); !slices.Equal(got, want) {
+			return "", fmt.Errorf("%s read back as %q, want %q", name, got, want)
+		}
+	}
+	return fmt.Sprintf("%d tags written and read back", len(roundTripTags)), nil
+}
+
+// minimalFLAC returns a FLAC file of a lone STREAMINFO block (44.1 kHz,
+// 16-bit stereo, no audio).
+func minimalFLAC() []byte {
+	info := make([]byte, 34)
+	binary.BigEndian.PutUint16(info[0:], 4096)
+	binary.BigEndian.PutUint16(info[2:], 4096)
+	binary.BigEndian.PutUint64(info[10:], uint64(44100)<<44|uint64(1)<<41|uint64(15)<<36)
+	return append([]byte("fLaC\x80\x00\x00\x22"), info...)
+}
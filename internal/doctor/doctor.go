@@ -0,0 +1,148 @@
+// Package doctor runs FLACidal's self-check, the troubleshooting report of
+// `flacidal-server doctor` and the Settings Status tab: a list of checks,
+// each passing, failing, warning or skipped with a one-line detail. The
+// checks that need only the standard library and flacmeta live here; the
+// app assembles them with those reaching flacidal-core (see app.Doctor).
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Status is a check's outcome.
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn" // works, but something is off
+	Fail Status = "fail"
+	Skip Status = "skip" // not applicable, or disabled
+)
+
+// Check is one self-check. Run returns the detail reported when it passes,
+// or an error: one from Warning or Skipped for those outcomes, any other a
+// failure.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// Result is a check's outcome.
+type Result struct {
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Detail   string `json:"detail"`
+	Duration int64  `json:"durationMs"`
+}
+
+// Report is the outcome of every check, in order.
+type Report struct {
+	Results   []Result  `json:"results"`
+	OK        bool      `json:"ok"` // no check failed
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// outcome is the error Warning and Skipped return.
+type outcome struct {
+	status Status
+	msg    string
+}
+
+func (o *outcome) Error() string { return o.msg }
+
+// Warning returns the error a Check's Run reports a warning with.
+func Warning(format string, args ...any) error {
+	return &outcome{Warn, fmt.Sprintf(format, args...)}
+}
+
+// Skipped returns the error a Check's Run reports being skipped with.
+func Skipped(format string, args ...any) error {
+	return &outcome{Skip, fmt.Sprintf(format, args...)}
+}
+
+// Run runs checks one after the other. Once ctx is done, the checks left
+// are skipped; a check that panics fails.
+func Run(ctx context.Context, checks []Check) Report {
+	r := Report{Results: make([]Result, 0, len(checks)), OK: true, StartedAt: time.Now().UTC()}
+	for _, c := range checks {
+		res := Result{Name: c.Name}
+		start := time.Now()
+		if ctx.Err() != nil {
+			res.Status, res.Detail = Skip, "cancelled"
+		} else {
+			res.Status, res.Detail = run(ctx, c)
+		}
+		res.Duration = time.Since(start).Milliseconds()
+		if res.Status == Fail {
+			r.OK = false
+		}
+		r.Results = append(r.Results, res)
+	}
+	return r
+}
+
+// run runs c, mapping its outcome to a status.
+func run(ctx context.Context, c Check) (status Status, detail string) {
+	defer func() {
+		if p := recover(); p != nil {
+			status, detail = Fail, fmt.Sprintf("panic: %v", p)
+		}
+	}()
+	detail, err := c.Run(ctx)
+	var o *outcome
+	switch {
+	case err == nil:
+		return Pass, detail
+	case errors.As(err, &o):
+		return o.status, o.msg
+	default:
+		return Fail, err.Error()
+	}
+}
+
+// Counts returns how many checks ended with each status.
+func (r Report) Counts() map[Status]int {
+	counts := map[Status]int{}
+	for _, res := range r.Results {
+		counts[res.Status]++
+	}
+	return counts
+}
+
+// WriteText writes r as a plain-text table, one check per line, followed by
+// a summary line.
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%dms\n", label(res.Status), res.Name, res.Detail, res.Duration)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	n := r.Counts()
+	verdict := "all checks passed"
+	if !r.OK {
+		verdict = "some checks failed"
+	}
+	_, err := fmt.Fprintf(w, "\n%s: %d passed, %d warnings, %d failed, %d skipped\n", verdict, n[Pass], n[Warn], n[Fail], n[Skip])
+	return err
+}
+
+// label is s as WriteText shows it.
+func label(s Status) string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Warn:
+		return "WARN"
+	case Fail:
+		return "FAIL"
+	default:
+		return "SKIP"
+	}
+}
@@ -0,0 +1,113 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	checks := []Check{
+		{"ok", func(context.Context) (string, error) { return "fine", nil }},
+		{"warn", func(context.Context) (string, error) { return "", Warning("%d slow", 2) }},
+		{"skip", func(context.Context) (string, error) { return "", Skipped("disabled") }},
+		{"fail", func(context.Context) (string, error) { return "", errors.New("broken") }},
+		{"panic", func(context.Context) (string, error) { panic("boom") }},
+	}
+	r := Run(context.Background(), checks)
+	want := []Result{
+		{Name: "ok", Status: Pass, Detail: "fine"},
+		{Name: "warn", Status: Warn, Detail: "2 slow"},
+		{Name: "skip", Status: Skip, Detail: "disabled"},
+		{Name: "fail", Status: Fail, Detail: "broken"},
+		{Name: "panic", Status: Fail, Detail: "panic: boom"},
+	}
+	if r.OK {
+		t.Error("OK = true with failed checks")
+	}
+	for i, res := range r.Results {
+		res.Duration = 0
+		if res != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, res, want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "FAIL  fail") || !strings.Contains(out, "1 passed, 1 warnings, 2 failed, 1 skipped") {
+		t.Errorf("WriteText =\n%s", out)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := false
+	r := Run(ctx, []Check{
+		{"first", func(context.Context) (string, error) { cancel(); return "", nil }},
+		{"second", func(context.Context) (string, error) { ran = true; return "", nil }},
+	})
+	if ran || r.Results[1].Status != Skip || !r.OK {
+		t.Errorf("ran = %v, results = %+v", ran, r.Results)
+	}
+}
+
+func TestWritable(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Writable(dir); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("left %d files behind", len(entries))
+	}
+	if _, err := Writable(filepath.Join(dir, "missing")); err == nil {
+		t.Error("missing folder passed")
+	}
+}
+
+func TestDatabasesNone(t *testing.T) {
+	_, err := Databases(context.Background(), "sqlite3", t.TempDir())
+	r := Run(context.Background(), []Check{{"db", func(context.Context) (string, error) { return "", err }}})
+	if r.Results[0].Status != Skip {
+		t.Errorf("no databases gave %+v", r.Results[0])
+	}
+}
+
+func TestReachable(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound) // any answer counts
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	ctx := context.Background()
+	if _, err := Reachable(ctx, nil, []string{up.URL}); err != nil {
+		t.Errorf("reachable endpoint: %v", err)
+	}
+	_, err := Reachable(ctx, nil, []string{up.URL, down.URL})
+	var o *outcome
+	if !errors.As(err, &o) || o.status != Warn || !strings.Contains(o.msg, "1 of 2 reachable") {
+		t.Errorf("half reachable: %v", err)
+	}
+	if _, err := Reachable(ctx, nil, []string{down.URL}); err == nil || errors.As(err, &o) {
+		t.Errorf("none reachable: %v", err)
+	}
+}
+
+func TestTagRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := TagRoundTrip(dir); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("left %d files behind", len(entries))
+	}
+}
@@ -0,0 +1,41 @@
+package downloads
+
+import "sync"
+
+// Finisher runs the post-download work of finished downloads off the
+// download manager's workers, one at a time and in the order the downloads
+// finished, so a slow step (a full decode check, a MusicBrainz lookup)
+// doesn't hold up the next download. The zero value is ready to use and
+// safe for concurrent use.
+type Finisher struct {
+	mu      sync.Mutex
+	pending []func()
+	running bool
+}
+
+// Go queues fn behind the work already queued and returns at once.
+func (f *Finisher) Go(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending = append(f.pending, fn)
+	if !f.running {
+		f.running = true
+		go f.drain()
+	}
+}
+
+// drain runs the queued work until none is left.
+func (f *Finisher) drain() {
+	for {
+		f.mu.Lock()
+		if len(f.pending) == 0 {
+			f.running = false
+			f.mu.Unlock()
+			return
+		}
+		fn := f.pending[0]
+		f.pending = f.pending[1:]
+		f.mu.Unlock()
+		fn()
+	}
+}
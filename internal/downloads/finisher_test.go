@@ -0,0 +1,34 @@
+package downloads
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFinisher_RunsInOrderWithoutBlocking(t *testing.T) {
+	var f Finisher
+	release := make(chan struct{})
+	done := make(chan int, 3)
+	f.Go(func() {
+		<-release
+		done <- 1
+	})
+	// The first job is still running, so these only queue.
+	f.Go(func() { done <- 2 })
+	f.Go(func() { done <- 3 })
+	close(release)
+
+	var got []int
+	for range 3 {
+		got = append(got, <-done)
+	}
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("ran %v, want in queued order", got)
+	}
+
+	// An idle Finisher starts again.
+	f.Go(func() { done <- 4 })
+	if n := <-done; n != 4 {
+		t.Errorf("ran %d", n)
+	}
+}
@@ -0,0 +1,114 @@
+package downloads
+
+import "sync"
+
+// Gate caps how many downloads run at once below the download manager's
+// pool size. flacidal-core's DownloadManager can't resize its worker pool,
+// so the pool is started at its largest and each worker waits at the Gate
+// before downloading; changing the limit takes effect on the next download
+// to start, without a restart. The zero value lets every download through
+// and is safe for concurrent use.
+type Gate struct {
+	mu      sync.Mutex
+	limit   int
+	held    map[int]bool
+	waiting map[int]chan struct{} // closed when the waiting download ended
+	wake    chan struct{}         // closed when a place may have freed up
+	closed  bool
+}
+
+// SetLimit lets n downloads run at once; n <= 0 lifts the limit. Lowering
+// it doesn't stop running downloads, it only holds new ones until enough
+// of them finished.
+func (g *Gate) SetLimit(n int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.limit = n
+	g.wakeAll()
+}
+
+// Enter blocks until the download of trackID may run, reporting true, or
+// until its job is cancelled or removed while waiting (see Leave) or the
+// gate is closed, reporting false. A track already let through (a retry
+// within the same attempt) passes at once.
+func (g *Gate) Enter(trackID int) bool {
+	for {
+		g.mu.Lock()
+		if g.closed {
+			g.mu.Unlock()
+			return false
+		}
+		if g.held[trackID] || g.limit <= 0 || len(g.held) < g.limit {
+			if g.held == nil {
+				g.held = make(map[int]bool)
+			}
+			g.held[trackID] = true
+			delete(g.waiting, trackID)
+			g.mu.Unlock()
+			return true
+		}
+		if g.wake == nil {
+			g.wake = make(chan struct{})
+		}
+		if g.waiting == nil {
+			g.waiting = make(map[int]chan struct{})
+		}
+		ended, ok := g.waiting[trackID]
+		if !ok {
+			ended = make(chan struct{})
+			g.waiting[trackID] = ended
+		}
+		wake := g.wake
+		g.mu.Unlock()
+		select {
+		case <-wake:
+		case <-ended:
+			return false
+		}
+	}
+}
+
+// Leave frees the place of trackID's download, which ended. A download
+// still waiting for a place, whose job was cancelled or removed, stops
+// waiting: its Enter returns false.
+func (g *Gate) Leave(trackID int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if ended, ok := g.waiting[trackID]; ok {
+		close(ended)
+		delete(g.waiting, trackID)
+	}
+	if g.held[trackID] {
+		delete(g.held, trackID)
+		g.wakeAll()
+	}
+}
+
+// Close lets every waiting download go, reporting false from Enter, and
+// turns away later ones. Call it before stopping the download manager,
+// whose workers may be waiting at the gate.
+func (g *Gate) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closed = true
+	for id, ended := range g.waiting {
+		close(ended)
+		delete(g.waiting, id)
+	}
+	g.wakeAll()
+}
+
+// Running returns how many downloads hold a place.
+func (g *Gate) Running() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.held)
+}
+
+// wakeAll wakes the waiting downloads to try again; g.mu must be held.
+func (g *Gate) wakeAll() {
+	if g.wake != nil {
+		close(g.wake)
+		g.wake = nil
+	}
+}
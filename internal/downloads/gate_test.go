@@ -0,0 +1,119 @@
+package downloads
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGate_LimitAppliesLive(t *testing.T) {
+	var g Gate
+	g.SetLimit(1)
+	g.Enter(1)
+	g.Enter(1) // a retry of a running download isn't held
+
+	entered := make(chan int, 2)
+	go func() { g.Enter(2); entered <- 2 }()
+	go func() { g.Enter(3); entered <- 3 }()
+	select {
+	case n := <-entered:
+		t.Fatalf("track %d entered past the limit", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Raising the limit lets one more through; the other waits for a place.
+	g.SetLimit(2)
+	first := <-entered
+	select {
+	case n := <-entered:
+		t.Fatalf("track %d entered past the raised limit", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+	g.Leave(1)
+	if second := <-entered; second == first {
+		t.Errorf("track %d entered twice", second)
+	}
+	if n := g.Running(); n != 2 {
+		t.Errorf("Running() = %d, want 2", n)
+	}
+
+	// Lowering the limit holds new downloads until enough finished.
+	g.SetLimit(1)
+	go func() { g.Enter(4); entered <- 4 }()
+	g.Leave(2)
+	select {
+	case n := <-entered:
+		t.Fatalf("track %d entered with %d running", n, g.Running())
+	case <-time.After(50 * time.Millisecond):
+	}
+	g.Leave(3)
+	if n := <-entered; n != 4 {
+		t.Errorf("entered %d, want 4", n)
+	}
+}
+
+func TestGate_ZeroValueIsUnlimited(t *testing.T) {
+	var g Gate
+	for id := range 20 {
+		g.Enter(id)
+	}
+	g.Leave(99) // never entered
+	if n := g.Running(); n != 20 {
+		t.Errorf("Running() = %d, want 20", n)
+	}
+}
+
+func TestGate_CancelledWhileWaiting(t *testing.T) {
+	var g Gate
+	g.SetLimit(1)
+	g.Enter(1)
+
+	entered := make(chan bool)
+	go func() { entered <- g.Enter(2) }()
+	select {
+	case <-entered:
+		t.Fatal("track 2 entered past the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The job is cancelled: its worker is let go without a place.
+	g.Leave(2)
+	select {
+	case ok := <-entered:
+		if ok {
+			t.Error("cancelled track 2 entered")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled track 2 still waiting")
+	}
+	if n := g.Running(); n != 1 {
+		t.Errorf("Running() = %d, want 1", n)
+	}
+	// A retry waits again, and enters once there is a place.
+	go func() { entered <- g.Enter(2) }()
+	g.Leave(1)
+	if ok := <-entered; !ok {
+		t.Error("retried track 2 didn't enter")
+	}
+}
+
+func TestGate_Close(t *testing.T) {
+	var g Gate
+	g.SetLimit(1)
+	g.Enter(1)
+	entered := make(chan bool)
+	go func() { entered <- g.Enter(2) }()
+	time.Sleep(20 * time.Millisecond)
+
+	g.Close()
+	select {
+	case ok := <-entered:
+		if ok {
+			t.Error("track 2 entered a closed gate")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close left track 2 waiting")
+	}
+	if g.Enter(3) {
+		t.Error("track 3 entered a closed gate")
+	}
+}
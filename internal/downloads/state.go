@@ -0,0 +1,62 @@
+// Package downloads models the lifecycle of download jobs. flacidal-core's
+// DownloadManager reports progress as loose status strings; the Tracker here
+// turns that stream into per-job state machines, recording every transition
+// with its time so queue and history views can show how long a job waited
+// and how long it took.
+package downloads
+
+import (
+	"errors"
+	"fmt"
+)
+
+// State is a job's lifecycle state. The values match the status strings the
+// core download manager emits.
+type State string
+
+const (
+	Queued      State = "queued"
+	Downloading State = "downloading"
+	Completed   State = "completed"
+	Failed      State = "error"
+	Cancelled   State = "cancelled"
+)
+
+// ErrInvalidTransition is returned (wrapped) for a transition the state
+// machine doesn't allow.
+var ErrInvalidTransition = errors.New("invalid state transition")
+
+// transitions lists the allowed next states for each state.
+var transitions = map[State][]State{
+	// queued → completed happens when core skips a file that already exists.
+	Queued:      {Downloading, Completed, Failed, Cancelled},
+	Downloading: {Completed, Failed, Cancelled},
+	// Finished jobs can be retried or re-queued.
+	Completed: {Queued},
+	Failed:    {Queued},
+	Cancelled: {Queued},
+}
+
+// ParseState converts a core status string to a State.
+func ParseState(status string) (State, error) {
+	s := State(status)
+	if _, ok := transitions[s]; !ok {
+		return "", fmt.Errorf("unknown download state %q", status)
+	}
+	return s, nil
+}
+
+// CanTransition reports whether a job may move from one state to another.
+func CanTransition(from, to State) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminal reports whether s ends an attempt.
+func (s State) Terminal() bool {
+	return s == Completed || s == Failed || s == Cancelled
+}
@@ -0,0 +1,114 @@
+package downloads
+
+import (
+	"sync"
+	"time"
+)
+
+// ThroughputWindow is how far back finished downloads count towards the
+// aggregate speed.
+const ThroughputWindow = 2 * time.Minute
+
+// Rate is the download speed and the estimated time to finish the queue.
+// Zero values mean unknown: nothing finished within ThroughputWindow yet.
+type Rate struct {
+	BytesPerSec int64 `json:"bytesPerSec"`
+	ETASeconds  int64 `json:"etaSeconds"`
+}
+
+// Throughput keeps a rolling record of finished downloads to report speeds.
+// flacidal-core reports no byte-level progress, so each track counts once,
+// when it completes: its file size over its download time. The zero value
+// is ready to use and safe for concurrent use.
+type Throughput struct {
+	mu      sync.Mutex
+	samples []sample // oldest first, at most MaxFinished
+
+	now func() time.Time // for tests; time.Now when nil
+}
+
+// sample is one finished download.
+type sample struct {
+	id    int
+	bytes int64
+	took  time.Duration
+	at    time.Time
+}
+
+// Add records that job id finished bytes in took and returns its speed in
+// bytes per second (0 when took isn't positive).
+func (t *Throughput) Add(id int, bytes int64, took time.Duration) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples = append(t.samples, sample{id: id, bytes: bytes, took: took, at: t.clock()})
+	if n := len(t.samples) - MaxFinished; n > 0 {
+		t.samples = append(t.samples[:0:0], t.samples[n:]...)
+	}
+	return perSecond(bytes, took)
+}
+
+// Track returns the recorded speed of job id's latest download.
+func (t *Throughput) Track(id int) (int64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := len(t.samples) - 1; i >= 0; i-- {
+		if s := t.samples[i]; s.id == id {
+			return perSecond(s.bytes, s.took), true
+		}
+	}
+	return 0, false
+}
+
+// Rate returns the aggregate speed over ThroughputWindow — bytes finished
+// over the time since the earliest of those downloads started, which
+// accounts for parallel workers — and the ETA for remaining more tracks
+// at the window's average track size.
+func (t *Throughput) Rate(remaining int) Rate {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock()
+	var bytes int64
+	var start time.Time
+	tracks := 0
+	for i := len(t.samples) - 1; i >= 0; i-- {
+		s := t.samples[i]
+		if now.Sub(s.at) > ThroughputWindow {
+			break
+		}
+		bytes += s.bytes
+		tracks++
+		if began := s.at.Add(-s.took); start.IsZero() || began.Before(start) {
+			start = began
+		}
+	}
+	if tracks == 0 {
+		return Rate{}
+	}
+	rate := Rate{BytesPerSec: perSecond(bytes, now.Sub(start))}
+	if rate.BytesPerSec > 0 && remaining > 0 {
+		rate.ETASeconds = int64(remaining) * (bytes / int64(tracks)) / rate.BytesPerSec
+	}
+	return rate
+}
+
+// Reset forgets every sample.
+func (t *Throughput) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples = nil
+}
+
+func (t *Throughput) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// perSecond is bytes over d, 0 for non-positive durations.
+func perSecond(bytes int64, d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64(float64(bytes) / d.Seconds())
+}
@@ -0,0 +1,81 @@
+package downloads
+
+import (
+	"testing"
+	"time"
+)
+
+const mb = 1 << 20
+
+// manualClock returns a Throughput and a func that moves its clock forward.
+func manualClock() (*Throughput, func(time.Duration)) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tp := &Throughput{now: func() time.Time { return at }}
+	return tp, func(d time.Duration) { at = at.Add(d) }
+}
+
+func TestThroughput_Track(t *testing.T) {
+	var tp Throughput
+	if got := tp.Add(1, 40*mb, 10*time.Second); got != 4*mb {
+		t.Errorf("Add = %d, want %d", got, 4*mb)
+	}
+	if got, ok := tp.Track(1); !ok || got != 4*mb {
+		t.Errorf("Track(1) = %d, %v; want %d, true", got, ok, 4*mb)
+	}
+	if _, ok := tp.Track(2); ok {
+		t.Error("Track(2) found an unrecorded job")
+	}
+	if got := tp.Add(3, 10*mb, 0); got != 0 {
+		t.Errorf("Add with no duration = %d, want 0", got)
+	}
+}
+
+func TestThroughput_RateCountsParallelDownloads(t *testing.T) {
+	tp, advance := manualClock()
+	// Two workers each fetch 40 MB over the same 10s: 8 MB/s together.
+	advance(10 * time.Second)
+	tp.Add(1, 40*mb, 10*time.Second)
+	tp.Add(2, 40*mb, 10*time.Second)
+
+	rate := tp.Rate(6)
+	if rate.BytesPerSec != 8*mb {
+		t.Errorf("BytesPerSec = %d, want %d", rate.BytesPerSec, 8*mb)
+	}
+	// 6 more tracks at 40 MB each = 240 MB at 8 MB/s.
+	if rate.ETASeconds != 30 {
+		t.Errorf("ETASeconds = %d, want 30", rate.ETASeconds)
+	}
+	if got := tp.Rate(0).ETASeconds; got != 0 {
+		t.Errorf("ETASeconds with nothing left = %d, want 0", got)
+	}
+}
+
+func TestThroughput_RateWindow(t *testing.T) {
+	tp, advance := manualClock()
+	if rate := tp.Rate(3); rate != (Rate{}) {
+		t.Errorf("Rate with no samples = %+v, want zero", rate)
+	}
+	tp.Add(1, 100*mb, time.Second)
+	advance(ThroughputWindow + time.Second)
+	if rate := tp.Rate(3); rate != (Rate{}) {
+		t.Errorf("Rate after the window = %+v, want zero", rate)
+	}
+	tp.Add(2, 20*mb, 10*time.Second)
+	if rate := tp.Rate(1); rate.BytesPerSec != 2*mb {
+		t.Errorf("BytesPerSec = %d, want only the recent %d", rate.BytesPerSec, 2*mb)
+	}
+}
+
+func TestThroughput_BoundedAndReset(t *testing.T) {
+	var tp Throughput
+	for id := 0; id <= MaxFinished; id++ {
+		tp.Add(id, mb, time.Second)
+	}
+	if _, ok := tp.Track(0); ok {
+		t.Error("oldest sample kept beyond MaxFinished")
+	}
+	tp.Reset()
+	if _, ok := tp.Track(MaxFinished); ok {
+		t.Error("sample kept after Reset")
+	}
+}
@@ -0,0 +1,180 @@
+package downloads
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MaxFinished is how many finished jobs a Tracker keeps for history views
+// before dropping the oldest.
+const MaxFinished = 500
+
+// Transition is one recorded state change.
+type Transition struct {
+	State State     `json:"state"`
+	At    time.Time `json:"at"`
+}
+
+// Job is a snapshot of one job: its current state, every transition so far
+// and timings derived from them. Timings cover the latest attempt and run up
+// to now while that attempt is still in progress.
+type Job struct {
+	ID             int          `json:"id"`
+	State          State        `json:"state"`
+	Attempts       int          `json:"attempts"`
+	Transitions    []Transition `json:"transitions"`
+	QueueTimeMs    int64        `json:"queueTimeMs"`    // queued → downloading
+	DownloadTimeMs int64        `json:"downloadTimeMs"` // downloading → finished
+}
+
+// Tracker records job transitions as reported by the download manager.
+// The zero value is ready to use and safe for concurrent use.
+type Tracker struct {
+	mu       sync.Mutex
+	jobs     map[int][]Transition
+	finished []int // finish order, for evicting old jobs
+
+	now func() time.Time // for tests; time.Now when nil
+}
+
+// Record applies status to job id and returns the updated snapshot. A job
+// seen for the first time starts in whatever state it is reported in, since
+// a Tracker may be attached to a manager that is already running. Repeating
+// the current state (progress updates) changes nothing. A disallowed
+// transition leaves the job as it was and returns an error wrapping
+// ErrInvalidTransition.
+func (t *Tracker) Record(id int, status string) (Job, error) {
+	to, err := ParseState(status)
+	if err != nil {
+		return Job{}, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.jobs == nil {
+		t.jobs = make(map[int][]Transition)
+	}
+	now := t.clock()
+	history := t.jobs[id]
+	if n := len(history); n > 0 {
+		from := history[n-1].State
+		if from == to {
+			return snapshot(id, history, now), nil
+		}
+		if !CanTransition(from, to) {
+			return snapshot(id, history, now), fmt.Errorf("job %d: %s → %s: %w", id, from, to, ErrInvalidTransition)
+		}
+	}
+	history = append(history, Transition{State: to, At: now})
+	t.jobs[id] = history
+	if to.Terminal() {
+		t.finish(id)
+	}
+	return snapshot(id, history, now), nil
+}
+
+// Job returns the snapshot for id.
+func (t *Tracker) Job(id int) (Job, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	history, ok := t.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return snapshot(id, history, t.clock()), true
+}
+
+// Jobs returns snapshots of every tracked job, ordered by ID.
+func (t *Tracker) Jobs() []Job {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock()
+	out := make([]Job, 0, len(t.jobs))
+	for id, history := range t.jobs {
+		out = append(out, snapshot(id, history, now))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Forget drops job id.
+func (t *Tracker) Forget(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.jobs, id)
+}
+
+// finish notes that id reached a terminal state and evicts the oldest
+// finished jobs beyond MaxFinished. Jobs re-queued since they finished are
+// skipped. Callers hold t.mu.
+func (t *Tracker) finish(id int) {
+	for i, f := range t.finished {
+		if f == id { // finished before, then retried
+			t.finished = append(t.finished[:i], t.finished[i+1:]...)
+			break
+		}
+	}
+	t.finished = append(t.finished, id)
+	for len(t.finished) > MaxFinished {
+		old := t.finished[0]
+		t.finished = t.finished[1:]
+		if h := t.jobs[old]; len(h) > 0 && h[len(h)-1].State.Terminal() {
+			delete(t.jobs, old)
+		}
+	}
+}
+
+func (t *Tracker) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now().UTC()
+}
+
+// snapshot builds a Job from its transitions, deriving the timings of the
+// latest attempt (everything since the last time it was queued).
+func snapshot(id int, history []Transition, now time.Time) Job {
+	job := Job{
+		ID:          id,
+		State:       history[len(history)-1].State,
+		Transitions: append([]Transition(nil), history...),
+	}
+	start := 0
+	for i, tr := range history {
+		if tr.State == Queued {
+			job.Attempts++
+			start = i
+		}
+	}
+	if job.Attempts == 0 {
+		job.Attempts = 1 // first seen mid-flight
+	}
+
+	var queuedAt, startedAt, endedAt time.Time
+	for _, tr := range history[start:] {
+		switch {
+		case tr.State == Queued:
+			queuedAt = tr.At
+		case tr.State == Downloading:
+			startedAt = tr.At
+		case tr.State.Terminal():
+			endedAt = tr.At
+		}
+	}
+	if endedAt.IsZero() {
+		endedAt = now
+	}
+	if !queuedAt.IsZero() {
+		waited := endedAt
+		if !startedAt.IsZero() {
+			waited = startedAt
+		}
+		job.QueueTimeMs = waited.Sub(queuedAt).Milliseconds()
+	}
+	if !startedAt.IsZero() {
+		job.DownloadTimeMs = endedAt.Sub(startedAt).Milliseconds()
+	}
+	return job
+}
@@ -0,0 +1,104 @@
+package downloads
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a Tracker whose clock advances by step on every read.
+func fakeClock(step time.Duration) *Tracker {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &Tracker{now: func() time.Time {
+		at = at.Add(step)
+		return at
+	}}
+}
+
+func TestRecord_Lifecycle(t *testing.T) {
+	tr := fakeClock(time.Second)
+	for _, s := range []string{"queued", "downloading", "downloading", "completed"} {
+		if _, err := tr.Record(1, s); err != nil {
+			t.Fatalf("Record(%s): %v", s, err)
+		}
+	}
+	job, ok := tr.Job(1)
+	if !ok {
+		t.Fatal("job not tracked")
+	}
+	if job.State != Completed || len(job.Transitions) != 3 || job.Attempts != 1 {
+		t.Errorf("job = %+v", job)
+	}
+	// Clock reads: queued@1s, downloading@2s, repeat@3s (ignored), completed@4s.
+	if job.QueueTimeMs != 1000 || job.DownloadTimeMs != 2000 {
+		t.Errorf("timings = queue %dms, download %dms; want 1000, 2000", job.QueueTimeMs, job.DownloadTimeMs)
+	}
+}
+
+func TestRecord_RejectsInvalidTransition(t *testing.T) {
+	var tr Tracker
+	tr.Record(1, "queued")
+	tr.Record(1, "downloading")
+	tr.Record(1, "completed")
+
+	job, err := tr.Record(1, "downloading")
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("err = %v, want ErrInvalidTransition", err)
+	}
+	if job.State != Completed {
+		t.Errorf("state changed to %s on a rejected transition", job.State)
+	}
+	if _, err := tr.Record(1, "paused"); err == nil {
+		t.Error("unknown status should be rejected")
+	}
+}
+
+func TestRecord_RetryStartsNewAttempt(t *testing.T) {
+	tr := fakeClock(time.Second)
+	for _, s := range []string{"queued", "downloading", "error", "queued", "downloading"} {
+		if _, err := tr.Record(7, s); err != nil {
+			t.Fatalf("Record(%s): %v", s, err)
+		}
+	}
+	job, _ := tr.Job(7)
+	if job.Attempts != 2 || job.State != Downloading {
+		t.Errorf("job = %+v", job)
+	}
+	// Second attempt: queued@4s, downloading@5s, snapshot taken at 6s.
+	if job.QueueTimeMs != 1000 || job.DownloadTimeMs != 1000 {
+		t.Errorf("timings = queue %dms, download %dms; want the latest attempt only", job.QueueTimeMs, job.DownloadTimeMs)
+	}
+}
+
+func TestRecord_FirstSeenMidFlight(t *testing.T) {
+	var tr Tracker
+	job, err := tr.Record(3, "downloading")
+	if err != nil || job.State != Downloading || job.Attempts != 1 {
+		t.Errorf("Record = %+v, %v", job, err)
+	}
+}
+
+func TestTracker_EvictsOldFinishedJobs(t *testing.T) {
+	var tr Tracker
+	for id := 0; id <= MaxFinished; id++ {
+		tr.Record(id, "queued")
+		tr.Record(id, "cancelled")
+	}
+	tr.Record(MaxFinished+1, "queued") // still active, never evicted
+
+	if _, ok := tr.Job(0); ok {
+		t.Error("oldest finished job should have been evicted")
+	}
+	if got := len(tr.Jobs()); got != MaxFinished+1 {
+		t.Errorf("len(Jobs) = %d, want %d", got, MaxFinished+1)
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	if !CanTransition(Queued, Completed) {
+		t.Error("queued → completed (skipped existing file) should be allowed")
+	}
+	if CanTransition(Completed, Downloading) || CanTransition(Downloading, Queued) {
+		t.Error("unexpected transition allowed")
+	}
+}
@@ -0,0 +1,49 @@
+package downloads
+
+import "time"
+
+// Verbosity is how much of the download event stream is forwarded to the
+// UI. Queueing thousands of tracks at once produces an event per track,
+// which one per message is enough to freeze the desktop webview.
+type Verbosity string
+
+const (
+	Batched  Verbosity = ""         // each BatchWindow's events as one message, one per track
+	Every    Verbosity = "all"      // every event as its own message, as it happens
+	Finished Verbosity = "finished" // Batched, without queued and downloading events
+)
+
+// BatchWindow is how long events are gathered into one message.
+const BatchWindow = 250 * time.Millisecond
+
+// Valid reports whether v is a known verbosity.
+func (v Verbosity) Valid() bool {
+	return v == Batched || v == Every || v == Finished
+}
+
+// Coalesce reduces a batch of events to what v forwards. Every keeps them
+// all. Otherwise only each track's latest event is kept, in the order of
+// those latest events, and with Finished only if the track has finished.
+// track returns an event's track ID and status.
+func Coalesce[E any](events []E, v Verbosity, track func(E) (id int, status string)) []E {
+	if v == Every {
+		return events
+	}
+	last := make(map[int]int, len(events)) // track ID → index of its latest event
+	for i, e := range events {
+		id, _ := track(e)
+		last[id] = i
+	}
+	kept := make([]E, 0, len(last))
+	for i, e := range events {
+		id, status := track(e)
+		if last[id] != i {
+			continue
+		}
+		if v == Finished && (State(status) == Queued || State(status) == Downloading) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
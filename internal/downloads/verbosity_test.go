@@ -0,0 +1,32 @@
+package downloads
+
+import (
+	"slices"
+	"testing"
+)
+
+type event struct {
+	id     int
+	status string
+}
+
+func TestCoalesce(t *testing.T) {
+	events := []event{{1, "queued"}, {2, "queued"}, {1, "downloading"}, {3, "queued"}, {1, "completed"}, {2, "downloading"}}
+	track := func(e event) (int, string) { return e.id, e.status }
+
+	for _, tc := range []struct {
+		v    Verbosity
+		want []event
+	}{
+		{Every, events},
+		{Batched, []event{{3, "queued"}, {1, "completed"}, {2, "downloading"}}},
+		{Finished, []event{{1, "completed"}}},
+	} {
+		if got := Coalesce(events, tc.v, track); !slices.Equal(got, tc.want) {
+			t.Errorf("Coalesce(%q) = %v, want %v", tc.v, got, tc.want)
+		}
+	}
+	if Verbosity("loud").Valid() || !Finished.Valid() {
+		t.Error("Valid misjudged a verbosity")
+	}
+}
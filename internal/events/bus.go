@@ -0,0 +1,204 @@
+// Package events is a small in-process publish/subscribe bus. flacidal-core's
+// DownloadManager accepts a single progress callback; FLACidal installs one
+// that publishes to a Bus, so the desktop event emitter, the WebSocket hubs,
+// logging and later integrations can each listen without replacing the
+// others.
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Bus fans published events out to every subscriber. The zero value is ready
+// to use.
+//
+// Publish doesn't block on ordinary events: a subscriber whose buffer is
+// full misses the event (counted by Dropped) rather than stalling the
+// publisher, which for download events is a download worker. Events Keep
+// reports true for, such as a download finishing, are never dropped:
+// Publish waits for room instead, until the subscriber leaves. Every
+// subscriber drains its channel until it is closed (see Listen), so the
+// wait is bounded by the slowest listener, and Unsubscribe and Close
+// never wait on it.
+type Bus[E any] struct {
+	// Keep, if not nil, picks the events that must reach every
+	// subscriber. Set it before the first Publish.
+	Keep func(E) bool
+
+	mu      sync.RWMutex
+	subs    map[int]*subscriber[E]
+	nextID  int
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// subscriber is one Subscribe call's channel. Publish sends to it under
+// mu's read lock, so close, taking the write lock, can't close ch under a
+// send; closing done first lets a send waiting for room give up.
+type subscriber[E any] struct {
+	ch     chan E
+	done   chan struct{}
+	mu     sync.RWMutex
+	closed bool
+}
+
+// send delivers e to s, waiting for room if wait is set, and reports
+// whether it did.
+func (s *subscriber[E]) send(e E, wait bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return true // gone, not dropped
+	}
+	if wait {
+		select {
+		case s.ch <- e:
+		case <-s.done:
+		}
+		return true
+	}
+	select {
+	case s.ch <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *subscriber[E]) close() {
+	close(s.done)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+// Subscribe registers a subscriber with room for buffer pending events and
+// returns its id and receive channel. The channel is closed by Unsubscribe
+// or Close; subscribing to a closed bus returns an already closed channel.
+func (b *Bus[E]) Subscribe(buffer int) (id int, ch <-chan E) {
+	s := &subscriber[E]{ch: make(chan E, max(buffer, 0)), done: make(chan struct{})}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.ch)
+		return 0, s.ch
+	}
+	if b.subs == nil {
+		b.subs = make(map[int]*subscriber[E])
+	}
+	b.nextID++
+	b.subs[b.nextID] = s
+	return b.nextID, s.ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel. Unknown ids are
+// ignored.
+func (b *Bus[E]) Unsubscribe(id int) {
+	b.mu.Lock()
+	s, ok := b.subs[id]
+	delete(b.subs, id)
+	b.mu.Unlock()
+	if ok {
+		s.close()
+	}
+}
+
+// Publish delivers e to every subscriber with room for it, or to every
+// subscriber, waiting for room, if Keep reports true for it.
+func (b *Bus[E]) Publish(e E) {
+	b.mu.RLock()
+	subs := make([]*subscriber[E], 0, len(b.subs))
+	for _, s := range b.subs {
+		subs = append(subs, s)
+	}
+	b.mu.RUnlock()
+	keep := b.Keep != nil && b.Keep(e)
+	for _, s := range subs {
+		if !s.send(e, keep) {
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribers returns the number of current subscribers.
+func (b *Bus[E]) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// Dropped returns how many deliveries were skipped because a subscriber's
+// buffer was full.
+func (b *Bus[E]) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Close unsubscribes everyone; later Publish calls are no-ops.
+func (b *Bus[E]) Close() {
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = nil
+	b.closed = true
+	b.mu.Unlock()
+	for _, s := range subs {
+		s.close()
+	}
+}
+
+// Listen subscribes fn to b, calling it for each event, in order, on its own
+// goroutine. The returned stop function unsubscribes and waits for fn to
+// return from the event in progress.
+func Listen[E any](b *Bus[E], buffer int, fn func(E)) (stop func()) {
+	id, ch := b.Subscribe(buffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range ch {
+			fn(e)
+		}
+	}()
+	return func() {
+		b.Unsubscribe(id)
+		<-done
+	}
+}
+
+// ListenBatched is Listen for bursty streams: fn receives, in order, the
+// events that arrived within window of the first one, so a subscriber
+// that forwards events somewhere slow pays once per burst. A window of 0
+// passes each event alone.
+func ListenBatched[E any](b *Bus[E], buffer int, window time.Duration, fn func([]E)) (stop func()) {
+	id, ch := b.Subscribe(buffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range ch {
+			batch := []E{e}
+			if window > 0 {
+				timer := time.NewTimer(window)
+			collect:
+				for {
+					select {
+					case e, ok := <-ch:
+						if !ok {
+							timer.Stop()
+							fn(batch)
+							return
+						}
+						batch = append(batch, e)
+					case <-timer.C:
+						break collect
+					}
+				}
+			}
+			fn(batch)
+		}
+	}()
+	return func() {
+		b.Unsubscribe(id)
+		<-done
+	}
+}
@@ -0,0 +1,196 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBus_FansOut(t *testing.T) {
+	var b Bus[int]
+	_, a := b.Subscribe(4)
+	_, c := b.Subscribe(4)
+
+	b.Publish(1)
+	b.Publish(2)
+	for _, ch := range []<-chan int{a, c} {
+		if got := []int{<-ch, <-ch}; got[0] != 1 || got[1] != 2 {
+			t.Errorf("received %v, want [1 2]", got)
+		}
+	}
+}
+
+func TestBus_UnsubscribeClosesChannel(t *testing.T) {
+	var b Bus[string]
+	id, ch := b.Subscribe(1)
+	b.Unsubscribe(id)
+	b.Unsubscribe(id) // second call is a no-op
+	if _, ok := <-ch; ok {
+		t.Error("channel still open after Unsubscribe")
+	}
+	b.Publish("ignored") // must not panic on the closed channel
+	if b.Subscribers() != 0 {
+		t.Errorf("Subscribers() = %d, want 0", b.Subscribers())
+	}
+}
+
+func TestBus_FullSubscriberDoesNotBlock(t *testing.T) {
+	var b Bus[int]
+	_, slow := b.Subscribe(1)
+	_, fast := b.Subscribe(3)
+
+	for i := range 3 {
+		b.Publish(i)
+	}
+	if len(fast) != 3 || len(slow) != 1 {
+		t.Errorf("buffered fast=%d slow=%d, want 3 and 1", len(fast), len(slow))
+	}
+	if b.Dropped() != 2 {
+		t.Errorf("Dropped() = %d, want 2", b.Dropped())
+	}
+}
+
+func TestBus_KeepWaitsForRoom(t *testing.T) {
+	b := Bus[int]{Keep: func(i int) bool { return i < 0 }}
+	ch := make(chan []int)
+	stop := Listen(&b, 1, func(i int) {
+		time.Sleep(time.Millisecond)
+		if i < 0 {
+			ch <- nil
+		}
+	})
+	defer stop()
+	for i := range 20 {
+		b.Publish(i)
+	}
+	b.Publish(-1)
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("the kept event never arrived")
+	}
+	if b.Dropped() == 0 {
+		t.Error("nothing dropped; the test didn't fill the buffer")
+	}
+}
+
+func TestBus_Close(t *testing.T) {
+	var b Bus[int]
+	_, ch := b.Subscribe(1)
+	b.Close()
+	if _, ok := <-ch; ok {
+		t.Error("subscriber channel open after Close")
+	}
+	if _, late := b.Subscribe(1); late != nil {
+		if _, ok := <-late; ok {
+			t.Error("Subscribe after Close returned an open channel")
+		}
+	}
+	b.Publish(1)
+}
+
+func TestListen(t *testing.T) {
+	var b Bus[int]
+	var (
+		mu  sync.Mutex
+		got []int
+	)
+	stop := Listen(&b, 8, func(e int) {
+		mu.Lock()
+		got = append(got, e)
+		mu.Unlock()
+	})
+	for i := 1; i <= 3; i++ {
+		b.Publish(i)
+	}
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("listener saw %v, want [1 2 3]", got)
+	}
+}
+
+func TestListenBatched(t *testing.T) {
+	for _, tc := range []struct {
+		window time.Duration
+		want   int // batches
+	}{{time.Hour, 1}, {0, 3}} {
+		var b Bus[int]
+		var (
+			mu      sync.Mutex
+			batches [][]int
+		)
+		stop := ListenBatched(&b, 8, tc.window, func(batch []int) {
+			mu.Lock()
+			batches = append(batches, batch)
+			mu.Unlock()
+		})
+		for i := 1; i <= 3; i++ {
+			b.Publish(i)
+		}
+		if tc.window == 0 {
+			// Let each event be handled before stop closes the channel.
+			for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+				mu.Lock()
+				n := len(batches)
+				mu.Unlock()
+				if n == 3 {
+					break
+				}
+			}
+		}
+		stop() // flushes the batch in progress
+
+		mu.Lock()
+		if len(batches) != tc.want || batches[len(batches)-1][len(batches[len(batches)-1])-1] != 3 {
+			t.Errorf("window %v: batches %v, want %d ending with 3", tc.window, batches, tc.want)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestBus_KeepDoesNotHoldUpLeaving(t *testing.T) {
+	b := Bus[int]{Keep: func(int) bool { return true }}
+	stuck, _ := b.Subscribe(0) // never read
+	published := make(chan struct{})
+	go func() {
+		b.Publish(1)
+		close(published)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// Others come and go while Publish waits for room
+	left := make(chan struct{})
+	go func() {
+		id, _ := b.Subscribe(1)
+		b.Unsubscribe(id)
+		b.Unsubscribe(stuck)
+		close(left)
+	}()
+	select {
+	case <-left:
+	case <-time.After(time.Second):
+		t.Fatal("Unsubscribe blocked behind a waiting Publish")
+	}
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish still waiting for a subscriber that left")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		b.Subscribe(0)
+		go b.Publish(2)
+		time.Sleep(20 * time.Millisecond)
+		b.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked behind a waiting Publish")
+	}
+}
@@ -0,0 +1,121 @@
+// Package fileerr turns the filesystem errors users can act on — missing
+// permissions, read-only mounts, full disks and quotas — into errors that
+// say so, naming the file's resolved path and the mount it lives on. Raw Go
+// errors ("operation not permitted") are unhelpful on NAS shares, where the
+// cause is usually the share's options rather than the file.
+package fileerr
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Code identifies what went wrong, for the frontend to act on.
+type Code string
+
+const (
+	PermissionDenied Code = "permission-denied" // EACCES, EPERM
+	ReadOnly         Code = "read-only"         // EROFS
+	QuotaExceeded    Code = "quota-exceeded"    // EDQUOT
+	DiskFull         Code = "disk-full"         // ENOSPC
+)
+
+// hint tells the user what to do about an error of each code.
+var hint = map[Code]string{
+	PermissionDenied: "permission denied; check that the user FLACidal runs as owns or may write to it",
+	ReadOnly:         "the filesystem is mounted read-only; remount it read-write or pick another folder",
+	QuotaExceeded:    "the disk quota is exceeded; free space or raise the quota",
+	DiskFull:         "no space left on the device",
+}
+
+// Classify returns the code for err, or "" when it isn't one of those.
+func Classify(err error) Code {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, syscall.EROFS):
+		return ReadOnly
+	case errors.Is(err, syscall.EDQUOT):
+		return QuotaExceeded
+	case errors.Is(err, syscall.ENOSPC):
+		return DiskFull
+	case errors.Is(err, fs.ErrPermission):
+		return PermissionDenied
+	}
+	return ""
+}
+
+// Error is a classified filesystem error.
+type Error struct {
+	Code    Code   `json:"code"`
+	Op      string `json:"op"`              // "open", "rename"…
+	Path    string `json:"path"`            // absolute, with symlinks resolved
+	Mount   string `json:"mount,omitempty"` // mount point holding Path, when found
+	Message string `json:"message"`
+	Err     error  `json:"-"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Wrap returns err as an *Error when Classify recognizes it and it names a
+// file, and err unchanged otherwise (including nil and errors already
+// wrapped).
+func Wrap(err error) error {
+	code := Classify(err)
+	if code == "" || As(err) != nil {
+		return err
+	}
+	var op, path string
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	switch {
+	case errors.As(err, &pathErr):
+		op, path = pathErr.Op, pathErr.Path
+	case errors.As(err, &linkErr):
+		// A rename fails for want of access to the destination as often as
+		// the source; the destination is the one that is new.
+		op, path = linkErr.Op, linkErr.New
+	default:
+		return err
+	}
+	e := &Error{Code: code, Op: op, Path: resolve(path), Err: err}
+	e.Mount = mountPoint(e.Path)
+	e.Message = op + " " + e.Path + ": " + hint[code]
+	if e.Mount != "" {
+		e.Message += " (on " + e.Mount + ")"
+	}
+	return e
+}
+
+// As returns the *Error in err's chain, or nil.
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
+
+// resolve makes path absolute and resolves the symlinks in as much of it as
+// exists: the file itself may be what couldn't be created.
+func resolve(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rest := ""
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		if filepath.Dir(dir) == dir {
+			return abs
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+	}
+}
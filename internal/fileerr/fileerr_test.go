@@ -0,0 +1,76 @@
+package fileerr
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestWrap_ResolvesPathAndMount(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "music")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+	resolvedDir, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file doesn't exist: only its folder resolves.
+	cause := &fs.PathError{Op: "open", Path: filepath.Join(link, "new.flac"), Err: syscall.EROFS}
+	err = Wrap(fmt.Errorf("tagging: %w", cause))
+	e := As(err)
+	if e == nil {
+		t.Fatalf("Wrap = %v, want an *Error", err)
+	}
+	if want := filepath.Join(resolvedDir, "new.flac"); e.Path != want {
+		t.Errorf("Path = %q, want %q", e.Path, want)
+	}
+	if e.Code != ReadOnly || e.Op != "open" || e.Mount == "" || !strings.HasPrefix(resolvedDir, e.Mount) {
+		t.Errorf("error = %+v", e)
+	}
+	if !strings.Contains(err.Error(), "read-only") || !strings.Contains(err.Error(), e.Path) {
+		t.Errorf("message = %q", err.Error())
+	}
+	if !errors.Is(err, syscall.EROFS) {
+		t.Error("cause lost")
+	}
+	if Wrap(err) != err {
+		t.Error("wrapping twice changed the error")
+	}
+}
+
+func TestWrap_RenameNamesDestination(t *testing.T) {
+	dir := t.TempDir()
+	err := Wrap(&os.LinkError{Op: "rename", Old: filepath.Join(dir, "a.flac"), New: filepath.Join(dir, "b.flac"), Err: syscall.EACCES})
+	if e := As(err); e == nil || e.Code != PermissionDenied || filepath.Base(e.Path) != "b.flac" {
+		t.Errorf("Wrap = %#v", err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	for err, want := range map[error]Code{
+		syscall.EPERM:  PermissionDenied,
+		syscall.EDQUOT: QuotaExceeded,
+		syscall.ENOSPC: DiskFull,
+		syscall.ENOENT: "",
+	} {
+		if got := Classify(&fs.PathError{Op: "write", Path: "x", Err: err}); got != want {
+			t.Errorf("Classify(%v) = %q, want %q", err, got, want)
+		}
+	}
+	plain := &fs.PathError{Op: "open", Path: "x", Err: syscall.ENOENT}
+	if Wrap(plain) != error(plain) || Wrap(nil) != nil {
+		t.Error("Wrap changed an error it doesn't classify")
+	}
+}
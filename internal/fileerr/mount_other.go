@@ -0,0 +1,14 @@
+//go:build !unix
+
+package fileerr
+
+import "path/filepath"
+
+// mountPoint returns the volume holding path ("C:\", `\\nas\share`).
+func mountPoint(path string) string {
+	vol := filepath.VolumeName(path)
+	if vol == "" {
+		return ""
+	}
+	return vol + string(filepath.Separator)
+}
@@ -0,0 +1,51 @@
+//go:build unix
+
+package fileerr
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// mountPoint returns the mount point holding path: the highest ancestor
+// still on the same device.
+func mountPoint(path string) string {
+	dir := existingDir(path)
+	dev, ok := device(dir)
+	if !ok {
+		return ""
+	}
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if d, ok := device(parent); !ok || d != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+func device(path string) (uint64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// existingDir returns the closest ancestor of path (or path) that exists.
+func existingDir(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil || filepath.Dir(path) == path {
+			return path
+		}
+		path = filepath.Dir(path)
+	}
+}
@@ -0,0 +1,131 @@
+package flacmeta
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// errBadComments is returned for a VORBIS_COMMENT block whose lengths run
+// past the end of the block.
+var errBadComments = errors.New("malformed VORBIS_COMMENT block")
+
+// Field is one NAME=value Vorbis comment.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Comments is a parsed VORBIS_COMMENT block. Field order is preserved and
+// names may repeat (multi-valued tags such as ARTIST). Name lookups are
+// case-insensitive, as the Vorbis comment spec requires.
+type Comments struct {
+	Vendor string
+	Fields []Field
+}
+
+// ParseComments decodes the payload of a VORBIS_COMMENT block.
+func ParseComments(data []byte) (*Comments, error) {
+	pos := 0
+	vendorLen, ok := readUint32LE(data, &pos)
+	if !ok || pos+int(vendorLen) > len(data) {
+		return nil, errBadComments
+	}
+	c := &Comments{Vendor: string(data[pos : pos+int(vendorLen)])}
+	pos += int(vendorLen)
+
+	count, ok := readUint32LE(data, &pos)
+	if !ok {
+		return nil, errBadComments
+	}
+	for i := uint32(0); i < count; i++ {
+		n, ok := readUint32LE(data, &pos)
+		if !ok || pos+int(n) > len(data) {
+			return nil, errBadComments
+		}
+		entry := string(data[pos : pos+int(n)])
+		pos += int(n)
+		name, value, found := strings.Cut(entry, "=")
+		if !found {
+			continue // not a valid comment; dropped like other taggers do
+		}
+		c.Fields = append(c.Fields, Field{Name: strings.ToUpper(name), Value: value})
+	}
+	return c, nil
+}
+
+// Marshal encodes c as a VORBIS_COMMENT block payload.
+func (c *Comments) Marshal() []byte {
+	size := 8 + len(c.Vendor)
+	for _, f := range c.Fields {
+		size += 4 + len(f.Name) + 1 + len(f.Value)
+	}
+	buf := make([]byte, 0, size)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(c.Vendor)))
+	buf = append(buf, c.Vendor...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(c.Fields)))
+	for _, f := range c.Fields {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(f.Name)+1+len(f.Value)))
+		buf = append(buf, f.Name...)
+		buf = append(buf, '=')
+		buf = append(buf, f.Value...)
+	}
+	return buf
+}
+
+// Get returns the first value of name, or "".
+func (c *Comments) Get(name string) string {
+	for _, f := range c.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// GetAll returns every value of name in file order.
+func (c *Comments) GetAll(name string) []string {
+	var values []string
+	for _, f := range c.Fields {
+		if strings.EqualFold(f.Name, name) {
+			values = append(values, f.Value)
+		}
+	}
+	return values
+}
+
+// Set replaces all values of name with values, keeping the position of the
+// first existing occurrence. Set with no values deletes the tag.
+func (c *Comments) Set(name string, values ...string) {
+	name = strings.ToUpper(name)
+	at := -1
+	kept := c.Fields[:0]
+	for _, f := range c.Fields {
+		if strings.EqualFold(f.Name, name) {
+			if at < 0 {
+				at = len(kept)
+			}
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if at < 0 {
+		at = len(kept)
+	}
+	fields := make([]Field, 0, len(kept)+len(values))
+	fields = append(fields, kept[:at]...)
+	for _, v := range values {
+		fields = append(fields, Field{Name: name, Value: v})
+	}
+	c.Fields = append(fields, kept[at:]...)
+}
+
+// Add appends one value for name, keeping any existing values.
+func (c *Comments) Add(name, value string) {
+	c.Fields = append(c.Fields, Field{Name: strings.ToUpper(name), Value: value})
+}
+
+// Delete removes every value of name.
+func (c *Comments) Delete(name string) {
+	c.Set(name)
+}
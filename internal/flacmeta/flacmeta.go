@@ -0,0 +1,329 @@
+// Package flacmeta reads and rewrites the metadata blocks at the head of a
+// FLAC file. It exists for the post-download steps FLACidal runs itself
+// (extra tags, disc numbers, provenance) — flacidal-core's tagger only writes
+// the fields it knows about at download time.
+//
+// Every block is kept byte-for-byte unless it is explicitly replaced, so
+// SEEKTABLE, CUESHEET, APPLICATION and PICTURE blocks written by other tools
+// survive a rewrite; only PADDING is resized. Audio frames are never
+// decoded; they are copied as-is, or not touched at all when the new
+// metadata fits in the old metadata's space.
+package flacmeta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// BlockType identifies a FLAC metadata block (RFC 9639 §8.1).
+type BlockType uint8
+
+const (
+	BlockStreamInfo    BlockType = 0
+	BlockPadding       BlockType = 1
+	BlockApplication   BlockType = 2
+	BlockSeekTable     BlockType = 3
+	BlockVorbisComment BlockType = 4
+	BlockCueSheet      BlockType = 5
+	BlockPicture       BlockType = 6
+)
+
+// maxBlockLength is the largest payload a 24-bit block length can describe.
+const maxBlockLength = 1<<24 - 1
+
+// ErrNotFLAC is returned when a file does not start with the "fLaC" marker,
+// bar an ID3v2 tag before it.
+var ErrNotFLAC = errors.New("not a FLAC file")
+
+// Block is one metadata block. Data excludes the 4-byte block header.
+type Block struct {
+	Type BlockType
+	Data []byte
+}
+
+// File is the metadata of a FLAC file on disk, plus where its audio starts.
+type File struct {
+	Path   string
+	Blocks []Block
+
+	// ID3 is the ID3v2 tag found before the "fLaC" marker, nil when there
+	// is none. Save keeps it as-is; set it to nil to remove it.
+	ID3 []byte
+
+	audioOffset int64
+	id3Length   int64 // length of the ID3 tag on disk
+}
+
+// Read parses the metadata blocks of the FLAC file at path, skipping an
+// ID3v2 tag in front of them. The audio frames are not read.
+func Read(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	id3, err := readID3(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: truncated ID3 tag: %w", path, ErrNotFLAC)
+	}
+	var marker [4]byte
+	if _, err := io.ReadFull(r, marker[:]); err != nil || string(marker[:]) != "fLaC" {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotFLAC)
+	}
+
+	file := &File{Path: path, ID3: id3, id3Length: int64(len(id3)), audioOffset: int64(len(id3)) + 4}
+	for {
+		var hdr [4]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, fmt.Errorf("%s: truncated metadata block header: %w", path, err)
+		}
+		last := hdr[0]&0x80 != 0
+		length := int(hdr[1])<<16 | int(hdr[2])<<8 | int(hdr[3])
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("%s: truncated metadata block: %w", path, err)
+		}
+		file.Blocks = append(file.Blocks, Block{Type: BlockType(hdr[0] & 0x7f), Data: data})
+		file.audioOffset += 4 + int64(length)
+		if last {
+			break
+		}
+	}
+	if len(file.Blocks) == 0 || file.Blocks[0].Type != BlockStreamInfo {
+		return nil, fmt.Errorf("%s: first metadata block is not STREAMINFO: %w", path, ErrNotFLAC)
+	}
+	return file, nil
+}
+
+// AudioOffset returns the byte offset of the first audio frame, counting
+// any ID3 tag in front of the metadata.
+func (f *File) AudioOffset() int64 {
+	return f.audioOffset
+}
+
+// Find returns the index of the first block of type t, or -1.
+func (f *File) Find(t BlockType) int {
+	for i, b := range f.Blocks {
+		if b.Type == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// Comments parses the file's VORBIS_COMMENT block. A file without one yields
+// an empty Comments, so callers can always edit and SetComments the result.
+func (f *File) Comments() (*Comments, error) {
+	i := f.Find(BlockVorbisComment)
+	if i < 0 {
+		return &Comments{Vendor: "FLACidal"}, nil
+	}
+	return ParseComments(f.Blocks[i].Data)
+}
+
+// SetComments replaces the VORBIS_COMMENT block with c, inserting one right
+// after STREAMINFO when the file has none.
+func (f *File) SetComments(c *Comments) {
+	block := Block{Type: BlockVorbisComment, Data: c.Marshal()}
+	if i := f.Find(BlockVorbisComment); i >= 0 {
+		f.Blocks[i] = block
+		return
+	}
+	f.Blocks = append(f.Blocks[:1], append([]Block{block}, f.Blocks[1:]...)...)
+}
+
+// DefaultPadding is the size of the PADDING block Save leaves after the
+// metadata whenever it rewrites a whole file, so that later edits of a few
+// tags or a cover usually fit in place.
+const DefaultPadding = 8192
+
+// Save writes the metadata back to f.Path. When the blocks fit in the space
+// the current metadata and padding take up, only that region is rewritten
+// and the audio is left untouched; the difference becomes the new PADDING
+// block. Otherwise the whole file is rewritten with DefaultPadding bytes of
+// padding: the new file is assembled next to the original and renamed over
+// it, so a failure part-way never leaves a half-written FLAC behind.
+//
+// Either way any PADDING blocks are merged into one after the other blocks.
+// The ID3 tag is written back too; removing it, or resizing it, always
+// rewrites the whole file.
+func (f *File) Save() error {
+	for _, b := range f.Blocks {
+		if len(b.Data) > maxBlockLength {
+			return fmt.Errorf("%s: metadata block type %d is %d bytes, over the FLAC limit", f.Path, b.Type, len(b.Data))
+		}
+	}
+
+	blocks := f.unpadded()
+	free := f.audioOffset - f.id3Length - metadataLength(blocks)
+	switch {
+	case int64(len(f.ID3)) != f.id3Length: // the audio moves
+	case free == 0:
+		f.Blocks = blocks
+		return f.saveInPlace()
+	case free >= 4 && free-4 <= maxBlockLength:
+		f.Blocks = append(blocks, Block{Type: BlockPadding, Data: make([]byte, free-4)})
+		return f.saveInPlace()
+	}
+	f.Blocks = append(blocks, Block{Type: BlockPadding, Data: make([]byte, DefaultPadding)})
+	return f.rewrite()
+}
+
+// unpadded returns f's blocks without any PADDING.
+func (f *File) unpadded() []Block {
+	blocks := make([]Block, 0, len(f.Blocks)+1)
+	for _, b := range f.Blocks {
+		if b.Type != BlockPadding {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+// metadataLength returns the bytes the marker and blocks take up on disk.
+func metadataLength(blocks []Block) int64 {
+	n := int64(4)
+	for _, b := range blocks {
+		n += 4 + int64(len(b.Data))
+	}
+	return n
+}
+
+// saveInPlace overwrites the metadata region of f.Path with f.ID3 and
+// f.Blocks, which must take up exactly f.audioOffset bytes.
+func (f *File) saveInPlace() error {
+	var buf bytes.Buffer
+	buf.Write(f.ID3)
+	if _, err := f.writeMetadata(&buf); err != nil {
+		return err
+	}
+	if int64(buf.Len()) != f.audioOffset {
+		return fmt.Errorf("%s: in-place metadata is %d bytes, want %d", f.Path, buf.Len(), f.audioOffset)
+	}
+	out, err := os.OpenFile(f.Path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = out.WriteAt(buf.Bytes(), 0)
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("%s: rewrite metadata: %w", f.Path, err)
+	}
+	return nil
+}
+
+// rewrite writes f.ID3, f.Blocks and the audio of f.Path to a new file and
+// renames it over f.Path.
+func (f *File) rewrite() error {
+	src, err := os.Open(f.Path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := src.Seek(f.audioOffset, io.SeekStart); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), ".flacmeta-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	w := bufio.NewWriter(tmp)
+	_, err = w.Write(f.ID3)
+	var newOffset int64
+	if err == nil {
+		newOffset, err = f.writeMetadata(w)
+		newOffset += int64(len(f.ID3))
+	}
+	if err == nil {
+		_, err = io.Copy(w, src)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("%s: rewrite metadata: %w", f.Path, err)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()); err != nil {
+		return err
+	}
+	src.Close()
+	if err := os.Rename(tmpPath, f.Path); err != nil {
+		return err
+	}
+	f.audioOffset = newOffset
+	f.id3Length = int64(len(f.ID3))
+	return nil
+}
+
+// writeMetadata writes the marker and all blocks, returning the resulting
+// audio offset.
+func (f *File) writeMetadata(w io.Writer) (int64, error) {
+	if _, err := io.WriteString(w, "fLaC"); err != nil {
+		return 0, err
+	}
+	n := int64(4)
+	for i, b := range f.Blocks {
+		hdr := [4]byte{byte(b.Type), byte(len(b.Data) >> 16), byte(len(b.Data) >> 8), byte(len(b.Data))}
+		if i == len(f.Blocks)-1 {
+			hdr[0] |= 0x80
+		}
+		if _, err := w.Write(hdr[:]); err != nil {
+			return 0, err
+		}
+		if _, err := w.Write(b.Data); err != nil {
+			return 0, err
+		}
+		n += 4 + int64(len(b.Data))
+	}
+	return n, nil
+}
+
+// UpdateComments reads the FLAC file at path, lets edit change its Vorbis
+// comments, and saves the result.
+func UpdateComments(path string, edit func(c *Comments)) error {
+	f, err := Read(path)
+	if err != nil {
+		return err
+	}
+	c, err := f.Comments()
+	if err != nil {
+		return err
+	}
+	edit(c)
+	f.SetComments(c)
+	return f.Save()
+}
+
+// readUint32LE reads a little-endian uint32 at data[*pos], advancing pos.
+func readUint32LE(data []byte, pos *int) (uint32, bool) {
+	if *pos+4 > len(data) {
+		return 0, false
+	}
+	v := binary.LittleEndian.Uint32(data[*pos:])
+	*pos += 4
+	return v, true
+}
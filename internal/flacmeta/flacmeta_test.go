@@ -0,0 +1,214 @@
+package flacmeta
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFixture writes a minimal FLAC file: STREAMINFO, the given extra
+// blocks, then audio bytes.
+func writeFixture(t *testing.T, blocks []Block, audio []byte) string {
+	t.Helper()
+	f := &File{Blocks: append([]Block{{Type: BlockStreamInfo, Data: make([]byte, 34)}}, blocks...)}
+	var buf bytes.Buffer
+	if _, err := f.writeMetadata(&buf); err != nil {
+		t.Fatal(err)
+	}
+	buf.Write(audio)
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead_NotFLAC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.flac")
+	os.WriteFile(path, []byte("ID3\x04 not a flac"), 0644)
+	if _, err := Read(path); !errors.Is(err, ErrNotFLAC) {
+		t.Fatalf("err = %v, want ErrNotFLAC", err)
+	}
+}
+
+func TestUpdateComments_PreservesBlocksAndAudio(t *testing.T) {
+	seek := Block{Type: BlockSeekTable, Data: bytes.Repeat([]byte{0xAB}, 18)}
+	pic := Block{Type: BlockPicture, Data: []byte("picture-bytes")}
+	audio := []byte("\xff\xf8 audio frames")
+	path := writeFixture(t, []Block{seek, pic}, audio)
+
+	err := UpdateComments(path, func(c *Comments) {
+		c.Set("DISCNUMBER", "2")
+		c.Set("TOTALDISCS", "3")
+	})
+	if err != nil {
+		t.Fatalf("UpdateComments: %v", err)
+	}
+
+	f, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	gotTypes := []BlockType{}
+	for _, b := range f.Blocks {
+		gotTypes = append(gotTypes, b.Type)
+	}
+	// The file had no room for the comments, so it was rewritten with padding.
+	wantTypes := []BlockType{BlockStreamInfo, BlockVorbisComment, BlockSeekTable, BlockPicture, BlockPadding}
+	if len(gotTypes) != len(wantTypes) {
+		t.Fatalf("blocks = %v, want %v", gotTypes, wantTypes)
+	}
+	for i := range wantTypes {
+		if gotTypes[i] != wantTypes[i] {
+			t.Fatalf("blocks = %v, want %v", gotTypes, wantTypes)
+		}
+	}
+	if !bytes.Equal(f.Blocks[2].Data, seek.Data) || !bytes.Equal(f.Blocks[3].Data, pic.Data) {
+		t.Error("existing blocks were modified")
+	}
+
+	c, err := f.Comments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Get("discnumber") != "2" || c.Get("TOTALDISCS") != "3" {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+
+	raw, _ := os.ReadFile(path)
+	if !bytes.Equal(raw[f.AudioOffset():], audio) {
+		t.Error("audio frames were not copied verbatim")
+	}
+}
+
+func TestSave_InPlaceWhenPaddingFits(t *testing.T) {
+	audio := []byte("\xff\xf8 audio frames")
+	path := writeFixture(t, []Block{{Type: BlockPadding, Data: make([]byte, 64)}}, audio)
+	before, _ := os.Stat(path)
+	first, _ := Read(path)
+
+	if err := UpdateComments(path, func(c *Comments) { c.Set("TITLE", "Song") }); err != nil {
+		t.Fatalf("UpdateComments: %v", err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() != before.Size() || !os.SameFile(before, after) {
+		t.Errorf("file was replaced (size %d -> %d), want an in-place edit", before.Size(), after.Size())
+	}
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.AudioOffset() != first.AudioOffset() || f.Blocks[len(f.Blocks)-1].Type != BlockPadding {
+		t.Errorf("blocks = %+v, audio at %d; want padding shrunk to fit", f.Blocks, f.AudioOffset())
+	}
+	if c, _ := f.Comments(); c.Get("TITLE") != "Song" {
+		t.Errorf("TITLE = %q", c.Get("TITLE"))
+	}
+	raw, _ := os.ReadFile(path)
+	if !bytes.Equal(raw[f.AudioOffset():], audio) {
+		t.Error("audio frames changed")
+	}
+}
+
+func TestSave_RewritesWithPaddingWhenFull(t *testing.T) {
+	path := writeFixture(t, []Block{{Type: BlockPadding, Data: make([]byte, 8)}}, []byte("audio"))
+	if err := UpdateComments(path, func(c *Comments) { c.Set("COMMENT", string(bytes.Repeat([]byte("x"), 100))) }); err != nil {
+		t.Fatalf("UpdateComments: %v", err)
+	}
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := f.Blocks[len(f.Blocks)-1]
+	if last.Type != BlockPadding || len(last.Data) != DefaultPadding {
+		t.Errorf("last block = type %d, %d bytes; want %d bytes of padding", last.Type, len(last.Data), DefaultPadding)
+	}
+}
+
+func TestComments_RoundTrip(t *testing.T) {
+	c := &Comments{Vendor: "ref libFLAC 1.4.3"}
+	c.Add("artist", "A")
+	c.Add("ARTIST", "B")
+	c.Add("TITLE", "Song=With=Equals")
+
+	got, err := ParseComments(c.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Vendor != c.Vendor {
+		t.Errorf("vendor = %q", got.Vendor)
+	}
+	if all := got.GetAll("Artist"); len(all) != 2 || all[0] != "A" || all[1] != "B" {
+		t.Errorf("ARTIST = %v", all)
+	}
+	if got.Get("TITLE") != "Song=With=Equals" {
+		t.Errorf("TITLE = %q", got.Get("TITLE"))
+	}
+}
+
+func TestComments_SetKeepsPosition(t *testing.T) {
+	c := &Comments{Fields: []Field{{"TITLE", "t"}, {"ARTIST", "a"}, {"ALBUM", "b"}, {"ARTIST", "c"}}}
+	c.Set("artist", "x", "y")
+	want := []Field{{"TITLE", "t"}, {"ARTIST", "x"}, {"ARTIST", "y"}, {"ALBUM", "b"}}
+	if len(c.Fields) != len(want) {
+		t.Fatalf("fields = %v", c.Fields)
+	}
+	for i := range want {
+		if c.Fields[i] != want[i] {
+			t.Fatalf("fields = %v, want %v", c.Fields, want)
+		}
+	}
+
+	c.Delete("ARTIST")
+	if len(c.GetAll("ARTIST")) != 0 || len(c.Fields) != 2 {
+		t.Errorf("after Delete: %v", c.Fields)
+	}
+}
+
+func TestParseComments_Truncated(t *testing.T) {
+	data := (&Comments{Vendor: "v", Fields: []Field{{"A", "b"}}}).Marshal()
+	if _, err := ParseComments(data[:len(data)-1]); err == nil {
+		t.Error("expected error for truncated block")
+	}
+}
+
+func TestPreserve_RestoresDroppedBlocks(t *testing.T) {
+	seek := Block{Type: BlockSeekTable, Data: bytes.Repeat([]byte{0xAB}, 18)}
+	cue := Block{Type: BlockCueSheet, Data: bytes.Repeat([]byte{0xCD}, 40)}
+	audio := []byte("\xff\xf8 audio frames")
+	path := writeFixture(t, []Block{seek, cue}, audio)
+
+	// Stand-in for a tagger that keeps only STREAMINFO and its comments.
+	err := Preserve(path, func() error {
+		f, err := Read(path)
+		if err != nil {
+			return err
+		}
+		f.Blocks = f.Blocks[:1]
+		f.SetComments(&Comments{Vendor: "tagger", Fields: []Field{{Name: "LYRICS", Value: "la"}}})
+		return f.Save()
+	})
+	if err != nil {
+		t.Fatalf("Preserve: %v", err)
+	}
+
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i := f.Find(BlockSeekTable); i < 0 || !bytes.Equal(f.Blocks[i].Data, seek.Data) {
+		t.Error("SEEKTABLE was not restored")
+	}
+	if i := f.Find(BlockCueSheet); i < 0 || !bytes.Equal(f.Blocks[i].Data, cue.Data) {
+		t.Error("CUESHEET was not restored")
+	}
+	if c, _ := f.Comments(); c.Get("LYRICS") != "la" {
+		t.Error("the rewrite's own changes were lost")
+	}
+	raw, _ := os.ReadFile(path)
+	if !bytes.HasSuffix(raw, audio) {
+		t.Error("audio frames were modified")
+	}
+}
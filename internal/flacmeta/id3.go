@@ -0,0 +1,59 @@
+package flacmeta
+
+import (
+	"bufio"
+	"io"
+)
+
+// id3HeaderLength is the size of an ID3v2 header and of its optional
+// footer.
+const id3HeaderLength = 10
+
+// id3Length returns the length of the ID3v2 tag head starts with, header
+// and footer included, or 0 when head (at least id3HeaderLength bytes)
+// doesn't start with one. Some taggers put an ID3v2 tag, meant for MP3s,
+// in front of the "fLaC" marker; most players skip it, so files like that
+// are common.
+func id3Length(head []byte) int64 {
+	if len(head) < id3HeaderLength || string(head[:3]) != "ID3" || head[3] == 0xff || head[4] == 0xff {
+		return 0
+	}
+	var size int64
+	for _, b := range head[6:10] {
+		if b&0x80 != 0 {
+			return 0 // sizes are "synchsafe": 7 bits per byte
+		}
+		size = size<<7 | int64(b)
+	}
+	n := id3HeaderLength + size
+	if head[5]&0x10 != 0 {
+		n += id3HeaderLength // footer present
+	}
+	return n
+}
+
+// readID3 reads the ID3v2 tag r starts with, if any.
+func readID3(r *bufio.Reader) ([]byte, error) {
+	head, _ := r.Peek(id3HeaderLength)
+	n := id3Length(head)
+	if n == 0 {
+		return nil, nil
+	}
+	tag := make([]byte, n)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// StripID3 removes the ID3v2 tag in front of the FLAC file at path and
+// reports whether it had one. The file is rewritten, its audio copied
+// as-is; files without the tag are not touched.
+func StripID3(path string) (bool, error) {
+	f, err := Read(path)
+	if err != nil || f.ID3 == nil {
+		return false, err
+	}
+	f.ID3 = nil
+	return true, f.Save()
+}
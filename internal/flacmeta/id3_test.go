@@ -0,0 +1,106 @@
+package flacmeta
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// id3Tag returns an ID3v2.3 tag with size bytes of frames.
+func id3Tag(size int) []byte {
+	head := []byte{'I', 'D', '3', 3, 0, 0, byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)}
+	return append(head, bytes.Repeat([]byte{'T'}, size)...)
+}
+
+// prependID3 puts tag in front of the file at path, and returns path.
+func prependID3(t *testing.T, path string, tag []byte) string {
+	t.Helper()
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, append(tag, data...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead_SkipsID3(t *testing.T) {
+	tag := id3Tag(300)
+	audio := []byte("\xff\xf8 audio frames")
+	path := prependID3(t, writeStream(t, 0, 0, audio), tag)
+
+	f, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !bytes.Equal(f.ID3, tag) {
+		t.Errorf("ID3 = %d bytes, want the %d-byte tag", len(f.ID3), len(tag))
+	}
+	raw, _ := os.ReadFile(path)
+	if !bytes.Equal(raw[f.AudioOffset():], audio) {
+		t.Errorf("audio offset %d is off", f.AudioOffset())
+	}
+	if _, err := ReadStreamInfo(path); err != nil {
+		t.Errorf("ReadStreamInfo: %v", err)
+	}
+	if err := Validate(path); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestSave_KeepsID3(t *testing.T) {
+	tag := id3Tag(100)
+	audio := []byte("\xff\xf8 audio frames")
+	path := prependID3(t, writeFixture(t, []Block{{Type: BlockPadding, Data: make([]byte, 256)}}, audio), tag)
+
+	// Fits the padding: rewritten in place.
+	if err := UpdateComments(path, func(c *Comments) { c.Set("TITLE", "Song") }); err != nil {
+		t.Fatalf("in place: %v", err)
+	}
+	// Doesn't: the whole file is rewritten.
+	if err := UpdateComments(path, func(c *Comments) { c.Set("COMMENT", string(bytes.Repeat([]byte("x"), 1024))) }); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	raw, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(raw, tag) {
+		t.Error("ID3 tag lost")
+	}
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := f.Comments()
+	if c.Get("TITLE") != "Song" || !bytes.Equal(raw[f.AudioOffset():], audio) {
+		t.Errorf("TITLE = %q, audio intact = %v", c.Get("TITLE"), bytes.Equal(raw[f.AudioOffset():], audio))
+	}
+}
+
+func TestStripID3(t *testing.T) {
+	audio := []byte("\xff\xf8 audio frames")
+	path := prependID3(t, writeFixture(t, nil, audio), id3Tag(100))
+
+	if stripped, err := StripID3(path); err != nil || !stripped {
+		t.Fatalf("StripID3 = %v, %v", stripped, err)
+	}
+	raw, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(raw, []byte("fLaC")) || !bytes.HasSuffix(raw, audio) {
+		t.Errorf("file = %q...", raw[:8])
+	}
+	if stripped, err := StripID3(path); err != nil || stripped {
+		t.Errorf("second StripID3 = %v, %v; want false", stripped, err)
+	}
+}
+
+func TestID3Length(t *testing.T) {
+	if n := id3Length(id3Tag(300)); n != 310 {
+		t.Errorf("id3Length = %d, want 310", n)
+	}
+	footer := id3Tag(5)
+	footer[5] |= 0x10
+	if n := id3Length(footer); n != 25 {
+		t.Errorf("with footer: %d, want 25", n)
+	}
+	for _, head := range []string{"fLaC\x00\x00\x00\x22\x00\x00", "ID3\x03\x00\x00\x80\x00\x00\x00", "ID3"} {
+		if n := id3Length([]byte(head)); n != 0 {
+			t.Errorf("id3Length(%q) = %d, want 0", head, n)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package flacmeta
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// PICTURE types (RFC 9639 §8.8) the file browser offers. Any other value
+// up to 20 is valid too; see PictureTypeName.
+const (
+	PictureOther      = 0
+	PictureFrontCover = 3 // an album's front cover
+	PictureBackCover  = 4
+	PictureLeaflet    = 5
+	PictureMedia      = 6 // e.g. the CD's label side
+	PictureArtist     = 8
+)
+
+// pictureTypeNames names the PICTURE types, by value.
+var pictureTypeNames = [...]string{
+	"Other", "File icon", "Other file icon", "Front cover", "Back cover",
+	"Leaflet page", "Media", "Lead artist", "Artist", "Conductor", "Band",
+	"Composer", "Lyricist", "Recording location", "During recording",
+	"During performance", "Screen capture", "Bright colored fish",
+	"Illustration", "Band logo", "Publisher logo",
+}
+
+// PictureTypeName returns the name of PICTURE type t, e.g. "Back cover".
+func PictureTypeName(t uint32) string {
+	if int(t) < len(pictureTypeNames) {
+		return pictureTypeNames[t]
+	}
+	return "Unknown"
+}
+
+// ValidPictureType reports whether t is a PICTURE type the format defines.
+func ValidPictureType(t uint32) bool {
+	return int(t) < len(pictureTypeNames)
+}
+
+// errBadStreamInfo is returned for a STREAMINFO block shorter than the
+// 34 bytes the format requires.
+var errBadStreamInfo = errors.New("malformed STREAMINFO block")
+
+// Picture is a PICTURE block (RFC 9639 §8.8). Width, Height and Depth may
+// be left 0 when unknown.
+type Picture struct {
+	Type        uint32
+	MIME        string
+	Description string
+	Width       uint32
+	Height      uint32
+	Depth       uint32
+	Data        []byte
+}
+
+// Marshal encodes p as a PICTURE block payload.
+func (p *Picture) Marshal() []byte {
+	buf := make([]byte, 0, 32+len(p.MIME)+len(p.Description)+len(p.Data))
+	buf = binary.BigEndian.AppendUint32(buf, p.Type)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(p.MIME)))
+	buf = append(buf, p.MIME...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(p.Description)))
+	buf = append(buf, p.Description...)
+	buf = binary.BigEndian.AppendUint32(buf, p.Width)
+	buf = binary.BigEndian.AppendUint32(buf, p.Height)
+	buf = binary.BigEndian.AppendUint32(buf, p.Depth)
+	buf = binary.BigEndian.AppendUint32(buf, 0) // colors: only for indexed images
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(p.Data)))
+	return append(buf, p.Data...)
+}
+
+// errBadPicture is returned for a PICTURE block whose lengths run past its
+// end.
+var errBadPicture = errors.New("malformed PICTURE block")
+
+// ParsePicture decodes a PICTURE block payload.
+func ParsePicture(data []byte) (Picture, error) {
+	var p Picture
+	pos := 0
+	u32 := func() (uint32, bool) {
+		if len(data)-pos < 4 {
+			return 0, false
+		}
+		v := binary.BigEndian.Uint32(data[pos:])
+		pos += 4
+		return v, true
+	}
+	chunk := func() ([]byte, bool) {
+		n, ok := u32()
+		if !ok || uint64(len(data)-pos) < uint64(n) {
+			return nil, false
+		}
+		b := data[pos : pos+int(n)]
+		pos += int(n)
+		return b, true
+	}
+	var ok bool
+	if p.Type, ok = u32(); !ok {
+		return p, errBadPicture
+	}
+	mime, ok := chunk()
+	if !ok {
+		return p, errBadPicture
+	}
+	desc, ok := chunk()
+	if !ok {
+		return p, errBadPicture
+	}
+	p.MIME, p.Description = string(mime), string(desc)
+	for _, v := range []*uint32{&p.Width, &p.Height, &p.Depth, new(uint32)} { // the last is colors
+		if *v, ok = u32(); !ok {
+			return p, errBadPicture
+		}
+	}
+	if p.Data, ok = chunk(); !ok {
+		return p, errBadPicture
+	}
+	return p, nil
+}
+
+// Pictures returns the file's PICTURE blocks, in file order. Indexes into
+// it are what RemovePicture takes.
+func (f *File) Pictures() ([]Picture, error) {
+	var pics []Picture
+	for _, b := range f.Blocks {
+		if b.Type != BlockPicture {
+			continue
+		}
+		p, err := ParsePicture(b.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		pics = append(pics, p)
+	}
+	return pics, nil
+}
+
+// AddPicture adds p after the file's other pictures, keeping any of the
+// same type: a file may hold several, say, artist photos. Use SetPicture
+// to replace a picture instead.
+func (f *File) AddPicture(p Picture) {
+	at := len(f.Blocks)
+	for at > 1 && f.Blocks[at-1].Type == BlockPadding {
+		at--
+	}
+	blocks := make([]Block, 0, len(f.Blocks)+1)
+	blocks = append(blocks, f.Blocks[:at]...)
+	blocks = append(blocks, Block{Type: BlockPicture, Data: p.Marshal()})
+	f.Blocks = append(blocks, f.Blocks[at:]...)
+}
+
+// RemovePicture deletes the i'th picture (see Pictures).
+func (f *File) RemovePicture(i int) error {
+	n := 0
+	for j, b := range f.Blocks {
+		if b.Type != BlockPicture {
+			continue
+		}
+		if n == i {
+			f.Blocks = slices.Delete(f.Blocks, j, j+1)
+			return nil
+		}
+		n++
+	}
+	return fmt.Errorf("%s: no picture %d", f.Path, i)
+}
+
+// pictureType returns the PICTURE type of a block payload.
+func pictureType(data []byte) (uint32, bool) {
+	if len(data) < 4 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(data), true
+}
+
+// HasPicture reports whether the file has a PICTURE block of type t.
+func (f *File) HasPicture(t uint32) bool {
+	for _, b := range f.Blocks {
+		if pt, ok := pictureType(b.Data); b.Type == BlockPicture && ok && pt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPicture replaces the file's PICTURE blocks of p's type with p, adding
+// it after the other metadata when there is none. Pictures of other types
+// are kept.
+func (f *File) SetPicture(p Picture) {
+	block := Block{Type: BlockPicture, Data: p.Marshal()}
+	kept := f.Blocks[:0]
+	at := -1
+	for _, b := range f.Blocks {
+		if pt, ok := pictureType(b.Data); b.Type == BlockPicture && ok && pt == p.Type {
+			if at < 0 {
+				at = len(kept)
+			}
+			continue
+		}
+		kept = append(kept, b)
+	}
+	if at < 0 {
+		// Before trailing padding, so the padding stays last.
+		at = len(kept)
+		for at > 1 && kept[at-1].Type == BlockPadding {
+			at--
+		}
+	}
+	blocks := make([]Block, 0, len(kept)+1)
+	blocks = append(blocks, kept[:at]...)
+	blocks = append(blocks, block)
+	f.Blocks = append(blocks, kept[at:]...)
+}
+
+// RemovePictures deletes every PICTURE block and returns how many there
+// were.
+func (f *File) RemovePictures() int {
+	kept := f.Blocks[:0]
+	for _, b := range f.Blocks {
+		if b.Type != BlockPicture {
+			kept = append(kept, b)
+		}
+	}
+	n := len(f.Blocks) - len(kept)
+	f.Blocks = kept
+	return n
+}
@@ -0,0 +1,121 @@
+package flacmeta
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetPicture_ReplacesSameTypeOnly(t *testing.T) {
+	back := Picture{Type: 4, MIME: "image/png", Data: []byte("back")}
+	old := Picture{Type: PictureFrontCover, MIME: "image/png", Data: []byte("old")}
+	pad := Block{Type: BlockPadding, Data: make([]byte, 8)}
+	path := writeFixture(t, []Block{{Type: BlockPicture, Data: back.Marshal()}, {Type: BlockPicture, Data: old.Marshal()}, pad}, []byte("audio"))
+
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	front := Picture{Type: PictureFrontCover, MIME: "image/jpeg", Width: 640, Height: 640, Depth: 24, Data: []byte("jpeg")}
+	f.SetPicture(front)
+	if err := f.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	f, err = Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Blocks) != 4 || f.Blocks[3].Type != BlockPadding {
+		t.Fatalf("blocks = %+v, want the front cover replaced in place", f.Blocks)
+	}
+	if !bytes.Equal(f.Blocks[1].Data, back.Marshal()) || !bytes.Equal(f.Blocks[2].Data, front.Marshal()) {
+		t.Error("pictures = wrong contents")
+	}
+	if !f.HasPicture(PictureFrontCover) || f.HasPicture(5) {
+		t.Error("HasPicture misreports types")
+	}
+}
+
+func TestSetPicture_AddsBeforePadding(t *testing.T) {
+	path := writeFixture(t, []Block{{Type: BlockPadding, Data: make([]byte, 8)}}, []byte("audio"))
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetPicture(Picture{Type: PictureFrontCover, MIME: "image/jpeg", Data: []byte("jpeg")})
+	if len(f.Blocks) != 3 || f.Blocks[1].Type != BlockPicture || f.Blocks[2].Type != BlockPadding {
+		t.Errorf("blocks = %+v", f.Blocks)
+	}
+}
+
+func TestRemovePictures(t *testing.T) {
+	front := Picture{Type: PictureFrontCover, MIME: "image/jpeg", Data: []byte("jpeg")}
+	back := Picture{Type: 4, MIME: "image/png", Data: []byte("back")}
+	path := writeFixture(t, []Block{{Type: BlockPicture, Data: front.Marshal()}, {Type: BlockPicture, Data: back.Marshal()}, {Type: BlockPadding, Data: make([]byte, 8)}}, []byte("audio"))
+
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := f.RemovePictures(); n != 2 {
+		t.Errorf("RemovePictures = %d, want 2", n)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if f, err = Read(path); err != nil {
+		t.Fatal(err)
+	}
+	if f.Find(BlockPicture) >= 0 || f.RemovePictures() != 0 {
+		t.Errorf("blocks = %+v, want no pictures", f.Blocks)
+	}
+}
+
+func TestPictures_AddAndRemove(t *testing.T) {
+	front := Picture{Type: PictureFrontCover, MIME: "image/jpeg", Description: "cover", Width: 600, Height: 600, Depth: 24, Data: []byte("jpeg")}
+	path := writeFixture(t, []Block{{Type: BlockPicture, Data: front.Marshal()}, {Type: BlockPadding, Data: make([]byte, 8)}}, []byte("audio"))
+
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artist1 := Picture{Type: PictureArtist, MIME: "image/png", Data: []byte("one")}
+	artist2 := Picture{Type: PictureArtist, MIME: "image/png", Data: []byte("two")}
+	f.AddPicture(artist1)
+	f.AddPicture(artist2)
+	if f.Blocks[len(f.Blocks)-1].Type != BlockPadding {
+		t.Error("padding no longer last")
+	}
+	if err := f.RemovePicture(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RemovePicture(5); err == nil {
+		t.Error("RemovePicture(5) of 2: no error")
+	}
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err = Read(path); err != nil {
+		t.Fatal(err)
+	}
+	pics, err := f.Pictures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pics) != 2 || pics[0].Description != "cover" || pics[0].Width != 600 || !bytes.Equal(pics[1].Data, artist2.Data) {
+		t.Errorf("pictures = %+v", pics)
+	}
+}
+
+func TestParsePicture_Malformed(t *testing.T) {
+	data := (&Picture{Type: PictureFrontCover, MIME: "image/jpeg", Data: []byte("jpeg")}).Marshal()
+	for _, n := range []int{0, 3, 10, len(data) - 1} {
+		if _, err := ParsePicture(data[:n]); err == nil {
+			t.Errorf("ParsePicture of %d/%d bytes: no error", n, len(data))
+		}
+	}
+	if PictureTypeName(PictureBackCover) != "Back cover" || PictureTypeName(99) != "Unknown" || ValidPictureType(21) {
+		t.Error("picture type names")
+	}
+}
@@ -0,0 +1,32 @@
+package flacmeta
+
+// Preserve runs rewrite, which rewrites the FLAC file at path with some
+// other writer, and then puts back every kind of block the rewrite dropped.
+// flacidal-core's tagger rebuilds the metadata from the blocks it knows
+// about, losing SEEKTABLE and CUESHEET (and anything else it doesn't
+// replace) along the way; the audio is untouched, so the original blocks
+// are still valid and are restored byte-for-byte after the ones it kept.
+func Preserve(path string, rewrite func() error) error {
+	before, err := Read(path)
+	if err != nil {
+		return err
+	}
+	if err := rewrite(); err != nil {
+		return err
+	}
+	after, err := Read(path)
+	if err != nil {
+		return err
+	}
+	var lost []Block
+	for _, b := range before.Blocks {
+		if b.Type != BlockPadding && after.Find(b.Type) < 0 {
+			lost = append(lost, b)
+		}
+	}
+	if len(lost) == 0 {
+		return nil
+	}
+	after.Blocks = append(after.unpadded(), lost...)
+	return after.Save()
+}
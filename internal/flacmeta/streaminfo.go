@@ -0,0 +1,107 @@
+package flacmeta
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// StreamInfo is the audio format STREAMINFO records (RFC 9639 §8.2).
+type StreamInfo struct {
+	MinBlockSize int // samples per frame; at least 16 in a valid stream
+	MaxBlockSize int
+	MinFrameSize int // bytes; 0 when the encoder didn't record it
+	MaxFrameSize int
+	SampleRate   int // Hz
+	Channels     int
+	BitDepth     int      // bits per sample
+	Samples      uint64   // per channel; 0 when the encoder didn't record it
+	MD5          [16]byte // of the decoded audio; all zero when the encoder didn't record it
+}
+
+// ParseStreamInfo decodes the payload of a STREAMINFO block.
+func ParseStreamInfo(data []byte) (StreamInfo, error) {
+	if len(data) < streamInfoLength {
+		return StreamInfo{}, errBadStreamInfo
+	}
+	si := StreamInfo{
+		MinBlockSize: int(binary.BigEndian.Uint16(data[0:2])),
+		MaxBlockSize: int(binary.BigEndian.Uint16(data[2:4])),
+		MinFrameSize: int(data[4])<<16 | int(data[5])<<8 | int(data[6]),
+		MaxFrameSize: int(data[7])<<16 | int(data[8])<<8 | int(data[9]),
+		SampleRate:   int(data[10])<<12 | int(data[11])<<4 | int(data[12])>>4,
+		Channels:     int(data[12]>>1&0x07) + 1,
+		BitDepth:     int(data[12]&0x01)<<4 | int(data[13]>>4) + 1,
+		Samples:      uint64(data[13]&0x0f)<<32 | uint64(data[14])<<24 | uint64(data[15])<<16 | uint64(data[16])<<8 | uint64(data[17]),
+	}
+	copy(si.MD5[:], data[18:34])
+	if si.SampleRate == 0 {
+		return StreamInfo{}, errBadStreamInfo
+	}
+	return si, nil
+}
+
+// Marshal encodes si as the payload of a STREAMINFO block, the inverse of
+// ParseStreamInfo. Zero channels or bit depth encode as the smallest the
+// format allows.
+func (si StreamInfo) Marshal() []byte {
+	data := make([]byte, streamInfoLength)
+	binary.BigEndian.PutUint16(data[0:2], uint16(si.MinBlockSize))
+	binary.BigEndian.PutUint16(data[2:4], uint16(si.MaxBlockSize))
+	data[4], data[5], data[6] = byte(si.MinFrameSize>>16), byte(si.MinFrameSize>>8), byte(si.MinFrameSize)
+	data[7], data[8], data[9] = byte(si.MaxFrameSize>>16), byte(si.MaxFrameSize>>8), byte(si.MaxFrameSize)
+	channels, bits := max(si.Channels-1, 0), max(si.BitDepth-1, 0)
+	packed := uint64(si.SampleRate&0xfffff)<<44 | uint64(channels&0x07)<<41 | uint64(bits&0x1f)<<36 | si.Samples&(1<<36-1)
+	binary.BigEndian.PutUint64(data[10:18], packed)
+	copy(data[18:34], si.MD5[:])
+	return data
+}
+
+// StreamInfo returns f's audio format.
+func (f *File) StreamInfo() (StreamInfo, error) {
+	return ParseStreamInfo(f.Blocks[0].Data) // Read guarantees STREAMINFO comes first
+}
+
+// Duration returns the play time STREAMINFO records: total samples over
+// the sample rate. It is 0 when the encoder didn't record a sample count.
+func (f *File) Duration() (time.Duration, error) {
+	si, err := f.StreamInfo()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(si.Samples * uint64(time.Second) / uint64(si.SampleRate)), nil
+}
+
+// ReadStreamInfo reads only the audio format of the FLAC file at path: the
+// first 42 bytes after any ID3 tag, rather than every metadata block as
+// Read does, which matters for listings of large libraries with embedded
+// covers.
+func ReadStreamInfo(path string) (StreamInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return StreamInfo{}, err
+	}
+	defer f.Close()
+	var head [4 + 4 + 34]byte
+	if _, err := io.ReadFull(f, head[:]); err != nil {
+		return StreamInfo{}, fmt.Errorf("%s: %w", path, ErrNotFLAC)
+	}
+	if n := id3Length(head[:]); n > 0 {
+		if _, err := f.ReadAt(head[:], n); err != nil {
+			return StreamInfo{}, fmt.Errorf("%s: %w", path, ErrNotFLAC)
+		}
+	}
+	if string(head[:4]) != "fLaC" {
+		return StreamInfo{}, fmt.Errorf("%s: %w", path, ErrNotFLAC)
+	}
+	if BlockType(head[4]&0x7f) != BlockStreamInfo {
+		return StreamInfo{}, fmt.Errorf("%s: first metadata block is not STREAMINFO: %w", path, ErrNotFLAC)
+	}
+	si, err := ParseStreamInfo(head[8:])
+	if err != nil {
+		return StreamInfo{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return si, nil
+}
@@ -0,0 +1,77 @@
+package flacmeta
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadStreamInfo(t *testing.T) {
+	info := make([]byte, 34)
+	// 4096-sample blocks, 14..9000-byte frames, 96000 Hz (0x17700),
+	// stereo, 24-bit, no sample count.
+	info[0], info[1], info[2], info[3] = 0x10, 0x00, 0x10, 0x00
+	info[6], info[7], info[8], info[9] = 14, 0x00, 0x23, 0x28
+	info[10], info[11], info[12], info[13] = 0x17, 0x70, 0x03, 0x70
+	info[18], info[33] = 0xab, 0xcd // first and last byte of the audio MD5
+	path := writeFixture(t, []Block{{Type: BlockPicture, Data: make([]byte, 1000)}}, nil)
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Blocks[0].Data = info
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadStreamInfo(path)
+	want := StreamInfo{MinBlockSize: 4096, MaxBlockSize: 4096, MinFrameSize: 14, MaxFrameSize: 9000, SampleRate: 96000, Channels: 2, BitDepth: 24}
+	want.MD5[0], want.MD5[15] = 0xab, 0xcd
+	if err != nil || got != want {
+		t.Errorf("ReadStreamInfo = %+v, %v; want %+v", got, err, want)
+	}
+	if fromFile, err := f.StreamInfo(); err != nil || fromFile != want {
+		t.Errorf("File.StreamInfo = %+v, %v", fromFile, err)
+	}
+
+	// writeFixture's STREAMINFO is all zeroes: no sample rate.
+	if _, err := ReadStreamInfo(writeFixture(t, nil, nil)); !errors.Is(err, errBadStreamInfo) {
+		t.Errorf("zero sample rate: err = %v", err)
+	}
+	notFLAC := filepath.Join(t.TempDir(), "x.flac")
+	os.WriteFile(notFLAC, []byte("ID3"), 0644)
+	if _, err := ReadStreamInfo(notFLAC); !errors.Is(err, ErrNotFLAC) {
+		t.Errorf("not a FLAC: err = %v", err)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	info := make([]byte, 34)
+	// 44100 Hz (0x0AC44), stereo, 16-bit, 441000 samples (0x6BAA8).
+	info[10], info[11], info[12] = 0x0A, 0xC4, 0x42
+	info[13], info[14], info[15], info[16], info[17] = 0xF0, 0x00, 0x06, 0xBA, 0xA8
+	f := &File{Blocks: []Block{{Type: BlockStreamInfo, Data: info}}}
+	got, err := f.Duration()
+	if err != nil || got != 10*time.Second {
+		t.Errorf("Duration() = %v, %v; want 10s", got, err)
+	}
+
+	f.Blocks[0].Data = make([]byte, 34)
+	if _, err := f.Duration(); err == nil {
+		t.Error("Duration() with no sample rate: want error")
+	}
+}
+
+func TestStreamInfoMarshal(t *testing.T) {
+	want := StreamInfo{MinBlockSize: 4096, MaxBlockSize: 4096, MinFrameSize: 14, MaxFrameSize: 9000, SampleRate: 96000, Channels: 2, BitDepth: 24, Samples: 1<<36 - 1}
+	want.MD5[0], want.MD5[15] = 0xab, 0xcd
+	data := want.Marshal()
+	if data[10] != 0x17 || data[11] != 0x70 || data[12]&0xf0 != 0 {
+		t.Errorf("sample rate bytes = % x", data[10:13])
+	}
+	if got, err := ParseStreamInfo(data); err != nil || got != want {
+		t.Errorf("round trip = %+v, %v; want %+v", got, err, want)
+	}
+}
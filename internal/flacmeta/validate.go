@@ -0,0 +1,75 @@
+package flacmeta
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrBroken is wrapped by the errors Validate returns.
+var ErrBroken = errors.New("broken FLAC")
+
+// streamInfoLength is the fixed size of a STREAMINFO block.
+const streamInfoLength = 34
+
+// Validate checks the structure of the FLAC file at path without decoding
+// it: the "fLaC" marker and metadata block chain, a sane STREAMINFO, audio
+// that starts with a frame header, and — when the encoder recorded the
+// sample count and smallest frame size — at least as much audio as those
+// require, which catches downloads cut off part-way. A nil error doesn't
+// prove every frame decodes; a non-nil one wraps ErrBroken.
+func Validate(path string) error {
+	f, err := Read(path)
+	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return err // missing or unreadable, not broken
+		}
+		return fmt.Errorf("%w: %w", ErrBroken, err)
+	}
+	if n := len(f.Blocks[0].Data); n != streamInfoLength {
+		return broken(path, "STREAMINFO is %d bytes, want %d", n, streamInfoLength)
+	}
+	info, err := f.StreamInfo()
+	if err != nil {
+		return broken(path, "%v", err)
+	}
+	minBlock, maxBlock := int64(info.MinBlockSize), int64(info.MaxBlockSize)
+	minFrame, samples := int64(info.MinFrameSize), int64(info.Samples)
+	if minBlock < 16 || maxBlock < minBlock {
+		return broken(path, "invalid block sizes %d-%d in STREAMINFO", minBlock, maxBlock)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	st, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	audio := st.Size() - f.AudioOffset()
+	var sync [2]byte
+	if _, err := file.ReadAt(sync[:], f.AudioOffset()); err == io.EOF {
+		return broken(path, "no audio after the metadata")
+	} else if err != nil {
+		return err
+	}
+	if sync[0] != 0xff || sync[1]&0xfe != 0xf8 {
+		return broken(path, "audio does not start with a frame header")
+	}
+	if samples > 0 && minFrame > 0 {
+		frames := (samples + maxBlock - 1) / maxBlock
+		if want := frames * minFrame; audio < want {
+			return broken(path, "truncated: %d bytes of audio, at least %d expected", audio, want)
+		}
+	}
+	return nil
+}
+
+// broken returns a Validate error for path.
+func broken(path, format string, args ...any) error {
+	return fmt.Errorf("%s: %w: %s", path, ErrBroken, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,67 @@
+package flacmeta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeStream writes a FLAC file whose STREAMINFO records 4096-sample
+// blocks, 44.1 kHz, the given sample count and smallest frame size, then
+// audio.
+func writeStream(t *testing.T, samples uint32, minFrame int, audio []byte) string {
+	t.Helper()
+	info := make([]byte, 34)
+	binary.BigEndian.PutUint16(info[0:2], 4096)
+	binary.BigEndian.PutUint16(info[2:4], 4096)
+	info[4], info[5], info[6] = byte(minFrame>>16), byte(minFrame>>8), byte(minFrame)
+	info[10], info[11], info[12] = 0x0a, 0xc4, 0x42 // 44100 Hz, 2 channels, 16 bits
+	binary.BigEndian.PutUint32(info[14:18], samples)
+	f := &File{Blocks: []Block{{Type: BlockStreamInfo, Data: info}}}
+	var buf bytes.Buffer
+	if _, err := f.writeMetadata(&buf); err != nil {
+		t.Fatal(err)
+	}
+	buf.Write(audio)
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	frames := append([]byte{0xff, 0xf8}, make([]byte, 398)...)
+	cases := []struct {
+		name   string
+		path   string
+		broken bool
+	}{
+		{"ok", writeStream(t, 8192, 200, frames), false},
+		{"unknown length", writeStream(t, 0, 0, frames[:10]), false},
+		{"no audio", writeStream(t, 8192, 200, nil), true},
+		{"no frame header", writeStream(t, 8192, 200, []byte("ID3 garbage")), true},
+		{"truncated", writeStream(t, 8192, 200, frames[:300]), true},
+		{"zero STREAMINFO", writeFixture(t, nil, frames), true},
+	}
+	for _, c := range cases {
+		err := Validate(c.path)
+		if got := errors.Is(err, ErrBroken); got != c.broken || (err != nil && !got) {
+			t.Errorf("%s: Validate = %v, want broken %v", c.name, err, c.broken)
+		}
+	}
+}
+
+func TestValidate_NotFLAC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.flac")
+	os.WriteFile(path, []byte("ID3\x04 not a flac"), 0644)
+	if err := Validate(path); !errors.Is(err, ErrBroken) || !errors.Is(err, ErrNotFLAC) {
+		t.Errorf("Validate = %v, want ErrBroken and ErrNotFLAC", err)
+	}
+	if err := Validate(filepath.Join(t.TempDir(), "missing.flac")); err == nil || errors.Is(err, ErrBroken) {
+		t.Errorf("missing file: Validate = %v, want a non-broken error", err)
+	}
+}
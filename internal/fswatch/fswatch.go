@@ -0,0 +1,168 @@
+// Package fswatch tells FLACidal's folder watchers (the library index and
+// the watch folder) when something changed in the folders they watch. It
+// listens for the OS's file notifications through fsnotify and falls back
+// to polling when they aren't available: on systems fsnotify doesn't
+// support, for folders that can't be watched (missing, or over the
+// inotify watch limit), and after the OS dropped events.
+package fswatch
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Options configure Run.
+type Options struct {
+	// Dirs returns the folders to watch. It is called every Poll, so
+	// settings changes apply without a restart; nil pauses watching.
+	Dirs func() []string
+
+	// Recursive watches the folders' subfolders too, those created later
+	// included.
+	Recursive bool
+
+	// Quiet is how long the folders must go without a change before fn
+	// runs, so a file still being written is seen once, finished.
+	Quiet time.Duration
+
+	// Poll is how often Dirs is re-read and, when a folder can't be
+	// watched, how often fn runs regardless.
+	Poll time.Duration
+}
+
+// Run calls fn once the folders opts.Dirs returns have changed and settled
+// (see Options.Quiet), or have kept changing for opts.Poll; when the list
+// of folders changes; and every opts.Poll while notifications aren't
+// available for all of them, until ctx is done. fn reports false to be
+// called again after opts.Quiet, when it couldn't look at the folders yet.
+// Calls to fn don't overlap.
+func Run(ctx context.Context, opts Options, fn func() bool) {
+	w := &watch{opts: opts}
+	defer w.close()
+	poll := time.NewTicker(opts.Poll)
+	defer poll.Stop()
+	settle := time.NewTimer(opts.Quiet)
+	settle.Stop()
+	defer settle.Stop()
+	var pending time.Time // first change fn hasn't seen yet
+
+	changed := func() {
+		if pending.IsZero() {
+			pending = time.Now()
+		}
+		if time.Since(pending) >= opts.Poll {
+			settle.Reset(0) // don't wait forever on a folder that never settles
+		} else {
+			settle.Reset(opts.Quiet)
+		}
+	}
+	w.sync()
+	for {
+		var events <-chan fsnotify.Event
+		var errs <-chan error
+		if w.w != nil {
+			events, errs = w.w.Events, w.w.Errors
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			if w.sync() || w.polling {
+				settle.Reset(0)
+			}
+		case ev, ok := <-events:
+			if !ok {
+				w.w, w.polling = nil, true
+				continue
+			}
+			if opts.Recursive && ev.Has(fsnotify.Create) {
+				w.addTree(ev.Name) // a new folder; for a file it does nothing
+			}
+			changed()
+		case _, ok := <-errs:
+			if !ok {
+				w.w, w.polling = nil, true
+				continue
+			}
+			// Events were dropped (e.g. the queue overflowed): look everywhere
+			changed()
+		case <-settle.C:
+			pending = time.Time{}
+			if !fn() {
+				settle.Reset(opts.Quiet)
+			}
+		}
+	}
+}
+
+// watch is Run's fsnotify watcher for the current folders.
+type watch struct {
+	opts    Options
+	dirs    []string
+	w       *fsnotify.Watcher
+	polling bool // some folder has no notifications
+}
+
+// sync re-reads the folders and watches them from scratch when the list
+// changed, or some couldn't be watched last time. It reports whether the
+// list changed, i.e. the folders must be looked at afresh.
+func (w *watch) sync() bool {
+	dirs := w.opts.Dirs()
+	changed := !slices.Equal(dirs, w.dirs)
+	if !changed && !w.polling {
+		return false
+	}
+	w.close()
+	w.dirs, w.polling = dirs, false
+	if len(dirs) == 0 {
+		return changed
+	}
+	nw, err := fsnotify.NewWatcher()
+	if err != nil {
+		w.polling = true
+		return changed
+	}
+	w.w = nw
+	for _, d := range dirs {
+		if !w.addTree(d) {
+			w.polling = true
+		}
+	}
+	return changed
+}
+
+// addTree watches dir and, with Options.Recursive, its subfolders. It
+// reports whether all of them could be watched.
+func (w *watch) addTree(dir string) bool {
+	if w.w == nil {
+		return false
+	}
+	if !w.opts.Recursive {
+		return w.w.Add(dir) == nil
+	}
+	ok := true
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			ok = false
+			return nil
+		}
+		if d.IsDir() && w.w.Add(path) != nil {
+			ok = false
+		}
+		return nil
+	})
+	return ok && err == nil
+}
+
+// close stops watching the current folders.
+func (w *watch) close() {
+	if w.w != nil {
+		w.w.Close() //nolint:errcheck // nothing to do about it
+		w.w = nil
+	}
+}
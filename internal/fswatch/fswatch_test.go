@@ -0,0 +1,72 @@
+package fswatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// wait fails the test unless calls receives within a few seconds.
+func wait(t *testing.T, calls <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-calls:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("fn not called after %s", what)
+	}
+}
+
+func TestRun_Notifies(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := make(chan struct{}, 16)
+	ready := make(chan struct{})
+	go Run(ctx, Options{
+		Dirs: func() []string {
+			select {
+			case <-ready:
+			default:
+				close(ready)
+			}
+			return []string{dir}
+		},
+		Recursive: true,
+		Quiet:     20 * time.Millisecond,
+		Poll:      time.Hour,
+	}, func() bool {
+		calls <- struct{}{}
+		return true
+	})
+	<-ready
+	time.Sleep(50 * time.Millisecond) // let the watches be added
+
+	os.WriteFile(filepath.Join(dir, "a.flac"), []byte("x"), 0644)
+	wait(t, calls, "a new file")
+
+	sub := filepath.Join(dir, "Artist")
+	os.Mkdir(sub, 0755)
+	wait(t, calls, "a new folder")
+	time.Sleep(50 * time.Millisecond)
+	os.WriteFile(filepath.Join(sub, "b.flac"), []byte("x"), 0644)
+	wait(t, calls, "a new file in a new folder")
+}
+
+func TestRun_PollsWhatCantBeWatched(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := make(chan struct{}, 16)
+	go Run(ctx, Options{
+		Dirs:  func() []string { return []string{missing} },
+		Quiet: 10 * time.Millisecond,
+		Poll:  20 * time.Millisecond,
+	}, func() bool {
+		calls <- struct{}{}
+		return true
+	})
+	wait(t, calls, "a poll")
+	wait(t, calls, "another poll")
+}
@@ -0,0 +1,29 @@
+package history
+
+import "time"
+
+// Heatmap counts downloads by day of the week and hour of the day.
+type Heatmap struct {
+	Days   int        `json:"days"`   // the period counted, in days; 0 is all time
+	Counts [7][24]int `json:"counts"` // [weekday][hour], Sunday first as in time.Weekday
+	ByDay  [7]int     `json:"byDay"`
+	ByHour [24]int    `json:"byHour"`
+	Total  int        `json:"total"`
+	Max    int        `json:"max"` // the largest cell of Counts, for scaling colors
+}
+
+// BuildHeatmap buckets times by their weekday and hour in loc, over a
+// period of days (0 for all time).
+func BuildHeatmap(times []time.Time, days int, loc *time.Location) Heatmap {
+	h := Heatmap{Days: days}
+	for _, t := range times {
+		t = t.In(loc)
+		day, hour := int(t.Weekday()), t.Hour()
+		h.Counts[day][hour]++
+		h.ByDay[day]++
+		h.ByHour[hour]++
+		h.Total++
+		h.Max = max(h.Max, h.Counts[day][hour])
+	}
+	return h
+}
@@ -0,0 +1,22 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildHeatmap(t *testing.T) {
+	// Sunday 2026-01-04 23:30 UTC is Monday 00:30 in UTC+1.
+	sunday := time.Date(2026, 1, 4, 23, 30, 0, 0, time.UTC)
+	times := []time.Time{sunday, sunday.Add(10 * time.Minute), sunday.Add(-3 * time.Hour)}
+	h := BuildHeatmap(times, 30, time.UTC)
+	if h.Days != 30 || h.Total != 3 || h.Counts[time.Sunday][23] != 2 || h.Counts[time.Sunday][20] != 1 || h.Max != 2 {
+		t.Errorf("UTC heatmap = %+v", h)
+	}
+	if h.ByDay[time.Sunday] != 3 || h.ByHour[23] != 2 {
+		t.Errorf("ByDay/ByHour = %v/%v", h.ByDay, h.ByHour)
+	}
+	if h := BuildHeatmap(times, 0, time.FixedZone("UTC+1", 3600)); h.Counts[time.Monday][0] != 2 {
+		t.Errorf("UTC+1 heatmap = %v", h.Counts)
+	}
+}
@@ -0,0 +1,176 @@
+// Package history records what flacidal-core's download history leaves
+// out. Its DownloadRecord only keeps a content ID, which FLACidal used to
+// turn back into a tidal.com URL; content from other sources could not be
+// refetched. Origins keeps the source and URL of each queued batch in
+// history_origins.json, next to core's database, with a snapshot of its
+// title, creator, description and, for playlists, cover, so the history
+// still shows them once the source deletes the content. Timings keeps
+// each track's download state transitions and timings, which core's
+// per-track history doesn't record, in history_timings.json. BuildHeatmap
+// turns the per-track history's times into the activity heatmap.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileName is the origins file name inside the data directory.
+const FileName = "history_origins.json"
+
+// maxPending bounds the fetched-but-not-queued origins kept in memory.
+const maxPending = 256
+
+// Origin is where a history entry's content was fetched from.
+type Origin struct {
+	Source      string `json:"source"`      // source name, e.g. "tidal" or "qobuz"
+	URL         string `json:"url"`         // URL the content was fetched from
+	ContentType string `json:"contentType"` // "album", "playlist", "track"...
+
+	Snapshot Snapshot `json:"snapshot,omitzero"`
+}
+
+// Key returns the history content ID for content id from source. Tidal IDs
+// are used as is, as they always have been; other sources are prefixed so
+// their IDs can't collide with Tidal's.
+func Key(source, id string) string {
+	if source == "" || source == "tidal" {
+		return id
+	}
+	return source + ":" + id
+}
+
+// Origins is a concurrency-safe, persisted map from history content ID to
+// Origin. Content is noted when fetched and committed once it is queued, so
+// browsing doesn't grow the file. A nil *Origins records nothing.
+type Origins struct {
+	// FetchCover downloads the image at a cover URL; Commit archives the
+	// covers of playlists with it. Nil archives none.
+	FetchCover func(rawURL string) ([]byte, error)
+
+	dir string
+
+	mu        sync.Mutex
+	saved     map[string]Origin
+	pending   map[string]Origin
+	archiving sync.WaitGroup
+}
+
+// Open loads the origins in dir. A missing file is not an error. On a read
+// error the returned Origins is empty but usable.
+func Open(dir string) (*Origins, error) {
+	o := &Origins{dir: dir, saved: map[string]Origin{}, pending: map[string]Origin{}}
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return o, err
+	}
+	if err := json.Unmarshal(data, &o.saved); err != nil {
+		o.saved = map[string]Origin{}
+		return o, err
+	}
+	return o, nil
+}
+
+// Note remembers origin for key until Commit; nothing is written yet.
+func (o *Origins) Note(key string, origin Origin) {
+	if o == nil || key == "" {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.pending) >= maxPending {
+		clear(o.pending)
+	}
+	o.pending[key] = origin
+}
+
+// Commit persists the origin noted for key, if any. A playlist's cover is
+// then archived in the background, unless the one archived before is of
+// the same URL.
+func (o *Origins) Commit(key string) error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	origin, ok := o.pending[key]
+	if !ok {
+		return nil
+	}
+	delete(o.pending, key)
+	old, ok := o.saved[key]
+	if ok && old.Snapshot.CoverURL == origin.Snapshot.CoverURL {
+		origin.Snapshot.Cover = old.Snapshot.Cover
+	}
+	if ok && old == origin {
+		return nil
+	}
+	o.saved[key] = origin
+	if err := o.save(); err != nil {
+		return err
+	}
+	if archived[origin.ContentType] && origin.Snapshot.CoverURL != "" && origin.Snapshot.Cover == "" && o.FetchCover != nil {
+		o.archiving.Add(1)
+		go o.archiveCover(key, origin.Snapshot.CoverURL)
+	}
+	return nil
+}
+
+// Get returns the committed origin for key.
+func (o *Origins) Get(key string) (Origin, bool) {
+	if o == nil {
+		return Origin{}, false
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	origin, ok := o.saved[key]
+	return origin, ok
+}
+
+// Delete forgets key.
+func (o *Origins) Delete(key string) error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	origin, ok := o.saved[key]
+	if !ok {
+		return nil
+	}
+	delete(o.saved, key)
+	if origin.Snapshot.Cover != "" {
+		os.Remove(filepath.Join(o.dir, CoversDirName, origin.Snapshot.Cover))
+	}
+	return o.save()
+}
+
+// Clear forgets every origin, alongside clearing the download history.
+func (o *Origins) Clear() error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	clear(o.saved)
+	os.RemoveAll(filepath.Join(o.dir, CoversDirName))
+	return o.save()
+}
+
+// save writes o.saved; callers hold o.mu.
+func (o *Origins) save() error {
+	if err := os.MkdirAll(o.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(o.saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(o.dir, FileName), data, 0644)
+}
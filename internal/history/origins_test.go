@@ -0,0 +1,86 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	if got := Key("tidal", "123"); got != "123" {
+		t.Errorf("Key(tidal) = %q, want plain id", got)
+	}
+	if got := Key("qobuz", "123"); got != "qobuz:123" {
+		t.Errorf("Key(qobuz) = %q", got)
+	}
+}
+
+func TestOrigins_CommitPersists(t *testing.T) {
+	dir := t.TempDir()
+	o, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	want := Origin{Source: "qobuz", URL: "https://open.qobuz.com/album/abc", ContentType: "album"}
+	o.Note("qobuz:abc", want)
+	if _, ok := o.Get("qobuz:abc"); ok {
+		t.Error("noted origin visible before Commit")
+	}
+	if err := o.Commit("qobuz:abc"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if err := o.Commit("never-noted"); err != nil {
+		t.Errorf("Commit of unknown key: %v", err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got, ok := reopened.Get("qobuz:abc"); !ok || got != want {
+		t.Errorf("Get after reopen = %+v, %v", got, ok)
+	}
+
+	if err := reopened.Delete("qobuz:abc"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := reopened.Get("qobuz:abc"); ok {
+		t.Error("origin still present after Delete")
+	}
+}
+
+func TestOrigins_Clear(t *testing.T) {
+	o, _ := Open(t.TempDir())
+	o.Note("1", Origin{Source: "tidal"})
+	o.Commit("1")
+	if err := o.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if _, ok := o.Get("1"); ok {
+		t.Error("origin present after Clear")
+	}
+}
+
+func TestOrigins_CorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, FileName), []byte("{"), 0644)
+	o, err := Open(dir)
+	if err == nil {
+		t.Error("expected a parse error")
+	}
+	o.Note("1", Origin{Source: "tidal"})
+	if err := o.Commit("1"); err != nil {
+		t.Errorf("Origins unusable after a read error: %v", err)
+	}
+}
+
+func TestOrigins_Nil(t *testing.T) {
+	var o *Origins
+	o.Note("1", Origin{})
+	if err := o.Commit("1"); err != nil {
+		t.Error(err)
+	}
+	if _, ok := o.Get("1"); ok {
+		t.Error("nil Origins returned an origin")
+	}
+}
@@ -0,0 +1,114 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// CoversDirName is the folder inside the data directory where playlist
+// covers are archived.
+const CoversDirName = "history_covers"
+
+// archived are the content types whose covers Commit archives. Playlists
+// and mixes are edited and deleted at the source; an album's cover is
+// saved with its tracks.
+var archived = map[string]bool{"playlist": true, "mix": true}
+
+// Snapshot is what content looked like when it was queued.
+type Snapshot struct {
+	Title       string `json:"title,omitempty"`
+	Creator     string `json:"creator,omitempty"`
+	Description string `json:"description,omitempty"`
+	CoverURL    string `json:"coverUrl,omitempty"` // the source's
+	Cover       string `json:"cover,omitempty"`    // the archived copy, in CoversDirName
+}
+
+// Describe adds what the content noted for key looked like once it was
+// fetched; Commit persists it with the origin.
+func (o *Origins) Describe(key string, s Snapshot) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if origin, ok := o.pending[key]; ok {
+		origin.Snapshot = s
+		o.pending[key] = origin
+	}
+}
+
+// Snapshots returns the committed origins' snapshots, by key. Origins
+// saved before snapshots were kept have none.
+func (o *Origins) Snapshots() map[string]Snapshot {
+	snaps := map[string]Snapshot{}
+	if o == nil {
+		return snaps
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for key, origin := range o.saved {
+		if origin.Snapshot != (Snapshot{}) {
+			snaps[key] = origin.Snapshot
+		}
+	}
+	return snaps
+}
+
+// Cover returns key's archived cover and its MIME type, or an error
+// matching os.ErrNotExist when it has none.
+func (o *Origins) Cover(key string) ([]byte, string, error) {
+	origin, _ := o.Get(key)
+	if origin.Snapshot.Cover == "" {
+		return nil, "", os.ErrNotExist
+	}
+	data, err := os.ReadFile(filepath.Join(o.dir, CoversDirName, origin.Snapshot.Cover))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, http.DetectContentType(data), nil
+}
+
+// archiveCover downloads the cover at rawURL into CoversDirName and records
+// it on key's origin. It is best-effort: when it fails, the history shows
+// the source's cover for as long as the source has it.
+func (o *Origins) archiveCover(key, rawURL string) {
+	defer o.archiving.Done()
+	data, err := o.FetchCover(rawURL)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:8]) + coverExt(data)
+	dir := filepath.Join(o.dir, CoversDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	origin, ok := o.saved[key]
+	if !ok || origin.Snapshot.CoverURL != rawURL {
+		return // deleted, or queued again with another cover, meanwhile
+	}
+	origin.Snapshot.Cover = name
+	o.saved[key] = origin
+	o.save() //nolint:errcheck // kept in memory, and written with the next change
+}
+
+// coverExt returns the file extension of the image in data.
+func coverExt(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
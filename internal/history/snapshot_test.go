@@ -0,0 +1,77 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+func TestOrigins_SnapshotArchivesPlaylistCover(t *testing.T) {
+	dir := t.TempDir()
+	o, _ := Open(dir)
+	fetches := 0
+	o.FetchCover = func(rawURL string) ([]byte, error) {
+		fetches++
+		return png, nil
+	}
+	snap := Snapshot{Title: "Mix", Creator: "me", Description: "Late night", CoverURL: "https://resources.tidal.com/images/a.jpg"}
+	o.Note("p1", Origin{Source: "tidal", ContentType: "playlist"})
+	o.Describe("p1", snap)
+	o.Note("a1", Origin{Source: "tidal", ContentType: "album"})
+	o.Describe("a1", Snapshot{Title: "Album", CoverURL: "https://resources.tidal.com/images/b.jpg"})
+	o.Describe("never-noted", snap)
+	o.Commit("p1")
+	o.Commit("a1")
+	o.archiving.Wait()
+
+	if fetches != 1 {
+		t.Errorf("covers fetched = %d, want only the playlist's", fetches)
+	}
+	data, mimeType, err := o.Cover("p1")
+	if err != nil || string(data) != string(png) || mimeType != "image/png" {
+		t.Fatalf("Cover = %d bytes, %q, %v", len(data), mimeType, err)
+	}
+	if _, _, err := o.Cover("a1"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("album cover: err = %v, want not exist", err)
+	}
+
+	reopened, _ := Open(dir)
+	snaps := reopened.Snapshots()
+	if got := snaps["p1"]; got.Description != "Late night" || got.Cover == "" || len(snaps) != 2 {
+		t.Errorf("Snapshots after reopen = %+v", snaps)
+	}
+
+	// Queued again with the same cover: the archived copy is kept.
+	o.Note("p1", Origin{Source: "tidal", ContentType: "playlist"})
+	o.Describe("p1", snap)
+	o.Commit("p1")
+	o.archiving.Wait()
+	if got, _ := o.Get("p1"); fetches != 1 || got.Snapshot.Cover == "" {
+		t.Errorf("requeue: fetches = %d, snapshot %+v", fetches, got.Snapshot)
+	}
+
+	cover := filepath.Join(dir, CoversDirName, snaps["p1"].Cover)
+	if err := o.Delete("p1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cover); !os.IsNotExist(err) {
+		t.Error("archived cover kept after Delete")
+	}
+}
+
+func TestOrigins_SnapshotFetchFails(t *testing.T) {
+	o, _ := Open(t.TempDir())
+	o.FetchCover = func(string) ([]byte, error) { return nil, errors.New("gone") }
+	o.Note("p1", Origin{ContentType: "playlist"})
+	o.Describe("p1", Snapshot{Title: "Mix", CoverURL: "https://resources.tidal.com/images/a.jpg"})
+	if err := o.Commit("p1"); err != nil {
+		t.Fatal(err)
+	}
+	o.archiving.Wait()
+	if got := o.Snapshots()["p1"]; got.Title != "Mix" || got.Cover != "" {
+		t.Errorf("snapshot = %+v", got)
+	}
+}
@@ -0,0 +1,120 @@
+package history
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+
+	"flacidal/internal/downloads"
+)
+
+// TimingsFileName is the timings file name inside the data directory.
+const TimingsFileName = "history_timings.json"
+
+// MaxTimings is how many downloads Timings keeps before dropping the ones
+// that finished first.
+const MaxTimings = 2000
+
+// Timings is a concurrency-safe, persisted map from track ID to the state
+// transitions and timings of the track's latest finished download, as
+// downloads.Tracker reported them. The Tracker forgets old jobs and every
+// restart; Timings keeps them with the per-track history. A nil *Timings
+// records nothing.
+type Timings struct {
+	dir string
+
+	mu    sync.Mutex
+	saved map[string]downloads.Job
+}
+
+// OpenTimings loads the timings in dir. A missing file is not an error. On
+// a read error the returned Timings is empty but usable.
+func OpenTimings(dir string) (*Timings, error) {
+	t := &Timings{dir: dir, saved: map[string]downloads.Job{}}
+	data, err := os.ReadFile(filepath.Join(dir, TimingsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(data, &t.saved); err != nil {
+		t.saved = map[string]downloads.Job{}
+		return t, err
+	}
+	return t, nil
+}
+
+// Record persists job if it finished; jobs still queued or downloading are
+// ignored.
+func (t *Timings) Record(job downloads.Job) error {
+	if t == nil || !job.State.Terminal() || len(job.Transitions) == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.saved[strconv.Itoa(job.ID)] = job
+	if len(t.saved) > MaxTimings {
+		t.evict()
+	}
+	return t.save()
+}
+
+// All returns every recorded download, by track ID.
+func (t *Timings) All() map[string]downloads.Job {
+	all := map[string]downloads.Job{}
+	if t == nil {
+		return all
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, job := range t.saved {
+		all[id] = job
+	}
+	return all
+}
+
+// Clear forgets every download, alongside clearing the download history.
+func (t *Timings) Clear() error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.saved)
+	return t.save()
+}
+
+// evict drops the downloads that finished first down to MaxTimings;
+// callers hold t.mu.
+func (t *Timings) evict() {
+	ids := make([]string, 0, len(t.saved))
+	for id := range t.saved {
+		ids = append(ids, id)
+	}
+	finished := func(id string) int64 {
+		trs := t.saved[id].Transitions
+		return trs[len(trs)-1].At.UnixNano()
+	}
+	slices.SortFunc(ids, func(a, b string) int { return cmp.Compare(finished(a), finished(b)) })
+	for _, id := range ids[:len(ids)-MaxTimings] {
+		delete(t.saved, id)
+	}
+}
+
+// save writes t.saved; callers hold t.mu.
+func (t *Timings) save() error {
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(t.saved)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(t.dir, TimingsFileName), data, 0644)
+}
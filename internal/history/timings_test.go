@@ -0,0 +1,75 @@
+package history
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"flacidal/internal/downloads"
+)
+
+func TestTimings_RecordPersists(t *testing.T) {
+	dir := t.TempDir()
+	tm, err := OpenTimings(dir)
+	if err != nil {
+		t.Fatalf("OpenTimings: %v", err)
+	}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	job := func(id int, finished time.Time) downloads.Job {
+		return downloads.Job{ID: id, State: downloads.Completed, Attempts: 1, QueueTimeMs: 1000, DownloadTimeMs: 2000, Transitions: []downloads.Transition{
+			{State: downloads.Queued, At: finished.Add(-3 * time.Second)},
+			{State: downloads.Downloading, At: finished.Add(-2 * time.Second)},
+			{State: downloads.Completed, At: finished},
+		}}
+	}
+	if err := tm.Record(job(7, start)); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	running := job(8, start)
+	running.State = downloads.Downloading
+	tm.Record(running)
+
+	reopened, err := OpenTimings(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	all := reopened.All()
+	if got, ok := all["7"]; !ok || got.DownloadTimeMs != 2000 || len(got.Transitions) != 3 || !got.Transitions[2].At.Equal(start) {
+		t.Errorf("after reopen: %+v", all)
+	}
+	if _, ok := all["8"]; ok {
+		t.Error("a download in progress was recorded")
+	}
+
+	if err := reopened.Clear(); err != nil || len(reopened.All()) != 0 {
+		t.Errorf("Clear: %v, %d left", err, len(reopened.All()))
+	}
+	var none *Timings
+	if none.Record(job(1, start)) != nil || len(none.All()) != 0 {
+		t.Error("nil Timings recorded something")
+	}
+}
+
+func TestTimings_DropsFirstFinished(t *testing.T) {
+	tm, _ := OpenTimings(t.TempDir())
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	failed := func(id int, at time.Time) downloads.Job {
+		return downloads.Job{ID: id, State: downloads.Failed, Transitions: []downloads.Transition{{State: downloads.Failed, At: at}}}
+	}
+	for id := range MaxTimings {
+		tm.saved[strconv.Itoa(id)] = failed(id, start.Add(time.Duration(id)*time.Second))
+	}
+	tm.saved["0"] = failed(0, start.Add(time.Hour)) // finished last, though recorded first
+	tm.Record(failed(MaxTimings, start.Add(time.Minute)))
+
+	all := tm.All()
+	if len(all) != MaxTimings {
+		t.Fatalf("%d recorded, want %d", len(all), MaxTimings)
+	}
+	if _, ok := all["0"]; !ok {
+		t.Error("dropped the download that finished last")
+	}
+	if _, ok := all["1"]; ok {
+		t.Error("kept the download that finished first")
+	}
+}
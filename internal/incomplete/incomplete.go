@@ -0,0 +1,212 @@
+// Package incomplete finds what interrupted downloads leave behind in the
+// library folders — partial files (.part, .tmp and the like) and zero-byte
+// FLACs — and removes them once they are old enough that no download can
+// still be writing them.
+package incomplete
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"flacidal/internal/textmatch"
+)
+
+// Kinds of incomplete file.
+const (
+	KindPartial = "partial" // a download or tag rewrite's temporary file
+	KindEmpty   = "empty"   // a zero-byte FLAC
+)
+
+// partialExts are the extensions downloaders and FLACidal's own rewrites
+// give files that aren't finished yet.
+var partialExts = map[string]bool{
+	".part":       true,
+	".partial":    true,
+	".tmp":        true,
+	".download":   true,
+	".crdownload": true,
+}
+
+// File is one incomplete file.
+type File struct {
+	Path    string    `json:"path"`
+	Root    string    `json:"root"` // library folder it was found under
+	Kind    string    `json:"kind"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	TrackID int       `json:"trackId,omitempty"` // failed download it belongs to, see Match
+	Track   string    `json:"track,omitempty"`   // that download's "Artist - Title"
+}
+
+// Classify returns the kind of incomplete file name is, given its size, or
+// "" for a file that isn't one.
+func Classify(name string, size int64) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	switch {
+	case partialExts[ext]:
+		return KindPartial
+	case ext == ".flac" && size == 0:
+		return KindEmpty
+	}
+	return ""
+}
+
+// Scan walks roots for incomplete files, oldest first. Folders that can't
+// be read are skipped; a missing root is reported in the error, but the
+// other roots are still scanned. A root inside another is only walked once.
+func Scan(roots []string) ([]File, error) {
+	var files []File
+	var errs []error
+	seen := map[string]bool{}
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == root {
+					return err
+				}
+				return nil // unreadable subfolder
+			}
+			if d.IsDir() || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if kind := Classify(d.Name(), info.Size()); kind != "" {
+				files = append(files, File{Path: path, Root: root, Kind: kind, Size: info.Size(), ModTime: info.ModTime().UTC()})
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].ModTime.Before(files[j].ModTime) })
+	return files, errors.Join(errs...)
+}
+
+// Stem returns a file's name without its partial and .flac extensions:
+// "Artist - Title.flac.part" gives "Artist - Title".
+func Stem(path string) string {
+	name := filepath.Base(path)
+	for {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".flac" && !partialExts[ext] {
+			return name
+		}
+		name = name[:len(name)-len(ext)]
+	}
+}
+
+// Candidate is a failed download an incomplete file may be left from.
+type Candidate struct {
+	TrackID int
+	Artist  string
+	Title   string
+}
+
+// Match links files to the failed downloads they were probably left by:
+// the first candidate whose title, and artist when known, both appear in
+// the file's name, compared as textmatch does (ignoring case and accents).
+// Unmatched files keep TrackID 0.
+func Match(files []File, candidates []Candidate) {
+	for i := range files {
+		stem := Stem(files[i].Path)
+		for _, c := range candidates {
+			if textmatch.Normalize(c.Title, textmatch.Default) == "" ||
+				!textmatch.Contains(stem, c.Title, textmatch.Default) || !textmatch.Contains(stem, c.Artist, textmatch.Default) {
+				continue
+			}
+			files[i].TrackID = c.TrackID
+			files[i].Track = c.Title
+			if c.Artist != "" {
+				files[i].Track = c.Artist + " - " + c.Title
+			}
+			break
+		}
+	}
+}
+
+// Within reports whether path is inside one of roots. Only files there are
+// ever deleted on a client's say-so.
+func Within(roots []string, path string) bool {
+	path = filepath.Clean(path)
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Clean(root), path)
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") && !filepath.IsAbs(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove deletes the incomplete file at path, refusing anything outside
+// roots or anything Classify doesn't consider incomplete.
+func Remove(roots []string, path string) error {
+	if !Within(roots, path) {
+		return errors.New("not in a library folder")
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() || Classify(path, info.Size()) == "" {
+		return errors.New("not an incomplete download")
+	}
+	return os.Remove(path)
+}
+
+// Cleanup deletes the files last modified more than maxAge before now and
+// returns the paths it deleted. A file that can't be deleted is reported in
+// the error; the others are still deleted.
+func Cleanup(files []File, maxAge time.Duration, now time.Time) ([]string, error) {
+	var removed []string
+	var errs []error
+	for _, f := range files {
+		if now.Sub(f.ModTime) <= maxAge {
+			continue
+		}
+		if err := os.Remove(f.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, f.Path)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// Run cleans up roots() right away and then every interval until ctx is
+// done, deleting incomplete files older than maxAge(). Both are re-read on
+// every run so settings changes apply without a restart; a zero maxAge
+// disables cleanup. Each run that deletes something or fails is reported
+// to report.
+func Run(ctx context.Context, interval time.Duration, roots func() []string, maxAge func() time.Duration, report func(removed []string, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	now := time.Now()
+	for {
+		if age := maxAge(); age > 0 {
+			files, err := Scan(roots())
+			removed, cerr := Cleanup(files, age, now)
+			if err = errors.Join(err, cerr); (len(removed) > 0 || err != nil) && report != nil {
+				report(removed, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case now = <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package incomplete
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func write(t *testing.T, path string, data string, age time.Duration) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mod := time.Now().Add(-age)
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, "Artist", "Album", "01 - Song.flac.part"), "abc", time.Hour)
+	write(t, filepath.Join(root, "Artist", "Album", "02 - Empty.flac"), "", 2*time.Hour)
+	write(t, filepath.Join(root, "Artist", "Album", "03 - Done.flac"), "fLaC", 0)
+	write(t, filepath.Join(root, ".flacmeta-123.tmp"), "x", 0)
+
+	files, err := Scan([]string{root, filepath.Join(root, "Artist"), filepath.Join(root, "missing")})
+	if err == nil {
+		t.Error("missing root not reported")
+	}
+	if len(files) != 3 {
+		t.Fatalf("files = %+v, want 3 (nested root walked once)", files)
+	}
+	if files[0].Kind != KindEmpty || files[1].Kind != KindPartial || files[1].Size != 3 || files[0].Root != root {
+		t.Errorf("files = %+v, want oldest first", files)
+	}
+}
+
+func TestStemAndMatch(t *testing.T) {
+	if got := Stem("/x/Artist - Song.FLAC.part"); got != "Artist - Song" {
+		t.Errorf("Stem = %q", got)
+	}
+	files := []File{{Path: "/x/Artist - Song.flac.part"}, {Path: "/x/Other.tmp"}}
+	Match(files, []Candidate{{TrackID: 1, Artist: "Nobody", Title: "Song"}, {TrackID: 2, Artist: "artist", Title: "song"}})
+	if files[0].TrackID != 2 || files[0].Track != "artist - song" || files[1].TrackID != 0 {
+		t.Errorf("files = %+v", files)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	root := t.TempDir()
+	part := filepath.Join(root, "a.flac.part")
+	done := filepath.Join(root, "b.flac")
+	write(t, part, "x", 0)
+	write(t, done, "fLaC", 0)
+
+	if err := Remove([]string{root}, done); err == nil {
+		t.Error("Remove deleted a finished FLAC")
+	}
+	if err := Remove([]string{filepath.Join(root, "sub")}, part); err == nil {
+		t.Error("Remove deleted a file outside the roots")
+	}
+	if err := Remove([]string{root}, part); err != nil {
+		t.Errorf("Remove: %v", err)
+	}
+	if _, err := os.Stat(part); !os.IsNotExist(err) {
+		t.Error("partial file still there")
+	}
+}
+
+func TestWithin(t *testing.T) {
+	if Within([]string{"/music"}, "/music2/a.part") || Within([]string{"/music"}, "/music/../etc/x.tmp") || Within([]string{"/music"}, "/music") {
+		t.Error("Within accepted a path outside the root")
+	}
+	if !Within([]string{"", "/music"}, "/music/a/b.part") {
+		t.Error("Within rejected a path inside the root")
+	}
+}
+
+func TestRun_CleansOldFiles(t *testing.T) {
+	root := t.TempDir()
+	old := filepath.Join(root, "old.flac.part")
+	fresh := filepath.Join(root, "fresh.flac.part")
+	write(t, old, "x", 72*time.Hour)
+	write(t, fresh, "x", time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var removed []string
+	Run(ctx, time.Hour, func() []string { return []string{root} }, func() time.Duration {
+		defer cancel() // one run only
+		return 48 * time.Hour
+	}, func(r []string, err error) {
+		removed = r
+		if err != nil {
+			t.Errorf("cleanup: %v", err)
+		}
+	})
+	if len(removed) != 1 || removed[0] != old {
+		t.Errorf("removed = %v, want only the old file", removed)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Error("fresh partial file was deleted")
+	}
+}
@@ -0,0 +1,176 @@
+// Package inventory reports every FLAC under a folder with its main tags,
+// audio format, length and size, as CSV or JSON, to catalogue a library in
+// a spreadsheet or another tool.
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/quality"
+	"flacidal/internal/timestamp"
+)
+
+// Formats are the report encodings Encode accepts.
+var Formats = []string{"csv", "json"}
+
+// Report lists the FLACs found under Folder.
+type Report struct {
+	Folder    string  `json:"folder"`
+	CreatedAt string  `json:"createdAt"` // UTC RFC3339, see internal/timestamp
+	Files     []Entry `json:"files"`
+}
+
+// Entry is one file. Files whose metadata can't be read are listed with
+// their path, size and Error.
+type Entry struct {
+	Path       string          `json:"path"`
+	Title      string          `json:"title"`
+	Artist     string          `json:"artist"`
+	Album      string          `json:"album"`
+	Year       string          `json:"year,omitempty"`
+	ISRC       string          `json:"isrc,omitempty"`
+	Quality    quality.Quality `json:"quality,omitempty"` // see quality.Tier
+	SampleRate int             `json:"sampleRate,omitempty"`
+	BitDepth   int             `json:"bitDepth,omitempty"`
+	Duration   float64         `json:"duration"` // seconds
+	Size       int64           `json:"size"`     // bytes
+	Error      string          `json:"error,omitempty"`
+}
+
+// Read returns the entry for the FLAC at path, whose size is size.
+func Read(path string, size int64) Entry {
+	e := Entry{Path: path, Size: size}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		e.Error = err.Error()
+		return e
+	}
+	if si, err := f.StreamInfo(); err == nil {
+		e.SampleRate, e.BitDepth = si.SampleRate, si.BitDepth
+		e.Quality = quality.Tier(si.BitDepth, si.SampleRate)
+	}
+	if d, err := f.Duration(); err == nil {
+		e.Duration = d.Round(time.Millisecond).Seconds()
+	}
+	c, err := f.Comments()
+	if err != nil {
+		e.Error = err.Error()
+		return e
+	}
+	e.Title, e.Artist, e.Album = c.Get("TITLE"), strings.Join(c.GetAll("ARTIST"), "; "), c.Get("ALBUM")
+	e.ISRC = c.Get("ISRC")
+	e.Year = year(c.Get("DATE"))
+	if e.Year == "" {
+		e.Year = year(c.Get("YEAR"))
+	}
+	return e
+}
+
+// year returns the year a DATE tag starts with ("2021-03-05" → "2021"),
+// or "" if it doesn't.
+func year(date string) string {
+	if len(date) < 4 {
+		return ""
+	}
+	if _, err := strconv.Atoi(date[:4]); err != nil {
+		return ""
+	}
+	return date[:4]
+}
+
+// Scan walks folder for .flac files, skipping hidden directories, and reads
+// each one. It stops early, returning ctx's error, if ctx is cancelled.
+func Scan(ctx context.Context, folder string) (*Report, error) {
+	r := &Report{Folder: folder, CreatedAt: timestamp.Format(time.Now()), Files: []Entry{}}
+	err := filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == folder {
+				return err
+			}
+			return nil // unreadable subfolders are skipped
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != folder && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".flac") {
+			return nil
+		}
+		var size int64
+		if info, err := d.Info(); err == nil {
+			size = info.Size()
+		}
+		r.Files = append(r.Files, Read(path, size))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ValidateFormat rejects formats Encode doesn't know.
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown export format %q (want %s)", format, strings.Join(Formats, " or "))
+}
+
+// csvHeader names the CSV columns, in Entry's field order.
+var csvHeader = []string{"path", "title", "artist", "album", "year", "isrc", "quality", "sample_rate", "bit_depth", "duration", "size", "error"}
+
+// CSV encodes r's files as CSV with a header row.
+func (r *Report) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(csvHeader) //nolint:errcheck // reported by w.Error
+	for _, e := range r.Files {
+		w.Write([]string{ //nolint:errcheck // reported by w.Error
+			e.Path, e.Title, e.Artist, e.Album, e.Year, e.ISRC, e.Quality.String(),
+			number(e.SampleRate), number(e.BitDepth),
+			strconv.FormatFloat(e.Duration, 'f', -1, 64), strconv.FormatInt(e.Size, 10), e.Error,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// number formats n, leaving unknown (zero) values blank.
+func number(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// Encode returns r in format ("csv" or "json") and the file extension that
+// goes with it.
+func (r *Report) Encode(format string) ([]byte, string, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, "", err
+	}
+	if format == "csv" {
+		data, err := r.CSV()
+		return data, ".csv", err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	return data, ".json", err
+}
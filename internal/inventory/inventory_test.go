@@ -0,0 +1,76 @@
+package inventory
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/flacmeta/flactest"
+	"flacidal/internal/quality"
+)
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "Artist", "Album"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".trash"), 0755)
+	song := filepath.Join(dir, "Artist", "Album", "01 Song.FLAC")
+	flactest.Write(t, song, flactest.Seconds(flactest.HiRes, 3), flactest.Tags(
+		flacmeta.Field{Name: "TITLE", Value: "Song, \"quoted\""},
+		flacmeta.Field{Name: "ARTIST", Value: "A"},
+		flacmeta.Field{Name: "ARTIST", Value: "B"},
+		flacmeta.Field{Name: "ALBUM", Value: "Album"},
+		flacmeta.Field{Name: "DATE", Value: "2021-03-05"},
+		flacmeta.Field{Name: "ISRC", Value: "USABC2100001"}))
+	flactest.Write(t, filepath.Join(dir, ".trash", "old.flac"), flactest.Seconds(flactest.HiRes, 1))
+	os.WriteFile(filepath.Join(dir, "broken.flac"), []byte("not a flac"), 0644)
+	os.WriteFile(filepath.Join(dir, "cover.jpg"), []byte("jpeg"), 0644)
+
+	r, err := Scan(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Files) != 2 {
+		t.Fatalf("files = %+v, want the song and the broken file", r.Files)
+	}
+	e, broken := r.Files[0], r.Files[1] // walked in lexical order
+	if broken.Error == "" || broken.Size != 10 {
+		t.Errorf("broken file = %+v", broken)
+	}
+	want := Entry{Path: song, Title: `Song, "quoted"`, Artist: "A; B", Album: "Album", Year: "2021", ISRC: "USABC2100001",
+		Quality: quality.HiRes, SampleRate: 96000, BitDepth: 24, Duration: 3, Size: e.Size}
+	if e != want || e.Size == 0 {
+		t.Errorf("entry = %+v, want %+v", e, want)
+	}
+
+	data, ext, err := r.Encode("csv")
+	if err != nil || ext != ".csv" {
+		t.Fatalf("Encode(csv): %q, %v", ext, err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil || len(rows) != 3 || rows[0][0] != "path" || rows[1][1] != `Song, "quoted"` || rows[1][6] != "HI_RES" || rows[1][9] != "3" {
+		t.Errorf("CSV = %q, %v", rows, err)
+	}
+
+	data, ext, err = r.Encode("json")
+	var decoded Report
+	if err != nil || ext != ".json" || json.Unmarshal(data, &decoded) != nil || decoded.Files[0] != want {
+		t.Errorf("JSON = %s, %v", data, err)
+	}
+	if _, _, err := r.Encode("xml"); err == nil {
+		t.Error("Encode(xml): want an error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Scan(ctx, dir); err == nil {
+		t.Error("cancelled Scan: want an error")
+	}
+	if _, err := Scan(context.Background(), filepath.Join(dir, "missing")); err == nil {
+		t.Error("missing folder: want an error")
+	}
+}
@@ -0,0 +1,489 @@
+// Package jobs runs long per-file work, such as analyzing a whole library
+// or renaming a selection of files, as background jobs: each job gets an
+// ID, waits in a queue while earlier jobs run, spreads its files over a
+// pool of workers, publishes progress events and can be cancelled. Work
+// that changes files can return a Change, making the job undoable, and an
+// atomic job rolls its changes back on the first failure. Jobs are kept in
+// memory only; a restart forgets them and their undo information.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"flacidal/internal/events"
+	"flacidal/internal/fileerr"
+	"flacidal/internal/timestamp"
+)
+
+// Work processes one file, returning its result. It should return soon
+// after ctx is cancelled.
+type Work func(ctx context.Context, path string) (any, error)
+
+// Tally names what a processed file counts as in its job's Summary, such
+// as an analysis verdict; "" counts it as nothing. err is the work's.
+type Tally func(result any, err error) string
+
+// Change is the result of Work that changed a file in a way it can revert:
+// the item keeps Result as its result and Output as its output (the new
+// path of a rename, a conversion's output…; "" when the file stayed put),
+// and Undo reverts the change (see Manager.Undo). A nil Undo changed
+// nothing worth reverting.
+type Change struct {
+	Result any
+	Output string
+	Undo   func() error
+}
+
+// Options configure a job.
+type Options struct {
+	// Tally counts the outcomes in the job's Summary; nil counts nothing.
+	Tally Tally
+
+	// Atomic stops the job on the first failure and reverts the files
+	// already changed, leaving it RolledBack.
+	Atomic bool
+}
+
+// State is where a job is in its life.
+type State string
+
+const (
+	Queued     State = "queued"
+	Running    State = "running"
+	Done       State = "done" // every file processed; some may have failed
+	Cancelled  State = "cancelled"
+	RolledBack State = "rolled-back" // atomic job undone after a failure
+	Undone     State = "undone"      // undone on request
+)
+
+// Finished reports whether a job in s will process no more files.
+func (s State) Finished() bool {
+	return s != Queued && s != Running
+}
+
+// Item is the outcome for one file.
+type Item struct {
+	Path   string `json:"path"`
+	Done   bool   `json:"done"` // processed, successfully or not
+	Result any    `json:"result,omitempty"`
+	Output string `json:"output,omitempty"` // see Change
+	Error  string `json:"error,omitempty"`
+
+	// Detail explains permission, read-only and disk space errors.
+	Detail *fileerr.Error `json:"detail,omitempty"`
+
+	Undone    bool   `json:"undone,omitempty"`
+	UndoError string `json:"undoError,omitempty"` // reverting failed; Undo tries again
+}
+
+// Job is a snapshot of one job.
+type Job struct {
+	ID        string         `json:"id"`
+	Kind      string         `json:"kind"`
+	State     State          `json:"state"`
+	Total     int            `json:"total"`
+	Processed int            `json:"processed"`
+	Failed    int            `json:"failed"`
+	Atomic    bool           `json:"atomic,omitempty"`
+	Undoable  bool           `json:"undoable,omitempty"` // Undo would revert at least one file
+	Summary   map[string]int `json:"summary,omitempty"`  // processed files by Tally
+	Items     []Item         `json:"items,omitempty"`    // left out by List
+	Queued    time.Time      `json:"queued"`
+	Started   time.Time      `json:"started,omitzero"`
+	Finished  time.Time      `json:"finished,omitzero"`
+}
+
+// Event reports a job's progress: one per processed file, then one when
+// its state changes. Item is the file just processed, if any.
+type Event struct {
+	ID        string         `json:"id"`
+	Kind      string         `json:"kind"`
+	State     State          `json:"state"`
+	Total     int            `json:"total"`
+	Processed int            `json:"processed"`
+	Failed    int            `json:"failed"`
+	Summary   map[string]int `json:"summary,omitempty"`
+	Item      *Item          `json:"item,omitempty"`
+}
+
+// maxKept is how many finished jobs a Manager remembers.
+const maxKept = 20
+
+// Errors returned by Cancel and Undo.
+var (
+	ErrNotFound    = errors.New("no such job")
+	ErrFinished    = errors.New("job has already finished")
+	ErrRunning     = errors.New("job is still running")
+	ErrNotUndoable = errors.New("job has nothing to undo")
+)
+
+// Manager queues and runs jobs and keeps the most recent ones for Get and
+// List. The zero value is ready to use and runs one file at a time.
+type Manager struct {
+	// Events receives every job's progress.
+	Events events.Bus[Event]
+
+	mu       sync.Mutex
+	workers  int
+	jobs     map[string]*run
+	order    []string // IDs, oldest first
+	pending  []*run   // queued, first to run first
+	running  bool     // the dispatcher is working through pending
+	nextID   int
+	closed   bool
+	shutdown context.CancelFunc
+	ctx      context.Context
+}
+
+// run is a job plus what its work needs, and the undo funcs of its items.
+type run struct {
+	j      Job
+	work   Work
+	tally  Tally
+	cancel context.CancelFunc
+	undos  []func() error
+	failed bool // an item failed; an atomic job stops
+}
+
+// SetWorkers sets how many files a job processes at once, from its next
+// job on; n < 1 means 1.
+func (m *Manager) SetWorkers(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers = max(n, 1)
+}
+
+// Start queues a job running work over paths and returns it as queued.
+// Jobs run one after another, in the order they were started.
+func (m *Manager) Start(kind string, paths []string, work Work, opts Options) Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx == nil {
+		m.ctx, m.shutdown = context.WithCancel(context.Background())
+	}
+	m.nextID++
+	r := &run{
+		j: Job{
+			ID:     strconv.Itoa(m.nextID),
+			Kind:   kind,
+			State:  Queued,
+			Total:  len(paths),
+			Atomic: opts.Atomic,
+			Items:  make([]Item, len(paths)),
+			Queued: timestamp.UTC(time.Now()),
+		},
+		work:  work,
+		tally: opts.Tally,
+		undos: make([]func() error, len(paths)),
+	}
+	for i, p := range paths {
+		r.j.Items[i].Path = p
+	}
+	if m.jobs == nil {
+		m.jobs = map[string]*run{}
+	}
+	m.jobs[r.j.ID] = r
+	m.order = append(m.order, r.j.ID)
+	if m.closed {
+		r.j.State = Cancelled
+		r.j.Finished = r.j.Queued
+	} else {
+		m.pending = append(m.pending, r)
+		if !m.running {
+			m.running = true
+			go m.dispatch()
+		}
+	}
+	m.prune()
+	j := r.j
+	j.Items = slices.Clone(j.Items)
+	return j
+}
+
+// dispatch runs the pending jobs in turn until none are left.
+func (m *Manager) dispatch() {
+	for {
+		m.mu.Lock()
+		if len(m.pending) == 0 {
+			m.running = false
+			m.mu.Unlock()
+			return
+		}
+		r := m.pending[0]
+		m.pending = m.pending[1:]
+		ctx, cancel := context.WithCancel(m.ctx)
+		r.cancel = cancel
+		r.j.State = Running
+		r.j.Started = timestamp.UTC(time.Now())
+		workers := max(m.workers, 1)
+		ev := r.event(nil)
+		m.mu.Unlock()
+		m.Events.Publish(ev)
+
+		m.execute(ctx, r, workers)
+		cancel()
+	}
+}
+
+// execute runs r's work over its items on workers goroutines, stopping
+// early when ctx is cancelled or, for an atomic job, an item failed.
+func (m *Manager) execute(ctx context.Context, r *run, workers int) {
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, max(len(r.j.Items), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if ctx.Err() == nil { // select below may hand out one more
+					m.process(ctx, r, i)
+				}
+			}
+		}()
+	}
+feed:
+	for i := range r.j.Items {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	// Stays Running through a rollback, keeping Undo out
+	if r.failed && r.j.Atomic {
+		m.revert(r, RolledBack)
+	}
+	m.mu.Lock()
+	switch {
+	case r.j.State != Running:
+	case ctx.Err() != nil && r.j.Processed < r.j.Total:
+		r.j.State = Cancelled
+	default:
+		r.j.State = Done
+	}
+	r.j.Finished = timestamp.UTC(time.Now())
+	r.j.Undoable = r.undoable()
+	ev := r.event(nil)
+	m.prune()
+	m.mu.Unlock()
+	m.Events.Publish(ev)
+}
+
+// process runs r's work on item i and publishes the outcome. A file
+// whose work was cut short by cancellation is left unprocessed; the first
+// failure in an atomic job cancels the rest.
+func (m *Manager) process(ctx context.Context, r *run, i int) {
+	path := r.j.Items[i].Path // Paths never change; no lock needed
+	result, err := r.work(ctx, path)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	m.mu.Lock()
+	item := &r.j.Items[i]
+	item.Done = true
+	if err != nil {
+		err = fileerr.Wrap(err)
+		item.Error, item.Detail = err.Error(), fileerr.As(err)
+		r.j.Failed++
+		r.failed = true
+		if r.j.Atomic {
+			r.cancel()
+		}
+	} else if c, ok := result.(Change); ok {
+		result = c.Result
+		item.Result, item.Output = c.Result, c.Output
+		r.undos[i] = c.Undo
+	} else {
+		item.Result = result
+	}
+	r.j.Processed++
+	if r.tally != nil {
+		if key := r.tally(result, err); key != "" {
+			if r.j.Summary == nil {
+				r.j.Summary = map[string]int{}
+			}
+			r.j.Summary[key]++
+		}
+	}
+	ev := r.event(item)
+	m.mu.Unlock()
+	m.Events.Publish(ev)
+}
+
+// prune forgets the oldest finished jobs beyond maxKept. Callers hold
+// m.mu.
+func (m *Manager) prune() {
+	for i := 0; len(m.order) > maxKept && i < len(m.order); {
+		if id := m.order[i]; m.jobs[id].j.State.Finished() {
+			delete(m.jobs, id)
+			m.order = slices.Delete(m.order, i, i+1)
+			continue
+		}
+		i++
+	}
+}
+
+// revert runs the undo funcs of r's changed items, last first, and sets
+// its state to state. Each func is claimed under m.mu before it runs, so
+// no item is reverted twice. Items that fail to revert get the error in
+// UndoError, and their func back for another try.
+func (m *Manager) revert(r *run, state State) {
+	for i := len(r.j.Items) - 1; i >= 0; i-- {
+		m.mu.Lock()
+		undo := r.undos[i]
+		r.undos[i] = nil
+		m.mu.Unlock()
+		if undo == nil {
+			continue
+		}
+		err := undo()
+		m.mu.Lock()
+		item := &r.j.Items[i]
+		if err != nil {
+			err = fileerr.Wrap(err)
+			item.UndoError, item.Detail = err.Error(), fileerr.As(err)
+			r.undos[i] = undo
+		} else {
+			item.Undone, item.UndoError, item.Detail = true, "", nil
+		}
+		m.mu.Unlock()
+	}
+	m.mu.Lock()
+	r.j.State = state
+	m.mu.Unlock()
+}
+
+// undoable reports whether any item can still be reverted. Callers hold
+// m.mu.
+func (r *run) undoable() bool {
+	return slices.ContainsFunc(r.undos, func(u func() error) bool { return u != nil })
+}
+
+// event builds r's progress event. Callers hold m.mu.
+func (r *run) event(item *Item) Event {
+	ev := Event{ID: r.j.ID, Kind: r.j.Kind, State: r.j.State, Total: r.j.Total, Processed: r.j.Processed, Failed: r.j.Failed, Summary: maps.Clone(r.j.Summary)}
+	if item != nil {
+		it := *item
+		ev.Item = &it
+	}
+	return ev
+}
+
+// Get returns the job with id, with its per-file results.
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	j := r.j
+	j.Items = slices.Clone(j.Items)
+	j.Summary = maps.Clone(j.Summary)
+	return j, true
+}
+
+// List returns the remembered jobs, newest first, without their items.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Job, 0, len(m.order))
+	for i := len(m.order) - 1; i >= 0; i-- {
+		j := m.jobs[m.order[i]].j
+		j.Items = nil
+		j.Summary = maps.Clone(j.Summary)
+		list = append(list, j)
+	}
+	return list
+}
+
+// Cancel stops a job: a queued one never starts, and a running one stops
+// handing out files, keeping the results it has. It returns the job as
+// it is then; a running job reaches Cancelled once its workers stop.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	r, ok := m.jobs[id]
+	switch {
+	case !ok:
+		m.mu.Unlock()
+		return Job{}, ErrNotFound
+	case r.j.State.Finished():
+		m.mu.Unlock()
+		return Job{}, ErrFinished
+	case r.j.State == Running:
+		r.cancel()
+		m.mu.Unlock()
+		j, _ := m.Get(id)
+		return j, nil
+	}
+	m.pending = slices.DeleteFunc(m.pending, func(p *run) bool { return p == r })
+	r.j.State = Cancelled
+	r.j.Finished = timestamp.UTC(time.Now())
+	ev := r.event(nil)
+	m.prune()
+	m.mu.Unlock()
+	m.Events.Publish(ev)
+	j, _ := m.Get(id)
+	return j, nil
+}
+
+// Undo reverts the files a finished job changed, last first, and returns
+// the job afterwards. Files whose undo fails report it in their UndoError.
+func (m *Manager) Undo(id string) (Job, error) {
+	m.mu.Lock()
+	r, ok := m.jobs[id]
+	switch {
+	case !ok:
+		m.mu.Unlock()
+		return Job{}, ErrNotFound
+	case !r.j.State.Finished():
+		m.mu.Unlock()
+		return Job{}, ErrRunning
+	case !r.undoable():
+		m.mu.Unlock()
+		return Job{}, ErrNotUndoable
+	}
+	r.j.State = Running // keeps a second Undo, and prune, out meanwhile
+	m.mu.Unlock()
+
+	m.revert(r, Undone)
+	m.mu.Lock()
+	r.j.Undoable = r.undoable()
+	ev := r.event(nil)
+	m.mu.Unlock()
+	m.Events.Publish(ev)
+	j, _ := m.Get(id)
+	return j, nil
+}
+
+// Close cancels every queued and running job and refuses new ones, which
+// are returned as cancelled.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	m.closed = true
+	pending := m.pending
+	m.pending = nil
+	if m.shutdown != nil {
+		m.shutdown()
+	}
+	now := timestamp.UTC(time.Now())
+	evs := make([]Event, len(pending))
+	for i, r := range pending {
+		r.j.State = Cancelled
+		r.j.Finished = now
+		evs[i] = r.event(nil)
+	}
+	m.mu.Unlock()
+	for _, ev := range evs {
+		m.Events.Publish(ev)
+	}
+}
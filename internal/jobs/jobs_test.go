@@ -0,0 +1,245 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// upper "processes" paths by upper-casing them, failing paths that contain
+// "bad".
+func upper(_ context.Context, path string) (any, error) {
+	if strings.Contains(path, "bad") {
+		return nil, errors.New("cannot process")
+	}
+	return strings.ToUpper(path), nil
+}
+
+// wait returns the event finishing job id, failing the test if it takes
+// too long.
+func wait(t *testing.T, ch <-chan Event, id string) Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-ch:
+			if ev.ID == id && ev.State.Finished() {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("job %s did not finish", id)
+		}
+	}
+}
+
+func TestStart_ProcessesEveryFile(t *testing.T) {
+	var m Manager
+	m.SetWorkers(3)
+	_, ch := m.Events.Subscribe(16)
+	j := m.Start("test", []string{"a", "bad", "c", "d"}, upper, Options{Tally: func(result any, err error) string {
+		if err != nil {
+			return "failed"
+		}
+		return "ok"
+	}})
+	if j.State != Queued || j.Total != 4 || len(j.Items) != 4 {
+		t.Errorf("started = %+v", j)
+	}
+
+	if ev := wait(t, ch, j.ID); ev.State != Done || ev.Processed != 4 || ev.Failed != 1 || ev.Summary["ok"] != 3 || ev.Summary["failed"] != 1 {
+		t.Errorf("last event = %+v", ev)
+	}
+	j, _ = m.Get(j.ID)
+	if j.Items[0].Result != "A" || !j.Items[1].Done || j.Items[1].Error == "" || j.Items[3].Result != "D" {
+		t.Errorf("items = %+v", j.Items)
+	}
+	if j.Started.IsZero() || j.Finished.IsZero() {
+		t.Errorf("times = %v, %v", j.Started, j.Finished)
+	}
+}
+
+func TestStart_JobsRunInOrder(t *testing.T) {
+	var m Manager
+	_, ch := m.Events.Subscribe(16)
+	release := make(chan struct{})
+	first := m.Start("test", []string{"a"}, func(ctx context.Context, path string) (any, error) {
+		<-release
+		return nil, nil
+	}, Options{})
+	second := m.Start("test", []string{"b"}, upper, Options{})
+
+	if j, _ := m.Get(second.ID); j.State != Queued {
+		t.Errorf("second job state = %s while the first runs", j.State)
+	}
+	close(release)
+	wait(t, ch, first.ID)
+	if ev := wait(t, ch, second.ID); ev.State != Done {
+		t.Errorf("second job = %+v", ev)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	var m Manager
+	_, ch := m.Events.Subscribe(16)
+	var calls atomic.Int32
+	running := m.Start("test", []string{"a", "b", "c"}, func(ctx context.Context, path string) (any, error) {
+		calls.Add(1)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, Options{})
+	queued := m.Start("test", []string{"d"}, upper, Options{})
+
+	j, err := m.Cancel(queued.ID)
+	if err != nil || j.State != Cancelled {
+		t.Errorf("Cancel(queued) = %+v, %v", j, err)
+	}
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := m.Cancel(running.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ev := wait(t, ch, running.ID); ev.State != Cancelled || ev.Processed != 0 {
+		t.Errorf("last event = %+v", ev)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("work ran %d times after cancelling, want 1", calls.Load())
+	}
+	if _, err := m.Cancel(running.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("second Cancel = %v, want ErrFinished", err)
+	}
+	if _, err := m.Cancel("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestManager_ListAndPrune(t *testing.T) {
+	var m Manager
+	_, ch := m.Events.Subscribe(4 * (maxKept + 5))
+	var last Job
+	for range maxKept + 5 {
+		last = m.Start("test", []string{"a"}, upper, Options{})
+	}
+	wait(t, ch, last.ID)
+	list := m.List()
+	if len(list) != maxKept || list[0].ID != last.ID || list[0].Items != nil {
+		t.Errorf("List() = %d jobs, first %+v", len(list), list[0])
+	}
+	if _, ok := m.Get("1"); ok {
+		t.Error("oldest job still kept")
+	}
+}
+
+func TestClose_CancelsJobs(t *testing.T) {
+	var m Manager
+	_, ch := m.Events.Subscribe(16)
+	j := m.Start("test", []string{"a"}, func(ctx context.Context, path string) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, Options{})
+	m.Close()
+	if ev := wait(t, ch, j.ID); ev.State != Cancelled {
+		t.Errorf("after Close: %+v", ev)
+	}
+	if j := m.Start("test", []string{"b"}, upper, Options{}); j.State != Cancelled {
+		t.Errorf("Start after Close = %+v", j)
+	}
+}
+
+// change "processes" paths by recording them in done, failing paths that
+// contain "bad"; the change's undo removes them again.
+func change(done *sync.Map) Work {
+	return func(_ context.Context, path string) (any, error) {
+		if strings.Contains(path, "bad") {
+			return nil, errors.New("cannot process")
+		}
+		done.Store(path, true)
+		return Change{Output: path + ".out", Undo: func() error { done.Delete(path); return nil }}, nil
+	}
+}
+
+// count returns how many paths done holds.
+func count(done *sync.Map) int {
+	n := 0
+	done.Range(func(any, any) bool { n++; return true })
+	return n
+}
+
+func TestUndo(t *testing.T) {
+	var m Manager
+	_, ch := m.Events.Subscribe(16)
+	var done sync.Map
+	j := m.Start("test", []string{"a", "bad", "c"}, change(&done), Options{})
+	if _, err := m.Undo(j.ID); !errors.Is(err, ErrRunning) {
+		t.Errorf("Undo while queued = %v, want ErrRunning", err)
+	}
+	wait(t, ch, j.ID)
+
+	j, _ = m.Get(j.ID)
+	if j.State != Done || j.Failed != 1 || !j.Undoable || j.Items[0].Output != "a.out" || j.Items[1].Error == "" || count(&done) != 2 {
+		t.Errorf("job = %+v", j)
+	}
+	j, err := m.Undo(j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.State != Undone || j.Undoable || count(&done) != 0 || !j.Items[0].Undone || j.Items[1].Undone {
+		t.Errorf("after Undo: %+v", j)
+	}
+	if _, err := m.Undo(j.ID); !errors.Is(err, ErrNotUndoable) {
+		t.Errorf("second Undo = %v, want ErrNotUndoable", err)
+	}
+	if _, err := m.Undo("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Undo(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStart_AtomicRollsBack(t *testing.T) {
+	var m Manager
+	_, ch := m.Events.Subscribe(16)
+	var done sync.Map
+	j := m.Start("test", []string{"a", "b", "bad", "d"}, change(&done), Options{Atomic: true})
+	if ev := wait(t, ch, j.ID); ev.State != RolledBack || ev.Processed != 3 {
+		t.Errorf("last event = %+v", ev)
+	}
+	j, _ = m.Get(j.ID)
+	if !j.Items[0].Undone || !j.Items[1].Undone || j.Items[3].Done || j.Undoable || count(&done) != 0 {
+		t.Errorf("job = %+v", j)
+	}
+}
+
+func TestUndo_DuringRollback(t *testing.T) {
+	var m Manager
+	undos := 0
+	rolling := make(chan struct{})
+	release := make(chan struct{})
+	j := m.Start("test", []string{"a", "bad"}, func(_ context.Context, path string) (any, error) {
+		if path == "bad" {
+			return nil, errors.New("cannot process")
+		}
+		return Change{Undo: func() error {
+			undos++
+			close(rolling)
+			<-release
+			return nil
+		}}, nil
+	}, Options{Atomic: true})
+	<-rolling
+	if _, err := m.Undo(j.ID); !errors.Is(err, ErrRunning) {
+		t.Errorf("Undo during rollback = %v, want ErrRunning", err)
+	}
+	close(release)
+	for {
+		if got, _ := m.Get(j.ID); got.State == RolledBack {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := m.Undo(j.ID); !errors.Is(err, ErrNotUndoable) || undos != 1 {
+		t.Errorf("Undo after rollback = %v, %d undos", err, undos)
+	}
+}
@@ -0,0 +1,172 @@
+package library
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// What makes two files copies of the same track, for Duplicates.
+const (
+	ByISRC = "isrc" // the same ISRC tag: the same recording
+	ByMD5  = "md5"  // the same STREAMINFO audio MD5: bit-identical audio
+)
+
+// CheckMatch returns an error unless by is ByISRC, ByMD5 or "" (either).
+func CheckMatch(by string) error {
+	if by != "" && by != ByISRC && by != ByMD5 {
+		return fmt.Errorf("unknown duplicate match %q (want %q or %q)", by, ByISRC, ByMD5)
+	}
+	return nil
+}
+
+// DuplicateGroup is a set of files holding the same track.
+type DuplicateGroup struct {
+	ISRC      string  `json:"isrc,omitempty"` // shared by at least two of Tracks
+	MD5       string  `json:"md5,omitempty"`  // likewise
+	Identical bool    `json:"identical"`      // every track has the same audio MD5
+	Tracks    []Track `json:"tracks"`         // best first; see Compare
+	Wasted    int64   `json:"wasted"`         // bytes taken by all but the best
+}
+
+// Compare orders a before b when a is the better copy of a track: readable
+// over unreadable, then by bit depth, sample rate, channels and bitrate,
+// highest first. Ties go by path.
+func Compare(a, b Track) int {
+	if (a.Error == "") != (b.Error == "") {
+		if a.Error == "" {
+			return -1
+		}
+		return 1
+	}
+	return cmp.Or(
+		cmp.Compare(b.BitDepth, a.BitDepth),
+		cmp.Compare(b.SampleRate, a.SampleRate),
+		cmp.Compare(b.Channels, a.Channels),
+		cmp.Compare(b.Bitrate, a.Bitrate),
+		strings.Compare(a.Path, b.Path),
+	)
+}
+
+// Duplicates returns the groups of files sharing an ISRC or an audio MD5,
+// or only the one by names (ByISRC or ByMD5) if set. A file sharing either
+// with any file of a group is in that group. Groups come by the artist,
+// album and title of their best file.
+func (x *Index) Duplicates(by string) ([]DuplicateGroup, error) {
+	if err := CheckMatch(by); err != nil {
+		return nil, err
+	}
+	var conds []string
+	if by != ByMD5 {
+		conds = append(conds, duplicateISRC)
+	}
+	if by != ByISRC {
+		conds = append(conds, duplicateMD5)
+	}
+	rows, err := x.db.Query("SELECT " + columns + " FROM library WHERE " + strings.Join(conds, " OR "))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tracks []Track
+	for rows.Next() {
+		t, err := scanTrack(rows)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groupDuplicates(tracks, by != ByMD5, by != ByISRC), nil
+}
+
+// The conditions selecting the files sharing an ISRC, case-insensitively,
+// and an audio MD5 with another.
+const (
+	duplicateISRC = "(isrc != '' AND UPPER(isrc) IN (SELECT UPPER(isrc) FROM library WHERE isrc != '' GROUP BY UPPER(isrc) HAVING COUNT(*) > 1))"
+	duplicateMD5  = "(md5 != '' AND md5 IN (SELECT md5 FROM library WHERE md5 != '' GROUP BY md5 HAVING COUNT(*) > 1))"
+)
+
+// groupDuplicates groups tracks sharing an ISRC (with byISRC) or an MD5
+// (with byMD5), transitively, dropping the tracks left alone.
+func groupDuplicates(tracks []Track, byISRC, byMD5 bool) []DuplicateGroup {
+	parent := make([]int, len(tracks))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	first := map[string]int{} // key → the first track having it
+	link := func(i int, key string) {
+		if j, ok := first[key]; ok {
+			parent[find(i)] = find(j)
+		} else {
+			first[key] = i
+		}
+	}
+	for i, t := range tracks {
+		if byISRC && t.ISRC != "" {
+			link(i, "isrc:"+strings.ToUpper(t.ISRC))
+		}
+		if byMD5 && t.MD5 != "" {
+			link(i, "md5:"+t.MD5)
+		}
+	}
+
+	members := map[int][]Track{}
+	var roots []int
+	for i, t := range tracks {
+		r := find(i)
+		if members[r] == nil {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], t)
+	}
+	groups := []DuplicateGroup{}
+	for _, r := range roots {
+		if len(members[r]) > 1 {
+			groups = append(groups, newDuplicateGroup(members[r], byISRC, byMD5))
+		}
+	}
+	slices.SortFunc(groups, func(a, b DuplicateGroup) int {
+		x, y := a.Tracks[0], b.Tracks[0]
+		return cmp.Or(
+			strings.Compare(strings.ToLower(x.Artist), strings.ToLower(y.Artist)),
+			strings.Compare(strings.ToLower(x.Album), strings.ToLower(y.Album)),
+			strings.Compare(strings.ToLower(x.Title), strings.ToLower(y.Title)),
+			strings.Compare(x.Path, y.Path),
+		)
+	})
+	return groups
+}
+
+// newDuplicateGroup sorts tracks best first and fills in what they share.
+func newDuplicateGroup(tracks []Track, byISRC, byMD5 bool) DuplicateGroup {
+	slices.SortFunc(tracks, Compare)
+	g := DuplicateGroup{Tracks: tracks, Identical: tracks[0].MD5 != ""}
+	isrcs, md5s := map[string]int{}, map[string]int{}
+	for i, t := range tracks {
+		if i > 0 {
+			g.Wasted += t.Size
+		}
+		if t.MD5 != tracks[0].MD5 {
+			g.Identical = false
+		}
+		isrc := strings.ToUpper(t.ISRC)
+		if isrcs[isrc]++; byISRC && isrc != "" && isrcs[isrc] == 2 && g.ISRC == "" {
+			g.ISRC = isrc
+		}
+		if md5s[t.MD5]++; byMD5 && t.MD5 != "" && md5s[t.MD5] == 2 && g.MD5 == "" {
+			g.MD5 = t.MD5
+		}
+	}
+	return g
+}
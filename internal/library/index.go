@@ -0,0 +1,414 @@
+package library
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"flacidal/internal/quality"
+)
+
+// FileName is the index's database file inside the data directory.
+const FileName = "library.db"
+
+// Driver is the database/sql driver Open uses, go-sqlite3's, which
+// flacidal-core's database uses too. Programs register it by importing
+// github.com/mattn/go-sqlite3.
+const Driver = "sqlite3"
+
+// ErrScanning is returned by Scan while another scan runs.
+var ErrScanning = errors.New("a library scan is already running")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS library (
+	path         TEXT PRIMARY KEY,
+	root         TEXT NOT NULL,
+	title        TEXT NOT NULL DEFAULT '',
+	artist       TEXT NOT NULL DEFAULT '',
+	album_artist TEXT NOT NULL DEFAULT '',
+	album        TEXT NOT NULL DEFAULT '',
+	genre        TEXT NOT NULL DEFAULT '',
+	year         TEXT NOT NULL DEFAULT '',
+	label        TEXT NOT NULL DEFAULT '',
+	catalog      TEXT NOT NULL DEFAULT '',
+	track_number INTEGER NOT NULL DEFAULT 0,
+	disc_number  INTEGER NOT NULL DEFAULT 0,
+	isrc         TEXT NOT NULL DEFAULT '',
+	md5          TEXT NOT NULL DEFAULT '',
+	sample_rate  INTEGER NOT NULL DEFAULT 0,
+	bit_depth    INTEGER NOT NULL DEFAULT 0,
+	channels     INTEGER NOT NULL DEFAULT 0,
+	duration     REAL NOT NULL DEFAULT 0,
+	size         INTEGER NOT NULL DEFAULT 0,
+	mod_time     INTEGER NOT NULL DEFAULT 0,
+	error        TEXT NOT NULL DEFAULT ''
+);
+`
+
+// added are the columns added to the library table since its first
+// version, with their definitions. Open adds the ones an older index
+// lacks, and forgets every file's modification time so the next scan
+// reads them all again.
+var added = []struct{ name, def string }{
+	{"md5", "TEXT NOT NULL DEFAULT ''"},
+	{"label", "TEXT NOT NULL DEFAULT ''"},
+	{"catalog", "TEXT NOT NULL DEFAULT ''"},
+}
+
+const indexes = `
+CREATE INDEX IF NOT EXISTS library_artist ON library (artist COLLATE NOCASE);
+CREATE INDEX IF NOT EXISTS library_album_artist ON library (album_artist COLLATE NOCASE);
+CREATE INDEX IF NOT EXISTS library_album ON library (album COLLATE NOCASE);
+CREATE INDEX IF NOT EXISTS library_root ON library (root);
+CREATE INDEX IF NOT EXISTS library_isrc ON library (UPPER(isrc));
+CREATE INDEX IF NOT EXISTS library_md5 ON library (md5);
+CREATE INDEX IF NOT EXISTS library_label ON library (label COLLATE NOCASE);
+`
+
+// columns are the library table's columns, in Track's field order; see
+// scanTrack.
+const columns = "path, root, title, artist, album_artist, album, genre, year, label, catalog, track_number, disc_number, isrc, md5, sample_rate, bit_depth, channels, duration, size, mod_time, error"
+
+// Index is the library index. It is safe for concurrent use; scans run one
+// at a time.
+type Index struct {
+	db *sql.DB
+
+	mu       sync.Mutex
+	scanning bool
+	last     *ScanReport
+	lastAt   time.Time
+}
+
+// Open opens the index in dataDir, creating it.
+func Open(dataDir string) (*Index, error) {
+	db, err := sql.Open(Driver, "file:"+filepath.Join(dataDir, FileName)+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Index{db: db}, nil
+}
+
+// migrate creates the library table and its indexes, adding the columns
+// an older table lacks.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+	if _, err := db.Exec(linkSchema); err != nil {
+		return err
+	}
+	rows, err := db.Query("SELECT name FROM pragma_table_info('library')")
+	if err != nil {
+		return err
+	}
+	have := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		have[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, c := range added {
+		if have[c.name] {
+			continue
+		}
+		if _, err := db.Exec("ALTER TABLE library ADD COLUMN " + c.name + " " + c.def); err != nil {
+			return err
+		}
+		if _, err := db.Exec("UPDATE library SET mod_time = 0"); err != nil {
+			return err
+		}
+	}
+	_, err = db.Exec(indexes)
+	return err
+}
+
+// Close closes the database.
+func (x *Index) Close() error {
+	return x.db.Close()
+}
+
+// Scan brings the index up to date with the FLACs under roots (see scan):
+// new and changed files are read, and files no longer found are dropped,
+// as are files under folders that are no longer roots. progress, if set,
+// is called with the number of files found so far.
+func (x *Index) Scan(ctx context.Context, roots []string, progress func(files int)) (*ScanReport, error) {
+	return x.scan(ctx, roots, 0, progress)
+}
+
+// scan is Scan, leaving files modified less than settle ago for later.
+func (x *Index) scan(ctx context.Context, roots []string, settle time.Duration, progress func(files int)) (*ScanReport, error) {
+	x.mu.Lock()
+	if x.scanning {
+		x.mu.Unlock()
+		return nil, ErrScanning
+	}
+	x.scanning = true
+	x.mu.Unlock()
+	defer func() {
+		x.mu.Lock()
+		x.scanning = false
+		x.mu.Unlock()
+	}()
+
+	start := time.Now()
+	known, err := x.stamps()
+	if err != nil {
+		return nil, err
+	}
+	res, err := scan(ctx, roots, known, settle, progress)
+	if err != nil {
+		return nil, err
+	}
+	if err := x.apply(res); err != nil {
+		return nil, err
+	}
+	res.report.Duration = time.Since(start).Round(time.Millisecond).Seconds()
+
+	x.mu.Lock()
+	x.last, x.lastAt = &res.report, time.Now()
+	x.mu.Unlock()
+	return &res.report, nil
+}
+
+// stamps returns the size and modification time of every indexed file.
+func (x *Index) stamps() (map[string]stamp, error) {
+	rows, err := x.db.Query("SELECT path, size, mod_time FROM library")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	known := map[string]stamp{}
+	for rows.Next() {
+		var path string
+		var s stamp
+		if err := rows.Scan(&path, &s.size, &s.modTime); err != nil {
+			return nil, err
+		}
+		known[path] = s
+	}
+	return known, rows.Err()
+}
+
+// apply writes a scan's changes in one transaction.
+func (x *Index) apply(res *scanResult) error {
+	tx, err := x.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after Commit
+	insert, err := tx.Prepare(upsert)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	isNew := make(map[string]bool, len(res.report.New))
+	for _, path := range res.report.New {
+		isNew[path] = true
+	}
+	added := map[string]string{} // audio MD5 → path, of the new files
+	for _, t := range res.changed {
+		if _, err := insert.Exec(upsertArgs(t)...); err != nil {
+			return err
+		}
+		if isNew[t.Path] && t.MD5 != "" {
+			added[t.MD5] = t.Path
+		}
+	}
+	for _, path := range res.removed {
+		if err := relink(tx, path, added); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Status is the state of the index.
+type Status struct {
+	Tracks   int         `json:"tracks"`
+	Scanning bool        `json:"scanning"`
+	LastScan *ScanReport `json:"lastScan,omitempty"` // since FLACidal started
+	ScanTime string      `json:"scanTime,omitempty"` // of LastScan, RFC3339
+}
+
+// Status returns the number of indexed tracks and the last scan.
+func (x *Index) Status() (*Status, error) {
+	st := &Status{}
+	if err := x.db.QueryRow("SELECT COUNT(*) FROM library").Scan(&st.Tracks); err != nil {
+		return nil, err
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	st.Scanning, st.LastScan = x.scanning, x.last
+	if x.last != nil {
+		st.ScanTime = x.lastAt.UTC().Format(time.RFC3339)
+	}
+	return st, nil
+}
+
+// Page is one page of Tracks' results.
+type Page struct {
+	Total  int     `json:"total"` // tracks q selects, on every page
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+	Tracks []Track `json:"tracks"`
+}
+
+// Tracks returns the page of tracks q selects, in its order.
+func (x *Index) Tracks(q Query) (*Page, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	where, args := q.where()
+	p := &Page{Limit: q.limit(), Offset: q.Offset, Tracks: []Track{}}
+	if err := x.db.QueryRow("SELECT COUNT(*) FROM library"+where, args...).Scan(&p.Total); err != nil {
+		return nil, err
+	}
+	rows, err := x.db.Query("SELECT "+columns+" FROM library"+where+q.orderBy()+" LIMIT ? OFFSET ?", append(args, p.Limit, p.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t, err := scanTrack(rows)
+		if err != nil {
+			return nil, err
+		}
+		p.Tracks = append(p.Tracks, t)
+	}
+	return p, rows.Err()
+}
+
+// scanTrack reads a row of columns.
+func scanTrack(rows *sql.Rows) (Track, error) {
+	var t Track
+	var modTime int64
+	err := rows.Scan(&t.Path, &t.Root, &t.Title, &t.Artist, &t.AlbumArtist, &t.Album, &t.Genre, &t.Year, &t.Label, &t.Catalog,
+		&t.TrackNumber, &t.DiscNumber, &t.ISRC, &t.MD5, &t.SampleRate, &t.BitDepth, &t.Channels,
+		&t.Duration, &t.Size, &modTime, &t.Error)
+	t.ModTime = time.Unix(0, modTime)
+	t.Quality = quality.Tier(t.BitDepth, t.SampleRate)
+	t.Bitrate = bitrate(t.Size, t.Duration)
+	return t, err
+}
+
+// albumArtist is the artist tracks are grouped under: the album artist,
+// else the track's artists.
+const albumArtist = "COALESCE(NULLIF(album_artist, ''), artist)"
+
+// Artist is an artist of the library, as Artists lists them.
+type Artist struct {
+	Name   string `json:"name"`
+	Albums int    `json:"albums"`
+	Tracks int    `json:"tracks"`
+}
+
+// Artists returns the album artists of the tracks q selects, by name;
+// tracks without one count under their artists. q's order and page are
+// ignored.
+func (x *Index) Artists(q Query) ([]Artist, error) {
+	where, args := q.where()
+	rows, err := x.db.Query("SELECT "+albumArtist+" AS name, COUNT(DISTINCT album COLLATE NOCASE), COUNT(*) FROM library"+where+
+		" GROUP BY name COLLATE NOCASE ORDER BY name COLLATE NOCASE", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	artists := []Artist{}
+	for rows.Next() {
+		var a Artist
+		if err := rows.Scan(&a.Name, &a.Albums, &a.Tracks); err != nil {
+			return nil, err
+		}
+		artists = append(artists, a)
+	}
+	return artists, rows.Err()
+}
+
+// Album is an album of the library, as Albums lists them.
+type Album struct {
+	Title    string  `json:"title"`
+	Artist   string  `json:"artist"` // album artist, else the tracks' artists
+	Year     string  `json:"year,omitempty"`
+	Genre    string  `json:"genre,omitempty"`
+	Label    string  `json:"label,omitempty"`
+	Catalog  string  `json:"catalogNumber,omitempty"`
+	Tracks   int     `json:"tracks"`
+	Duration float64 `json:"duration"` // seconds
+	Size     int64   `json:"size"`     // bytes
+	Path     string  `json:"path"`     // a track's, for its cover
+}
+
+// Albums returns the albums of the tracks q selects, by artist, year and
+// title; the albums of one label (q.Label) are listed by catalog number,
+// those without one last. q's order and page are ignored.
+func (x *Index) Albums(q Query) ([]Album, error) {
+	where, args := q.where()
+	order := " ORDER BY name COLLATE NOCASE, MAX(year), album COLLATE NOCASE"
+	if q.Label != "" {
+		order = " ORDER BY MAX(catalog) = '', MAX(catalog) COLLATE NOCASE," + strings.TrimPrefix(order, " ORDER BY")
+	}
+	rows, err := x.db.Query("SELECT album, "+albumArtist+" AS name, MAX(year), MAX(genre), MAX(label), MAX(catalog), COUNT(*), SUM(duration), SUM(size), MIN(path) FROM library"+where+
+		" GROUP BY album COLLATE NOCASE, name COLLATE NOCASE"+order, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	albums := []Album{}
+	for rows.Next() {
+		var a Album
+		if err := rows.Scan(&a.Title, &a.Artist, &a.Year, &a.Genre, &a.Label, &a.Catalog, &a.Tracks, &a.Duration, &a.Size, &a.Path); err != nil {
+			return nil, err
+		}
+		albums = append(albums, a)
+	}
+	return albums, rows.Err()
+}
+
+// Label is a record label of the library, as Labels lists them.
+type Label struct {
+	Name   string `json:"name"`
+	Albums int    `json:"albums"`
+	Tracks int    `json:"tracks"`
+}
+
+// Labels returns the labels of the tracks q selects, by name; tracks
+// without a label are left out. q's order and page are ignored.
+func (x *Index) Labels(q Query) ([]Label, error) {
+	where, args := q.where()
+	if where == "" {
+		where = " WHERE label != ''"
+	} else {
+		where += " AND label != ''"
+	}
+	rows, err := x.db.Query("SELECT label AS name, COUNT(DISTINCT album COLLATE NOCASE), COUNT(*) FROM library"+where+
+		" GROUP BY name COLLATE NOCASE ORDER BY name COLLATE NOCASE", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	labels := []Label{}
+	for rows.Next() {
+		var l Label
+		if err := rows.Scan(&l.Name, &l.Albums, &l.Tracks); err != nil {
+			return nil, err
+		}
+		labels = append(labels, l)
+	}
+	return labels, rows.Err()
+}
@@ -0,0 +1,250 @@
+// Package library indexes the FLACs under the download folder and the
+// external library paths in a SQLite database, library.db next to core's
+// own, so the library can be browsed, searched and sorted without walking
+// the folders again. Tracks are indexed by their embedded tags and
+// STREAMINFO, not their names. A rescan only reads the files whose size or
+// modification time changed, and drops the ones that are gone.
+package library
+
+import (
+	"context"
+	"encoding/hex"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/quality"
+)
+
+// Track is one indexed FLAC. Files whose tags can't be read are indexed
+// with their path, size and Error, so they can be found and fixed.
+type Track struct {
+	Path        string          `json:"path"`
+	Root        string          `json:"root"`   // the library folder it was found under
+	Title       string          `json:"title"`  // TITLE
+	Artist      string          `json:"artist"` // every ARTIST, "; "-joined
+	AlbumArtist string          `json:"albumArtist,omitempty"`
+	Album       string          `json:"album"`
+	Genre       string          `json:"genre,omitempty"` // every GENRE, "; "-joined
+	Year        string          `json:"year,omitempty"`
+	Label       string          `json:"label,omitempty"`         // LABEL, else ORGANIZATION or PUBLISHER
+	Catalog     string          `json:"catalogNumber,omitempty"` // CATALOGNUMBER, else LABELNO
+	TrackNumber int             `json:"trackNumber,omitempty"`
+	DiscNumber  int             `json:"discNumber,omitempty"`
+	ISRC        string          `json:"isrc,omitempty"`
+	MD5         string          `json:"md5,omitempty"`     // STREAMINFO's audio MD5, hex; "" when unset
+	Quality     quality.Quality `json:"quality,omitempty"` // see quality.Tier
+	SampleRate  int             `json:"sampleRate,omitempty"`
+	BitDepth    int             `json:"bitDepth,omitempty"`
+	Channels    int             `json:"channels,omitempty"`
+	Duration    float64         `json:"duration"`          // seconds
+	Bitrate     int             `json:"bitrate,omitempty"` // kbps, from Size and Duration
+	Size        int64           `json:"size"`              // bytes
+	ModTime     time.Time       `json:"modTime"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// Read returns the track for the FLAC at path, found under root.
+func Read(root, path string, info fs.FileInfo) Track {
+	t := Track{Path: path, Root: root, Size: info.Size(), ModTime: info.ModTime()}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Error = err.Error()
+		return t
+	}
+	if si, err := f.StreamInfo(); err == nil {
+		t.SampleRate, t.BitDepth, t.Channels = si.SampleRate, si.BitDepth, si.Channels
+		t.Quality = quality.Tier(si.BitDepth, si.SampleRate)
+		if si.MD5 != ([16]byte{}) {
+			t.MD5 = hex.EncodeToString(si.MD5[:])
+		}
+	}
+	if d, err := f.Duration(); err == nil {
+		t.Duration = d.Round(time.Millisecond).Seconds()
+		t.Bitrate = bitrate(t.Size, t.Duration)
+	}
+	c, err := f.Comments()
+	if err != nil {
+		t.Error = err.Error()
+		return t
+	}
+	t.Title, t.Album, t.AlbumArtist = c.Get("TITLE"), c.Get("ALBUM"), c.Get("ALBUMARTIST")
+	t.Artist = strings.Join(c.GetAll("ARTIST"), "; ")
+	t.Genre = strings.Join(c.GetAll("GENRE"), "; ")
+	t.ISRC = c.Get("ISRC")
+	t.TrackNumber, t.DiscNumber = number(c.Get("TRACKNUMBER")), number(c.Get("DISCNUMBER"))
+	t.Year = year(c.Get("DATE"))
+	if t.Year == "" {
+		t.Year = year(c.Get("YEAR"))
+	}
+	t.Label = first(c, "LABEL", "ORGANIZATION", "PUBLISHER")
+	t.Catalog = first(c, "CATALOGNUMBER", "LABELNO")
+	return t
+}
+
+// first returns the first of the tags names that c has, or "".
+func first(c *flacmeta.Comments, names ...string) string {
+	for _, name := range names {
+		if v := strings.TrimSpace(c.Get(name)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// bitrate returns the average bitrate in kbps of size bytes lasting
+// duration seconds, or 0.
+func bitrate(size int64, duration float64) int {
+	if duration <= 0 {
+		return 0
+	}
+	return int(float64(size) * 8 / duration / 1000)
+}
+
+// number returns the number a tag starts with ("3/12" → 3), or 0.
+func number(s string) int {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if end >= 0 {
+		s = s[:end]
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// year returns the year a DATE tag starts with ("2021-03-05" → "2021"),
+// or "" if it doesn't.
+func year(date string) string {
+	if len(date) < 4 {
+		return ""
+	}
+	if _, err := strconv.Atoi(date[:4]); err != nil {
+		return ""
+	}
+	return date[:4]
+}
+
+// stamp is what tells an indexed file changed.
+type stamp struct {
+	size    int64
+	modTime int64 // UnixNano
+}
+
+// ScanReport is the outcome of a scan.
+type ScanReport struct {
+	Roots    []string `json:"roots"`
+	Files    int      `json:"files"`             // FLACs found
+	Added    int      `json:"added"`             // newly indexed
+	Updated  int      `json:"updated"`           // read again, having changed
+	Removed  int      `json:"removed"`           // gone, or no longer under a root
+	Failed   int      `json:"failed"`            // read, but indexed with an Error
+	Pending  int      `json:"pending,omitempty"` // still being written, left for the next scan (see Index.Watch)
+	Errors   []string `json:"errors,omitempty"`
+	Duration float64  `json:"duration"` // seconds
+
+	New []string `json:"-"` // paths of the files added
+}
+
+// scanResult is what a scan changes in the index.
+type scanResult struct {
+	report  ScanReport
+	changed []Track  // to insert or replace
+	removed []string // paths to delete
+}
+
+// progressEvery is how many files a scan finds between two progress
+// calls.
+const progressEvery = 100
+
+// scan walks roots for .flac files, skipping hidden directories, and reads
+// the ones known doesn't hold or holds with another size or modification
+// time. Files modified less than settle ago may still be being written,
+// and are left as they are for a later scan. Paths known holds that weren't
+// found are removed, unless they are under a root that couldn't be read,
+// e.g. an unplugged drive. progress, if set, is called with the number of
+// files found so far. It stops early, returning ctx's error, if ctx is
+// cancelled.
+func scan(ctx context.Context, roots []string, known map[string]stamp, settle time.Duration, progress func(files int)) (*scanResult, error) {
+	res := &scanResult{report: ScanReport{Roots: roots}}
+	now := time.Now()
+	seen := map[string]bool{}
+	var unreadable []string
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == root {
+					return err
+				}
+				return nil // unreadable subfolders are skipped
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.EqualFold(filepath.Ext(path), ".flac") || seen[path] {
+				return nil // roots may nest
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			seen[path] = true
+			res.report.Files++
+			if progress != nil && res.report.Files%progressEvery == 0 {
+				progress(res.report.Files)
+			}
+			old, ok := known[path]
+			if ok && old == (stamp{info.Size(), info.ModTime().UnixNano()}) {
+				return nil
+			}
+			if settle > 0 && now.Sub(info.ModTime()) < settle {
+				res.report.Pending++
+				return nil
+			}
+			t := Read(root, path, info)
+			if t.Error != "" {
+				res.report.Failed++
+			}
+			if ok {
+				res.report.Updated++
+			} else {
+				res.report.Added++
+				res.report.New = append(res.report.New, path)
+			}
+			res.changed = append(res.changed, t)
+			return nil
+		})
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			unreadable = append(unreadable, root)
+			res.report.Errors = append(res.report.Errors, err.Error())
+		}
+	}
+	for path := range known {
+		if !seen[path] && !under(path, unreadable) {
+			res.removed = append(res.removed, path)
+		}
+	}
+	res.report.Removed = len(res.removed)
+	return res, nil
+}
+
+// under reports whether path is inside one of roots.
+func under(path string, roots []string) bool {
+	for _, root := range roots {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
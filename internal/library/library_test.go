@@ -0,0 +1,151 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/flacmeta/flactest"
+	"flacidal/internal/quality"
+)
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.flac")
+	flactest.Write(t, path, flactest.Seconds(flactest.HiRes, 90), flactest.Tags(
+		flacmeta.Field{Name: "TITLE", Value: "Song"},
+		flacmeta.Field{Name: "ARTIST", Value: "A"},
+		flacmeta.Field{Name: "ARTIST", Value: "B"},
+		flacmeta.Field{Name: "TRACKNUMBER", Value: "3/12"},
+		flacmeta.Field{Name: "DATE", Value: "2021-03-05"},
+		flacmeta.Field{Name: "ORGANIZATION", Value: "Warp"},
+		flacmeta.Field{Name: "CATALOGNUMBER", Value: "WARPCD92"},
+	))
+	info, _ := os.Stat(path)
+	got := Read("root", path, info)
+	if got.Title != "Song" || got.Artist != "A; B" || got.TrackNumber != 3 || got.Year != "2021" || got.Label != "Warp" || got.Catalog != "WARPCD92" {
+		t.Errorf("tags: %+v", got)
+	}
+	if got.Duration != 90 || got.Quality != quality.Tier(24, 96000) || got.Channels != 2 || got.MD5 != "" || got.Error != "" {
+		t.Errorf("format: %+v", got)
+	}
+}
+
+func TestScan_Incremental(t *testing.T) {
+	root := t.TempDir()
+	a, b := filepath.Join(root, "Artist", "Album", "01.flac"), filepath.Join(root, "02.flac")
+	flactest.Write(t, a, flactest.Seconds(flactest.HiRes, 10), flactest.Tags(flacmeta.Field{Name: "TITLE", Value: "One"}))
+	flactest.Write(t, b, flactest.Seconds(flactest.HiRes, 10), flactest.Tags(flacmeta.Field{Name: "TITLE", Value: "Two"}))
+	flactest.Write(t, filepath.Join(root, ".hidden", "x.flac"), flactest.Seconds(flactest.HiRes, 10))
+	os.WriteFile(filepath.Join(root, "broken.flac"), []byte("not a flac"), 0644)
+
+	res, err := scan(context.Background(), []string{root}, nil, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := res.report; r.Files != 3 || r.Added != 3 || r.Failed != 1 || len(res.changed) != 3 {
+		t.Fatalf("first scan: %+v", r)
+	}
+
+	known := map[string]stamp{}
+	for _, tr := range res.changed {
+		known[tr.Path] = stamp{tr.Size, tr.ModTime.UnixNano()}
+	}
+	known[filepath.Join(root, "gone.flac")] = stamp{1, 1}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(b, later, later)
+
+	res, err = scan(context.Background(), []string{root}, known, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := res.report; r.Updated != 1 || r.Added != 0 || r.Removed != 1 || len(res.changed) != 1 || res.changed[0].Path != b {
+		t.Errorf("rescan: %+v, changed %v", r, res.changed)
+	}
+}
+
+func TestScan_LeavesUnsettledFiles(t *testing.T) {
+	root := t.TempDir()
+	old, fresh := filepath.Join(root, "old.flac"), filepath.Join(root, "fresh.flac")
+	flactest.Write(t, old, flactest.Seconds(flactest.HiRes, 10))
+	flactest.Write(t, fresh, flactest.Seconds(flactest.HiRes, 10))
+	earlier := time.Now().Add(-time.Minute)
+	os.Chtimes(old, earlier, earlier)
+
+	res, err := scan(context.Background(), []string{root}, nil, 30*time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := res.report; r.Added != 1 || r.Pending != 1 || !slices.Equal(r.New, []string{old}) {
+		t.Errorf("scan: %+v", r)
+	}
+}
+
+func TestScan_KeepsUnreadableRoots(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "unplugged")
+	known := map[string]stamp{filepath.Join(missing, "a.flac"): {1, 1}, "/elsewhere/b.flac": {1, 1}}
+	res, err := scan(context.Background(), []string{missing}, known, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.report.Errors) != 1 || !slices.Equal(res.removed, []string{"/elsewhere/b.flac"}) {
+		t.Errorf("errors %v, removed %v; want the unplugged drive's tracks kept", res.report.Errors, res.removed)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	where, args := Query{Search: "50%  off", Artist: "Björk", Genre: "Pop", Label: "Warp"}.where()
+	if strings.Count(where, " AND ") != 4 || len(args) != 17 || args[0] != `%50\%%` || args[16] != "Warp" {
+		t.Errorf("where = %q, args %v", where, args)
+	}
+	if where, args := (Query{}).where(); where != "" || args != nil {
+		t.Errorf("empty query: %q, %v", where, args)
+	}
+	if got := (Query{Sort: "title", Desc: true}).orderBy(); got != " ORDER BY title COLLATE NOCASE DESC, path DESC" {
+		t.Errorf("orderBy = %q", got)
+	}
+	if (Query{Sort: "bpm"}).Validate() == nil || (Query{Limit: -1}).Validate() == nil {
+		t.Error("bad sort or limit accepted")
+	}
+	if (Query{Limit: 5000}).limit() != MaxLimit {
+		t.Error("limit not capped")
+	}
+}
+
+func TestGroupDuplicates(t *testing.T) {
+	tracks := []Track{
+		{Path: "/a/cd.flac", ISRC: "usabc2100001", MD5: "m1", BitDepth: 16, SampleRate: 44100, Size: 30},
+		{Path: "/a/hires.flac", ISRC: "USABC2100001", MD5: "m2", BitDepth: 24, SampleRate: 96000, Size: 90},
+		{Path: "/b/copy.flac", MD5: "m1", BitDepth: 16, SampleRate: 44100, Size: 30}, // untagged copy of cd.flac
+		{Path: "/b/other.flac", ISRC: "USABC2100002", MD5: "m3", BitDepth: 16, SampleRate: 44100, Size: 30},
+		{Path: "/b/broken.flac", ISRC: "USABC2100002", BitDepth: 24, SampleRate: 192000, Size: 5, Error: "bad"},
+		{Path: "/b/alone.flac", ISRC: "USABC2100003", MD5: "m4"},
+	}
+
+	groups := groupDuplicates(slices.Clone(tracks), true, true)
+	if len(groups) != 2 {
+		t.Fatalf("groups = %+v", groups)
+	}
+	var paths []string
+	for _, tr := range groups[0].Tracks {
+		paths = append(paths, tr.Path)
+	}
+	if want := []string{"/a/hires.flac", "/a/cd.flac", "/b/copy.flac"}; !slices.Equal(paths, want) {
+		t.Errorf("ISRC and MD5 group = %v, want %v (best first)", paths, want)
+	}
+	if g := groups[0]; g.ISRC != "USABC2100001" || g.MD5 != "m1" || g.Identical || g.Wasted != 60 {
+		t.Errorf("ISRC and MD5 group = %+v", g)
+	}
+	if g := groups[1]; g.Tracks[0].Path != "/b/other.flac" || g.Wasted != 5 {
+		t.Errorf("readable files come first: %+v", g)
+	}
+
+	groups = groupDuplicates(slices.Clone(tracks), false, true)
+	if len(groups) != 1 || len(groups[0].Tracks) != 2 || !groups[0].Identical || groups[0].ISRC != "" {
+		t.Errorf("MD5 only = %+v", groups)
+	}
+}
@@ -0,0 +1,143 @@
+package library
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+)
+
+// linkSchema links downloads in the history to the indexed file each
+// became, by the file's row ID rather than its path, so the link survives
+// the file being moved or renamed (see Move). The UPSERT in apply and
+// Move's UPDATE keep a file's row ID as its row changes.
+const linkSchema = `
+CREATE TABLE IF NOT EXISTS history_links (
+	track_id TEXT PRIMARY KEY,
+	file     INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS history_links_file ON history_links (file);
+`
+
+// upsert indexes a Track, keeping the row ID of a path already indexed.
+var upsert = "INSERT INTO library (" + columns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)" +
+	" ON CONFLICT (path) DO UPDATE SET root = excluded.root, title = excluded.title, artist = excluded.artist," +
+	" album_artist = excluded.album_artist, album = excluded.album, genre = excluded.genre, year = excluded.year," +
+	" label = excluded.label, catalog = excluded.catalog, track_number = excluded.track_number," +
+	" disc_number = excluded.disc_number, isrc = excluded.isrc, md5 = excluded.md5, sample_rate = excluded.sample_rate," +
+	" bit_depth = excluded.bit_depth, channels = excluded.channels, duration = excluded.duration, size = excluded.size," +
+	" mod_time = excluded.mod_time, error = excluded.error"
+
+// upsertArgs are t's values for upsert.
+func upsertArgs(t Track) []any {
+	return []any{t.Path, t.Root, t.Title, t.Artist, t.AlbumArtist, t.Album, t.Genre, t.Year, t.Label, t.Catalog,
+		t.TrackNumber, t.DiscNumber, t.ISRC, t.MD5, t.SampleRate, t.BitDepth, t.Channels,
+		t.Duration, t.Size, t.ModTime.UnixNano(), t.Error}
+}
+
+// execer is what links need of a *sql.DB or *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Link records that the download of trackID, as the download manager
+// numbers it, became the FLAC at path under root, indexing the file now if
+// a scan hasn't yet. A later Link for trackID replaces the link.
+func (x *Index) Link(trackID, root, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tx, err := x.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after Commit
+	var id int64
+	if err := tx.QueryRow("SELECT rowid FROM library WHERE path = ?", path).Scan(&id); errors.Is(err, sql.ErrNoRows) {
+		if _, err := tx.Exec(upsert, upsertArgs(Read(root, path, info))...); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT OR REPLACE INTO history_links (track_id, file) VALUES (?, (SELECT rowid FROM library WHERE path = ?))", trackID, path); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// LinkedPath returns where the file trackID's download became is now, or
+// "" if it isn't linked to an indexed file, e.g. because it was deleted.
+func (x *Index) LinkedPath(trackID string) (string, error) {
+	var path string
+	err := x.db.QueryRow("SELECT l.path FROM history_links h JOIN library l ON l.rowid = h.file WHERE h.track_id = ?", trackID).Scan(&path)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return path, err
+}
+
+// Move records that the indexed file at from is now at to, keeping its
+// history links. A row already at to, a file that was replaced, is
+// dropped. Moving a file the index doesn't hold does nothing.
+func (x *Index) Move(from, to string) error {
+	if from == to {
+		return nil
+	}
+	tx, err := x.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after Commit
+	if err := remove(tx, to); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE library SET path = ? WHERE path = ?", to, from); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Remove drops the files at paths from the index, with their history
+// links.
+func (x *Index) Remove(paths ...string) error {
+	tx, err := x.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after Commit
+	for _, p := range paths {
+		if err := remove(tx, p); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// remove drops the file at path and its history links.
+func remove(db execer, path string) error {
+	if _, err := db.Exec("DELETE FROM history_links WHERE file IN (SELECT rowid FROM library WHERE path = ?)", path); err != nil {
+		return err
+	}
+	_, err := db.Exec("DELETE FROM library WHERE path = ?", path)
+	return err
+}
+
+// relink points the history links of the file at path, which a scan found
+// gone, to a file the same scan added with the same audio MD5: the file
+// moved outside FLACidal. Links with nowhere to go are dropped with the
+// file.
+func relink(db execer, path string, added map[string]string) error {
+	var md5 string
+	if err := db.QueryRow("SELECT md5 FROM library WHERE path = ?", path).Scan(&md5); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if to, ok := added[md5]; ok && md5 != "" {
+		_, err := db.Exec("UPDATE history_links SET file = (SELECT rowid FROM library WHERE path = ?) WHERE file IN (SELECT rowid FROM library WHERE path = ?)", to, path)
+		if err != nil {
+			return err
+		}
+	}
+	return remove(db, path)
+}
@@ -0,0 +1,75 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/flacmeta/flactest"
+)
+
+func TestLinks(t *testing.T) {
+	x, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Close()
+	root := t.TempDir()
+	a, b := filepath.Join(root, "a.flac"), filepath.Join(root, "Album", "b.flac")
+	flactest.Write(t, a, flactest.Seconds(flactest.HiRes, 10), flactest.Tags(flacmeta.Field{Name: "TITLE", Value: "Song"}))
+
+	linked := func(want string) {
+		t.Helper()
+		if got, err := x.LinkedPath("42"); err != nil || got != want {
+			t.Errorf("LinkedPath = %q, %v, want %q", got, err, want)
+		}
+	}
+	linked("")
+	if err := x.Link("42", root, a); err != nil {
+		t.Fatal(err)
+	}
+	linked(a)
+
+	// Moved by FLACidal, then rescanned: the link follows the file
+	os.MkdirAll(filepath.Dir(b), 0755)
+	if err := os.Rename(a, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Move(a, b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.Scan(context.Background(), []string{root}, nil); err != nil {
+		t.Fatal(err)
+	}
+	linked(b)
+
+	// Moved elsewhere: the scan finds it again by its audio MD5
+	data, err := os.ReadFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(data[8+18:], []byte{1, 2, 3}) // STREAMINFO's MD5
+	if err := os.WriteFile(b, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.Scan(context.Background(), []string{root}, nil); err != nil {
+		t.Fatal(err)
+	}
+	c := filepath.Join(root, "c.flac")
+	if err := os.Rename(b, c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.Scan(context.Background(), []string{root}, nil); err != nil {
+		t.Fatal(err)
+	}
+	linked(c)
+
+	if err := x.Remove(c); err != nil {
+		t.Fatal(err)
+	}
+	linked("")
+}
@@ -0,0 +1,136 @@
+package library
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Page sizes of Tracks.
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// sorts are the orders Query.Sort picks from, as ORDER BY columns. Ties
+// fall back to the album's track order, and last to the path.
+var sorts = map[string][]string{
+	"artist":   {"artist COLLATE NOCASE", "album COLLATE NOCASE", "disc_number", "track_number", "path"},
+	"album":    {"album COLLATE NOCASE", "disc_number", "track_number", "path"},
+	"title":    {"title COLLATE NOCASE", "path"},
+	"year":     {"year", "album COLLATE NOCASE", "disc_number", "track_number", "path"},
+	"label":    {"label COLLATE NOCASE", "catalog COLLATE NOCASE", "album COLLATE NOCASE", "disc_number", "track_number", "path"},
+	"modified": {"mod_time", "path"},
+	"duration": {"duration", "path"},
+	"size":     {"size", "path"},
+	"quality":  {"bit_depth", "sample_rate", "path"},
+	"path":     {"path"},
+}
+
+// Sorts returns the names Query.Sort accepts, sorted.
+func Sorts() []string {
+	names := make([]string, 0, len(sorts))
+	for name := range sorts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Query selects and orders indexed tracks. The zero Query lists every
+// track by artist, album and track number.
+type Query struct {
+	// Search keeps tracks whose title, artist, album artist, album, label
+	// or catalog number contain every word of it, ignoring case.
+	Search string `json:"search,omitempty"`
+	// Artist keeps the tracks of one artist: its artist, one of its
+	// artists, or its album artist, ignoring case.
+	Artist string `json:"artist,omitempty"`
+	// Album keeps the tracks of one album, ignoring case.
+	Album string `json:"album,omitempty"`
+	// Genre keeps the tracks with one genre among theirs, ignoring case.
+	Genre string `json:"genre,omitempty"`
+	// Label keeps the tracks of one record label, ignoring case.
+	Label string `json:"label,omitempty"`
+	// Root keeps the tracks found under one library folder.
+	Root   string `json:"root,omitempty"`
+	Sort   string `json:"sort,omitempty"` // one of Sorts; "artist" when empty
+	Desc   bool   `json:"desc,omitempty"`
+	Limit  int    `json:"limit,omitempty"` // DefaultLimit when 0, at most MaxLimit
+	Offset int    `json:"offset,omitempty"`
+}
+
+// Validate rejects sorts and pages Tracks can't list.
+func (q Query) Validate() error {
+	if _, ok := sorts[q.Sort]; q.Sort != "" && !ok {
+		return fmt.Errorf("unknown sort %q (want one of %s)", q.Sort, strings.Join(Sorts(), ", "))
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return fmt.Errorf("limit and offset must not be negative")
+	}
+	return nil
+}
+
+// limit is q's page size.
+func (q Query) limit() int {
+	if q.Limit == 0 {
+		return DefaultLimit
+	}
+	return min(q.Limit, MaxLimit)
+}
+
+// orderBy is q's ORDER BY clause.
+func (q Query) orderBy() string {
+	columns := sorts[q.Sort]
+	if columns == nil {
+		columns = sorts["artist"]
+	}
+	if q.Desc {
+		columns = slices.Clone(columns)
+		for i := range columns {
+			columns[i] += " DESC"
+		}
+	}
+	return " ORDER BY " + strings.Join(columns, ", ")
+}
+
+// where is q's WHERE clause, "" when it keeps every track, and its
+// arguments.
+func (q Query) where() (string, []any) {
+	var conds []string
+	var args []any
+	for _, word := range strings.Fields(q.Search) {
+		conds = append(conds, `(title LIKE ? ESCAPE '\' OR artist LIKE ? ESCAPE '\' OR album_artist LIKE ? ESCAPE '\' OR album LIKE ? ESCAPE '\' OR label LIKE ? ESCAPE '\' OR catalog LIKE ? ESCAPE '\')`)
+		like := "%" + escapeLike(word) + "%"
+		args = append(args, like, like, like, like, like, like)
+	}
+	if q.Artist != "" {
+		conds = append(conds, `(album_artist = ? COLLATE NOCASE OR artist = ? COLLATE NOCASE OR '; ' || artist || '; ' LIKE ? ESCAPE '\')`)
+		args = append(args, q.Artist, q.Artist, "%; "+escapeLike(q.Artist)+"; %")
+	}
+	if q.Album != "" {
+		conds = append(conds, "album = ? COLLATE NOCASE")
+		args = append(args, q.Album)
+	}
+	if q.Genre != "" {
+		conds = append(conds, `'; ' || genre || '; ' LIKE ? ESCAPE '\'`)
+		args = append(args, "%; "+escapeLike(q.Genre)+"; %")
+	}
+	if q.Label != "" {
+		conds = append(conds, "label = ? COLLATE NOCASE")
+		args = append(args, q.Label)
+	}
+	if q.Root != "" {
+		conds = append(conds, "root = ?")
+		args = append(args, q.Root)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// escapeLike escapes LIKE's wildcards in s, for ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
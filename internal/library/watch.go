@@ -0,0 +1,48 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"flacidal/internal/fswatch"
+)
+
+// WatchSettle is how long a file must go unmodified before Watch indexes
+// it, so a file still being copied into the library isn't read
+// half-finished.
+const WatchSettle = 5 * time.Second
+
+// Watch keeps the index up to date with the folders roots returns until
+// ctx is done, rescanning them once a change in them has settled (see
+// internal/fswatch). Where the OS can't report changes, the folders are
+// rescanned every interval instead; since a scan only reads the files that
+// changed, a quiet library costs a walk of its folders. roots is called
+// every interval so settings changes apply without a restart; nil pauses
+// watching. onScan is called after each scan that changed the index or
+// failed; a change that finds a manual scan running is looked at again
+// once it settles anew.
+func (x *Index) Watch(ctx context.Context, interval time.Duration, roots func() []string, onScan func(*ScanReport, error)) {
+	fswatch.Run(ctx, fswatch.Options{
+		Dirs:      roots,
+		Recursive: true,
+		Quiet:     WatchSettle + time.Second,
+		Poll:      interval,
+	}, func() bool {
+		r := roots()
+		if len(r) == 0 {
+			return true
+		}
+		report, err := x.scan(ctx, r, WatchSettle, nil)
+		switch {
+		case errors.Is(err, ErrScanning):
+			return false
+		case ctx.Err() != nil:
+		case err != nil:
+			onScan(nil, err)
+		case report.Added+report.Updated+report.Removed > 0:
+			onScan(report, nil)
+		}
+		return true
+	})
+}
@@ -0,0 +1,177 @@
+// Package logging provides FLACidal's structured logger: log/slog records
+// tagged with a component ("http", "ws", "downloads"…) and filtered by a
+// per-component level that can be changed while the app is running, so a
+// noisy subsystem can be quieted, or traced, without a restart.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// ComponentKey is the attribute that names a record's component.
+const ComponentKey = "component"
+
+// Components FLACidal logs under. Levels accepts any name; these are the
+// ones the settings UI lists.
+const (
+	HTTP      = "http"      // HTTP access log
+	WebSocket = "ws"        // WebSocket hubs
+	Server    = "server"    // headless server lifecycle
+	Downloads = "downloads" // per-track queue progress
+)
+
+// Components returns the known component names.
+func Components() []string {
+	return []string{HTTP, WebSocket, Server, Downloads}
+}
+
+// ParseLevel parses debug, info, warn (or warning) and error,
+// case-insensitively.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// LevelName is the lower-case name ParseLevel accepts for l.
+func LevelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
+
+// Levels holds the minimum level per component, falling back to a default
+// for components without their own. The zero value logs everything at Info
+// and above; it is safe for concurrent use.
+type Levels struct {
+	mu  sync.RWMutex
+	def slog.Level
+	m   map[string]slog.Level
+}
+
+// Level returns the minimum level for component.
+func (l *Levels) Level(component string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if lvl, ok := l.m[component]; ok {
+		return lvl
+	}
+	return l.def
+}
+
+// Set sets the minimum level for component; "" or "*" sets the default.
+func (l *Levels) Set(component string, level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if component == "" || component == "*" {
+		l.def = level
+		return
+	}
+	if l.m == nil {
+		l.m = make(map[string]slog.Level)
+	}
+	l.m[component] = level
+}
+
+// Snapshot returns every configured level by name, the default under "*".
+func (l *Levels) Snapshot() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := map[string]string{"*": LevelName(l.def)}
+	for c, lvl := range l.m {
+		out[c] = LevelName(lvl)
+	}
+	return out
+}
+
+// ParseSpec applies a comma-separated spec such as "warn,http=error,ws=debug":
+// a bare level sets the default, component=level sets one component. Nothing
+// is applied if any entry is invalid.
+func (l *Levels) ParseSpec(spec string) error {
+	type entry struct {
+		component string
+		level     slog.Level
+	}
+	var entries []entry
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		component, name, ok := strings.Cut(part, "=")
+		if !ok {
+			component, name = "", part
+		}
+		lvl, err := ParseLevel(name)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{strings.TrimSpace(component), lvl})
+	}
+	for _, e := range entries {
+		l.Set(e.component, e.level)
+	}
+	return nil
+}
+
+// New returns a logger that filters records by levels and writes the
+// survivors to next. next should accept every level; filtering is done
+// here.
+func New(levels *Levels, next slog.Handler) *slog.Logger {
+	return slog.New(&handler{levels: levels, next: next})
+}
+
+// NewText is New writing slog's text format to w, the usual setup for
+// console output.
+func NewText(levels *Levels, w io.Writer) *slog.Logger {
+	return New(levels, slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// Component returns logger tagged with component, so its records are
+// filtered by that component's level.
+func Component(logger *slog.Logger, component string) *slog.Logger {
+	return logger.With(ComponentKey, component)
+}
+
+// handler is the level-filtering slog.Handler behind New.
+type handler struct {
+	levels    *Levels
+	next      slog.Handler
+	component string
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.Level(h.component)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	for _, a := range attrs {
+		if a.Key == ComponentKey {
+			clone.component = a.Value.String()
+		}
+	}
+	clone.next = h.next.WithAttrs(attrs)
+	return &clone
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	return &clone
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newTestLogger(levels *Levels) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	next := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return New(levels, next), &buf
+}
+
+func TestComponentLevels(t *testing.T) {
+	var levels Levels
+	logger, buf := newTestLogger(&levels)
+	httpLog := Component(logger, HTTP)
+	wsLog := Component(logger, WebSocket)
+
+	httpLog.Info("GET /api/config")
+	wsLog.Debug("frame sent")
+	if out := buf.String(); !strings.Contains(out, "GET /api/config") || strings.Contains(out, "frame sent") {
+		t.Fatalf("default levels: output = %q", out)
+	}
+
+	buf.Reset()
+	levels.Set(HTTP, slog.LevelWarn)
+	levels.Set(WebSocket, slog.LevelDebug)
+	httpLog.Info("GET /api/config")
+	wsLog.Debug("frame sent")
+	if out := buf.String(); strings.Contains(out, "GET /api/config") || !strings.Contains(out, "frame sent") {
+		t.Errorf("after Set: output = %q", out)
+	}
+	if !strings.Contains(buf.String(), "component=ws") {
+		t.Errorf("component attribute missing: %q", buf.String())
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	var levels Levels
+	if err := levels.ParseSpec("warn, http=error,ws=debug"); err != nil {
+		t.Fatal(err)
+	}
+	if levels.Level("downloads") != slog.LevelWarn || levels.Level(HTTP) != slog.LevelError || levels.Level(WebSocket) != slog.LevelDebug {
+		t.Errorf("levels = %v", levels.Snapshot())
+	}
+
+	if err := levels.ParseSpec("http=info,ws=loud"); err == nil {
+		t.Error("invalid level should be rejected")
+	}
+	if levels.Level(HTTP) != slog.LevelError {
+		t.Error("a rejected spec must not be partially applied")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"DEBUG": slog.LevelDebug, "warning": slog.LevelWarn, " error ": slog.LevelError} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v", in, got, err)
+		}
+	}
+	if LevelName(slog.LevelWarn) != "warn" {
+		t.Errorf("LevelName(warn) = %q", LevelName(slog.LevelWarn))
+	}
+}
@@ -0,0 +1,249 @@
+// Package lyricscache remembers LRCLIB lookups in lyrics_cache.json, next
+// to core's database, keyed by title, artist and duration. Fetching a
+// track's lyrics again, from the lyrics panel, a batch or a tag import,
+// is answered from the cache until the entry expires; tracks LRCLIB has no
+// lyrics for are remembered too, for less long. Lookups that do reach
+// LRCLIB are spaced out, and held back longer after LRCLIB throttles.
+package lyricscache
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// FileName is the cache file name inside the data directory.
+const FileName = "lyrics_cache.json"
+
+const (
+	// TTL is how long found lyrics are kept.
+	TTL = 30 * 24 * time.Hour
+	// MissTTL is how long a track without lyrics is kept, shorter as
+	// LRCLIB gains lyrics over time.
+	MissTTL = 24 * time.Hour
+	// MinInterval is the least time between two LRCLIB requests.
+	MinInterval = 500 * time.Millisecond
+	// Backoff is how long requests are held back after LRCLIB throttles.
+	Backoff = 30 * time.Second
+)
+
+// maxEntries bounds the file; the oldest entries are dropped beyond it.
+const maxEntries = 5000
+
+// ErrNotFound is returned for a track the cache knows has no lyrics.
+var ErrNotFound = errors.New("lyrics not found")
+
+// Key identifies a lookup. Title and artist match regardless of case,
+// spaces and punctuation.
+type Key struct {
+	Title    string
+	Artist   string
+	Duration int // seconds; 0 when unknown
+}
+
+// id is the map key for k.
+func (k Key) id() string {
+	return fold(k.Title) + "|" + fold(k.Artist) + "|" + strconv.Itoa(k.Duration)
+}
+
+// fold keeps s's letters and digits, lower-cased, one space between words.
+func fold(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+type entry struct {
+	Lyrics  json.RawMessage `json:"lyrics,omitempty"` // nil for a miss
+	Fetched time.Time       `json:"fetched"`
+}
+
+// Cache is a concurrency-safe, persisted lyrics cache. A nil *Cache caches
+// and paces nothing: every lookup reaches LRCLIB.
+type Cache struct {
+	TTL      time.Duration // TTL when 0
+	MissTTL  time.Duration // MissTTL when 0
+	Interval time.Duration // MinInterval when 0
+
+	dir string
+
+	mu      sync.Mutex
+	entries map[string]entry
+
+	pace sync.Mutex
+	next time.Time // no request before then
+}
+
+// Open loads the cache in dir. A missing file is not an error. On a read
+// error the returned Cache is empty but usable.
+func Open(dir string) (*Cache, error) {
+	c := &Cache{dir: dir, entries: map[string]entry{}}
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		c.entries = map[string]entry{}
+		return c, err
+	}
+	return c, nil
+}
+
+// Lookup returns the lyrics for k from c, or calls fetch and caches what it
+// returns. A nil result or an error from fetch is cached as a miss, and
+// answered with ErrNotFound until it expires, unless the error is a network
+// failure or throttling, which is returned and not cached. Only the cache
+// write can fail silently: the lyrics are returned regardless.
+func Lookup[T any](c *Cache, k Key, fetch func() (*T, error)) (*T, error) {
+	if c == nil {
+		return fetch()
+	}
+	if v, ok, err := get[T](c, k); ok {
+		return v, err
+	}
+	c.wait()
+	v, err := fetch()
+	switch {
+	case throttled(err):
+		c.backOff()
+		return nil, err
+	case transient(err):
+		return nil, err
+	case err != nil || v == nil:
+		c.put(k, nil) //nolint:errcheck // a cache write failing only costs a lookup
+		return nil, cmp.Or(err, ErrNotFound)
+	}
+	if data, merr := json.Marshal(v); merr == nil {
+		c.put(k, data) //nolint:errcheck // a cache write failing only costs a lookup
+	}
+	return v, nil
+}
+
+// get returns k's unexpired entry: its lyrics, or ErrNotFound for a miss.
+func get[T any](c *Cache, k Key) (*T, bool, error) {
+	c.mu.Lock()
+	e, ok := c.entries[k.id()]
+	c.mu.Unlock()
+	if !ok || time.Since(e.Fetched) > c.ttl(e) {
+		return nil, false, nil
+	}
+	if e.Lyrics == nil {
+		return nil, true, ErrNotFound
+	}
+	v := new(T)
+	if err := json.Unmarshal(e.Lyrics, v); err != nil {
+		return nil, false, nil
+	}
+	return v, true, nil
+}
+
+// put stores lyrics, nil for a miss, for k and writes the file.
+func (c *Cache) put(k Key, lyrics json.RawMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]entry{}
+	}
+	c.entries[k.id()] = entry{Lyrics: lyrics, Fetched: time.Now()}
+	c.prune()
+	return c.save()
+}
+
+// Len returns the number of entries, expired ones included.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache) ttl(e entry) time.Duration {
+	if e.Lyrics == nil {
+		return cmp.Or(c.MissTTL, MissTTL)
+	}
+	return cmp.Or(c.TTL, TTL)
+}
+
+// prune drops expired entries, then the oldest beyond maxEntries; callers
+// hold c.mu.
+func (c *Cache) prune() {
+	for id, e := range c.entries {
+		if time.Since(e.Fetched) > c.ttl(e) {
+			delete(c.entries, id)
+		}
+	}
+	if len(c.entries) <= maxEntries {
+		return
+	}
+	ids := make([]string, 0, len(c.entries))
+	for id := range c.entries {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		return c.entries[a].Fetched.Compare(c.entries[b].Fetched)
+	})
+	for _, id := range ids[:len(ids)-maxEntries] {
+		delete(c.entries, id)
+	}
+}
+
+// save writes c.entries; callers hold c.mu.
+func (c *Cache) save() error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.dir, FileName), data, 0644)
+}
+
+// wait blocks until c may send its next request, and books it.
+func (c *Cache) wait() {
+	c.pace.Lock()
+	defer c.pace.Unlock()
+	if d := time.Until(c.next); d > 0 {
+		time.Sleep(d)
+	}
+	c.next = time.Now().Add(cmp.Or(c.Interval, MinInterval))
+}
+
+// backOff holds the next request back for Backoff.
+func (c *Cache) backOff() {
+	c.pace.Lock()
+	defer c.pace.Unlock()
+	c.next = time.Now().Add(Backoff)
+}
+
+// throttled reports whether err is LRCLIB asking for fewer requests.
+// Errors from the lyrics client aren't typed, so this goes by their text.
+func throttled(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "429") || strings.Contains(msg, "too many requests")
+}
+
+// transient reports whether err is a failure to reach LRCLIB, rather than
+// an answer, so it must not be cached as a miss.
+func transient(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
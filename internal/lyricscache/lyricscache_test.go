@@ -0,0 +1,134 @@
+package lyricscache
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+type lyrics struct {
+	Plain string `json:"plain"`
+}
+
+func open(t *testing.T) *Cache {
+	t.Helper()
+	c, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Interval = time.Millisecond
+	return c
+}
+
+// fetcher returns a fetch func answering with v and err, and a counter of
+// its calls.
+func fetcher(v *lyrics, err error) (func() (*lyrics, error), *int) {
+	n := new(int)
+	return func() (*lyrics, error) { *n++; return v, err }, n
+}
+
+func TestLookup_CachesHits(t *testing.T) {
+	c := open(t)
+	fetch, n := fetcher(&lyrics{Plain: "la la"}, nil)
+
+	for i := range 2 {
+		got, err := Lookup(c, Key{"Song", "Artist", 200}, fetch)
+		if err != nil || got.Plain != "la la" {
+			t.Fatalf("lookup %d = %+v, %v", i, got, err)
+		}
+	}
+	// Title and artist match regardless of case and punctuation.
+	if _, err := Lookup(c, Key{"song!", "ARTIST", 200}, fetch); err != nil {
+		t.Fatal(err)
+	}
+	if *n != 1 {
+		t.Errorf("fetched %d times, want 1", *n)
+	}
+	// Another duration is another track.
+	Lookup(c, Key{"Song", "Artist", 201}, fetch)
+	if *n != 2 {
+		t.Errorf("fetched %d times, want 2", *n)
+	}
+
+	// The cache survives a restart.
+	c2, err := Open(c.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Lookup(c2, Key{"Song", "Artist", 200}, fetch); err != nil || got.Plain != "la la" || *n != 2 {
+		t.Errorf("reopened: %+v, %v after %d fetches", got, err, *n)
+	}
+}
+
+func TestLookup_CachesMisses(t *testing.T) {
+	c := open(t)
+	fetch, n := fetcher(nil, errors.New("no lyrics found"))
+	k := Key{"Song", "Artist", 200}
+
+	if _, err := Lookup(c, k, fetch); err == nil || err.Error() != "no lyrics found" {
+		t.Fatalf("first lookup: %v", err)
+	}
+	if _, err := Lookup(c, k, fetch); !errors.Is(err, ErrNotFound) {
+		t.Errorf("cached miss: %v, want ErrNotFound", err)
+	}
+	if *n != 1 {
+		t.Errorf("fetched %d times, want 1", *n)
+	}
+
+	// Misses expire sooner than hits.
+	c.MissTTL = time.Millisecond
+	time.Sleep(2 * time.Millisecond)
+	Lookup(c, k, fetch)
+	if *n != 2 {
+		t.Errorf("expired miss: fetched %d times, want 2", *n)
+	}
+}
+
+func TestLookup_DoesNotCacheFailures(t *testing.T) {
+	c := open(t)
+	k := Key{"Song", "Artist", 200}
+
+	fetch, n := fetcher(nil, &net.OpError{Op: "dial", Err: errors.New("refused")})
+	Lookup(c, k, fetch)
+	Lookup(c, k, fetch)
+	if *n != 2 {
+		t.Errorf("network error: fetched %d times, want 2", *n)
+	}
+
+	fetch, n = fetcher(nil, errors.New("LRCLIB returned status 429"))
+	Lookup(c, k, fetch)
+	if *n != 1 || c.Len() != 0 {
+		t.Errorf("throttled: fetched %d times, %d entries", *n, c.Len())
+	}
+	// Throttling holds the next request back.
+	if d := time.Until(c.next); d < Backoff-time.Second {
+		t.Errorf("next request in %v, want about %v", d, Backoff)
+	}
+}
+
+func TestLookup_Paces(t *testing.T) {
+	c := open(t)
+	c.Interval = 20 * time.Millisecond
+	fetch, _ := fetcher(&lyrics{}, nil)
+
+	start := time.Now()
+	for _, title := range []string{"a", "b", "c"} {
+		Lookup(c, Key{Title: title}, fetch)
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Errorf("3 requests took %v, want at least 40ms", d)
+	}
+}
+
+func TestLookup_NilCache(t *testing.T) {
+	fetch, n := fetcher(&lyrics{Plain: "x"}, nil)
+	for range 2 {
+		if got, err := Lookup(nil, Key{Title: "a"}, fetch); err != nil || got.Plain != "x" {
+			t.Fatalf("nil cache: %+v, %v", got, err)
+		}
+	}
+	if *n != 2 {
+		t.Errorf("fetched %d times, want 2", *n)
+	}
+}
@@ -0,0 +1,70 @@
+// Package lyricsfile writes lyrics to .lrc sidecar files next to FLACs.
+// Many players and DAPs (Poweramp, foobar2000, most portable players) read
+// "<track name>.lrc" rather than the LYRICS and SYNCEDLYRICS tags.
+package lyricsfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output is where lyrics go when they are embedded into a file.
+type Output string
+
+const (
+	Embed   Output = ""        // the file's tags only
+	Both    Output = "both"    // the tags and a .lrc sidecar
+	Sidecar Output = "sidecar" // a .lrc sidecar only; the FLAC is left untouched
+)
+
+// Valid reports whether o is a known output.
+func (o Output) Valid() bool {
+	return o == Embed || o == Both || o == Sidecar
+}
+
+// Tags reports whether o writes lyrics into the file's tags.
+func (o Output) Tags() bool { return o != Sidecar }
+
+// File reports whether o writes a .lrc sidecar.
+func (o Output) File() bool { return o != Embed }
+
+// ErrEmpty is returned by Write when there are no lyrics to write.
+var ErrEmpty = errors.New("no lyrics to write")
+
+// Path returns the sidecar of the audio file at path: the same name with a
+// .lrc extension.
+func Path(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".lrc"
+}
+
+// Write saves lyrics as path's sidecar (see Path), replacing any existing
+// one, and returns the sidecar's path. Synced lyrics are preferred; plain
+// lyrics are written as they are, which players show unsynced.
+func Write(path, plain, synced string) (string, error) {
+	text := strings.TrimSpace(synced)
+	if text == "" {
+		text = strings.TrimSpace(plain)
+	}
+	if text == "" {
+		return "", ErrEmpty
+	}
+	dest := Path(path)
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".lyrics-*.lrc")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(text + "\n"); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", err
+	}
+	return dest, os.Rename(tmp.Name(), dest)
+}
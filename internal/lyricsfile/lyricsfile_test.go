@@ -0,0 +1,60 @@
+package lyricsfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	if got := Path(filepath.Join("a", "01 - Song.flac")); got != filepath.Join("a", "01 - Song.lrc") {
+		t.Errorf("Path() = %q", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	track := filepath.Join(dir, "Song.flac")
+
+	got, err := Write(track, "plain", "[00:01.00]synced\n")
+	if err != nil || got != filepath.Join(dir, "Song.lrc") {
+		t.Fatalf("Write() = %q, %v", got, err)
+	}
+	if data, _ := os.ReadFile(got); string(data) != "[00:01.00]synced\n" {
+		t.Errorf("sidecar = %q, want the synced lyrics", data)
+	}
+
+	if _, err := Write(track, "plain only", ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(got); string(data) != "plain only\n" {
+		t.Errorf("sidecar = %q, want the plain lyrics", data)
+	}
+
+	if _, err := Write(track, " ", ""); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Write() without lyrics: err = %v, want ErrEmpty", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("dir holds %d files, want just the sidecar", len(entries))
+	}
+}
+
+func TestOutput(t *testing.T) {
+	for _, tc := range []struct {
+		o          Output
+		tags, file bool
+	}{
+		{Embed, true, false},
+		{Both, true, true},
+		{Sidecar, false, true},
+	} {
+		if !tc.o.Valid() || tc.o.Tags() != tc.tags || tc.o.File() != tc.file {
+			t.Errorf("%q: Valid %v, Tags %v, File %v", tc.o, tc.o.Valid(), tc.o.Tags(), tc.o.File())
+		}
+	}
+	if Output("txt").Valid() {
+		t.Error(`Output("txt").Valid() = true`)
+	}
+}
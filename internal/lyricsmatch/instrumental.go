@@ -0,0 +1,61 @@
+package lyricsmatch
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"flacidal/internal/flacmeta"
+)
+
+// TagInstrumental is the Vorbis comment that marks a file as instrumental,
+// written once LRCLIB says so, so later lyric batches skip the file
+// instead of asking again.
+const TagInstrumental = "INSTRUMENTAL"
+
+// ErrInstrumental is returned instead of lyrics for instrumental tracks.
+var ErrInstrumental = errors.New("instrumental track, no lyrics")
+
+// instrumentalText matches lyrics that only say the track is instrumental,
+// e.g. "[Instrumental]" or "[00:00.00] (instrumental)".
+var instrumentalText = regexp.MustCompile(`(?i)^[\s\[(]*instrumental[\s\])!.]*$`)
+
+// timestamp matches an LRC line's timestamps.
+var timestamp = regexp.MustCompile(`\[\d+:\d+(?:[.:]\d+)?\]`)
+
+// IsInstrumentalText reports whether lyrics only say the track is
+// instrumental. LRCLIB flags instrumentals, but some entries carry the
+// word as their lyrics instead.
+func IsInstrumentalText(plain, synced string) bool {
+	for _, text := range []string{plain, timestamp.ReplaceAllString(synced, "")} {
+		if text = strings.TrimSpace(text); text != "" {
+			return instrumentalText.MatchString(text)
+		}
+	}
+	return false
+}
+
+// IsInstrumental reports whether the FLAC at path is tagged instrumental.
+// Unreadable files are not.
+func IsInstrumental(path string) bool {
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return false
+	}
+	c, err := f.Comments()
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Get(TagInstrumental))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// MarkInstrumental tags the FLAC at path instrumental.
+func MarkInstrumental(path string) error {
+	return flacmeta.UpdateComments(path, func(c *flacmeta.Comments) {
+		c.Set(TagInstrumental, "1")
+	})
+}
@@ -0,0 +1,52 @@
+package lyricsmatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+)
+
+func TestIsInstrumentalText(t *testing.T) {
+	tests := []struct {
+		plain, synced string
+		want          bool
+	}{
+		{"[Instrumental]", "", true},
+		{"", "[00:00.00] (instrumental)", true},
+		{"  INSTRUMENTAL  ", "", true},
+		{"An instrumental break\nthen words", "", false},
+		{"la la la", "[00:01.00] la la la", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := IsInstrumentalText(tt.plain, tt.synced); got != tt.want {
+			t.Errorf("IsInstrumentalText(%q, %q) = %v, want %v", tt.plain, tt.synced, got, tt.want)
+		}
+	}
+}
+
+func TestMarkInstrumental(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if IsInstrumental(path) {
+		t.Fatal("untagged file is instrumental")
+	}
+	if err := MarkInstrumental(path); err != nil {
+		t.Fatal(err)
+	}
+	if !IsInstrumental(path) {
+		t.Error("marked file is not instrumental")
+	}
+
+	flacmeta.UpdateComments(path, func(c *flacmeta.Comments) { c.Set(TagInstrumental, "0") })
+	if IsInstrumental(path) {
+		t.Error("INSTRUMENTAL=0 is instrumental")
+	}
+	if IsInstrumental(filepath.Join(t.TempDir(), "missing.flac")) {
+		t.Error("missing file is instrumental")
+	}
+}
@@ -0,0 +1,60 @@
+// Package lyricsmatch judges whether lyrics found for a file belong to its
+// song before they are embedded unreviewed. LRCLIB's search falls back to
+// whatever matches the title and artist best, which is sometimes another
+// recording or another song of the same name; its length gives it away.
+// It also tells instrumentals apart, and tags them so they are skipped
+// next time.
+package lyricsmatch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MaxDelta is the largest difference, in seconds, between a file's length
+// and that of the track the lyrics were published for, for the lyrics to
+// be embedded without review.
+const MaxDelta = 3
+
+// ErrUnsure is returned, wrapped, for lyrics that may be another song's.
+var ErrUnsure = errors.New("lyrics may be for another song")
+
+// Match describes lyrics found for a file.
+type Match struct {
+	Track        string  `json:"track"` // as LRCLIB names it
+	Artist       string  `json:"artist"`
+	Album        string  `json:"album,omitempty"`
+	Duration     float64 `json:"duration"`      // seconds, of LRCLIB's track; 0 when unknown
+	FileDuration float64 `json:"fileDuration"`  // seconds; 0 when unknown
+	Delta        float64 `json:"durationDelta"` // Duration - FileDuration, when both are known
+	HasPlain     bool    `json:"hasPlain"`
+	HasSynced    bool    `json:"hasSynced"`
+	Instrumental bool    `json:"instrumental"`     // LRCLIB has the track as instrumental
+	Confident    bool    `json:"confident"`        // within MaxDelta; safe to embed unreviewed
+	Reason       string  `json:"reason,omitempty"` // why not Confident
+}
+
+// Judge sets m's Delta, Confident and Reason from its durations. Lyrics
+// are only confident when both lengths are known.
+func (m *Match) Judge() {
+	m.Delta, m.Confident, m.Reason = 0, false, ""
+	if m.Duration <= 0 || m.FileDuration <= 0 {
+		m.Reason = "length unknown"
+		return
+	}
+	m.Delta = math.Round((m.Duration-m.FileDuration)*10) / 10
+	if math.Abs(m.Delta) > MaxDelta {
+		m.Reason = fmt.Sprintf("length differs by %gs", math.Abs(m.Delta))
+		return
+	}
+	m.Confident = true
+}
+
+// Err returns nil for a confident match, else ErrUnsure with the reason.
+func (m Match) Err() error {
+	if m.Confident {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsure, m.Reason)
+}
@@ -0,0 +1,34 @@
+package lyricsmatch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJudge(t *testing.T) {
+	tests := []struct {
+		lyrics, file float64
+		delta        float64
+		confident    bool
+		reason       string
+	}{
+		{200, 200, 0, true, ""},
+		{203, 200.4, 2.6, true, ""},
+		{197, 200, -3, true, ""},
+		{207, 200, 7, false, "length differs by 7s"},
+		{150.5, 200, -49.5, false, "length differs by 49.5s"},
+		{0, 200, 0, false, "length unknown"},
+		{200, 0, 0, false, "length unknown"},
+	}
+	for _, tt := range tests {
+		m := Match{Duration: tt.lyrics, FileDuration: tt.file}
+		m.Judge()
+		if m.Delta != tt.delta || m.Confident != tt.confident || m.Reason != tt.reason {
+			t.Errorf("Judge(%v, %v) = %v, %v, %q; want %v, %v, %q",
+				tt.lyrics, tt.file, m.Delta, m.Confident, m.Reason, tt.delta, tt.confident, tt.reason)
+		}
+		if err := m.Err(); (err == nil) != tt.confident || err != nil && !errors.Is(err, ErrUnsure) {
+			t.Errorf("Judge(%v, %v).Err() = %v", tt.lyrics, tt.file, err)
+		}
+	}
+}
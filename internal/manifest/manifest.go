@@ -0,0 +1,93 @@
+// Package manifest exports the tracks of an album, playlist or track as a
+// download manifest, for running the transfer with an external downloader
+// (e.g. aria2 on a seedbox) instead of FLACidal's own queue. Each entry
+// carries the track's metadata and, where the source hands out a plain
+// file URL, its stream URL.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Formats are the manifest encodings Encode accepts.
+var Formats = []string{"json", "aria2"}
+
+// Manifest is the exported content and its tracks.
+type Manifest struct {
+	URL       string  `json:"url"`
+	Source    string  `json:"source"`
+	Type      string  `json:"type"`
+	Title     string  `json:"title"`
+	Quality   string  `json:"quality"`
+	CreatedAt string  `json:"createdAt"` // UTC RFC3339, see internal/timestamp
+	Tracks    []Entry `json:"tracks"`
+}
+
+// Entry is one track. StreamURL is empty when it couldn't be resolved;
+// Error then says why.
+type Entry struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Artist      string `json:"artist"`
+	Album       string `json:"album,omitempty"`
+	ISRC        string `json:"isrc,omitempty"`
+	TrackNumber int    `json:"trackNumber,omitempty"`
+	DiscNumber  int    `json:"discNumber,omitempty"`
+	Duration    int    `json:"duration,omitempty"` // seconds
+	FileName    string `json:"fileName"`           // suggested name, extension included
+	StreamURL   string `json:"streamUrl,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Resolved counts the entries that have a stream URL.
+func (m *Manifest) Resolved() int {
+	n := 0
+	for _, e := range m.Tracks {
+		if e.StreamURL != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Aria2 encodes m as an aria2c input file (aria2c -i): each resolved track
+// is its URL followed by an out= option naming the file. Unresolved tracks
+// are listed as comments so the file still accounts for every track.
+func (m *Manifest) Aria2() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s (%s %s), %d of %d tracks\n", m.Title, m.Source, m.Type, m.Resolved(), len(m.Tracks))
+	for _, e := range m.Tracks {
+		if e.StreamURL == "" {
+			fmt.Fprintf(&sb, "# skipped %s - %s: %s\n", e.Artist, e.Title, e.Error)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s\n  out=%s\n", e.StreamURL, e.FileName)
+	}
+	return sb.String()
+}
+
+// ValidateFormat rejects formats Encode doesn't know.
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown manifest format %q (want %s)", format, strings.Join(Formats, " or "))
+}
+
+// Encode returns m in format ("json" or "aria2") and the file extension
+// that goes with it.
+func (m *Manifest) Encode(format string) ([]byte, string, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, "", err
+	}
+	if format == "aria2" {
+		return []byte(m.Aria2()), ".txt", nil
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	return data, ".json", err
+}
@@ -0,0 +1,48 @@
+package manifest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testManifest() *Manifest {
+	return &Manifest{
+		Title: "Album", Source: "tidal", Type: "album",
+		Tracks: []Entry{
+			{ID: "1", Artist: "A", Title: "One", FileName: "A - One.flac", StreamURL: "https://cdn.example/1.flac"},
+			{ID: "2", Artist: "A", Title: "Two", FileName: "A - Two.flac", Error: "stream can't be exported"},
+		},
+	}
+}
+
+func TestAria2(t *testing.T) {
+	got := testManifest().Aria2()
+	want := "# Album (tidal album), 1 of 2 tracks\n" +
+		"https://cdn.example/1.flac\n  out=A - One.flac\n" +
+		"# skipped A - Two: stream can't be exported\n"
+	if got != want {
+		t.Errorf("Aria2() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	m := testManifest()
+	data, ext, err := m.Encode("json")
+	if err != nil || ext != ".json" {
+		t.Fatalf("Encode(json) = %q, %v", ext, err)
+	}
+	var back Manifest
+	if err := json.Unmarshal(data, &back); err != nil || len(back.Tracks) != 2 || back.Tracks[0].StreamURL == "" {
+		t.Errorf("round trip = %+v, %v", back, err)
+	}
+
+	data, ext, err = m.Encode("aria2")
+	if err != nil || ext != ".txt" || !strings.Contains(string(data), "out=A - One.flac") {
+		t.Errorf("Encode(aria2) = %q, %q, %v", data, ext, err)
+	}
+
+	if _, _, err := m.Encode("xml"); err == nil {
+		t.Error("Encode(xml): want error, got nil")
+	}
+}
@@ -0,0 +1,114 @@
+package manifest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// btsMimeType is Tidal's single-file manifest type. Hi-Res streams are
+// usually DASH (application/dash+xml) instead: a list of segments with no
+// one URL an external downloader could fetch.
+const btsMimeType = "application/vnd.tidal.bts"
+
+// ErrNotExportable is returned for streams that can't be handed to an
+// external downloader as a single plain URL (segmented or encrypted).
+var ErrNotExportable = errors.New("stream can't be exported as a single URL")
+
+// DefaultClient is the HTTP client TidalResolver uses when its Client is nil.
+var DefaultClient = &http.Client{Timeout: 20 * time.Second}
+
+// TidalResolver looks up Tidal stream URLs through the HiFi API endpoint
+// pool the downloader uses (GET <endpoint>/track/?id=<id>&quality=<q>),
+// trying each endpoint in order until one answers.
+type TidalResolver struct {
+	Endpoints []string
+	Client    *http.Client
+}
+
+// playbackInfo is the part of an endpoint's track response used here.
+// Endpoints answer either with the playback info itself or wrapped in
+// {"data": ...}.
+type playbackInfo struct {
+	ManifestMimeType string        `json:"manifestMimeType"`
+	Manifest         string        `json:"manifest"`
+	Data             *playbackInfo `json:"data"`
+}
+
+// btsManifest is the decoded Manifest of a btsMimeType stream.
+type btsManifest struct {
+	MimeType       string   `json:"mimeType"`
+	EncryptionType string   `json:"encryptionType"`
+	URLs           []string `json:"urls"`
+}
+
+// Resolve returns the stream URL and MIME type of Tidal track id in
+// quality. Streams that aren't a single unencrypted file yield
+// ErrNotExportable.
+func (r TidalResolver) Resolve(id, quality string) (string, string, error) {
+	if len(r.Endpoints) == 0 {
+		return "", "", fmt.Errorf("no Tidal endpoints configured")
+	}
+	client := r.Client
+	if client == nil {
+		client = DefaultClient
+	}
+	var lastErr error
+	for _, endpoint := range r.Endpoints {
+		info, err := fetchPlaybackInfo(client, endpoint, id, quality)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return streamURL(info)
+	}
+	return "", "", lastErr
+}
+
+// fetchPlaybackInfo asks one endpoint for id's playback info.
+func fetchPlaybackInfo(client *http.Client, endpoint, id, quality string) (*playbackInfo, error) {
+	q := url.Values{"id": {id}, "quality": {quality}}
+	resp, err := client.Get(strings.TrimRight(endpoint, "/") + "/track/?" + q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %d", endpoint, resp.StatusCode)
+	}
+	var info playbackInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("%s: %w", endpoint, err)
+	}
+	if info.Data != nil {
+		return info.Data, nil
+	}
+	return &info, nil
+}
+
+// streamURL extracts the file URL from a single-file manifest.
+func streamURL(info *playbackInfo) (string, string, error) {
+	if info.ManifestMimeType != btsMimeType {
+		return "", "", fmt.Errorf("%w (%s)", ErrNotExportable, info.ManifestMimeType)
+	}
+	raw, err := base64.StdEncoding.DecodeString(info.Manifest)
+	if err != nil {
+		return "", "", fmt.Errorf("decoding manifest: %w", err)
+	}
+	var m btsManifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return "", "", fmt.Errorf("decoding manifest: %w", err)
+	}
+	if m.EncryptionType != "" && m.EncryptionType != "NONE" {
+		return "", "", fmt.Errorf("%w (encrypted)", ErrNotExportable)
+	}
+	if len(m.URLs) == 0 {
+		return "", "", fmt.Errorf("manifest has no URL")
+	}
+	return m.URLs[0], m.MimeType, nil
+}
@@ -0,0 +1,63 @@
+package manifest
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// trackServer answers /track/ with the given manifest type and manifest
+// JSON, wrapped in {"data": ...} when wrapped is set.
+func trackServer(t *testing.T, mimeType, manifest string, wrapped bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/track/" || r.URL.Query().Get("id") != "42" || r.URL.Query().Get("quality") != "LOSSLESS" {
+			http.NotFound(w, r)
+			return
+		}
+		body := fmt.Sprintf(`{"manifestMimeType":%q,"manifest":%q}`, mimeType, base64.StdEncoding.EncodeToString([]byte(manifest)))
+		if wrapped {
+			body = `{"version":"2.0","data":` + body + `}`
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTidalResolver(t *testing.T) {
+	bts := `{"mimeType":"audio/flac","encryptionType":"NONE","urls":["https://cdn.example/42.flac"]}`
+	for _, wrapped := range []bool{false, true} {
+		srv := trackServer(t, btsMimeType, bts, wrapped)
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "down", http.StatusBadGateway)
+		}))
+		defer down.Close()
+
+		url, mime, err := TidalResolver{Endpoints: []string{down.URL, srv.URL + "/"}}.Resolve("42", "LOSSLESS")
+		if err != nil || url != "https://cdn.example/42.flac" || mime != "audio/flac" {
+			t.Errorf("wrapped=%v: Resolve() = %q, %q, %v", wrapped, url, mime, err)
+		}
+	}
+}
+
+func TestTidalResolver_NotExportable(t *testing.T) {
+	tests := map[string]*httptest.Server{
+		"dash":      trackServer(t, "application/dash+xml", "<MPD/>", true),
+		"encrypted": trackServer(t, btsMimeType, `{"encryptionType":"OLD_AES","urls":["https://cdn.example/x"]}`, false),
+	}
+	for name, srv := range tests {
+		if _, _, err := (TidalResolver{Endpoints: []string{srv.URL}}).Resolve("42", "LOSSLESS"); !errors.Is(err, ErrNotExportable) {
+			t.Errorf("%s: err = %v, want ErrNotExportable", name, err)
+		}
+	}
+}
+
+func TestTidalResolver_NoEndpoints(t *testing.T) {
+	if _, _, err := (TidalResolver{}).Resolve("42", "LOSSLESS"); err == nil {
+		t.Error("Resolve() without endpoints: want error, got nil")
+	}
+}
@@ -0,0 +1,68 @@
+// Package metacache remembers the audio format of FLAC files, keyed by path
+// and invalidated by size and modification time, so file listings can show
+// quality badges without reading every file's metadata on each refresh.
+package metacache
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/quality"
+)
+
+// Info is the audio format of a file.
+type Info struct {
+	SampleRate int             `json:"sampleRate"` // Hz
+	BitDepth   int             `json:"bitDepth"`
+	Tier       quality.Quality `json:"tier,omitempty"` // see quality.Tier
+}
+
+// Cache maps paths to their Info. Its zero value is ready to use and safe
+// for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+type entry struct {
+	size    int64
+	modTime time.Time
+	info    Info
+}
+
+// Get returns the audio format of the FLAC at path, reading its STREAMINFO
+// only when the file is new to c or has changed since.
+func (c *Cache) Get(path string) (Info, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return Info{}, err
+	}
+	c.mu.Lock()
+	e, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && e.size == st.Size() && e.modTime.Equal(st.ModTime()) {
+		return e.info, nil
+	}
+
+	si, err := flacmeta.ReadStreamInfo(path)
+	if err != nil {
+		return Info{}, err
+	}
+	info := Info{SampleRate: si.SampleRate, BitDepth: si.BitDepth, Tier: quality.Tier(si.BitDepth, si.SampleRate)}
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]entry)
+	}
+	c.entries[path] = entry{size: st.Size(), modTime: st.ModTime(), info: info}
+	c.mu.Unlock()
+	return info, nil
+}
+
+// Forget drops path from c, for files that were deleted.
+func (c *Cache) Forget(path string) {
+	c.mu.Lock()
+	delete(c.entries, path)
+	c.mu.Unlock()
+}
@@ -0,0 +1,42 @@
+package metacache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"flacidal/internal/flacmeta/flactest"
+	"flacidal/internal/quality"
+)
+
+func TestGet(t *testing.T) {
+	var c Cache
+	path := filepath.Join(t.TempDir(), "track.flac")
+	flactest.Write(t, path, flactest.HiRes)
+
+	info, err := c.Get(path)
+	if want := (Info{SampleRate: 96000, BitDepth: 24, Tier: quality.HiRes}); err != nil || info != want {
+		t.Fatalf("Get = %+v, %v; want %+v", info, err, want)
+	}
+
+	// Unchanged files are served from the cache...
+	c.entries[path] = entry{size: c.entries[path].size, modTime: c.entries[path].modTime, info: Info{SampleRate: 1}}
+	if info, _ := c.Get(path); info.SampleRate != 1 {
+		t.Errorf("unchanged file re-read: %+v", info)
+	}
+	// ...and changed ones re-read.
+	flactest.Write(t, path, flactest.CD)
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Minute))
+	if info, _ := c.Get(path); info.SampleRate != 44100 || info.BitDepth != 16 || info.Tier != quality.Lossless {
+		t.Errorf("changed file: %+v", info)
+	}
+
+	c.Forget(path)
+	if _, ok := c.entries[path]; ok {
+		t.Error("Forget kept the entry")
+	}
+	if _, err := c.Get(filepath.Join(t.TempDir(), "missing.flac")); err == nil {
+		t.Error("missing file: want an error")
+	}
+}
@@ -0,0 +1,105 @@
+package musicbrainz
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Edition is one release of an album: a regional pressing, a deluxe or
+// clean version, a reissue. Its barcode finds the same edition on a
+// streaming service.
+type Edition struct {
+	ReleaseID      string `json:"releaseId"`
+	Title          string `json:"title"`
+	Artist         string `json:"artist"`
+	Country        string `json:"country,omitempty"` // ISO 3166-1 code; "XW" is worldwide
+	Date           string `json:"date,omitempty"`
+	Barcode        string `json:"barcode,omitempty"`
+	TrackCount     int    `json:"trackCount"`
+	Status         string `json:"status,omitempty"`
+	Disambiguation string `json:"disambiguation,omitempty"` // e.g. "clean", "Japan bonus tracks"
+}
+
+// searchRelease is a release as the release search returns it.
+type searchRelease struct {
+	ID             string `json:"id"`
+	Score          int    `json:"score"`
+	Title          string `json:"title"`
+	Country        string `json:"country"`
+	Date           string `json:"date"`
+	Barcode        string `json:"barcode"`
+	Status         string `json:"status"`
+	Disambiguation string `json:"disambiguation"`
+	TrackCount     int    `json:"track-count"`
+	ArtistCredit   []struct {
+		Name       string `json:"name"`
+		JoinPhrase string `json:"joinphrase"`
+	} `json:"artist-credit"`
+}
+
+// Editions lists the releases of album by artist, as ordered by
+// SortEditions with no preferred countries. Releases scoring under
+// MinScore are left out; an album MusicBrainz doesn't know is ErrNoMatch.
+func (c *Client) Editions(ctx context.Context, artist, album string) ([]Edition, error) {
+	if strings.TrimSpace(artist) == "" || strings.TrimSpace(album) == "" {
+		return nil, fmt.Errorf("artist and album are required")
+	}
+	var resp struct {
+		Releases []searchRelease `json:"releases"`
+	}
+	query := fmt.Sprintf("release:%s AND artist:%s", phrase(album), phrase(artist))
+	if err := c.get(ctx, "/release", url.Values{"query": {query}, "limit": {"50"}}, &resp); err != nil {
+		return nil, err
+	}
+	var editions []Edition
+	for _, r := range resp.Releases {
+		if r.Score < MinScore {
+			continue
+		}
+		e := Edition{
+			ReleaseID: r.ID, Title: r.Title, Country: r.Country, Date: r.Date, Barcode: r.Barcode,
+			TrackCount: r.TrackCount, Status: r.Status, Disambiguation: r.Disambiguation,
+		}
+		for _, ac := range r.ArtistCredit {
+			e.Artist += ac.Name + ac.JoinPhrase
+		}
+		editions = append(editions, e)
+	}
+	if len(editions) == 0 {
+		return nil, ErrNoMatch
+	}
+	SortEditions(editions, nil)
+	return editions, nil
+}
+
+// SortEditions orders editions by how early their country appears in
+// countries (ISO 3166-1 codes, most preferred first), then official
+// releases with a barcode before the rest, then by date. Editions from
+// countries not listed keep their date order after the preferred ones.
+func SortEditions(editions []Edition, countries []string) {
+	rank := func(e Edition) int {
+		for i, c := range countries {
+			if strings.EqualFold(c, e.Country) {
+				return i
+			}
+		}
+		return len(countries)
+	}
+	usable := func(e Edition) int {
+		if e.Status == "Official" && e.Barcode != "" {
+			return 0
+		}
+		return 1
+	}
+	slices.SortStableFunc(editions, func(a, b Edition) int {
+		return cmp.Or(
+			cmp.Compare(rank(a), rank(b)),
+			cmp.Compare(usable(a), usable(b)),
+			cmp.Compare(a.Date, b.Date),
+		)
+	})
+}
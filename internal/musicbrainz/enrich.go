@@ -0,0 +1,84 @@
+package musicbrainz
+
+import (
+	"context"
+	"slices"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/tagedit"
+)
+
+// The MusicBrainz ID tags, named as Picard writes them to Vorbis comments.
+const (
+	TagTrackID  = "MUSICBRAINZ_TRACKID" // the recording's MBID
+	TagAlbumID  = "MUSICBRAINZ_ALBUMID" // the release's MBID
+	TagArtistID = "MUSICBRAINZ_ARTISTID"
+)
+
+// QueryFor builds the lookup for a file tagged c.
+func QueryFor(c *flacmeta.Comments) Query {
+	return Query{ISRC: c.Get("ISRC"), Artist: c.Get("ARTIST"), Title: c.Get("TITLE"), Album: c.Get("ALBUM")}
+}
+
+// Changes lists what tagging a file tagged c with m changes: the MBID tags
+// are set, and DATE, GENRE, LABEL and CATALOGNUMBER are filled in only
+// when missing.
+func (m Match) Changes(c *flacmeta.Comments) []tagedit.Change {
+	var changes []tagedit.Change
+	set := func(field string, values ...string) {
+		values = slices.DeleteFunc(values, func(v string) bool { return v == "" })
+		if old := c.GetAll(field); len(values) > 0 && !slices.Equal(old, values) {
+			changes = append(changes, tagedit.Change{Field: field, Old: old, New: values})
+		}
+	}
+	fill := func(field, value string) {
+		if c.Get(field) == "" {
+			set(field, value)
+		}
+	}
+	set(TagTrackID, m.RecordingID)
+	set(TagAlbumID, m.ReleaseID)
+	set(TagArtistID, m.ArtistIDs...)
+	fill("DATE", m.Year)
+	fill("GENRE", m.Genre)
+	fill("LABEL", m.Label)
+	fill("CATALOGNUMBER", m.CatalogNum)
+	return changes
+}
+
+// Enrich looks up the FLAC at path by its tags (see QueryFor) and tags it
+// with the match (see Match.Changes), or with dryRun only works out the
+// changes. The lookup's error, ErrNoMatch when MusicBrainz doesn't know the
+// track, is returned as well as recorded in the result.
+func (c *Client) Enrich(ctx context.Context, path string, dryRun bool) (tagedit.Result, error) {
+	result := tagedit.Result{Path: path, Changes: []tagedit.Change{}}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		result.Fail(err)
+		return result, nil
+	}
+	comments, err := f.Comments()
+	if err != nil {
+		result.Fail(err)
+		return result, nil
+	}
+	m, err := c.Lookup(ctx, QueryFor(comments))
+	if err != nil {
+		result.Fail(err)
+		return result, err
+	}
+	if changes := m.Changes(comments); changes != nil {
+		result.Changes = changes
+	}
+	if dryRun || len(result.Changes) == 0 {
+		return result, nil
+	}
+	tagedit.Apply(comments, result.Changes)
+	f.SetComments(comments)
+	if err := f.Save(); err != nil {
+		result.Fail(err)
+		return result, nil
+	}
+	result.Written = true
+	return result, nil
+}
@@ -0,0 +1,291 @@
+// Package musicbrainz looks tracks up on MusicBrainz (musicbrainz.org/ws/2)
+// by ISRC or by artist and title, for the MusicBrainz ID tags players and
+// taggers such as Picard key on, and for the year, genre and label sources
+// often leave out.
+package musicbrainz
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// DefaultBaseURL is the MusicBrainz web service a Client uses when its
+// BaseURL is empty.
+const DefaultBaseURL = "https://musicbrainz.org/ws/2"
+
+// DefaultUserAgent identifies FLACidal, as MusicBrainz asks every client to.
+const DefaultUserAgent = "FLACidal ( https://github.com/kushiemoon-dev/flacidal )"
+
+// MinInterval is the least time between two requests of a Client:
+// MusicBrainz allows one per second and answers 503 beyond that.
+const MinInterval = time.Second
+
+// MinScore is the search score (0–100) an artist and title match needs.
+const MinScore = 90
+
+// DefaultClient is the HTTP client a Client uses when its HTTP is nil.
+var DefaultClient = &http.Client{Timeout: 20 * time.Second}
+
+// Default is the client shared by the desktop app and the server, so their
+// lookups share one rate limit.
+var Default = &Client{}
+
+// ErrNoMatch is returned for tracks MusicBrainz doesn't know.
+var ErrNoMatch = errors.New("no MusicBrainz match")
+
+// Client is a MusicBrainz web service client. Its zero value is ready to
+// use; requests are spaced at least Interval apart.
+type Client struct {
+	BaseURL   string        // DefaultBaseURL when empty
+	HTTP      *http.Client  // DefaultClient when nil
+	UserAgent string        // DefaultUserAgent when empty
+	Interval  time.Duration // MinInterval when 0
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Query describes the track to look up. ISRC is tried first; Album picks
+// among the releases a recording appears on.
+type Query struct {
+	ISRC   string
+	Artist string
+	Title  string
+	Album  string
+}
+
+// Match is the recording and release a Query found.
+type Match struct {
+	RecordingID string   `json:"recordingId"`
+	ReleaseID   string   `json:"releaseId,omitempty"`
+	ArtistIDs   []string `json:"artistIds"`
+	Title       string   `json:"title"`
+	Artist      string   `json:"artist"`
+	Album       string   `json:"album,omitempty"`
+	Year        string   `json:"year,omitempty"`
+	Genre       string   `json:"genre,omitempty"`
+	Label       string   `json:"label,omitempty"`
+	CatalogNum  string   `json:"catalogNumber,omitempty"` // the label's
+}
+
+// recording, release and genre are the parts of MusicBrainz's JSON used
+// here.
+type recording struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Score        int    `json:"score"`
+	ArtistCredit []struct {
+		Name       string `json:"name"`
+		JoinPhrase string `json:"joinphrase"`
+		Artist     struct {
+			ID string `json:"id"`
+		} `json:"artist"`
+	} `json:"artist-credit"`
+	Releases []release `json:"releases"`
+	Genres   []genre   `json:"genres"`
+}
+
+type release struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Date      string `json:"date"`
+	Status    string `json:"status"`
+	LabelInfo []struct {
+		CatalogNumber string `json:"catalog-number"`
+		Label         *struct {
+			Name string `json:"name"`
+		} `json:"label"`
+	} `json:"label-info"`
+	Genres []genre `json:"genres"`
+}
+
+type genre struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Lookup finds q's recording, by ISRC when q has one and otherwise by
+// artist and title, and the release it was most likely taken from.
+func (c *Client) Lookup(ctx context.Context, q Query) (Match, error) {
+	var recs []recording
+	if isrc := strings.ToUpper(strings.TrimSpace(q.ISRC)); isrc != "" {
+		var resp struct {
+			Recordings []recording `json:"recordings"`
+		}
+		err := c.get(ctx, "/isrc/"+url.PathEscape(isrc), url.Values{"inc": {"artist-credits releases genres"}}, &resp)
+		if err != nil && !errors.Is(err, ErrNoMatch) {
+			return Match{}, err
+		}
+		recs = resp.Recordings
+	}
+	if len(recs) == 0 && q.Artist != "" && q.Title != "" {
+		var resp struct {
+			Recordings []recording `json:"recordings"`
+		}
+		query := fmt.Sprintf("recording:%s AND artist:%s", phrase(q.Title), phrase(q.Artist))
+		if err := c.get(ctx, "/recording", url.Values{"query": {query}, "limit": {"10"}}, &resp); err != nil {
+			return Match{}, err
+		}
+		recs = slices.DeleteFunc(resp.Recordings, func(r recording) bool { return r.Score < MinScore })
+	}
+	if len(recs) == 0 {
+		return Match{}, ErrNoMatch
+	}
+
+	rec := recs[0]
+	for _, r := range recs {
+		if strings.EqualFold(r.Title, q.Title) {
+			rec = r
+			break
+		}
+	}
+	m := Match{RecordingID: rec.ID, Title: rec.Title, Genre: topGenre(rec.Genres)}
+	for _, ac := range rec.ArtistCredit {
+		m.Artist += ac.Name + ac.JoinPhrase
+		if ac.Artist.ID != "" {
+			m.ArtistIDs = append(m.ArtistIDs, ac.Artist.ID)
+		}
+	}
+	rel, ok := pickRelease(rec.Releases, q.Album)
+	if !ok {
+		return m, nil
+	}
+	m.ReleaseID, m.Album, m.Year = rel.ID, rel.Title, year(rel.Date)
+
+	// Labels and release genres only come with the release itself.
+	var full release
+	if err := c.get(ctx, "/release/"+url.PathEscape(rel.ID), url.Values{"inc": {"labels genres"}}, &full); err != nil {
+		return m, nil // the recording's IDs are worth having anyway
+	}
+	if y := year(full.Date); y != "" {
+		m.Year = y
+	}
+	if g := topGenre(full.Genres); g != "" {
+		m.Genre = g
+	}
+	for _, li := range full.LabelInfo {
+		if li.Label != nil && li.Label.Name != "" {
+			m.Label, m.CatalogNum = li.Label.Name, li.CatalogNumber
+			break
+		}
+	}
+	return m, nil
+}
+
+// pickRelease returns the release titled album, or else the earliest
+// official one, or else the first.
+func pickRelease(releases []release, album string) (release, bool) {
+	if len(releases) == 0 {
+		return release{}, false
+	}
+	for _, r := range releases {
+		if album != "" && strings.EqualFold(r.Title, album) {
+			return r, true
+		}
+	}
+	official := slices.DeleteFunc(slices.Clone(releases), func(r release) bool { return r.Status != "Official" || r.Date == "" })
+	if len(official) == 0 {
+		return releases[0], true
+	}
+	return slices.MinFunc(official, func(a, b release) int { return cmp.Compare(a.Date, b.Date) }), true
+}
+
+// topGenre returns the most voted genre with each word capitalized, as
+// genre tags usually are ("hip hop" → "Hip Hop"), or "".
+func topGenre(genres []genre) string {
+	if len(genres) == 0 {
+		return ""
+	}
+	name := []rune(slices.MaxFunc(genres, func(a, b genre) int { return cmp.Compare(a.Count, b.Count) }).Name)
+	for i, r := range name {
+		if i == 0 || name[i-1] == ' ' || name[i-1] == '-' {
+			name[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(name)
+}
+
+// year returns the year of a MusicBrainz date ("2019", "2019-05",
+// "2019-05-24").
+func year(date string) string {
+	if len(date) < 4 {
+		return ""
+	}
+	return date[:4]
+}
+
+// phrase quotes s as a Lucene phrase for the search API.
+func phrase(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// get fetches path with params as JSON into v, after waiting out the rate
+// limit. A 404 is ErrNoMatch; a 503 (rate limited) is retried once.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v any) error {
+	base := cmp.Or(c.BaseURL, DefaultBaseURL)
+	params.Set("fmt", "json")
+	u := strings.TrimRight(base, "/") + path + "?" + params.Encode()
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", cmp.Or(c.UserAgent, DefaultUserAgent))
+		req.Header.Set("Accept", "application/json")
+		client := c.HTTP
+		if client == nil {
+			client = DefaultClient
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("musicbrainz: %w", err)
+		}
+		switch {
+		case resp.StatusCode == http.StatusServiceUnavailable && attempt == 0:
+			resp.Body.Close()
+			continue
+		case resp.StatusCode == http.StatusNotFound:
+			resp.Body.Close()
+			return ErrNoMatch
+		case resp.StatusCode != http.StatusOK:
+			resp.Body.Close()
+			return fmt.Errorf("musicbrainz: HTTP %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(v)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("musicbrainz: %w", err)
+		}
+		return nil
+	}
+}
+
+// wait blocks until c.Interval has passed since c's last request.
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d := time.Until(c.last.Add(cmp.Or(c.Interval, MinInterval))); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	c.last = time.Now()
+	return nil
+}
@@ -0,0 +1,204 @@
+package musicbrainz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"flacidal/internal/flacmeta"
+)
+
+const (
+	recordingJSON = `{"id": "rec-1", "title": "Song", "score": %d,
+		"artist-credit": [{"name": "A", "joinphrase": " & ", "artist": {"id": "art-a"}}, {"name": "B", "artist": {"id": "art-b"}}],
+		"releases": [
+			{"id": "rel-comp", "title": "Hits", "date": "2001-01-01", "status": "Official"},
+			{"id": "rel-album", "title": "Album", "date": "1999-05-01", "status": "Official"},
+			{"id": "rel-boot", "title": "Live", "date": "1990", "status": "Bootleg"}
+		]}`
+	releaseJSON = `{"id": "rel-album", "title": "Album", "date": "1999-05-01",
+		"label-info": [{"label": null}, {"catalog-number": "LBL-001", "label": {"name": "Label"}}],
+		"genres": [{"name": "rock", "count": 2}, {"name": "hip hop", "count": 5}]}`
+)
+
+// fakeMusicBrainz serves the ISRC "KNOWN" and the search for "Song" by
+// "A", counting requests.
+func fakeMusicBrainz(t *testing.T, requests *atomic.Int32) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("fmt") != "json" || !strings.HasPrefix(r.Header.Get("User-Agent"), "FLACidal") {
+			t.Errorf("request %s: fmt=%q, User-Agent %q", r.URL, r.URL.Query().Get("fmt"), r.Header.Get("User-Agent"))
+		}
+		switch {
+		case r.URL.Path == "/isrc/KNOWN":
+			w.Write([]byte(`{"recordings": [` + strings.Replace(recordingJSON, "%d", "0", 1) + `]}`))
+		case r.URL.Path == "/recording" && strings.Contains(r.URL.Query().Get("query"), `recording:"Song"`):
+			w.Write([]byte(`{"recordings": [` + strings.Replace(recordingJSON, "%d", "95", 1) + `]}`))
+		case r.URL.Path == "/recording":
+			w.Write([]byte(`{"recordings": [` + strings.Replace(recordingJSON, "%d", "40", 1) + `]}`))
+		case r.URL.Path == "/release/rel-album":
+			w.Write([]byte(releaseJSON))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &Client{BaseURL: srv.URL, Interval: time.Millisecond}
+}
+
+func TestLookup(t *testing.T) {
+	var requests atomic.Int32
+	c := fakeMusicBrainz(t, &requests)
+	want := Match{RecordingID: "rec-1", ReleaseID: "rel-album", ArtistIDs: []string{"art-a", "art-b"}, Title: "Song", Artist: "A & B", Album: "Album", Year: "1999", Genre: "Hip Hop", Label: "Label", CatalogNum: "LBL-001"}
+
+	for _, q := range []Query{
+		{ISRC: " known ", Title: "Song"},
+		{ISRC: "UNKNOWN", Artist: "A", Title: "Song"}, // falls back to the search
+		{Artist: "A", Title: "Song", Album: "album"},
+	} {
+		m, err := c.Lookup(context.Background(), q)
+		if err != nil {
+			t.Fatalf("Lookup(%+v): %v", q, err)
+		}
+		if m.RecordingID != want.RecordingID || m.ReleaseID != want.ReleaseID || !slices.Equal(m.ArtistIDs, want.ArtistIDs) ||
+			m.Artist != want.Artist || m.Year != want.Year || m.Genre != want.Genre || m.Label != want.Label || m.CatalogNum != want.CatalogNum {
+			t.Errorf("Lookup(%+v) = %+v", q, m)
+		}
+	}
+
+	// Low-scoring search results don't count.
+	if _, err := c.Lookup(context.Background(), Query{Artist: "A", Title: "Other"}); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("weak match: err = %v, want ErrNoMatch", err)
+	}
+	if _, err := c.Lookup(context.Background(), Query{ISRC: "UNKNOWN"}); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("unknown ISRC: err = %v, want ErrNoMatch", err)
+	}
+}
+
+func TestGet_RetriesRateLimitAndWaits(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := &Client{BaseURL: srv.URL, Interval: 20 * time.Millisecond}
+	start := time.Now()
+	var v struct{}
+	if err := c.get(context.Background(), "/x", map[string][]string{}, &v); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 || time.Since(start) < 20*time.Millisecond {
+		t.Errorf("%d calls in %v, want a retry an interval later", calls.Load(), time.Since(start))
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	var requests atomic.Int32
+	c := fakeMusicBrainz(t, &requests)
+	path := filepath.Join(t.TempDir(), "song.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := flacmeta.UpdateComments(path, func(c *flacmeta.Comments) {
+		c.Set("ISRC", "KNOWN")
+		c.Set("TITLE", "Song")
+		c.Set("DATE", "2000")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preview, err := c.Enrich(context.Background(), path, true)
+	if err != nil || preview.Written || len(preview.Changes) != 6 {
+		t.Fatalf("preview = %+v, %v", preview, err)
+	}
+	result, err := c.Enrich(context.Background(), path, false)
+	if err != nil || !result.Written {
+		t.Fatalf("Enrich = %+v, %v", result, err)
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, _ := f.Comments()
+	if tags.Get(TagTrackID) != "rec-1" || tags.Get(TagAlbumID) != "rel-album" || !slices.Equal(tags.GetAll(TagArtistID), []string{"art-a", "art-b"}) {
+		t.Errorf("MBIDs = %v", tags.Fields)
+	}
+	if tags.Get("DATE") != "2000" || tags.Get("GENRE") != "Hip Hop" || tags.Get("LABEL") != "Label" || tags.Get("CATALOGNUMBER") != "LBL-001" {
+		t.Errorf("DATE/GENRE/LABEL/CATALOGNUMBER = %q/%q/%q/%q; want the existing DATE kept", tags.Get("DATE"), tags.Get("GENRE"), tags.Get("LABEL"), tags.Get("CATALOGNUMBER"))
+	}
+
+	// Tagged already: nothing left to change.
+	if again, _ := c.Enrich(context.Background(), path, false); again.Written || len(again.Changes) != 0 {
+		t.Errorf("second run = %+v", again)
+	}
+
+	if err := flacmeta.UpdateComments(path, func(c *flacmeta.Comments) { c.Set("ISRC", "UNKNOWN") }); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := c.Enrich(context.Background(), path, true); !errors.Is(err, ErrNoMatch) || r.Error == "" {
+		t.Errorf("unknown track: %+v, %v", r, err)
+	}
+}
+
+func TestEditions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/release" || !strings.Contains(r.URL.Query().Get("query"), `release:"Album"`) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"releases": [
+			{"id": "us", "score": 100, "title": "Album", "country": "US", "date": "1999-05-01", "barcode": "111", "status": "Official", "track-count": 10,
+			 "artist-credit": [{"name": "A"}]},
+			{"id": "jp", "score": 100, "title": "Album", "country": "JP", "date": "1999-06-01", "barcode": "222", "status": "Official", "track-count": 12,
+			 "disambiguation": "bonus tracks"},
+			{"id": "boot", "score": 95, "title": "Album", "country": "GB", "date": "1998", "status": "Bootleg", "track-count": 9},
+			{"id": "other", "score": 30, "title": "Albumen", "country": "US"}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+	c := &Client{BaseURL: srv.URL, Interval: time.Millisecond}
+
+	editions, err := c.Editions(context.Background(), "A", "Album")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := func() []string {
+		var ids []string
+		for _, e := range editions {
+			ids = append(ids, e.ReleaseID)
+		}
+		return ids
+	}
+	if got := ids(); !slices.Equal(got, []string{"us", "jp", "boot"}) {
+		t.Errorf("Editions() = %v, want official releases by date, then the bootleg", got)
+	}
+	if e := editions[1]; e.Country != "JP" || e.TrackCount != 12 || e.Barcode != "222" || e.Disambiguation != "bonus tracks" {
+		t.Errorf("JP edition = %+v", e)
+	}
+
+	SortEditions(editions, []string{"gb", "JP"})
+	if got := ids(); !slices.Equal(got, []string{"boot", "jp", "us"}) {
+		t.Errorf("SortEditions(GB, JP) = %v", got)
+	}
+
+	if _, err := c.Editions(context.Background(), "A", "Unknown"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("unknown album: err = %v, want ErrNoMatch", err)
+	}
+	if _, err := c.Editions(context.Background(), "", "Album"); err == nil {
+		t.Error("no artist: want error")
+	}
+}
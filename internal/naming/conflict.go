@@ -0,0 +1,77 @@
+package naming
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Conflict selects what happens when a file's name is already taken by
+// another file, e.g. when a remix and the original render to the same name
+// because the template drops the version.
+type Conflict string
+
+const (
+	ConflictKeep    Conflict = ""        // leave the file under its old name
+	ConflictCounter Conflict = "counter" // "Song (2).flac", "Song (3).flac"…
+	ConflictVersion Conflict = "version" // "Song (Remix).flac", else a counter
+	ConflictID      Conflict = "id"      // "Song [12345].flac", else a counter
+)
+
+// Valid reports whether c is a known strategy.
+func (c Conflict) Valid() bool {
+	return c == ConflictKeep || c == ConflictCounter || c == ConflictVersion || c == ConflictID
+}
+
+// maxCounter bounds the numbers Resolve tries.
+const maxCounter = 999
+
+// Resolve returns dest when no file has it, or else the name c gives the
+// file instead: dest's name with the title's version (see Version), the
+// track's id or a counter appended. A version already in the name, or a
+// missing id, falls through to the counter. It reports false when c is
+// ConflictKeep or no name is free.
+func (c Conflict) Resolve(dest, version, id string) (string, bool) {
+	if !exists(dest) {
+		return dest, true
+	}
+	if c == ConflictKeep {
+		return dest, false
+	}
+	dir, base := filepath.Split(dest)
+	stem, ext := splitExt(base)
+	free := func(suffix string) (string, bool) {
+		if suffix = SanitizeComponent(suffix); suffix == "" {
+			return "", false
+		}
+		p := dir + SafeName(stem+" "+suffix+ext)
+		return p, !exists(p)
+	}
+	switch c {
+	case ConflictVersion:
+		if version != "" && !strings.HasSuffix(strings.ToLower(stem), strings.ToLower(version)) {
+			if p, ok := free(version); ok {
+				return p, true
+			}
+		}
+	case ConflictID:
+		if id != "" {
+			if p, ok := free("[" + id + "]"); ok {
+				return p, true
+			}
+		}
+	}
+	for n := 2; n <= maxCounter; n++ {
+		if p, ok := free(fmt.Sprintf("(%d)", n)); ok {
+			return p, true
+		}
+	}
+	return dest, false
+}
+
+// exists reports whether anything is at path.
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -0,0 +1,54 @@
+package naming
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConflictResolve(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "Song.flac")
+	if got, ok := ConflictCounter.Resolve(dest, "", ""); !ok || got != dest {
+		t.Errorf("free name = %q, %v; want it unchanged", got, ok)
+	}
+	for _, name := range []string{"Song.flac", "Song (2).flac"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		c           Conflict
+		version, id string
+		want        string
+		ok          bool
+	}{
+		{ConflictKeep, "(Remix)", "1", "Song.flac", false},
+		{ConflictCounter, "(Remix)", "1", "Song (3).flac", true},
+		{ConflictVersion, "(Remix)", "1", "Song (Remix).flac", true},
+		{ConflictVersion, "", "1", "Song (3).flac", true},
+		{ConflictID, "(Remix)", "12345", "Song [12345].flac", true},
+		{ConflictID, "", "", "Song (3).flac", true},
+	}
+	for _, tt := range tests {
+		got, ok := tt.c.Resolve(dest, tt.version, tt.id)
+		if filepath.Base(got) != tt.want || ok != tt.ok {
+			t.Errorf("%q.Resolve(%q, %q) = %q, %v; want %q, %v", tt.c, tt.version, tt.id, filepath.Base(got), ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	for title, want := range map[string]string{
+		"Song":                         "",
+		"Song (Remix)":                 "(Remix)",
+		"Song (Remix) [2011 Remaster]": "(Remix) [2011 Remaster]",
+		"夜に駆ける (Yoru ni Kakeru)":       "",
+		"Song (feat. Someone) (Live)":  "(feat. Someone) (Live)",
+	} {
+		if got := Version(title); got != want {
+			t.Errorf("Version(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
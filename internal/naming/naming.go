@@ -0,0 +1,204 @@
+// Package naming renders FLACidal's filename templates. flacidal-core's
+// downloader expands a fixed set of tokens itself; templates that use
+// anything beyond that set (see NeedsRender) are rendered here after the
+// download finishes and the file is renamed to the result.
+package naming
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Values are the per-track inputs a template can reference.
+type Values struct {
+	Title         string
+	Artist        string
+	AlbumArtist   string
+	Album         string
+	Year          string
+	Date          string // full release date; {date} falls back to Year
+	Genre         string
+	ISRC          string
+	Quality       string
+	Source        string
+	ID            string
+	Track         int
+	Disc          int
+	PlaylistIndex int
+	PlaylistNum   int // position in a playlist; 0 outside playlists
+	PlaylistTotal int // playlist length, for {playlistnum}'s default padding
+}
+
+// Token documents one template token for the settings UI.
+type Token struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Example     string `json:"example"`
+	Numeric     bool   `json:"numeric"` // accepts the {name:N} zero-padding form
+}
+
+// tokens is the authoritative token list, in display order.
+var tokens = []Token{
+	{Name: "artist", Description: "Track artist", Example: "Daft Punk"},
+	{Name: "albumartist", Description: "Album artist (falls back to artist)", Example: "Daft Punk"},
+	{Name: "title", Description: "Track title", Example: "Contact"},
+	{Name: "album", Description: "Album title", Example: "Random Access Memories"},
+	{Name: "track", Description: "Track number on its disc", Example: "08", Numeric: true},
+	{Name: "disc", Description: "Disc number (alias: discnumber)", Example: "1", Numeric: true},
+	{Name: "year", Description: "Release year", Example: "2013"},
+	{Name: "isrc", Description: "ISRC code", Example: "USQX91300108"},
+	{Name: "quality", Description: "Delivered quality", Example: "LOSSLESS"},
+	{Name: "source", Description: "Source the file came from", Example: "tidal"},
+	{Name: "id", Description: "Source track ID", Example: "28048259"},
+	{Name: "playlistindex", Description: "Position in the playlist or album being downloaded", Example: "3", Numeric: true},
+	{Name: "playlistnum", Description: "Position in the playlist, padded to its length (empty outside playlists)", Example: "03", Numeric: true},
+}
+
+// coreTokens are the tokens flacidal-core's own formatter expands.
+var coreTokens = map[string]bool{
+	"artist": true, "albumartist": true, "title": true, "album": true,
+	"track": true, "discnumber": true, "year": true, "isrc": true,
+}
+
+// tokenRe matches {name} and {name:N}.
+var tokenRe = regexp.MustCompile(`\{([a-z]+)(?::(\d+))?\}`)
+
+// Tokens returns the documented template tokens.
+func Tokens() []Token {
+	return append([]Token(nil), tokens...)
+}
+
+// NeedsRender reports whether tmpl uses a token or padding form that
+// flacidal-core can't expand, so FLACidal must render the name itself.
+func NeedsRender(tmpl string) bool {
+	for _, m := range tokenRe.FindAllStringSubmatch(tmpl, -1) {
+		if m[2] != "" || !coreTokens[m[1]] {
+			if m[1] == "disc" && m[2] == "" {
+				continue // plain {disc} is rewritten to {discnumber} for core
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Render expands tmpl with v. Numeric tokens take an optional width, e.g.
+// {track:3} → "008". {tracknumber}, {date} and {genre}, the tokens of
+// flacidal-core's rename templates, are understood too. Unknown tokens are
+// left as written so typos are visible in the result rather than silently
+// dropped. The result is not sanitized; callers pass it through
+// SanitizeComponent.
+func Render(tmpl string, v Values) string {
+	return tokenRe.ReplaceAllStringFunc(tmpl, func(tok string) string {
+		m := tokenRe.FindStringSubmatch(tok)
+		name, width := m[1], 0
+		if m[2] != "" {
+			width, _ = strconv.Atoi(m[2])
+		}
+		num := func(n int) string {
+			if n <= 0 {
+				return ""
+			}
+			if width > 0 {
+				return fmt.Sprintf("%0*d", width, n)
+			}
+			return strconv.Itoa(n)
+		}
+		switch name {
+		case "artist":
+			return v.Artist
+		case "albumartist":
+			if v.AlbumArtist != "" {
+				return v.AlbumArtist
+			}
+			return v.Artist
+		case "title":
+			return v.Title
+		case "album":
+			return v.Album
+		case "track", "tracknumber":
+			if width == 0 {
+				width = 2 // two digits by default so names sort correctly
+			}
+			return num(v.Track)
+		case "disc", "discnumber":
+			return num(v.Disc)
+		case "year":
+			return v.Year
+		case "date":
+			if v.Date != "" {
+				return v.Date
+			}
+			return v.Year
+		case "genre":
+			return v.Genre
+		case "isrc":
+			return v.ISRC
+		case "quality":
+			return v.Quality
+		case "source":
+			return v.Source
+		case "id":
+			return v.ID
+		case "playlistindex":
+			return num(v.PlaylistIndex)
+		case "playlistnum":
+			if width == 0 {
+				width = max(2, len(strconv.Itoa(v.PlaylistTotal)))
+			}
+			return num(v.PlaylistNum)
+		}
+		return tok
+	})
+}
+
+// SanitizeComponent makes s safe to use as a single file or folder name:
+// path separators and characters reserved on Windows are replaced, runs of
+// whitespace collapse, leading/trailing dots and spaces are trimmed, and
+// the SafeName rules (reserved device names, length) are applied.
+func SanitizeComponent(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', '|', ':':
+			return '-'
+		case '*', '?', '"', '<', '>':
+			return -1
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	return SafeName(strings.Trim(s, " ."))
+}
+
+// IsFolderTemplate reports whether tmpl has path separators, so that files
+// renamed with it move into the folders it names.
+func IsFolderTemplate(tmpl string) bool {
+	return strings.ContainsAny(tmpl, `/\`)
+}
+
+// RenderPath expands a folder template into a relative path, the last
+// element being the file name without its extension. Each "/" or "\"
+// separated part is rendered and sanitized on its own, so a value such as
+// "AC/DC" stays one folder name, and folders that render empty are
+// dropped. It returns "" when the file name itself renders empty.
+func RenderPath(tmpl string, v Values) string {
+	parts := strings.FieldsFunc(tmpl, func(r rune) bool { return r == '/' || r == '\\' })
+	var elems []string
+	for i, part := range parts {
+		part = SanitizeComponent(Render(part, v))
+		if part == "" {
+			if i == len(parts)-1 {
+				return ""
+			}
+			continue
+		}
+		elems = append(elems, part)
+	}
+	return filepath.Join(elems...)
+}
@@ -0,0 +1,123 @@
+package naming
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	v := Values{
+		Title: "Contact", Artist: "Daft Punk", Album: "RAM", Year: "2013",
+		ISRC: "USQX91300108", Quality: "HI_RES", Source: "qobuz", ID: "123",
+		Genre: "Electronic", Track: 8, Disc: 2, PlaylistIndex: 14,
+	}
+	tests := []struct {
+		tmpl, want string
+	}{
+		{"{artist} - {title}", "Daft Punk - Contact"},
+		{"{track} {title}", "08 Contact"},
+		{"{track:3} {title}", "008 Contact"},
+		{"{disc}-{track} - {title}", "2-08 - Contact"},
+		{"{discnumber}{track:1}", "28"},
+		{"{playlistindex:4}. {title} [{quality}] ({source} {id})", "0014. Contact [HI_RES] (qobuz 123)"},
+		{"{albumartist} - {year} - {isrc}", "Daft Punk - 2013 - USQX91300108"},
+		{"{unknown} {title}", "{unknown} Contact"},
+		{"{tracknumber} - {title} ({date}, {genre})", "08 - Contact (2013, Electronic)"},
+	}
+	for _, tt := range tests {
+		if got := Render(tt.tmpl, v); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestRender_MissingNumbersAreEmpty(t *testing.T) {
+	if got := Render("{disc}{track:2}{title}", Values{Title: "x"}); got != "x" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRender_PlaylistNum(t *testing.T) {
+	tests := []struct {
+		v    Values
+		tmpl string
+		want string
+	}{
+		{Values{PlaylistNum: 3, PlaylistTotal: 12}, "{playlistnum}", "03"},
+		{Values{PlaylistNum: 7, PlaylistTotal: 150}, "{playlistnum}", "007"},
+		{Values{PlaylistNum: 7, PlaylistTotal: 150}, "{playlistnum:1}", "7"},
+		{Values{PlaylistIndex: 4}, "{playlistnum}", ""}, // not a playlist
+	}
+	for _, tt := range tests {
+		if got := Render(tt.tmpl, tt.v); got != tt.want {
+			t.Errorf("Render(%q, %+v) = %q, want %q", tt.tmpl, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsRender(t *testing.T) {
+	tests := map[string]bool{
+		"{artist} - {title}":          false,
+		"{discnumber}-{track}":        false,
+		"{disc}-{track}":              false,
+		"{track:3} {title}":           true,
+		"{quality}/{title}":           true,
+		"{playlistindex}. {title}":    true,
+		"{playlistnum} - {title}":     true,
+		"{artist} - {title} [{id}]":   true,
+		"{albumartist} - {year}":      false,
+		"{source} {isrc}":             true,
+		"no tokens at all":            false,
+		"{disc:2}-{track} - {title}":  true,
+		"{artist} - {title} {source}": true,
+	}
+	for tmpl, want := range tests {
+		if got := NeedsRender(tmpl); got != want {
+			t.Errorf("NeedsRender(%q) = %v, want %v", tmpl, got, want)
+		}
+	}
+}
+
+func TestRenderPath(t *testing.T) {
+	v := Values{Title: "T.N.T.", Artist: "AC/DC", Album: "High Voltage", Track: 3}
+	tests := []struct {
+		tmpl, want string
+	}{
+		{"{artist}/{album}/{tracknumber} - {title}", filepath.Join("AC-DC", "High Voltage", "03 - T.N.T")},
+		{`{artist}\{album}\{title}`, filepath.Join("AC-DC", "High Voltage", "T.N.T")},
+		{"/{artist}//{year}/{title}", filepath.Join("AC-DC", "T.N.T")}, // empty folders dropped
+		{"../{album}/{title}", filepath.Join("High Voltage", "T.N.T")},
+		{"{artist}/{year}", ""},
+	}
+	for _, tt := range tests {
+		if got := RenderPath(tt.tmpl, v); got != tt.want {
+			t.Errorf("RenderPath(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+	if IsFolderTemplate("{artist} - {title}") || !IsFolderTemplate("{artist}/{title}") || !IsFolderTemplate(`{artist}\{title}`) {
+		t.Error("IsFolderTemplate misreads separators")
+	}
+}
+
+func TestSanitizeComponent(t *testing.T) {
+	tests := map[string]string{
+		"AC/DC - Back in Black": "AC-DC - Back in Black",
+		`What? "Why" <not>*`:    "What Why not",
+		"Title: Subtitle":       "Title- Subtitle",
+		"  spaced   out . ":     "spaced out",
+		"...hidden":             "hidden",
+	}
+	for in, want := range tests {
+		if got := SanitizeComponent(in); got != want {
+			t.Errorf("SanitizeComponent(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTokens_ReturnsCopy(t *testing.T) {
+	got := Tokens()
+	got[0].Name = "changed"
+	if Tokens()[0].Name == "changed" {
+		t.Error("Tokens() exposed the package-level slice")
+	}
+}
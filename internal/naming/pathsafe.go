@@ -0,0 +1,140 @@
+package naming
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// DefaultMaxPathLength is Windows' MAX_PATH (260) less the terminating NUL,
+// the path limit on Windows when none is configured (see PathLimit).
+const DefaultMaxPathLength = 259
+
+// goos is runtime.GOOS, swapped in tests.
+var goos = runtime.GOOS
+
+// PathLimit resolves a MaxPathLength setting: max itself when positive,
+// otherwise DefaultMaxPathLength on Windows, where MAX_PATH applies, and 0
+// (no limit) elsewhere.
+func PathLimit(max int) int {
+	if max > 0 {
+		return max
+	}
+	if goos == "windows" {
+		return DefaultMaxPathLength
+	}
+	return 0
+}
+
+// MaxComponentLength is the longest single file or folder name NTFS, ext4
+// and APFS all accept.
+const MaxComponentLength = 255
+
+// maxExtLength bounds what counts as an extension when truncating, so a
+// dotted title ("Vol. 2 ... Remastered") isn't mistaken for one.
+const maxExtLength = 8
+
+// reservedNames are Windows device names. They can't be used as a file or
+// folder name, with or without an extension.
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SafeName applies the Windows rules that character replacement alone
+// doesn't cover: reserved device names get a "_" suffix ("CON" → "CON_",
+// "nul.flac" → "nul_.flac"), trailing dots and spaces are trimmed, and the
+// name is truncated to MaxComponentLength, keeping its extension. It expects
+// a name already free of separators, e.g. from SanitizeComponent or
+// flacidal-core's SanitizeFileName.
+func SafeName(name string) string {
+	name = strings.TrimRight(name, " .")
+	// Windows matches device names against everything before the first dot.
+	head, rest, dotted := strings.Cut(name, ".")
+	if reservedNames[strings.ToUpper(strings.TrimRight(head, " "))] {
+		name = strings.TrimRight(head, " ") + "_"
+		if dotted {
+			name += "." + rest
+		}
+	}
+	return truncateName(name, MaxComponentLength)
+}
+
+// FitPath returns path with its final element made safe (SafeName) and, if
+// the whole path is longer than max UTF-16 code units, its name shortened
+// to fit, keeping the extension. A max of 0 sets no limit. It fails when
+// the directory alone leaves no room for a name.
+func FitPath(path string, max int) (string, error) {
+	dir, base := filepath.Split(path)
+	base = SafeName(base)
+	if over := Overflow(dir+base, max); over > 0 {
+		room := pathLength(base) - over
+		_, ext := splitExt(base)
+		if room <= pathLength(ext) {
+			return path, fmt.Errorf("path too long: %s exceeds %d characters", filepath.Clean(dir), max)
+		}
+		base = truncateName(base, room)
+	}
+	return dir + base, nil
+}
+
+// Overflow returns how many UTF-16 code units path runs past limit, or 0
+// when it fits or limit is 0 (no limit).
+func Overflow(path string, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return max(pathLength(path)-limit, 0)
+}
+
+// Shorten drops n UTF-16 code units from the end of s, never splitting a
+// character, and trims the spaces and dots left at the end.
+func Shorten(s string, n int) string {
+	return strings.TrimRight(cut(s, pathLength(s)-n), " .")
+}
+
+// truncateName shortens name to at most max UTF-16 code units, cutting the
+// stem rather than the extension and never splitting a character.
+func truncateName(name string, max int) string {
+	if pathLength(name) <= max {
+		return name
+	}
+	stem, ext := splitExt(name)
+	return strings.TrimRight(cut(stem, max-pathLength(ext)), " .") + ext
+}
+
+// cut returns the longest prefix of s within budget UTF-16 code units.
+func cut(s string, budget int) string {
+	n := 0
+	for i, r := range s {
+		w := 1
+		if r >= 0x10000 {
+			w = 2 // surrogate pair
+		}
+		if n+w > budget {
+			return s[:i]
+		}
+		n += w
+	}
+	return s
+}
+
+// splitExt splits name into stem and a short extension (including the dot).
+func splitExt(name string) (stem, ext string) {
+	ext = filepath.Ext(name)
+	if ext == name || utf8.RuneCountInString(ext) > maxExtLength || strings.ContainsRune(ext, ' ') {
+		return name, ""
+	}
+	return strings.TrimSuffix(name, ext), ext
+}
+
+// pathLength measures s the way Windows does, in UTF-16 code units.
+func pathLength(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
@@ -0,0 +1,101 @@
+package naming
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSafeName(t *testing.T) {
+	tests := map[string]string{
+		"CON":          "CON_",
+		"nul.flac":     "nul_.flac",
+		"Com1.tar.gz":  "Com1_.tar.gz",
+		"LPT9 .flac":   "LPT9_.flac",
+		"Console":      "Console",
+		"Album...":     "Album",
+		"Track . ":     "Track",
+		"Aux Cord.lrc": "Aux Cord.lrc",
+	}
+	for in, want := range tests {
+		if got := SafeName(in); got != want {
+			t.Errorf("SafeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSafeName_TruncatesLongNames(t *testing.T) {
+	got := SafeName(strings.Repeat("a", 300) + ".flac")
+	if pathLength(got) != MaxComponentLength || !strings.HasSuffix(got, ".flac") {
+		t.Errorf("SafeName(long) = %d chars %q, want %d ending in .flac", pathLength(got), got[len(got)-10:], MaxComponentLength)
+	}
+}
+
+func TestFitPath(t *testing.T) {
+	dir := filepath.Join("music", "Artist", "Album")
+	path := filepath.Join(dir, strings.Repeat("x", 80)+".flac")
+
+	got, err := FitPath(path, 60)
+	if err != nil {
+		t.Fatalf("FitPath: %v", err)
+	}
+	if pathLength(got) != 60 || filepath.Dir(got) != dir || filepath.Ext(got) != ".flac" {
+		t.Errorf("FitPath = %q (%d)", got, pathLength(got))
+	}
+
+	if got, _ := FitPath(path, 1000); got != path {
+		t.Errorf("short enough path changed: %q", got)
+	}
+	if _, err := FitPath(path, len(dir)+3); err == nil {
+		t.Error("expected an error when the directory leaves no room")
+	}
+	if got, _ := FitPath(path, 0); got != path {
+		t.Errorf("no limit: path changed to %q", got)
+	}
+}
+
+func TestPathLimit(t *testing.T) {
+	defer func(os string) { goos = os }(goos)
+	for _, tt := range []struct {
+		goos string
+		max  int
+		want int
+	}{
+		{"windows", 0, DefaultMaxPathLength},
+		{"linux", 0, 0},
+		{"darwin", 0, 0},
+		{"linux", 1024, 1024},
+		{"windows", 4096, 4096},
+	} {
+		goos = tt.goos
+		if got := PathLimit(tt.max); got != tt.want {
+			t.Errorf("PathLimit(%d) on %s = %d, want %d", tt.max, tt.goos, got, tt.want)
+		}
+	}
+}
+
+func TestOverflowAndShorten(t *testing.T) {
+	if got := Overflow("music/"+strings.Repeat("x", 20), 16); got != 10 {
+		t.Errorf("Overflow = %d, want 10", got)
+	}
+	if got := Overflow(strings.Repeat("x", 5000), 0); got != 0 {
+		t.Errorf("Overflow without a limit = %d, want 0", got)
+	}
+	if got := Shorten("Song Title, Part 2", 8); got != "Song Title" {
+		t.Errorf("Shorten = %q, want %q", got, "Song Title")
+	}
+	if got := Shorten("é🎵🎵", 3); got != "é" {
+		t.Errorf("Shorten split a character: %q", got)
+	}
+}
+
+func TestTruncateName_KeepsCharactersWhole(t *testing.T) {
+	// Each 🎵 is a surrogate pair (2 UTF-16 units); é is one.
+	got := truncateName("é🎵🎵🎵.flac", 8)
+	if got != "é🎵.flac" {
+		t.Errorf("truncateName = %q, want %q", got, "é🎵.flac")
+	}
+	if got := truncateName("Vol. 2 of a long series", 10); got != "Vol. 2 of" {
+		t.Errorf("dotted title treated as extension: %q", got)
+	}
+}
@@ -0,0 +1,156 @@
+package naming
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TitleLanguage selects which spelling of a bilingual title to keep. Sources
+// often return titles in two scripts at once — "夜に駆ける (Yoru ni Kakeru)",
+// "Sukiyaki / 上を向いて歩こう" — one in the original script, the other a
+// transliteration or translation.
+type TitleLanguage string
+
+const (
+	TitleAsIs      TitleLanguage = ""          // as the source spells it
+	TitleOriginal  TitleLanguage = "original"  // the non-Latin spelling
+	TitleLocalized TitleLanguage = "localized" // the Latin spelling
+)
+
+// Valid reports whether l is a known preference.
+func (l TitleLanguage) Valid() bool {
+	return l == TitleAsIs || l == TitleOriginal || l == TitleLocalized
+}
+
+// Pick returns the spelling of title l prefers. Titles that aren't one
+// non-Latin and one Latin spelling side by side are returned unchanged.
+// version is the track's version as the source reports it ("Live", "2011
+// Remaster"); its group at the end of title is kept. Without one, trailing
+// groups that read as versions, such as "(Live)" or "[2011 Remaster]",
+// are kept instead.
+func (l TitleLanguage) Pick(title, version string) string {
+	if l == TitleAsIs {
+		return title
+	}
+	base, suffix := splitVersion(title)
+	if version != "" {
+		base, suffix = cutVersion(title, version)
+	}
+
+	var main, alt string
+	if rest, group, ok := cutBracketGroup(base); ok && rest != "" {
+		main, alt = rest, group
+	} else if a, b, ok := strings.Cut(base, " / "); ok && !strings.Contains(b, " / ") {
+		main, alt = strings.TrimSpace(a), strings.TrimSpace(b)
+	} else {
+		return title
+	}
+	mainLatin, altLatin := latinOnly(main), latinOnly(alt)
+	if mainLatin == altLatin || !hasLetter(main) || !hasLetter(alt) {
+		return title
+	}
+	if (l == TitleLocalized) == mainLatin {
+		return main + suffix
+	}
+	return alt + suffix
+}
+
+// Version returns title's trailing version groups as written, such as
+// "(Live)" or "(Remix) [2011 Remaster]"; "" when it has none.
+func Version(title string) string {
+	_, suffix := splitVersion(title)
+	return strings.TrimSpace(suffix)
+}
+
+// cutVersion splits version's bracketed group off the end of title:
+// "A (Live)" and "Live" yield "A" and " (Live)". A title without it is
+// all base.
+func cutVersion(title, version string) (base, suffix string) {
+	title = strings.TrimSpace(title)
+	if rest, group, ok := cutBracketGroup(title); ok && strings.EqualFold(group, strings.TrimSpace(version)) {
+		return rest, title[len(rest):]
+	}
+	return title, ""
+}
+
+// splitVersion splits the trailing version groups off title: "A (Live)"
+// yields "A" and " (Live)".
+func splitVersion(title string) (base, suffix string) {
+	title = strings.TrimSpace(title)
+	base = title
+	for {
+		rest, group, ok := cutBracketGroup(base)
+		if !ok || !isVersion(group) {
+			break
+		}
+		base = rest
+	}
+	return base, title[len(base):]
+}
+
+// closing maps the brackets cutBracketGroup recognizes to their openers.
+var closing = map[rune]rune{')': '(', ']': '[', '）': '（', '】': '【'}
+
+// cutBracketGroup splits a trailing bracketed group off s: "A (B)" yields
+// "A", "B". Nested brackets of the same kind are balanced.
+func cutBracketGroup(s string) (rest, group string, ok bool) {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s, "", false
+	}
+	end := runes[len(runes)-1]
+	open, isBracket := closing[end]
+	if !isBracket {
+		return s, "", false
+	}
+	depth := 0
+	for i := len(runes) - 1; i >= 0; i-- {
+		switch runes[i] {
+		case end:
+			depth++
+		case open:
+			if depth--; depth == 0 {
+				return strings.TrimSpace(string(runes[:i])), strings.TrimSpace(string(runes[i+1 : len(runes)-1])), true
+			}
+		}
+	}
+	return s, "", false
+}
+
+// versionWords mark a bracketed group as a version or credit rather than
+// another spelling of the title.
+var versionWords = map[string]bool{
+	"live": true, "remix": true, "mix": true, "remaster": true, "remastered": true,
+	"version": true, "ver": true, "edit": true, "instrumental": true, "inst": true,
+	"acoustic": true, "demo": true, "mono": true, "stereo": true, "bonus": true,
+	"feat": true, "ft": true, "featuring": true, "with": true, "from": true,
+	"size": true, "cover": true, "karaoke": true, "extended": true, "radio": true,
+	"session": true, "deluxe": true, "explicit": true, "clean": true, "reprise": true,
+	"interlude": true, "unplugged": true, "orchestral": true, "piano": true,
+}
+
+// isVersion reports whether a bracketed group names a version.
+func isVersion(group string) bool {
+	for _, w := range strings.FieldsFunc(strings.ToLower(group), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if versionWords[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// latinOnly reports whether every letter in s is Latin.
+func latinOnly(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
@@ -0,0 +1,57 @@
+package naming
+
+import "testing"
+
+func TestTitleLanguagePick(t *testing.T) {
+	tests := []struct {
+		title               string
+		original, localized string
+	}{
+		{"夜に駆ける (Yoru ni Kakeru)", "夜に駆ける", "Yoru ni Kakeru"},
+		{"Yoru ni Kakeru (夜に駆ける)", "夜に駆ける", "Yoru ni Kakeru"},
+		{"夜に駆ける (Yoru ni Kakeru) (Live)", "夜に駆ける (Live)", "Yoru ni Kakeru (Live)"},
+		{"Sukiyaki / 上を向いて歩こう [2011 Remaster]", "上を向いて歩こう [2011 Remaster]", "Sukiyaki [2011 Remaster]"},
+		{"Кино（Kino）", "Кино", "Kino"},
+		// Not two spellings: left alone.
+		{"夜に駆ける (Live)", "夜に駆ける (Live)", "夜に駆ける (Live)"},
+		{"Song (Remastered 2011)", "Song (Remastered 2011)", "Song (Remastered 2011)"},
+		{"Song (Acoustic) / Other", "Song (Acoustic) / Other", "Song (Acoustic) / Other"},
+		{"紅蓮華 (紅蓮華)", "紅蓮華 (紅蓮華)", "紅蓮華 (紅蓮華)"},
+		{"(Yoru ni Kakeru)", "(Yoru ni Kakeru)", "(Yoru ni Kakeru)"},
+		{"Plain", "Plain", "Plain"},
+	}
+	for _, tt := range tests {
+		if got := TitleOriginal.Pick(tt.title, ""); got != tt.original {
+			t.Errorf("original %q = %q, want %q", tt.title, got, tt.original)
+		}
+		if got := TitleLocalized.Pick(tt.title, ""); got != tt.localized {
+			t.Errorf("localized %q = %q, want %q", tt.title, got, tt.localized)
+		}
+		if got := TitleAsIs.Pick(tt.title, ""); got != tt.title {
+			t.Errorf("as is %q = %q", tt.title, got)
+		}
+	}
+}
+
+func TestTitleLanguagePick_SourceVersion(t *testing.T) {
+	tests := []struct {
+		title, version      string
+		original, localized string
+	}{
+		// A version the word list doesn't know is still kept.
+		{"夜に駆ける (Yoru ni Kakeru) (Tokyo 2020)", "Tokyo 2020", "夜に駆ける (Tokyo 2020)", "Yoru ni Kakeru (Tokyo 2020)"},
+		{"Sukiyaki / 上を向いて歩こう [Live]", "live", "上を向いて歩こう [Live]", "Sukiyaki [Live]"},
+		// The version isn't in the title: no group is mistaken for it.
+		{"夜に駆ける (Yoru ni Kakeru)", "Remix", "夜に駆ける", "Yoru ni Kakeru"},
+		// The bracketed group is the other spelling, not the version.
+		{"上を向いて歩こう (Sukiyaki)", "Mono", "上を向いて歩こう", "Sukiyaki"},
+	}
+	for _, tt := range tests {
+		if got := TitleOriginal.Pick(tt.title, tt.version); got != tt.original {
+			t.Errorf("original %q (%s) = %q, want %q", tt.title, tt.version, got, tt.original)
+		}
+		if got := TitleLocalized.Pick(tt.title, tt.version); got != tt.localized {
+			t.Errorf("localized %q (%s) = %q, want %q", tt.title, tt.version, got, tt.localized)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package naming
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// UnicodeMode selects how non-ASCII characters in file names are written.
+// Some devices and sync tools mangle decomposed (NFD) names, as macOS
+// produces, or anything outside ASCII.
+type UnicodeMode string
+
+const (
+	UnicodeKeep  UnicodeMode = ""      // as the source spells it
+	UnicodeNFC   UnicodeMode = "nfc"   // composed form: "é" is one character
+	UnicodeASCII UnicodeMode = "ascii" // transliterated: "Björk" → "Bjork"
+)
+
+// Valid reports whether m is a known mode.
+func (m UnicodeMode) Valid() bool {
+	return m == UnicodeKeep || m == UnicodeNFC || m == UnicodeASCII
+}
+
+// Apply rewrites s according to m.
+func (m UnicodeMode) Apply(s string) string {
+	switch m {
+	case UnicodeNFC:
+		return norm.NFC.String(s)
+	case UnicodeASCII:
+		return Transliterate(s)
+	}
+	return s
+}
+
+// Transliterate spells s in ASCII where it knows how: accents are dropped,
+// ligatures and letters such as ß, ø and þ are spelled out, and Greek,
+// Cyrillic and kana are romanized. Characters without a reading that can
+// be derived character by character — CJK ideographs, Hangul, Arabic… —
+// are kept, so a name never ends up empty.
+func Transliterate(s string) string {
+	runes := []rune(norm.NFC.String(s))
+	var b strings.Builder
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case isKana(r):
+			i += writeKana(&b, runes[i:]) - 1
+		default:
+			b.WriteString(transliterateRune(r))
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+func transliterateRune(r rune) string {
+	if t, ok := lookup(r); ok {
+		return t
+	}
+	// Compatibility-decompose ("ﬁ" → "fi", "Ａ" → "A", "ά" → "α" + accent)
+	// and drop the combining marks.
+	var b strings.Builder
+	for _, d := range norm.NFKD.String(string(r)) {
+		switch t, ok := lookup(d); {
+		case unicode.Is(unicode.Mn, d):
+		case d < utf8.RuneSelf:
+			b.WriteRune(d)
+		case ok:
+			b.WriteString(t)
+		default:
+			return string(r) // no ASCII reading: keep as is
+		}
+	}
+	if b.Len() == 0 {
+		return string(r)
+	}
+	return b.String()
+}
+
+// lookup finds r in translit, capitalizing the result for upper-case r.
+func lookup(r rune) (string, bool) {
+	lower := unicode.ToLower(r)
+	t, ok := translit[lower]
+	if ok && lower != r && t != "" {
+		t = strings.ToUpper(t[:1]) + t[1:]
+	}
+	return t, ok
+}
+
+// translit maps lower-case letters that don't decompose to an ASCII base,
+// plus typographic punctuation, to ASCII.
+var translit = map[rune]string{
+	// Latin
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'þ': "th",
+	'ł': "l", 'ı': "i", 'ŋ': "ng", 'ħ': "h", 'ŧ': "t",
+	// Punctuation
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '“': "'", '”': "'", '„': "'",
+	'«': "'", '»': "'", '‹': "'", '›': "'", '–': "-", '—': "-", '‐': "-",
+	'‑': "-", '…': "...", '×': "x", '•': "-", '·': "-",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u", 'ђ': "dj", 'ј': "j",
+	'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz",
+}
+
+// kana maps hiragana to Hepburn romaji; katakana is looked up through its
+// hiragana counterpart.
+var kana = map[rune]string{}
+
+func init() {
+	const chars = "あいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほまみむめもやゆよらりるれろわをん" +
+		"がぎぐげござじずぜぞだぢづでどばびぶべぼぱぴぷぺぽぁぃぅぇぉゔ"
+	romaji := strings.Fields("a i u e o ka ki ku ke ko sa shi su se so ta chi tsu te to " +
+		"na ni nu ne no ha hi fu he ho ma mi mu me mo ya yu yo ra ri ru re ro wa wo n " +
+		"ga gi gu ge go za ji zu ze zo da ji zu de do ba bi bu be bo pa pi pu pe po a i u e o vu")
+	i := 0
+	for _, r := range chars {
+		kana[r] = romaji[i]
+		i++
+	}
+}
+
+// youon are the small kana that combine with a preceding -i syllable
+// (き + ゃ → "kya").
+var youon = map[rune]string{'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo"}
+
+func isKana(r rune) bool {
+	return (r >= 0x3041 && r <= 0x3096) || (r >= 0x30A1 && r <= 0x30FA) || r == 'ー'
+}
+
+// toHiragana maps katakana to the matching hiragana.
+func toHiragana(r rune) rune {
+	if r >= 0x30A1 && r <= 0x30F6 {
+		return r - 0x60
+	}
+	return r
+}
+
+// writeKana romanizes the kana at the start of rs and returns how many
+// runes it consumed.
+func writeKana(b *strings.Builder, rs []rune) int {
+	r := toHiragana(rs[0])
+	switch r {
+	case 'っ': // sokuon: doubles the next consonant
+		if len(rs) > 1 {
+			if next := kana[toHiragana(rs[1])]; next != "" && !strings.ContainsRune("aiueon", rune(next[0])) {
+				if strings.HasPrefix(next, "ch") {
+					b.WriteByte('t')
+				} else {
+					b.WriteByte(next[0])
+				}
+			}
+		}
+		return 1
+	case 'ー': // long vowel mark
+		return 1
+	}
+	roma, ok := kana[r]
+	if !ok {
+		b.WriteRune(rs[0])
+		return 1
+	}
+	if len(rs) > 1 {
+		if y, ok := youon[toHiragana(rs[1])]; ok && len(roma) > 1 && strings.HasSuffix(roma, "i") {
+			stem := strings.TrimSuffix(roma, "i")
+			if stem == "sh" || stem == "ch" || stem == "j" {
+				y = y[1:] // しゃ → "sha", not "shya"
+			}
+			b.WriteString(stem + y)
+			return 2
+		}
+	}
+	b.WriteString(roma)
+	return 1
+}
@@ -0,0 +1,45 @@
+package naming
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := map[string]string{
+		"Björk":                       "Bjork",
+		"Sigur Rós – Ágætis byrjun":   "Sigur Ros - Agaetis byrjun",
+		"Motörhead":                   "Motorhead",
+		"Straße":                      "Strasse",
+		"Øresund":                     "Oresund",
+		"Мумий Тролль":                "Mumiy Troll",
+		"Жанна":                       "Zhanna",
+		"Ελληνικά":                    "Ellinika",
+		"カラオケ":                        "karaoke",
+		"きゃりーぱみゅぱみゅ":                  "kyaripamyupamyu",
+		"がっこう":                        "gakkou",
+		"しゃしん":                        "shashin",
+		"坂本龍一":                        "坂本龍一",
+		"ﬁnal Ｍix":                    "final Mix",
+		"Björk (decomposed)":         "Bjork (decomposed)",
+		"plain ASCII stays the same!": "plain ASCII stays the same!",
+	}
+	for in, want := range tests {
+		if got := Transliterate(in); got != want {
+			t.Errorf("Transliterate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestUnicodeMode_Apply(t *testing.T) {
+	decomposed := "Björk"
+	if got := UnicodeKeep.Apply(decomposed); got != decomposed {
+		t.Errorf("keep changed the name: %q", got)
+	}
+	if got := UnicodeNFC.Apply(decomposed); got != "Björk" || len(got) != 6 {
+		t.Errorf("nfc = %q (%d bytes), want composed Björk", got, len(got))
+	}
+	if got := UnicodeASCII.Apply(decomposed); got != "Bjork" {
+		t.Errorf("ascii = %q", got)
+	}
+	if UnicodeMode("latin1").Valid() || !UnicodeASCII.Valid() {
+		t.Error("Valid mismatch")
+	}
+}
@@ -0,0 +1,172 @@
+package postprocess
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"flacidal/internal/naming"
+	"flacidal/internal/textmatch"
+)
+
+// VariousArtists is the album artist compilations are tagged and filed
+// under when the source doesn't name one.
+const VariousArtists = "Various Artists"
+
+// compilationArtists is how many distinct track artists an album needs,
+// beyond which it counts as a compilation unless its album artist is on
+// at least half the tracks (an artist album with many guests).
+const compilationArtists = 4
+
+// IsCompilation reports whether an album with the given album artist and
+// per-track artists is a various-artists compilation.
+func IsCompilation(albumArtist string, artists []string) bool {
+	if isVariousArtists(albumArtist) {
+		return true
+	}
+	distinct := map[string]bool{}
+	for _, a := range artists {
+		if a = textmatch.Normalize(a, textmatch.Default); a != "" {
+			distinct[a] = true
+		}
+	}
+	if len(distinct) <= compilationArtists {
+		return false
+	}
+	if albumArtist == "" {
+		return true
+	}
+	onTracks := 0
+	for _, a := range artists {
+		if credited(a, albumArtist) {
+			onTracks++
+		}
+	}
+	return onTracks*2 < len(artists)
+}
+
+// creditSeparators split a track's artist credit into names: "A feat. B",
+// "A & B", "A, B", "A x B"…
+var creditSeparators = regexp.MustCompile(`(?i)\s*(?:[,;&/]|\s(?:feat\.?|ft\.?|featuring|with|vs\.?|x)\s)\s*`)
+
+// credited reports whether the artist credit names artist, as the whole
+// credit or one of its names, compared as normalized text: "A feat. B"
+// credits "A" and "B", but "Aaron" doesn't credit "A".
+func credited(credit, artist string) bool {
+	if textmatch.Equal(credit, artist, textmatch.Default) {
+		return true
+	}
+	for _, name := range creditSeparators.Split(credit, -1) {
+		if textmatch.Equal(name, artist, textmatch.Default) {
+			return true
+		}
+	}
+	return false
+}
+
+// isVariousArtists matches the usual spellings of the compilation artist.
+func isVariousArtists(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "various artists", "various", "va", "v.a.":
+		return true
+	}
+	return false
+}
+
+// MarkCompilation flags tracks as one compilation, giving those without an
+// album artist VariousArtists.
+func MarkCompilation(tracks []Track) {
+	for i := range tracks {
+		tracks[i].Compilation = true
+		if tracks[i].AlbumArtist == "" {
+			tracks[i].AlbumArtist = VariousArtists
+		}
+	}
+}
+
+// coverFiles are the folder-level images the core downloader may save next
+// to an album's tracks.
+var coverFiles = []string{"cover.jpg", "folder.jpg"}
+
+// moveToCompilationFolder refiles a compilation track that the core
+// downloader organized under its own artist: the trailing folders the
+// FolderTemplate produced are replaced with the template rendered for the
+// album artist. Templates without {artist}, or with tokens FLACidal can't
+// render (e.g. {label}), are left alone.
+func moveToCompilationFolder(path string, t Track, opts Options) (string, error) {
+	tmpl := strings.Trim(filepath.ToSlash(opts.FolderTemplate), "/")
+	if !t.Compilation || !opts.OrganizeFolders || !strings.Contains(tmpl, "{artist}") {
+		return path, nil
+	}
+	values := t.Values()
+	values.Artist = t.AlbumArtist
+	parts := strings.Split(naming.Render(tmpl, values), "/")
+	root := filepath.Dir(path)
+	for i := range parts {
+		if strings.Contains(parts[i], "{") {
+			return path, nil
+		}
+		if parts[i] = naming.SanitizeComponent(parts[i]); parts[i] == "" {
+			return path, nil
+		}
+		root = filepath.Dir(root)
+	}
+	destDir := filepath.Join(append([]string{root}, parts...)...)
+	oldDir := filepath.Dir(path)
+	if destDir == oldDir {
+		return path, nil
+	}
+	dest := filepath.Join(destDir, filepath.Base(path))
+	if _, err := os.Stat(dest); err == nil {
+		return path, nil // already filed there by an earlier download
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return path, err
+	}
+	path, err := moveWithSidecar(path, dest)
+	if err != nil {
+		return path, err
+	}
+	LeaveFolder(oldDir, destDir, root)
+	return path, nil
+}
+
+// LeaveFolder tidies the folder a track was moved out of: once only cover
+// images remain they follow the track (or are dropped if the destination
+// has its own), and the emptied folders are removed up to root.
+// Best-effort; anything else left behind keeps the folder.
+func LeaveFolder(oldDir, destDir, root string) {
+	entries, err := os.ReadDir(oldDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || !isCoverFile(e.Name()) {
+			return
+		}
+	}
+	for _, e := range entries {
+		src := filepath.Join(oldDir, e.Name())
+		if _, err := os.Stat(filepath.Join(destDir, e.Name())); err == nil {
+			os.Remove(src) //nolint:errcheck // best-effort cleanup
+		} else {
+			os.Rename(src, filepath.Join(destDir, e.Name())) //nolint:errcheck // best-effort cleanup
+		}
+	}
+	for dir := oldDir; dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return // not empty
+		}
+	}
+}
+
+// isCoverFile reports whether name is one of coverFiles.
+func isCoverFile(name string) bool {
+	for _, c := range coverFiles {
+		if strings.EqualFold(name, c) {
+			return true
+		}
+	}
+	return false
+}
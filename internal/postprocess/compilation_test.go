@@ -0,0 +1,91 @@
+package postprocess
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsCompilation(t *testing.T) {
+	many := []string{"A", "B", "C", "D", "E"}
+	tests := []struct {
+		name        string
+		albumArtist string
+		artists     []string
+		want        bool
+	}{
+		{"various artists", "Various Artists", []string{"A", "A"}, true},
+		{"va spelling", "V.A.", nil, true},
+		{"single artist", "Daft Punk", []string{"Daft Punk", "Daft Punk"}, false},
+		{"few guests", "", []string{"A", "B", "C", "D"}, false},
+		{"many artists, no album artist", "", many, true},
+		{"many artists, album artist elsewhere", "Z", many, true},
+		{"artist album with many guests", "A", []string{"A", "A feat. B", "A feat. C", "D", "E", "F"}, false},
+		{"album artist inside other names", "Ann", []string{"Ann", "Anna", "Joanne", "Annie", "Ann-Marie", "Ann"}, true},
+		{"album artist credited with others", "Ann", []string{"Ann & Bo", "Cy, Ann", "Ann x Di", "Ed", "Fay", "Gus"}, false},
+		{"album artist spelled differently", "Beyoncé", []string{"BEYONCE", "beyoncé feat. X", "Beyonce & Y", "Z", "W", "V"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCompilation(tt.albumArtist, tt.artists); got != tt.want {
+				t.Errorf("IsCompilation(%q, %v) = %v, want %v", tt.albumArtist, tt.artists, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkCompilation(t *testing.T) {
+	tracks := []Track{{AlbumArtist: "Now!"}, {}}
+	MarkCompilation(tracks)
+	if !tracks[0].Compilation || tracks[0].AlbumArtist != "Now!" || tracks[1].AlbumArtist != VariousArtists {
+		t.Errorf("tracks = %+v", tracks)
+	}
+}
+
+func TestApply_Compilation(t *testing.T) {
+	root := t.TempDir()
+	oldDir := filepath.Join(root, "Artist A", "Hits")
+	os.MkdirAll(oldDir, 0755)
+	path := filepath.Join(oldDir, "01 - Song.flac")
+	writeBareFLAC(t, path)
+	os.WriteFile(filepath.Join(oldDir, "cover.jpg"), []byte("jpeg"), 0644)
+
+	track := Track{Artist: "Artist A", AlbumArtist: VariousArtists, Album: "Hits", Compilation: true}
+	opts := Options{OrganizeFolders: true, FolderTemplate: "{artist}/{album}"}
+	got, err := Apply(path, track, opts)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	want := filepath.Join(root, VariousArtists, "Hits", "01 - Song.flac")
+	if got != want {
+		t.Fatalf("path = %q, want %q", got, want)
+	}
+	c := readComments(t, got)
+	if c.Get("ALBUMARTIST") != VariousArtists || c.Get("COMPILATION") != "1" {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+	if _, err := os.Stat(filepath.Join(root, VariousArtists, "Hits", "cover.jpg")); err != nil {
+		t.Errorf("cover not moved along: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Artist A")); !os.IsNotExist(err) {
+		t.Errorf("emptied artist folder left behind: %v", err)
+	}
+}
+
+func TestApply_CompilationLeavesOtherLayouts(t *testing.T) {
+	track := Track{Artist: "Artist A", AlbumArtist: VariousArtists, Album: "Hits", Compilation: true}
+	for _, opts := range []Options{
+		{FolderTemplate: "{artist}/{album}"},                             // organizing off
+		{OrganizeFolders: true, FolderTemplate: ""},                      // no template
+		{OrganizeFolders: true, FolderTemplate: "{albumartist}/{album}"}, // already album artist
+		{OrganizeFolders: true, FolderTemplate: "{label}/{artist}"},      // token FLACidal can't render
+	} {
+		dir := filepath.Join(t.TempDir(), "Artist A", "Hits")
+		os.MkdirAll(dir, 0755)
+		path := filepath.Join(dir, "01 - Song.flac")
+		writeBareFLAC(t, path)
+		if got, err := Apply(path, track, opts); err != nil || got != path {
+			t.Errorf("Apply with %+v = %q, %v; want unchanged", opts, got, err)
+		}
+	}
+}
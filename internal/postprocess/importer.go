@@ -0,0 +1,306 @@
+package postprocess
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/naming"
+	"flacidal/internal/tagedit"
+	"flacidal/internal/textmatch"
+)
+
+// DurationTolerance is how far apart a file's length and a track's may be
+// for MatchFiles to pair them.
+const DurationTolerance = 3 * time.Second
+
+// TitleSimilarity is the textmatch.Similarity a file's title needs with a
+// track's for MatchFiles to pair them by title.
+const TitleSimilarity = 0.6
+
+// LocalFile is an externally downloaded FLAC file waiting to be imported.
+type LocalFile struct {
+	Path     string
+	Duration time.Duration // from STREAMINFO; 0 when unknown
+	Disc     int           // from existing tags; 0 when untagged
+	Track    int
+	Title    string // TITLE tag, or the file name without its track number
+	ISRC     string
+	Source   string // provenance tags; set when FLACidal downloaded the file
+	SourceID string
+}
+
+// ReadLocalFiles lists the FLAC files directly in dir with their length,
+// title and any disc and track numbers, ISRC and provenance they are
+// already tagged with, in track order (see sortLocalFiles). Files flacmeta
+// can't parse are skipped.
+func ReadLocalFiles(dir string) ([]LocalFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []LocalFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".flac") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		f, err := flacmeta.Read(path)
+		if err != nil {
+			continue
+		}
+		lf := LocalFile{Path: path}
+		lf.Duration, _ = f.Duration()
+		if c, err := f.Comments(); err == nil {
+			lf.Disc = leadingNumber(c.Get("DISCNUMBER"))
+			lf.Track = leadingNumber(c.Get("TRACKNUMBER"))
+			lf.Title = c.Get("TITLE")
+			lf.ISRC = c.Get("ISRC")
+			p := ReadProvenance(c)
+			lf.Source, lf.SourceID = p.Source, p.SourceID
+		}
+		if lf.Title == "" {
+			lf.Title = titleFromName(e.Name())
+		}
+		files = append(files, lf)
+	}
+	sortLocalFiles(files)
+	return files, nil
+}
+
+// sortLocalFiles orders files by their disc and track tags when every file
+// has a track number, and otherwise by the number their name starts with
+// ("01 - Intro.flac"), then by name.
+func sortLocalFiles(files []LocalFile) {
+	tagged := len(files) > 0
+	for _, f := range files {
+		tagged = tagged && f.Track > 0
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if tagged && (a.Disc != b.Disc || a.Track != b.Track) {
+			if a.Disc != b.Disc {
+				return a.Disc < b.Disc
+			}
+			return a.Track < b.Track
+		}
+		an, bn := filepath.Base(a.Path), filepath.Base(b.Path)
+		if na, nb := leadingNumber(an), leadingNumber(bn); na != nb {
+			return na < nb
+		}
+		return an < bn
+	})
+}
+
+// leadingDigits matches the number a tag value or file name starts with.
+var leadingDigits = regexp.MustCompile(`^\s*(\d+)`)
+
+// titleFromName returns a file name without its extension and the track
+// number it starts with: "03 - Song.flac" gives "Song".
+func titleFromName(name string) string {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if m := leadingDigits.FindString(name); m != "" {
+		name = name[len(m):]
+	}
+	return strings.TrimLeft(name, " -._")
+}
+
+// leadingNumber parses the number s starts with ("3/12", "03 - Song"), or 0.
+func leadingNumber(s string) int {
+	m := leadingDigits.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// Match pairs files[File] with tracks[Track]. By is "order", "duration" or
+// "title".
+type Match struct {
+	File  int    `json:"file"`
+	Track int    `json:"track"`
+	By    string `json:"by"`
+}
+
+// MatchFiles pairs files with tracks. When there are as many files as
+// tracks and each file, in order, is within DurationTolerance of its track,
+// they are paired by order. Otherwise each track, in order, takes the
+// closest remaining file within the tolerance; files or tracks without a
+// length never match that way. Tracks still unpaired then take the
+// remaining file whose title is most like theirs, compared under mode (see
+// textmatch), if it scores at least TitleSimilarity and their lengths
+// don't rule it out. Unpaired files and tracks are left out.
+func MatchFiles(files []LocalFile, tracks []Track, mode textmatch.Mode) []Match {
+	if len(files) == len(tracks) {
+		matches := make([]Match, len(files))
+		ordered := true
+		for i := range files {
+			if !durationFits(files[i].Duration, tracks[i].Duration, true) {
+				ordered = false
+				break
+			}
+			matches[i] = Match{File: i, Track: i, By: "order"}
+		}
+		if ordered {
+			return matches
+		}
+	}
+
+	var matches []Match
+	used := make([]bool, len(files))
+	paired := make([]bool, len(tracks))
+	for ti, t := range tracks {
+		best := -1
+		var bestDiff time.Duration
+		for fi, f := range files {
+			if used[fi] || !durationFits(f.Duration, t.Duration, false) {
+				continue
+			}
+			diff := (f.Duration - time.Duration(t.Duration)*time.Second).Abs()
+			if best < 0 || diff < bestDiff {
+				best, bestDiff = fi, diff
+			}
+		}
+		if best >= 0 {
+			used[best] = true
+			paired[ti] = true
+			matches = append(matches, Match{File: best, Track: ti, By: "duration"})
+		}
+	}
+
+	for ti, t := range tracks {
+		if paired[ti] {
+			continue
+		}
+		best, bestScore := -1, TitleSimilarity
+		for fi, f := range files {
+			if used[fi] || !durationFits(f.Duration, t.Duration, true) {
+				continue
+			}
+			if score := textmatch.Similarity(f.Title, t.Title, mode); score >= bestScore {
+				best, bestScore = fi, score
+			}
+		}
+		if best >= 0 {
+			used[best] = true
+			matches = append(matches, Match{File: best, Track: ti, By: "title"})
+		}
+	}
+	return matches
+}
+
+// durationFits reports whether a file of length d can be a track of
+// seconds length. Unknown lengths fit only when unknownFits.
+func durationFits(d time.Duration, seconds int, unknownFits bool) bool {
+	if d <= 0 || seconds <= 0 {
+		return unknownFits
+	}
+	return (d - time.Duration(seconds)*time.Second).Abs() <= DurationTolerance
+}
+
+// Import tags the externally downloaded FLAC at path as t — the full tag
+// set, not just what Apply adds to core's downloads — and moves it into
+// outputDir the way a FLACidal download of t would be named and filed,
+// then runs Apply's remaining steps. It never overwrites an existing file:
+// a taken name gets the suffix FileConflict picks, or fails the import.
+// Returns the file's final location.
+func Import(path string, t Track, opts Options, outputDir string) (string, error) {
+	t = t.withTitleLanguage(opts.TitleLanguage).withTagRules(opts.TagRules)
+	if err := writeAllTags(path, t); err != nil {
+		return path, err
+	}
+	destDir := outputDir
+	if opts.OrganizeFolders && opts.FolderTemplate != "" {
+		values := t.Values()
+		if t.Compilation {
+			values.Artist = t.AlbumArtist
+		}
+		for _, part := range strings.Split(strings.Trim(filepath.ToSlash(naming.Render(opts.FolderTemplate, values)), "/"), "/") {
+			if part = naming.SanitizeComponent(part); part != "" && !strings.Contains(part, "{") {
+				destDir = filepath.Join(destDir, part)
+			}
+		}
+	}
+	tmpl := opts.FileNameFormat
+	if tmpl == "" {
+		tmpl = defaultFileNameFormat
+	}
+	values := t.Values()
+	if t.playlistOrdered(opts) {
+		values.Track = t.PlaylistNum
+	}
+	name := naming.SanitizeComponent(naming.Render(tmpl, values))
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	dest := filepath.Join(destDir, name+filepath.Ext(path))
+	if dest != path {
+		free, ok := claim(dest, t, opts)
+		if !ok {
+			return path, fmt.Errorf("not importing to %s: file exists", dest)
+		}
+		dest = free
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			return path, err
+		}
+		var err error
+		if path, err = moveWithSidecar(path, dest); err != nil {
+			return path, err
+		}
+	}
+	return Apply(path, t, opts)
+}
+
+// PreviewImport lists the tag changes Import would make to the FLAC at
+// path for t, without touching it. Tags Import doesn't set are kept, so
+// they never appear.
+func PreviewImport(path string, t Track, opts Options) ([]tagedit.Change, error) {
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := f.Comments()
+	if err != nil {
+		return nil, err
+	}
+	changes := tagedit.DiffValues(c, allTagValues(t.withTitleLanguage(opts.TitleLanguage).withTagRules(opts.TagRules)))
+	if changes == nil {
+		changes = []tagedit.Change{}
+	}
+	return changes, nil
+}
+
+// writeAllTags sets the basic tags from t (TITLE, ARTIST, ALBUM, DATE,
+// ISRC, TRACKNUMBER) along with everything writeTags adds.
+func writeAllTags(path string, t Track) error {
+	return setTags(path, allTagValues(t))
+}
+
+// allTagValues returns the tags writeAllTags sets for t, by name.
+func allTagValues(t Track) map[string][]string {
+	want := map[string][]string{}
+	for name, value := range map[string]string{
+		"TITLE":  t.Title,
+		"ARTIST": t.Artist,
+		"ALBUM":  t.Album,
+		"DATE":   t.Year,
+		"ISRC":   t.ISRC,
+	} {
+		if value != "" {
+			want[name] = []string{value}
+		}
+	}
+	if t.TrackNumber > 0 {
+		want["TRACKNUMBER"] = []string{strconv.Itoa(t.TrackNumber)}
+	}
+	maps.Copy(want, tagValues(t))
+	return want
+}
@@ -0,0 +1,138 @@
+package postprocess
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"flacidal/internal/flacmeta/flactest"
+	"flacidal/internal/textmatch"
+)
+
+func TestReadLocalFiles_SortsByLeadingNumber(t *testing.T) {
+	dir := t.TempDir()
+	flactest.Write(t, filepath.Join(dir, "10 - Ten.flac"), flactest.Seconds(flactest.CD, 100))
+	flactest.Write(t, filepath.Join(dir, "2 - Two.flac"), flactest.Seconds(flactest.CD, 20))
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644)
+
+	files, err := ReadLocalFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || filepath.Base(files[0].Path) != "2 - Two.flac" {
+		t.Fatalf("files = %+v, want 2 before 10", files)
+	}
+	if files[0].Duration != 20*time.Second || files[1].Duration != 100*time.Second {
+		t.Errorf("durations = %v, %v", files[0].Duration, files[1].Duration)
+	}
+}
+
+func TestSortLocalFiles_PrefersTags(t *testing.T) {
+	files := []LocalFile{
+		{Path: "a.flac", Disc: 2, Track: 1},
+		{Path: "b.flac", Disc: 1, Track: 2},
+		{Path: "c.flac", Disc: 1, Track: 1},
+	}
+	sortLocalFiles(files)
+	if files[0].Path != "c.flac" || files[1].Path != "b.flac" || files[2].Path != "a.flac" {
+		t.Errorf("order = %+v", files)
+	}
+}
+
+func TestMatchFiles_ByOrder(t *testing.T) {
+	files := []LocalFile{{Duration: 181 * time.Second}, {}}
+	tracks := []Track{{Duration: 180}, {Duration: 200}}
+	got := MatchFiles(files, tracks, textmatch.Default)
+	if len(got) != 2 || got[1] != (Match{File: 1, Track: 1, By: "order"}) {
+		t.Errorf("matches = %+v, want both by order", got)
+	}
+}
+
+func TestMatchFiles_ByDuration(t *testing.T) {
+	// Out of order, and one track missing from the folder.
+	files := []LocalFile{{Duration: 300 * time.Second}, {Duration: 121 * time.Second}}
+	tracks := []Track{{Duration: 120}, {Duration: 240}, {Duration: 299}}
+	got := MatchFiles(files, tracks, textmatch.Default)
+	want := []Match{{File: 1, Track: 0, By: "duration"}, {File: 0, Track: 2, By: "duration"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("matches = %+v, want %+v", got, want)
+	}
+}
+
+func TestMatchFiles_ByTitle(t *testing.T) {
+	// Lengths unknown or ambiguous: the accent-insensitive title decides,
+	// but never against a length that rules the file out.
+	files := []LocalFile{
+		{Title: "Jóga"},
+		{Title: "Hunter", Duration: 500 * time.Second},
+		{Title: "Hunter (Live)"},
+	}
+	tracks := []Track{{Title: "Hunter", Duration: 240}, {Title: "Joga"}}
+	got := MatchFiles(files, tracks, textmatch.Default)
+	want := []Match{{File: 2, Track: 0, By: "title"}, {File: 0, Track: 1, By: "title"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("matches = %+v, want %+v", got, want)
+	}
+}
+
+func TestTitleFromName(t *testing.T) {
+	for name, want := range map[string]string{"03 - Song.flac": "Song", "Song.flac": "Song", "1. Intro.flac": "Intro"} {
+		if got := titleFromName(name); got != want {
+			t.Errorf("titleFromName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestImport_TagsAndFiles(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "track01.flac")
+	flactest.Write(t, src, flactest.Seconds(flactest.CD, 10))
+	os.WriteFile(filepath.Join(filepath.Dir(src), "track01.lrc"), []byte("[00:01.00]hi"), 0644)
+	out := t.TempDir()
+
+	track := Track{Title: "Song", Artist: "Artist", Album: "Album", Year: "2026", TrackNumber: 1, ISRC: "X"}
+	opts := Options{FileNameFormat: "{track} - {title}", OrganizeFolders: true, FolderTemplate: "{artist}/{album}"}
+	got, err := Import(src, track, opts, out)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if want := filepath.Join(out, "Artist", "Album", "01 - Song.flac"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(out, "Artist", "Album", "01 - Song.lrc")); err != nil {
+		t.Errorf("lyrics sidecar not moved: %v", err)
+	}
+	c := readComments(t, got)
+	if c.Get("TITLE") != "Song" || c.Get("ARTIST") != "Artist" || c.Get("ALBUM") != "Album" ||
+		c.Get("DATE") != "2026" || c.Get("TRACKNUMBER") != "1" || c.Get("ISRC") != "X" {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+
+	// A second copy never overwrites the first.
+	again := filepath.Join(t.TempDir(), "copy.flac")
+	flactest.Write(t, again, flactest.Seconds(flactest.CD, 10))
+	if p, err := Import(again, track, opts, out); err == nil || p != again {
+		t.Errorf("Import over an existing file = %q, %v; want an error and the file left in place", p, err)
+	}
+}
+
+func TestPreviewImport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track01.flac")
+	flactest.Write(t, path, flactest.Seconds(flactest.CD, 10))
+	if err := setTags(path, map[string][]string{"TITLE": {"track01"}, "ALBUM": {"Album"}, "COMMENT": {"ripped"}}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+
+	changes, err := PreviewImport(path, Track{Title: "Song", Album: "Album", TrackNumber: 1}, Options{})
+	if err != nil {
+		t.Fatalf("PreviewImport: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Field != "TITLE" || changes[0].Old[0] != "track01" || changes[0].New[0] != "Song" ||
+		changes[1].Field != "TRACKNUMBER" || changes[1].Old != nil || changes[1].New[0] != "1" {
+		t.Errorf("changes = %+v, want TITLE track01 -> Song and TRACKNUMBER added", changes)
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Error("PreviewImport rewrote the file")
+	}
+}
@@ -0,0 +1,606 @@
+// Package postprocess finishes files after flacidal-core's downloader has
+// written them: tags core does not set and layout options core does not know
+// about. Metadata is captured at queue time (Registry) because the progress
+// callback only carries the download result, not the queued track.
+package postprocess
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"flacidal/internal/coverart"
+	"flacidal/internal/credits"
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/naming"
+	"flacidal/internal/settings"
+	"flacidal/internal/tagedit"
+	"flacidal/internal/tagrules"
+)
+
+// Track is the queue-time metadata kept for one download job. Quality,
+// Source and Downloaded are only known once the download finishes and are
+// filled in then.
+type Track struct {
+	ID            string
+	Title         string
+	Version       string // the source's version of the track, e.g. "Live"; "" when it reports none
+	Artist        string
+	Artists       []string // every contributing artist, when the source lists more than one
+	AlbumArtist   string
+	Album         string
+	Label         string
+	Copyright     string
+	Year          string
+	ISRC          string
+	Duration      int // seconds; matches imported files to tracks (see MatchFiles)
+	TrackNumber   int
+	DiscNumber    int  // 1-based; 0 when the source didn't report one
+	TotalDiscs    int  // discs on the release; 0 when unknown
+	PlaylistIndex int  // 1-based position in the queued batch
+	PlaylistNum   int  // 1-based position in the playlist; 0 outside playlists
+	PlaylistTotal int  // tracks in the playlist; 0 outside playlists
+	Compilation   bool // part of a various-artists compilation (see IsCompilation)
+	Quality       string
+	Source        string    // service that delivered the file, e.g. "tidal"; empty for imports
+	Downloaded    time.Time // when the download finished; zero for imports
+	Overrides     Overrides // user edits, already applied to the fields above
+	Fitted        Overrides // full values of the fields core was given shortened or made safe for its path
+}
+
+// Overrides replace source metadata for one queued track, e.g. to fix a bad
+// title. They take precedence over the API's values in the filename and
+// the tags. Empty fields keep the source value.
+type Overrides struct {
+	Title       string `json:"title,omitempty"`
+	Artist      string `json:"artist,omitempty"`
+	Album       string `json:"album,omitempty"`
+	TrackNumber int    `json:"trackNumber,omitempty"`
+}
+
+// IsZero reports whether o overrides nothing.
+func (o Overrides) IsZero() bool {
+	return o == Overrides{}
+}
+
+// Validate rejects overrides that can't be written as tags.
+func (o Overrides) Validate() error {
+	if o.TrackNumber < 0 {
+		return fmt.Errorf("track number must not be negative")
+	}
+	return nil
+}
+
+// apply returns t with o's non-empty fields in place of the source values.
+func (o Overrides) apply(t Track) Track {
+	if o.Title != "" {
+		t.Title = o.Title
+	}
+	if o.Artist != "" {
+		t.Artist = o.Artist
+	}
+	if o.Album != "" {
+		t.Album = o.Album
+	}
+	if o.TrackNumber > 0 {
+		t.TrackNumber = o.TrackNumber
+	}
+	t.Overrides = o
+	return t
+}
+
+// defaultFileNameFormat is the core downloader's filename template when
+// config leaves it empty.
+const defaultFileNameFormat = "{artist} - {title}"
+
+// Options configure Apply.
+type Options struct {
+	settings.Settings
+
+	// FileNameFormat is the user's filename template. It is only rendered
+	// here when it needs tokens the core downloader can't expand.
+	FileNameFormat string
+
+	// OrganizeFolders and FolderTemplate mirror the core downloader's
+	// folder options, so compilations can be refiled under their album
+	// artist.
+	OrganizeFolders bool
+	FolderTemplate  string
+
+	// Credits looks up the credits of the queued tracks for the
+	// PerformerTags setting; nil skips them.
+	Credits credits.Fetcher
+}
+
+// Registry maps download-manager track IDs to their queue-time metadata
+// until the job finishes. The zero value is ready to use.
+type Registry struct {
+	m         sync.Map // int → Track
+	overrides sync.Map // int → Overrides, kept apart so Remember doesn't drop them
+	fitted    sync.Map // int → Overrides, likewise
+}
+
+// Remember records t for trackID, replacing any earlier entry.
+func (r *Registry) Remember(trackID int, t Track) {
+	r.m.Store(trackID, t)
+}
+
+// SetOverrides records user edits for trackID, replacing earlier ones; zero
+// overrides clear them. They may be set before or after the track is
+// queued, as long as it hasn't finished.
+func (r *Registry) SetOverrides(trackID int, o Overrides) {
+	if o.IsZero() {
+		r.overrides.Delete(trackID)
+		return
+	}
+	r.overrides.Store(trackID, o)
+}
+
+// SetFitted records that core was given fitted values in place of full's
+// non-empty fields for trackID (see Track.Fitted), so Apply writes the full
+// ones back to the tags.
+func (r *Registry) SetFitted(trackID int, full Overrides) {
+	if full.IsZero() {
+		r.fitted.Delete(trackID)
+		return
+	}
+	r.fitted.Store(trackID, full)
+}
+
+// Take returns and removes the metadata for trackID, with any overrides
+// applied. A track with overrides but no queue-time metadata yields a Track
+// holding just the overrides.
+func (r *Registry) Take(trackID int) (Track, bool) {
+	v, ok := r.m.LoadAndDelete(trackID)
+	o, hasOverrides := r.overrides.LoadAndDelete(trackID)
+	if !ok && !hasOverrides {
+		return Track{}, false
+	}
+	var t Track
+	if ok {
+		t = v.(Track)
+	}
+	if hasOverrides {
+		t = o.(Overrides).apply(t)
+	}
+	if f, ok := r.fitted.LoadAndDelete(trackID); ok {
+		t.Fitted = f.(Overrides)
+	}
+	return t, true
+}
+
+// Forget drops the metadata, overrides and fitted values for trackID
+// (failed or cancelled jobs).
+func (r *Registry) Forget(trackID int) {
+	r.m.Delete(trackID)
+	r.overrides.Delete(trackID)
+	r.fitted.Delete(trackID)
+}
+
+// Apply runs the post-download steps on the FLAC file at path and returns
+// its final location, which differs from path when the file was renamed or
+// moved. Files in other containers are only named from the template.
+func Apply(path string, t Track, opts Options) (string, error) {
+	t = t.withTitleLanguage(opts.TitleLanguage).withTagRules(opts.TagRules)
+	if !strings.EqualFold(filepath.Ext(path), ".flac") {
+		// Other containers (e.g. Soulseek MP3 fallbacks) are only named
+		path, err := renameFromTemplate(path, t, opts)
+		if err != nil {
+			return path, err
+		}
+		return finalizeName(path, t, opts)
+	}
+	if err := writeTags(path, t); err != nil {
+		return path, err
+	}
+	if err := remapGenres(path, opts.GenreMap); err != nil {
+		return path, err
+	}
+	if err := shrinkCover(path, opts); err != nil {
+		return path, err
+	}
+	// A rename clash is reported but doesn't stop the remaining steps.
+	path, renameErr := renameFromTemplate(path, t, opts)
+	path, err := moveToCompilationFolder(path, t, opts)
+	if err != nil {
+		return path, err
+	}
+	if opts.DiscSubfolders && t.TotalDiscs > 1 && t.DiscNumber > 0 {
+		if path, err = moveToDiscFolder(path, t, opts); err != nil {
+			return path, err
+		}
+	}
+	// Last, since the steps above can lengthen the path.
+	path, err = finalizeName(path, t, opts)
+	if err != nil {
+		return path, err
+	}
+	return path, renameErr
+}
+
+// withTitleLanguage returns t with its title spelled as l prefers (see
+// naming.TitleLanguage.Pick). The choice is applied as a title override, so
+// the TITLE tag core wrote and the filename follow it; a user's own title
+// override wins.
+func (t Track) withTitleLanguage(l naming.TitleLanguage) Track {
+	if t.Overrides.Title != "" {
+		return t
+	}
+	title := l.Pick(t.Title, t.Version)
+	if title == t.Title {
+		return t
+	}
+	o := t.Overrides
+	o.Title = title
+	return o.apply(t)
+}
+
+// withTagRules returns t with r applied to its title, artists and album
+// (see tagrules.Rules.Apply). Like withTitleLanguage, the changes are
+// applied as overrides, so the tags core wrote and the filename follow
+// them; a user's own overrides win.
+func (t Track) withTagRules(r tagrules.Rules) Track {
+	if r.IsZero() {
+		return t
+	}
+	o := t.Overrides
+	normalize := func(override *string, field, value string) {
+		if v := r.Apply(field, value); *override == "" && v != "" && v != value {
+			*override = v
+		}
+	}
+	normalize(&o.Title, "TITLE", t.Title)
+	normalize(&o.Album, "ALBUM", t.Album)
+	if len(t.Artists) > 1 {
+		// writeTags writes these as the ARTIST tags.
+		artists := make([]string, len(t.Artists))
+		for i, a := range t.Artists {
+			if artists[i] = r.Apply("ARTIST", a); artists[i] == "" {
+				artists[i] = a
+			}
+		}
+		t.Artists = artists
+	} else {
+		normalize(&o.Artist, "ARTIST", t.Artist)
+	}
+	if v := r.Apply("ALBUMARTIST", t.AlbumArtist); v != "" {
+		t.AlbumArtist = v
+	}
+	if o == t.Overrides {
+		return t
+	}
+	return o.apply(t)
+}
+
+// maxPathLength resolves the MaxPathLength setting (see naming.PathLimit).
+func (o Options) maxPathLength() int {
+	return naming.PathLimit(o.MaxPathLength)
+}
+
+// finalizeName renames path to its final form — the FilenameUnicode
+// setting applied, then Windows-safe and length-limited (naming.FitPath) —
+// when that differs, never overwriting another file.
+func finalizeName(path string, t Track, opts Options) (string, error) {
+	name := opts.FilenameUnicode.Apply(filepath.Base(path))
+	dest, err := naming.FitPath(filepath.Join(filepath.Dir(path), name), opts.maxPathLength())
+	if err != nil || dest == path {
+		return path, err
+	}
+	free, ok := claim(dest, t, opts)
+	if !ok {
+		return path, fmt.Errorf("not renaming to %s: file exists", filepath.Base(dest))
+	}
+	return moveWithSidecar(path, free)
+}
+
+// claim returns dest, or when another file has it, the name the
+// FileConflict setting gives t's file instead (see
+// naming.Conflict.Resolve). It reports false when the file should keep
+// its current name.
+func claim(dest string, t Track, opts Options) (string, bool) {
+	free, ok := opts.FileConflict.Resolve(dest, naming.Version(t.Title), t.ID)
+	if !ok {
+		return dest, false
+	}
+	if free == dest {
+		return dest, true
+	}
+	// The suffix can push a long name past the limit again.
+	free, err := naming.FitPath(free, opts.maxPathLength())
+	if err != nil {
+		return dest, false
+	}
+	if _, err := os.Lstat(free); err == nil {
+		return dest, false
+	}
+	return free, true
+}
+
+// Values converts t into template inputs.
+func (t Track) Values() naming.Values {
+	return naming.Values{
+		Title:         t.Title,
+		Artist:        t.Artist,
+		AlbumArtist:   t.AlbumArtist,
+		Album:         t.Album,
+		Year:          t.Year,
+		ISRC:          t.ISRC,
+		Quality:       t.Quality,
+		Source:        t.Source,
+		ID:            t.ID,
+		Track:         t.TrackNumber,
+		Disc:          t.DiscNumber,
+		PlaylistIndex: t.PlaylistIndex,
+		PlaylistNum:   t.PlaylistNum,
+		PlaylistTotal: t.PlaylistTotal,
+	}
+}
+
+// playlistOrdered reports whether t's {track} should render its playlist
+// position (the UsePlaylistOrder setting, for playlist downloads only).
+func (t Track) playlistOrdered(opts Options) bool {
+	return opts.UsePlaylistOrder && t.PlaylistNum > 0
+}
+
+// renameFromTemplate renames path within its directory to the rendered
+// template when the template uses FLACidal-only tokens, or when playlist
+// order or overrides change values core used. An existing file at the
+// target is never overwritten; the download gets the name FileConflict
+// picks, or keeps its original one.
+func renameFromTemplate(path string, t Track, opts Options) (string, error) {
+	tmpl := opts.FileNameFormat
+	ordered := t.playlistOrdered(opts)
+	// Overrides alone only re-render names of tracks with queue-time
+	// metadata; otherwise the other tokens would render empty.
+	overridden := !t.Overrides.IsZero() && t.ID != ""
+	if tmpl == "" && overridden {
+		tmpl = defaultFileNameFormat
+	}
+	if tmpl == "" || !naming.NeedsRender(tmpl) && !ordered && !overridden {
+		return path, nil
+	}
+	values := t.Values()
+	if ordered {
+		values.Track = t.PlaylistNum
+	}
+	name := naming.SanitizeComponent(naming.Render(tmpl, values))
+	if name == "" {
+		return path, nil
+	}
+	dest := filepath.Join(filepath.Dir(path), name+filepath.Ext(path))
+	if dest == path {
+		return path, nil
+	}
+	free, ok := claim(dest, t, opts)
+	if !ok {
+		return path, fmt.Errorf("not renaming to %s: file exists", filepath.Base(dest))
+	}
+	return moveWithSidecar(path, free)
+}
+
+// writeTags sets the tags core doesn't write or gets wrong: DISCNUMBER and
+// TOTALDISCS (plus the DISCTOTAL alias some players read instead),
+// ALBUMARTIST, LABEL and COPYRIGHT, one ARTIST per contributing
+// artist, COMPILATION for compilations, the overridden or fitted TITLE,
+// ARTIST, ALBUM and TRACKNUMBER, and the provenance tags of downloads (see
+// Provenance). The file is only rewritten when a tag changes.
+func writeTags(path string, t Track) error {
+	return setTags(path, tagValues(t))
+}
+
+// tagValues returns the tags writeTags sets for t, by name.
+func tagValues(t Track) map[string][]string {
+	want := map[string][]string{}
+	if t.DiscNumber > 0 {
+		want["DISCNUMBER"] = []string{strconv.Itoa(t.DiscNumber)}
+		if t.TotalDiscs > 0 {
+			want["TOTALDISCS"] = []string{strconv.Itoa(t.TotalDiscs)}
+			want["DISCTOTAL"] = []string{strconv.Itoa(t.TotalDiscs)}
+		}
+	}
+	for name, value := range map[string]string{
+		"ALBUMARTIST": t.AlbumArtist,
+		"LABEL":       t.Label,
+		"COPYRIGHT":   t.Copyright,
+	} {
+		if value != "" {
+			want[name] = []string{value}
+		}
+	}
+	if len(t.Artists) > 1 {
+		want["ARTIST"] = t.Artists // Vorbis comments repeat the key per value
+	}
+	if t.Compilation {
+		want["COMPILATION"] = []string{"1"}
+	}
+	// Core tagged the file with the values it was given fitted
+	if t.Fitted.Title != "" {
+		want["TITLE"] = []string{t.Title}
+	}
+	if t.Fitted.Artist != "" && want["ARTIST"] == nil {
+		want["ARTIST"] = []string{t.Artist}
+	}
+	if t.Fitted.Album != "" {
+		want["ALBUM"] = []string{t.Album}
+	}
+	o := t.Overrides
+	for name, value := range map[string]string{"TITLE": o.Title, "ARTIST": o.Artist, "ALBUM": o.Album} {
+		if value != "" {
+			want[name] = []string{value}
+		}
+	}
+	if o.TrackNumber > 0 {
+		want["TRACKNUMBER"] = []string{strconv.Itoa(o.TrackNumber)}
+	}
+	if t.Source != "" {
+		want[TagSource] = []string{strings.ToLower(t.Source)}
+		if t.ID != "" {
+			want[TagSourceID] = []string{t.ID}
+		}
+		if !t.Downloaded.IsZero() {
+			want[TagDownloadDate] = []string{t.Downloaded.UTC().Format(time.RFC3339)}
+		}
+	}
+	return want
+}
+
+// remapGenres renames the GENRE tags core wrote with m (see
+// tagrules.GenreMap), rewriting the file only when one changes.
+func remapGenres(path string, m tagrules.GenreMap) error {
+	if len(m) == 0 {
+		return nil
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return err
+	}
+	c, err := f.Comments()
+	if err != nil {
+		return err
+	}
+	changes := tagedit.GenreDiff(c, m)
+	if len(changes) == 0 {
+		return nil
+	}
+	tagedit.Apply(c, changes)
+	f.SetComments(c)
+	return f.Save()
+}
+
+// folderCover is where KeepFullCover saves the full-size cover.
+const folderCover = "folder.jpg"
+
+// shrinkCover scales down and recompresses the embedded front cover as the
+// CoverMaxSize and CoverQuality settings ask (see coverart.Shrink), saving
+// the original as folder.jpg first with KeepFullCover. Covers it can't
+// make smaller, or can't decode, are left alone.
+func shrinkCover(path string, opts Options) error {
+	if opts.CoverMaxSize == 0 && opts.CoverQuality == 0 {
+		return nil
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i, b := range f.Blocks {
+		if b.Type != flacmeta.BlockPicture {
+			continue
+		}
+		p, err := flacmeta.ParsePicture(b.Data)
+		if err != nil || p.Type != flacmeta.PictureFrontCover {
+			continue
+		}
+		res, ok, err := coverart.Shrink(p.Data, opts.CoverMaxSize, opts.CoverQuality)
+		if err != nil || !ok {
+			continue
+		}
+		if opts.KeepFullCover {
+			if err := saveFolderCover(filepath.Dir(path), p); err != nil {
+				return err
+			}
+		}
+		p.MIME, p.Width, p.Height, p.Depth, p.Data = "image/jpeg", uint32(res.Width), uint32(res.Height), 24, res.Data
+		f.Blocks[i].Data = p.Marshal()
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return f.Save()
+}
+
+// saveFolderCover writes p to dir's folder.jpg unless the folder has one:
+// the first track of an album leaves it for the rest. Only JPEGs are
+// saved, since the name promises one.
+func saveFolderCover(dir string, p flacmeta.Picture) error {
+	if p.MIME != "image/jpeg" {
+		return nil
+	}
+	dest := filepath.Join(dir, folderCover)
+	if _, err := os.Stat(dest); err == nil {
+		return nil
+	}
+	return os.WriteFile(dest, p.Data, 0644)
+}
+
+// setTags sets each tag in want to its values, rewriting the file only when
+// one of them differs.
+func setTags(path string, want map[string][]string) error {
+	if len(want) == 0 {
+		return nil
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return err
+	}
+	c, err := f.Comments()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for name, values := range want {
+		if !slices.Equal(c.GetAll(name), values) {
+			c.Set(name, values...)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	f.SetComments(c)
+	return f.Save()
+}
+
+// DiscFolderName is the subfolder multi-disc tracks are moved into.
+func DiscFolderName(disc int) string {
+	return fmt.Sprintf("Disc %d", disc)
+}
+
+// moveToDiscFolder moves path (and its .lrc sidecar, if any) into a
+// "Disc N" subfolder of its directory, never overwriting another file.
+// Folder-level files such as cover.jpg stay with the album.
+func moveToDiscFolder(path string, t Track, opts Options) (string, error) {
+	dir := filepath.Dir(path)
+	disc := t.DiscNumber
+	if filepath.Base(dir) == DiscFolderName(disc) {
+		return path, nil
+	}
+	discDir := filepath.Join(dir, DiscFolderName(disc))
+	if err := os.MkdirAll(discDir, 0755); err != nil {
+		return path, err
+	}
+	dest, ok := claim(filepath.Join(discDir, filepath.Base(path)), t, opts)
+	if !ok {
+		return path, fmt.Errorf("not moving to %s: file exists", dest)
+	}
+	return moveWithSidecar(path, dest)
+}
+
+// Move renames path to dest like the post-download steps do, taking a
+// same-named .lrc sidecar along and never overwriting an existing file.
+// Returns the file's location afterwards.
+func Move(path, dest string) (string, error) {
+	if _, err := os.Stat(dest); err == nil {
+		return path, fmt.Errorf("not moving to %s: file exists", dest)
+	}
+	return moveWithSidecar(path, dest)
+}
+
+// moveWithSidecar renames path to dest, taking a same-named .lrc lyrics
+// sidecar along.
+func moveWithSidecar(path, dest string) (string, error) {
+	if err := os.Rename(path, dest); err != nil {
+		return path, err
+	}
+	lrc := strings.TrimSuffix(path, filepath.Ext(path)) + ".lrc"
+	if _, err := os.Stat(lrc); err == nil {
+		os.Rename(lrc, strings.TrimSuffix(dest, filepath.Ext(dest))+".lrc") //nolint:errcheck // sidecar is best-effort
+	}
+	return dest, nil
+}
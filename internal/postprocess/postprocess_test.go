@@ -0,0 +1,535 @@
+package postprocess
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/naming"
+	"flacidal/internal/settings"
+	"flacidal/internal/tagrules"
+)
+
+// writeBareFLAC writes a FLAC file holding only a STREAMINFO block and a few
+// bytes of "audio" — enough for flacmeta to parse and rewrite.
+func writeBareFLAC(t *testing.T, path string) {
+	t.Helper()
+	data := append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...)
+	data = append(data, "audio"...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readComments(t *testing.T, path string) *flacmeta.Comments {
+	t.Helper()
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	c, err := f.Comments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestApply_WritesDiscTags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "01 - Song.flac")
+	writeBareFLAC(t, path)
+
+	got, err := Apply(path, Track{DiscNumber: 2, TotalDiscs: 3}, Options{})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got != path {
+		t.Errorf("path = %q, want unchanged %q", got, path)
+	}
+	c := readComments(t, path)
+	if c.Get("DISCNUMBER") != "2" || c.Get("TOTALDISCS") != "3" || c.Get("DISCTOTAL") != "3" {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+}
+
+func TestApply_WritesExtendedTags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "01 - Song.flac")
+	writeBareFLAC(t, path)
+
+	track := Track{
+		Artist:      "A",
+		Artists:     []string{"A", "B"},
+		AlbumArtist: "A",
+		Label:       "L",
+		Copyright:   "℗ 2026 L",
+	}
+	if _, err := Apply(path, track, Options{}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	c := readComments(t, path)
+	if c.Get("ALBUMARTIST") != "A" || c.Get("COMPOSER") != "" || c.Get("LABEL") != "L" || c.Get("COPYRIGHT") != "℗ 2026 L" {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+	if got := c.GetAll("ARTIST"); len(got) != 2 || got[0] != "A" || got[1] != "B" {
+		t.Errorf("ARTIST = %q, want one value per artist", got)
+	}
+
+	// An artist override replaces the list.
+	track.Overrides = Overrides{Artist: "D"}
+	if _, err := Apply(path, track, Options{}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := readComments(t, path).GetAll("ARTIST"); len(got) != 1 || got[0] != "D" {
+		t.Errorf("ARTIST = %q, want the override alone", got)
+	}
+}
+
+func TestApply_DiscSubfolders(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "01 - Song.flac")
+	writeBareFLAC(t, path)
+	os.WriteFile(filepath.Join(dir, "01 - Song.lrc"), []byte("[00:01.00]hi"), 0644)
+
+	got, err := Apply(path, Track{DiscNumber: 2, TotalDiscs: 2}, Options{Settings: settings.Settings{DiscSubfolders: true}})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	want := filepath.Join(dir, "Disc 2", "01 - Song.flac")
+	if got != want {
+		t.Fatalf("path = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("moved file missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Disc 2", "01 - Song.lrc")); err != nil {
+		t.Errorf("lyrics sidecar not moved: %v", err)
+	}
+}
+
+func TestApply_SingleDiscStaysFlat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Song.flac")
+	writeBareFLAC(t, path)
+
+	got, err := Apply(path, Track{DiscNumber: 1, TotalDiscs: 1}, Options{Settings: settings.Settings{DiscSubfolders: true}})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got != path {
+		t.Errorf("single-disc track moved to %q", got)
+	}
+}
+
+func TestApply_NamesNonFLAC(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Song.mp3")
+	os.WriteFile(path, []byte("ID3"), 0644)
+	got, err := Apply(path, Track{ID: "1", Title: "Song", TrackNumber: 4, DiscNumber: 1, TotalDiscs: 2}, Options{FileNameFormat: "{track:2} - {title}", Settings: settings.Settings{DiscSubfolders: true}})
+	if want := filepath.Join(dir, "04 - Song.mp3"); err != nil || got != want {
+		t.Errorf("Apply on mp3 = %q, %v, want it renamed, untagged, to %q", got, err, want)
+	}
+	if b, _ := os.ReadFile(got); string(b) != "ID3" {
+		t.Errorf("mp3 content changed to %q", b)
+	}
+}
+
+func TestRegistry_TakeRemoves(t *testing.T) {
+	var r Registry
+	r.Remember(7, Track{DiscNumber: 1})
+	if got, ok := r.Take(7); !ok || got.DiscNumber != 1 {
+		t.Fatalf("Take = %+v, %v", got, ok)
+	}
+	if _, ok := r.Take(7); ok {
+		t.Error("second Take should miss")
+	}
+}
+
+func TestRegistry_OverridesSurviveRemember(t *testing.T) {
+	var r Registry
+	r.SetOverrides(7, Overrides{Title: "Fixed", TrackNumber: 4})
+	r.Remember(7, Track{ID: "7", Title: "Bad", Artist: "A", TrackNumber: 1})
+
+	got, ok := r.Take(7)
+	if !ok || got.Title != "Fixed" || got.Artist != "A" || got.TrackNumber != 4 {
+		t.Fatalf("Take = %+v, %v", got, ok)
+	}
+	if _, ok := r.Take(7); ok {
+		t.Error("overrides should be consumed by Take")
+	}
+
+	r.SetOverrides(8, Overrides{Album: "X"})
+	r.SetOverrides(8, Overrides{})
+	if _, ok := r.Take(8); ok {
+		t.Error("zero overrides should clear earlier ones")
+	}
+}
+
+func TestApply_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Artist - Bad Title.flac")
+	writeBareFLAC(t, path)
+
+	var r Registry
+	r.Remember(1, Track{ID: "1", Title: "Bad Title", Artist: "Artist", TrackNumber: 2})
+	r.SetOverrides(1, Overrides{Title: "Good Title", Album: "Album", TrackNumber: 3})
+	track, _ := r.Take(1)
+
+	got, err := Apply(path, track, Options{FileNameFormat: "{track} {artist} - {title}"})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := filepath.Join(dir, "03 Artist - Good Title.flac"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	c := readComments(t, got)
+	if c.Get("TITLE") != "Good Title" || c.Get("ALBUM") != "Album" || c.Get("TRACKNUMBER") != "3" || c.Get("ARTIST") != "" {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+}
+
+func TestApply_FittedTitleWrittenBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Artist - Long.flac")
+	writeBareFLAC(t, path)
+
+	var r Registry
+	r.SetFitted(1, Overrides{Title: "Long Title"})
+	r.Remember(1, Track{ID: "1", Title: "Long Title", Artist: "Artist"})
+	track, _ := r.Take(1)
+
+	got, err := Apply(path, track, Options{})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got != path {
+		t.Errorf("renamed to %q; core's fitted name should stay", got)
+	}
+	if c := readComments(t, path); c.Get("TITLE") != "Long Title" || c.Get("ARTIST") != "" {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+}
+
+func TestApply_OverridesWithoutMetadataOnlyRetag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Artist - Song.flac")
+	writeBareFLAC(t, path)
+
+	got, err := Apply(path, Overrides{Title: "New"}.apply(Track{}), Options{})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got != path {
+		t.Errorf("renamed to %q without queue-time metadata", got)
+	}
+	if c := readComments(t, path); c.Get("TITLE") != "New" {
+		t.Errorf("TITLE = %q, want New", c.Get("TITLE"))
+	}
+}
+
+func TestOverrides_Validate(t *testing.T) {
+	if err := (Overrides{TrackNumber: -1}).Validate(); err == nil {
+		t.Error("negative track number should be rejected")
+	}
+}
+
+func TestApply_RendersExtendedTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Artist - Song.flac")
+	writeBareFLAC(t, path)
+	os.WriteFile(filepath.Join(dir, "Artist - Song.lrc"), []byte("[00:01.00]hi"), 0644)
+
+	track := Track{Title: "Song", Artist: "Artist", TrackNumber: 3, Quality: "HI_RES", ID: "99"}
+	got, err := Apply(path, track, Options{FileNameFormat: "{track:3} - {title} [{quality}]"})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	want := filepath.Join(dir, "003 - Song [HI_RES].flac")
+	if got != want {
+		t.Fatalf("path = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "003 - Song [HI_RES].lrc")); err != nil {
+		t.Errorf("lyrics sidecar not renamed: %v", err)
+	}
+}
+
+func TestApply_CoreTemplateNotRenamed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Artist - Song.flac")
+	writeBareFLAC(t, path)
+
+	got, err := Apply(path, Track{Title: "Other"}, Options{FileNameFormat: "{title}"})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got != path {
+		t.Errorf("core-expandable template was re-rendered: %q", got)
+	}
+}
+
+func TestApply_RenameNeverOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.flac")
+	writeBareFLAC(t, path)
+	existing := filepath.Join(dir, "1 - x.flac")
+	os.WriteFile(existing, []byte("keep"), 0644)
+
+	got, err := Apply(path, Track{Title: "x", PlaylistIndex: 1}, Options{FileNameFormat: "{playlistindex} - {title}"})
+	if err == nil {
+		t.Error("expected an error for the name clash")
+	}
+	if got != path {
+		t.Errorf("path = %q, want original %q", got, path)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep" {
+		t.Error("existing file was overwritten")
+	}
+}
+
+func TestApply_RenameConflictSuffix(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "1 - Hits.flac")
+	os.WriteFile(existing, []byte("keep"), 0644)
+
+	// The template leaves the title out, so every track of "Hits" at
+	// position 1 renders to the existing file's name.
+	tests := []struct {
+		conflict naming.Conflict
+		track    Track
+		want     string
+	}{
+		{naming.ConflictVersion, Track{Title: "Song (Remix)"}, "1 - Hits (Remix).flac"},
+		{naming.ConflictVersion, Track{Title: "Song"}, "1 - Hits (2).flac"},
+		{naming.ConflictID, Track{Title: "Song", ID: "42"}, "1 - Hits [42].flac"},
+		{naming.ConflictCounter, Track{Title: "Song", ID: "43"}, "1 - Hits (3).flac"},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, fmt.Sprintf("download%d.flac", i))
+		writeBareFLAC(t, path)
+		tt.track.Album, tt.track.PlaylistIndex = "Hits", 1
+		got, err := Apply(path, tt.track, Options{
+			Settings:       settings.Settings{FileConflict: tt.conflict},
+			FileNameFormat: "{playlistindex} - {album}",
+		})
+		if err != nil {
+			t.Fatalf("%s: Apply: %v", tt.conflict, err)
+		}
+		if want := filepath.Join(dir, tt.want); got != want {
+			t.Errorf("%s: path = %q, want %q", tt.conflict, got, want)
+		}
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep" {
+		t.Error("existing file was overwritten")
+	}
+}
+
+func TestApply_PlaylistOrder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "08 - Song.flac")
+	writeBareFLAC(t, path)
+
+	track := Track{Title: "Song", TrackNumber: 8, PlaylistIndex: 2, PlaylistNum: 2, PlaylistTotal: 20}
+	opts := Options{FileNameFormat: "{track} - {title}", Settings: settings.Settings{UsePlaylistOrder: true}}
+	got, err := Apply(path, track, opts)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := filepath.Join(dir, "02 - Song.flac"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+}
+
+func TestApply_PlaylistOrderIgnoresAlbums(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "08 - Song.flac")
+	writeBareFLAC(t, path)
+
+	track := Track{Title: "Song", TrackNumber: 8, PlaylistIndex: 2}
+	opts := Options{FileNameFormat: "{track} - {title}", Settings: settings.Settings{UsePlaylistOrder: true}}
+	got, err := Apply(path, track, opts)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got != path {
+		t.Errorf("album track renamed to %q", got)
+	}
+}
+
+func TestApply_ShortensLongPaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, strings.Repeat("x", 120)+".flac")
+	writeBareFLAC(t, path)
+	os.WriteFile(strings.TrimSuffix(path, ".flac")+".lrc", []byte("[00:01.00]hi"), 0644)
+
+	max := len(dir) + 40
+	got, err := Apply(path, Track{}, Options{Settings: settings.Settings{MaxPathLength: max}})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(got) != max || filepath.Ext(got) != ".flac" {
+		t.Fatalf("path = %q (%d), want %d chars ending in .flac", got, len(got), max)
+	}
+	if _, err := os.Stat(strings.TrimSuffix(got, ".flac") + ".lrc"); err != nil {
+		t.Errorf("lyrics sidecar not renamed: %v", err)
+	}
+}
+
+func TestApply_ReservedName(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CON.flac")
+	writeBareFLAC(t, path)
+
+	got, err := Apply(path, Track{}, Options{})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := filepath.Join(dir, "CON_.flac"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+}
+
+func TestApply_TransliteratesName(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Björk - Jóga.flac")
+	writeBareFLAC(t, path)
+
+	got, err := Apply(path, Track{}, Options{Settings: settings.Settings{FilenameUnicode: naming.UnicodeASCII}})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := filepath.Join(dir, "Bjork - Joga.flac"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+}
+
+func TestApply_TitleLanguage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "YOASOBI - 夜に駆ける (Yoru ni Kakeru).flac")
+	writeBareFLAC(t, path)
+	track := Track{ID: "1", Title: "夜に駆ける (Yoru ni Kakeru)", Artist: "YOASOBI"}
+
+	got, err := Apply(path, track, Options{Settings: settings.Settings{TitleLanguage: naming.TitleOriginal}})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := filepath.Join(dir, "YOASOBI - 夜に駆ける.flac"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	if c := readComments(t, got); c.Get("TITLE") != "夜に駆ける" {
+		t.Errorf("TITLE = %q", c.Get("TITLE"))
+	}
+
+	// A user's title override wins over the preference.
+	track.Overrides = Overrides{Title: "Racing into the Night"}
+	if track.withTitleLanguage(naming.TitleLocalized).Title != track.Title {
+		t.Error("preference replaced an overridden title")
+	}
+}
+
+func TestApply_ShrinksCover(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Artist - Song.flac")
+	writeBareFLAC(t, path)
+	img := image.NewRGBA(image.Rect(0, 0, 1200, 1000))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i * 7 % 251)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	full := buf.Bytes()
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetPicture(flacmeta.Picture{Type: flacmeta.PictureFrontCover, MIME: "image/jpeg", Description: "cover", Width: 1200, Height: 1000, Depth: 24, Data: full})
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{Settings: settings.Settings{CoverMaxSize: 600, KeepFullCover: true}}
+	if _, err := Apply(path, Track{ID: "1", Title: "Song", Artist: "Artist"}, opts); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if f, err = flacmeta.Read(path); err != nil {
+		t.Fatal(err)
+	}
+	pics, err := f.Pictures()
+	if err != nil || len(pics) != 1 {
+		t.Fatalf("pictures = %d, %v", len(pics), err)
+	}
+	if p := pics[0]; p.Width != 600 || p.Height != 500 || p.Description != "cover" || len(p.Data) >= len(full) {
+		t.Errorf("cover = %dx%d %q, %d bytes (was %d)", p.Width, p.Height, p.Description, len(p.Data), len(full))
+	}
+	saved, err := os.ReadFile(filepath.Join(dir, "folder.jpg"))
+	if err != nil || !bytes.Equal(saved, full) {
+		t.Errorf("folder.jpg not the full-size cover: %v", err)
+	}
+}
+
+func TestApply_TagRules(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Artist - song.flac")
+	writeBareFLAC(t, path)
+
+	track := Track{Title: "song (2011 Remaster)", Artist: "Artist ft. Guest", Album: "Album", TrackNumber: 1, ID: "1"}
+	opts := Options{
+		Settings:       settings.Settings{TagRules: tagrules.Rules{TitleCase: true, Featuring: true, StripRemaster: true}},
+		FileNameFormat: "{artist} - {title}",
+	}
+	got, err := Apply(path, track, opts)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := filepath.Join(dir, "Artist feat. Guest - Song.flac"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	c := readComments(t, got)
+	if c.Get("TITLE") != "Song" || c.Get("ARTIST") != "Artist feat. Guest" {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+}
+
+func TestApply_GenreMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.flac")
+	writeBareFLAC(t, path)
+	if err := setTags(path, map[string][]string{"GENRE": {"Hip-Hop/Rap", "Jazz"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{Settings: settings.Settings{GenreMap: tagrules.GenreMap{"Hip-Hop/Rap": "Hip Hop"}}}
+	if _, err := Apply(path, Track{ID: "1"}, opts); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := readComments(t, path).GetAll("GENRE"); !slices.Equal(got, []string{"Hip Hop", "Jazz"}) {
+		t.Errorf("GENRE = %q, want [Hip Hop Jazz]", got)
+	}
+}
+
+func TestApply_WritesProvenance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.flac")
+	writeBareFLAC(t, path)
+
+	downloaded := time.Date(2026, 3, 14, 15, 9, 26, 0, time.FixedZone("CET", 3600))
+	track := Track{ID: "12345", Source: "Qobuz", Downloaded: downloaded}
+	if _, err := Apply(path, track, Options{}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	got := ReadProvenance(readComments(t, path))
+	want := Provenance{Source: "qobuz", SourceID: "12345", DownloadDate: "2026-03-14T14:09:26Z"}
+	if got != want {
+		t.Errorf("provenance = %+v, want %+v", got, want)
+	}
+
+	// Imports (no source) get none.
+	other := filepath.Join(t.TempDir(), "import.flac")
+	writeBareFLAC(t, other)
+	if _, err := Apply(other, Track{ID: "1"}, Options{}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := ReadProvenance(readComments(t, other)); got != (Provenance{}) {
+		t.Errorf("import provenance = %+v, want none", got)
+	}
+}
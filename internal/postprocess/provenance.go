@@ -0,0 +1,29 @@
+package postprocess
+
+import "flacidal/internal/flacmeta"
+
+// The provenance tags record where and when a download came from, so a
+// library can be audited, filtered by provider and re-fetched from the
+// right place later.
+const (
+	TagSource       = "SOURCE"       // the delivering service, lower case: "tidal", "qobuz"…
+	TagSourceID     = "SOURCEID"     // the ID the track was queued by
+	TagDownloadDate = "DOWNLOADDATE" // when the download finished, RFC 3339 in UTC
+)
+
+// Provenance is a file's provenance tags. Files FLACidal didn't download
+// have none.
+type Provenance struct {
+	Source       string `json:"source,omitempty"`
+	SourceID     string `json:"sourceId,omitempty"`
+	DownloadDate string `json:"downloadDate,omitempty"`
+}
+
+// ReadProvenance returns the provenance tags in c.
+func ReadProvenance(c *flacmeta.Comments) Provenance {
+	return Provenance{
+		Source:       c.Get(TagSource),
+		SourceID:     c.Get(TagSourceID),
+		DownloadDate: c.Get(TagDownloadDate),
+	}
+}
@@ -0,0 +1,147 @@
+// Package quality defines the download quality levels FLACidal understands
+// and how they compare. Config and the core downloader still carry quality
+// as a plain string; this package is the one place those strings are parsed,
+// validated and ranked, so Tidal, Qobuz and config spellings ("CD",
+// "HI_RES_LOSSLESS", "27"...) all mean the same thing.
+package quality
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Quality is a canonical quality level. The zero value is invalid.
+type Quality string
+
+const (
+	High     Quality = "HIGH"     // lossy, ~320 kbps AAC/MP3
+	Lossless Quality = "LOSSLESS" // 16-bit / 44.1 kHz FLAC (CD quality)
+	HiRes    Quality = "HI_RES"   // 24-bit FLAC, 48 kHz and up
+)
+
+// Default is used when config leaves the quality empty.
+const Default = Lossless
+
+// DefaultOrder is the fallback ladder used when config has none: best first.
+var DefaultOrder = []Quality{HiRes, Lossless, High}
+
+// rank orders the levels; higher is better.
+var rank = map[Quality]int{High: 1, Lossless: 2, HiRes: 3}
+
+// aliases maps accepted spellings (upper-cased, '-' and ' ' as '_') to a
+// canonical level. Qobuz format IDs are included because they leak into
+// results from that source.
+var aliases = map[string]Quality{
+	"HIGH": High, "MP3": High, "AAC": High, "320": High, "5": High,
+	"LOSSLESS": Lossless, "CD": Lossless, "FLAC": Lossless, "16": Lossless, "6": Lossless,
+	"HI_RES": HiRes, "HIRES": HiRes, "HI_RES_LOSSLESS": HiRes, "MAX": HiRes, "24": HiRes, "7": HiRes, "27": HiRes,
+}
+
+// Parse returns the canonical Quality for s, accepting the aliases above
+// case-insensitively.
+func Parse(s string) (Quality, error) {
+	key := strings.ToUpper(strings.TrimSpace(s))
+	key = strings.NewReplacer("-", "_", " ", "_").Replace(key)
+	if q, ok := aliases[key]; ok {
+		return q, nil
+	}
+	return "", fmt.Errorf("unknown quality %q", s)
+}
+
+// ParseOrDefault parses s, returning Default for an empty string.
+func ParseOrDefault(s string) (Quality, error) {
+	if strings.TrimSpace(s) == "" {
+		return Default, nil
+	}
+	return Parse(s)
+}
+
+// ParseOrder parses a fallback ladder, rejecting unknown and duplicate
+// levels. An empty order yields DefaultOrder.
+func ParseOrder(order []string) ([]Quality, error) {
+	if len(order) == 0 {
+		return append([]Quality(nil), DefaultOrder...), nil
+	}
+	seen := map[Quality]bool{}
+	out := make([]Quality, 0, len(order))
+	for _, s := range order {
+		q, err := Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		if seen[q] {
+			return nil, fmt.Errorf("duplicate quality %q in order", s)
+		}
+		seen[q] = true
+		out = append(out, q)
+	}
+	return out, nil
+}
+
+// Strings converts qs back to the plain strings config and core use.
+func Strings(qs []Quality) []string {
+	out := make([]string, len(qs))
+	for i, q := range qs {
+		out[i] = string(q)
+	}
+	return out
+}
+
+// Tier returns the lossless level of a FLAC with the given bit depth and
+// sample rate: HiRes beyond CD's 16 bits or 48 kHz, Lossless otherwise. It
+// is "" when either is unknown (0).
+func Tier(bitDepth, sampleRate int) Quality {
+	switch {
+	case bitDepth <= 0 || sampleRate <= 0:
+		return ""
+	case bitDepth > 16 || sampleRate > 48000:
+		return HiRes
+	}
+	return Lossless
+}
+
+// Valid reports whether q is one of the canonical levels.
+func (q Quality) Valid() bool {
+	return rank[q] > 0
+}
+
+// Compare returns -1, 0 or +1 as q is worse than, equal to or better than
+// other. Invalid values rank below every valid one.
+func (q Quality) Compare(other Quality) int {
+	a, b := rank[q], rank[other]
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// IsLossless reports whether q is a lossless level.
+func (q Quality) IsLossless() bool {
+	return q == Lossless || q == HiRes
+}
+
+// String returns the canonical spelling.
+func (q Quality) String() string {
+	return string(q)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (q Quality) MarshalText() ([]byte, error) {
+	if !q.Valid() {
+		return nil, fmt.Errorf("invalid quality %q", string(q))
+	}
+	return []byte(q), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting any alias.
+func (q *Quality) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
@@ -0,0 +1,99 @@
+package quality
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]Quality{
+		"LOSSLESS":        Lossless,
+		"lossless":        Lossless,
+		"CD":              Lossless,
+		"HI_RES":          HiRes,
+		"hi-res":          HiRes,
+		"HI_RES_LOSSLESS": HiRes,
+		"27":              HiRes,
+		" high ":          High,
+		"mp3":             High,
+	}
+	for in, want := range tests {
+		got, err := Parse(in)
+		if err != nil || got != want {
+			t.Errorf("Parse(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "ULTRA", "HI_RES_PLUS"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseOrDefault(t *testing.T) {
+	if q, err := ParseOrDefault(""); err != nil || q != Default {
+		t.Errorf("ParseOrDefault(\"\") = %q, %v", q, err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	if HiRes.Compare(Lossless) != 1 || Lossless.Compare(HiRes) != -1 || High.Compare(High) != 0 {
+		t.Error("unexpected ordering")
+	}
+	if Quality("bogus").Compare(High) != -1 {
+		t.Error("invalid quality should rank below valid ones")
+	}
+	if High.IsLossless() || !Lossless.IsLossless() || !HiRes.IsLossless() {
+		t.Error("IsLossless mismatch")
+	}
+}
+
+func TestTier(t *testing.T) {
+	for _, tc := range []struct {
+		bits, rate int
+		want       Quality
+	}{
+		{16, 44100, Lossless},
+		{16, 48000, Lossless},
+		{24, 44100, HiRes},
+		{16, 96000, HiRes},
+		{24, 192000, HiRes},
+		{0, 44100, ""},
+	} {
+		if got := Tier(tc.bits, tc.rate); got != tc.want {
+			t.Errorf("Tier(%d, %d) = %q, want %q", tc.bits, tc.rate, got, tc.want)
+		}
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	got, err := ParseOrder([]string{"cd", "hi-res"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"LOSSLESS", "HI_RES"}; Strings(got)[0] != want[0] || Strings(got)[1] != want[1] {
+		t.Errorf("ParseOrder = %v, want %v", got, want)
+	}
+	if _, err := ParseOrder([]string{"LOSSLESS", "CD"}); err == nil {
+		t.Error("duplicate (via alias) should be rejected")
+	}
+	if def, _ := ParseOrder(nil); len(def) != len(DefaultOrder) {
+		t.Errorf("empty order = %v, want default", def)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Q Quality `json:"q"`
+	}
+	if err := json.Unmarshal([]byte(`{"q":"hi_res_lossless"}`), &v); err != nil || v.Q != HiRes {
+		t.Fatalf("unmarshal = %q, %v", v.Q, err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil || string(out) != `{"q":"HI_RES"}` {
+		t.Errorf("marshal = %s, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"q":"nope"}`), &v); err == nil {
+		t.Error("unknown quality should fail to unmarshal")
+	}
+}
@@ -0,0 +1,354 @@
+// Package settings holds FLACidal options that live outside flacidal-core's
+// Config — behaviour implemented in this repository rather than in the core
+// library. They are stored as settings.json in the app data directory, next
+// to core's config.json, and shared by the desktop app and the HTTP server.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"flacidal/internal/analysis"
+	"flacidal/internal/configdiff"
+	"flacidal/internal/coverproxy"
+	"flacidal/internal/downloads"
+	"flacidal/internal/lyricsfile"
+	"flacidal/internal/naming"
+	"flacidal/internal/tagrules"
+	"flacidal/internal/textmatch"
+)
+
+// FileName is the settings file name inside the data directory.
+const FileName = "settings.json"
+
+// Settings are the app-local options. The zero value turns every option
+// off; a fresh install, and a missing or partial file, gets Defaults.
+type Settings struct {
+	// DiscSubfolders moves tracks of multi-disc albums into "Disc N"
+	// subfolders of the album folder once they finish downloading.
+	DiscSubfolders bool `json:"discSubfolders"`
+
+	// UsePlaylistOrder numbers playlist downloads by playlist position:
+	// {track} in the filename template renders the position instead of the
+	// album track number. Albums are unaffected.
+	UsePlaylistOrder bool `json:"usePlaylistOrder"`
+
+	// MaxPathLength caps the length of downloaded file paths; longer names
+	// are truncated, keeping their extension. 0 means the system's limit:
+	// MAX_PATH on Windows, none elsewhere (see naming.PathLimit).
+	MaxPathLength int `json:"maxPathLength"`
+
+	// FilenameUnicode controls non-ASCII characters in the names FLACidal
+	// writes: "" keeps them, "nfc" normalizes to composed form and "ascii"
+	// transliterates ("Björk" → "Bjork").
+	FilenameUnicode naming.UnicodeMode `json:"filenameUnicode"`
+
+	// TitleLanguage picks one spelling of titles that sources return in two
+	// scripts at once, for both the TITLE tag and the filename: ""
+	// keeps both, "original" the non-Latin one and "localized" the Latin
+	// one (see naming.TitleLanguage.Pick).
+	TitleLanguage naming.TitleLanguage `json:"titleLanguage"`
+
+	// TagRules normalizes the title, artist and album tags of downloads
+	// and imports: title case, one spelling of "feat.", no remaster
+	// suffixes or explicit markers, each on its own toggle (see
+	// internal/tagrules).
+	TagRules tagrules.Rules `json:"tagRules"`
+
+	// GenreMap renames the genres of downloads and imports, e.g.
+	// {"Hip-Hop/Rap": "Hip Hop"}, so every source's spelling ends up as
+	// one; a genre mapped to "" is removed (see tagrules.GenreMap).
+	GenreMap tagrules.GenreMap `json:"genreMap"`
+
+	// FileConflict decides what happens when a track's name is taken by
+	// another file, e.g. a remix whose version the template drops: ""
+	// keeps the track under the name it downloaded as, "counter" appends
+	// " (2)", "version" the title's version and "id" the track ID (see
+	// naming.Conflict.Resolve).
+	FileConflict naming.Conflict `json:"fileConflict"`
+
+	// CoverMaxSize scales embedded front covers down so neither side
+	// exceeds this many pixels (see internal/coverart). 0 keeps the size.
+	CoverMaxSize int `json:"coverMaxSize"`
+
+	// CoverQuality is the JPEG quality (1–100) embedded covers are
+	// re-encoded at when CoverMaxSize or it is set. 0 means 90 when
+	// resizing and leaves covers alone otherwise.
+	CoverQuality int `json:"coverQuality"`
+
+	// KeepFullCover saves the full-size cover as folder.jpg next to the
+	// track, unless one exists, before the embedded copy is shrunk.
+	KeepFullCover bool `json:"keepFullCover"`
+
+	// MusicBrainzTagging looks completed downloads up on MusicBrainz and
+	// adds their MUSICBRAINZ_* ID tags, filling in a missing year, genre
+	// or label (see internal/musicbrainz).
+	MusicBrainzTagging bool `json:"musicBrainzTagging"`
+
+	// PerformerTags looks completed downloads' credits up at their service
+	// and writes a PERFORMER tag for each credited producer, engineer and
+	// musician (see internal/credits).
+	PerformerTags bool `json:"performerTags"`
+
+	// AcoustIDKey is the AcoustID application API key used to identify
+	// files by their audio fingerprint (see internal/acoustid). Empty
+	// disables identification; keys are free at acoustid.org.
+	AcoustIDKey string `json:"acoustIdKey"`
+
+	// WatchClipboard makes the desktop app offer to download supported
+	// music URLs as they are copied to the clipboard. The HTTP server has
+	// no clipboard and ignores it.
+	WatchClipboard bool `json:"watchClipboard"`
+
+	// WatchFolder is a folder polled for dropped .txt/.m3u URL lists (or
+	// .csv/.json exports of other downloaders), which
+	// are queued into the download folder and moved to its "processed"
+	// subfolder. Empty disables it.
+	WatchFolder string `json:"watchFolder"`
+
+	// WatchLibrary rescans the download folder and external library paths
+	// every half minute (see library.Index.Watch), so files other programs
+	// put there are indexed without a manual scan.
+	WatchLibrary bool `json:"watchLibrary"`
+
+	// AnalyzeNewFiles runs the quality analyzer on each file WatchLibrary
+	// indexes, logging the files that look upscaled from lossy sources.
+	AnalyzeNewFiles bool `json:"analyzeNewFiles"`
+
+	// AnalysisWorkers caps how many files the quality analyzer analyzes at
+	// once. 0 means one per CPU core.
+	AnalysisWorkers int `json:"analysisWorkers"`
+
+	// ConversionWorkers is how many ffmpeg processes the converter runs at
+	// once. 0 means one per two CPU cores.
+	ConversionWorkers int `json:"conversionWorkers"`
+
+	// LoudnessTags writes the loudness the analyzer measures to each
+	// analyzed file as ReplayGain tags (REPLAYGAIN_TRACK_GAIN and
+	// REPLAYGAIN_TRACK_PEAK, see internal/analysis), for players that level
+	// tracks by them.
+	LoudnessTags bool `json:"loudnessTags"`
+
+	// AnalyzerThresholds are the spectral cutoffs and confidences the
+	// quality analyzer judges files by (see analysis.Judge); fields left
+	// at 0 keep their defaults (analysis.DefaultThresholds). Lower cutoffs
+	// flag fewer files.
+	AnalyzerThresholds analysis.Thresholds `json:"analyzerThresholds"`
+
+	// StartOnLogin registers the desktop app to start when the user logs
+	// in (see internal/autostart). The HTTP server ignores it; run it as a
+	// service instead.
+	StartOnLogin bool `json:"startOnLogin"`
+
+	// TrimSilence trims leading and trailing silence from finished
+	// downloads (see internal/silence). Needs FFmpeg.
+	TrimSilence bool `json:"trimSilence"`
+
+	// SilenceThreshold is the level, in dBFS, below which audio counts as
+	// silence. 0 means the default, -60.
+	SilenceThreshold int `json:"silenceThreshold"`
+
+	// SilenceMinSeconds is how long silence must last at the start or end
+	// of a track to be trimmed. 0 means the default, 2 seconds.
+	SilenceMinSeconds float64 `json:"silenceMinSeconds"`
+
+	// IncompleteCleanupDays deletes partial files and zero-byte FLACs left
+	// in the library folders by interrupted downloads once they are this
+	// many days old (see internal/incomplete). 0 keeps them.
+	IncompleteCleanupDays int `json:"incompleteCleanupDays"`
+
+	// StrictValidation refuses to tag, convert, rename, split or trim FLAC
+	// files that fail flacmeta.Validate, instead of producing broken output
+	// from a broken input, and fails downloads that do; such files are
+	// offered for re-download. On by default.
+	StrictValidation bool `json:"strictValidation"`
+
+	// VerifyDownloads fully decodes each FLAC download and checks it
+	// against its STREAMINFO MD5 signature (see analysis.Verify), leaving
+	// a damaged file unprocessed and reporting it. Needs FFmpeg.
+	VerifyDownloads bool `json:"verifyDownloads"`
+
+	// MatchNormalization controls how titles and artists are compared when
+	// matching tracks across sources and to local files (see
+	// internal/textmatch): "" ignores case, width and accents, "accents"
+	// keeps accents significant, and "romanize" also reads kana, Greek and
+	// Cyrillic as Latin so original-script and romanized titles match.
+	MatchNormalization textmatch.Mode `json:"matchNormalization"`
+
+	// EventVerbosity controls how download progress reaches the UI (see
+	// downloads.Verbosity): "" coalesces each quarter second of events
+	// into one message, "all" sends every event on its own, and
+	// "finished" sends only tracks that finished.
+	EventVerbosity downloads.Verbosity `json:"eventVerbosity"`
+
+	// EditionCountries orders the editions of an album offered in search
+	// (see musicbrainz.SortEditions): ISO 3166-1 codes, most preferred
+	// first, e.g. ["JP", "US"] to list Japanese pressings with their bonus
+	// tracks first. "XW" is a worldwide release. Empty lists editions by
+	// date.
+	EditionCountries []string `json:"editionCountries"`
+
+	// LyricsOutput is where embedded lyrics go (see lyricsfile.Output): ""
+	// into the tags only, "both" also into a "<track>.lrc" sidecar, and
+	// "sidecar" into the sidecar alone, leaving the FLAC untouched.
+	LyricsOutput lyricsfile.Output `json:"lyricsOutput"`
+
+	// CoverUserAgents sets the User-Agent the HTTP server's cover proxy
+	// sends to each source's image hosts, keyed by source (see
+	// coverproxy.Hosts), for hosts that turn away unknown clients.
+	// Sources left out get coverproxy.DefaultUserAgent.
+	CoverUserAgents map[string]string `json:"coverUserAgents"`
+}
+
+// Validate reports settings the rest of the app can't act on, as a
+// configdiff.FieldError naming the setting.
+func (s Settings) Validate() error {
+	if s.MaxPathLength < 0 {
+		return invalid("maxPathLength", "maxPathLength must not be negative")
+	}
+	if s.SilenceThreshold > 0 {
+		return invalid("silenceThreshold", "silenceThreshold is in dBFS and must not be positive")
+	}
+	if s.SilenceMinSeconds < 0 {
+		return invalid("silenceMinSeconds", "silenceMinSeconds must not be negative")
+	}
+	if s.AnalysisWorkers < 0 {
+		return invalid("analysisWorkers", "analysisWorkers must not be negative")
+	}
+	if s.ConversionWorkers < 0 {
+		return invalid("conversionWorkers", "conversionWorkers must not be negative")
+	}
+	if s.IncompleteCleanupDays < 0 {
+		return invalid("incompleteCleanupDays", "incompleteCleanupDays must not be negative")
+	}
+	if err := s.AnalyzerThresholds.Validate(); err != nil {
+		return invalid("analyzerThresholds", "analyzerThresholds: %w", err)
+	}
+	if s.CoverMaxSize < 0 {
+		return invalid("coverMaxSize", "coverMaxSize must not be negative")
+	}
+	if s.CoverQuality < 0 || s.CoverQuality > 100 {
+		return invalid("coverQuality", "coverQuality must be between 1 and 100")
+	}
+	if !s.FilenameUnicode.Valid() {
+		return invalid("filenameUnicode", "unknown filenameUnicode mode %q", s.FilenameUnicode)
+	}
+	if !s.TitleLanguage.Valid() {
+		return invalid("titleLanguage", "unknown titleLanguage %q", s.TitleLanguage)
+	}
+	if err := s.GenreMap.Validate(); err != nil {
+		return invalid("genreMap", "genreMap: %w", err)
+	}
+	if !s.FileConflict.Valid() {
+		return invalid("fileConflict", "unknown fileConflict %q", s.FileConflict)
+	}
+	if !s.MatchNormalization.Valid() {
+		return invalid("matchNormalization", "unknown matchNormalization mode %q", s.MatchNormalization)
+	}
+	if !s.EventVerbosity.Valid() {
+		return invalid("eventVerbosity", "unknown eventVerbosity %q", s.EventVerbosity)
+	}
+	if !s.LyricsOutput.Valid() {
+		return invalid("lyricsOutput", "unknown lyricsOutput %q", s.LyricsOutput)
+	}
+	for source := range s.CoverUserAgents {
+		if !slices.Contains(coverproxy.Sources(), source) {
+			return invalid("coverUserAgents", "unknown cover source %q; use one of %v", source, coverproxy.Sources())
+		}
+	}
+	for _, c := range s.EditionCountries {
+		if len(c) != 2 || !isLetter(c[0]) || !isLetter(c[1]) {
+			return invalid("editionCountries", "editionCountries: %q is not an ISO 3166-1 country code", c)
+		}
+	}
+	return nil
+}
+
+// invalid returns a validation error for the setting named key (see
+// configdiff.FieldError).
+func invalid(key, format string, args ...any) error {
+	return configdiff.Field(key, fmt.Errorf(format, args...))
+}
+
+// isLetter reports whether b is an ASCII letter.
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// Defaults returns the settings of a fresh install: everything off or at
+// its default, except StrictValidation.
+func Defaults() Settings {
+	return Settings{StrictValidation: true}
+}
+
+// Load reads settings from dir, taking the options the file leaves out
+// from Defaults. A missing file is not an error; it yields the defaults.
+func Load(dir string) (Settings, error) {
+	s := Defaults()
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Defaults(), err
+	}
+	return s, nil
+}
+
+// Save writes s to dir, creating the directory if needed.
+func Save(dir string, s Settings) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, FileName), data, 0644)
+}
+
+// Store is a concurrency-safe, persisted Settings value. Download workers
+// read it from progress callbacks while the UI may be saving a new one.
+type Store struct {
+	dir string
+
+	mu sync.RWMutex
+	s  Settings
+}
+
+// Open loads the settings in dir into a Store. On a read error the Store
+// still holds the defaults, so callers can log the error and carry on.
+func Open(dir string) (*Store, error) {
+	s, err := Load(dir)
+	return &Store{dir: dir, s: s}, err
+}
+
+// Get returns the current settings.
+func (st *Store) Get() Settings {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.s
+}
+
+// Update validates and persists s and makes it current. The in-memory
+// value is only replaced once the file is written.
+func (st *Store) Update(s Settings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if err := Save(st.dir, s); err != nil {
+		return err
+	}
+	st.s = s
+	return nil
+}
@@ -0,0 +1,120 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"flacidal/internal/analysis"
+)
+
+func TestLoad_MissingFileYieldsDefaults(t *testing.T) {
+	s, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(s, Defaults()) {
+		t.Errorf("got %+v, want the defaults", s)
+	}
+}
+
+func TestLoad_PartialFileKeepsDefaults(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, FileName), []byte(`{"discSubfolders": true}`), 0644)
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !s.DiscSubfolders || !s.StrictValidation {
+		t.Errorf("got %+v, want discSubfolders with strict validation still on", s)
+	}
+}
+
+func TestSaveLoad_RoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	want := Settings{DiscSubfolders: true, EditionCountries: []string{"JP", "XW"}}
+	if err := Save(dir, want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLoad_Malformed(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, FileName), []byte("{not json"), 0644)
+	if _, err := Load(dir); err == nil {
+		t.Error("expected error for malformed settings file")
+	}
+}
+
+func TestStore_UpdatePersists(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := st.Update(Settings{DiscSubfolders: true}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !st.Get().DiscSubfolders {
+		t.Error("Get did not return the updated value")
+	}
+	reopened, _ := Open(dir)
+	if !reopened.Get().DiscSubfolders {
+		t.Error("update was not persisted")
+	}
+}
+
+func TestStore_UpdateRejectsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	st, _ := Open(dir)
+	if err := st.Update(Settings{FilenameUnicode: "latin1"}); err == nil {
+		t.Error("unknown unicode mode should be rejected")
+	}
+	if err := st.Update(Settings{MaxPathLength: -1}); err == nil {
+		t.Error("negative max path length should be rejected")
+	}
+	if err := st.Update(Settings{SilenceThreshold: 10}); err == nil {
+		t.Error("positive silence threshold should be rejected")
+	}
+	if err := st.Update(Settings{AnalysisWorkers: -1}); err == nil {
+		t.Error("negative analysis workers should be rejected")
+	}
+	if err := st.Update(Settings{ConversionWorkers: -1}); err == nil {
+		t.Error("negative conversion workers should be rejected")
+	}
+	if err := st.Update(Settings{IncompleteCleanupDays: -1}); err == nil {
+		t.Error("negative cleanup age should be rejected")
+	}
+	if err := st.Update(Settings{AnalyzerThresholds: analysis.Thresholds{LosslessHz: 15000}}); err == nil {
+		t.Error("lossless cutoff below the likely cutoff should be rejected")
+	}
+	if err := st.Update(Settings{CoverQuality: 101}); err == nil {
+		t.Error("cover quality above 100 should be rejected")
+	}
+	if err := st.Update(Settings{EventVerbosity: "loud"}); err == nil {
+		t.Error("unknown event verbosity should be rejected")
+	}
+	if err := st.Update(Settings{FileConflict: "overwrite"}); err == nil {
+		t.Error("unknown file conflict strategy should be rejected")
+	}
+	if err := st.Update(Settings{LyricsOutput: "txt"}); err == nil {
+		t.Error("unknown lyrics output should be rejected")
+	}
+	if err := st.Update(Settings{EditionCountries: []string{"JP", "USA"}}); err == nil {
+		t.Error("a country that isn't an ISO code should be rejected")
+	}
+	if err := st.Update(Settings{CoverUserAgents: map[string]string{"napster": "x"}}); err == nil {
+		t.Error("a cover source without image hosts should be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+		t.Error("invalid settings were written to disk")
+	}
+}
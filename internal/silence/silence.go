@@ -0,0 +1,270 @@
+// Package silence finds and trims dead air at the start and end of FLAC
+// files, which some proxy-sourced downloads carry several seconds of. The
+// audio is analysed with ffmpeg's silencedetect filter and cut at exact
+// sample offsets with atrim; the file's tags, cover and other metadata
+// blocks are carried over to the trimmed file unchanged.
+package silence
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"flacidal/internal/flacmeta"
+)
+
+// Defaults for zero Options fields.
+const (
+	DefaultThreshold   = -60 // dBFS
+	DefaultMinDuration = 2 * time.Second
+)
+
+// Keep is the silence left at a trimmed end, so tracks don't start or stop
+// abruptly.
+const Keep = 500 * time.Millisecond
+
+// edge is how close to either end of the file a silent stretch must reach
+// to count as leading or trailing silence.
+const edge = 0.05 // seconds
+
+// Options configure detection.
+type Options struct {
+	Threshold   int           // dBFS below which audio is silent; 0 means DefaultThreshold
+	MinDuration time.Duration // shortest silence to report; 0 means DefaultMinDuration
+}
+
+// threshold resolves Threshold.
+func (o Options) threshold() int {
+	if o.Threshold < 0 {
+		return o.Threshold
+	}
+	return DefaultThreshold
+}
+
+// minDuration resolves MinDuration.
+func (o Options) minDuration() time.Duration {
+	if o.MinDuration > 0 {
+		return o.MinDuration
+	}
+	return DefaultMinDuration
+}
+
+// Region is a silent stretch, in seconds from the start of the file.
+type Region struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Report describes the silence found in a file, and what was trimmed.
+type Report struct {
+	Path     string   `json:"path"`
+	Duration float64  `json:"duration"` // seconds, before trimming
+	Leading  float64  `json:"leading"`  // seconds of silence at the start; 0 for none
+	Trailing float64  `json:"trailing"` // seconds of silence at the end; 0 for none
+	Regions  []Region `json:"regions"`  // every silent stretch found, including mid-track ones
+	Trimmed  bool     `json:"trimmed"`
+}
+
+// Trimmable reports whether r found silence at either end.
+func (r *Report) Trimmable() bool {
+	return r.Leading > 0 || r.Trailing > 0
+}
+
+// Runner runs an external command and returns its combined output; tests
+// replace it.
+type Runner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// ExecRunner runs the command with os/exec, adding its output to the error
+// when it fails.
+func ExecRunner(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return out, fmt.Errorf("%w: %s", err, lastLine(msg))
+		}
+	}
+	return out, err
+}
+
+// lastLine returns the last line of s, where ffmpeg puts the reason it
+// failed.
+func lastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// DetectArgs returns the ffmpeg arguments that log path's silent stretches.
+func DetectArgs(path string, opts Options) []string {
+	filter := fmt.Sprintf("silencedetect=noise=%ddB:d=%s", opts.threshold(),
+		strconv.FormatFloat(opts.minDuration().Seconds(), 'f', -1, 64))
+	return []string{"-hide_banner", "-nostdin", "-i", path, "-map", "0:a:0", "-af", filter, "-f", "null", "-"}
+}
+
+// silenceLogRe matches silencedetect's "silence_start: 1.5" and
+// "silence_end: 4.2 | silence_duration: 2.7" log lines.
+var silenceLogRe = regexp.MustCompile(`silence_(start|end): (-?[0-9.]+)`)
+
+// ParseDetect reads the silent stretches from silencedetect's log. A
+// stretch still open when the log ends runs to duration.
+func ParseDetect(log string, duration float64) []Region {
+	var regions []Region
+	open := math.NaN()
+	sc := bufio.NewScanner(strings.NewReader(log))
+	for sc.Scan() {
+		m := silenceLogRe.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		at, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			continue
+		}
+		at = max(at, 0)
+		if m[1] == "start" {
+			open = at
+		} else if !math.IsNaN(open) {
+			regions = append(regions, Region{Start: open, End: at})
+			open = math.NaN()
+		}
+	}
+	if !math.IsNaN(open) && duration > open {
+		regions = append(regions, Region{Start: open, End: duration})
+	}
+	return regions
+}
+
+// Detect finds the silence in the FLAC file at path.
+func Detect(ctx context.Context, run Runner, ffmpeg, path string, opts Options) (*Report, error) {
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := f.Duration()
+	if err != nil || d <= 0 {
+		return nil, fmt.Errorf("%s: unknown length", filepath.Base(path))
+	}
+	out, err := run(ctx, ffmpeg, DetectArgs(path, opts)...)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Path: path, Duration: d.Seconds()}
+	report.Regions = ParseDetect(string(out), report.Duration)
+	for _, r := range report.Regions {
+		if r.Start <= edge {
+			report.Leading = r.End
+		}
+		if r.End >= report.Duration-edge {
+			report.Trailing = report.Duration - r.Start
+		}
+	}
+	if report.Leading >= report.Duration-edge {
+		// All silence: nothing sensible to keep.
+		report.Leading, report.Trailing = 0, 0
+	}
+	return report, nil
+}
+
+// TrimArgs returns the ffmpeg arguments that write samples [start, end) of
+// input to output as FLAC, without metadata.
+func TrimArgs(input, output string, start, end int64) []string {
+	return []string{
+		"-hide_banner", "-loglevel", "error", "-nostdin", "-y",
+		"-i", input,
+		"-map", "0:a:0", "-map_metadata", "-1",
+		"-af", fmt.Sprintf("atrim=start_sample=%d:end_sample=%d,asetpts=N/SR/TB", start, end),
+		"-c:a", "flac",
+		output,
+	}
+}
+
+// Trim cuts the leading and trailing silence report found out of its file,
+// leaving Keep at each trimmed end, and sets report.Trimmed. The trimmed
+// audio is written next to the file and renamed over it once its metadata
+// has been copied across, so a failure leaves the original in place.
+func Trim(ctx context.Context, run Runner, ffmpeg string, report *Report) error {
+	if !report.Trimmable() {
+		return nil
+	}
+	orig, err := flacmeta.Read(report.Path)
+	if err != nil {
+		return err
+	}
+	info, err := orig.StreamInfo()
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(report.Path), err)
+	}
+	rate, total := int64(info.SampleRate), int64(info.Samples)
+	if total == 0 {
+		return fmt.Errorf("%s: unknown length", filepath.Base(report.Path))
+	}
+
+	keep := Keep.Seconds()
+	start, end := int64(0), total
+	if report.Leading > keep {
+		start = int64((report.Leading - keep) * float64(rate))
+	}
+	if report.Trailing > keep {
+		end = total - int64((report.Trailing-keep)*float64(rate))
+	}
+	if end <= start {
+		return fmt.Errorf("%s: nothing left after trimming", filepath.Base(report.Path))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(report.Path), ".silence-*.flac")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if _, err := run(ctx, ffmpeg, TrimArgs(report.Path, tmpPath, start, end)...); err != nil {
+		return err
+	}
+	trimmed, err := flacmeta.Read(tmpPath)
+	if err != nil {
+		return err
+	}
+	blocks := []flacmeta.Block{trimmed.Blocks[0]}
+	if i := trimmed.Find(flacmeta.BlockSeekTable); i >= 0 {
+		blocks = append(blocks, trimmed.Blocks[i])
+	}
+	trimmed.Blocks = append(blocks, carried(orig.Blocks)...)
+	if err := trimmed.Save(); err != nil {
+		return err
+	}
+	if st, err := os.Stat(report.Path); err == nil {
+		os.Chmod(tmpPath, st.Mode().Perm()) //nolint:errcheck // keep the original's mode when possible
+	}
+	if err := os.Rename(tmpPath, report.Path); err != nil {
+		return err
+	}
+	report.Trimmed = true
+	return nil
+}
+
+// carried returns the original metadata blocks that still hold after
+// trimming: everything but STREAMINFO and SEEKTABLE, which the encoder
+// wrote anew, CUESHEET, whose offsets no longer match, and PADDING, which
+// Save lays out again.
+func carried(blocks []flacmeta.Block) []flacmeta.Block {
+	var kept []flacmeta.Block
+	for _, b := range blocks {
+		switch b.Type {
+		case flacmeta.BlockStreamInfo, flacmeta.BlockSeekTable, flacmeta.BlockCueSheet, flacmeta.BlockPadding:
+			continue
+		}
+		kept = append(kept, b)
+	}
+	return kept
+}
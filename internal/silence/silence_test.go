@@ -0,0 +1,108 @@
+package silence
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/flacmeta/flactest"
+)
+
+const detectLog = `Input #0, flac, from 'x.flac':
+[silencedetect @ 0x1] silence_start: 0
+[silencedetect @ 0x1] silence_end: 4.25 | silence_duration: 4.25
+[silencedetect @ 0x1] silence_start: 60
+[silencedetect @ 0x1] silence_end: 63 | silence_duration: 3
+[silencedetect @ 0x1] silence_start: 170.5
+size=N/A time=00:03:00.00 bitrate=N/A speed= 500x
+`
+
+func TestParseDetect(t *testing.T) {
+	got := ParseDetect(detectLog, 180)
+	want := []Region{{0, 4.25}, {60, 63}, {170.5, 180}}
+	if !slices.Equal(got, want) {
+		t.Errorf("regions = %v, want %v", got, want)
+	}
+}
+
+func TestDetectArgs(t *testing.T) {
+	args := strings.Join(DetectArgs("x.flac", Options{}), " ")
+	if !strings.Contains(args, "silencedetect=noise=-60dB:d=2") {
+		t.Errorf("args = %s", args)
+	}
+}
+
+func TestDetectAndTrim(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	comments := (&flacmeta.Comments{Vendor: "v", Fields: []flacmeta.Field{{Name: "TITLE", Value: "Song"}}}).Marshal()
+	flactest.Write(t, path, flactest.Seconds(flactest.CD, 180),
+		flacmeta.Block{Type: flacmeta.BlockVorbisComment, Data: comments},
+		flacmeta.Block{Type: flacmeta.BlockSeekTable, Data: make([]byte, 18)},
+	)
+
+	var trimArgs []string
+	run := func(_ context.Context, name string, args ...string) ([]byte, error) {
+		if slices.Contains(args, "null") {
+			return []byte(detectLog), nil
+		}
+		trimArgs = args
+		flactest.Write(t, args[len(args)-1], flactest.Seconds(flactest.CD, 171), flacmeta.Block{Type: flacmeta.BlockSeekTable, Data: make([]byte, 36)})
+		return nil, nil
+	}
+
+	report, err := Detect(context.Background(), run, "ffmpeg", path, Options{})
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if report.Leading != 4.25 || report.Trailing != 9.5 || len(report.Regions) != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	if err := Trim(context.Background(), run, "ffmpeg", report); err != nil {
+		t.Fatalf("Trim: %v", err)
+	}
+	// Half a second of silence is kept at each end.
+	if !slices.Contains(trimArgs, "atrim=start_sample=165375:end_sample=7541100,asetpts=N/SR/TB") {
+		t.Errorf("trim args = %q", trimArgs)
+	}
+	if !report.Trimmed {
+		t.Error("report not marked trimmed")
+	}
+
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := f.Duration(); d.Seconds() != 171 {
+		t.Errorf("duration = %v, want the trimmed file's", d)
+	}
+	if i := f.Find(flacmeta.BlockSeekTable); i < 0 || len(f.Blocks[i].Data) != 36 {
+		t.Error("seek table is not the trimmed file's")
+	}
+	if c, _ := f.Comments(); c.Get("TITLE") != "Song" {
+		t.Errorf("comments = %+v, want the original tags", c.Fields)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".silence-*")); len(leftovers) != 0 {
+		t.Errorf("temp files left: %v", leftovers)
+	}
+}
+
+func TestDetect_NothingToTrim(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	flactest.Write(t, path, flactest.Seconds(flactest.CD, 180))
+	run := func(context.Context, string, ...string) ([]byte, error) {
+		return []byte("[silencedetect @ 0x1] silence_start: 60\n[silencedetect @ 0x1] silence_end: 63\n"), nil
+	}
+	report, err := Detect(context.Background(), run, "ffmpeg", path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Trimmable() {
+		t.Errorf("report = %+v, want only mid-track silence", report)
+	}
+	if err := Trim(context.Background(), nil, "ffmpeg", report); err != nil || report.Trimmed {
+		t.Errorf("Trim = %v, trimmed %v; want a no-op", err, report.Trimmed)
+	}
+}
@@ -0,0 +1,190 @@
+// Package split cuts a single-file album rip (FLAC or WAV) into one FLAC
+// per track with ffmpeg, at the positions of a cue sheet or a tracklist,
+// and tags each piece. Positions are kept in CD frames (1/75 s) and turned
+// into exact sample offsets, so consecutive pieces neither overlap nor
+// leave a gap.
+package split
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FramesPerSecond is the CD frame rate cue sheet positions count in.
+const FramesPerSecond = 75
+
+// Album is an album rip's metadata and track positions.
+type Album struct {
+	Title     string
+	Performer string
+	Date      string
+	Genre     string
+	File      string // audio file the cue sheet names; empty for tracklists
+	Tracks    []Track
+}
+
+// Track is one track of an Album.
+type Track struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	Performer string `json:"performer,omitempty"` // empty when it's the album performer
+	ISRC      string `json:"isrc,omitempty"`
+	Start     int64  `json:"start"` // CD frames from the start of the file
+}
+
+// ParseCue parses a cue sheet. Each track starts at its INDEX 01; any
+// pregap (INDEX 00) stays at the end of the track before it. Cue sheets
+// naming more than one FILE describe already split tracks and are
+// rejected.
+func ParseCue(text string) (*Album, error) {
+	album := &Album{}
+	var track *Track
+	files := 0
+	sc := bufio.NewScanner(strings.NewReader(strings.TrimPrefix(text, "\ufeff")))
+	for line := 1; sc.Scan(); line++ {
+		cmd, args := cueCommand(sc.Text())
+		switch cmd {
+		case "FILE":
+			if files++; files > 1 {
+				return nil, fmt.Errorf("cue sheet names several files; its tracks are already split")
+			}
+			album.File = cueFile(args)
+		case "TRACK":
+			fields := strings.Fields(args)
+			n, err := strconv.Atoi(firstField(fields))
+			if err != nil || len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: malformed TRACK", line)
+			}
+			if fields[1] != "AUDIO" {
+				track = nil // data tracks are skipped
+				continue
+			}
+			album.Tracks = append(album.Tracks, Track{Number: n, Start: -1})
+			track = &album.Tracks[len(album.Tracks)-1]
+		case "TITLE", "PERFORMER":
+			value := cueString(args)
+			switch {
+			case track != nil && cmd == "TITLE":
+				track.Title = value
+			case track != nil:
+				track.Performer = value
+			case cmd == "TITLE":
+				album.Title = value
+			default:
+				album.Performer = value
+			}
+		case "ISRC":
+			if track != nil {
+				track.ISRC = cueString(args)
+			}
+		case "INDEX":
+			fields := strings.Fields(args)
+			if track == nil || len(fields) < 2 || fields[0] != "01" && fields[0] != "1" {
+				continue
+			}
+			start, err := ParsePosition(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			track.Start = start
+		case "REM":
+			key, value, _ := strings.Cut(args, " ")
+			switch strings.ToUpper(key) {
+			case "DATE":
+				album.Date = cueString(value)
+			case "GENRE":
+				album.Genre = cueString(value)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	for _, t := range album.Tracks {
+		if t.Start < 0 {
+			return nil, fmt.Errorf("track %d has no INDEX 01", t.Number)
+		}
+	}
+	return album, album.validate()
+}
+
+// validate checks the album has tracks in playing order.
+func (a *Album) validate() error {
+	if len(a.Tracks) == 0 {
+		return fmt.Errorf("no tracks")
+	}
+	for i := 1; i < len(a.Tracks); i++ {
+		if a.Tracks[i].Start <= a.Tracks[i-1].Start {
+			return fmt.Errorf("track %d starts before track %d ends", a.Tracks[i].Number, a.Tracks[i-1].Number)
+		}
+	}
+	return nil
+}
+
+// ParsePosition parses a cue sheet position, mm:ss:ff, into CD frames.
+func ParsePosition(s string) (int64, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("bad position %q (want mm:ss:ff)", s)
+	}
+	var n [3]int64
+	for i, p := range parts {
+		v, err := strconv.ParseInt(p, 10, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("bad position %q (want mm:ss:ff)", s)
+		}
+		n[i] = v
+	}
+	if n[1] >= 60 || n[2] >= FramesPerSecond {
+		return 0, fmt.Errorf("bad position %q (want mm:ss:ff)", s)
+	}
+	return (n[0]*60+n[1])*FramesPerSecond + n[2], nil
+}
+
+// cueCommand splits a cue sheet line into its upper-cased command and the
+// rest.
+func cueCommand(line string) (string, string) {
+	cmd, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	return strings.ToUpper(cmd), strings.TrimSpace(args)
+}
+
+// cueString unquotes a cue sheet value.
+func cueString(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		if end := strings.Index(s[1:], `"`); end >= 0 {
+			return s[1 : end+1]
+		}
+		return strings.Trim(s, `"`)
+	}
+	return s
+}
+
+// cueFile returns a FILE command's file name, without its trailing type
+// ("WAVE").
+func cueFile(args string) string {
+	args = strings.TrimSpace(args)
+	if i := strings.LastIndex(args, " "); i > 0 && isFileType(args[i+1:]) {
+		args = args[:i]
+	}
+	return cueString(args)
+}
+
+// isFileType reports whether s is a cue FILE type.
+func isFileType(s string) bool {
+	switch strings.ToUpper(s) {
+	case "WAVE", "BINARY", "MOTOROLA", "AIFF", "MP3", "FLAC":
+		return true
+	}
+	return false
+}
+
+// firstField returns fields[0], or "".
+func firstField(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,61 @@
+package split
+
+import "testing"
+
+const sampleCue = "\ufeff" + `REM GENRE Rock
+REM DATE 1994
+PERFORMER "The Band"
+TITLE "The Album"
+FILE "The Band - The Album.flac" WAVE
+  TRACK 01 AUDIO
+    TITLE "Opening"
+    ISRC USABC9400001
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "Second"
+    PERFORMER "The Band feat. Guest"
+    INDEX 00 03:58:70
+    INDEX 01 04:00:15
+`
+
+func TestParseCue(t *testing.T) {
+	album, err := ParseCue(sampleCue)
+	if err != nil {
+		t.Fatalf("ParseCue: %v", err)
+	}
+	if album.Title != "The Album" || album.Performer != "The Band" || album.Date != "1994" ||
+		album.Genre != "Rock" || album.File != "The Band - The Album.flac" {
+		t.Errorf("album = %+v", album)
+	}
+	if len(album.Tracks) != 2 {
+		t.Fatalf("tracks = %+v, want 2", album.Tracks)
+	}
+	want := Track{Number: 2, Title: "Second", Performer: "The Band feat. Guest", Start: 4*60*75 + 15}
+	if album.Tracks[1] != want {
+		t.Errorf("track 2 = %+v, want %+v (INDEX 01, not the pregap)", album.Tracks[1], want)
+	}
+	if album.Tracks[0].ISRC != "USABC9400001" {
+		t.Errorf("track 1 ISRC = %q", album.Tracks[0].ISRC)
+	}
+}
+
+func TestParseCue_Rejects(t *testing.T) {
+	for name, text := range map[string]string{
+		"several files": "FILE \"1.flac\" WAVE\nTRACK 01 AUDIO\nINDEX 01 00:00:00\nFILE \"2.flac\" WAVE\nTRACK 02 AUDIO\nINDEX 01 00:00:00\n",
+		"no index":      "FILE \"a.flac\" WAVE\nTRACK 01 AUDIO\n",
+		"out of order":  "FILE \"a.flac\" WAVE\nTRACK 01 AUDIO\nINDEX 01 02:00:00\nTRACK 02 AUDIO\nINDEX 01 01:00:00\n",
+		"bad position":  "FILE \"a.flac\" WAVE\nTRACK 01 AUDIO\nINDEX 01 00:61:00\n",
+		"no tracks":     "TITLE \"Empty\"\n",
+	} {
+		if _, err := ParseCue(text); err == nil {
+			t.Errorf("%s: ParseCue succeeded, want an error", name)
+		}
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	got, err := ParsePosition("75:01:74")
+	if want := int64((75*60+1)*75 + 74); err != nil || got != want {
+		t.Errorf("ParsePosition = %d, %v; want %d", got, err, want)
+	}
+}
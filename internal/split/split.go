@@ -0,0 +1,210 @@
+package split
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"flacidal/internal/flacmeta"
+)
+
+// Stream is the format of an album rip, as far as splitting needs it.
+type Stream struct {
+	SampleRate int64
+	Samples    int64 // total per channel; 0 when the file doesn't say
+}
+
+// Probe reads the sample rate and length of a FLAC or WAV file from its
+// header.
+func Probe(path string) (Stream, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".flac":
+		si, err := flacmeta.ReadStreamInfo(path)
+		if err != nil {
+			return Stream{}, err
+		}
+		rate := int64(si.SampleRate)
+		return Stream{SampleRate: rate, Samples: int64(si.Samples)}, checkRate(path, rate)
+	case ".wav":
+		return probeWAV(path)
+	}
+	return Stream{}, fmt.Errorf("%s: only FLAC and WAV rips can be split", filepath.Base(path))
+}
+
+// probeWAV reads the fmt and data chunk headers of a RIFF WAVE file.
+func probeWAV(path string) (Stream, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Stream{}, err
+	}
+	defer f.Close()
+	var riff [12]byte
+	if _, err := io.ReadFull(f, riff[:]); err != nil || string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return Stream{}, fmt.Errorf("%s: not a WAV file", path)
+	}
+	var s Stream
+	var blockAlign int64
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(f, hdr[:]); err != nil {
+			return Stream{}, fmt.Errorf("%s: no data chunk", path)
+		}
+		size := int64(binary.LittleEndian.Uint32(hdr[4:]))
+		switch string(hdr[0:4]) {
+		case "fmt ":
+			fmtChunk := make([]byte, size)
+			if _, err := io.ReadFull(f, fmtChunk); err != nil || size < 16 {
+				return Stream{}, fmt.Errorf("%s: malformed fmt chunk", path)
+			}
+			s.SampleRate = int64(binary.LittleEndian.Uint32(fmtChunk[4:]))
+			blockAlign = int64(binary.LittleEndian.Uint16(fmtChunk[12:]))
+			if size%2 == 1 {
+				f.Seek(1, io.SeekCurrent) //nolint:errcheck // pad byte; a short file fails the next read
+			}
+		case "data":
+			if blockAlign == 0 {
+				return Stream{}, fmt.Errorf("%s: data before fmt chunk", path)
+			}
+			s.Samples = size / blockAlign
+			return s, checkRate(path, s.SampleRate)
+		default:
+			if _, err := f.Seek(size+size%2, io.SeekCurrent); err != nil {
+				return Stream{}, err
+			}
+		}
+	}
+}
+
+// checkRate rejects a zero sample rate.
+func checkRate(path string, rate int64) error {
+	if rate <= 0 {
+		return fmt.Errorf("%s: unknown sample rate", path)
+	}
+	return nil
+}
+
+// Piece is one track cut from the rip: samples [Start, End) of the input,
+// End 0 meaning the end of the file.
+type Piece struct {
+	Track Track  `json:"track"`
+	Path  string `json:"path"`
+	Start int64  `json:"startSample"`
+	End   int64  `json:"endSample,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Plan converts album's track positions into sample ranges of a stream s,
+// each track ending where the next begins, and names each piece with name
+// inside outDir. Tracks starting past the end of the stream are an error.
+func Plan(album *Album, s Stream, outDir string, name func(Track) string) ([]Piece, error) {
+	pieces := make([]Piece, len(album.Tracks))
+	for i, t := range album.Tracks {
+		start := t.Start * s.SampleRate / FramesPerSecond
+		if s.Samples > 0 && start >= s.Samples {
+			return nil, fmt.Errorf("track %d starts after the end of the file", t.Number)
+		}
+		pieces[i] = Piece{Track: t, Path: filepath.Join(outDir, name(t)+".flac"), Start: start}
+		if i > 0 {
+			pieces[i-1].End = start
+		}
+	}
+	return pieces, nil
+}
+
+// Runner runs an external command; tests replace it.
+type Runner func(ctx context.Context, name string, args ...string) error
+
+// ExecRunner runs the command with os/exec, returning its output with the
+// error when it fails.
+func ExecRunner(ctx context.Context, name string, args ...string) error {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(bytes.TrimSpace(out))); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+	}
+	return err
+}
+
+// FFmpegArgs returns the ffmpeg arguments that cut p out of input as FLAC.
+// atrim counts samples, so the cut is exact regardless of frame or packet
+// boundaries; tags are stripped and written afterwards by Tag.
+func FFmpegArgs(input string, p Piece) []string {
+	trim := "atrim=start_sample=" + strconv.FormatInt(p.Start, 10)
+	if p.End > 0 {
+		trim += ":end_sample=" + strconv.FormatInt(p.End, 10)
+	}
+	return []string{
+		"-hide_banner", "-loglevel", "error", "-nostdin", "-n",
+		"-i", input,
+		"-map", "0:a:0", "-map_metadata", "-1",
+		"-af", trim + ",asetpts=N/SR/TB",
+		"-c:a", "flac",
+		p.Path,
+	}
+}
+
+// ErrExists is returned for pieces whose output file already exists.
+var ErrExists = errors.New("file exists")
+
+// Split cuts every piece out of input with ffmpeg and tags it from album.
+// A failed piece is recorded in its Error and doesn't stop the others;
+// existing files are never overwritten.
+func Split(ctx context.Context, run Runner, ffmpeg, input string, album *Album, pieces []Piece) []Piece {
+	for i := range pieces {
+		p := &pieces[i]
+		if err := ctx.Err(); err != nil {
+			p.Error = err.Error()
+			continue
+		}
+		if _, err := os.Stat(p.Path); err == nil {
+			p.Error = ErrExists.Error()
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p.Path), 0755); err != nil {
+			p.Error = err.Error()
+			continue
+		}
+		if err := run(ctx, ffmpeg, FFmpegArgs(input, *p)...); err != nil {
+			p.Error = err.Error()
+			continue
+		}
+		if err := Tag(p.Path, album, p.Track); err != nil {
+			p.Error = err.Error()
+		}
+	}
+	return pieces
+}
+
+// Tag writes t's tags, and album's, to the FLAC at path.
+func Tag(path string, album *Album, t Track) error {
+	artist := t.Performer
+	if artist == "" {
+		artist = album.Performer
+	}
+	return flacmeta.UpdateComments(path, func(c *flacmeta.Comments) {
+		for name, value := range map[string]string{
+			"TITLE":       t.Title,
+			"ARTIST":      artist,
+			"ALBUM":       album.Title,
+			"ALBUMARTIST": album.Performer,
+			"DATE":        album.Date,
+			"GENRE":       album.Genre,
+			"ISRC":        t.ISRC,
+			"TRACKNUMBER": strconv.Itoa(t.Number),
+			"TRACKTOTAL":  strconv.Itoa(len(album.Tracks)),
+		} {
+			if value != "" {
+				c.Set(name, value)
+			}
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package split
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/flacmeta/flactest"
+)
+
+// writeWAV writes a WAV header for samples of 16-bit stereo at rate Hz,
+// without the audio itself.
+func writeWAV(t *testing.T, path string, rate, samples uint32) {
+	t.Helper()
+	b := []byte("RIFF\x00\x00\x00\x00WAVE")
+	b = append(b, "LIST\x03\x00\x00\x00abc\x00"...) // odd-sized chunk to skip
+	b = append(b, "fmt \x10\x00\x00\x00"...)
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:], 2)
+	binary.LittleEndian.PutUint32(fmtChunk[4:], rate)
+	binary.LittleEndian.PutUint32(fmtChunk[8:], rate*4)
+	binary.LittleEndian.PutUint16(fmtChunk[12:], 4)
+	binary.LittleEndian.PutUint16(fmtChunk[14:], 16)
+	b = append(b, fmtChunk...)
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, samples*4)
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	dir := t.TempDir()
+	wav, flac := filepath.Join(dir, "rip.wav"), filepath.Join(dir, "rip.flac")
+	writeWAV(t, wav, 44100, 44100*60)
+	flactest.Write(t, flac, flactest.Seconds(flactest.HiRes, 60))
+
+	if s, err := Probe(wav); err != nil || s != (Stream{SampleRate: 44100, Samples: 44100 * 60}) {
+		t.Errorf("Probe(wav) = %+v, %v", s, err)
+	}
+	if s, err := Probe(flac); err != nil || s != (Stream{SampleRate: 96000, Samples: 96000 * 60}) {
+		t.Errorf("Probe(flac) = %+v, %v", s, err)
+	}
+	if _, err := Probe(filepath.Join(dir, "rip.ape")); err == nil {
+		t.Error("Probe(ape) succeeded")
+	}
+}
+
+func TestPlan_SampleAccurate(t *testing.T) {
+	album := &Album{Tracks: []Track{{Number: 1}, {Number: 2, Start: 4*60*75 + 15}}}
+	pieces, err := Plan(album, Stream{SampleRate: 44100, Samples: 44100 * 600}, "out", func(t Track) string {
+		return strconv.Itoa(t.Number)
+	})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	// 4:00:15 is 240.2 s: 10592820 samples at 44.1 kHz.
+	if pieces[0].End != 10592820 || pieces[1].Start != 10592820 || pieces[1].End != 0 {
+		t.Errorf("pieces = %+v", pieces)
+	}
+	if pieces[1].Path != filepath.Join("out", "2.flac") {
+		t.Errorf("path = %q", pieces[1].Path)
+	}
+	if _, err := Plan(album, Stream{SampleRate: 44100, Samples: 44100}, "out", func(Track) string { return "x" }); err == nil {
+		t.Error("Plan past the end of the file succeeded")
+	}
+}
+
+func TestSplit_RunsFFmpegAndTags(t *testing.T) {
+	dir := t.TempDir()
+	album := &Album{Title: "The Album", Performer: "The Band", Date: "1994", Tracks: []Track{
+		{Number: 1, Title: "One"},
+		{Number: 2, Title: "Two", Performer: "Guest", Start: 75},
+		{Number: 3, Title: "Three", Start: 150},
+	}}
+	pieces, err := Plan(album, Stream{SampleRate: 44100}, dir, func(t Track) string { return t.Title })
+	if err != nil {
+		t.Fatal(err)
+	}
+	flactest.Write(t, pieces[2].Path, flactest.CD) // already there: skipped
+
+	var calls [][]string
+	fake := func(_ context.Context, name string, args ...string) error {
+		calls = append(calls, args)
+		out := args[len(args)-1]
+		if filepath.Base(out) == "Two.flac" {
+			return errors.New("boom")
+		}
+		flactest.Write(t, out, flactest.Seconds(flactest.CD, 1))
+		return nil
+	}
+	got := Split(context.Background(), fake, "ffmpeg", "rip.flac", album, pieces)
+
+	if len(calls) != 2 {
+		t.Fatalf("ffmpeg ran %d times, want 2", len(calls))
+	}
+	if !slices.Contains(calls[0], "atrim=start_sample=0:end_sample=44100,asetpts=N/SR/TB") {
+		t.Errorf("args = %q", calls[0])
+	}
+	if got[0].Error != "" || got[1].Error != "boom" || got[2].Error != ErrExists.Error() {
+		t.Errorf("errors = %q, %q, %q", got[0].Error, got[1].Error, got[2].Error)
+	}
+	f, err := flacmeta.Read(got[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := f.Comments()
+	if c.Get("TITLE") != "One" || c.Get("ARTIST") != "The Band" || c.Get("ALBUMARTIST") != "The Band" ||
+		c.Get("TRACKNUMBER") != "1" || c.Get("TRACKTOTAL") != "3" || c.Get("DATE") != "1994" {
+		t.Errorf("comments = %+v", c.Fields)
+	}
+}
@@ -0,0 +1,79 @@
+package split
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// leadingTimeRe and trailingTimeRe match a tracklist line with its start
+// time, [h:]mm:ss, before ("03:45 Song") or after ("2. Song - 03:45") the
+// title. An optional track number ("2." or "2)") starts the line.
+var (
+	leadingTimeRe  = regexp.MustCompile(`^\s*(?:\d+[.)]\s*)?\[?((?:\d+:)?\d{1,2}:\d{2})\]?\s*(?:[-–.]\s*)?(.*?)\s*$`)
+	trailingTimeRe = regexp.MustCompile(`^\s*(?:\d+[.)]\s*)?(.*?)\s*(?:[-–]\s*)?[(\[]?((?:\d+:)?\d{1,2}:\d{2})[)\]]?\s*$`)
+)
+
+// ParseTracklist parses a plain-text tracklist, one track per line with its
+// start time ("00:00 Intro", "1. Intro - 0:00"), as pasted from a vinyl rip
+// or video description. Lines without a time are ignored.
+func ParseTracklist(text string) (*Album, error) {
+	album := &Album{}
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		var stamp, title string
+		if m := leadingTimeRe.FindStringSubmatch(sc.Text()); m != nil {
+			stamp, title = m[1], m[2]
+		} else if m := trailingTimeRe.FindStringSubmatch(sc.Text()); m != nil {
+			stamp, title = m[2], m[1]
+		} else {
+			continue
+		}
+		start, err := parseTimestamp(stamp)
+		if err != nil {
+			return nil, err
+		}
+		album.Tracks = append(album.Tracks, Track{
+			Number: len(album.Tracks) + 1,
+			Title:  title,
+			Start:  start,
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return album, album.validate()
+}
+
+// parseTimestamp converts [h:]mm:ss into CD frames.
+func parseTimestamp(s string) (int64, error) {
+	var seconds int64
+	for _, p := range strings.Split(s, ":") {
+		v, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad time %q", s)
+		}
+		seconds = seconds*60 + v
+	}
+	return seconds * FramesPerSecond, nil
+}
+
+// FromDurations builds track positions from each track's length in
+// seconds, as source APIs report them: every track starts where the ones
+// before it add up to. The last track runs to the end of the file.
+func FromDurations(album *Album, durations []int) error {
+	if len(durations) != len(album.Tracks) {
+		return fmt.Errorf("%d durations for %d tracks", len(durations), len(album.Tracks))
+	}
+	var at int64
+	for i, d := range durations {
+		if d <= 0 && i < len(durations)-1 {
+			return fmt.Errorf("track %d has no duration", album.Tracks[i].Number)
+		}
+		album.Tracks[i].Start = at
+		at += int64(d) * FramesPerSecond
+	}
+	return album.validate()
+}
@@ -0,0 +1,40 @@
+package split
+
+import "testing"
+
+func TestParseTracklist(t *testing.T) {
+	album, err := ParseTracklist(`Side A
+00:00 Intro
+1:02:03 - Long One
+2. Trailing (1:03:00)
+`)
+	if err != nil {
+		t.Fatalf("ParseTracklist: %v", err)
+	}
+	want := []Track{
+		{Number: 1, Title: "Intro", Start: 0},
+		{Number: 2, Title: "Long One", Start: 3723 * 75},
+		{Number: 3, Title: "Trailing", Start: 3780 * 75},
+	}
+	if len(album.Tracks) != len(want) {
+		t.Fatalf("tracks = %+v, want %+v", album.Tracks, want)
+	}
+	for i := range want {
+		if album.Tracks[i] != want[i] {
+			t.Errorf("track %d = %+v, want %+v", i+1, album.Tracks[i], want[i])
+		}
+	}
+}
+
+func TestFromDurations(t *testing.T) {
+	album := &Album{Tracks: []Track{{Number: 1}, {Number: 2}, {Number: 3}}}
+	if err := FromDurations(album, []int{100, 200, 0}); err != nil {
+		t.Fatalf("FromDurations: %v", err)
+	}
+	if album.Tracks[1].Start != 100*75 || album.Tracks[2].Start != 300*75 {
+		t.Errorf("tracks = %+v", album.Tracks)
+	}
+	if err := FromDurations(album, []int{100}); err == nil {
+		t.Error("FromDurations with too few durations succeeded")
+	}
+}
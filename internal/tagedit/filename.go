@@ -0,0 +1,126 @@
+package tagedit
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// nameTokens maps the placeholders a NamePattern accepts to the fields
+// they fill. {ignore} matches text that is thrown away.
+var nameTokens = map[string]string{
+	"track":       "TRACKNUMBER",
+	"disc":        "DISCNUMBER",
+	"artist":      "ARTIST",
+	"albumartist": "ALBUMARTIST",
+	"album":       "ALBUM",
+	"title":       "TITLE",
+	"year":        "DATE",
+	"genre":       "GENRE",
+	"ignore":      "",
+}
+
+// numericTokens match digits only, so "{track} {title}" splits "01 Intro"
+// at the first space rather than wherever the shortest title fits.
+var numericTokens = map[string]bool{"track": true, "disc": true, "year": true}
+
+// tokenRe finds the placeholders in a pattern.
+var tokenRe = regexp.MustCompile(`\{(\w+)\}`)
+
+// NamePattern reads tags from file names, e.g. "{track} - {artist} -
+// {title}". A pattern with slashes also reads the parent folders, as in
+// "{artist}/{album}/{track} {title}". The extension is never part of the
+// match.
+type NamePattern struct {
+	re     *regexp.Regexp
+	fields []string // field per capture group; "" for {ignore}
+	depth  int      // path components matched
+}
+
+// ParseNamePattern compiles pattern. It needs at least one placeholder
+// that fills a field, and each placeholder may appear once.
+func ParseNamePattern(pattern string) (*NamePattern, error) {
+	pattern = strings.TrimSpace(filepath.ToSlash(pattern))
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	p := &NamePattern{depth: strings.Count(pattern, "/") + 1}
+	var expr strings.Builder
+	expr.WriteString("^")
+	seen := map[string]bool{}
+	last := 0
+	for _, m := range tokenRe.FindAllStringSubmatchIndex(pattern, -1) {
+		name := strings.ToLower(pattern[m[2]:m[3]])
+		field, ok := nameTokens[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown placeholder {%s}", name)
+		}
+		if seen[name] && name != "ignore" {
+			return nil, fmt.Errorf("{%s} appears twice", name)
+		}
+		seen[name] = true
+		expr.WriteString(regexp.QuoteMeta(pattern[last:m[0]]))
+		if numericTokens[name] {
+			expr.WriteString(`(\d+)`)
+		} else {
+			expr.WriteString(`([^/]+?)`)
+		}
+		p.fields = append(p.fields, field)
+		last = m[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(pattern[last:]))
+	expr.WriteString("$")
+	if !slices.ContainsFunc(p.fields, func(f string) bool { return f != "" }) {
+		return nil, fmt.Errorf("pattern %q has no placeholder for a tag", pattern)
+	}
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, err
+	}
+	p.re = re
+	return p, nil
+}
+
+// Fields returns the tags p reads from path, or false when its name
+// doesn't match. Values are trimmed, and track and disc numbers lose
+// their leading zeros.
+func (p *NamePattern) Fields(path string) (map[string]string, bool) {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	if len(parts) < p.depth {
+		return nil, false
+	}
+	name := strings.Join(parts[len(parts)-p.depth:], "/")
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	m := p.re.FindStringSubmatch(name)
+	if m == nil {
+		return nil, false
+	}
+	fields := make(map[string]string, len(p.fields))
+	for i, field := range p.fields {
+		value := strings.TrimSpace(m[i+1])
+		if field == "" || value == "" {
+			continue
+		}
+		if field == "TRACKNUMBER" || field == "DISCNUMBER" {
+			if n, err := strconv.Atoi(value); err == nil {
+				value = strconv.Itoa(n)
+			}
+		}
+		fields[field] = value
+	}
+	return fields, true
+}
+
+// FromName sets the tags p reads from path's name on the FLAC at path,
+// keeping its other tags, or with dryRun only works out the changes. A
+// name that doesn't match is reported as the file's error.
+func FromName(path string, p *NamePattern, dryRun bool) Result {
+	fields, ok := p.Fields(path)
+	if !ok {
+		return Result{Path: path, Changes: []Change{}, Error: "file name doesn't match the pattern"}
+	}
+	return Edit(path, fields, Merge, dryRun)
+}
@@ -0,0 +1,71 @@
+package tagedit
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+)
+
+func TestNamePattern_Fields(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, path string
+		want          map[string]string // nil: no match
+	}{
+		{"{track} - {artist} - {title}", "/music/03 - Nujabes - Aruarian Dance.flac",
+			map[string]string{"TRACKNUMBER": "3", "ARTIST": "Nujabes", "TITLE": "Aruarian Dance"}},
+		{"{track} {title}", "/music/01 Intro - Live.flac",
+			map[string]string{"TRACKNUMBER": "1", "TITLE": "Intro - Live"}},
+		{"{artist}/{album} ({year})/{track}. {title}", "/lib/Boards of Canada/Geogaddi (2002)/07. Julie and Candy.flac",
+			map[string]string{"ARTIST": "Boards of Canada", "ALBUM": "Geogaddi", "DATE": "2002", "TRACKNUMBER": "7", "TITLE": "Julie and Candy"}},
+		{"{ignore} - {title}", "/x/[web] - Song.flac", map[string]string{"TITLE": "Song"}},
+		{"{track} - {title}", "/music/Intro.flac", nil},
+	} {
+		p, err := ParseNamePattern(tc.pattern)
+		if err != nil {
+			t.Fatalf("ParseNamePattern(%q): %v", tc.pattern, err)
+		}
+		got, ok := p.Fields(tc.path)
+		if ok != (tc.want != nil) || !maps.Equal(got, tc.want) {
+			t.Errorf("%q on %s = %v, %v; want %v", tc.pattern, tc.path, got, ok, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"", "no placeholders", "{ignore}", "{title} {bogus}", "{title} - {title}"} {
+		if _, err := ParseNamePattern(bad); err == nil {
+			t.Errorf("ParseNamePattern(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFromName(t *testing.T) {
+	src := writeTagged(t, flacmeta.Field{Name: "COMMENT", Value: "keep"})
+	path := filepath.Join(filepath.Dir(src), "02 - Artist - Title.flac")
+	if err := os.Rename(src, path); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := ParseNamePattern("{track} - {artist} - {title}")
+
+	if r := FromName(path, p, true); r.Written || len(r.Changes) != 3 {
+		t.Fatalf("preview = %+v, want 3 changes and nothing written", r)
+	}
+	if r := FromName(path, p, false); !r.Written || r.Error != "" {
+		t.Fatalf("apply = %+v", r)
+	}
+	want := map[string]string{"COMMENT": "keep", "TRACKNUMBER": "2", "ARTIST": "Artist", "TITLE": "Title"}
+	got := map[string]string{}
+	for _, f := range readFields(t, path) {
+		got[f.Name] = f.Value
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+
+	other := filepath.Join(filepath.Dir(path), "Title.flac")
+	os.Rename(path, other)
+	if r := FromName(other, p, false); r.Error == "" || r.Written {
+		t.Errorf("non-matching name: %+v", r)
+	}
+}
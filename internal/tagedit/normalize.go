@@ -0,0 +1,57 @@
+package tagedit
+
+import (
+	"slices"
+	"strings"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/tagrules"
+)
+
+// Normalize applies rules to the title, artist and album tags of the FLAC
+// at path (see tagrules.Rules.Apply), or with dryRun only works out the
+// changes.
+func Normalize(path string, rules tagrules.Rules, dryRun bool) Result {
+	return edit(path, func(c *flacmeta.Comments) []Change {
+		return NormalizeDiff(c, rules)
+	}, dryRun)
+}
+
+// NormalizeDiff lists the changes applying rules to c makes, by field name.
+// Other fields are left alone.
+func NormalizeDiff(c *flacmeta.Comments, rules tagrules.Rules) []Change {
+	var changes []Change
+	for _, name := range tagrules.Fields {
+		old := c.GetAll(name)
+		values := make([]string, len(old))
+		for i, v := range old {
+			if values[i] = rules.Apply(name, v); values[i] == "" {
+				values[i] = v // never empty a tag, e.g. a title that was only "(Explicit)"
+			}
+		}
+		if !slices.Equal(old, values) {
+			changes = append(changes, Change{Field: name, Old: old, New: values})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Field, b.Field) })
+	return changes
+}
+
+// RemapGenres renames the GENRE tags of the FLAC at path with m (see
+// tagrules.GenreMap.Apply), or with dryRun only works out the change.
+func RemapGenres(path string, m tagrules.GenreMap, dryRun bool) Result {
+	return edit(path, func(c *flacmeta.Comments) []Change {
+		return GenreDiff(c, m)
+	}, dryRun)
+}
+
+// GenreDiff lists the change remapping c's genres with m makes: none, or
+// one for GENRE. A genre mapped to "" is removed.
+func GenreDiff(c *flacmeta.Comments, m tagrules.GenreMap) []Change {
+	old := c.GetAll("GENRE")
+	genres := m.Apply(old)
+	if slices.Equal(old, genres) {
+		return nil
+	}
+	return []Change{{Field: "GENRE", Old: old, New: genres}}
+}
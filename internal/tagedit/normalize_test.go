@@ -0,0 +1,74 @@
+package tagedit
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+	"flacidal/internal/tagrules"
+)
+
+func TestNormalize(t *testing.T) {
+	path := writeTagged(t,
+		flacmeta.Field{Name: "TITLE", Value: "song (2011 Remaster)"},
+		flacmeta.Field{Name: "ARTIST", Value: "Artist ft. Guest"},
+		flacmeta.Field{Name: "ALBUM", Value: "(Explicit)"},
+		flacmeta.Field{Name: "COMMENT", Value: "ft. nobody (Remastered)"},
+	)
+	before, _ := os.ReadFile(path)
+	rules := tagrules.Rules{TitleCase: true, Featuring: true, StripRemaster: true, StripExplicit: true}
+
+	preview := Normalize(path, rules, true)
+	want := []Change{
+		{Field: "ARTIST", Old: []string{"Artist ft. Guest"}, New: []string{"Artist feat. Guest"}},
+		{Field: "TITLE", Old: []string{"song (2011 Remaster)"}, New: []string{"Song"}},
+	}
+	if !slices.EqualFunc(preview.Changes, want, changeEqual) || preview.Written {
+		t.Errorf("preview = %+v, want %+v", preview, want)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("preview wrote the file")
+	}
+
+	if r := Normalize(path, rules, false); !r.Written || r.Error != "" {
+		t.Fatalf("result = %+v", r)
+	}
+	got := readFields(t, path)
+	wantFields := []flacmeta.Field{
+		{Name: "TITLE", Value: "Song"}, {Name: "ARTIST", Value: "Artist feat. Guest"},
+		{Name: "ALBUM", Value: "(Explicit)"}, {Name: "COMMENT", Value: "ft. nobody (Remastered)"},
+	}
+	if !slices.Equal(got, wantFields) {
+		t.Errorf("fields = %v, want %v", got, wantFields)
+	}
+}
+
+func TestRemapGenres(t *testing.T) {
+	path := writeTagged(t,
+		flacmeta.Field{Name: "TITLE", Value: "Song"},
+		flacmeta.Field{Name: "GENRE", Value: "Hip-Hop/Rap"},
+		flacmeta.Field{Name: "GENRE", Value: "Hip Hop"},
+		flacmeta.Field{Name: "GENRE", Value: "Other"},
+	)
+	m := tagrules.GenreMap{"hip hop rap": "Hip Hop", "Other": ""}
+
+	preview := RemapGenres(path, m, true)
+	want := []Change{{Field: "GENRE", Old: []string{"Hip-Hop/Rap", "Hip Hop", "Other"}, New: []string{"Hip Hop"}}}
+	if !slices.EqualFunc(preview.Changes, want, changeEqual) || preview.Written {
+		t.Errorf("preview = %+v, want %+v", preview, want)
+	}
+
+	if r := RemapGenres(path, m, false); !r.Written || r.Error != "" {
+		t.Fatalf("result = %+v", r)
+	}
+	got := readFields(t, path)
+	wantFields := []flacmeta.Field{{Name: "TITLE", Value: "Song"}, {Name: "GENRE", Value: "Hip Hop"}}
+	if !slices.Equal(got, wantFields) {
+		t.Errorf("fields = %v, want %v", got, wantFields)
+	}
+	if r := RemapGenres(path, m, false); r.Written || len(r.Changes) != 0 {
+		t.Errorf("second run = %+v, want no changes", r)
+	}
+}
@@ -0,0 +1,125 @@
+package tagedit
+
+import (
+	"fmt"
+	"strings"
+
+	"flacidal/internal/flacmeta"
+)
+
+// StripOptions selects what Strip removes from a file.
+type StripOptions struct {
+	Fields []string `json:"fields"` // Vorbis comments to remove, by name
+	All    bool     `json:"all"`    // remove every Vorbis comment
+	Covers bool     `json:"covers"` // remove every embedded picture
+	ID3    bool     `json:"id3"`    // remove an ID3v2 tag in front of the FLAC
+}
+
+// Check validates opts and returns them with field names upper-cased.
+func (opts StripOptions) Check() (StripOptions, error) {
+	fields := make([]string, 0, len(opts.Fields))
+	for _, name := range opts.Fields {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if !validName(name) {
+			return opts, fmt.Errorf("invalid tag name %q", name)
+		}
+		fields = append(fields, name)
+	}
+	if len(fields) == 0 && !opts.All && !opts.Covers && !opts.ID3 {
+		return opts, fmt.Errorf("nothing to strip")
+	}
+	opts.Fields = fields
+	return opts, nil
+}
+
+// Strip removes what opts selects (see StripOptions.Check) from the FLAC at
+// path, or with dryRun only works out the changes. Removing all comments
+// keeps the vendor string; the file is only rewritten when something
+// changes.
+func Strip(path string, opts StripOptions, dryRun bool) Result {
+	result := Result{Path: path, Changes: []Change{}}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		result.Fail(err)
+		return result
+	}
+	c, err := f.Comments()
+	if err != nil {
+		result.Fail(err)
+		return result
+	}
+	// Removing a field is setting it to nothing; Replace mode with no
+	// fields removes them all.
+	fields := map[string]string{}
+	mode := Merge
+	if opts.All {
+		mode = Replace
+	} else {
+		for _, name := range opts.Fields {
+			fields[name] = ""
+		}
+	}
+	if changes := Diff(c, fields, mode); changes != nil {
+		result.Changes = changes
+	}
+	if opts.Covers {
+		result.Pictures = f.RemovePictures()
+	}
+	if opts.ID3 && f.ID3 != nil {
+		result.ID3 = true
+		f.ID3 = nil
+	}
+	if dryRun || (len(result.Changes) == 0 && result.Pictures == 0 && !result.ID3) {
+		return result
+	}
+	if len(result.Changes) > 0 {
+		Apply(c, result.Changes)
+		f.SetComments(c)
+	}
+	if err := f.Save(); err != nil {
+		result.Fail(err)
+		return result
+	}
+	result.Written = true
+	return result
+}
+
+// StripAll is Strip for each of paths, after validating opts.
+func StripAll(paths []string, opts StripOptions, dryRun bool) ([]Result, error) {
+	opts, err := opts.Check()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, len(paths))
+	for i, p := range paths {
+		results[i] = Strip(p, opts, dryRun)
+	}
+	return results, nil
+}
+
+// Snapshot saves the metadata of the FLAC at path (tags, pictures, every
+// other block but padding, and any ID3 tag in front) and returns a func
+// that writes it back, undoing any Edit or Strip made since. The audio is
+// never touched.
+func Snapshot(path string) (restore func() error, err error) {
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	saved := make([]flacmeta.Block, 0, len(f.Blocks))
+	for _, b := range f.Blocks {
+		if b.Type != flacmeta.BlockPadding {
+			saved = append(saved, b)
+		}
+	}
+	id3 := f.ID3
+	return func() error {
+		f, err := flacmeta.Read(path)
+		if err != nil {
+			return err
+		}
+		f.Blocks = saved
+		f.ID3 = id3
+		return f.Save()
+	}, nil
+}
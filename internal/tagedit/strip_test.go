@@ -0,0 +1,140 @@
+package tagedit
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+)
+
+// addCover embeds a front cover in the FLAC at path.
+func addCover(t *testing.T, path string) {
+	t.Helper()
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetPicture(flacmeta.Picture{Type: flacmeta.PictureFrontCover, MIME: "image/jpeg", Data: []byte("jpeg")})
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStrip_FieldsAndCovers(t *testing.T) {
+	path := writeTagged(t, flacmeta.Field{Name: "TITLE", Value: "Song"}, flacmeta.Field{Name: "COMMENT", Value: "ripped by x"}, flacmeta.Field{Name: "COMMENT", Value: "y"})
+	addCover(t, path)
+	before, _ := os.ReadFile(path)
+
+	opts := StripOptions{Fields: []string{"comment", "lyrics"}, Covers: true}
+	preview, err := StripAll([]string{path}, opts, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := preview[0]; len(got.Changes) != 1 || got.Changes[0].Field != "COMMENT" || got.Pictures != 1 || got.Written {
+		t.Errorf("preview = %+v", got)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("preview wrote the file")
+	}
+
+	results, _ := StripAll([]string{path}, opts, false)
+	if !results[0].Written || results[0].Error != "" {
+		t.Fatalf("result = %+v", results[0])
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Find(flacmeta.BlockPicture) >= 0 {
+		t.Error("cover kept")
+	}
+	if got := readFields(t, path); len(got) != 1 || got[0].Name != "TITLE" {
+		t.Errorf("fields = %v, want only TITLE", got)
+	}
+}
+
+func TestStrip_All(t *testing.T) {
+	path := writeTagged(t, flacmeta.Field{Name: "TITLE", Value: "Song"}, flacmeta.Field{Name: "ARTIST", Value: "A"})
+	results, err := StripAll([]string{path}, StripOptions{All: true}, false)
+	if err != nil || !results[0].Written || len(results[0].Changes) != 2 {
+		t.Fatalf("StripAll = %+v, %v", results, err)
+	}
+	if got := readFields(t, path); len(got) != 0 {
+		t.Errorf("fields = %v, want none", got)
+	}
+
+	// Nothing left to strip: the file isn't rewritten.
+	results, _ = StripAll([]string{path}, StripOptions{All: true, Covers: true}, false)
+	if results[0].Written {
+		t.Errorf("rewrote an already stripped file: %+v", results[0])
+	}
+}
+
+func TestStripOptions_Check(t *testing.T) {
+	if _, err := (StripOptions{}).Check(); err == nil {
+		t.Error("accepted an empty strip")
+	}
+	if _, err := (StripOptions{Fields: []string{"A=B"}}).Check(); err == nil {
+		t.Error("accepted a field name with '='")
+	}
+	opts, err := StripOptions{Fields: []string{" comment "}}.Check()
+	if err != nil || opts.Fields[0] != "COMMENT" {
+		t.Errorf("Check = %+v, %v", opts, err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	path := writeTagged(t, flacmeta.Field{Name: "TITLE", Value: "Song"}, flacmeta.Field{Name: "GENRE", Value: "Rock"})
+	addCover(t, path)
+	restore, err := Snapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StripAll([]string{path}, StripOptions{All: true, Covers: true}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.HasPicture(flacmeta.PictureFrontCover) {
+		t.Error("cover not restored")
+	}
+	if got := readFields(t, path); len(got) != 2 || got[1].Value != "Rock" {
+		t.Errorf("fields = %v", got)
+	}
+}
+
+func TestStrip_ID3(t *testing.T) {
+	path := writeTagged(t, flacmeta.Field{Name: "TITLE", Value: "Song"})
+	data, _ := os.ReadFile(path)
+	tag := []byte("ID3\x03\x00\x00\x00\x00\x00\x04TIT2")
+	os.WriteFile(path, append(tag, data...), 0644)
+	restore, err := Snapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := StripAll([]string{path}, StripOptions{ID3: true}, false)
+	if err != nil || !results[0].ID3 || !results[0].Written {
+		t.Fatalf("StripAll = %+v, %v", results, err)
+	}
+	if after, _ := os.ReadFile(path); !bytes.HasPrefix(after, []byte("fLaC")) {
+		t.Errorf("file starts with %q", after[:4])
+	}
+	if got := readFields(t, path); len(got) != 1 {
+		t.Errorf("fields = %v, want TITLE kept", got)
+	}
+
+	// Undo puts the ID3 tag back.
+	if err := restore(); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if after, _ := os.ReadFile(path); !bytes.HasPrefix(after, tag) {
+		t.Error("ID3 tag not restored")
+	}
+}
@@ -0,0 +1,217 @@
+// Package tagedit edits the Vorbis comments of many FLAC files at once —
+// fixing a genre, year or album artist across an album, or stripping tags
+// and covers before sharing — with a preview of every field each file
+// would change.
+package tagedit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"flacidal/internal/fileerr"
+	"flacidal/internal/flacmeta"
+)
+
+// Mode selects what happens to the fields an edit doesn't name.
+type Mode string
+
+const (
+	Merge   Mode = "merge"   // keep them; the default
+	Replace Mode = "replace" // remove them, leaving only the edit's fields
+)
+
+// Valid reports whether m is a known mode; "" means Merge.
+func (m Mode) Valid() bool {
+	return m == "" || m == Merge || m == Replace
+}
+
+// Change is one field an edit changes. Old is empty for an added field,
+// New for a removed one.
+type Change struct {
+	Field string   `json:"field"`
+	Old   []string `json:"old,omitempty"`
+	New   []string `json:"new,omitempty"`
+}
+
+// Result is the outcome of an edit for one file.
+type Result struct {
+	Path     string   `json:"path"`
+	Changes  []Change `json:"changes"`
+	Pictures int      `json:"pictures,omitempty"` // embedded pictures removed (see Strip)
+	ID3      bool     `json:"id3,omitempty"`      // ID3v2 tag removed from in front of the FLAC (see Strip)
+	Written  bool     `json:"written"`            // false for previews and files with nothing to change
+	Error    string   `json:"error,omitempty"`
+
+	// Detail explains permission, read-only and disk space errors.
+	Detail *fileerr.Error `json:"detail,omitempty"`
+}
+
+// Fail records err as r's error, explaining permission and disk space
+// errors (see fileerr).
+func (r *Result) Fail(err error) {
+	err = fileerr.Wrap(err)
+	r.Error = err.Error()
+	r.Detail = fileerr.As(err)
+}
+
+// MultiValued are the fields a file commonly repeats, one comment per
+// artist or genre. An edit's value for one of them is split on ";" into a
+// comment per part, the way previews show several values: GENRE "Rock;
+// Pop" writes GENRE=Rock and GENRE=Pop.
+var MultiValued = map[string]bool{"ARTIST": true, "ALBUMARTIST": true, "GENRE": true, "COMPOSER": true}
+
+// Values returns the comments an edit's value for the field name writes:
+// value itself, or its parts for MultiValued fields. An empty value writes
+// none.
+func Values(name, value string) []string {
+	if !MultiValued[strings.ToUpper(name)] {
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	}
+	var values []string
+	for _, v := range strings.Split(value, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Fields validates an edit and returns its fields keyed by their
+// upper-case name. An empty value removes the field (in Merge mode; in
+// Replace mode it is simply not kept).
+func Fields(tags map[string]string, mode Mode) (map[string]string, error) {
+	if !mode.Valid() {
+		return nil, fmt.Errorf("unknown mode %q", mode)
+	}
+	fields := make(map[string]string, len(tags))
+	for name, value := range tags {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if !validName(name) {
+			return nil, fmt.Errorf("invalid tag name %q", name)
+		}
+		fields[name] = value
+	}
+	if len(fields) == 0 && mode != Replace {
+		return nil, fmt.Errorf("no tags to set")
+	}
+	return fields, nil
+}
+
+// validName reports whether name is a legal Vorbis comment field name:
+// printable ASCII other than '='.
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] < 0x20 || name[i] > 0x7d || name[i] == '=' {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff lists the changes applying fields (see Fields) to c in mode makes,
+// by field name.
+func Diff(c *flacmeta.Comments, fields map[string]string, mode Mode) []Change {
+	var changes []Change
+	for name, value := range fields {
+		old := c.GetAll(name)
+		want := Values(name, value)
+		if !slices.Equal(old, want) {
+			changes = append(changes, Change{Field: name, Old: old, New: want})
+		}
+	}
+	if mode == Replace {
+		seen := map[string]bool{}
+		for _, f := range c.Fields {
+			name := strings.ToUpper(f.Name)
+			if _, kept := fields[name]; !kept && !seen[name] {
+				seen[name] = true
+				changes = append(changes, Change{Field: name, Old: c.GetAll(name)})
+			}
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Field, b.Field) })
+	return changes
+}
+
+// DiffValues lists the changes setting each field in want to its values
+// makes to c, by field name, e.g. to preview a tagger that writes whole
+// tag sets. Fields want doesn't name are kept, so they never appear.
+func DiffValues(c *flacmeta.Comments, want map[string][]string) []Change {
+	var changes []Change
+	for name, values := range want {
+		name = strings.ToUpper(name)
+		if old := c.GetAll(name); !slices.Equal(old, values) {
+			changes = append(changes, Change{Field: name, Old: old, New: values})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Field, b.Field) })
+	return changes
+}
+
+// Apply makes changes (see Diff) to c.
+func Apply(c *flacmeta.Comments, changes []Change) {
+	for _, ch := range changes {
+		if len(ch.New) == 0 {
+			c.Delete(ch.Field)
+		} else {
+			c.Set(ch.Field, ch.New...)
+		}
+	}
+}
+
+// Edit applies fields to the FLAC at path in mode, or with dryRun only
+// works out the changes. The file is only rewritten when something
+// changes.
+func Edit(path string, fields map[string]string, mode Mode, dryRun bool) Result {
+	return edit(path, func(c *flacmeta.Comments) []Change { return Diff(c, fields, mode) }, dryRun)
+}
+
+// edit makes the changes diff works out to the FLAC at path, or with
+// dryRun only reports them.
+func edit(path string, diff func(*flacmeta.Comments) []Change, dryRun bool) Result {
+	result := Result{Path: path, Changes: []Change{}}
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		result.Fail(err)
+		return result
+	}
+	c, err := f.Comments()
+	if err != nil {
+		result.Fail(err)
+		return result
+	}
+	if changes := diff(c); changes != nil {
+		result.Changes = changes
+	}
+	if dryRun || len(result.Changes) == 0 {
+		return result
+	}
+	Apply(c, result.Changes)
+	f.SetComments(c)
+	if err := f.Save(); err != nil {
+		result.Fail(err)
+		return result
+	}
+	result.Written = true
+	return result
+}
+
+// EditAll is Edit for each of paths, after validating the edit.
+func EditAll(paths []string, tags map[string]string, mode Mode, dryRun bool) ([]Result, error) {
+	fields, err := Fields(tags, mode)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, len(paths))
+	for i, p := range paths {
+		results[i] = Edit(p, fields, mode, dryRun)
+	}
+	return results, nil
+}
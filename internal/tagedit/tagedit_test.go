@@ -0,0 +1,123 @@
+package tagedit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"flacidal/internal/flacmeta"
+)
+
+// writeTagged writes a FLAC with a bare STREAMINFO and the given comments.
+func writeTagged(t *testing.T, fields ...flacmeta.Field) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(path, append([]byte("fLaC\x80\x00\x00\x22"), make([]byte, 34)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := flacmeta.UpdateComments(path, func(c *flacmeta.Comments) { c.Fields = fields }); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readFields(t *testing.T, path string) []flacmeta.Field {
+	t.Helper()
+	f, err := flacmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := f.Comments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Fields
+}
+
+func TestEditAll_Merge(t *testing.T) {
+	path := writeTagged(t, flacmeta.Field{Name: "TITLE", Value: "Song"}, flacmeta.Field{Name: "GENRE", Value: "Rock"}, flacmeta.Field{Name: "COMMENT", Value: "x"})
+	before, _ := os.ReadFile(path)
+
+	preview, err := EditAll([]string{path}, map[string]string{"genre": "Jazz", "Date": "1999", "comment": "", "TITLE": "Song"}, Merge, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{Field: "COMMENT", Old: []string{"x"}},
+		{Field: "DATE", New: []string{"1999"}},
+		{Field: "GENRE", Old: []string{"Rock"}, New: []string{"Jazz"}},
+	}
+	if !slices.EqualFunc(preview[0].Changes, want, changeEqual) || preview[0].Written {
+		t.Errorf("preview = %+v, want %+v", preview[0], want)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("preview wrote the file")
+	}
+
+	results, _ := EditAll([]string{path}, map[string]string{"genre": "Jazz", "Date": "1999", "comment": ""}, "", false)
+	if !results[0].Written || results[0].Error != "" {
+		t.Fatalf("result = %+v", results[0])
+	}
+	got := readFields(t, path)
+	wantFields := []flacmeta.Field{{Name: "TITLE", Value: "Song"}, {Name: "GENRE", Value: "Jazz"}, {Name: "DATE", Value: "1999"}}
+	if !slices.Equal(got, wantFields) {
+		t.Errorf("fields = %v, want %v", got, wantFields)
+	}
+}
+
+func TestEditAll_Replace(t *testing.T) {
+	path := writeTagged(t, flacmeta.Field{Name: "TITLE", Value: "Song"}, flacmeta.Field{Name: "ARTIST", Value: "A"}, flacmeta.Field{Name: "ARTIST", Value: "B"})
+	results, err := EditAll([]string{path}, map[string]string{"TITLE": "Song", "GENRE": "Pop"}, Replace, false)
+	if err != nil || results[0].Error != "" {
+		t.Fatalf("EditAll = %+v, %v", results, err)
+	}
+	if got := results[0].Changes; len(got) != 2 || got[0].Field != "ARTIST" || len(got[0].Old) != 2 || got[1].Field != "GENRE" {
+		t.Errorf("changes = %+v", got)
+	}
+	want := []flacmeta.Field{{Name: "TITLE", Value: "Song"}, {Name: "GENRE", Value: "Pop"}}
+	if got := readFields(t, path); !slices.Equal(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestEditAll_MultiValued(t *testing.T) {
+	path := writeTagged(t, flacmeta.Field{Name: "ARTIST", Value: "A"}, flacmeta.Field{Name: "ARTIST", Value: "B"})
+	results, err := EditAll([]string{path}, map[string]string{"ARTIST": "A; B", "GENRE": "Rock;Pop; ", "TITLE": "Love; Hate"}, Merge, false)
+	if err != nil || results[0].Error != "" {
+		t.Fatalf("EditAll = %+v, %v", results, err)
+	}
+	// ARTIST already has both values: unchanged.
+	if got := results[0].Changes; len(got) != 2 || got[0].Field != "GENRE" || got[1].Field != "TITLE" {
+		t.Errorf("changes = %+v", got)
+	}
+	want := []flacmeta.Field{
+		{Name: "ARTIST", Value: "A"}, {Name: "ARTIST", Value: "B"},
+		{Name: "GENRE", Value: "Rock"}, {Name: "GENRE", Value: "Pop"},
+		{Name: "TITLE", Value: "Love; Hate"},
+	}
+	if got := readFields(t, path); !slices.Equal(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestEditAll_Invalid(t *testing.T) {
+	if _, err := EditAll(nil, map[string]string{"A=B": "x"}, Merge, true); err == nil {
+		t.Error("accepted a field name with '='")
+	}
+	if _, err := EditAll(nil, map[string]string{"GENRE": "x"}, "append", true); err == nil {
+		t.Error("accepted an unknown mode")
+	}
+	if _, err := EditAll(nil, nil, Merge, true); err == nil {
+		t.Error("accepted an empty merge")
+	}
+	results, _ := EditAll([]string{filepath.Join(t.TempDir(), "missing.flac")}, map[string]string{"GENRE": "x"}, Merge, false)
+	if results[0].Error == "" {
+		t.Error("missing file: no error")
+	}
+}
+
+func changeEqual(a, b Change) bool {
+	return a.Field == b.Field && slices.Equal(a.Old, b.Old) && slices.Equal(a.New, b.New)
+}
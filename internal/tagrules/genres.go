@@ -0,0 +1,59 @@
+package tagrules
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// GenreMap renames genres, e.g. the "Hip-Hop/Rap" one source tags with to
+// the "Hip Hop" another uses, so a library has one name per genre. Keys
+// match regardless of case, spaces and punctuation: "Hip-Hop/Rap" also
+// maps "hip hop rap". An empty value drops the genre.
+type GenreMap map[string]string
+
+// Validate rejects keys that can't match a genre.
+func (m GenreMap) Validate() error {
+	for from := range m {
+		if genreKey(from) == "" {
+			return fmt.Errorf("genre %q has no letters or digits", from)
+		}
+	}
+	return nil
+}
+
+// Apply returns genres with each one m maps renamed, or dropped when it
+// maps to "". Genres that end up the same are kept once, in their first
+// position.
+func (m GenreMap) Apply(genres []string) []string {
+	if len(m) == 0 {
+		return genres
+	}
+	keys := make(map[string]string, len(m))
+	for from, to := range m {
+		keys[genreKey(from)] = strings.TrimSpace(to)
+	}
+	out := make([]string, 0, len(genres))
+	seen := map[string]bool{}
+	for _, g := range genres {
+		if to, ok := keys[genreKey(g)]; ok {
+			g = to
+		}
+		if k := strings.ToLower(g); g != "" && !seen[k] {
+			seen[k] = true
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// genreKey is what GenreMap matches genres by: their letters and digits,
+// lower-cased.
+func genreKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,37 @@
+package tagrules
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGenreMapApply(t *testing.T) {
+	m := GenreMap{"Hip-Hop/Rap": "Hip Hop", "R&B/Soul": "R&B", "Other": ""}
+	tests := []struct {
+		genres, want []string
+	}{
+		{[]string{"Hip-Hop/Rap"}, []string{"Hip Hop"}},
+		{[]string{"hip hop rap"}, []string{"Hip Hop"}},
+		{[]string{"Jazz"}, []string{"Jazz"}},
+		{[]string{"Hip Hop", "Hip-Hop/Rap", "r&b / soul"}, []string{"Hip Hop", "R&B"}},
+		{[]string{"Other", "Pop"}, []string{"Pop"}},
+		{[]string{"Other"}, []string{}},
+	}
+	for _, tt := range tests {
+		if got := m.Apply(tt.genres); !slices.Equal(got, tt.want) {
+			t.Errorf("Apply(%q) = %q, want %q", tt.genres, got, tt.want)
+		}
+	}
+	if got := GenreMap(nil).Apply([]string{"Pop"}); !slices.Equal(got, []string{"Pop"}) {
+		t.Errorf("nil map changed genres: %q", got)
+	}
+}
+
+func TestGenreMapValidate(t *testing.T) {
+	if err := (GenreMap{"Hip-Hop/Rap": "Hip Hop"}).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if err := (GenreMap{" / ": "Pop"}).Validate(); err == nil {
+		t.Error("Validate() accepted a key without letters")
+	}
+}
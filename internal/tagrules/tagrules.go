@@ -0,0 +1,98 @@
+// Package tagrules normalizes tag values the way many libraries like them:
+// titles in title case, one spelling of "feat.", no "(Remastered 2011)"
+// suffixes and no explicit markers. Each rule is toggled on its own.
+package tagrules
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules toggles the normalizations. The zero value changes nothing.
+type Rules struct {
+	TitleCase     bool `json:"titleCase"`     // "hello in there" → "Hello in There"
+	Featuring     bool `json:"featuring"`     // "ft." / "Feat" / "featuring" → "feat."
+	StripRemaster bool `json:"stripRemaster"` // drop "(Remastered 2011)", " - 2011 Remaster"
+	StripExplicit bool `json:"stripExplicit"` // drop "(Explicit)", "[E]", "🅴"
+}
+
+// IsZero reports whether r changes nothing.
+func (r Rules) IsZero() bool {
+	return r == Rules{}
+}
+
+// Fields are the tags the rules apply to.
+var Fields = []string{"TITLE", "ARTIST", "ALBUM", "ALBUMARTIST"}
+
+var (
+	remasterGroup  = regexp.MustCompile(`(?i)\s*[(\[][^()\[\]]*\bremaster(ed)?\b[^()\[\]]*[)\]]`)
+	remasterSuffix = regexp.MustCompile(`(?i)\s+-\s+[^-]*\bremaster(ed)?\b[^-]*$`)
+	explicitGroup  = regexp.MustCompile(`(?i)\s*[(\[](explicit( version)?|e)[)\]]|\s*\x{1F174}`)
+	featuring      = regexp.MustCompile(`(?i)(^|[\s(\[])(featuring|feat\.?|ft\.?)\s+`)
+)
+
+// Apply returns value, a tag named field, with r's rules applied. Title
+// case and the suffix rules only apply to titles and album names; artist
+// names are left as the artist spells them, bar "feat.".
+func (r Rules) Apply(field, value string) string {
+	field = strings.ToUpper(field)
+	named := field == "TITLE" || field == "ALBUM"
+	if r.StripExplicit && named {
+		value = explicitGroup.ReplaceAllString(value, "")
+	}
+	if r.StripRemaster && named {
+		value = remasterGroup.ReplaceAllString(value, "")
+		value = remasterSuffix.ReplaceAllString(value, "")
+	}
+	if r.Featuring {
+		value = featuring.ReplaceAllString(value, "${1}feat. ")
+	}
+	if r.TitleCase && named {
+		value = TitleCase(value)
+	}
+	return strings.TrimSpace(value)
+}
+
+// smallWords stay lower case inside a title.
+var smallWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "but": true,
+	"by": true, "for": true, "from": true, "in": true, "into": true, "nor": true,
+	"of": true, "on": true, "or": true, "the": true, "to": true, "vs": true,
+	"vs.": true, "with": true, "feat.": true,
+}
+
+// TitleCase capitalizes the words of s, keeping small words such as "of"
+// and "the" lower case unless they start or end it or follow a bracket.
+// Words already in mixed or upper case ("iTunes", "AC/DC") are kept.
+func TitleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		core := strings.TrimLeft(w, `("'[“‘`)
+		lead := w[:len(w)-len(core)]
+		if core == "" || !isLower(core) && !isCapitalized(core) {
+			continue
+		}
+		lower := strings.ToLower(core)
+		opens := lead != "" || i > 0 && strings.HasSuffix(words[i-1], ":")
+		if smallWords[lower] && i > 0 && i < len(words)-1 && !opens {
+			words[i] = lead + lower
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(core)
+		words[i] = lead + string(unicode.ToUpper(first)) + core[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// isLower reports whether s has no upper-case letters.
+func isLower(s string) bool {
+	return strings.IndexFunc(s, unicode.IsUpper) < 0
+}
+
+// isCapitalized reports whether only s's first letter is upper case.
+func isCapitalized(s string) bool {
+	first, size := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(first) && isLower(s[size:])
+}
@@ -0,0 +1,35 @@
+package tagrules
+
+import "testing"
+
+func TestApply(t *testing.T) {
+	all := Rules{TitleCase: true, Featuring: true, StripRemaster: true, StripExplicit: true}
+	tests := []struct {
+		rules        Rules
+		field, value string
+		want         string
+	}{
+		{Rules{}, "TITLE", "song (Remastered 2011)", "song (Remastered 2011)"},
+		{Rules{StripRemaster: true}, "TITLE", "Song (Remastered 2011)", "Song"},
+		{Rules{StripRemaster: true}, "TITLE", "Song - 2011 Remaster", "Song"},
+		{Rules{StripRemaster: true}, "ALBUM", "Album [2009 Remastered Version] (Deluxe)", "Album (Deluxe)"},
+		{Rules{StripRemaster: true}, "TITLE", "Song (Live)", "Song (Live)"},
+		{Rules{StripExplicit: true}, "TITLE", "Song [Explicit]", "Song"},
+		{Rules{StripExplicit: true}, "TITLE", "Song (E) 🅴", "Song"},
+		{Rules{StripExplicit: true}, "ARTIST", "Artist (E)", "Artist (E)"},
+		{Rules{Featuring: true}, "TITLE", "Song (ft. Someone)", "Song (feat. Someone)"},
+		{Rules{Featuring: true}, "ARTIST", "Artist Featuring Someone", "Artist feat. Someone"},
+		{Rules{Featuring: true}, "TITLE", "Left Behind", "Left Behind"},
+		{Rules{TitleCase: true}, "TITLE", "hello in there", "Hello in There"},
+		{Rules{TitleCase: true}, "TITLE", "the sound of silence", "The Sound of Silence"},
+		{Rules{TitleCase: true}, "TITLE", "Back In Black (live at the iTunes festival)", "Back in Black (Live at the iTunes Festival)"},
+		{Rules{TitleCase: true}, "TITLE", "AC/DC", "AC/DC"},
+		{Rules{TitleCase: true}, "ARTIST", "the beatles", "the beatles"},
+		{all, "TITLE", "song ft. someone (2015 Remaster) [Explicit]", "Song feat. Someone"},
+	}
+	for _, tt := range tests {
+		if got := tt.rules.Apply(tt.field, tt.value); got != tt.want {
+			t.Errorf("%+v.Apply(%s, %q) = %q, want %q", tt.rules, tt.field, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package textmatch normalizes titles and artist names so they can be
+// compared across sources and catalogs: case, full-width forms and accents
+// ("Beyoncé" = "Beyonce") are ignored, punctuation only separates words,
+// and CJK text, which isn't written with spaces, is compared character by
+// character. Optionally kana, Greek and Cyrillic are romanized so that
+// "ヨルシカ" matches "Yorushika".
+package textmatch
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"flacidal/internal/naming"
+)
+
+// Mode selects how far text is normalized before it is compared.
+type Mode string
+
+const (
+	Default     Mode = ""         // ignore case, width and accents
+	KeepAccents Mode = "accents"  // ignore case and width; "é" ≠ "e"
+	Romanize    Mode = "romanize" // Default, plus kana, Greek and Cyrillic read as Latin
+)
+
+// Valid reports whether m is a known mode.
+func (m Mode) Valid() bool {
+	return m == Default || m == KeepAccents || m == Romanize
+}
+
+// Fold rewrites s the way m compares it, keeping case and punctuation:
+// compatibility forms are unified ("Ａ" → "A", "ﬁ" → "fi"), accents are
+// dropped from Latin, Greek and Cyrillic letters unless m is KeepAccents,
+// and with Romanize, scripts naming.Transliterate knows are spelled in
+// Latin. The result suits a search query as well as comparison.
+func Fold(s string, m Mode) string {
+	if m == Romanize {
+		s = naming.Transliterate(s)
+	}
+	if m == KeepAccents {
+		return norm.NFKC.String(s)
+	}
+	var b strings.Builder
+	strip := false // whether the current base letter's accents are dropped
+	for _, r := range norm.NFKD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			if !strip {
+				b.WriteRune(r) // e.g. kana voicing marks, Indic vowel signs
+			}
+			continue
+		}
+		strip = unicode.In(r, unicode.Latin, unicode.Greek, unicode.Cyrillic)
+		b.WriteRune(r)
+	}
+	return norm.NFC.String(b.String())
+}
+
+// Tokens splits the folded, lower-cased s into words. Letters and digits
+// make up words; apostrophes are dropped ("don't" → "dont") and any other
+// character separates words. Each CJK character is a word of its own.
+func Tokens(s string, m Mode) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(Fold(s, m)) {
+		switch {
+		case isCJK(r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			word.WriteRune(r)
+		case r == '\'' || r == '’':
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// isCJK reports whether r is a Han, kana or Hangul character, or the kana
+// long-vowel mark.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || r == 'ー'
+}
+
+// Normalize returns s's tokens joined by single spaces: two strings with
+// the same Normalize match.
+func Normalize(s string, m Mode) string {
+	return strings.Join(Tokens(s, m), " ")
+}
+
+// Equal reports whether a and b are the same non-empty text under m.
+func Equal(a, b string, m Mode) bool {
+	na := Normalize(a, m)
+	return na != "" && na == Normalize(b, m)
+}
+
+// Contains reports whether sub's words appear, in order and next to each
+// other, in s. An empty sub is contained in anything.
+func Contains(s, sub string, m Mode) bool {
+	ns := Normalize(sub, m)
+	return ns == "" || strings.Contains(" "+Normalize(s, m)+" ", " "+ns+" ")
+}
+
+// Similarity scores how alike a and b are, from 0 (no word in common, or
+// either is empty) to 1 (the same words): the Dice coefficient of their
+// tokens. Word order is ignored, so "Artist feat. X" and "X, Artist" score
+// high.
+func Similarity(a, b string, m Mode) float64 {
+	ta, tb := Tokens(a, m), Tokens(b, m)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	counts := map[string]int{}
+	for _, t := range ta {
+		counts[t]++
+	}
+	common := 0
+	for _, t := range tb {
+		if counts[t] > 0 {
+			counts[t]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(ta)+len(tb))
+}
@@ -0,0 +1,72 @@
+package textmatch
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		in   string
+		mode Mode
+		want string
+	}{
+		{"Beyoncé", Default, "beyonce"},
+		{"Beyoncé", KeepAccents, "beyoncé"},
+		{"Ｓｉｇｕｒ Ｒóｓ", Default, "sigur ros"},
+		{"Don't Stop (Remastered)", Default, "dont stop remastered"},
+		{"Мумий Тролль", Default, "мумии тролль"},
+		{"Мумий Тролль", Romanize, "mumiy troll"},
+		{"夜に駆ける", Default, "夜 に 駆 け る"},
+		{"ヨルシカ", Romanize, "yorushika"},
+		{"がっこう", Default, "が っ こ う"}, // voicing marks are not accents
+		{"방탄소년단", Default, "방 탄 소 년 단"},
+	}
+	for _, c := range cases {
+		if got := Normalize(c.in, c.mode); got != c.want {
+			t.Errorf("Normalize(%q, %q) = %q, want %q", c.in, c.mode, got, c.want)
+		}
+	}
+}
+
+func TestFold_KeepsCaseAndPunctuation(t *testing.T) {
+	if got := Fold("Björk – Jóga", Default); got != "Bjork – Joga" {
+		t.Errorf("Fold = %q", got)
+	}
+}
+
+func TestEqualAndContains(t *testing.T) {
+	if !Equal("Sigur Rós", "sigur ros", Default) || Equal("Sigur Rós", "sigur ros", KeepAccents) {
+		t.Error("accent folding follows the mode")
+	}
+	if Equal("", "", Default) {
+		t.Error("empty strings match")
+	}
+	if !Equal("ヨルシカ", "Yorushika", Romanize) || Equal("ヨルシカ", "Yorushika", Default) {
+		t.Error("romanization follows the mode")
+	}
+	if !Contains("01 - Björk - Jóga.flac", "bjork", Default) || Contains("Björkish", "bjork", Default) {
+		t.Error("Contains matches whole words")
+	}
+	if !Contains("anything", "", Default) {
+		t.Error("empty sub is contained")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	if got := Similarity("Artist feat. Guest", "Guest, Artist", Default); got < 0.79 {
+		t.Errorf("reordered = %v", got)
+	}
+	if got := Similarity("夜に駆ける", "夜に駆ける (Live)", Default); got < 0.8 {
+		t.Errorf("CJK with suffix = %v", got)
+	}
+	if got := Similarity("", "x", Default); got != 0 {
+		t.Errorf("empty = %v", got)
+	}
+}
+
+func TestTokens_Apostrophes(t *testing.T) {
+	if got := Tokens("Rock ’n’ Roll", Default); !slices.Equal(got, []string{"rock", "n", "roll"}) {
+		t.Errorf("Tokens = %v", got)
+	}
+}
@@ -0,0 +1,195 @@
+package timestamp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// StoredLayout is how go-sqlite3 writes a time.Time, and so how
+// flacidal-core's database stores times.
+const StoredLayout = "2006-01-02 15:04:05.999999999-07:00"
+
+// timeType is reflect's view of time.Time, for UTCFields and Latest.
+var timeType = reflect.TypeOf(time.Time{})
+
+// UTCFields converts the time.Time fields of the struct v points to, or of
+// every struct in the slice v, to API timestamps (see UTC) in place. It is
+// for flacidal-core's types whose times come back from the database in the
+// local zone; other values are left alone.
+func UTCFields(v any) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		utcFields(rv.Elem())
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			utcFields(rv.Index(i))
+		}
+	}
+}
+
+// utcFields converts the settable time.Time fields of the struct v.
+func utcFields(v reflect.Value) {
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Type() == timeType && f.CanSet() {
+			f.Set(reflect.ValueOf(UTC(f.Interface().(time.Time))))
+		}
+	}
+}
+
+// Latest returns the latest time.Time field of the struct v, or of the
+// struct v points to — for flacidal-core's history entries, when the track
+// finished. It returns the zero time when v has none set.
+func Latest(v any) time.Time {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	var latest time.Time
+	if rv.Kind() != reflect.Struct {
+		return latest
+	}
+	for i := 0; i < rv.NumField(); i++ {
+		if f := rv.Field(i); f.Type() == timeType && f.CanInterface() {
+			if t := f.Interface().(time.Time); t.After(latest) {
+				latest = t
+			}
+		}
+	}
+	return latest
+}
+
+// MigrateDB rewrites the times stored in db's date, datetime and timestamp
+// columns — go-sqlite3's time columns — in UTC, in StoredLayout, and
+// returns how many it rewrote. Zone-less values are taken as UTC, as
+// go-sqlite3 reads them; values that aren't times are left alone. Running
+// it again rewrites nothing.
+func MigrateDB(ctx context.Context, db *sql.DB) (int, error) {
+	columns, err := timeColumns(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	n := 0
+	for _, c := range columns {
+		changed, err := migrateColumn(ctx, tx, c.table, c.column)
+		if err != nil {
+			return 0, fmt.Errorf("%s.%s: %w", c.table, c.column, err)
+		}
+		n += changed
+	}
+	return n, tx.Commit()
+}
+
+// column names a table column.
+type column struct{ table, column string }
+
+// timeColumns lists the columns go-sqlite3 reads as time.Time, in rowid
+// tables (MigrateDB updates by rowid).
+func timeColumns(ctx context.Context, db *sql.DB) ([]column, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, sql FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		var ddl sql.NullString
+		if err := rows.Scan(&name, &ddl); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !strings.Contains(strings.ToUpper(ddl.String), "WITHOUT ROWID") {
+			tables = append(tables, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var columns []column
+	for _, table := range tables {
+		rows, err := db.QueryContext(ctx, "SELECT name, type FROM pragma_table_info(?)", table)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var name, typ string
+			if err := rows.Scan(&name, &typ); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			switch strings.ToLower(typ) {
+			case "date", "datetime", "timestamp":
+				columns = append(columns, column{table, name})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return columns, nil
+}
+
+// migrateColumn rewrites the text times in one column.
+func migrateColumn(ctx context.Context, tx *sql.Tx, table, col string) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT rowid, CAST(%s AS TEXT) FROM %s WHERE typeof(%[1]s) = 'text'", quote(col), quote(table)))
+	if err != nil {
+		return 0, err
+	}
+	type update struct {
+		rowid int64
+		value string
+	}
+	var updates []update
+	for rows.Next() {
+		var rowid int64
+		var s string
+		if err := rows.Scan(&rowid, &s); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		t, ok := parse(s, time.UTC)
+		if !ok {
+			continue
+		}
+		if v := t.UTC().Format(StoredLayout); v != s {
+			updates = append(updates, update{rowid, v})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	stmt := fmt.Sprintf("UPDATE %s SET %s = ? WHERE rowid = ?", quote(table), quote(col))
+	for _, u := range updates {
+		if _, err := tx.ExecContext(ctx, stmt, u.value, u.rowid); err != nil {
+			return 0, err
+		}
+	}
+	return len(updates), nil
+}
+
+// quote quotes an SQL identifier.
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,107 @@
+package timestamp
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestUTCFields(t *testing.T) {
+	type entry struct {
+		Title string
+		At    time.Time
+		Done  time.Time
+	}
+	paris := time.FixedZone("CET", 3600)
+	entries := []entry{{Title: "a", At: time.Date(2026, 3, 1, 19, 4, 5, 999, paris)}}
+	UTCFields(entries)
+	if got := entries[0].At.Format(time.RFC3339Nano); got != "2026-03-01T18:04:05Z" {
+		t.Errorf("At = %s", got)
+	}
+	if !entries[0].Done.IsZero() {
+		t.Errorf("zero Done became %v", entries[0].Done)
+	}
+
+	e := entry{At: time.Date(2026, 3, 1, 19, 4, 5, 0, paris)}
+	UTCFields(&e)
+	if e.At.Location() != time.UTC {
+		t.Errorf("At in %v, want UTC", e.At.Location())
+	}
+}
+
+func TestLatest(t *testing.T) {
+	type entry struct {
+		Queued   time.Time
+		Finished time.Time
+		private  time.Time
+	}
+	queued := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	e := entry{Queued: queued, Finished: queued.Add(time.Minute), private: queued.Add(time.Hour)}
+	if got := Latest(e); !got.Equal(queued.Add(time.Minute)) {
+		t.Errorf("Latest = %v, want Finished", got)
+	}
+	if got := Latest(&entry{}); !got.IsZero() {
+		t.Errorf("Latest(no times) = %v, want zero", got)
+	}
+}
+
+func TestMigrateDB(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE history (title TEXT, downloaded_at DATETIME, note TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	rows := [][2]string{
+		{"local", "2026-03-01 19:04:05.5+01:00"},
+		{"zoneless", "2026-03-01 18:04:05"},
+		{"utc", "2026-03-01 18:04:05+00:00"},
+		{"junk", "yesterday"},
+	}
+	for _, r := range rows {
+		if _, err := db.Exec("INSERT INTO history VALUES (?, ?, '2026-03-01 19:04:05+01:00')", r[0], r[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := MigrateDB(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("MigrateDB rewrote %d values, want 2", n)
+	}
+	want := map[string]string{
+		"local":    "2026-03-01 18:04:05.5+00:00",
+		"zoneless": "2026-03-01 18:04:05+00:00",
+		"utc":      "2026-03-01 18:04:05+00:00",
+		"junk":     "yesterday",
+	}
+	got, err := db.Query("SELECT title, CAST(downloaded_at AS TEXT), note FROM history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Close()
+	for got.Next() {
+		var title, at, note string
+		if err := got.Scan(&title, &at, &note); err != nil {
+			t.Fatal(err)
+		}
+		if at != want[title] {
+			t.Errorf("%s: downloaded_at = %q, want %q", title, at, want[title])
+		}
+		if note != "2026-03-01 19:04:05+01:00" {
+			t.Errorf("%s: text column rewritten to %q", title, note)
+		}
+	}
+
+	if n, err := MigrateDB(context.Background(), db); err != nil || n != 0 {
+		t.Errorf("second MigrateDB = %d, %v; want 0, nil", n, err)
+	}
+}
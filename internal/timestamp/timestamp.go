@@ -0,0 +1,126 @@
+// Package timestamp is how FLACidal's APIs serialize times: UTC RFC3339,
+// seconds precision, e.g. "2026-03-01T18:04:05Z". flacidal-core hands
+// times over in several shapes — time.Time in the local zone from the
+// database, preformatted local strings in log entries and file listings —
+// so both APIs pass them through here, MigrateDB rewrites the ones stored
+// before, and the frontend formats them for display (the desktop app with
+// the locale hint from Hint).
+package timestamp
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// Layout is the API timestamp format.
+const Layout = time.RFC3339
+
+// UTC returns t in UTC at seconds precision, so it marshals as Layout.
+// The zero time is left as is.
+func UTC(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.UTC().Truncate(time.Second)
+}
+
+// Format returns t as an API timestamp, or "" for the zero time.
+func Format(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return UTC(t).Format(Layout)
+}
+
+// layouts are the string forms seen from flacidal-core and SQLite, with
+// and without zone. Zone-less values are read in the caller's location.
+var layouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+}
+
+// Normalize rewrites a timestamp string as an API timestamp. Zone-less
+// values are read in loc. A bare time of day ("15:04:05", as log entries
+// carry) is taken as the most recent such time at or before now. Strings
+// it can't parse are returned unchanged rather than dropped.
+func Normalize(s string, loc *time.Location, now time.Time) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return s
+	}
+	if t, ok := parse(s, loc); ok {
+		return Format(t)
+	}
+	if clock, err := time.ParseInLocation("15:04:05", s, loc); err == nil {
+		now = now.In(loc)
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, loc)
+		if t.After(now) {
+			t = t.AddDate(0, 0, -1)
+		}
+		return Format(t)
+	}
+	return s
+}
+
+// parse reads s in one of layouts, zone-less values in loc.
+func parse(s string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// LocaleHint tells the frontend how the user expects dates to read: API
+// timestamps are UTC, and the frontend formats them with this locale in
+// this time zone.
+type LocaleHint struct {
+	Locale   string `json:"locale"`   // BCP 47 tag, e.g. "en-GB"; "" lets the browser decide
+	TimeZone string `json:"timeZone"` // IANA zone, e.g. "Europe/Paris"; "" means the browser's
+}
+
+// Hint returns the locale hint for the machine FLACidal runs on, from the
+// POSIX locale variables and TZ.
+func Hint() LocaleHint {
+	return LocaleHint{Locale: localeTag(posixLocale()), TimeZone: zoneName()}
+}
+
+// posixLocale returns the locale used for dates, in POSIX precedence order.
+func posixLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// localeTag converts a POSIX locale ("en_GB.UTF-8", "de_DE@euro") into a
+// BCP 47 tag ("en-GB"). "C" and "POSIX" have no language and yield "".
+func localeTag(posix string) string {
+	if i := strings.IndexAny(posix, ".@"); i >= 0 {
+		posix = posix[:i]
+	}
+	if posix == "" || posix == "C" || posix == "POSIX" {
+		return ""
+	}
+	return strings.ReplaceAll(posix, "_", "-")
+}
+
+// zoneName returns the IANA name of the local zone when it is known.
+func zoneName() string {
+	tz := strings.TrimPrefix(os.Getenv("TZ"), ":")
+	if tz != "" && !strings.HasPrefix(tz, "/") {
+		return tz
+	}
+	if name := time.Local.String(); name != "Local" {
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	got := Format(time.Date(2026, 3, 1, 19, 4, 5, 999, paris))
+	if got != "2026-03-01T18:04:05Z" {
+		t.Errorf("Format() = %q", got)
+	}
+	if got := Format(time.Time{}); got != "" {
+		t.Errorf("Format(zero) = %q, want empty", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, loc)
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2026-03-01T19:04:05+01:00", "2026-03-01T18:04:05Z"},
+		{"2026-03-01T18:04:05.123Z", "2026-03-01T18:04:05Z"},
+		{"2026-03-01 19:04:05.5+01:00", "2026-03-01T18:04:05Z"},
+		{"2026-03-01 19:04:05", "2026-03-01T18:04:05Z"},
+		{"2026-03-01 19:04", "2026-03-01T18:04:00Z"},
+		{"11:30:00", "2026-03-01T10:30:00Z"},
+		{"13:00:00", "2026-02-28T12:00:00Z"}, // later than now: yesterday
+		{"", ""},
+		{"not a time", "not a time"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in, loc, now); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleTag(t *testing.T) {
+	for in, want := range map[string]string{
+		"en_GB.UTF-8": "en-GB",
+		"de_DE@euro":  "de-DE",
+		"fr":          "fr",
+		"C":           "",
+		"POSIX":       "",
+		"":            "",
+	} {
+		if got := localeTag(in); got != want {
+			t.Errorf("localeTag(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHint(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_TIME", "pt_BR.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	t.Setenv("TZ", "America/Sao_Paulo")
+	if got := Hint(); got != (LocaleHint{Locale: "pt-BR", TimeZone: "America/Sao_Paulo"}) {
+		t.Errorf("Hint() = %+v", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Package watchfolder queues downloads from URL lists dropped into a
+// folder. Every .txt, .m3u, .csv or .json file that appears there is handed to an
+// import function and then moved to a "processed" subfolder, so each file
+// is imported once. The folder is watched like the library (see
+// internal/fswatch), and polled where the OS can't report changes.
+package watchfolder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"flacidal/internal/fswatch"
+)
+
+// ProcessedDir is the subfolder imported files are moved to.
+const ProcessedDir = "processed"
+
+// Interval is how often the folder is scanned where its changes can't be
+// watched.
+const Interval = 5 * time.Second
+
+// settleTime is how long a file must go unmodified before it is imported,
+// so a list still being written or copied isn't read half-finished.
+const settleTime = 2 * time.Second
+
+// ImportFunc imports the URL list in a dropped file. name is the file's
+// base name, for logging.
+type ImportFunc func(name, text string)
+
+// IsListFile reports whether name is a URL list the watcher imports.
+func IsListFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".txt", ".m3u", ".m3u8", ".csv", ".json":
+		return true
+	}
+	return false
+}
+
+// Scan imports every settled list file in dir once, moving each to
+// dir/processed afterwards, and returns how many it imported. A file that
+// can't be read or moved is reported in the error and left for the next
+// scan; the others are still processed.
+func Scan(dir string, now time.Time, fn ImportFunc) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []string
+	imported := 0
+	for _, e := range entries {
+		if e.IsDir() || !IsListFile(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || now.Sub(info.ModTime()) < settleTime {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		// Move first: a file that can't be moved would be imported again on
+		// every scan.
+		if err := moveProcessed(dir, e.Name(), now); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		fn(e.Name(), string(data))
+		imported++
+	}
+
+	if len(errs) > 0 {
+		return imported, fmt.Errorf("watch folder: %s", strings.Join(errs, "; "))
+	}
+	return imported, nil
+}
+
+// moveProcessed moves dir/name into dir/processed, adding a timestamp to
+// the name if a file of the same name was processed before.
+func moveProcessed(dir, name string, now time.Time) error {
+	processed := filepath.Join(dir, ProcessedDir)
+	if err := os.MkdirAll(processed, 0755); err != nil {
+		return err
+	}
+	dest := filepath.Join(processed, name)
+	if _, err := os.Stat(dest); err == nil {
+		ext := filepath.Ext(name)
+		dest = filepath.Join(processed, fmt.Sprintf("%s-%s%s", strings.TrimSuffix(name, ext), now.Format("20060102-150405"), ext))
+	}
+	return os.Rename(filepath.Join(dir, name), dest)
+}
+
+// Run scans the folder returned by dir once a change in it has settled,
+// and every interval where the OS can't report changes, until ctx is done.
+// dir is re-read every interval so settings changes apply without a
+// restart; an empty folder disables scanning. Scan errors go to onErr,
+// once each until the error changes, so a missing folder isn't reported
+// on every scan.
+func Run(ctx context.Context, interval time.Duration, dir func() string, fn ImportFunc, onErr func(error)) {
+	lastErr := ""
+	dirs := func() []string {
+		if d := dir(); d != "" {
+			return []string{d}
+		}
+		return nil
+	}
+	fswatch.Run(ctx, fswatch.Options{Dirs: dirs, Quiet: settleTime + time.Second, Poll: interval}, func() bool {
+		d := dir()
+		if d == "" {
+			lastErr = ""
+			return true
+		}
+		_, err := Scan(d, time.Now(), fn)
+		if err == nil {
+			lastErr = ""
+		} else if err.Error() != lastErr {
+			lastErr = err.Error()
+			if onErr != nil {
+				onErr(err)
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,83 @@
+package watchfolder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsListFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"urls.txt":       true,
+		"Mix.M3U":        true,
+		"list.m3u8":      true,
+		"favorites.csv":  true,
+		"library.JSON":   true,
+		"cover.jpg":      false,
+		"notes":          false,
+		"track.flac.txt": true,
+	} {
+		if got := IsListFile(name); got != want {
+			t.Errorf("IsListFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "urls.txt"), []byte("https://tidal.com/browse/album/1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "cover.jpg"), []byte("x"), 0644)
+
+	got := map[string]string{}
+	n, err := Scan(dir, time.Now().Add(time.Minute), func(name, text string) { got[name] = text })
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if n != 1 || got["urls.txt"] != "https://tidal.com/browse/album/1\n" {
+		t.Errorf("Scan imported %d files: %v", n, got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ProcessedDir, "urls.txt")); err != nil {
+		t.Errorf("urls.txt not moved to processed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cover.jpg")); err != nil {
+		t.Errorf("non-list file was touched: %v", err)
+	}
+
+	// A second scan finds nothing left to import
+	if n, _ := Scan(dir, time.Now().Add(time.Minute), func(string, string) {}); n != 0 {
+		t.Errorf("second Scan imported %d files, want 0", n)
+	}
+}
+
+func TestScan_WaitsForFileToSettle(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "urls.txt"), []byte("x"), 0644)
+
+	n, err := Scan(dir, time.Now(), func(string, string) { t.Error("unsettled file imported") })
+	if err != nil || n != 0 {
+		t.Errorf("Scan = (%d, %v), want (0, nil)", n, err)
+	}
+}
+
+func TestScan_RenamesRepeatedNames(t *testing.T) {
+	dir := t.TempDir()
+	later := time.Now().Add(time.Minute)
+	for range 2 {
+		os.WriteFile(filepath.Join(dir, "urls.txt"), []byte("x"), 0644)
+		if _, err := Scan(dir, later, func(string, string) {}); err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		later = later.Add(time.Second)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, ProcessedDir))
+	if len(entries) != 2 {
+		t.Errorf("processed holds %d files, want 2", len(entries))
+	}
+}
+
+func TestScan_MissingDir(t *testing.T) {
+	if _, err := Scan(filepath.Join(t.TempDir(), "nope"), time.Now(), func(string, string) {}); err == nil {
+		t.Error("Scan of a missing folder: want error")
+	}
+}